@@ -2,14 +2,29 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/twinmind/newo-tool/internal/cli"
+	"github.com/twinmind/newo-tool/internal/fsutil"
 )
 
+// interruptedExitCode follows the shell convention of 128 + SIGINT.
+const interruptedExitCode = 130
+
 func main() {
-	if err := run(os.Args[1:]); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleSignals(cancel)
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "error: interrupted")
+			os.Exit(interruptedExitCode)
+		}
 		exitCode := 1
 		if coder, ok := err.(interface{ ExitCode() int }); ok {
 			exitCode = coder.ExitCode()
@@ -22,8 +37,23 @@ func main() {
 	}
 }
 
-func run(args []string) error {
+// handleSignals cancels the command context on the first SIGINT or SIGTERM so commands can save partial
+// state and release their locks. A second signal exits immediately, still removing the lock files.
+func handleSignals(cancel context.CancelFunc) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Fprintln(os.Stderr, "\nInterrupted; saving progress. Press Ctrl+C again to quit immediately.")
+		cancel()
+		<-signals
+		fsutil.ReleaseLocks()
+		os.Exit(interruptedExitCode)
+	}()
+}
+
+func run(ctx context.Context, args []string) error {
 	app := cli.New(os.Stdout, os.Stderr)
 
-	return app.Execute(context.Background(), args)
+	return app.Execute(ctx, args)
 }
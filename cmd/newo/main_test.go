@@ -1,9 +1,12 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestRunNoArgs(t *testing.T) {
-	if err := run(nil); err != nil {
+	if err := run(context.Background(), nil); err != nil {
 		t.Fatalf("run returned error: %v", err)
 	}
 }
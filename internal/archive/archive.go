@@ -0,0 +1,223 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+const (
+	// FormatVersion identifies the layout of archives produced by this package.
+	FormatVersion = 1
+	// ManifestName is the archive entry holding the manifest.
+	ManifestName = "manifest.json"
+
+	projectPrefix = "project/"
+)
+
+var (
+	// ErrManifestMissing indicates the archive does not contain a manifest.
+	ErrManifestMissing = errors.New("archive manifest not found")
+	// ErrHashMismatch indicates an archived file does not match the hash recorded in the manifest.
+	ErrHashMismatch = errors.New("archive content does not match manifest")
+)
+
+// Manifest describes an exported project archive.
+type Manifest struct {
+	FormatVersion      int               `json:"format_version"`
+	ToolVersion        string            `json:"tool_version"`
+	SourceCustomer     string            `json:"source_customer"`
+	SourceCustomerType string            `json:"source_customer_type"`
+	ProjectIDN         string            `json:"project_idn"`
+	ProjectSlug        string            `json:"project_slug"`
+	CreatedAt          time.Time         `json:"created_at"`
+	Project            state.ProjectData `json:"project"`
+	Hashes             map[string]string `json:"hashes"`
+}
+
+// Create writes a gzip-compressed tar archive containing the manifest and every file under projectDir.
+// The manifest hashes are computed from the files and returned to the caller.
+func Create(w io.Writer, projectDir string, manifest Manifest) (Manifest, error) {
+	files, err := collectFiles(projectDir)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	manifest.FormatVersion = FormatVersion
+	manifest.Hashes = make(map[string]string, len(files))
+	contents := make(map[string][]byte, len(files))
+	for _, rel := range files {
+		data, err := os.ReadFile(filepath.Join(projectDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read %s: %w", rel, err)
+		}
+		contents[rel] = data
+		manifest.Hashes[rel] = util.SHA256Bytes(data)
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	modTime := manifest.CreatedAt
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+
+	if err := writeEntry(tw, ManifestName, manifestBytes, modTime); err != nil {
+		return Manifest{}, err
+	}
+	for _, rel := range files {
+		if err := writeEntry(tw, projectPrefix+rel, contents[rel], modTime); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("finalize archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("finalize archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// Extract unpacks an archive created by Create into destDir and verifies every file against the manifest hashes.
+func Extract(r io.Reader, destDir string) (Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("open archive: %w", err)
+	}
+	defer func() {
+		_ = gz.Close()
+	}()
+
+	var manifest *Manifest
+	written := map[string]string{}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read %s: %w", header.Name, err)
+		}
+
+		if header.Name == ManifestName {
+			var m Manifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				return Manifest{}, fmt.Errorf("decode manifest: %w", err)
+			}
+			manifest = &m
+			continue
+		}
+
+		rel, ok := strings.CutPrefix(header.Name, projectPrefix)
+		if !ok {
+			continue
+		}
+		target, err := safeJoin(destDir, rel)
+		if err != nil {
+			return Manifest{}, err
+		}
+		if err := fsutil.EnsureParentDir(target); err != nil {
+			return Manifest{}, err
+		}
+		if err := os.WriteFile(target, data, fsutil.FilePerm); err != nil {
+			return Manifest{}, fmt.Errorf("write %s: %w", target, err)
+		}
+		written[rel] = util.SHA256Bytes(data)
+	}
+
+	if manifest == nil {
+		return Manifest{}, ErrManifestMissing
+	}
+	if manifest.FormatVersion > FormatVersion {
+		return Manifest{}, fmt.Errorf("archive format %d is newer than supported version %d", manifest.FormatVersion, FormatVersion)
+	}
+	for rel, want := range manifest.Hashes {
+		got, ok := written[rel]
+		if !ok {
+			return Manifest{}, fmt.Errorf("%w: %s missing", ErrHashMismatch, rel)
+		}
+		if got != want {
+			return Manifest{}, fmt.Errorf("%w: %s", ErrHashMismatch, rel)
+		}
+	}
+	return *manifest, nil
+}
+
+func collectFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", root, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    fsutil.FilePerm,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("write header %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// safeJoin resolves an archive entry below destDir, rejecting absolute paths and traversal.
+func safeJoin(destDir, rel string) (string, error) {
+	clean := path.Clean(rel)
+	if clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid archive entry %q", rel)
+	}
+	return filepath.Join(destDir, filepath.FromSlash(clean)), nil
+}
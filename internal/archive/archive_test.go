@@ -0,0 +1,62 @@
+package archive
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateExtractRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"project.json":               `{"project_idn":"demo"}`,
+		"flows/main/skill.nsl":       "{{ greeting }}",
+		"flows/main/skill.meta.yaml": "idn: skill\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	manifest, err := Create(&buf, src, Manifest{ProjectIDN: "demo", SourceCustomer: "acme"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(manifest.Hashes) != len(files) {
+		t.Fatalf("expected %d hashes, got %d", len(files), len(manifest.Hashes))
+	}
+
+	dest := t.TempDir()
+	got, err := Extract(bytes.NewReader(buf.Bytes()), dest)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if got.ProjectIDN != "demo" || got.SourceCustomer != "acme" || got.FormatVersion != FormatVersion {
+		t.Fatalf("unexpected manifest: %+v", got)
+	}
+	for rel, content := range files {
+		data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Fatalf("read %s: %v", rel, err)
+		}
+		if string(data) != content {
+			t.Fatalf("content mismatch for %s: %q", rel, data)
+		}
+	}
+}
+
+func TestExtractRejectsInvalidInput(t *testing.T) {
+	if _, err := Extract(bytes.NewReader([]byte("not an archive")), t.TempDir()); err == nil {
+		t.Fatalf("expected error for invalid archive")
+	}
+	if _, err := safeJoin(t.TempDir(), "../escape"); err == nil {
+		t.Fatalf("expected traversal to be rejected")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/twinmind/newo-tool/internal/fsutil"
@@ -79,3 +80,14 @@ func (t Tokens) IsExpired() bool {
 func (t Tokens) CanRefresh() bool {
 	return t.RefreshToken != ""
 }
+
+// Remove deletes cached tokens for the customer. Missing tokens are not an error.
+func Remove(customerIDN string) error {
+	for _, idn := range []string{customerIDN, strings.ToLower(customerIDN)} {
+		path := filepath.Join(fsutil.CustomerStateDir(idn), "tokens.json")
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove tokens: %w", err)
+		}
+	}
+	return nil
+}
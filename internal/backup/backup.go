@@ -0,0 +1,291 @@
+// Package backup writes and verifies customer backups: gzip-compressed tar archives holding everything
+// the platform returns for a customer, fetched directly rather than from the workspace.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+const (
+	// FormatVersion identifies the layout of backups produced by this package.
+	FormatVersion = 1
+	// ManifestName is the archive entry holding the manifest.
+	ManifestName = "manifest.json"
+	// TimestampLayout is the UTC timestamp in backup file names.
+	TimestampLayout = "20060102T150405Z"
+)
+
+var (
+	// ErrManifestMissing indicates the archive does not contain a manifest.
+	ErrManifestMissing = errors.New("backup manifest not found")
+	// ErrHashMismatch indicates the archive content does not match the hashes recorded in the manifest.
+	ErrHashMismatch = errors.New("backup content does not match manifest")
+)
+
+// Manifest describes a customer backup.
+type Manifest struct {
+	FormatVersion int               `json:"format_version"`
+	ToolVersion   string            `json:"tool_version"`
+	Customer      string            `json:"customer"`
+	CustomerType  string            `json:"customer_type"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Counts        Counts            `json:"counts"`
+	Files         map[string]string `json:"files"`
+}
+
+// Counts summarises what a backup holds.
+type Counts struct {
+	Projects   int `json:"projects"`
+	Agents     int `json:"agents"`
+	Flows      int `json:"flows"`
+	Skills     int `json:"skills"`
+	Attributes int `json:"attributes"`
+}
+
+// Client is the part of the platform client a backup reads from.
+type Client interface {
+	GetCustomerProfile(ctx context.Context) (platform.CustomerProfile, error)
+	GetCustomerAttributes(ctx context.Context, includeHidden bool) (platform.CustomerAttributesResponse, error)
+	ListProjects(ctx context.Context) ([]platform.Project, error)
+	ListAgents(ctx context.Context, projectID string) ([]platform.Agent, error)
+	ListFlowSkills(ctx context.Context, flowID string) ([]platform.Skill, error)
+	ListFlowEvents(ctx context.Context, flowID string) ([]platform.FlowEvent, error)
+	ListFlowStates(ctx context.Context, flowID string) ([]platform.FlowState, error)
+}
+
+// flowDocument is a flow together with its events and state fields.
+type flowDocument struct {
+	platform.Flow
+	Events      []platform.FlowEvent `json:"events"`
+	StateFields []platform.FlowState `json:"state_fields"`
+}
+
+// agentDocument is an agent without its flows, which are stored in files of their own.
+type agentDocument struct {
+	ID          string `json:"id"`
+	IDN         string `json:"idn"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// FileName returns the archive name of a backup of customerIDN taken at.
+func FileName(customerIDN string, at time.Time) string {
+	return fmt.Sprintf("%s-%s.tar.gz", customerIDN, at.UTC().Format(TimestampLayout))
+}
+
+// Collect fetches the customer's profile, attributes (hidden ones included), and every project with its
+// agents, flows, events, state fields, and skills. The result maps archive paths to JSON documents.
+func Collect(ctx context.Context, client Client) (map[string][]byte, Counts, error) {
+	files := map[string][]byte{}
+	var counts Counts
+	add := func(name string, value any) error {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", name, err)
+		}
+		files[name] = append(data, '\n')
+		return nil
+	}
+
+	profile, err := client.GetCustomerProfile(ctx)
+	if err != nil {
+		return nil, Counts{}, fmt.Errorf("fetch customer profile: %w", err)
+	}
+	if err := add("profile.json", profile); err != nil {
+		return nil, Counts{}, err
+	}
+	attributes, err := client.GetCustomerAttributes(ctx, true)
+	if err != nil {
+		return nil, Counts{}, fmt.Errorf("fetch attributes: %w", err)
+	}
+	counts.Attributes = len(attributes.Attributes)
+	if err := add("attributes.json", attributes.Attributes); err != nil {
+		return nil, Counts{}, err
+	}
+
+	projects, err := client.ListProjects(ctx)
+	if err != nil {
+		return nil, Counts{}, fmt.Errorf("list projects: %w", err)
+	}
+	for _, project := range projects {
+		projectDir := path.Join("projects", project.IDN)
+		if err := add(path.Join(projectDir, "project.json"), project); err != nil {
+			return nil, Counts{}, err
+		}
+		counts.Projects++
+
+		agents, err := client.ListAgents(ctx, project.ID)
+		if err != nil {
+			return nil, Counts{}, fmt.Errorf("list agents for %s: %w", project.IDN, err)
+		}
+		for _, agent := range agents {
+			agentDir := path.Join(projectDir, "agents", agent.IDN)
+			if err := add(path.Join(agentDir, "agent.json"), agentDocument{ID: agent.ID, IDN: agent.IDN, Title: agent.Title, Description: agent.Description}); err != nil {
+				return nil, Counts{}, err
+			}
+			counts.Agents++
+
+			for _, flow := range agent.Flows {
+				flowDir := path.Join(agentDir, "flows", flow.IDN)
+				events, err := client.ListFlowEvents(ctx, flow.ID)
+				if err != nil {
+					return nil, Counts{}, fmt.Errorf("list events for %s/%s: %w", project.IDN, flow.IDN, err)
+				}
+				states, err := client.ListFlowStates(ctx, flow.ID)
+				if err != nil {
+					return nil, Counts{}, fmt.Errorf("list state fields for %s/%s: %w", project.IDN, flow.IDN, err)
+				}
+				if err := add(path.Join(flowDir, "flow.json"), flowDocument{Flow: flow, Events: events, StateFields: states}); err != nil {
+					return nil, Counts{}, err
+				}
+				counts.Flows++
+
+				skills, err := client.ListFlowSkills(ctx, flow.ID)
+				if err != nil {
+					return nil, Counts{}, fmt.Errorf("list skills for %s/%s: %w", project.IDN, flow.IDN, err)
+				}
+				for _, skill := range skills {
+					if err := add(path.Join(flowDir, "skills", skill.IDN+".json"), skill); err != nil {
+						return nil, Counts{}, err
+					}
+					counts.Skills++
+				}
+			}
+		}
+	}
+	return files, counts, nil
+}
+
+// Write stores files in a gzip-compressed tar archive after a manifest recording the hash of each. The
+// completed manifest is returned.
+func Write(w io.Writer, manifest Manifest, files map[string][]byte) (Manifest, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	manifest.FormatVersion = FormatVersion
+	manifest.Files = make(map[string]string, len(files))
+	for _, name := range names {
+		manifest.Files[name] = util.SHA256Bytes(files[name])
+	}
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	modTime := manifest.CreatedAt
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	if err := writeEntry(tw, ManifestName, manifestBytes, modTime); err != nil {
+		return Manifest{}, err
+	}
+	for _, name := range names {
+		if err := writeEntry(tw, name, files[name], modTime); err != nil {
+			return Manifest{}, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("finalize backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("finalize backup: %w", err)
+	}
+	return manifest, nil
+}
+
+// Verify reads a backup and checks that it holds exactly the files its manifest lists, with the
+// recorded hashes.
+func Verify(r io.Reader) (Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("open backup: %w", err)
+	}
+	defer func() {
+		_ = gz.Close()
+	}()
+
+	var manifest *Manifest
+	hashes := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read backup: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read %s: %w", header.Name, err)
+		}
+		if header.Name == ManifestName {
+			var m Manifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				return Manifest{}, fmt.Errorf("decode manifest: %w", err)
+			}
+			manifest = &m
+			continue
+		}
+		hashes[header.Name] = util.SHA256Bytes(data)
+	}
+
+	if manifest == nil {
+		return Manifest{}, ErrManifestMissing
+	}
+	if manifest.FormatVersion > FormatVersion {
+		return Manifest{}, fmt.Errorf("backup format %d is newer than supported version %d", manifest.FormatVersion, FormatVersion)
+	}
+	for name, want := range manifest.Files {
+		got, ok := hashes[name]
+		if !ok {
+			return *manifest, fmt.Errorf("%w: %s missing", ErrHashMismatch, name)
+		}
+		if got != want {
+			return *manifest, fmt.Errorf("%w: %s", ErrHashMismatch, name)
+		}
+	}
+	for name := range hashes {
+		if _, ok := manifest.Files[name]; !ok {
+			return *manifest, fmt.Errorf("%w: %s not in manifest", ErrHashMismatch, name)
+		}
+	}
+	return *manifest, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    fsutil.FilePerm,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("write header %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
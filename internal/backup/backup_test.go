@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWriteVerifyRoundTrip(t *testing.T) {
+	files := map[string][]byte{
+		"profile.json":                        []byte(`{"idn":"acme"}`),
+		"attributes.json":                     []byte(`[]`),
+		"projects/shop/project.json":          []byte(`{"idn":"shop"}`),
+		"projects/shop/agents/bot/agent.json": []byte(`{"idn":"bot"}`),
+	}
+	createdAt := time.Date(2026, 5, 4, 3, 2, 1, 0, time.UTC)
+
+	var buf bytes.Buffer
+	written, err := Write(&buf, Manifest{Customer: "acme", CreatedAt: createdAt, Counts: Counts{Projects: 1, Agents: 1}}, files)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(written.Files) != len(files) {
+		t.Fatalf("expected %d hashes, got %d", len(files), len(written.Files))
+	}
+
+	got, err := Verify(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got.Customer != "acme" || got.FormatVersion != FormatVersion || !got.CreatedAt.Equal(createdAt) || got.Counts.Projects != 1 {
+		t.Fatalf("unexpected manifest: %+v", got)
+	}
+	if name := FileName("acme", createdAt); name != "acme-20260504T030201Z.tar.gz" {
+		t.Fatalf("unexpected file name %q", name)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Write(&buf, Manifest{Customer: "acme"}, map[string][]byte{"profile.json": []byte(`{"idn":"acme"}`)}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	entries := readEntries(t, buf.Bytes())
+
+	tampered := rewrite(t, entries, func(name string, data []byte) []byte {
+		if name == "profile.json" {
+			return []byte(`{"idn":"evil"}`)
+		}
+		return data
+	})
+	if _, err := Verify(bytes.NewReader(tampered)); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected hash mismatch, got %v", err)
+	}
+
+	entries = append(entries, entry{name: "extra.json", data: []byte(`{}`)})
+	if _, err := Verify(bytes.NewReader(rewrite(t, entries, nil))); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected unexpected file to be rejected, got %v", err)
+	}
+
+	if _, err := Verify(bytes.NewReader(rewrite(t, entries[1:], nil))); !errors.Is(err, ErrManifestMissing) {
+		t.Fatalf("expected missing manifest, got %v", err)
+	}
+}
+
+type entry struct {
+	name string
+	data []byte
+}
+
+func readEntries(t *testing.T, archive []byte) []entry {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	var entries []entry
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		entries = append(entries, entry{name: header.Name, data: data})
+	}
+}
+
+func rewrite(t *testing.T, entries []entry, edit func(name string, data []byte) []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		data := e.data
+		if edit != nil {
+			data = edit(e.name, data)
+		}
+		if err := writeEntry(tw, e.name, data, time.Now()); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
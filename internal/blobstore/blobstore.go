@@ -0,0 +1,89 @@
+// Package blobstore keeps a content-addressed copy of exported files so identical content, such as a
+// prompt script shared by many customers, is stored once.
+//
+// Files are materialised from the store as copy-on-write clones where the file system supports them
+// (Btrfs, XFS, APFS) and as plain copies elsewhere. Hard links are deliberately not used: an editor that
+// saves in place would silently change the same script in every other customer sharing the blob.
+package blobstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// Store is a directory of blobs named by the SHA-256 digest of their content.
+type Store struct {
+	root string
+}
+
+// Open returns the store rooted at dir. The directory is created on first write.
+func Open(dir string) *Store {
+	return &Store{root: dir}
+}
+
+// Path returns the location of the blob with the given digest.
+func (s *Store) Path(digest string) string {
+	if len(digest) < 2 {
+		return filepath.Join(s.root, digest)
+	}
+	return filepath.Join(s.root, digest[:2], digest)
+}
+
+// Has reports whether a blob with the given digest is stored.
+func (s *Store) Has(digest string) bool {
+	_, err := os.Stat(s.Path(digest))
+	return err == nil
+}
+
+// Put stores data unless an identical blob already exists and returns its digest. An existing blob's
+// modification time is refreshed so gc treats it as in use.
+func (s *Store) Put(data []byte) (string, error) {
+	digest := util.SHA256Bytes(data)
+	path := s.Path(digest)
+	if s.Has(digest) {
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return digest, nil
+	}
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		return "", err
+	}
+	if err := fsutil.AtomicWrite(path, data, fsutil.FilePerm); err != nil {
+		return "", fmt.Errorf("store blob %s: %w", digest, err)
+	}
+	return digest, nil
+}
+
+// WriteFile stores data and replaces target with a copy of the blob.
+func (s *Store) WriteFile(target string, data []byte) error {
+	digest, err := s.Put(data)
+	if err != nil {
+		return err
+	}
+	return s.Materialize(digest, target, data)
+}
+
+// Materialize replaces target with the blob. The copy is cloned when the file system allows it;
+// otherwise data, which must be the blob's content, is written instead.
+func (s *Store) Materialize(digest, target string, data []byte) error {
+	if err := fsutil.EnsureParentDir(target); err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".blob-tmp")
+	_ = os.Remove(tmp)
+	if err := cloneFile(s.Path(digest), tmp); err == nil {
+		if err := os.Rename(tmp, target); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("replace %s: %w", target, err)
+		}
+		return nil
+	}
+	// Cloning is an optimisation; any failure falls back to a plain copy.
+	_ = os.Remove(tmp)
+	return fsutil.AtomicWrite(target, data, fsutil.FilePerm)
+}
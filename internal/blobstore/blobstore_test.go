@@ -0,0 +1,70 @@
+package blobstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileStoresContentOnceAndKeepsCopiesIndependent(t *testing.T) {
+	dir := t.TempDir()
+	store := Open(filepath.Join(dir, "blobs"))
+	first := filepath.Join(dir, "acme", "greet.nsl")
+	second := filepath.Join(dir, "globex", "greet.nsl")
+	script := []byte("Hello {{name}}\n")
+
+	if err := store.WriteFile(first, script); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := store.WriteFile(second, script); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	var blobs int
+	_ = filepath.Walk(filepath.Join(dir, "blobs"), func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			blobs++
+		}
+		return nil
+	})
+	if blobs != 1 {
+		t.Fatalf("expected one stored blob, got %d", blobs)
+	}
+
+	// Editing one copy in place must not leak into the other copy or the store.
+	if err := os.WriteFile(first, []byte("edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(second); err != nil || string(data) != string(script) {
+		t.Fatalf("expected the second copy to keep its content, got %q, %v", data, err)
+	}
+	digest, err := store.Put(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(store.Path(digest)); err != nil || string(data) != string(script) {
+		t.Fatalf("expected the blob to keep its content, got %q, %v", data, err)
+	}
+}
+
+func TestPutRefreshesExistingBlob(t *testing.T) {
+	store := Open(t.TempDir())
+	digest, err := store.Put([]byte("shared\n"))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(store.Path(digest), past, past); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put([]byte("shared\n")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	info, err := os.Stat(store.Path(digest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().After(past) {
+		t.Fatalf("expected Put to refresh the blob's modification time, got %v", info.ModTime())
+	}
+}
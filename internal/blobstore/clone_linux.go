@@ -0,0 +1,31 @@
+//go:build linux
+
+package blobstore
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// cloneFile creates dst as a reflink of src using the FICLONE ioctl. File systems without reflink
+// support report EOPNOTSUPP or EXDEV.
+func cloneFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, fsutil.FilePerm)
+	if err != nil {
+		return err
+	}
+	if err := unix.IoctlFileClone(int(out.Fd()), int(in.Fd())); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package blobstore
+
+import "errors"
+
+func cloneFile(string, string) error {
+	return errors.ErrUnsupported
+}
@@ -9,6 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/logging"
+	"github.com/twinmind/newo-tool/internal/platform"
 )
 
 // App coordinates CLI command registration and execution.
@@ -30,13 +36,43 @@ func New(stdout, stderr io.Writer) *App {
 	app.Register(&VersionCommand{writer: stdout})
 	app.Register(NewPullCommand(stdout, stderr))
 	app.Register(NewPushCommand(stdout, stderr))
+	app.Register(NewPublishCommand(stdout, stderr))
 	app.Register(NewStatusCommand(stdout, stderr))
 	app.Register(NewLintCommand(stdout, stderr))
+	app.Register(NewValidateCommand(stdout, stderr))
 	app.Register(NewFmtCommand(stdout, stderr))
 	app.Register(NewGenerateCommand(stdout, stderr))
 	app.Register(NewHealthcheckCommand(stdout, stderr))
+	app.Register(NewDoctorCommand(stdout, stderr))
 	app.Register(NewMergeCommand(stdout, stderr))
 	app.Register(NewDeployCommand(stdout, stderr))
+	app.Register(NewApplyCommand(stdout, stderr))
+	app.Register(NewExportCommand(stdout, stderr))
+	app.Register(NewImportCommand(stdout, stderr))
+	app.Register(NewBackupCommand(stdout, stderr))
+	app.Register(NewStateCommand(stdout, stderr))
+	app.Register(NewSkillsCommand(stdout, stderr))
+	app.Register(NewGrepCommand(stdout, stderr))
+	app.Register(NewSedCommand(stdout, stderr))
+	app.Register(NewProjectsCommand(stdout, stderr))
+	app.Register(NewCustomersCommand(stdout, stderr))
+	app.Register(NewAttributesCommand(stdout, stderr))
+	app.Register(NewInitCommand(stdout, stderr))
+	app.Register(NewOpenCommand(stdout, stderr))
+	app.Register(NewNewCommand(stdout, stderr))
+	app.Register(NewUICommand(stdout, stderr))
+	app.Register(NewAuthCommand(stdout, stderr))
+	app.Register(NewLockCommand(stdout, stderr))
+	app.Register(NewGCCommand(stdout, stderr))
+	app.Register(NewVerifyCommand(stdout, stderr))
+	app.Register(NewLogCommand(stdout, stderr))
+	app.Register(NewDriftCommand(stdout, stderr))
+	app.Register(NewHistoryCommand(stdout, stderr))
+	app.Register(NewLogsCommand(stdout, stderr))
+	app.Register(NewExecCommand(stdout, stderr))
+	app.Register(NewTestCommand(stdout, stderr))
+	app.Register(NewNSLCommand(stdout, stderr))
+	app.Register(NewMockServerCommand(stdout, stderr))
 
 	return app
 }
@@ -49,8 +85,53 @@ func (a *App) Register(cmd Command) {
 	a.commands[cmd.Name()] = cmd
 }
 
+// globalOptions holds the flags every command accepts, either before or after the command name.
+type globalOptions struct {
+	config    string
+	profile   string
+	strictAPI bool
+	record    string
+	replay    string
+	log       logging.Options
+	// diffStyle selects how confirmation and review diffs are drawn.
+	diffStyle string
+	// yes and no answer every confirmation prompt instead of reading stdin.
+	yes bool
+	no  bool
+}
+
+// register binds the global flags to fs, keeping values already parsed before the command name as defaults.
+func (o *globalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.config, "config", o.config, "path to newo.toml (default: $NEWO_CONFIG or the nearest newo.toml in a parent directory)")
+	fs.StringVar(&o.profile, "profile", o.profile, "newo.toml profile to use (default: $NEWO_PROFILE)")
+	fs.StringVar(&o.log.Level, "log-level", o.log.Level, "diagnostic log level: debug, info, warn, or error")
+	fs.StringVar(&o.log.File, "log-file", o.log.File, "append diagnostic logs to this file")
+	fs.StringVar(&o.log.Format, "log-format", o.log.Format, "diagnostic log format: text or json")
+	fs.BoolVar(&o.strictAPI, "strict-api", o.strictAPI, "check platform API responses for unknown and missing fields")
+	fs.StringVar(&o.record, "record", o.record, "record platform traffic, with secrets scrubbed, to this cassette file")
+	fs.StringVar(&o.replay, "replay", o.replay, "answer platform requests from this cassette file instead of the network")
+	fs.StringVar(&o.diffStyle, "diff-style", o.diffStyle, "how diffs are shown: "+strings.Join(diff.Styles, ", "))
+	fs.BoolVar(&o.yes, "yes", o.yes, "answer y to every confirmation prompt")
+	fs.BoolVar(&o.no, "no", o.no, "answer n to every confirmation prompt")
+}
+
 // Execute runs the command specified by args, defaulting to help.
 func (a *App) Execute(ctx context.Context, args []string) error {
+	opts := globalOptions{log: logging.Options{Format: "text"}}
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		global := flag.NewFlagSet(executableName(), flag.ContinueOnError)
+		global.SetOutput(a.stderr)
+		opts.register(global)
+		if err := global.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				a.printUsage()
+				return nil
+			}
+			return err
+		}
+		args = global.Args()
+	}
+
 	if len(args) == 0 {
 		a.printUsage()
 		return nil
@@ -65,8 +146,10 @@ func (a *App) Execute(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet(target.Name(), flag.ContinueOnError)
 	fs.SetOutput(a.stderr)
 	target.RegisterFlags(fs)
+	opts.register(fs)
 
-	if err := fs.Parse(args[1:]); err != nil {
+	positional, err := parseFlags(fs, args[1:])
+	if err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			a.printCommandUsage(target, fs)
 			return nil
@@ -74,12 +157,96 @@ func (a *App) Execute(ctx context.Context, args []string) error {
 		return err
 	}
 
-	return target.Run(ctx, fs.Args())
+	leaveWorkspace, err := enterWorkspace(opts.config)
+	if err != nil {
+		return err
+	}
+	defer leaveWorkspace()
+	config.SetProfile(opts.profile)
+	defer config.SetProfile("")
+
+	if err := configureRedaction(); err != nil {
+		return err
+	}
+	if err := configureLineEndings(); err != nil {
+		return err
+	}
+	if err := diff.SetStyle(opts.diffStyle); err != nil {
+		return err
+	}
+	defer func() { _ = diff.SetStyle("") }()
+	prompter, err := newPrompter(opts.yes, opts.no)
+	if err != nil {
+		return err
+	}
+	if prompted, ok := target.(promptedCommand); ok {
+		prompted.SetPrompter(prompter)
+		defer prompted.SetPrompter(nil)
+	}
+
+	opts.log.File = userPath(opts.log.File)
+	closeLog, err := logging.Setup(opts.log, a.stderr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = closeLog()
+	}()
+
+	operationTimeout, err := configureHTTP(a.stderr)
+	if err != nil {
+		return err
+	}
+	if opts.strictAPI {
+		platform.SetStrictAPI(a.stderr)
+		defer platform.SetStrictAPI(nil)
+	}
+	stopCassette, err := startCassette(opts.record, opts.replay)
+	if err != nil {
+		return err
+	}
+	if operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
+	}
+
+	logging.Debug("command started", "command", target.Name(), "args", strings.Join(positional, " "), "profile", config.ActiveProfile())
+	runErr := target.Run(ctx, positional)
+	if runErr != nil {
+		logging.Error("command failed", "command", target.Name(), "error", runErr.Error())
+	}
+	if err := stopCassette(); err != nil {
+		runErr = errors.Join(runErr, err)
+	}
+	return withExitCode(runErr)
+}
+
+// parseFlags parses flags that may appear before or after positional arguments.
+// Everything following a literal "--" is treated as positional.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
 }
 
 func (a *App) printUsage() {
 	_, _ = fmt.Fprintf(a.stderr, "Usage:\n")
-	_, _ = fmt.Fprintf(a.stderr, "  %s <command> [flags]\n\n", executableName())
+	_, _ = fmt.Fprintf(a.stderr, "  %s [global flags] <command> [flags]\n\n", executableName())
+	_, _ = fmt.Fprintf(a.stderr, "Global flags: --config, --profile, --log-level, --log-file, --log-format, --strict-api, --record, --replay, --diff-style, --yes, --no\n\n")
 	_, _ = fmt.Fprintf(a.stderr, "Available commands:\n")
 
 	names := make([]string, 0, len(a.commands))
@@ -88,6 +255,9 @@ func (a *App) printUsage() {
 			// help is implicit; show it last.
 			continue
 		}
+		if hidden, ok := a.commands[name].(interface{ Hidden() bool }); ok && hidden.Hidden() {
+			continue
+		}
 		names = append(names, name)
 	}
 	sort.Strings(names)
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestParseFlagsAllowsTrailingFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	to := fs.String("to", "", "")
+	verbose := fs.Bool("verbose", false, "")
+
+	args, err := parseFlags(fs, []string{"archive.tar.gz", "--to", "acme", "--verbose", "--", "--literal"})
+	if err != nil {
+		t.Fatalf("parseFlags: %v", err)
+	}
+	if *to != "acme" || !*verbose {
+		t.Fatalf("flags not parsed: to=%q verbose=%v", *to, *verbose)
+	}
+	if want := []string{"archive.tar.gz", "--literal"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected positional args: %v", args)
+	}
+}
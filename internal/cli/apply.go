@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/deploy"
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/notify"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	skillsync "github.com/twinmind/newo-tool/internal/sync"
+	"github.com/twinmind/newo-tool/internal/templatevars"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// defaultManifestFile is where `newo apply --import` writes the manifest when no path is given.
+const defaultManifestFile = "newo.project.yaml"
+
+// ApplyCommand reconciles a remote project with a declarative project manifest.
+type ApplyCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+
+	verbose     *bool
+	customer    *string
+	autoApprove *bool
+	importIDN   *string
+	confirmIDN  *string
+
+	prompts
+}
+
+// NewApplyCommand constructs an apply command.
+func NewApplyCommand(stdout, stderr io.Writer) *ApplyCommand {
+	return &ApplyCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *ApplyCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *ApplyCommand) Name() string {
+	return "apply"
+}
+
+func (c *ApplyCommand) Summary() string {
+	return "Create, update, and delete remote resources to match a project manifest"
+}
+
+func (c *ApplyCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.verbose = fs.Bool("verbose", false, "enable verbose logging")
+	c.customer = fs.String("customer", "", "customer IDN or alias to apply the manifest to")
+	c.autoApprove = fs.Bool("auto-approve", false, "apply the plan without asking for confirmation")
+	c.confirmIDN = fs.String("confirm-customer", "", "IDN of a protected customer, confirming the apply to it")
+	c.importIDN = fs.String("import", "", "write a manifest and skill scripts for an existing remote project instead of applying")
+}
+
+func (c *ApplyCommand) Run(ctx context.Context, args []string) (err error) {
+	c.ensureConsole()
+
+	verbose := c.verbose != nil && *c.verbose
+	autoApprove := c.autoApprove != nil && *c.autoApprove
+	if importIDN := flagValue(c.importIDN); importIDN != "" {
+		if len(args) > 1 {
+			return errors.New("usage: newo apply --import <project_idn> [<manifest.yaml>] [--customer <idn>]")
+		}
+		manifestPath := defaultManifestFile
+		if len(args) == 1 && strings.TrimSpace(args[0]) != "" {
+			manifestPath = args[0]
+		}
+		return c.runImport(ctx, importIDN, manifestPath, verbose)
+	}
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return errors.New("usage: newo apply <manifest.yaml> [--customer <idn>] [--auto-approve] [--confirm-customer <idn>] [--verbose]")
+	}
+
+	projectPlan, err := deploy.LoadManifest(userPath(args[0]))
+	if err != nil {
+		return err
+	}
+
+	env, entry, registry, sess, err := c.session(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := confirmProtectedCustomer(c.console, c.prompt(), *entry, sess.IDN, flagValue(c.confirmIDN)); err != nil {
+		return err
+	}
+
+	releaseLock, err := fsutil.AcquireLock(sess.IDN, "apply")
+	if err != nil {
+		return lockError(err)
+	}
+	defer func() {
+		if err := releaseLock(); err != nil && verbose {
+			c.console.Warn("Release lock: %v", err)
+		}
+	}()
+
+	started := time.Now()
+	event := notify.Event{Command: "apply", Customer: sess.IDN, Project: projectPlan.IDN}
+	defer func() {
+		event.Finish(started, err)
+		sendNotifications(ctx, c.console, env.Notifications, []notify.Event{event})
+	}()
+
+	result, err := deploy.NewApplier(sess.Client).Apply(ctx, deploy.ApplyRequest{
+		Project:            projectPlan,
+		TargetCustomerIDN:  sess.IDN,
+		TargetCustomerType: sess.CustomerType,
+		OutputRoot:         env.OutputRoot,
+		WorkspaceDir:       ".",
+		Reporter:           consoleReporter{writer: c.console},
+		Vars:               templatevars.ForCustomer(sess.IDN, sess.CustomerType, entry.Vars),
+		Confirm: func(plan deploy.ApplyPlan) (bool, error) {
+			c.printPlan(plan)
+			if autoApprove {
+				return true, nil
+			}
+			return c.confirm()
+		},
+	})
+	event.Counts = map[string]int{
+		"created": result.Created,
+		"updated": result.Updated,
+		"deleted": result.Deleted,
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.ProjectCreated {
+		if err := config.AddProjectToToml(config.TomlPath(), sess.IDN, projectPlan.IDN, result.ProjectID); err != nil {
+			return fmt.Errorf("update newo.toml: %w", err)
+		}
+	}
+	if sess.RegistryUpdated {
+		if err := registry.Save(); err != nil && verbose {
+			c.console.Warn("Save API key registry: %v", err)
+		}
+	}
+
+	if result.Declined {
+		c.console.Info("Apply cancelled; nothing was changed.")
+		return nil
+	}
+	if !result.Changed() {
+		c.console.Success("Project %s on %s already matches %s", projectPlan.IDN, sess.IDN, args[0])
+		return nil
+	}
+	c.console.Success("Applied %s to %s: %d created, %d updated, %d deleted", args[0], sess.IDN, result.Created, result.Updated, result.Deleted)
+	return nil
+}
+
+// runImport describes an existing remote project as a manifest, writing its skill scripts next to it.
+// Nothing on the platform is changed.
+func (c *ApplyCommand) runImport(ctx context.Context, projectIDN, manifestArg string, verbose bool) error {
+	manifestPath := userPath(manifestArg)
+	if _, err := os.Stat(manifestPath); err == nil {
+		return fmt.Errorf("%s already exists; choose another manifest path", manifestArg)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	_, _, registry, sess, err := c.session(ctx)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := deploy.NewApplier(sess.Client).Import(ctx, projectIDN, filepath.Dir(manifestPath))
+	if err != nil {
+		return err
+	}
+	if err := deploy.WriteManifest(manifestPath, manifest); err != nil {
+		return err
+	}
+	if sess.RegistryUpdated {
+		if err := registry.Save(); err != nil && verbose {
+			c.console.Warn("Save API key registry: %v", err)
+		}
+	}
+	c.console.Success("Imported project %s from %s into %s", manifest.IDN, sess.IDN, manifestArg)
+	return nil
+}
+
+// session opens a session for the single customer a manifest is applied to: the one asked for, the
+// default, or the only one configured.
+func (c *ApplyCommand) session(ctx context.Context) (config.Env, *customer.Entry, *state.APIKeyRegistry, *session.Session, error) {
+	env, err := config.LoadEnv()
+	if err != nil {
+		return config.Env{}, nil, nil, nil, err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return config.Env{}, nil, nil, nil, err
+	}
+
+	customerToken := flagValue(c.customer)
+	if customerToken == "" {
+		customerToken = cfg.DefaultCustomer
+	}
+	var entry *customer.Entry
+	switch {
+	case customerToken != "":
+		if entry, err = cfg.FindCustomer(customerToken); err != nil {
+			return config.Env{}, nil, nil, nil, err
+		}
+	case len(cfg.Entries) == 1:
+		entry = &cfg.Entries[0]
+	case len(cfg.Entries) == 0:
+		return config.Env{}, nil, nil, nil, errors.New("no customers configured")
+	default:
+		return config.Env{}, nil, nil, nil, errors.New("several customers are configured; choose one with --customer")
+	}
+
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return config.Env{}, nil, nil, nil, err
+	}
+	sess, err := session.New(ctx, env, *entry, registry)
+	if err != nil {
+		return config.Env{}, nil, nil, nil, err
+	}
+	return env, entry, registry, sess, nil
+}
+
+var planSymbols = map[string]string{
+	skillsync.FlowChangeCreate: "+",
+	skillsync.FlowChangeUpdate: "~",
+	skillsync.FlowChangeDelete: "-",
+}
+
+// printPlan lists the planned changes Terraform-style, with a diff under each update.
+func (c *ApplyCommand) printPlan(plan deploy.ApplyPlan) {
+	c.console.Section(fmt.Sprintf("Plan for project %s", plan.ProjectIDN))
+	for _, change := range plan.Changes {
+		c.console.RawLine("  %s %s %s", planSymbols[change.Action], change.Kind, change.Path)
+		if len(change.Diff) > 0 {
+			c.console.Write(diff.Format(change.Path, change.Diff))
+		}
+	}
+	created, updated, deleted := plan.Counts()
+	c.console.Info("Plan: %d to create, %d to update, %d to delete.", created, updated, deleted)
+}
+
+func (c *ApplyCommand) confirm() (bool, error) {
+	text, err := c.prompt().Confirm(c.console, "Apply these changes? [y/N]: ")
+	if err != nil {
+		return false, err
+	}
+	return text == "y", nil
+}
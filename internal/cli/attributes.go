@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// Kinds of difference between the attributes of two customers.
+const (
+	attributeChanged  = "changed"
+	attributeOnlyFrom = "only in source"
+	attributeOnlyTo   = "only in target"
+)
+
+// attributeClient is the part of the platform client that reads and writes customer attributes.
+type attributeClient interface {
+	GetCustomerAttributes(ctx context.Context, includeHidden bool) (platform.CustomerAttributesResponse, error)
+	CreateCustomerAttribute(ctx context.Context, payload platform.CustomerAttributeRequest) (platform.CreateCustomerAttributeResponse, error)
+	UpdateCustomerAttribute(ctx context.Context, attributeID string, payload platform.CustomerAttributeRequest) error
+}
+
+// AttributesCommand compares the customer attributes of two customers and copies values between them.
+type AttributesCommand struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	console    *console.Writer
+	from       *string
+	to         *string
+	keys       *string
+	force      *bool
+	format     *string
+	confirmIDN *string
+
+	prompts
+}
+
+// attributeDiff is an attribute whose value differs between the two customers.
+type attributeDiff struct {
+	IDN  string `json:"idn"`
+	Kind string `json:"kind"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	source *platform.CustomerAttribute
+	target *platform.CustomerAttribute
+}
+
+// NewAttributesCommand constructs an attributes command.
+func NewAttributesCommand(stdout, stderr io.Writer) *AttributesCommand {
+	return &AttributesCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *AttributesCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *AttributesCommand) Name() string {
+	return "attributes"
+}
+
+func (c *AttributesCommand) Summary() string {
+	return "Compare customer attributes of two customers and copy values between them"
+}
+
+func (c *AttributesCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.from = fs.String("from", "", "customer IDN or alias to compare from")
+	c.to = fs.String("to", "", "customer IDN or alias to compare with, and to copy values to")
+	c.keys = fs.String("key", "", "comma-separated attribute IDNs to compare or copy (defaults to all)")
+	c.force = fs.Bool("force", false, "copy every differing value without asking")
+	c.format = fs.String("format", "text", "output format of diff: text or json")
+	c.confirmIDN = fs.String("confirm-customer", "", "IDN of a protected target customer, confirming the sync to it")
+}
+
+func (c *AttributesCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo attributes <diff|sync> --from <idn> --to <idn> [--key <idns>] [--format text|json] [--force] [--confirm-customer <idn>]"
+	if len(args) != 1 || (args[0] != "diff" && args[0] != "sync") {
+		return errors.New(usage)
+	}
+	fromToken, toToken := flagValue(c.from), flagValue(c.to)
+	if fromToken == "" || toToken == "" {
+		return errors.New(usage)
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+	_, source, err := openCustomerSession(ctx, env, cfg, registry, fromToken)
+	if err != nil {
+		return err
+	}
+	targetEntry, target, err := openCustomerSession(ctx, env, cfg, registry, toToken)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(source.IDN, target.IDN) {
+		return errors.New("--from and --to name the same customer")
+	}
+
+	diffs, err := compareCustomerAttributes(ctx, source.Client, target.Client, splitRuleList(c.keys))
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "diff" {
+		if format == "json" {
+			encoder := json.NewEncoder(c.stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(diffs); err != nil {
+				return fmt.Errorf("write json: %w", err)
+			}
+			return nil
+		}
+		c.printDiff(source.IDN, target.IDN, diffs)
+		return nil
+	}
+
+	if err := confirmProtectedCustomer(c.console, c.prompt(), *targetEntry, target.IDN, flagValue(c.confirmIDN)); err != nil {
+		return err
+	}
+	return c.sync(ctx, target.Client, target.IDN, diffs)
+}
+
+// openCustomerSession logs in to the customer named by token.
+func openCustomerSession(ctx context.Context, env config.Env, cfg customer.Configuration, registry *state.APIKeyRegistry, token string) (*customer.Entry, *session.Session, error) {
+	entry, err := cfg.FindCustomer(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	sess, err := session.New(ctx, env, *entry, registry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sess.RegistryUpdated {
+		if err := registry.Save(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return entry, sess, nil
+}
+
+// compareCustomerAttributes lists the attributes, hidden ones included, whose values differ between
+// the two customers, sorted by IDN. keys limits the comparison to those IDNs.
+func compareCustomerAttributes(ctx context.Context, source, target attributeClient, keys []string) ([]attributeDiff, error) {
+	from, err := source.GetCustomerAttributes(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("fetch source attributes: %w", err)
+	}
+	to, err := target.GetCustomerAttributes(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("fetch target attributes: %w", err)
+	}
+	return diffAttributes(from.Attributes, to.Attributes, keys), nil
+}
+
+func diffAttributes(from, to []platform.CustomerAttribute, keys []string) []attributeDiff {
+	wanted := func(idn string) bool {
+		if len(keys) == 0 {
+			return true
+		}
+		for _, key := range keys {
+			if strings.EqualFold(key, idn) {
+				return true
+			}
+		}
+		return false
+	}
+	targets := make(map[string]*platform.CustomerAttribute, len(to))
+	for i := range to {
+		targets[to[i].IDN] = &to[i]
+	}
+
+	diffs := []attributeDiff{}
+	seen := map[string]bool{}
+	for i := range from {
+		source := &from[i]
+		seen[source.IDN] = true
+		if !wanted(source.IDN) {
+			continue
+		}
+		target, ok := targets[source.IDN]
+		switch {
+		case !ok:
+			diffs = append(diffs, attributeDiff{IDN: source.IDN, Kind: attributeOnlyFrom, From: attributeValue(source.Value), source: source})
+		case attributeValue(source.Value) != attributeValue(target.Value):
+			diffs = append(diffs, attributeDiff{IDN: source.IDN, Kind: attributeChanged, From: attributeValue(source.Value), To: attributeValue(target.Value), source: source, target: target})
+		}
+	}
+	for i := range to {
+		if !seen[to[i].IDN] && wanted(to[i].IDN) {
+			diffs = append(diffs, attributeDiff{IDN: to[i].IDN, Kind: attributeOnlyTo, To: attributeValue(to[i].Value), target: &to[i]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].IDN < diffs[j].IDN })
+	return diffs
+}
+
+// attributeValue renders a value for comparison and display: strings as they are, anything else as
+// JSON.
+func attributeValue(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func (c *AttributesCommand) printDiff(fromIDN, toIDN string, diffs []attributeDiff) {
+	c.console.Section(fmt.Sprintf("Attributes %s → %s", strings.ToUpper(fromIDN), strings.ToUpper(toIDN)))
+	if len(diffs) == 0 {
+		c.console.Success("No differences.")
+		return
+	}
+	rows := make([][]string, 0, len(diffs))
+	for _, diff := range diffs {
+		rows = append(rows, []string{diff.IDN, diff.Kind, orDash(diff.From), orDash(diff.To)})
+	}
+	writeTable(c.console, []string{"ATTRIBUTE", "DIFFERENCE", strings.ToUpper(fromIDN), strings.ToUpper(toIDN)}, rows)
+}
+
+// sync copies the source value of each differing attribute to the target, creating attributes the
+// target lacks with the source's settings. Attributes that exist only in the target are left alone.
+func (c *AttributesCommand) sync(ctx context.Context, target attributeClient, targetIDN string, diffs []attributeDiff) error {
+	applyAll := c.force != nil && *c.force
+	copied := 0
+	for _, diff := range diffs {
+		if diff.source == nil {
+			continue
+		}
+		if !applyAll {
+			question := fmt.Sprintf("Set %s on %s to %q (now %q)? [y/N/a]: ", diff.IDN, targetIDN, diff.From, diff.To)
+			if diff.target == nil {
+				question = fmt.Sprintf("Create %s on %s with %q? [y/N/a]: ", diff.IDN, targetIDN, diff.From)
+			}
+			text, err := c.prompt().Confirm(c.console, question)
+			if err != nil {
+				return err
+			}
+			switch text {
+			case "y":
+			case "a":
+				applyAll = true
+			default:
+				c.console.Info("Skipping.")
+				continue
+			}
+		}
+
+		if diff.target == nil {
+			if _, err := target.CreateCustomerAttribute(ctx, attributeRequest(*diff.source, diff.source.Value)); err != nil {
+				return fmt.Errorf("create attribute %s: %w", diff.IDN, err)
+			}
+		} else if err := target.UpdateCustomerAttribute(ctx, diff.target.ID, attributeRequest(*diff.target, diff.source.Value)); err != nil {
+			return fmt.Errorf("update attribute %s: %w", diff.IDN, err)
+		}
+		copied++
+	}
+	c.console.Success("Copied %d attribute value(s) to %s.", copied, targetIDN)
+	return nil
+}
+
+// attributeRequest is the payload that gives attribute the value, keeping its other settings.
+func attributeRequest(attribute platform.CustomerAttribute, value any) platform.CustomerAttributeRequest {
+	return platform.CustomerAttributeRequest{
+		IDN:            attribute.IDN,
+		Value:          value,
+		Title:          attribute.Title,
+		Description:    attribute.Description,
+		Group:          attribute.Group,
+		IsHidden:       attribute.IsHidden,
+		PossibleValues: attribute.PossibleValues,
+		ValueType:      attribute.ValueType,
+	}
+}
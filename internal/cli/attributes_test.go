@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+)
+
+type fakeAttributeClient struct {
+	attributes []platform.CustomerAttribute
+	created    []platform.CustomerAttributeRequest
+	updated    map[string]platform.CustomerAttributeRequest
+}
+
+func (f *fakeAttributeClient) GetCustomerAttributes(context.Context, bool) (platform.CustomerAttributesResponse, error) {
+	return platform.CustomerAttributesResponse{Attributes: f.attributes}, nil
+}
+
+func (f *fakeAttributeClient) CreateCustomerAttribute(_ context.Context, payload platform.CustomerAttributeRequest) (platform.CreateCustomerAttributeResponse, error) {
+	f.created = append(f.created, payload)
+	return platform.CreateCustomerAttributeResponse{ID: "new"}, nil
+}
+
+func (f *fakeAttributeClient) UpdateCustomerAttribute(_ context.Context, attributeID string, payload platform.CustomerAttributeRequest) error {
+	if f.updated == nil {
+		f.updated = map[string]platform.CustomerAttributeRequest{}
+	}
+	f.updated[attributeID] = payload
+	return nil
+}
+
+func TestAttributesSyncCopiesConfirmedValues(t *testing.T) {
+	source := &fakeAttributeClient{attributes: []platform.CustomerAttribute{
+		{ID: "s1", IDN: "timezone", Value: "UTC"},
+		{ID: "s2", IDN: "max_turns", Value: float64(5)},
+		{ID: "s3", IDN: "greeting", Value: "Hi", Title: "Greeting", ValueType: "string"},
+		{ID: "s4", IDN: "same", Value: "x"},
+	}}
+	target := &fakeAttributeClient{attributes: []platform.CustomerAttribute{
+		{ID: "t1", IDN: "timezone", Value: "Europe/Berlin", Title: "Time zone"},
+		{ID: "t2", IDN: "max_turns", Value: float64(3)},
+		{ID: "t4", IDN: "same", Value: "x"},
+		{ID: "t5", IDN: "legacy", Value: "old"},
+	}}
+
+	diffs, err := compareCustomerAttributes(context.Background(), source, target, nil)
+	if err != nil {
+		t.Fatalf("compareCustomerAttributes: %v", err)
+	}
+	var kinds []string
+	for _, diff := range diffs {
+		kinds = append(kinds, diff.IDN+":"+diff.Kind)
+	}
+	want := "greeting:only in source,legacy:only in target,max_turns:changed,timezone:changed"
+	if got := strings.Join(kinds, ","); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if diffs[2].From != "5" || diffs[2].To != "3" {
+		t.Fatalf("expected numbers rendered as JSON, got %+v", diffs[2])
+	}
+
+	var out bytes.Buffer
+	cmd := NewAttributesCommand(&out, &out)
+	// greeting is created, max_turns declined, and timezone updated.
+	cmd.SetPrompter(NewPrompter(strings.NewReader("y\nn\ny\n")))
+	if err := cmd.sync(context.Background(), target, "integration", diffs); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(target.created) != 1 || target.created[0].IDN != "greeting" || target.created[0].Title != "Greeting" || target.created[0].Value != "Hi" {
+		t.Fatalf("expected greeting to be created with the source settings, got %+v", target.created)
+	}
+	update, ok := target.updated["t1"]
+	if len(target.updated) != 1 || !ok || update.Value != "UTC" || update.Title != "Time zone" {
+		t.Fatalf("expected only timezone to be updated, keeping the target title, got %+v", target.updated)
+	}
+	if !strings.Contains(out.String(), "Copied 2 attribute value(s) to integration.") {
+		t.Fatalf("expected a summary, got:\n%s", out.String())
+	}
+}
+
+func TestDiffAttributesLimitsToKeys(t *testing.T) {
+	from := []platform.CustomerAttribute{{IDN: "a", Value: "1"}, {IDN: "b", Value: "1"}}
+	to := []platform.CustomerAttribute{{IDN: "a", Value: "2"}, {IDN: "b", Value: "2"}}
+	diffs := diffAttributes(from, to, []string{"B"})
+	if len(diffs) != 1 || diffs[0].IDN != "b" {
+		t.Fatalf("expected only b, got %+v", diffs)
+	}
+}
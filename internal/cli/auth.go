@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/auth"
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// AuthCommand stores and removes customer API keys in the configured credential backend.
+type AuthCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	noVerify *bool
+
+	prompts
+}
+
+// NewAuthCommand constructs an auth command.
+func NewAuthCommand(stdout, stderr io.Writer) *AuthCommand {
+	return &AuthCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *AuthCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *AuthCommand) Name() string {
+	return "auth"
+}
+
+func (c *AuthCommand) Summary() string {
+	return "Store or remove customer API keys in the OS keychain or another credential backend"
+}
+
+func (c *AuthCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias the API key belongs to")
+	c.noVerify = fs.Bool("no-verify", false, "store the key without checking it against the platform (login only)")
+}
+
+func (c *AuthCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo auth <login|logout> [--customer <idn>] [--no-verify]"
+	if len(args) != 1 {
+		return errors.New(usage)
+	}
+	token := ""
+	if c.customer != nil {
+		token = strings.TrimSpace(*c.customer)
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	store, err := customer.OpenCredentialStore(env)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "login":
+		return c.login(ctx, env, store, token)
+	case "logout":
+		if token == "" {
+			return errors.New("usage: newo auth logout --customer <idn>")
+		}
+		return c.logout(env, store, configuredIDN(env, token))
+	default:
+		return fmt.Errorf("unknown auth subcommand %q; %s", args[0], usage)
+	}
+}
+
+func (c *AuthCommand) login(ctx context.Context, env config.Env, store state.CredentialStore, token string) error {
+	verify := c.noVerify == nil || !*c.noVerify
+	if !verify && token == "" {
+		return errors.New("--no-verify requires --customer")
+	}
+
+	apiKey, err := c.prompt().Secret(c.console, "API key: ")
+	if err != nil {
+		return fmt.Errorf("read API key: %w", err)
+	}
+	if apiKey == "" {
+		return errors.New("no API key provided")
+	}
+
+	account := configuredIDN(env, token)
+	if verify {
+		registry, err := state.LoadAPIKeyRegistry()
+		if err != nil {
+			return err
+		}
+		// The hint is left empty so the key is exchanged even when tokens are cached.
+		sess, err := session.New(ctx, env, customer.Entry{APIKey: apiKey}, registry)
+		if err != nil {
+			return err
+		}
+		if token != "" && !strings.EqualFold(sess.IDN, account) {
+			return fmt.Errorf("API key belongs to customer %s, not %s", sess.IDN, token)
+		}
+		if token == "" {
+			account = configuredIDN(env, sess.IDN)
+		}
+		if sess.RegistryUpdated {
+			if err := registry.Save(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := store.Set(account, apiKey); err != nil {
+		return err
+	}
+	c.console.Success("Stored API key for %s in %s", account, store.Name())
+
+	for _, fileCustomer := range env.FileCustomers {
+		if !strings.EqualFold(fileCustomer.IDN, account) {
+			continue
+		}
+		if fileCustomer.APIKey != "" {
+			c.console.Warn("%s still sets api_key for %s; remove it so the stored key is used", config.TomlPath(), account)
+		}
+		return nil
+	}
+	c.console.Info("Add a [[customers]] entry with idn = %q and no api_key to %s to use it", account, config.TomlPath())
+	return nil
+}
+
+func (c *AuthCommand) logout(env config.Env, store state.CredentialStore, account string) error {
+	if err := store.Delete(account); err != nil {
+		return err
+	}
+	if err := auth.Remove(account); err != nil {
+		return err
+	}
+	c.console.Success("Removed API key and cached tokens for %s from %s", account, store.Name())
+	for _, fileCustomer := range env.FileCustomers {
+		if strings.EqualFold(fileCustomer.IDN, account) && fileCustomer.APIKey != "" {
+			c.console.Warn("%s still sets api_key for %s", config.TomlPath(), account)
+		}
+	}
+	return nil
+}
+
+// configuredIDN maps an IDN or alias to the IDN spelled in newo.toml, so keys are filed under the
+// account the customer entry looks up. Unknown tokens are returned unchanged.
+func configuredIDN(env config.Env, token string) string {
+	for _, fileCustomer := range env.FileCustomers {
+		if strings.EqualFold(fileCustomer.IDN, token) || (fileCustomer.Alias != "" && strings.EqualFold(fileCustomer.Alias, token)) {
+			return fileCustomer.IDN
+		}
+	}
+	return token
+}
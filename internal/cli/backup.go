@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/backup"
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/version"
+)
+
+// defaultBackupDir is where backups are written when --dir is not given.
+const defaultBackupDir = "backups"
+
+// BackupCommand saves everything the platform holds for a customer into a timestamped archive.
+type BackupCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	dir      *string
+
+	// now returns the time backups are stamped with; tests replace it.
+	now func() time.Time
+}
+
+// NewBackupCommand constructs a backup command.
+func NewBackupCommand(stdout, stderr io.Writer) *BackupCommand {
+	return &BackupCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+		now:     time.Now,
+	}
+}
+
+func (c *BackupCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *BackupCommand) Name() string {
+	return "backup"
+}
+
+func (c *BackupCommand) Summary() string {
+	return "Back up customers from the platform into timestamped archives, or verify a backup"
+}
+
+func (c *BackupCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to back up (defaults to all)")
+	c.dir = fs.String("dir", defaultBackupDir, "directory the archives are written to")
+}
+
+func (c *BackupCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	switch {
+	case len(args) == 0:
+		return c.backup(ctx)
+	case len(args) == 2 && args[0] == "verify":
+		return c.verify(userPath(args[1]))
+	default:
+		return errors.New("usage: newo backup [--customer <idn>] [--dir <path>] | newo backup verify <archive>")
+	}
+}
+
+func (c *BackupCommand) backup(ctx context.Context) error {
+	customerFilter := flagValue(c.customer)
+	dir := flagValue(c.dir)
+	if dir == "" {
+		dir = defaultBackupDir
+	}
+	dir = userPath(dir)
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	matched := false
+	processed := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		sess, err := session.New(ctx, env, entry, registry)
+		if err != nil {
+			return err
+		}
+		if sess.RegistryUpdated {
+			if err := registry.Save(); err != nil {
+				return err
+			}
+		}
+		if customerFilter != "" && !matchesCustomerToken(entry, sess.IDN, customerFilter) {
+			continue
+		}
+		matched = true
+		if processed[strings.ToLower(sess.IDN)] {
+			continue
+		}
+		processed[strings.ToLower(sess.IDN)] = true
+
+		if err := c.backupCustomer(ctx, sess.Client, sess.IDN, entry.Type, dir); err != nil {
+			return err
+		}
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+	return nil
+}
+
+// backupCustomer writes one customer's backup. A backup that fails halfway is removed rather than left
+// looking complete.
+func (c *BackupCommand) backupCustomer(ctx context.Context, client backup.Client, customerIDN, customerType, dir string) error {
+	files, counts, err := backup.Collect(ctx, client)
+	if err != nil {
+		return fmt.Errorf("back up %s: %w", customerIDN, err)
+	}
+
+	createdAt := c.now().UTC()
+	outputPath := filepath.Join(dir, backup.FileName(customerIDN, createdAt))
+	if err := fsutil.EnsureParentDir(outputPath); err != nil {
+		return err
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("create backup %s: %w", outputPath, err)
+	}
+	_, err = backup.Write(file, backup.Manifest{
+		ToolVersion:  version.Version,
+		Customer:     customerIDN,
+		CustomerType: customerType,
+		CreatedAt:    createdAt,
+		Counts:       counts,
+	}, files)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close backup %s: %w", outputPath, closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(outputPath)
+		return err
+	}
+
+	c.console.Success("Backed up %s (%d projects, %d skills, %d attributes) to %s", customerIDN, counts.Projects, counts.Skills, counts.Attributes, outputPath)
+	return nil
+}
+
+func (c *BackupCommand) verify(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open backup %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	manifest, err := backup.Verify(file)
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", path, err)
+	}
+	c.console.Section(fmt.Sprintf("Backup of %s", strings.ToUpper(manifest.Customer)))
+	writeTable(c.console, []string{"FIELD", "VALUE"}, [][]string{
+		{"Created", manifest.CreatedAt.Format(time.RFC3339)},
+		{"Tool version", orDash(manifest.ToolVersion)},
+		{"Projects", fmt.Sprint(manifest.Counts.Projects)},
+		{"Agents", fmt.Sprint(manifest.Counts.Agents)},
+		{"Flows", fmt.Sprint(manifest.Counts.Flows)},
+		{"Skills", fmt.Sprint(manifest.Counts.Skills)},
+		{"Attributes", fmt.Sprint(manifest.Counts.Attributes)},
+	})
+	c.console.Success("All %d files match the manifest.", len(manifest.Files))
+	return nil
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/twinmind/newo-tool/internal/customer"
@@ -22,3 +24,23 @@ func matchesCustomerToken(entry customer.Entry, remoteID string, token string) b
 	}
 	return false
 }
+
+// groupEntries narrows the configured customers to those in the group named by --group; without a
+// group every entry is kept. A group cannot be combined with --customer.
+func groupEntries(cfg customer.Configuration, group, customerFilter string) ([]customer.Entry, error) {
+	group = strings.TrimSpace(group)
+	if group == "" {
+		return cfg.Entries, nil
+	}
+	if customerFilter != "" {
+		return nil, errors.New("--customer and --group cannot be used together")
+	}
+	entries := cfg.InGroup(group)
+	if len(entries) == 0 {
+		if groups := cfg.Groups(); len(groups) > 0 {
+			return nil, fmt.Errorf("no customer in newo.toml belongs to group %s (groups: %s)", group, strings.Join(groups, ", "))
+		}
+		return nil, fmt.Errorf("no customer in newo.toml belongs to group %s; set group on its [[customers]] entries", group)
+	}
+	return entries, nil
+}
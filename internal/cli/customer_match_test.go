@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/customer"
+)
+
+func TestGroupEntries(t *testing.T) {
+	cfg := customer.Configuration{Entries: []customer.Entry{
+		{HintIDN: "shop-us", Group: "us-prod"},
+		{HintIDN: "shop-ca", Group: "us-prod"},
+		{HintIDN: "shop-eu", Group: "eu-prod"},
+		{HintIDN: "sandbox"},
+	}}
+
+	entries, err := groupEntries(cfg, "", "")
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("expected every customer without --group, got %d (%v)", len(entries), err)
+	}
+	entries, err = groupEntries(cfg, "us-prod", "")
+	if err != nil || len(entries) != 2 || entries[0].HintIDN != "shop-us" || entries[1].HintIDN != "shop-ca" {
+		t.Fatalf("expected the us-prod customers, got %#v (%v)", entries, err)
+	}
+	if _, err := groupEntries(cfg, "us-prod", "shop-us"); err == nil {
+		t.Fatal("expected --customer and --group together to be rejected")
+	}
+	if _, err := groupEntries(cfg, "apac", ""); err == nil || !strings.Contains(err.Error(), "eu-prod, us-prod") {
+		t.Fatalf("expected an unknown group to list the known ones, got %v", err)
+	}
+}
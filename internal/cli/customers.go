@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// CustomersCommand lists the customers configured in newo.toml or the environment.
+type CustomersCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+	format  *string
+}
+
+// customerRow describes one configured customer; API keys are never included.
+type customerRow struct {
+	IDN      string   `json:"idn"`
+	Alias    string   `json:"alias,omitempty"`
+	Type     string   `json:"type"`
+	Group    string   `json:"group,omitempty"`
+	Projects []string `json:"projects"`
+	Registry string   `json:"registry"`
+	Pulled   bool     `json:"pulled"`
+	Default  bool     `json:"default"`
+}
+
+// NewCustomersCommand constructs a customers command.
+func NewCustomersCommand(stdout, stderr io.Writer) *CustomersCommand {
+	return &CustomersCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *CustomersCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *CustomersCommand) Name() string {
+	return "customers"
+}
+
+func (c *CustomersCommand) Summary() string {
+	return "List configured customers and their local state"
+}
+
+func (c *CustomersCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *CustomersCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo customers list [--format text|json]"
+	if len(args) != 1 || args[0] != "list" {
+		return errors.New(usage)
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	rows := customerRows(cfg, registry)
+
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(rows); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+
+	if len(rows) == 0 {
+		c.console.Info("No customers configured. Add a [[customers]] entry to newo.toml.")
+		return nil
+	}
+	table := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		idn := row.IDN
+		if row.Default {
+			idn += " *"
+		}
+		projects := "all"
+		if len(row.Projects) > 0 {
+			projects = strings.Join(row.Projects, ",")
+		}
+		pulled := "no"
+		if row.Pulled {
+			pulled = "yes"
+		}
+		table = append(table, []string{idn, orDash(row.Alias), orDash(row.Type), orDash(row.Group), projects, row.Registry, pulled})
+	}
+	c.console.Section("Customers")
+	writeTable(c.console, []string{"IDN", "ALIAS", "TYPE", "GROUP", "PROJECTS", "API KEY", "PULLED"}, table)
+	c.console.Info("* default customer")
+	return nil
+}
+
+// customerRows folds per-project configuration entries back into one row per customer.
+// Registry status reports whether the customer IDN is known without contacting the platform:
+// "configured" when newo.toml names it, "registered" when a previous session recorded the key,
+// and "unknown" otherwise.
+func customerRows(cfg customer.Configuration, registry *state.APIKeyRegistry) []customerRow {
+	rows := []customerRow{}
+	index := map[string]int{}
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		status := "configured"
+		if idn == "" {
+			if known, ok := registry.Lookup(entry.APIKey); ok {
+				idn = known
+				status = "registered"
+			} else {
+				status = "unknown"
+			}
+		}
+
+		key := strings.ToLower(idn) + "\x00" + entry.APIKey
+		pos, seen := index[key]
+		if !seen {
+			row := customerRow{
+				IDN:      idn,
+				Alias:    entry.Alias,
+				Type:     entry.Type,
+				Group:    entry.Group,
+				Projects: []string{},
+				Registry: status,
+			}
+			if idn != "" {
+				_, err := os.Stat(fsutil.MapPath(idn))
+				row.Pulled = err == nil
+			}
+			if cfg.DefaultCustomer != "" && (strings.EqualFold(cfg.DefaultCustomer, idn) || strings.EqualFold(cfg.DefaultCustomer, entry.Alias)) {
+				row.Default = true
+			}
+			if row.IDN == "" {
+				row.IDN = "?"
+			}
+			rows = append(rows, row)
+			pos = len(rows) - 1
+			index[key] = pos
+		}
+		project := strings.TrimSpace(entry.ProjectIDN)
+		if project == "" {
+			project = strings.TrimSpace(entry.ProjectID)
+		}
+		if project != "" {
+			rows[pos].Projects = append(rows[pos].Projects, project)
+		}
+	}
+	return rows
+}
+
+// listFormat validates the --format flag shared by the list commands.
+func listFormat(flagValue *string) (string, error) {
+	format := "text"
+	if flagValue != nil && strings.TrimSpace(*flagValue) != "" {
+		format = strings.ToLower(strings.TrimSpace(*flagValue))
+	}
+	if format != "text" && format != "json" {
+		return "", fmt.Errorf("unsupported format %q (expected text or json)", format)
+	}
+	return format, nil
+}
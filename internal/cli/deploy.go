@@ -2,18 +2,20 @@ package cli
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/twinmind/newo-tool/internal/config"
 	"github.com/twinmind/newo-tool/internal/customer"
 	"github.com/twinmind/newo-tool/internal/deploy"
 	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/notify"
 	"github.com/twinmind/newo-tool/internal/session"
 	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/templatevars"
 	"github.com/twinmind/newo-tool/internal/ui/console"
 )
 
@@ -23,8 +25,13 @@ type DeployCommand struct {
 	stderr  io.Writer
 	console *console.Writer
 
-	verbose        *bool
-	sourceCustomer *string
+	verbose           *bool
+	sourceCustomer    *string
+	rollbackOnFailure *bool
+	resume            *bool
+	confirmCustomer   *string
+
+	prompts
 }
 
 // NewDeployCommand constructs a deploy command.
@@ -53,13 +60,16 @@ func (c *DeployCommand) Summary() string {
 func (c *DeployCommand) RegisterFlags(fs *flag.FlagSet) {
 	c.verbose = fs.Bool("verbose", false, "enable verbose logging")
 	c.sourceCustomer = fs.String("source-customer", "", "integration customer IDN to use as source")
+	c.rollbackOnFailure = fs.Bool("rollback-on-failure", false, "delete created project, agents, and flows if the deploy fails")
+	c.resume = fs.Bool("resume", false, "continue a previously failed deploy from its saved state")
+	c.confirmCustomer = fs.String("confirm-customer", "", "IDN of a protected target customer, confirming the deploy to it")
 }
 
-func (c *DeployCommand) Run(ctx context.Context, args []string) error {
+func (c *DeployCommand) Run(ctx context.Context, args []string) (err error) {
 	c.ensureConsole()
 
 	if len(args) != 3 || !strings.EqualFold(args[1], "to") {
-		return fmt.Errorf("usage: newo deploy <project_idn> to <target_customer_idn> [--source-customer]")
+		return fmt.Errorf("usage: newo deploy <project_idn> to <target_customer_idn> [--source-customer] [--rollback-on-failure] [--resume]")
 	}
 
 	projectIDN := strings.TrimSpace(args[0])
@@ -69,6 +79,8 @@ func (c *DeployCommand) Run(ctx context.Context, args []string) error {
 	}
 
 	verbose := c.verbose != nil && *c.verbose
+	rollbackOnFailure := c.rollbackOnFailure != nil && *c.rollbackOnFailure
+	resume := c.resume != nil && *c.resume
 	sourceCustomerHint := ""
 	if c.sourceCustomer != nil {
 		sourceCustomerHint = strings.TrimSpace(*c.sourceCustomer)
@@ -99,19 +111,9 @@ func (c *DeployCommand) Run(ctx context.Context, args []string) error {
 	if strings.EqualFold(targetEntry.Type, "integration") {
 		return fmt.Errorf("target customer %s must not have type integration", targetEntry.HintIDN)
 	}
-
-	releaseLock, err := fsutil.AcquireLock("deploy")
-	if err != nil {
-		if errors.Is(err, fsutil.ErrLocked) {
-			return fmt.Errorf("another operation is already running; please retry later")
-		}
+	if err := confirmProtectedCustomer(c.console, c.prompt(), *targetEntry, targetEntry.HintIDN, flagValue(c.confirmCustomer)); err != nil {
 		return err
 	}
-	defer func() {
-		if err := releaseLock(); err != nil && verbose {
-			c.console.Warn("Release lock: %v", err)
-		}
-	}()
 
 	registry, err := state.LoadAPIKeyRegistry()
 	if err != nil {
@@ -127,6 +129,24 @@ func (c *DeployCommand) Run(ctx context.Context, args []string) error {
 	if err != nil {
 		return err
 	}
+
+	releaseLock, err := fsutil.AcquireLock(targetSession.IDN, "deploy")
+	if err != nil {
+		return lockError(err)
+	}
+	defer func() {
+		if err := releaseLock(); err != nil && verbose {
+			c.console.Warn("Release lock: %v", err)
+		}
+	}()
+
+	started := time.Now()
+	event := notify.Event{Command: "deploy", Customer: targetSession.IDN, SourceCustomer: sourceSession.IDN, Project: projectIDN}
+	defer func() {
+		event.Finish(started, err)
+		sendNotifications(ctx, c.console, env.Notifications, []notify.Event{event})
+	}()
+
 	registryDirty := sourceSession.RegistryUpdated || targetSession.RegistryUpdated
 
 	sourceConfig := deploy.SourceConfig{
@@ -150,14 +170,24 @@ func (c *DeployCommand) Run(ctx context.Context, args []string) error {
 		OutputRoot:         env.OutputRoot,
 		WorkspaceDir:       ".",
 		Reporter:           reporter,
+		RollbackOnFailure:  rollbackOnFailure,
+		Resume:             resume,
+		Vars:               templatevars.ForCustomer(targetSession.IDN, targetSession.CustomerType, targetEntry.Vars),
 	}
 
 	result, err := deployService.Deploy(ctx, request)
+	event.SkillsCreated = result.SkillsCreated
+	event.Counts = map[string]int{
+		"agents_created": result.AgentsCreated,
+		"flows_created":  result.FlowsCreated,
+		"events_created": result.EventsCreated,
+		"states_created": result.StatesCreated,
+	}
 	if err != nil {
 		return err
 	}
 
-	if err := config.AddProjectToToml(config.DefaultTomlPath, targetSession.IDN, projectIDN, result.ProjectID); err != nil {
+	if err := config.AddProjectToToml(config.TomlPath(), targetSession.IDN, projectIDN, result.ProjectID); err != nil {
 		return fmt.Errorf("update newo.toml: %w", err)
 	}
 
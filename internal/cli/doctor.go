@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/healthcheck"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// DoctorCommand checks that a customer's API key and workspace allow the tool to work, without
+// changing anything remotely.
+type DoctorCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+
+	customer *string
+}
+
+// NewDoctorCommand constructs a doctor command.
+func NewDoctorCommand(stdout, stderr io.Writer) *DoctorCommand {
+	return &DoctorCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *DoctorCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *DoctorCommand) Name() string {
+	return "doctor"
+}
+
+func (c *DoctorCommand) Summary() string {
+	return "Check a customer's API permissions and workspace before running other commands"
+}
+
+func (c *DoctorCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to check")
+}
+
+func (c *DoctorCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+	if len(args) > 0 {
+		return errors.New("usage: newo doctor [--customer <idn>]")
+	}
+
+	c.console.Section("Configuration")
+	env, err := config.LoadEnv()
+	if err != nil {
+		c.console.Error("Load configuration: %v", err)
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		c.console.Error("Resolve customers: %v", err)
+		return err
+	}
+	token := flagValue(c.customer)
+	if token == "" {
+		token = cfg.DefaultCustomer
+	}
+	var entry *customer.Entry
+	switch {
+	case token != "":
+		entry, err = cfg.FindCustomer(token)
+	case len(cfg.Entries) == 1:
+		entry = &cfg.Entries[0]
+	default:
+		err = errors.New("several customers are configured; choose one with --customer")
+	}
+	if err != nil {
+		c.console.Error("%v", err)
+		return err
+	}
+	c.console.Success("Configuration is valid.")
+
+	c.console.Section("Permissions")
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+	sess, err := session.New(ctx, env, *entry, registry)
+	if err != nil {
+		c.console.Error("Authenticate: %v", err)
+		return fmt.Errorf("authenticate: %w", err)
+	}
+	c.console.Success("Authenticated as customer %s.", sess.IDN)
+	if sess.RegistryUpdated {
+		_ = registry.Save()
+	}
+	failed := c.report(healthcheck.ProbePermissions(ctx, sess.Client))
+
+	c.console.Section("Workspace")
+	failed += c.report(healthcheck.CheckWorkspace(env.OutputRoot, sess.IDN))
+
+	if failed > 0 {
+		return fmt.Errorf("%d doctor check(s) failed", failed)
+	}
+	c.console.Success("All checks passed for %s.", sess.IDN)
+	return nil
+}
+
+// report prints one line per result and returns how many of them failed.
+func (c *DoctorCommand) report(results []healthcheck.Result) int {
+	failed := 0
+	for _, result := range results {
+		line := result.Name
+		if result.Detail != "" {
+			line += ": " + result.Detail
+		}
+		switch result.Status {
+		case healthcheck.StatusOK:
+			c.console.Success("%s", line)
+		case healthcheck.StatusSkipped:
+			c.console.Info("%s (skipped)", line)
+		case healthcheck.StatusDenied:
+			c.console.Error("%s", line)
+			failed++
+		default:
+			c.console.Warn("%s", line)
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/logging"
+	"github.com/twinmind/newo-tool/internal/notify"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// Kinds of remote drift.
+const (
+	driftModified = "modified"
+	driftAdded    = "added"
+	driftDeleted  = "deleted"
+)
+
+// driftClient is the part of the platform client a drift check reads from.
+type driftClient interface {
+	ListFlowSkills(ctx context.Context, flowID string) ([]platform.Skill, error)
+}
+
+// DriftCommand reports skills that changed on the platform since the last pull or push without going
+// through newo, such as prompts edited directly in the platform UI.
+type DriftCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	group    *string
+	watch    *time.Duration
+	format   *string
+}
+
+// driftedSkill is a skill whose remote state no longer matches the baseline of the last sync.
+type driftedSkill struct {
+	Customer string `json:"customer"`
+	Project  string `json:"project"`
+	Agent    string `json:"agent"`
+	Flow     string `json:"flow"`
+	Skill    string `json:"skill"`
+	Kind     string `json:"kind"`
+	Path     string `json:"path"`
+	// LocalEdit reports that the local script was edited too, so a push would conflict.
+	LocalEdit  bool   `json:"local_edit"`
+	RemoteHash string `json:"remote_hash,omitempty"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+}
+
+func (d driftedSkill) name() string {
+	return strings.Join([]string{d.Project, d.Agent, d.Flow, d.Skill}, "/")
+}
+
+// NewDriftCommand constructs a drift command.
+func NewDriftCommand(stdout, stderr io.Writer) *DriftCommand {
+	return &DriftCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *DriftCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *DriftCommand) Name() string {
+	return "drift"
+}
+
+func (c *DriftCommand) Summary() string {
+	return "Detect skills changed on the platform outside newo since the last sync"
+}
+
+func (c *DriftCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to check")
+	c.group = fs.String("group", "", "check every customer in this newo.toml group")
+	c.watch = fs.Duration("watch", 0, "keep checking at this interval (e.g. 5m), alerting on new drift only")
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *DriftCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 0 {
+		return errors.New("usage: newo drift [--customer <idn>] [--group <name>] [--watch <interval>] [--format text|json]")
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+	var interval time.Duration
+	if c.watch != nil {
+		interval = *c.watch
+	}
+	if interval < 0 {
+		return errors.New("--watch must be positive")
+	}
+	customerFilter := flagValue(c.customer)
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	entries, err := groupEntries(cfg, flagValue(c.group), customerFilter)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Drift already reported is remembered by path and remote hash, so a watch alerts once per edit.
+	reported := map[string]bool{}
+	check := func() ([]driftedSkill, []notify.Event, error) {
+		return c.check(ctx, env, entries, registry, customerFilter, reported)
+	}
+	if interval > 0 {
+		return c.watchDrift(ctx, format, interval, env.Notifications, check)
+	}
+
+	drifted, events, err := check()
+	if err != nil {
+		return err
+	}
+	sendNotifications(ctx, c.console, env.Notifications, events)
+	if err := c.print(format, drifted); err != nil {
+		return err
+	}
+	// Drift fails the run, so schedulers and CI notice it.
+	if len(drifted) > 0 {
+		return newSilentExitError(exitDrift)
+	}
+	return nil
+}
+
+// watchDrift runs check every interval until ctx is done. A failed check, such as a platform outage,
+// is reported and retried at the next interval instead of ending the watch.
+func (c *DriftCommand) watchDrift(ctx context.Context, format string, interval time.Duration, targets []config.Notification, check func() ([]driftedSkill, []notify.Event, error)) error {
+	for {
+		drifted, events, err := check()
+		if err != nil {
+			logging.Warn("drift check failed", "error", err)
+			c.console.Warn("Drift check failed: %v; retrying in %s", err, interval)
+		} else {
+			sendNotifications(ctx, c.console, targets, events)
+			if len(drifted) > 0 {
+				if err := c.print(format, drifted); err != nil {
+					return err
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// check compares every selected customer with the platform once and returns the drift not reported
+// before, with a notification event for each customer that drifted or could not be checked.
+func (c *DriftCommand) check(ctx context.Context, env config.Env, entries []customer.Entry, registry *state.APIKeyRegistry, customerFilter string, reported map[string]bool) ([]driftedSkill, []notify.Event, error) {
+	drifted := []driftedSkill{}
+	var events []notify.Event
+	matched := false
+	processed := map[string]bool{}
+	for _, entry := range entries {
+		sess, err := session.New(ctx, env, entry, registry)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sess.RegistryUpdated {
+			if err := registry.Save(); err != nil {
+				return nil, nil, err
+			}
+		}
+		if customerFilter != "" && !matchesCustomerToken(entry, sess.IDN, customerFilter) {
+			continue
+		}
+		matched = true
+		if processed[strings.ToLower(sess.IDN)] {
+			continue
+		}
+		processed[strings.ToLower(sess.IDN)] = true
+
+		started := time.Now()
+		event := notify.Event{Command: "drift", Customer: sess.IDN}
+		found, err := detectDrift(ctx, sess.Client, env.OutputRoot, entry.Type, sess.IDN)
+		if err != nil {
+			event.Finish(started, err)
+			sendNotifications(ctx, c.console, env.Notifications, []notify.Event{event})
+			return nil, nil, fmt.Errorf("check %s: %w", sess.IDN, err)
+		}
+		for _, skill := range found {
+			key := skill.Path + "@" + skill.RemoteHash
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			logging.Warn("remote drift", "customer", skill.Customer, "path", skill.Path, "kind", skill.Kind, "local_edit", skill.LocalEdit)
+			drifted = append(drifted, skill)
+			event.Drifted = append(event.Drifted, skill.name())
+		}
+		if len(event.Drifted) > 0 {
+			event.Finish(started, nil)
+			events = append(events, event)
+		}
+	}
+	if customerFilter != "" && !matched {
+		return nil, nil, fmt.Errorf("customer %s not configured", customerFilter)
+	}
+	return drifted, events, nil
+}
+
+// detectDrift compares the skills of every pulled flow on the platform with the hashes of the last
+// sync. A script whose remote hash differs from its baseline was changed outside newo, since pull and
+// push both move the baseline. Skills created or removed on the platform are reported too.
+func detectDrift(ctx context.Context, client driftClient, outputRoot, customerType, customerIDN string) ([]driftedSkill, error) {
+	projectMap, err := state.LoadProjectMap(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := state.LoadHashes(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+	hashCache, err := state.LoadFileHashCache(customerIDN, false)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted := []driftedSkill{}
+	for _, projectIDN := range sortedKeys(projectMap.Projects) {
+		projectData := projectMap.Projects[projectIDN]
+		slug := projectSlugFromState(projectIDN, projectData)
+		for _, agentIDN := range sortedKeys(projectData.Agents) {
+			for _, flowIDN := range sortedKeys(projectData.Agents[agentIDN].Flows) {
+				flow := projectData.Agents[agentIDN].Flows[flowIDN]
+				remote, err := client.ListFlowSkills(ctx, flow.ID)
+				if err != nil {
+					return nil, fmt.Errorf("list skills for %s/%s: %w", projectIDN, flowIDN, err)
+				}
+				scriptPath := func(skillIDN, runnerType string) string {
+					fileName := skillIDN + "." + platform.ScriptExtension(runnerType)
+					return fsutil.ExportSkillScriptPath(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN, fileName)
+				}
+				base := driftedSkill{Customer: customerIDN, Project: projectIDN, Agent: agentIDN, Flow: flowIDN}
+
+				seen := map[string]bool{}
+				for _, skill := range remote {
+					seen[skill.IDN] = true
+					path := scriptPath(skill.IDN, skill.RunnerType)
+					normalized := filepath.ToSlash(path)
+					remoteHash := util.ContentHashString(skill.PromptScript)
+					entry := base
+					entry.Skill, entry.Path, entry.RemoteHash, entry.UpdatedAt = skill.IDN, normalized, remoteHash, skill.UpdatedAt
+
+					baseline, tracked := hashes[normalized]
+					switch {
+					case !tracked:
+						if _, known := flow.Skills[skill.IDN]; known {
+							continue
+						}
+						entry.Kind = driftAdded
+					case baseline != remoteHash:
+						entry.Kind = driftModified
+						if localHash, err := hashCache.Hash(path); err == nil && localHash != baseline {
+							entry.LocalEdit = true
+						}
+					default:
+						continue
+					}
+					drifted = append(drifted, entry)
+				}
+				for _, skillIDN := range sortedKeys(flow.Skills) {
+					if seen[skillIDN] {
+						continue
+					}
+					entry := base
+					entry.Skill, entry.Kind = skillIDN, driftDeleted
+					entry.Path = filepath.ToSlash(scriptPath(skillIDN, flow.Skills[skillIDN].RunnerType))
+					drifted = append(drifted, entry)
+				}
+			}
+		}
+	}
+	return drifted, nil
+}
+
+func (c *DriftCommand) print(format string, drifted []driftedSkill) error {
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(drifted); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+	if len(drifted) == 0 {
+		c.console.Success("No remote drift.")
+		return nil
+	}
+	c.console.Section(fmt.Sprintf("Remote drift (%s)", time.Now().Format(time.DateTime)))
+	rows := make([][]string, 0, len(drifted))
+	for _, skill := range drifted {
+		local := "-"
+		if skill.LocalEdit {
+			local = "edited"
+		}
+		rows = append(rows, []string{skill.Customer, skill.name(), skill.Kind, local, orDash(skill.UpdatedAt)})
+	}
+	writeTable(c.console, []string{"CUSTOMER", "SKILL", "CHANGE", "LOCAL", "UPDATED"}, rows)
+	c.console.Warn("%d skill(s) changed on the platform outside newo; run `newo pull` to review them.", len(drifted))
+	return nil
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/notify"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+type fakeDriftClient struct {
+	skills map[string][]platform.Skill
+}
+
+func (f fakeDriftClient) ListFlowSkills(_ context.Context, flowID string) ([]platform.Skill, error) {
+	return f.skills[flowID], nil
+}
+
+func TestDetectDriftReportsOutOfBandChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"shop": {ProjectID: "p1", Path: "shop", Agents: map[string]state.AgentData{
+			"bot": {ID: "a1", Flows: map[string]state.FlowData{
+				"main": {ID: "f1", Skills: map[string]state.SkillMetadataInfo{
+					"clean":   {IDN: "clean", RunnerType: "nsl"},
+					"edited":  {IDN: "edited", RunnerType: "nsl"},
+					"both":    {IDN: "both", RunnerType: "nsl"},
+					"removed": {IDN: "removed", RunnerType: "nsl"},
+				}},
+			}},
+		}},
+	}}
+	if err := state.SaveProjectMap("acme", projectMap); err != nil {
+		t.Fatalf("save project map: %v", err)
+	}
+	path := func(skill string) string {
+		return fsutil.ExportSkillScriptPath("out", "integration", "acme", "shop", "bot", "main", skill+".nsl")
+	}
+	hashes := state.HashStore{}
+	for _, skill := range []string{"clean", "edited", "both", "removed"} {
+		writeTestFile(t, path(skill), "v1 "+skill)
+		hashes[filepath.ToSlash(path(skill))] = util.ContentHashString("v1 " + skill)
+	}
+	writeTestFile(t, path("both"), "local edit")
+	if err := state.SaveHashes("acme", hashes); err != nil {
+		t.Fatalf("save hashes: %v", err)
+	}
+
+	client := fakeDriftClient{skills: map[string][]platform.Skill{"f1": {
+		{IDN: "clean", RunnerType: "nsl", PromptScript: "v1 clean"},
+		{IDN: "edited", RunnerType: "nsl", PromptScript: "v2 edited in the UI"},
+		{IDN: "both", RunnerType: "nsl", PromptScript: "v2 both"},
+		{IDN: "created", RunnerType: "nsl", PromptScript: "new"},
+	}}}
+	drifted, err := detectDrift(context.Background(), client, "out", "integration", "acme")
+	if err != nil {
+		t.Fatalf("detectDrift: %v", err)
+	}
+
+	got := map[string]driftedSkill{}
+	for _, skill := range drifted {
+		got[skill.Skill] = skill
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected four drifted skills, got %+v", drifted)
+	}
+	if got["edited"].Kind != driftModified || got["edited"].LocalEdit {
+		t.Fatalf("unexpected edited drift: %+v", got["edited"])
+	}
+	if got["both"].Kind != driftModified || !got["both"].LocalEdit {
+		t.Fatalf("expected both to be flagged as edited locally: %+v", got["both"])
+	}
+	if got["created"].Kind != driftAdded || got["removed"].Kind != driftDeleted {
+		t.Fatalf("unexpected added or deleted drift: %+v", drifted)
+	}
+	if got["edited"].name() != "shop/bot/main/edited" {
+		t.Fatalf("unexpected name %q", got["edited"].name())
+	}
+}
+
+func TestWatchDriftContinuesAfterFailedCheck(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewDriftCommand(&out, &out)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	check := func() ([]driftedSkill, []notify.Event, error) {
+		calls++
+		if calls == 1 {
+			return nil, nil, errors.New("platform unavailable")
+		}
+		cancel()
+		return []driftedSkill{{Customer: "acme", Project: "shop", Agent: "bot", Flow: "main", Skill: "greet", Kind: driftModified}}, nil, nil
+	}
+	if err := cmd.watchDrift(ctx, "text", time.Millisecond, nil, check); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the watch to end only when cancelled, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected a second check after the failed one, got %d call(s)", calls)
+	}
+	for _, want := range []string{"platform unavailable", "shop/bot/main/greet"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected output to mention %q, got %q", want, out.String())
+		}
+	}
+}
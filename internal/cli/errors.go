@@ -1,9 +1,28 @@
 package cli
 
+import (
+	"errors"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	skillsync "github.com/twinmind/newo-tool/internal/sync"
+)
+
+// Exit codes let wrapper scripts tell failures apart. They are documented in the README.
+const (
+	exitGeneric     = 1
+	exitConflicts   = 2
+	exitAuth        = 3
+	exitLocked      = 4
+	exitPartialPush = 5
+	exitDrift       = 6
+)
+
 type exitError struct {
 	msg    string
 	code   int
 	silent bool
+	cause  error
 }
 
 func (e exitError) Error() string {
@@ -12,7 +31,7 @@ func (e exitError) Error() string {
 
 func (e exitError) ExitCode() int {
 	if e.code == 0 {
-		return 1
+		return exitGeneric
 	}
 	return e.code
 }
@@ -21,6 +40,44 @@ func (e exitError) Silent() bool {
 	return e.silent
 }
 
+func (e exitError) Unwrap() error {
+	return e.cause
+}
+
 func newSilentExitError(code int) error {
 	return exitError{code: code, silent: true}
 }
+
+// exitCode classifies a command failure. A partial push wins over the error that interrupted it, so
+// scripts know remote state moved even when the cause was, say, a revoked key.
+func exitCode(err error) int {
+	var coded exitError
+	var partial *skillsync.PartialPushError
+	switch {
+	case errors.As(err, &partial):
+		return exitPartialPush
+	case errors.As(err, &coded):
+		return coded.ExitCode()
+	case errors.Is(err, fsutil.ErrLocked):
+		return exitLocked
+	case platform.IsAuthFailure(err):
+		return exitAuth
+	}
+	return exitGeneric
+}
+
+// withExitCode attaches the exit code of err so main can report it; the message and the wrapped
+// errors stay as they were.
+func withExitCode(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(exitError); ok {
+		return err
+	}
+	code := exitCode(err)
+	if code == exitGeneric {
+		return err
+	}
+	return exitError{msg: err.Error(), code: code, cause: err}
+}
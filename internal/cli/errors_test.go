@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	skillsync "github.com/twinmind/newo-tool/internal/sync"
+)
+
+func TestWithExitCodeClassifiesFailures(t *testing.T) {
+	authErr := fmt.Errorf("exchange api key: %w", &platform.AuthError{Status: 401})
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"generic", errors.New("boom"), exitGeneric},
+		{"conflicts", fmt.Errorf("push for target customer: %w", exitError{msg: "2 skill(s) changed", code: exitConflicts}), exitConflicts},
+		{"auth", authErr, exitAuth},
+		{"forbidden call", &platform.APIError{Method: "GET", Path: "/api/v1/bff/agents/list", Status: 403}, exitAuth},
+		{"lock", fmt.Errorf("%w; run `newo lock status` for details, or retry later", fsutil.ErrLocked), exitLocked},
+		{"partial push", &skillsync.PartialPushError{Err: authErr}, exitPartialPush},
+		{"silent", newSilentExitError(1), exitGeneric},
+	}
+	for _, tc := range cases {
+		err := withExitCode(tc.err)
+		coder, ok := err.(interface{ ExitCode() int })
+		got := exitGeneric
+		if ok {
+			got = coder.ExitCode()
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected exit code %d, got %d", tc.name, tc.want, got)
+		}
+		if err.Error() != tc.err.Error() || !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected the original error to be kept, got %v", tc.name, err)
+		}
+	}
+	if withExitCode(nil) != nil {
+		t.Fatalf("expected no error for a successful run")
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// ExecCommand runs a pushed skill on the platform with parameters and context read from a file.
+type ExecCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	input    *string
+	format   *string
+}
+
+// execInput is the layout of the --input file.
+type execInput struct {
+	Parameters map[string]any `yaml:"parameters"`
+	Context    map[string]any `yaml:"context"`
+}
+
+// NewExecCommand constructs an exec command.
+func NewExecCommand(stdout, stderr io.Writer) *ExecCommand {
+	return &ExecCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *ExecCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *ExecCommand) Name() string {
+	return "exec"
+}
+
+func (c *ExecCommand) Summary() string {
+	return "Run a skill on the platform and print its result"
+}
+
+func (c *ExecCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias owning the skill")
+	c.input = fs.String("input", "", "YAML or JSON file with parameters and context (- reads stdin)")
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *ExecCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo exec <skill path> [--input <file>] [--customer <idn>] [--format text|json]"
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return errors.New(usage)
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+
+	input, err := loadExecInput(flagValue(c.input))
+	if err != nil {
+		return err
+	}
+
+	sess, found, err := locateSkill(ctx, args[0], flagValue(c.customer))
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	resp, err := sess.Client.ExecuteSkill(ctx, found.SkillID, platform.ExecuteSkillRequest{Arguments: input.Parameters, Context: input.Context})
+	if err != nil {
+		return fmt.Errorf("execute %s: %w", found.SkillIDN, err)
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(resp); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+	} else {
+		for _, line := range resp.Logs {
+			c.console.Info("%s", line)
+		}
+		if resp.Result != "" {
+			c.console.RawLine("%s", resp.Result)
+		}
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("skill %s failed: %s", found.SkillIDN, resp.Error)
+	}
+	if format == "text" {
+		c.console.Success("Executed %s/%s/%s in %s", found.ProjectIDN, found.FlowIDN, found.SkillIDN, time.Since(started).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// loadExecInput reads the parameters and context for an execution. JSON input is accepted because
+// it is valid YAML; unknown top-level keys are rejected so typos do not silently drop values.
+func loadExecInput(path string) (execInput, error) {
+	if path == "" {
+		return execInput{}, nil
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(userPath(path))
+	}
+	if err != nil {
+		return execInput{}, fmt.Errorf("read input: %w", err)
+	}
+
+	var input execInput
+	if len(bytes.TrimSpace(data)) == 0 {
+		return input, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&input); err != nil {
+		return execInput{}, fmt.Errorf("decode input %s: %w", path, err)
+	}
+	return input, nil
+}
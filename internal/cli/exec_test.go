@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadExecInput(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "input.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"parameters": {"name": "Ada"}, "context": {"turns": 2}}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	input, err := loadExecInput(jsonPath)
+	if err != nil {
+		t.Fatalf("loadExecInput: %v", err)
+	}
+	if input.Parameters["name"] != "Ada" || input.Context["turns"] != 2 {
+		t.Fatalf("unexpected input: %#v", input)
+	}
+
+	typoPath := filepath.Join(dir, "typo.yaml")
+	if err := os.WriteFile(typoPath, []byte("params:\n  name: Ada\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := loadExecInput(typoPath); err == nil || !strings.Contains(err.Error(), "params") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+
+	empty, err := loadExecInput("")
+	if err != nil || empty.Parameters != nil || empty.Context != nil {
+		t.Fatalf("unexpected empty input: %#v, %v", empty, err)
+	}
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/archive"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/version"
+)
+
+// ExportCommand bundles a locally pulled project into a portable archive.
+type ExportCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+
+	customer *string
+	output   *string
+}
+
+// NewExportCommand constructs an export command.
+func NewExportCommand(stdout, stderr io.Writer) *ExportCommand {
+	return &ExportCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *ExportCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *ExportCommand) Name() string {
+	return "export"
+}
+
+func (c *ExportCommand) Summary() string {
+	return "Bundle a local project and its state into a .tar.gz archive"
+}
+
+func (c *ExportCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias owning the project")
+	c.output = fs.String("o", "", "archive path (default <project_idn>.tar.gz)")
+}
+
+func (c *ExportCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return fmt.Errorf("usage: newo export <project_idn> [-o project.tar.gz] [--customer <idn>]")
+	}
+	projectIDN := strings.TrimSpace(args[0])
+
+	customerFlag := ""
+	if c.customer != nil {
+		customerFlag = strings.TrimSpace(*c.customer)
+	}
+	outputPath := projectIDN + ".tar.gz"
+	if c.output != nil && strings.TrimSpace(*c.output) != "" {
+		outputPath = strings.TrimSpace(*c.output)
+	}
+	outputPath = userPath(outputPath)
+
+	definition, err := resolveProjectOwner(customerFlag, projectIDN)
+	if err != nil {
+		return err
+	}
+
+	projectMap, err := state.LoadProjectMap(definition.IDN)
+	if err != nil {
+		return err
+	}
+	projectData, ok := projectMap.Projects[projectIDN]
+	if !ok {
+		return fmt.Errorf("project %s not found in local state for customer %s", projectIDN, definition.IDN)
+	}
+
+	outputRoot, err := getOutputRoot()
+	if err != nil {
+		return err
+	}
+	slug := projectSlugFromState(projectIDN, projectData)
+	projectDir := fsutil.ExportProjectDir(outputRoot, definition.Type, definition.IDN, slug)
+	if _, err := os.Stat(projectDir); err != nil {
+		return fmt.Errorf("project directory %s: %w", projectDir, err)
+	}
+
+	if err := fsutil.EnsureParentDir(outputPath); err != nil {
+		return err
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("create archive %s: %w", outputPath, err)
+	}
+
+	manifest, err := archive.Create(file, projectDir, archive.Manifest{
+		ToolVersion:        version.Version,
+		SourceCustomer:     definition.IDN,
+		SourceCustomerType: definition.Type,
+		ProjectIDN:         projectIDN,
+		ProjectSlug:        slug,
+		CreatedAt:          time.Now().UTC(),
+		Project:            projectData,
+	})
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close archive %s: %w", outputPath, closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(outputPath)
+		return err
+	}
+
+	c.console.Success("Exported project %s (%d files) to %s", projectIDN, len(manifest.Hashes), outputPath)
+	return nil
+}
+
+// resolveProjectOwner finds the customer whose local state contains the project. When token is empty,
+// every customer with local state is searched and the match must be unambiguous.
+func resolveProjectOwner(token, projectIDN string) (*customerDefinition, error) {
+	if token != "" {
+		definition, err := loadCustomerDefinition(token)
+		if err != nil {
+			return nil, err
+		}
+		if definition == nil {
+			if _, statErr := os.Stat(fsutil.MapPath(token)); statErr != nil {
+				return nil, fmt.Errorf("customer %s not configured or has no local state", token)
+			}
+			definition = &customerDefinition{IDN: token}
+		}
+		return definition, nil
+	}
+
+	customers, err := listCustomersWithState()
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, idn := range customers {
+		projectMap, err := state.LoadProjectMap(idn)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := projectMap.Projects[projectIDN]; ok {
+			matches = append(matches, idn)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("project %s not found in local state; run `newo pull` first or pass --customer", projectIDN)
+	case 1:
+	default:
+		return nil, fmt.Errorf("project %s exists for multiple customers (%s); specify one with --customer", projectIDN, strings.Join(matches, ", "))
+	}
+
+	definition, err := loadCustomerDefinition(matches[0])
+	if err != nil {
+		return nil, err
+	}
+	if definition == nil {
+		definition = &customerDefinition{IDN: matches[0]}
+	}
+	return definition, nil
+}
@@ -0,0 +1,422 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// Kinds of leftovers found by gc.
+const (
+	gcOrphanFile    = "orphaned file"
+	gcOrphanProject = "orphaned project"
+	gcStaleHash     = "stale hash"
+	gcOrphanState   = "orphaned state"
+	gcUnusedBlob    = "unused blob"
+)
+
+// gcScriptExtensions lists the extensions a skill script can have on disk.
+var gcScriptExtensions = map[string]bool{"nsl": true, "guidance": true, "jinja": true, "txt": true}
+
+// GCCommand finds and removes local files and state that no longer belong to any pulled project.
+type GCCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	dryRun   *bool
+	force    *bool
+
+	prompts
+}
+
+// gcItem is one leftover that gc can remove.
+type gcItem struct {
+	Kind     string
+	Customer string
+	Path     string
+}
+
+// NewGCCommand constructs a gc command.
+func NewGCCommand(stdout, stderr io.Writer) *GCCommand {
+	return &GCCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *GCCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *GCCommand) Name() string {
+	return "gc"
+}
+
+func (c *GCCommand) Summary() string {
+	return "Find and remove orphaned export files, stale hashes, and unused local state"
+}
+
+func (c *GCCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to clean up")
+	c.dryRun = fs.Bool("dry-run", false, "only list what would be removed")
+	c.force = fs.Bool("force", false, "remove without asking for confirmation")
+}
+
+func (c *GCCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+	started := time.Now()
+
+	if len(args) != 0 {
+		return errors.New("usage: newo gc [--customer <idn>] [--dry-run] [--force]")
+	}
+	customerFilter := ""
+	if c.customer != nil {
+		customerFilter = strings.TrimSpace(*c.customer)
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Customers are identified offline; an entry whose IDN is unknown has never been pulled.
+	known := map[string]bool{}
+	unresolved := 0
+	matched := false
+	var items []gcItem
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" {
+			unresolved++
+			continue
+		}
+		key := strings.ToLower(idn)
+		if known[key] {
+			continue
+		}
+		known[key] = true
+		if customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter) {
+			continue
+		}
+		matched = true
+		if _, err := os.Stat(fsutil.MapPath(idn)); err != nil {
+			continue
+		}
+		found, err := scanCustomerGarbage(env.OutputRoot, entry.Type, idn)
+		if err != nil {
+			return err
+		}
+		items = append(items, found...)
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	// State directories and blobs are shared, so they are only checked when every customer is known.
+	if customerFilter == "" {
+		if unresolved > 0 {
+			c.console.Warn("Skipping state and blob checks: %d configured customer(s) have never been pulled", unresolved)
+		} else {
+			found, err := scanSharedGarbage(known, started)
+			if err != nil {
+				return err
+			}
+			items = append(items, found...)
+		}
+	}
+
+	if len(items) == 0 {
+		c.console.Success("Nothing to clean up.")
+		return nil
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].Path < items[j].Path
+	})
+	table := make([][]string, 0, len(items))
+	for _, item := range items {
+		table = append(table, []string{item.Kind, orDash(item.Customer), item.Path})
+	}
+	c.console.Section("Garbage")
+	writeTable(c.console, []string{"KIND", "CUSTOMER", "PATH"}, table)
+
+	if c.dryRun != nil && *c.dryRun {
+		c.console.Info("Dry run: %d item(s) would be removed.", len(items))
+		return nil
+	}
+	if c.force == nil || !*c.force {
+		text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Remove %d item(s)? [y/N]: ", len(items)))
+		if err != nil {
+			return err
+		}
+		if text != "y" {
+			c.console.Info("Nothing removed.")
+			return nil
+		}
+	}
+
+	removed, err := c.remove(items, started)
+	if err != nil {
+		return err
+	}
+	c.console.Success("Removed %d item(s).", removed)
+	return nil
+}
+
+// remove deletes the given items, holding each customer's lock while its files and hashes change.
+// Blobs are not covered by any customer's lock, so an unused blob written or reused since started,
+// e.g. by a pull running alongside, is kept.
+func (c *GCCommand) remove(items []gcItem, started time.Time) (int, error) {
+	byCustomer := map[string][]gcItem{}
+	var order []string
+	for _, item := range items {
+		if _, seen := byCustomer[item.Customer]; !seen {
+			order = append(order, item.Customer)
+		}
+		byCustomer[item.Customer] = append(byCustomer[item.Customer], item)
+	}
+
+	removed := 0
+	for _, customerIDN := range order {
+		group := byCustomer[customerIDN]
+		apply := func() error {
+			var stale []string
+			for _, item := range group {
+				if item.Kind == gcStaleHash {
+					stale = append(stale, item.Path)
+					continue
+				}
+				if item.Kind == gcUnusedBlob && blobUsedSince(item.Path, started) {
+					continue
+				}
+				if err := os.RemoveAll(filepath.FromSlash(item.Path)); err != nil {
+					return fmt.Errorf("remove %s: %w", item.Path, err)
+				}
+				removed++
+			}
+			if len(stale) == 0 {
+				return nil
+			}
+			hashes, err := state.LoadHashes(customerIDN)
+			if err != nil {
+				return err
+			}
+			for _, path := range stale {
+				delete(hashes, path)
+			}
+			if err := state.SaveHashes(customerIDN, hashes); err != nil {
+				return err
+			}
+			removed += len(stale)
+			return nil
+		}
+		var err error
+		if customerIDN == "" {
+			err = apply()
+		} else {
+			err = withCustomerLock(c.console, customerIDN, "gc", false, apply)
+		}
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// scanCustomerGarbage finds leftovers in one customer's export tree and hash store: hashes of deleted
+// files, project directories missing from the project map, scripts left behind by a runner change,
+// conflict copies, and temporary files of interrupted writes. Skills and flows created locally but not
+// pushed yet are not reported.
+func scanCustomerGarbage(outputRoot, customerType, customerIDN string) ([]gcItem, error) {
+	projectMap, err := state.LoadProjectMap(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := state.LoadHashes(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []gcItem
+	for path := range hashes {
+		if _, err := os.Stat(filepath.FromSlash(path)); errors.Is(err, os.ErrNotExist) {
+			items = append(items, gcItem{Kind: gcStaleHash, Customer: customerIDN, Path: path})
+		}
+	}
+
+	slugs := map[string]bool{}
+	for projectIDN, projectData := range projectMap.Projects {
+		slug := projectSlugFromState(projectIDN, projectData)
+		slugs[slug] = true
+		projectDir := fsutil.ExportProjectDir(outputRoot, customerType, customerIDN, slug)
+		found, err := scanProjectGarbage(projectDir, outputRoot, customerType, customerIDN, slug, projectData)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range found {
+			items = append(items, gcItem{Kind: gcOrphanFile, Customer: customerIDN, Path: path})
+		}
+	}
+
+	// Integration exports share the output root with other customers, so only the per-customer
+	// layouts can tell an orphaned project directory apart.
+	if !strings.EqualFold(strings.TrimSpace(customerType), "integration") {
+		customerDir := filepath.Dir(fsutil.ExportProjectDir(outputRoot, customerType, customerIDN, "project"))
+		entries, err := os.ReadDir(customerDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read %s: %w", customerDir, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || slugs[entry.Name()] {
+				continue
+			}
+			items = append(items, gcItem{Kind: gcOrphanProject, Customer: customerIDN, Path: filepath.ToSlash(filepath.Join(customerDir, entry.Name()))})
+		}
+	}
+	return items, nil
+}
+
+// scanProjectGarbage returns the orphaned files inside one project directory.
+func scanProjectGarbage(projectDir, outputRoot, customerType, customerIDN, slug string, projectData state.ProjectData) ([]string, error) {
+	stale := map[string]bool{}
+	for agentIDN, agentData := range projectData.Agents {
+		for flowIDN, flowData := range agentData.Flows {
+			flowDir := fsutil.ExportFlowDir(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN)
+			entries, err := os.ReadDir(flowDir)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("read %s: %w", flowDir, err)
+			}
+			for _, entry := range entries {
+				name := entry.Name()
+				ext := strings.TrimPrefix(filepath.Ext(name), ".")
+				skill, known := flowData.Skills[strings.TrimSuffix(name, filepath.Ext(name))]
+				if entry.IsDir() || !known || !gcScriptExtensions[ext] {
+					continue
+				}
+				if ext != platform.ScriptExtension(skill.RunnerType) {
+					stale[filepath.Join(flowDir, name)] = true
+				}
+			}
+		}
+	}
+
+	var orphans []string
+	err := filepath.WalkDir(projectDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if stale[path] || strings.HasSuffix(name, remoteCopySuffix) || isInterruptedWrite(name) {
+			orphans = append(orphans, filepath.ToSlash(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", projectDir, err)
+	}
+	return orphans, nil
+}
+
+// isInterruptedWrite matches the temporary files of atomic writes and blob clones that were cut short.
+func isInterruptedWrite(name string) bool {
+	return strings.HasPrefix(name, ".") && (strings.Contains(name, ".tmp-") || strings.HasSuffix(name, ".blob-tmp"))
+}
+
+// scanSharedGarbage finds state directories of customers that are no longer configured and blobs
+// that no remaining customer's hash store refers to. Blobs written or reused since started are skipped.
+func scanSharedGarbage(known map[string]bool, started time.Time) ([]gcItem, error) {
+	entries, err := os.ReadDir(fsutil.StateDirName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", fsutil.StateDirName, err)
+	}
+
+	var items []gcItem
+	referenced := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == fsutil.LocksDirName || name == fsutil.BlobsDirName {
+			continue
+		}
+		if !known[strings.ToLower(name)] {
+			items = append(items, gcItem{Kind: gcOrphanState, Customer: name, Path: filepath.ToSlash(filepath.Join(fsutil.StateDirName, name))})
+			continue
+		}
+		hashes, err := state.LoadHashes(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range hashes {
+			referenced[hash] = true
+		}
+	}
+
+	err = filepath.WalkDir(fsutil.BlobsDir(), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && !referenced[d.Name()] && !blobUsedSince(path, started) {
+			items = append(items, gcItem{Kind: gcUnusedBlob, Path: filepath.ToSlash(path)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan blobs: %w", err)
+	}
+	return items, nil
+}
+
+// blobUsedSince reports whether the blob at path was written or reused at or after started. Blobs
+// that cannot be inspected are treated as in use.
+func blobUsedSince(path string, started time.Time) bool {
+	info, err := os.Stat(filepath.FromSlash(path))
+	if err != nil {
+		return !errors.Is(err, os.ErrNotExist)
+	}
+	return !info.ModTime().Before(started)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+func TestScanCustomerGarbage(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"support": {Agents: map[string]state.AgentData{
+			"agent": {Flows: map[string]state.FlowData{
+				"main": {Skills: map[string]state.SkillMetadataInfo{
+					"greet": {RunnerType: "guidance"},
+				}},
+			}},
+		}},
+	}}
+	if err := state.SaveProjectMap("acme", projectMap); err != nil {
+		t.Fatal(err)
+	}
+	flowDir := fsutil.ExportFlowDir("out", "", "acme", "support", "agent", "main")
+	files := []string{
+		"greet.guidance",          // current script
+		"greet.meta.yaml",         // current metadata
+		"greet.nsl",               // left behind by a runner change
+		"greet.guidance.remote",   // conflict copy
+		".greet.guidance.tmp-123", // interrupted atomic write
+		"draft.nsl",               // new skill awaiting push
+		"draft.meta.yaml",
+	}
+	for _, name := range files {
+		writeTestFile(t, filepath.Join(flowDir, name), "x")
+	}
+	writeTestFile(t, filepath.Join("out", "acme", "retired", "project.json"), "{}")
+	current := filepath.ToSlash(filepath.Join(flowDir, "greet.guidance"))
+	if err := state.SaveHashes("acme", state.HashStore{current: "h1", "out/acme/support/gone.nsl": "h2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := scanCustomerGarbage("out", "", "acme")
+	if err != nil {
+		t.Fatalf("scanCustomerGarbage: %v", err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Kind+" "+item.Path)
+	}
+	sort.Strings(got)
+	want := []string{
+		gcOrphanFile + " " + filepath.ToSlash(filepath.Join(flowDir, ".greet.guidance.tmp-123")),
+		gcOrphanFile + " " + filepath.ToSlash(filepath.Join(flowDir, "greet.guidance.remote")),
+		gcOrphanFile + " " + filepath.ToSlash(filepath.Join(flowDir, "greet.nsl")),
+		gcOrphanProject + " out/acme/retired",
+		gcStaleHash + " out/acme/support/gone.nsl",
+	}
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("unexpected garbage:\n got %v\nwant %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected garbage:\n got %v\nwant %v", got, want)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGCKeepsUnusedBlobsUsedSinceStart(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	started := time.Now()
+	old := filepath.Join(fsutil.BlobsDir(), "aa", "aa11")
+	fresh := filepath.Join(fsutil.BlobsDir(), "bb", "bb22")
+	writeTestFile(t, old, "old")
+	writeTestFile(t, fresh, "fresh")
+	past := started.Add(-time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := scanSharedGarbage(map[string]bool{}, started)
+	if err != nil {
+		t.Fatalf("scanSharedGarbage: %v", err)
+	}
+	if len(items) != 1 || items[0].Kind != gcUnusedBlob || items[0].Path != filepath.ToSlash(old) {
+		t.Fatalf("expected only the old blob to be unused, got %+v", items)
+	}
+
+	// A pull that reuses the blob after the scan keeps it from being removed.
+	if err := os.Chtimes(old, started, started); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := NewGCCommand(io.Discard, io.Discard).remove(items, started)
+	if err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if removed != 0 {
+		t.Fatalf("expected no blob to be removed, got %d", removed)
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Fatalf("expected the reused blob to be kept: %v", err)
+	}
+}
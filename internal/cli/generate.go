@@ -73,15 +73,15 @@ func (c *GenerateCommand) Run(ctx context.Context, _ []string) error {
 	}
 
 	// 3. Parse the generated code to validate it and build an AST
-	l := lexer.New(generatedCode)
+	l := lexer.NewTemplate(generatedCode)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.Errors()) > 0 {
 		return fmt.Errorf("failed to parse generated NSL code: %v\n--- Generated Code ---\n%s", p.Errors(), generatedCode)
 	}
 
-	// 4. Convert the validated AST back to clean, formatted NSL code
-	prettyPrinter := printer.New()
+	// 4. Convert the validated AST back to NSL with canonical tags; text between tags is kept as generated
+	prettyPrinter := printer.NewTemplate()
 	finalNSL := prettyPrinter.Print(program)
 
 	_, err = fmt.Fprintln(c.stdout, finalNSL)
@@ -0,0 +1,397 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// scriptExtensions are the file extensions skill scripts are exported with.
+var scriptExtensions = map[string]bool{
+	"." + platform.ScriptExtension("nsl"):      true,
+	"." + platform.ScriptExtension("guidance"): true,
+	"." + platform.ScriptExtension(""):         true,
+}
+
+// GrepCommand searches the scripts of skills, in the workspace or on the platform.
+type GrepCommand struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	console    *console.Writer
+	customer   *string
+	project    *string
+	flow       *string
+	skill      *string
+	ignoreCase *bool
+	fixed      *bool
+	remote     *bool
+	list       *bool
+	format     *string
+}
+
+// grepMatch is one line of a skill script that matches the pattern.
+type grepMatch struct {
+	Customer string `json:"customer"`
+	Project  string `json:"project"`
+	Agent    string `json:"agent"`
+	Flow     string `json:"flow"`
+	Skill    string `json:"skill"`
+	Path     string `json:"path,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Text     string `json:"text"`
+}
+
+// NewGrepCommand constructs a grep command.
+func NewGrepCommand(stdout, stderr io.Writer) *GrepCommand {
+	return &GrepCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *GrepCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *GrepCommand) Name() string {
+	return "grep"
+}
+
+func (c *GrepCommand) Summary() string {
+	return "Search skill scripts for a regular expression"
+}
+
+func (c *GrepCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to search")
+	c.project = fs.String("project", "", "only search skills of this project IDN")
+	c.flow = fs.String("flow", "", "only search skills of this flow IDN")
+	c.skill = fs.String("skill", "", "only search the skill with this IDN")
+	c.ignoreCase = fs.Bool("ignore-case", false, "match letters regardless of case")
+	c.fixed = fs.Bool("fixed", false, "treat the pattern as literal text instead of a regular expression")
+	c.remote = fs.Bool("remote", false, "search the scripts on the platform instead of the workspace")
+	c.list = fs.Bool("list", false, "print only the skills that match, not the matching lines")
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *GrepCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo grep <pattern> [--customer <idn>] [--project <idn>] [--flow <idn>] [--skill <idn>] [--ignore-case] [--fixed] [--remote] [--list] [--format text|json]"
+	if len(args) != 1 || args[0] == "" {
+		return errors.New(usage)
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+	pattern, err := grepPattern(args[0], c.fixed != nil && *c.fixed, c.ignoreCase != nil && *c.ignoreCase)
+	if err != nil {
+		return err
+	}
+	filter := grepFilter{project: flagValue(c.project), flow: flagValue(c.flow), skill: flagValue(c.skill)}
+	customerFilter := flagValue(c.customer)
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	matches := []grepMatch{}
+	matched := false
+	processed := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		var found []grepMatch
+		if c.remote != nil && *c.remote {
+			sess, err := session.New(ctx, env, entry, registry)
+			if err != nil {
+				return err
+			}
+			if sess.RegistryUpdated {
+				if err := registry.Save(); err != nil {
+					return err
+				}
+			}
+			if customerFilter != "" && !matchesCustomerToken(entry, sess.IDN, customerFilter) {
+				continue
+			}
+			matched = true
+			if processed[strings.ToLower(sess.IDN)] {
+				continue
+			}
+			processed[strings.ToLower(sess.IDN)] = true
+			if found, err = grepRemote(ctx, sess.Client, sess.IDN, pattern, filter); err != nil {
+				return err
+			}
+		} else {
+			// The workspace is searched without logging in, so only customers pulled before are known.
+			idn := strings.TrimSpace(entry.HintIDN)
+			if idn == "" {
+				idn, _ = registry.Lookup(entry.APIKey)
+			}
+			if idn == "" || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+				continue
+			}
+			matched = true
+			if processed[strings.ToLower(idn)] {
+				continue
+			}
+			processed[strings.ToLower(idn)] = true
+			if found, err = grepWorkspace(env.OutputRoot, entry.Type, idn, pattern, filter); err != nil {
+				return err
+			}
+		}
+		matches = append(matches, found...)
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	list := c.list != nil && *c.list
+	if list {
+		matches = firstMatchPerSkill(matches)
+	}
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(matches); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+	} else {
+		c.printMatches(matches, list)
+	}
+	// Like grep, finding nothing is a failure, so scripts can test for a pattern.
+	if len(matches) == 0 {
+		return newSilentExitError(exitGeneric)
+	}
+	return nil
+}
+
+// grepPattern compiles the search pattern, quoting it first when it is literal text.
+func grepPattern(pattern string, fixed, ignoreCase bool) (*regexp.Regexp, error) {
+	if fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// grepFilter limits a search to one project, flow, or skill; empty fields match everything.
+type grepFilter struct {
+	project string
+	flow    string
+	skill   string
+}
+
+// grepFieldMatches reports whether an IDN passes one field of a grepFilter.
+func grepFieldMatches(value, want string) bool {
+	return want == "" || strings.EqualFold(value, want)
+}
+
+// workspaceScript is a skill script found in the workspace.
+type workspaceScript struct {
+	Project string
+	Agent   string
+	Flow    string
+	Skill   string
+	Path    string
+}
+
+// workspaceScripts lists the skill scripts in the flows recorded in a customer's project map that pass
+// the filter, including scripts that were added locally and not pushed yet.
+func workspaceScripts(outputRoot, customerType, customerIDN string, filter grepFilter) ([]workspaceScript, error) {
+	projectMap, err := state.LoadProjectMap(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+
+	var scripts []workspaceScript
+	for _, projectIDN := range sortedKeys(projectMap.Projects) {
+		if !grepFieldMatches(projectIDN, filter.project) {
+			continue
+		}
+		projectData := projectMap.Projects[projectIDN]
+		slug := projectSlugFromState(projectIDN, projectData)
+		for _, agentIDN := range sortedKeys(projectData.Agents) {
+			for _, flowIDN := range sortedKeys(projectData.Agents[agentIDN].Flows) {
+				if !grepFieldMatches(flowIDN, filter.flow) {
+					continue
+				}
+				flowDir := fsutil.ExportFlowDir(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN)
+				entries, err := os.ReadDir(flowDir)
+				if err != nil {
+					if os.IsNotExist(err) {
+						continue
+					}
+					return nil, fmt.Errorf("read %s: %w", flowDir, err)
+				}
+				for _, entry := range entries {
+					ext := filepath.Ext(entry.Name())
+					skillIDN := strings.TrimSuffix(entry.Name(), ext)
+					if entry.IsDir() || !scriptExtensions[ext] || !grepFieldMatches(skillIDN, filter.skill) {
+						continue
+					}
+					scripts = append(scripts, workspaceScript{
+						Project: projectIDN,
+						Agent:   agentIDN,
+						Flow:    flowIDN,
+						Skill:   skillIDN,
+						Path:    filepath.Join(flowDir, entry.Name()),
+					})
+				}
+			}
+		}
+	}
+	return scripts, nil
+}
+
+// grepWorkspace searches the skill scripts of a customer's workspace.
+func grepWorkspace(outputRoot, customerType, customerIDN string, pattern *regexp.Regexp, filter grepFilter) ([]grepMatch, error) {
+	scripts, err := workspaceScripts(outputRoot, customerType, customerIDN, filter)
+	if err != nil {
+		return nil, err
+	}
+	var matches []grepMatch
+	for _, script := range scripts {
+		content, err := os.ReadFile(script.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", script.Path, err)
+		}
+		base := grepMatch{Customer: customerIDN, Project: script.Project, Agent: script.Agent, Flow: script.Flow, Skill: script.Skill, Path: filepath.ToSlash(script.Path)}
+		matches = append(matches, grepScript(base, string(content), pattern)...)
+	}
+	return matches, nil
+}
+
+// grepRemote searches the scripts of every skill on the platform, whether or not it was pulled.
+func grepRemote(ctx context.Context, client *platform.Client, customerIDN string, pattern *regexp.Regexp, filter grepFilter) ([]grepMatch, error) {
+	projects, err := client.ListProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].IDN < projects[j].IDN })
+
+	var matches []grepMatch
+	for _, project := range projects {
+		if !grepFieldMatches(project.IDN, filter.project) {
+			continue
+		}
+		agents, err := client.ListAgents(ctx, project.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list agents for %s: %w", project.IDN, err)
+		}
+		sort.Slice(agents, func(i, j int) bool { return agents[i].IDN < agents[j].IDN })
+		for _, agent := range agents {
+			flows := agent.Flows
+			sort.Slice(flows, func(i, j int) bool { return flows[i].IDN < flows[j].IDN })
+			for _, flow := range flows {
+				if !grepFieldMatches(flow.IDN, filter.flow) {
+					continue
+				}
+				var found []grepMatch
+				err := client.EachFlowSkill(ctx, flow.ID, func(skill platform.Skill) error {
+					if grepFieldMatches(skill.IDN, filter.skill) {
+						base := grepMatch{Customer: customerIDN, Project: project.IDN, Agent: agent.IDN, Flow: flow.IDN, Skill: skill.IDN}
+						found = append(found, grepScript(base, skill.PromptScript, pattern)...)
+					}
+					return nil
+				})
+				if err != nil {
+					return nil, fmt.Errorf("list skills for %s/%s: %w", project.IDN, flow.IDN, err)
+				}
+				sort.SliceStable(found, func(i, j int) bool { return found[i].Skill < found[j].Skill })
+				matches = append(matches, found...)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// grepScript returns the lines of script that match the pattern, with the column of the first match
+// counted in characters.
+func grepScript(base grepMatch, script string, pattern *regexp.Regexp) []grepMatch {
+	var matches []grepMatch
+	for i, line := range strings.Split(script, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		loc := pattern.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		match := base
+		match.Line = i + 1
+		match.Column = utf8.RuneCountInString(line[:loc[0]]) + 1
+		match.Text = line
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// firstMatchPerSkill keeps the first matching line of each skill.
+func firstMatchPerSkill(matches []grepMatch) []grepMatch {
+	seen := map[string]bool{}
+	kept := []grepMatch{}
+	for _, match := range matches {
+		key := strings.Join([]string{match.Customer, match.Project, match.Agent, match.Flow, match.Skill}, "/")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, match)
+	}
+	return kept
+}
+
+func (c *GrepCommand) printMatches(matches []grepMatch, list bool) {
+	if len(matches) == 0 {
+		c.console.Info("No matches found.")
+		return
+	}
+	for idx, match := range matches {
+		if idx == 0 || matches[idx-1].Customer != match.Customer {
+			c.console.Section(fmt.Sprintf("Matches %s", strings.ToUpper(match.Customer)))
+		}
+		skill := strings.Join([]string{match.Project, match.Agent, match.Flow, match.Skill}, "/")
+		if list {
+			c.console.RawLine("%s", skill)
+			continue
+		}
+		c.console.RawLine("%s:%d:%d: %s", skill, match.Line, match.Column, match.Text)
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+func TestGrepScriptReportsLineAndColumn(t *testing.T) {
+	pattern, err := grepPattern("user.old_attr", true, true)
+	if err != nil {
+		t.Fatalf("grepPattern: %v", err)
+	}
+	base := grepMatch{Project: "shop", Skill: "Greeting"}
+	matches := grepScript(base, "Hello\r\n  café {{ User.Old_Attr }}\nuserXold_attr\n", pattern)
+	if len(matches) != 1 {
+		t.Fatalf("expected one match, got %+v", matches)
+	}
+	if got := matches[0]; got.Line != 2 || got.Column != 11 || got.Text != "  café {{ User.Old_Attr }}" || got.Skill != "Greeting" {
+		t.Fatalf("unexpected match: %+v", got)
+	}
+
+	if _, err := grepPattern("(", false, false); err == nil {
+		t.Fatalf("expected an invalid pattern to fail")
+	}
+}
+
+func TestGrepWorkspaceFindsSkillsOfMappedFlows(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"shop": {ProjectID: "p1", Path: "shop", Agents: map[string]state.AgentData{
+			"bot": {ID: "a1", Flows: map[string]state.FlowData{
+				"main":  {ID: "f1"},
+				"other": {ID: "f2"},
+			}},
+		}},
+	}}
+	if err := state.SaveProjectMap("acme", projectMap); err != nil {
+		t.Fatalf("save project map: %v", err)
+	}
+	for path, content := range map[string]string{
+		"Greeting.nsl":       "{{ old_attr }}",
+		"Greeting.meta.yaml": "idn: Greeting\ndescription: old_attr\n",
+		"Draft.guidance":     "Use old_attr here",
+	} {
+		writeTestFile(t, fsutil.ExportSkillScriptPath("out", "integration", "acme", "shop", "bot", "main", path), content)
+	}
+	writeTestFile(t, fsutil.ExportSkillScriptPath("out", "integration", "acme", "shop", "bot", "other", "Farewell.nsl"), "old_attr")
+
+	pattern, err := grepPattern("old_attr", false, false)
+	if err != nil {
+		t.Fatalf("grepPattern: %v", err)
+	}
+	matches, err := grepWorkspace("out", "integration", "acme", pattern, grepFilter{flow: "MAIN"})
+	if err != nil {
+		t.Fatalf("grepWorkspace: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Skill != "Draft" || matches[1].Skill != "Greeting" {
+		t.Fatalf("expected Draft and Greeting in main, got %+v", matches)
+	}
+	if matches[1].Project != "shop" || matches[1].Agent != "bot" || matches[1].Flow != "main" || matches[1].Path == "" {
+		t.Fatalf("unexpected location: %+v", matches[1])
+	}
+}
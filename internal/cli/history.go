@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// HistoryCommand lists the saved platform versions of a skill and diffs or restores one of them locally.
+type HistoryCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	diff     *string
+	restore  *string
+	limit    *int
+	force    *bool
+
+	prompts
+}
+
+// NewHistoryCommand constructs a history command.
+func NewHistoryCommand(stdout, stderr io.Writer) *HistoryCommand {
+	return &HistoryCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *HistoryCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *HistoryCommand) Name() string {
+	return "history"
+}
+
+func (c *HistoryCommand) Summary() string {
+	return "List, diff, or restore previous platform versions of a skill"
+}
+
+func (c *HistoryCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias owning the skill")
+	c.diff = fs.String("diff", "", "show the changes from this version to the local script")
+	c.restore = fs.String("restore", "", "write this version to the local script")
+	c.limit = fs.Int("limit", 20, "maximum number of versions to list (0 for all)")
+	c.force = fs.Bool("force", false, "restore without asking even if the local script has unpushed changes")
+}
+
+func (c *HistoryCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo history <skill path> [--diff <version> | --restore <version>] [--customer <idn>] [--limit <n>] [--force]"
+	diffVersion := flagValue(c.diff)
+	restoreVersion := flagValue(c.restore)
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" || (diffVersion != "" && restoreVersion != "") {
+		return errors.New(usage)
+	}
+	sess, found, err := locateSkill(ctx, args[0], flagValue(c.customer))
+	if err != nil {
+		return err
+	}
+
+	versions, err := sess.Client.ListSkillVersions(ctx, found.SkillID)
+	if err != nil {
+		return fmt.Errorf("list versions of %s: %w", found.SkillIDN, err)
+	}
+	switch {
+	case diffVersion != "":
+		return c.showDiff(ctx, sess.Client, found, versions, diffVersion)
+	case restoreVersion != "":
+		return c.restoreVersion(ctx, sess.Client, sess.IDN, found, versions, restoreVersion)
+	default:
+		c.printVersions(found, versions)
+		return nil
+	}
+}
+
+// locateSkill resolves a local skill script or metadata file to its remote skill and logs in as the
+// customer owning it. Other customers are only matched against their local project maps.
+func locateSkill(ctx context.Context, path, customerFilter string) (*session.Session, designerTarget, error) {
+	target, err := filepath.Abs(userPath(path))
+	if err != nil {
+		return nil, designerTarget{}, fmt.Errorf("resolve %s: %w", path, err)
+	}
+	env, err := config.LoadEnv()
+	if err != nil {
+		return nil, designerTarget{}, err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return nil, designerTarget{}, err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return nil, designerTarget{}, err
+	}
+
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+			continue
+		}
+		projectMap, err := state.LoadProjectMap(idn)
+		if err != nil {
+			return nil, designerTarget{}, err
+		}
+		found, ok := locateDesignerTarget(target, env.OutputRoot, entry.Type, idn, projectMap)
+		if !ok {
+			continue
+		}
+		if found.SkillIDN == "" {
+			return nil, designerTarget{}, fmt.Errorf("%s is not a skill script", filepath.ToSlash(path))
+		}
+		if found.SkillID == "" {
+			return nil, designerTarget{}, fmt.Errorf("skill %s has no remote identifier; push it first", found.SkillIDN)
+		}
+
+		sess, err := session.New(ctx, env, entry, registry)
+		if err != nil {
+			return nil, designerTarget{}, err
+		}
+		if sess.RegistryUpdated {
+			if err := registry.Save(); err != nil {
+				return nil, designerTarget{}, err
+			}
+		}
+		return sess, found, nil
+	}
+	return nil, designerTarget{}, fmt.Errorf("%s does not belong to a pulled project", filepath.ToSlash(path))
+}
+
+func (c *HistoryCommand) printVersions(found designerTarget, versions []platform.SkillVersion) {
+	if len(versions) == 0 {
+		c.console.Info("No saved versions of %s.", found.SkillIDN)
+		return
+	}
+	shown := versions
+	if c.limit != nil && *c.limit > 0 && len(shown) > *c.limit {
+		shown = shown[:*c.limit]
+	}
+	c.console.Section(fmt.Sprintf("History %s/%s/%s", found.ProjectIDN, found.FlowIDN, found.SkillIDN))
+	rows := make([][]string, 0, len(shown))
+	for _, version := range shown {
+		rows = append(rows, []string{strconv.Itoa(version.Version), version.ID, orDash(version.CreatedAt), orDash(version.CreatedBy), orDash(version.Comment)})
+	}
+	writeTable(c.console, []string{"VERSION", "ID", "CREATED", "AUTHOR", "COMMENT"}, rows)
+	if len(shown) < len(versions) {
+		c.console.Info("%d older version(s) not shown; use --limit 0 to list all.", len(versions)-len(shown))
+	}
+}
+
+func (c *HistoryCommand) showDiff(ctx context.Context, client *platform.Client, found designerTarget, versions []platform.SkillVersion, selector string) error {
+	version, err := fetchSkillVersion(ctx, client, found.SkillID, versions, selector)
+	if err != nil {
+		return err
+	}
+	local, err := os.ReadFile(found.ScriptPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", filepath.ToSlash(found.ScriptPath), err)
+	}
+	lines := diff.Generate([]byte(version.PromptScript), local, 3)
+	if len(lines) == 0 {
+		c.console.Info("%s matches version %d.", filepath.ToSlash(found.ScriptPath), version.Version)
+		return nil
+	}
+	c.console.RawLine("%s", diff.Format(filepath.ToSlash(found.ScriptPath), lines))
+	return nil
+}
+
+func (c *HistoryCommand) restoreVersion(ctx context.Context, client *platform.Client, customerIDN string, found designerTarget, versions []platform.SkillVersion, selector string) error {
+	version, err := fetchSkillVersion(ctx, client, found.SkillID, versions, selector)
+	if err != nil {
+		return err
+	}
+	normalized := filepath.ToSlash(found.ScriptPath)
+	local, err := os.ReadFile(found.ScriptPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", normalized, err)
+	}
+	if string(local) == version.PromptScript {
+		c.console.Info("%s already matches version %d.", normalized, version.Version)
+		return nil
+	}
+
+	if c.force == nil || !*c.force {
+		hashes, err := state.LoadHashes(customerIDN)
+		if err != nil {
+			return err
+		}
+		if stored, tracked := hashes[normalized]; tracked && local != nil && util.ContentHash(local) != stored {
+			c.console.Warn("%s has local changes that were not pushed; restoring discards them.", normalized)
+			text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Restore version %d into %s? [y/N]: ", version.Version, normalized))
+			if err != nil {
+				return err
+			}
+			if text != "y" {
+				c.console.Info("Skipping.")
+				return nil
+			}
+		}
+	}
+
+	if err := fsutil.EnsureParentDir(found.ScriptPath); err != nil {
+		return err
+	}
+	if err := fsutil.AtomicWrite(found.ScriptPath, []byte(version.PromptScript), fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write %s: %w", normalized, err)
+	}
+	c.console.Success("Restored version %d into %s. Run `newo push` to upload it.", version.Version, normalized)
+	return nil
+}
+
+// fetchSkillVersion resolves a version number or ID against the listing and fetches its script.
+func fetchSkillVersion(ctx context.Context, client *platform.Client, skillID string, versions []platform.SkillVersion, selector string) (platform.SkillVersion, error) {
+	for _, version := range versions {
+		if version.ID == selector || strconv.Itoa(version.Version) == selector {
+			if version.PromptScript != "" {
+				return version, nil
+			}
+			full, err := client.GetSkillVersion(ctx, skillID, version.ID)
+			if err != nil {
+				return platform.SkillVersion{}, fmt.Errorf("get version %s: %w", selector, err)
+			}
+			return full, nil
+		}
+	}
+	return platform.SkillVersion{}, fmt.Errorf("version %s not found; run `newo history <path>` to list versions", selector)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Built-in hooks accepted in the [hooks] section of newo.toml; any other entry runs as a shell command.
+const (
+	hookLint     = "lint"
+	hookValidate = "validate"
+)
+
+// runPrePushHooks executes the configured hooks in order and stops at the first failure.
+// Lint warnings do not fail a hook; only lint errors do.
+func runPrePushHooks(ctx context.Context, hooks []string, customerToken string, stdout, stderr io.Writer) error {
+	for _, hook := range hooks {
+		var err error
+		switch hook {
+		case hookLint:
+			cmd := NewLintCommand(stdout, stderr)
+			cmd.customer = &customerToken
+			cmd.errorsOnly = true
+			err = cmd.Run(ctx, nil)
+		case hookValidate:
+			cmd := NewValidateCommand(stdout, stderr)
+			cmd.customer = &customerToken
+			err = cmd.Run(ctx, nil)
+		default:
+			err = runShellHook(ctx, hook, customerToken, stdout, stderr)
+		}
+		if err != nil {
+			var exitErr exitError
+			if errors.As(err, &exitErr) && exitErr.Silent() {
+				return fmt.Errorf("pre-push hook %q reported problems; push aborted (use --no-hooks to skip hooks)", hook)
+			}
+			return fmt.Errorf("pre-push hook %q failed: %w; push aborted (use --no-hooks to skip hooks)", hook, err)
+		}
+	}
+	return nil
+}
+
+func runShellHook(ctx context.Context, command, customerToken string, stdout, stderr io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(), "NEWO_HOOK_CUSTOMER="+customerToken)
+	return cmd.Run()
+}
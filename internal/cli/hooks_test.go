@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunPrePushHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell hooks use sh")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("NEWO_OUTPUT_ROOT", "projects")
+
+	scriptDir := filepath.Join(dir, "projects", "demo")
+	if err := os.MkdirAll(scriptDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	script := filepath.Join(scriptDir, "skill.nsl")
+	// A trailing-whitespace warning must not block the push.
+	if err := os.WriteFile(script, []byte("{{ true }} \n"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	if err := runPrePushHooks(context.Background(), []string{hookLint}, "", io.Discard, io.Discard); err != nil {
+		t.Fatalf("expected lint hook to pass, got %v", err)
+	}
+
+	marker := filepath.Join(dir, "ran")
+	if err := runPrePushHooks(context.Background(), []string{"echo $NEWO_HOOK_CUSTOMER > " + marker}, "acme", io.Discard, io.Discard); err != nil {
+		t.Fatalf("expected shell hook to pass, got %v", err)
+	}
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("expected shell hook to run: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "acme" {
+		t.Fatalf("expected customer to be exported to the hook, got %q", string(data))
+	}
+
+	if err := os.WriteFile(script, []byte("{% if true %}\n"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	err = runPrePushHooks(context.Background(), []string{hookLint, "touch " + marker + ".late"}, "", io.Discard, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), `"lint"`) {
+		t.Fatalf("expected lint hook failure, got %v", err)
+	}
+	if _, statErr := os.Stat(marker + ".late"); !os.IsNotExist(statErr) {
+		t.Fatalf("expected hooks after a failure to be skipped")
+	}
+
+	if err := runPrePushHooks(context.Background(), []string{"exit 3"}, "", io.Discard, io.Discard); err == nil {
+		t.Fatalf("expected failing shell hook to abort")
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/logging"
+	"github.com/twinmind/newo-tool/internal/platform"
+)
+
+// configureHTTP applies the [http] settings from newo.toml to the platform client and returns the
+// deadline for the whole command, zero when there is none. Disabling certificate verification is
+// announced on every run so it is not left on by accident.
+func configureHTTP(stderr io.Writer) (time.Duration, error) {
+	var cfg config.TomlConfig
+	data, err := os.ReadFile(config.TomlPath())
+	if err == nil {
+		_, _ = toml.Decode(string(data), &cfg)
+	}
+	var requestTimeout, operationTimeout, idleConnTimeout time.Duration
+	for _, setting := range []struct {
+		name   string
+		raw    string
+		target *time.Duration
+	}{
+		{"request_timeout", cfg.HTTP.RequestTimeout, &requestTimeout},
+		{"operation_timeout", cfg.HTTP.OperationTimeout, &operationTimeout},
+		{"idle_conn_timeout", cfg.HTTP.IdleConnTimeout, &idleConnTimeout},
+	} {
+		raw := strings.TrimSpace(setting.raw)
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("%s [http] %s: expected a duration such as \"30s\", got %q", config.TomlPath(), setting.name, setting.raw)
+		}
+		*setting.target = value
+	}
+
+	opts := platform.TransportOptions{
+		Proxy:               cfg.HTTP.Proxy,
+		CABundle:            cfg.HTTP.CABundle,
+		InsecureSkipVerify:  cfg.HTTP.InsecureSkipVerify,
+		RequestTimeout:      requestTimeout,
+		MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.HTTP.MaxConnsPerHost,
+		IdleConnTimeout:     idleConnTimeout,
+		DisableKeepAlives:   cfg.HTTP.DisableKeepAlives,
+		CompressRequests:    cfg.HTTP.CompressRequests,
+	}
+	if err := platform.ConfigureTransport(opts); err != nil {
+		return 0, fmt.Errorf("%s [http]: %w", config.TomlPath(), err)
+	}
+	if opts.InsecureSkipVerify {
+		logging.Warn("TLS certificate verification is disabled", "setting", "http.insecure_skip_verify")
+		_, _ = fmt.Fprintln(stderr, "WARNING: TLS certificate verification is disabled ([http] insecure_skip_verify in newo.toml).")
+		_, _ = fmt.Fprintln(stderr, "WARNING: Anyone on the network path can read and alter traffic to the platform, including API keys.")
+	}
+	return operationTimeout, nil
+}
+
+// concurrencyUsage is the help text of the --concurrency flag of pull and push.
+const concurrencyUsage = "parallel platform requests at each level (default: [performance] in newo.toml)"
+
+// performanceLimits applies --concurrency to the [performance] limits from newo.toml.
+func performanceLimits(env config.Env, concurrency *int) (config.PerformanceConfig, error) {
+	if concurrency == nil || *concurrency == 0 {
+		return env.Performance, nil
+	}
+	if *concurrency < 0 {
+		return config.PerformanceConfig{}, fmt.Errorf("--concurrency must be at least 1, got %d", *concurrency)
+	}
+	return env.Performance.WithConcurrency(*concurrency), nil
+}
+
+// startCassette records platform traffic to a cassette or replays it from one, as asked by --record
+// or --replay. The returned function ends the recording and saves it; replaying needs no cleanup
+// beyond restoring the transport.
+func startCassette(record, replay string) (func() error, error) {
+	record, replay = strings.TrimSpace(record), strings.TrimSpace(replay)
+	switch {
+	case record != "" && replay != "":
+		return nil, errors.New("--record and --replay cannot be used together")
+	case record != "":
+		path := userPath(record)
+		stop := platform.StartRecording(path)
+		return func() error {
+			if err := stop(); err != nil {
+				return fmt.Errorf("save cassette %s: %w", record, err)
+			}
+			return nil
+		}, nil
+	case replay != "":
+		stop, err := platform.StartReplay(userPath(replay))
+		if err != nil {
+			return nil, fmt.Errorf("load cassette: %w", err)
+		}
+		return func() error {
+			stop()
+			return nil
+		}, nil
+	}
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
+)
+
+func TestRecordAndReplayPull(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "mock-customer"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN:    "main",
+			Skills: []*httpmock.Skill{{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello"}},
+		}}}}}},
+	}, "")
+	online, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(online))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"mock-customer\"\napi_key = \"sk-live-0123456789abcdefghij\"\n  [[customers.projects]]\n  idn = \"shop\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+	if err := New(&bytes.Buffer{}, &stderr).Execute(context.Background(), []string{"--record", "pull.json", "pull"}); err != nil {
+		t.Fatalf("recorded pull: %v\n%s", err, stderr.String())
+	}
+	cassette, err := os.ReadFile("pull.json")
+	if err != nil {
+		t.Fatalf("expected a cassette: %v", err)
+	}
+	if strings.Contains(string(cassette), "mock-access") || strings.Contains(string(cassette), "sk-live") {
+		t.Fatalf("expected secrets to be scrubbed from the cassette:\n%s", cassette)
+	}
+
+	scriptPath := filepath.Join("mock-customer", "shop", "bot", "flows", "main", "greet.nsl")
+	if err := os.RemoveAll("mock-customer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(".newo"); err != nil {
+		t.Fatal(err)
+	}
+	offline, transport := httpmock.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected network request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(platform.SetHTTPClientForTesting(offline))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	if err := New(&bytes.Buffer{}, &stderr).Execute(context.Background(), []string{"pull", "--replay", "pull.json"}); err != nil {
+		t.Fatalf("replayed pull: %v\n%s", err, stderr.String())
+	}
+	if content, err := os.ReadFile(scriptPath); err != nil || string(content) != "Hello" {
+		t.Fatalf("expected the replayed pull to write the script, got %q, %v", content, err)
+	}
+
+	if err := New(&bytes.Buffer{}, &stderr).Execute(context.Background(), []string{"pull", "--record", "a.json", "--replay", "pull.json"}); err == nil {
+		t.Fatalf("expected --record and --replay together to be rejected")
+	}
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/archive"
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/deploy"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/templatevars"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// ImportCommand deploys a project archive produced by `newo export` into a target customer.
+type ImportCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+
+	verbose    *bool
+	target     *string
+	confirmIDN *string
+
+	prompts
+}
+
+// NewImportCommand constructs an import command.
+func NewImportCommand(stdout, stderr io.Writer) *ImportCommand {
+	return &ImportCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *ImportCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *ImportCommand) Name() string {
+	return "import"
+}
+
+func (c *ImportCommand) Summary() string {
+	return "Deploy a project archive into a target customer"
+}
+
+func (c *ImportCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.verbose = fs.Bool("verbose", false, "enable verbose logging")
+	c.target = fs.String("to", "", "target customer IDN or alias")
+	c.confirmIDN = fs.String("confirm-customer", "", "IDN of a protected target customer, confirming the import into it")
+}
+
+func (c *ImportCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	targetCustomerIDN := ""
+	if c.target != nil {
+		targetCustomerIDN = strings.TrimSpace(*c.target)
+	}
+	if len(args) != 1 || targetCustomerIDN == "" {
+		return fmt.Errorf("usage: newo import <archive.tar.gz> --to <target_customer_idn> [--confirm-customer <idn>]")
+	}
+	verbose := c.verbose != nil && *c.verbose
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+
+	targetEntry, err := cfg.FindCustomer(targetCustomerIDN)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(targetEntry.Type, "integration") {
+		return fmt.Errorf("target customer %s must not have type integration", targetEntry.HintIDN)
+	}
+	// Importing creates or overwrites the project on the platform, so confirm a protected target first.
+	if err := confirmProtectedCustomer(c.console, c.prompt(), *targetEntry, targetEntry.HintIDN, flagValue(c.confirmIDN)); err != nil {
+		return err
+	}
+
+	file, err := os.Open(userPath(args[0]))
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	extractDir, err := os.MkdirTemp("", "newo-import-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(extractDir)
+	}()
+
+	manifest, err := archive.Extract(file, extractDir)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(manifest.SourceCustomerType, "integration") {
+		return fmt.Errorf("archive was exported from a %q customer; only integration projects can be imported", manifest.SourceCustomerType)
+	}
+	if verbose {
+		c.console.Info("Archive: project %s from %s (tool %s, %d files)", manifest.ProjectIDN, manifest.SourceCustomer, manifest.ToolVersion, len(manifest.Hashes))
+	}
+
+	projectPlan, err := deploy.LoadProjectFromDir(extractDir, manifest.ProjectSlug, manifest.ProjectIDN, manifest.Project)
+	if err != nil {
+		return err
+	}
+
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	targetSession, err := session.New(ctx, env, *targetEntry, registry)
+	if err != nil {
+		return err
+	}
+
+	releaseLock, err := fsutil.AcquireLock(targetSession.IDN, "deploy")
+	if err != nil {
+		return lockError(err)
+	}
+	defer func() {
+		if err := releaseLock(); err != nil && verbose {
+			c.console.Warn("Release lock: %v", err)
+		}
+	}()
+
+	result, err := deploy.NewService(targetSession.Client).Deploy(ctx, deploy.DeployRequest{
+		Project:            projectPlan,
+		TargetCustomerIDN:  targetSession.IDN,
+		TargetCustomerType: targetSession.CustomerType,
+		OutputRoot:         env.OutputRoot,
+		WorkspaceDir:       ".",
+		Reporter:           consoleReporter{writer: c.console},
+		Vars:               templatevars.ForCustomer(targetSession.IDN, targetSession.CustomerType, targetEntry.Vars),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := config.AddProjectToToml(config.TomlPath(), targetSession.IDN, manifest.ProjectIDN, result.ProjectID); err != nil {
+		return fmt.Errorf("update newo.toml: %w", err)
+	}
+
+	if targetSession.RegistryUpdated {
+		if err := registry.Save(); err != nil && verbose {
+			c.console.Warn("Save API key registry: %v", err)
+		}
+	}
+
+	c.console.Success("Project %s imported into %s (ID %s)", manifest.ProjectIDN, targetSession.IDN, result.ProjectID)
+	return nil
+}
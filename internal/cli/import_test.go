@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestImportCommandConfirmsProtectedTarget(t *testing.T) {
+	toml := buildCustomersToml(
+		tomlCustomer{idn: "prod-customer", apiKey: "prod-key", customerType: "", projects: []string{"test-project"}, protected: true},
+	)
+	restore := mustChdir(t, createTempNewoToml(t, toml))
+	defer restore()
+
+	run := func(confirm string) error {
+		var stdout, stderr bytes.Buffer
+		cmd := NewImportCommand(&stdout, &stderr)
+		cmd.SetPrompter(fixedPrompter{answer: "y"})
+		fs := flag.NewFlagSet("import", flag.ContinueOnError)
+		cmd.RegisterFlags(fs)
+		_ = fs.Set("to", "prod-customer")
+		_ = fs.Set("confirm-customer", confirm)
+		return cmd.Run(context.Background(), []string{"missing.tar.gz"})
+	}
+
+	if err := run(""); err == nil || !strings.Contains(err.Error(), "customer prod-customer is protected") {
+		t.Fatalf("expected the import to need confirmation, got %v", err)
+	}
+	if err := run("other"); err == nil || !strings.Contains(err.Error(), "does not match protected customer prod-customer") {
+		t.Fatalf("expected a confirmation mismatch error, got %v", err)
+	}
+	// Once confirmed the import goes on to read the archive.
+	if err := run("prod-customer"); err == nil || !strings.Contains(err.Error(), "open archive") {
+		t.Fatalf("expected the confirmed import to reach the archive, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// InitCommand interactively creates newo.toml and the workspace layout for a new checkout.
+type InitCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+	dir     *string
+	force   *bool
+	pull    *bool
+	noPull  *bool
+
+	prompts
+}
+
+// initCustomer holds the answers given for one customer.
+type initCustomer struct {
+	IDN    string
+	Alias  string
+	Type   string
+	APIKey string
+	// Stored reports that the API key went to the credential backend and stays out of newo.toml.
+	Stored bool
+}
+
+// NewInitCommand constructs an init command.
+func NewInitCommand(stdout, stderr io.Writer) *InitCommand {
+	return &InitCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *InitCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *InitCommand) Name() string {
+	return "init"
+}
+
+func (c *InitCommand) Summary() string {
+	return "Create newo.toml and the workspace layout interactively"
+}
+
+func (c *InitCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.dir = fs.String("dir", ".", "workspace directory to initialise")
+	c.force = fs.Bool("force", false, "overwrite an existing newo.toml")
+	c.pull = fs.Bool("pull", false, "run the first pull without asking")
+	c.noPull = fs.Bool("no-pull", false, "skip the first pull without asking")
+}
+
+func (c *InitCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 0 {
+		return errors.New("usage: newo init [--dir <path>] [--force] [--pull|--no-pull]")
+	}
+	dir := "."
+	if c.dir != nil && strings.TrimSpace(*c.dir) != "" {
+		dir = strings.TrimSpace(*c.dir)
+	}
+	dir = userPath(dir)
+	tomlPath := filepath.Join(dir, config.DefaultTomlPath)
+	if _, err := os.Stat(tomlPath); err == nil && (c.force == nil || !*c.force) {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", tomlPath)
+	}
+	c.console.Section("New workspace")
+	var customers []initCustomer
+	for {
+		entry, err := c.askCustomer(len(customers) == 0)
+		if err != nil {
+			return err
+		}
+		if entry.IDN == "" {
+			break
+		}
+		customers = append(customers, entry)
+		more, err := c.confirm("Add another customer?", false)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+	}
+	if len(customers) == 0 {
+		return errors.New("at least one customer is required")
+	}
+	outputRoot, err := c.ask("Output directory", fsutil.DefaultCustomersDir)
+	if err != nil {
+		return err
+	}
+
+	if err := fsutil.EnsureDir(dir); err != nil {
+		return err
+	}
+	if err := config.SaveToml(tomlPath, initConfig(customers, outputRoot)); err != nil {
+		return err
+	}
+	outputDir := outputRoot
+	if !filepath.IsAbs(outputDir) {
+		outputDir = filepath.Join(dir, outputDir)
+	}
+	for _, sub := range []string{outputDir, filepath.Join(dir, fsutil.StateDirName)} {
+		if err := fsutil.EnsureDir(sub); err != nil {
+			return err
+		}
+	}
+	if err := ensureGitignore(dir, fsutil.StateDirName+"/"); err != nil {
+		return err
+	}
+	c.console.Success("Wrote %s", tomlPath)
+	for _, entry := range customers {
+		if entry.APIKey != "" && !entry.Stored {
+			c.console.Warn("newo.toml contains the API key for %s; keep it out of version control or use `newo auth login`", entry.IDN)
+			break
+		}
+	}
+
+	runPull := c.pull != nil && *c.pull
+	if !runPull && (c.noPull == nil || !*c.noPull) {
+		if runPull, err = c.confirm("Run the first pull now?", true); err != nil {
+			return err
+		}
+	}
+	if !runPull {
+		c.console.Info("Run `newo pull` in %s to download your projects.", dir)
+		return nil
+	}
+	return c.firstPull(ctx, dir)
+}
+
+func (c *InitCommand) askCustomer(first bool) (initCustomer, error) {
+	var entry initCustomer
+	prompt := "Customer IDN"
+	if !first {
+		prompt += " (blank to finish)"
+	}
+	idn, err := c.ask(prompt, "")
+	if errors.Is(err, errNoAnswer) && !first {
+		return entry, nil
+	}
+	if err != nil || idn == "" {
+		return entry, err
+	}
+	entry.IDN = idn
+	if entry.Alias, err = c.ask("Alias (optional)", ""); err != nil {
+		return entry, err
+	}
+	for {
+		if entry.Type, err = c.ask("Customer type (blank, integration, or e2e)", ""); err != nil {
+			return entry, err
+		}
+		entry.Type = strings.ToLower(entry.Type)
+		if entry.Type == "" || entry.Type == "integration" || entry.Type == "e2e" {
+			break
+		}
+		c.console.Warn("Unknown customer type %q", entry.Type)
+	}
+	for entry.APIKey == "" {
+		if entry.APIKey, err = c.prompt().Secret(c.console, "API key: "); err != nil {
+			return entry, err
+		}
+	}
+
+	useKeychain, err := c.confirm("Store the API key in the OS keychain instead of newo.toml?", false)
+	if err != nil || !useKeychain {
+		return entry, err
+	}
+	store, err := state.OpenCredentialStore(state.CredentialConfig{})
+	if err == nil {
+		err = store.Set(entry.IDN, entry.APIKey)
+	}
+	if err != nil {
+		c.console.Warn("Could not store the key in the keychain (%v); writing it to newo.toml instead", err)
+		return entry, nil
+	}
+	entry.Stored = true
+	return entry, nil
+}
+
+// ask prints a prompt and returns the trimmed answer, or fallback when the answer is blank. Once the
+// input ends, as when stdin is not a terminal, a question without a fallback fails with errNoAnswer.
+func (c *InitCommand) ask(prompt, fallback string) (string, error) {
+	question := prompt + ": "
+	if fallback != "" {
+		question = fmt.Sprintf("%s [%s]: ", prompt, fallback)
+	}
+	text, err := c.prompt().Input(c.console, question)
+	if errors.Is(err, errNoAnswer) && fallback != "" {
+		return fallback, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return fallback, nil
+	}
+	return text, nil
+}
+
+func (c *InitCommand) confirm(prompt string, fallback bool) (bool, error) {
+	hint := "y/N"
+	if fallback {
+		hint = "Y/n"
+	}
+	answer, err := c.prompt().Confirm(c.console, prompt+" ["+hint+"]: ")
+	if err != nil {
+		return false, err
+	}
+	switch answer {
+	case "":
+		return fallback, nil
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// firstPull runs pull inside the new workspace and returns to the previous directory afterwards.
+func (c *InitCommand) firstPull(ctx context.Context, dir string) error {
+	previous, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("determine working directory: %w", err)
+	}
+	previousToml := config.TomlPath()
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("enter workspace %s: %w", dir, err)
+	}
+	config.SetTomlPath(config.DefaultTomlPath)
+	defer func() {
+		_ = os.Chdir(previous)
+		config.SetTomlPath(previousToml)
+	}()
+	return NewPullCommand(c.stdout, c.stderr).Run(ctx, nil)
+}
+
+// initConfig builds newo.toml from the init answers. The first customer becomes the default.
+func initConfig(customers []initCustomer, outputRoot string) config.TomlFile {
+	var cfg config.TomlFile
+	if outputRoot != fsutil.DefaultCustomersDir {
+		cfg.Defaults.OutputRoot = &outputRoot
+	}
+	if len(customers) > 1 {
+		cfg.Defaults.DefaultCustomerIDN = customers[0].IDN
+	}
+	for _, entry := range customers {
+		written := config.FileCustomerWritable{
+			IDN:   entry.IDN,
+			Alias: entry.Alias,
+			Type:  entry.Type,
+		}
+		if !entry.Stored {
+			written.APIKey = entry.APIKey
+		}
+		cfg.Customers = append(cfg.Customers, written)
+	}
+	return cfg
+}
+
+// ensureGitignore appends line to dir/.gitignore unless it is already listed.
+func ensureGitignore(dir, line string) error {
+	path := filepath.Join(dir, ".gitignore")
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	for _, current := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(current) == line {
+			return nil
+		}
+	}
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content+line+"\n"), fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
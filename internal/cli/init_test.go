@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/config"
+)
+
+func TestInitCommandWritesWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	answers := strings.Join([]string{
+		"acme",        // customer IDN
+		"prod",        // alias
+		"integration", // type
+		"key-1",       // API key
+		"n",           // keychain
+		"y",           // another customer
+		"acme-e2e",    // customer IDN
+		"",            // alias
+		"e2e",         // type
+		"key-2",       // API key
+		"n",           // keychain
+		"n",           // another customer
+		"",            // output directory
+	}, "\n") + "\n"
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatalf("temp stdin: %v", err)
+	}
+	if _, err := stdin.WriteString(answers); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		t.Fatalf("seek stdin: %v", err)
+	}
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() {
+		os.Stdin = originalStdin
+		_ = stdin.Close()
+	})
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewInitCommand(&stdout, &stderr)
+	target, force, pull, noPull := dir, false, false, true
+	cmd.dir, cmd.force, cmd.pull, cmd.noPull = &target, &force, &pull, &noPull
+	if err := cmd.Run(context.Background(), nil); err != nil {
+		t.Fatalf("Run: %v\n%s", err, stderr.String())
+	}
+
+	cfg, err := config.LoadToml(filepath.Join(dir, config.DefaultTomlPath))
+	if err != nil {
+		t.Fatalf("LoadToml: %v", err)
+	}
+	if len(cfg.Customers) != 2 || cfg.Defaults.DefaultCustomerIDN != "acme" || cfg.Defaults.OutputRoot != nil {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	first := cfg.Customers[0]
+	if first.IDN != "acme" || first.Alias != "prod" || first.Type != "integration" || first.APIKey != "key-1" {
+		t.Fatalf("unexpected first customer: %+v", first)
+	}
+	for _, sub := range []string{"newo_customers", ".newo"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			t.Fatalf("expected directory %s: %v", sub, err)
+		}
+	}
+	ignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil || !strings.Contains(string(ignore), ".newo/") {
+		t.Fatalf("expected .gitignore to list .newo/, got %q (%v)", ignore, err)
+	}
+
+	if err := cmd.Run(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected existing newo.toml to be protected, got %v", err)
+	}
+}
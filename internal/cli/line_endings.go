@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// configureLineEndings applies the autocrlf setting of newo.toml to hashing, uploads, and pulled files.
+// A missing or unparsable newo.toml keeps files byte for byte; the command itself reports parse errors.
+func configureLineEndings() error {
+	var cfg config.TomlConfig
+	data, err := os.ReadFile(config.TomlPath())
+	if err == nil {
+		_, _ = toml.Decode(string(data), &cfg)
+	}
+	if err := util.ConfigureLineEndings(string(cfg.Defaults.AutoCRLF)); err != nil {
+		return fmt.Errorf("%s [defaults]: %w", config.TomlPath(), err)
+	}
+	return nil
+}
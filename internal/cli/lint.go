@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"flag"
@@ -12,8 +11,14 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/BurntSushi/toml"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/fsutil"
 	"github.com/twinmind/newo-tool/internal/linter"
+	"github.com/twinmind/newo-tool/internal/state"
 	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/version"
 )
 
 // LintCommand performs linting on .nsl files.
@@ -23,7 +28,19 @@ type LintCommand struct {
 	console  *console.Writer
 	customer *string
 	fix      *bool
-	input    io.Reader
+	fixAll   *bool
+	enable   *string
+	disable  *string
+	format   *string
+	tag      *string
+
+	rules    linter.Config
+	projects map[string]string
+
+	// errorsOnly makes warnings non-fatal; used when lint runs as a push hook.
+	errorsOnly bool
+
+	prompts
 }
 
 // NewLintCommand constructs a lint command.
@@ -32,7 +49,6 @@ func NewLintCommand(stdout, stderr io.Writer) *LintCommand {
 		stdout:  stdout,
 		stderr:  stderr,
 		console: console.New(stdout, stderr),
-		input:   os.Stdin,
 	}
 }
 
@@ -53,10 +69,30 @@ func (c *LintCommand) Summary() string {
 func (c *LintCommand) RegisterFlags(fs *flag.FlagSet) {
 	c.customer = fs.String("customer", "", "customer IDN to lint")
 	c.fix = fs.Bool("fix", false, "interactively fix supported lint warnings")
+	c.fixAll = fs.Bool("all", false, "with --fix, apply every available fix without prompting")
+	c.enable = fs.String("enable", "", "comma-separated rule IDs to enable, overriding newo.toml")
+	c.disable = fs.String("disable", "", "comma-separated rule IDs to disable")
+	c.format = fs.String("format", linter.FormatText, "output format: text, json, sarif, or github")
+	c.tag = fs.String("tag", "", "only lint skills with one of these comma-separated tags in their .meta.yaml")
 }
 
 func (c *LintCommand) Run(ctx context.Context, _ []string) error {
 	c.ensureConsole()
+
+	format := linter.FormatText
+	if c.format != nil && strings.TrimSpace(*c.format) != "" {
+		format = strings.ToLower(strings.TrimSpace(*c.format))
+	}
+	if err := linter.ValidateFormat(format); err != nil {
+		return err
+	}
+	if format != linter.FormatText {
+		if c.fix != nil && *c.fix {
+			return fmt.Errorf("--fix cannot be combined with --format %s", format)
+		}
+		// Keep stdout for the machine-readable report; progress messages go to stderr.
+		c.console = console.New(c.stderr, c.stderr)
+	}
 	c.console.Section("Lint")
 
 	outputRoot, err := getOutputRoot()
@@ -69,7 +105,7 @@ func (c *LintCommand) Run(ctx context.Context, _ []string) error {
 
 	if _, err := os.Stat(outputRoot); errors.Is(err, os.ErrNotExist) {
 		c.console.Info("Directory %q does not exist. Nothing to lint.", outputRoot)
-		return nil
+		return c.writeReport(format, nil)
 	}
 
 	filter := ""
@@ -86,18 +122,21 @@ func (c *LintCommand) Run(ctx context.Context, _ []string) error {
 			id = filter
 		}
 		c.console.Info("No project map for %s. Run `newo pull --customer %s` first.", id, id)
-		return nil
+		return c.writeReport(format, nil)
 	}
 	if len(dirs) == 0 {
 		c.console.Success("No linting issues found.")
-		return nil
+		return c.writeReport(format, nil)
+	}
+
+	if err := c.loadRules(outputRoot); err != nil {
+		return err
 	}
 
 	fixRequested := c.fix != nil && *c.fix
-	if fixRequested {
-		if file, ok := c.input.(*os.File); !ok || !isTerminalFile(file) {
-			return fmt.Errorf("--fix requires an interactive terminal")
-		}
+	fixAll := c.fixAll != nil && *c.fixAll
+	if fixAll && !fixRequested {
+		return fmt.Errorf("--all requires --fix")
 	}
 
 	grouped, totalErrors, totalWarnings, err := c.collectIssues(dirs, true)
@@ -120,6 +159,16 @@ func (c *LintCommand) Run(ctx context.Context, _ []string) error {
 		}
 	}
 
+	if format != linter.FormatText {
+		if err := c.writeReport(format, grouped); err != nil {
+			return err
+		}
+		if totalErrors == 0 && totalWarnings == 0 {
+			return nil
+		}
+		return newSilentExitError(1)
+	}
+
 	if totalErrors == 0 && totalWarnings == 0 {
 		c.console.Success("No linting issues found.")
 		return nil
@@ -134,6 +183,9 @@ func (c *LintCommand) Run(ctx context.Context, _ []string) error {
 		c.console.Info("%s", summary)
 	}
 
+	if c.errorsOnly && totalErrors == 0 {
+		return nil
+	}
 	return newSilentExitError(1)
 }
 
@@ -142,6 +194,7 @@ func (c *LintCommand) collectIssues(dirs []string, log bool) (map[string][]linte
 	visitedRoots := make(map[string]struct{})
 	totalErrors := 0
 	totalWarnings := 0
+	tags := splitRuleList(c.tag)
 
 	for _, dir := range dirs {
 		root := filepath.Clean(dir)
@@ -170,7 +223,10 @@ func (c *LintCommand) collectIssues(dirs []string, log bool) (map[string][]linte
 			return nil, 0, 0, fmt.Errorf("error during linting: %w", lintErr)
 		}
 
-		for _, issue := range lintErrors {
+		for _, issue := range c.applyRules(lintErrors) {
+			if len(tags) > 0 && !scriptTagged(issue.FilePath, tags) {
+				continue
+			}
 			canonical := filepath.ToSlash(filepath.Clean(issue.FilePath))
 			issue.FilePath = canonical
 			grouped[canonical] = append(grouped[canonical], issue)
@@ -185,9 +241,122 @@ func (c *LintCommand) collectIssues(dirs []string, log bool) (map[string][]linte
 	return grouped, totalErrors, totalWarnings, nil
 }
 
+// loadRules reads the [lint] section of newo.toml, applies the --enable/--disable toggles,
+// and indexes project directories so per-project overrides can be matched to files.
+func (c *LintCommand) loadRules(outputRoot string) error {
+	enable := splitRuleList(c.enable)
+	disable := splitRuleList(c.disable)
+	if err := linter.ValidateRules(append(append([]string{}, enable...), disable...)); err != nil {
+		return err
+	}
+
+	rules, err := loadLintConfig()
+	if err != nil {
+		return err
+	}
+	projects := make(map[string]linter.Config, len(rules.Projects))
+	for idn, project := range rules.Projects {
+		projects[idn] = project.Toggle(enable, disable)
+	}
+	rules = rules.Toggle(enable, disable)
+	rules.Projects = projects
+	c.rules = rules
+
+	c.projects = map[string]string{}
+	if len(rules.Projects) == 0 {
+		return nil
+	}
+	customers, err := listCustomersWithState()
+	if err != nil {
+		return err
+	}
+	for _, idn := range customers {
+		definition, err := loadCustomerDefinition(idn)
+		if err != nil {
+			return err
+		}
+		if definition == nil {
+			definition = &customerDefinition{IDN: idn}
+		}
+		projectMap, err := state.LoadProjectMap(definition.IDN)
+		if err != nil {
+			return err
+		}
+		for projectIDN, data := range projectMap.Projects {
+			dir := fsutil.ExportProjectDir(outputRoot, definition.Type, definition.IDN, projectSlugFromState(projectIDN, data))
+			c.projects[filepath.ToSlash(filepath.Clean(dir))] = projectIDN
+		}
+	}
+	return nil
+}
+
+// applyRules filters and re-grades issues using the settings of the project each file belongs to.
+func (c *LintCommand) applyRules(issues []linter.LintError) []linter.LintError {
+	result := make([]linter.LintError, 0, len(issues))
+	for _, issue := range issues {
+		rules := c.rules.ForProject(c.projectForPath(issue.FilePath))
+		result = append(result, rules.Apply([]linter.LintError{issue})...)
+	}
+	return result
+}
+
+func (c *LintCommand) projectForPath(path string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	best := ""
+	project := ""
+	for dir, idn := range c.projects {
+		if (path == dir || strings.HasPrefix(path, dir+"/")) && len(dir) > len(best) {
+			best = dir
+			project = idn
+		}
+	}
+	return project
+}
+
+func loadLintConfig() (linter.Config, error) {
+	data, err := os.ReadFile(config.TomlPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return linter.Config{}, nil
+		}
+		return linter.Config{}, fmt.Errorf("read %s: %w", config.TomlPath(), err)
+	}
+
+	var cfg config.TomlConfig
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return linter.Config{}, fmt.Errorf("parse %s: %w", config.TomlPath(), err)
+	}
+
+	rules, err := linter.NewConfig(cfg.Lint.Disable, cfg.Lint.Severity)
+	if err != nil {
+		return linter.Config{}, fmt.Errorf("%s [lint]: %w", config.TomlPath(), err)
+	}
+	rules.Projects = make(map[string]linter.Config, len(cfg.Lint.Projects))
+	for idn, settings := range cfg.Lint.Projects {
+		project, err := linter.NewConfig(settings.Disable, settings.Severity)
+		if err != nil {
+			return linter.Config{}, fmt.Errorf("%s [lint.projects.%s]: %w", config.TomlPath(), idn, err)
+		}
+		rules.Projects[strings.ToLower(strings.TrimSpace(idn))] = project
+	}
+	return rules, nil
+}
+
+func splitRuleList(value *string) []string {
+	if value == nil {
+		return nil
+	}
+	var rules []string
+	for _, part := range strings.Split(*value, ",") {
+		if rule := strings.TrimSpace(part); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
 func (c *LintCommand) applyFixes(grouped map[string][]linter.LintError) (bool, error) {
-	reader := bufio.NewReader(c.input)
-	applyAll := false
+	applyAll := c.fixAll != nil && *c.fixAll
 	modified := false
 
 	files := make([]string, 0, len(grouped))
@@ -198,47 +367,78 @@ func (c *LintCommand) applyFixes(grouped map[string][]linter.LintError) (bool, e
 
 	for _, file := range files {
 		display := displayLintPath(file)
-		issues := grouped[file]
-
-		for _, issue := range issues {
-			if !isFixableIssue(issue) {
-				continue
-			}
+		issues := append([]linter.LintError(nil), grouped[file]...)
+		sort.SliceStable(issues, func(i, j int) bool {
+			return issues[i].Line < issues[j].Line
+		})
 
-			if !applyAll {
-				c.console.Info("Fix %s (line %d): %s", display, issue.Line, issue.Message)
-				c.console.Prompt("Apply fix? [y/N/a]: ")
-				response, err := reader.ReadString('\n')
-				if err != nil {
-					return modified, fmt.Errorf("read input: %w", err)
-				}
-				decision := strings.ToLower(strings.TrimSpace(response))
-				switch decision {
-				case "y":
-					// proceed
-				case "a":
-					applyAll = true
-				default:
-					c.console.Info("Skipped.")
-					continue
-				}
+		var promptErr error
+		applied, err := linter.FixFile(filepath.FromSlash(file), issues, func(issue linter.LintError) (bool, error) {
+			if applyAll {
+				return true, nil
 			}
-
-			changed, err := fixNSLComment(issue)
+			c.console.Info("Fix %s (line %d): [%s] %s", display, issue.Line, issue.Rule, issue.Message)
+			response, err := c.prompt().Confirm(c.console, "Apply fix? [y/N/a]: ")
 			if err != nil {
-				c.console.Warn("Failed to fix %s:%d: %v", display, issue.Line, err)
-				continue
+				promptErr = err
+				return false, promptErr
 			}
-			if changed {
-				modified = true
-				c.console.Success("Fixed %s:%d", display, issue.Line)
+			switch response {
+			case "y":
+				return true, nil
+			case "a":
+				applyAll = true
+				return true, nil
+			default:
+				c.console.Info("Skipped.")
+				return false, nil
 			}
+		})
+		if promptErr != nil {
+			return modified || applied > 0, promptErr
+		}
+		if err != nil {
+			c.console.Warn("Failed to fix %s: %v", display, err)
+			continue
+		}
+		if applied > 0 {
+			modified = true
+			c.console.Success("Fixed %d issue(s) in %s", applied, display)
 		}
 	}
 
 	return modified, nil
 }
 
+// writeReport renders grouped issues to stdout in a machine-readable format; text output is handled by printLintReport.
+func (c *LintCommand) writeReport(format string, grouped map[string][]linter.LintError) error {
+	if format == linter.FormatText {
+		return nil
+	}
+	if err := linter.WriteReport(c.stdout, format, flattenLintIssues(grouped), version.Version); err != nil {
+		return fmt.Errorf("write %s report: %w", format, err)
+	}
+	return nil
+}
+
+// flattenLintIssues orders issues by file and line, using workspace-relative paths.
+func flattenLintIssues(grouped map[string][]linter.LintError) []linter.LintError {
+	var issues []linter.LintError
+	for file, fileIssues := range grouped {
+		for _, issue := range fileIssues {
+			issue.FilePath = displayLintPath(file)
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].FilePath != issues[j].FilePath {
+			return issues[i].FilePath < issues[j].FilePath
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
+
 func displayLintPath(path string) string {
 	cleaned := filepath.Clean(path)
 	if rel, err := filepath.Rel(".", cleaned); err == nil {
@@ -291,8 +491,15 @@ func printLintReport(writer *console.Writer, grouped map[string][]linter.LintErr
 			if issue.Line > 0 {
 				line = fmt.Sprintf("%d", issue.Line)
 			}
+			if issue.Column > 0 {
+				line += fmt.Sprintf(":%d", issue.Column)
+			}
 
-			formatted := fmt.Sprintf("  line %-4s | %-7s | %s", line, issue.Severity, issue.Message)
+			message := issue.Message
+			if issue.Rule != "" {
+				message = fmt.Sprintf("[%s] %s", issue.Rule, issue.Message)
+			}
+			formatted := fmt.Sprintf("  line %-7s | %-7s | %s", line, issue.Severity, message)
 			if colorEnabled {
 				switch issue.Severity {
 				case linter.SeverityWarning:
@@ -305,7 +512,10 @@ func printLintReport(writer *console.Writer, grouped map[string][]linter.LintErr
 
 			snippet := strings.TrimSpace(issue.Snippet)
 			if snippet != "" {
-				writer.RawLine("    > %s", snippet)
+				// Syntax errors carry a source line and a caret marker, so each line is prefixed on its own.
+				for _, snippetLine := range strings.Split(snippet, "\n") {
+					writer.RawLine("    > %s", snippetLine)
+				}
 			}
 		}
 	}
@@ -326,82 +536,6 @@ func countIssues(grouped map[string][]linter.LintError) (int, int) {
 	return errorsCount, warningsCount
 }
 
-func isFixableIssue(issue linter.LintError) bool {
-	if issue.Message != "Line contains an NSL comment" {
-		return false
-	}
-	trimmed := strings.TrimSpace(issue.Snippet)
-	if trimmed == "" {
-		return false
-	}
-	return strings.Contains(trimmed, "{#") || strings.Contains(trimmed, "#}")
-}
-
-func fixNSLComment(issue linter.LintError) (bool, error) {
-	path := filepath.FromSlash(issue.FilePath)
-	info, err := os.Stat(path)
-	if err != nil {
-		return false, err
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return false, err
-	}
-
-	lines := strings.SplitAfter(string(data), "\n")
-	if issue.Line <= 0 || issue.Line > len(lines) {
-		return false, fmt.Errorf("line %d out of range", issue.Line)
-	}
-
-	idx := issue.Line - 1
-	original := lines[idx]
-	trimmed := strings.TrimSpace(original)
-
-	changed := false
-
-	if strings.HasPrefix(trimmed, "{#") && strings.HasSuffix(trimmed, "#}") {
-		lines = append(lines[:idx], lines[idx+1:]...)
-		changed = true
-	} else {
-		lineContent := original
-		newline := ""
-		if strings.HasSuffix(lineContent, "\n") {
-			newline = "\n"
-			lineContent = strings.TrimSuffix(lineContent, "\n")
-		}
-
-		start := strings.Index(lineContent, "{#")
-		end := strings.Index(lineContent, "#}")
-
-		switch {
-		case start >= 0 && end >= 0 && end >= start:
-			lineContent = lineContent[:start] + lineContent[end+2:]
-			changed = true
-		case start >= 0:
-			lineContent = strings.TrimRight(lineContent[:start], " \t")
-			changed = true
-		case end >= 0:
-			lineContent = strings.TrimRight(lineContent[:end], " \t")
-			changed = true
-		}
-
-		if changed {
-			lines[idx] = lineContent + newline
-		}
-	}
-
-	if !changed {
-		return false, nil
-	}
-
-	result := strings.Join(lines, "")
-	if err := os.WriteFile(path, []byte(result), info.Mode().Perm()); err != nil {
-		return false, fmt.Errorf("write %s: %w", path, err)
-	}
-	return true, nil
-}
-
 func isTerminalFile(f *os.File) bool {
 	if f == nil {
 		return false
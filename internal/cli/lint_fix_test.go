@@ -11,10 +11,10 @@ import (
 	"github.com/twinmind/newo-tool/internal/ui/console"
 )
 
-func TestFixNSLComment_RemovesEntireCommentLine(t *testing.T) {
+func TestFixNSLComment_TrimsTrailingComment(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "script.nsl")
-	original := "{# comment #}\nkeep\n"
+	original := "value {{ foo }} #}\n"
 	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
 		t.Fatalf("write file: %v", err)
 	}
@@ -22,33 +22,34 @@ func TestFixNSLComment_RemovesEntireCommentLine(t *testing.T) {
 	issue := linter.LintError{
 		FilePath: filepath.ToSlash(path),
 		Line:     1,
+		Rule:     linter.RuleNSLComment,
 		Message:  "Line contains an NSL comment",
-		Snippet:  "{# comment #}",
+		Snippet:  "value {{ foo }} #}",
 		Severity: linter.SeverityWarning,
 	}
 
-	changed, err := fixNSLComment(issue)
+	applied, err := linter.FixFile(path, []linter.LintError{issue}, func(linter.LintError) (bool, error) { return true, nil })
 	if err != nil {
-		t.Fatalf("fixNSLComment: %v", err)
+		t.Fatalf("FixFile: %v", err)
 	}
-	if !changed {
-		t.Fatalf("expected change, got none")
+	if applied != 1 {
+		t.Fatalf("expected change, got %d", applied)
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		t.Fatalf("read file: %v", err)
 	}
-	expected := "keep\n"
+	expected := "value {{ foo }}\n"
 	if string(data) != expected {
 		t.Fatalf("unexpected content: %q", string(data))
 	}
 }
 
-func TestFixNSLComment_TrimsTrailingComment(t *testing.T) {
+func TestApplyFixesInteractive(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "script.nsl")
-	original := "value {{ foo }} #}\n"
+	original := "{# comment #}\n"
 	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
 		t.Fatalf("write file: %v", err)
 	}
@@ -56,54 +57,60 @@ func TestFixNSLComment_TrimsTrailingComment(t *testing.T) {
 	issue := linter.LintError{
 		FilePath: filepath.ToSlash(path),
 		Line:     1,
+		Rule:     linter.RuleNSLComment,
 		Message:  "Line contains an NSL comment",
-		Snippet:  "value {{ foo }} #}",
+		Snippet:  "{# comment #}",
 		Severity: linter.SeverityWarning,
 	}
 
-	changed, err := fixNSLComment(issue)
+	grouped := map[string][]linter.LintError{
+		filepath.ToSlash(path): {issue},
+	}
+
+	cmd := &LintCommand{
+		stdout:  io.Discard,
+		stderr:  io.Discard,
+		console: console.New(io.Discard, io.Discard, console.WithColors(false)),
+		prompts: prompts{prompter: NewPrompter(strings.NewReader("y\n"))},
+	}
+
+	modified, err := cmd.applyFixes(grouped)
 	if err != nil {
-		t.Fatalf("fixNSLComment: %v", err)
+		t.Fatalf("applyFixes: %v", err)
 	}
-	if !changed {
-		t.Fatalf("expected change, got none")
+	if !modified {
+		t.Fatalf("expected modifications")
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		t.Fatalf("read file: %v", err)
 	}
-	expected := "value {{ foo }}\n"
-	if string(data) != expected {
-		t.Fatalf("unexpected content: %q", string(data))
+	if string(data) != "{% set _comment = \"comment\" %}\n" {
+		t.Fatalf("expected comment converted to a set statement, got %q", string(data))
 	}
 }
 
-func TestApplyFixesInteractive(t *testing.T) {
+func TestApplyFixesAllSkipsPrompts(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "script.nsl")
-	original := "{# comment #}\n"
-	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+	if err := os.WriteFile(path, []byte("{{foo}}  \n"), 0o644); err != nil {
 		t.Fatalf("write file: %v", err)
 	}
 
-	issue := linter.LintError{
-		FilePath: filepath.ToSlash(path),
-		Line:     1,
-		Message:  "Line contains an NSL comment",
-		Snippet:  "{# comment #}",
-		Severity: linter.SeverityWarning,
-	}
-
-	grouped := map[string][]linter.LintError{
-		filepath.ToSlash(path): {issue},
-	}
-
+	fixAll := true
 	cmd := &LintCommand{
 		stdout:  io.Discard,
 		stderr:  io.Discard,
 		console: console.New(io.Discard, io.Discard, console.WithColors(false)),
-		input:   strings.NewReader("y\n"),
+		prompts: prompts{prompter: NewPrompter(strings.NewReader(""))},
+		fixAll:  &fixAll,
+	}
+	grouped := map[string][]linter.LintError{
+		filepath.ToSlash(path): {
+			{FilePath: filepath.ToSlash(path), Line: 1, Rule: linter.RuleTrailingWhitespace, Severity: linter.SeverityWarning},
+			{FilePath: filepath.ToSlash(path), Line: 1, Rule: linter.RuleTagSpacing, Severity: linter.SeverityWarning},
+		},
 	}
 
 	modified, err := cmd.applyFixes(grouped)
@@ -113,35 +120,42 @@ func TestApplyFixesInteractive(t *testing.T) {
 	if !modified {
 		t.Fatalf("expected modifications")
 	}
-
 	data, err := os.ReadFile(path)
 	if err != nil {
 		t.Fatalf("read file: %v", err)
 	}
-	if string(data) != "" {
-		t.Fatalf("expected empty file, got %q", string(data))
+	if string(data) != "{{ foo }}\n" {
+		t.Fatalf("unexpected content: %q", string(data))
 	}
 }
 
 func TestIsFixableIssue(t *testing.T) {
+	lines := []string{"{# comment #}"}
 	issue := linter.LintError{
+		Line:    1,
+		Rule:    linter.RuleNSLComment,
 		Message: "Line contains an NSL comment",
 		Snippet: "{# comment #}",
 	}
-	if !isFixableIssue(issue) {
+	fixer, ok := linter.FixerFor(issue)
+	if !ok {
 		t.Fatalf("expected fixable issue")
 	}
+	if _, ok := fixer.Fix(lines, issue); !ok {
+		t.Fatalf("expected a fix for the comment line")
+	}
 
-	issue.Snippet = ""
-	if isFixableIssue(issue) {
-		t.Fatalf("expected non-fixable issue")
+	if _, ok := fixer.Fix([]string{"plain text"}, issue); ok {
+		t.Fatalf("expected non-fixable issue without a comment")
 	}
 
 	issue = linter.LintError{
+		Line:    1,
+		Rule:    linter.RuleUndefinedVariable,
 		Message: "Undefined variable",
 		Snippet: "foo",
 	}
-	if isFixableIssue(issue) {
-		t.Fatalf("expected non-fixable for different message")
+	if _, ok := linter.FixerFor(issue); ok {
+		t.Fatalf("expected non-fixable for different rule")
 	}
 }
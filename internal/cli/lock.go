@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// LockCommand shows and removes the per-customer workspace locks taken by pull, push, and deploy.
+type LockCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+	force   *bool
+}
+
+// NewLockCommand constructs a lock command.
+func NewLockCommand(stdout, stderr io.Writer) *LockCommand {
+	return &LockCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *LockCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *LockCommand) Name() string {
+	return "lock"
+}
+
+func (c *LockCommand) Summary() string {
+	return "Show who holds the workspace locks or remove abandoned ones"
+}
+
+func (c *LockCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.force = fs.Bool("force", false, "break the lock even if its owner may still be running")
+}
+
+func (c *LockCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo lock status | newo lock break [<customer>] [--force]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "status":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		return c.status()
+	case "break":
+		if len(args) > 2 {
+			return errors.New(usage)
+		}
+		customerIDN := ""
+		if len(args) == 2 {
+			customerIDN = args[1]
+		}
+		return c.breakLocks(customerIDN, c.force != nil && *c.force)
+	default:
+		return fmt.Errorf("unknown lock subcommand %q; %s", args[0], usage)
+	}
+}
+
+func (c *LockCommand) status() error {
+	locks, err := fsutil.ListLocks()
+	if err != nil {
+		return err
+	}
+	if len(locks) == 0 {
+		c.console.Info("No locks held.")
+		return nil
+	}
+	now := time.Now()
+	table := make([][]string, 0, len(locks))
+	for _, lock := range locks {
+		pid := "-"
+		if lock.PID > 0 {
+			pid = strconv.Itoa(lock.PID)
+		}
+		table = append(table, []string{lock.Customer, orDash(lock.Operation), pid, orDash(lock.Hostname), now.Sub(lock.StartedAt).Round(time.Second).String(), lock.State(now)})
+	}
+	c.console.Section("Locks")
+	writeTable(c.console, []string{"CUSTOMER", "OPERATION", "PID", "HOST", "AGE", "STATE"}, table)
+	return nil
+}
+
+// breakLocks removes the lock of one customer, or every abandoned lock when no customer is given.
+func (c *LockCommand) breakLocks(customerIDN string, force bool) error {
+	if customerIDN != "" {
+		info, err := fsutil.BreakLock(customerIDN, force)
+		if err != nil {
+			return err
+		}
+		c.console.Success("Removed lock of %s taken by %s", info.Customer, info.Describe(time.Now()))
+		return nil
+	}
+
+	locks, err := fsutil.ListLocks()
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, lock := range locks {
+		state := lock.State(time.Now())
+		if !force && state != fsutil.LockDead && state != fsutil.LockStale {
+			c.console.Warn("Keeping %s lock of %s taken by %s; use --force to remove it", state, lock.Customer, lock.Describe(time.Now()))
+			continue
+		}
+		info, err := fsutil.BreakLock(lock.Customer, force)
+		if err != nil {
+			return err
+		}
+		c.console.Success("Removed lock of %s taken by %s", info.Customer, info.Describe(time.Now()))
+		removed++
+	}
+	if removed == 0 && len(locks) == 0 {
+		c.console.Info("No locks held.")
+	}
+	return nil
+}
+
+// withCustomerLock runs fn while holding the workspace lock of one customer, so operations on other
+// customers can proceed in parallel.
+func withCustomerLock(writer *console.Writer, customerIDN, operation string, verbose bool, fn func() error) error {
+	release, err := fsutil.AcquireLock(customerIDN, operation)
+	if err != nil {
+		return lockError(err)
+	}
+	defer func() {
+		if err := release(); err != nil && verbose {
+			writer.Warn("Release lock: %v", err)
+		}
+	}()
+	return fn()
+}
+
+// lockError adds a pointer to `newo lock status` when a workspace lock is held by someone else.
+func lockError(err error) error {
+	if errors.Is(err, fsutil.ErrLocked) {
+		return fmt.Errorf("%w; run `newo lock status` for details, or retry later", err)
+	}
+	return err
+}
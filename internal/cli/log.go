@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// LogCommand lists the remote skill changes that pull detected, newest first.
+type LogCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	project  *string
+	skill    *string
+	since    *string
+	limit    *int
+	format   *string
+}
+
+// logRow is a changelog entry together with the customer it belongs to.
+type logRow struct {
+	Customer string `json:"customer"`
+	state.ChangelogEntry
+}
+
+// NewLogCommand constructs a log command.
+func NewLogCommand(stdout, stderr io.Writer) *LogCommand {
+	return &LogCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *LogCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *LogCommand) Name() string {
+	return "log"
+}
+
+func (c *LogCommand) Summary() string {
+	return "Show skills that changed on the platform between pulls"
+}
+
+func (c *LogCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to show")
+	c.project = fs.String("project", "", "only show changes in this project IDN")
+	c.skill = fs.String("skill", "", "only show changes to this skill IDN")
+	c.since = fs.String("since", "", "only show changes pulled after this time (duration such as 36h or 7d, or a date)")
+	c.limit = fs.Int("limit", 50, "maximum number of changes to show (0 for all)")
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *LogCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 0 {
+		return errors.New("usage: newo log [--customer <idn>] [--project <idn>] [--skill <idn>] [--since <time>] [--limit <n>] [--format text|json]")
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+	var since time.Time
+	if c.since != nil && strings.TrimSpace(*c.since) != "" {
+		if since, err = parseSince(*c.since, time.Now()); err != nil {
+			return err
+		}
+	}
+	customerFilter := flagValue(c.customer)
+	projectFilter := flagValue(c.project)
+	skillFilter := flagValue(c.skill)
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	rows := []logRow{}
+	matched := false
+	processed := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+			continue
+		}
+		matched = true
+		key := strings.ToLower(idn)
+		if processed[key] {
+			continue
+		}
+		processed[key] = true
+
+		changelog, err := state.LoadChangelog(idn)
+		if err != nil {
+			return err
+		}
+		for _, change := range changelog.Entries {
+			if projectFilter != "" && !strings.EqualFold(change.ProjectIDN, projectFilter) {
+				continue
+			}
+			if skillFilter != "" && !strings.EqualFold(change.SkillIDN, skillFilter) {
+				continue
+			}
+			if !since.IsZero() && change.PulledAt.Before(since) {
+				continue
+			}
+			rows = append(rows, logRow{Customer: idn, ChangelogEntry: change})
+		}
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].PulledAt.After(rows[j].PulledAt)
+	})
+	if c.limit != nil && *c.limit > 0 && len(rows) > *c.limit {
+		rows = rows[:*c.limit]
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(rows); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+	if len(rows) == 0 {
+		c.console.Info("No remote changes recorded. Changes are logged when `newo pull` finds skills edited on the platform.")
+		return nil
+	}
+	header := []string{"PULLED", "CUSTOMER", "PROJECT", "FLOW", "SKILL", "UPDATED", "HASH", "SIZE"}
+	table := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		table = append(table, []string{
+			row.PulledAt.Local().Format("2006-01-02 15:04"),
+			row.Customer,
+			row.ProjectIDN,
+			row.FlowIDN,
+			row.SkillIDN,
+			orDash(row.UpdatedAt),
+			shortHash(row.OldHash) + ".." + shortHash(row.NewHash),
+			formatSizeDelta(row.Size, row.SizeDelta),
+		})
+	}
+	writeTable(c.console, header, table)
+	return nil
+}
+
+// parseSince accepts a duration before now (Go syntax plus a "d" suffix for days), a date, or an RFC 3339 timestamp.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration such as 36h or 7d, a date, or an RFC 3339 time", value)
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return orDash(hash)
+}
+
+func formatSizeDelta(size int, delta *int) string {
+	if delta == nil {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%d B (%+d)", size, *delta)
+}
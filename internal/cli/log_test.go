@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"36h":                  now.Add(-36 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2024-05-01T08:00:00Z": time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		"2024-05-01":           time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local),
+	}
+	for value, want := range cases {
+		got, err := parseSince(value, now)
+		if err != nil {
+			t.Fatalf("parseSince(%q): %v", value, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("parseSince(%q) = %v, want %v", value, got, want)
+		}
+	}
+	if _, err := parseSince("last week", now); err == nil {
+		t.Fatal("expected an error for an unparseable value")
+	}
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// LogsCommand prints the conversation act log of a customer and optionally follows it.
+type LogsCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	flow     *string
+	skill    *string
+	since    *string
+	limit    *int
+	tail     *bool
+	interval *time.Duration
+	format   *string
+}
+
+// NewLogsCommand constructs a logs command.
+func NewLogsCommand(stdout, stderr io.Writer) *LogsCommand {
+	return &LogsCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *LogsCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *LogsCommand) Name() string {
+	return "logs"
+}
+
+func (c *LogsCommand) Summary() string {
+	return "Show or follow recent conversation act logs from the platform"
+}
+
+func (c *LogsCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to read logs from")
+	c.flow = fs.String("flow", "", "only show acts of this flow IDN")
+	c.skill = fs.String("skill", "", "only show acts of this skill IDN")
+	c.since = fs.String("since", "", "only show acts newer than a duration (36h, 7d), date, or RFC 3339 time")
+	c.limit = fs.Int("limit", 50, "maximum number of acts to fetch per request")
+	c.tail = fs.Bool("tail", false, "keep polling and print new acts as they arrive")
+	c.interval = fs.Duration("interval", 5*time.Second, "polling interval for --tail")
+	c.format = fs.String("format", "text", "output format: text or json (one object per line)")
+}
+
+func (c *LogsCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo logs [--flow <idn>] [--skill <idn>] [--customer <idn>] [--since <time>] [--limit <n>] [--tail [--interval <duration>]] [--format text|json]"
+	if len(args) != 0 {
+		return errors.New(usage)
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+	query := platform.ActLogQuery{FlowIDN: flagValue(c.flow), SkillIDN: flagValue(c.skill)}
+	if c.limit != nil {
+		if *c.limit < 0 {
+			return errors.New("--limit must not be negative")
+		}
+		query.Limit = *c.limit
+	}
+	if value := flagValue(c.since); value != "" {
+		if query.Since, err = parseSince(value, time.Now()); err != nil {
+			return err
+		}
+	}
+	tail := c.tail != nil && *c.tail
+	interval := 5 * time.Second
+	if c.interval != nil {
+		interval = *c.interval
+	}
+	if tail && interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Logs are read from a single customer: the one asked for, the default, or the only one configured.
+	customerFilter := flagValue(c.customer)
+	if customerFilter == "" {
+		customerFilter = cfg.DefaultCustomer
+	}
+	if customerFilter == "" && len(cfg.Entries) > 1 {
+		return errors.New("several customers are configured; choose one with --customer")
+	}
+
+	for _, entry := range cfg.Entries {
+		sess, err := session.New(ctx, env, entry, registry)
+		if err != nil {
+			return err
+		}
+		if sess.RegistryUpdated {
+			if err := registry.Save(); err != nil {
+				return err
+			}
+		}
+		if customerFilter != "" && !matchesCustomerToken(entry, sess.IDN, customerFilter) {
+			continue
+		}
+		return c.stream(ctx, sess.Client, query, format, tail, interval)
+	}
+	if customerFilter != "" {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+	return errors.New("no customers configured")
+}
+
+// stream prints the acts matching the query oldest first. With tail it keeps polling from the newest
+// act seen so far; acts that share that timestamp are returned again and skipped by ID.
+func (c *LogsCommand) stream(ctx context.Context, client *platform.Client, query platform.ActLogQuery, format string, tail bool, interval time.Duration) error {
+	seen := map[string]bool{}
+	for {
+		acts, err := client.ListActLogs(ctx, query)
+		if err != nil {
+			if tail && ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("list act logs: %w", err)
+		}
+		sortActs(acts)
+
+		fresh := make([]platform.ActLog, 0, len(acts))
+		for _, act := range acts {
+			if act.ID != "" && seen[act.ID] {
+				continue
+			}
+			fresh = append(fresh, act)
+		}
+		if err := c.printActs(fresh, format); err != nil {
+			return err
+		}
+		if !tail {
+			if len(acts) == 0 {
+				c.console.Info("No acts found.")
+			}
+			return nil
+		}
+
+		if len(acts) > 0 {
+			seen = map[string]bool{}
+			for _, act := range acts {
+				seen[act.ID] = true
+			}
+			if newest, err := time.Parse(time.RFC3339, acts[len(acts)-1].Datetime); err == nil {
+				query.Since = newest
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (c *LogsCommand) printActs(acts []platform.ActLog, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		for _, act := range acts {
+			if err := encoder.Encode(act); err != nil {
+				return fmt.Errorf("write json: %w", err)
+			}
+		}
+		return nil
+	}
+	for _, act := range acts {
+		source := act.FlowIDN
+		if act.SkillIDN != "" {
+			source += "/" + act.SkillIDN
+		}
+		c.console.RawLine("%s  %-5s  %s  %s", orDash(act.Datetime), strings.ToUpper(orDash(act.Level)), orDash(source), act.Message)
+	}
+	return nil
+}
+
+// sortActs orders acts oldest first; the platform returns them newest first.
+func sortActs(acts []platform.ActLog) {
+	sort.SliceStable(acts, func(i, j int) bool {
+		if acts[i].Datetime != acts[j].Datetime {
+			return acts[i].Datetime < acts[j].Datetime
+		}
+		return acts[i].ID < acts[j].ID
+	})
+}
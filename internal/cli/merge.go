@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -14,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"encoding/json"
 
@@ -21,8 +21,11 @@ import (
 	"github.com/twinmind/newo-tool/internal/customer"
 	"github.com/twinmind/newo-tool/internal/diff"
 	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/notify"
 	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/templatevars"
 	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
@@ -39,14 +42,26 @@ type MergeCommand struct {
 	noPull            *bool
 	noPush            *bool
 	force             *bool
+	flowIDN           *string
+	skillIDN          *string
+	reverse           *bool
+	confirmCustomer   *string
 
 	outputRoot string
+	vars       map[string]string
+	scope      mergeScope
+	// ownership caches the ownership sections of the destination's flows, keyed by flow directory.
+	ownership map[string]*state.FlowOwnership
+	// event summarises the merge for the notification webhooks.
+	event notify.Event
 
 	promptMu sync.Mutex
 
 	// Command factories for dependency injection in tests.
 	pullCmdFactory func(stdout, stderr io.Writer) Command
 	pushCmdFactory func(stdout, stderr io.Writer) Command
+
+	prompts
 }
 
 // NewMergeCommand constructs a merge command.
@@ -62,6 +77,10 @@ func NewMergeCommand(stdout, stderr io.Writer) *MergeCommand {
 		noPull:            new(bool),
 		noPush:            new(bool),
 		force:             new(bool),
+		flowIDN:           new(string),
+		skillIDN:          new(string),
+		reverse:           new(bool),
+		confirmCustomer:   new(string),
 
 		pullCmdFactory: func(stdout, stderr io.Writer) Command { return NewPullCommand(stdout, stderr) },
 		pushCmdFactory: func(stdout, stderr io.Writer) Command { return NewPushCommand(stdout, stderr) },
@@ -87,9 +106,13 @@ func (c *MergeCommand) RegisterFlags(fs *flag.FlagSet) {
 	fs.BoolVar(c.noPush, "no-push", false, "Skip the final push step")
 	fs.BoolVar(c.force, "force", false, "Perform copy and push without interactive diff/confirmation")
 	fs.StringVar(c.targetCustomerIDN, "target-customer", "", "IDN of the target customer (optional, auto-detects if unambiguous)")
+	fs.StringVar(c.flowIDN, "flow", "", "Only merge the given flow")
+	fs.StringVar(c.skillIDN, "skill", "", "Only merge the given skill")
+	fs.BoolVar(c.reverse, "reverse", false, "Copy from the target customer back into the source customer")
+	fs.StringVar(c.confirmCustomer, "confirm-customer", "", "IDN of a protected destination customer, confirming the merge into it")
 }
 
-func (c *MergeCommand) Run(ctx context.Context, args []string) error {
+func (c *MergeCommand) Run(ctx context.Context, args []string) (err error) {
 	prevTarget := ""
 	if c.targetCustomerIDN != nil {
 		prevTarget = *c.targetCustomerIDN
@@ -106,6 +129,10 @@ func (c *MergeCommand) Run(ctx context.Context, args []string) error {
 	if c.force != nil {
 		prevForce = *c.force
 	}
+	prevFlow := flagValue(c.flowIDN)
+	prevSkill := flagValue(c.skillIDN)
+	prevReverse := c.reverse != nil && *c.reverse
+	prevConfirm := flagValue(c.confirmCustomer)
 
 	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
 	c.RegisterFlags(fs)
@@ -123,6 +150,18 @@ func (c *MergeCommand) Run(ctx context.Context, args []string) error {
 	if prevForce {
 		_ = fs.Set("force", "true")
 	}
+	if prevFlow != "" {
+		_ = fs.Set("flow", prevFlow)
+	}
+	if prevSkill != "" {
+		_ = fs.Set("skill", prevSkill)
+	}
+	if prevReverse {
+		_ = fs.Set("reverse", "true")
+	}
+	if prevConfirm != "" {
+		_ = fs.Set("confirm-customer", prevConfirm)
+	}
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -176,6 +215,27 @@ func (c *MergeCommand) Run(ctx context.Context, args []string) error {
 		return fmt.Errorf("target customer %q must be of type \"integration\", but got \"%s\"", targetEntry.HintIDN, targetEntry.Type)
 	}
 
+	// Scripts are expanded with the integration customer's variables, or collapsed back to placeholders
+	// with them when merging in reverse.
+	c.vars = templatevars.ForCustomer(targetEntry.HintIDN, targetEntry.Type, targetEntry.Vars)
+	c.scope = mergeScope{flow: strings.TrimSpace(*c.flowIDN), skill: strings.TrimSpace(*c.skillIDN)}
+	if *c.reverse {
+		sourceEntry, targetEntry = targetEntry, sourceEntry
+	}
+	// Merging rewrites the destination's local files before pushing, so confirm a protected one up front.
+	if err := confirmProtectedCustomer(c.console, c.prompt(), *targetEntry, targetEntry.HintIDN, strings.TrimSpace(*c.confirmCustomer)); err != nil {
+		return err
+	}
+
+	if !*c.noPush {
+		started := time.Now()
+		c.event = notify.Event{Command: "merge", Customer: targetEntry.HintIDN, SourceCustomer: sourceEntry.HintIDN, Project: projectIDN}
+		defer func() {
+			c.event.Finish(started, err)
+			sendNotifications(ctx, c.console, env.Notifications, []notify.Event{c.event})
+		}()
+	}
+
 	c.console.Section("Merge")
 	c.console.Success(
 		"Validated project %q: %s → %s",
@@ -218,19 +278,34 @@ func (c *MergeCommand) Run(ctx context.Context, args []string) error {
 		return fmt.Errorf("stat source project directory: %w", err)
 	}
 
-	if err := os.MkdirAll(targetProjectDir, fsutil.DirPerm); err != nil {
-		return fmt.Errorf("ensure target project directory: %w", err)
+	if err := c.scope.check(sourceProjectDir); err != nil {
+		return err
 	}
 
-	c.console.Section("Copy")
-	c.console.Info("Source: %s", sourceProjectDir)
-	c.console.Info("Target: %s", targetProjectDir)
+	// The copy rewrites the target's files, so it holds the target's lock like pull and push do. The lock
+	// is released before the push below, which takes it again.
+	err = withCustomerLock(c.console, targetEntry.HintIDN, "merge", false, func() error {
+		if err := os.MkdirAll(targetProjectDir, fsutil.DirPerm); err != nil {
+			return fmt.Errorf("ensure target project directory: %w", err)
+		}
+
+		c.console.Section("Copy")
+		c.console.Info("Source: %s", sourceProjectDir)
+		c.console.Info("Target: %s", targetProjectDir)
+		if c.scope.active() {
+			c.console.Info("Scope: %s", c.scope)
+		}
 
-	c.console.Info("Copying files from source to target...")
-	if err := c.copyProjectFiles(sourceProjectDir, targetProjectDir, *c.force); err != nil {
-		return fmt.Errorf("failed to copy project files: %w", err)
+		c.console.Info("Copying files from source to target...")
+		if err := c.copyProjectFiles(sourceProjectDir, targetProjectDir, *c.force); err != nil {
+			return fmt.Errorf("failed to copy project files: %w", err)
+		}
+		c.console.Success("File copy complete.")
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	c.console.Success("File copy complete.")
 
 	if !*c.noPush {
 		c.console.Section("Push")
@@ -250,6 +325,9 @@ func (c *MergeCommand) runPullCommand(ctx context.Context, customerIDN, projectI
 	pullCmd := c.pullCmdFactory(c.stdout, c.stderr)
 	fs := flag.NewFlagSet("pull", flag.ContinueOnError)
 	pullCmd.RegisterFlags(fs)
+	if prompted, ok := pullCmd.(promptedCommand); ok {
+		prompted.SetPrompter(c.prompt())
+	}
 
 	_ = fs.Set("force", "true")
 	_ = fs.Set("customer", customerIDN)
@@ -264,16 +342,39 @@ func (c *MergeCommand) runPushCommand(ctx context.Context, customerIDN string, f
 	pushCmd := c.pushCmdFactory(c.stdout, c.stderr)
 	fs := flag.NewFlagSet("push", flag.ContinueOnError)
 	pushCmd.RegisterFlags(fs)
+	if prompted, ok := pushCmd.(promptedCommand); ok {
+		prompted.SetPrompter(c.prompt())
+	}
 
 	_ = fs.Set("customer", customerIDN)
+	_ = fs.Set("confirm-customer", customerIDN)
 	if force {
 		_ = fs.Set("force", "true")
 	}
 
-	return pushCmd.Run(ctx, []string{})
+	push, isPush := pushCmd.(*PushCommand)
+	if isPush {
+		push.skipNotify = true
+	}
+	err := pushCmd.Run(ctx, []string{})
+	if isPush {
+		for _, pushed := range push.events {
+			c.event.SkillsUpdated += pushed.SkillsUpdated
+			c.event.SkillsCreated += pushed.SkillsCreated
+			c.event.SkillsDeleted += pushed.SkillsDeleted
+			c.event.Published += pushed.Published
+			c.event.PublishSkipped = pushed.PublishSkipped
+			c.event.Warnings = append(c.event.Warnings, pushed.Warnings...)
+		}
+	}
+	return err
 }
 
+// copyProjectFiles copies the source files over the target and removes target files the source no
+// longer has. Overwrites and removals are confirmed separately, so applying one overwrite to all files
+// never deletes anything unasked.
 func (c *MergeCommand) copyProjectFiles(sourceDir, targetDir string, force bool) error {
+	overwriteAll := force
 	keep := make(map[string]struct{})
 	if err := filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -288,8 +389,23 @@ func (c *MergeCommand) copyProjectFiles(sourceDir, targetDir string, force bool)
 		targetPath := filepath.Join(targetDir, relPath)
 
 		if d.IsDir() {
+			if c.scope.active() {
+				return nil
+			}
 			return os.MkdirAll(targetPath, fsutil.DirPerm)
 		}
+		if !c.scope.matches(relPath) {
+			return nil
+		}
+		owner, err := c.targetOwner(targetPath)
+		if err != nil {
+			return err
+		}
+		if owner != "" {
+			keep[relPath] = struct{}{}
+			c.console.Info("Kept %s: the destination marks it as %s managed", targetPath, owner)
+			return nil
+		}
 
 		sourceContent, err := os.ReadFile(path)
 		if err != nil {
@@ -321,8 +437,11 @@ func (c *MergeCommand) copyProjectFiles(sourceDir, targetDir string, force bool)
 				writeContent = ensureTrailingNewline(sanitizedSource)
 			}
 		case strings.HasSuffix(path, "metadata.yaml"):
-			sanitizedSource := removeFlowStateFieldIDs(canonicalizeFlowMetadata(stripFlowMetaID(sourceContent)))
 			sanitizedTarget := removeFlowStateFieldIDs(canonicalizeFlowMetadata(stripFlowMetaID(targetContent)))
+			sanitizedSource, err := keepOwnedFlowKeys(removeFlowStateFieldIDs(canonicalizeFlowMetadata(stripFlowMetaID(sourceContent))), sanitizedTarget)
+			if err != nil {
+				return fmt.Errorf("%s: %w", targetPath, err)
+			}
 			targetID := extractFlowMetaID(targetContent)
 			targetFieldIDs := extractFlowStateFieldIDs(targetContent)
 
@@ -347,16 +466,30 @@ func (c *MergeCommand) copyProjectFiles(sourceDir, targetDir string, force bool)
 				restoreIDs = sourceIDs
 			}
 			writeContent = applyProjectIDs(sanitizedSource, restoreIDs)
+		case isSkillScriptFile(path) && c.reverse != nil && *c.reverse:
+			// Keep the target's placeholders when the script only differs by their expansion.
+			if bytes.Equal(templatevars.Expand(targetContent, c.vars), sourceContent) {
+				writeContent = targetContent
+			} else {
+				writeContent = templatevars.Collapse(sourceContent, c.vars)
+			}
+			sourceForCompare = writeContent
+		case isSkillScriptFile(path):
+			if missing := templatevars.Unresolved(sourceContent, c.vars); len(missing) > 0 {
+				c.console.Warn("%s uses undefined template variables: %s", path, strings.Join(missing, ", "))
+			}
+			writeContent = templatevars.Expand(sourceContent, c.vars)
+			sourceForCompare = writeContent
 		}
 
-		if !force && !bytes.Equal(sourceForCompare, targetForCompare) {
+		if !overwriteAll && !bytes.Equal(util.NormalizeEOL(sourceForCompare), util.NormalizeEOL(targetForCompare)) {
 			lines := diff.Generate(targetForCompare, sourceForCompare, 3)
 			confirmed, applyAll, err := c.confirmOverwrite(targetPath, lines)
 			if err != nil {
 				return err
 			}
 			if applyAll {
-				force = true
+				overwriteAll = true
 			}
 			if !confirmed {
 				c.console.Warn("Skipped %s (not confirmed)", targetPath)
@@ -364,7 +497,10 @@ func (c *MergeCommand) copyProjectFiles(sourceDir, targetDir string, force bool)
 			}
 		}
 
-		if err := os.WriteFile(targetPath, writeContent, fsutil.FilePerm); err != nil {
+		if err := fsutil.EnsureParentDir(targetPath); err != nil {
+			return fmt.Errorf("failed to create directory for %q: %w", targetPath, err)
+		}
+		if err := fsutil.AtomicWrite(targetPath, writeContent, fsutil.FilePerm); err != nil {
 			return fmt.Errorf("failed to write file %q: %w", targetPath, err)
 		}
 		c.console.Info("Copied %s → %s", path, targetPath)
@@ -375,29 +511,147 @@ func (c *MergeCommand) copyProjectFiles(sourceDir, targetDir string, force bool)
 	return c.removeStaleFiles(targetDir, keep, force)
 }
 
+// mergeScope limits a merge to one flow, one skill, or one skill of one flow. The zero value merges the
+// whole project.
+type mergeScope struct {
+	flow  string
+	skill string
+}
+
+func (s mergeScope) active() bool {
+	return s.flow != "" || s.skill != ""
+}
+
+func (s mergeScope) String() string {
+	switch {
+	case s.flow != "" && s.skill != "":
+		return fmt.Sprintf("skill %s/%s", s.flow, s.skill)
+	case s.skill != "":
+		return fmt.Sprintf("skill %s", s.skill)
+	default:
+		return fmt.Sprintf("flow %s", s.flow)
+	}
+}
+
+// matches reports whether a path relative to the project directory belongs to the scope. A flow
+// covers everything under flows/<flow>/; a skill covers its script and .meta.yaml file.
+func (s mergeScope) matches(relPath string) bool {
+	if !s.active() {
+		return true
+	}
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	if len(parts) < 3 || parts[0] != fsutil.FlowsDir {
+		return false
+	}
+	if s.flow != "" && parts[1] != s.flow {
+		return false
+	}
+	if s.skill == "" {
+		return true
+	}
+	if len(parts) != 3 {
+		return false
+	}
+	name := parts[2]
+	if name == s.skill+fsutil.SkillMetaFileExt {
+		return true
+	}
+	return isSkillScriptFile(name) && strings.TrimSuffix(name, filepath.Ext(name)) == s.skill
+}
+
+// check makes sure the scope names something in the source project, and that a skill given without
+// --flow belongs to a single flow.
+func (s mergeScope) check(sourceDir string) error {
+	if !s.active() {
+		return nil
+	}
+	flows := map[string]bool{}
+	if err := filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		if s.matches(rel) {
+			flows[strings.Split(filepath.ToSlash(rel), "/")[1]] = true
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("scan source project: %w", err)
+	}
+	switch {
+	case len(flows) == 0:
+		return fmt.Errorf("%s not found in the source project", s)
+	case len(flows) > 1:
+		names := make([]string, 0, len(flows))
+		for name := range flows {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("skill %s exists in several flows (%s); choose one with --flow", s.skill, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// isSkillScriptFile reports whether path has one of the extensions produced by platform.ScriptExtension.
+func isSkillScriptFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".nsl", ".guidance", ".txt":
+		return true
+	}
+	return false
+}
+
+// targetOwner returns the owner that the destination flow's metadata.yaml sets for the skill a file
+// belongs to, or "" for files of unmarked skills and for files that are not skill files.
+func (c *MergeCommand) targetOwner(targetPath string) (string, error) {
+	name := filepath.Base(targetPath)
+	var skillIDN string
+	switch {
+	case name == fsutil.MetadataYAML:
+		return "", nil
+	case strings.HasSuffix(name, fsutil.SkillMetaFileExt):
+		skillIDN = strings.TrimSuffix(name, fsutil.SkillMetaFileExt)
+	case isSkillScriptFile(name):
+		skillIDN = strings.TrimSuffix(name, filepath.Ext(name))
+	default:
+		return "", nil
+	}
+
+	dir := filepath.Dir(targetPath)
+	ownership, cached := c.ownership[dir]
+	if !cached {
+		var err error
+		if ownership, err = state.LoadFlowOwnership(filepath.Join(dir, fsutil.MetadataYAML)); err != nil {
+			return "", err
+		}
+		if c.ownership == nil {
+			c.ownership = map[string]*state.FlowOwnership{}
+		}
+		c.ownership[dir] = ownership
+	}
+	return ownership.Skill(skillIDN), nil
+}
+
 func (c *MergeCommand) confirmOverwrite(path string, lines []diff.Line) (bool, bool, error) {
 	c.promptMu.Lock()
 	defer c.promptMu.Unlock()
 
 	c.ensureConsole()
-	c.console.Write(diff.Format(path, lines))
-	c.console.Prompt("Overwrite local file %s? [y/N/a]: ", path)
-
-	reader := bufio.NewReader(os.Stdin)
-	text, err := reader.ReadString('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
-		return false, false, fmt.Errorf("read confirmation input: %w", err)
+	pageDiff(c.stdout, c.console, diff.Format(path, lines))
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Overwrite local file %s? [y/N/a] (a: apply to all overwrites): ", path))
+	if err != nil {
+		return false, false, err
 	}
 
-	response := strings.TrimSpace(strings.ToLower(text))
+	response := text
 	switch response {
 	case "y":
 		return true, false, nil
 	case "a":
-		if c.force != nil {
-			*c.force = true
-		}
-		c.console.Info("Applying overwrite to all subsequent files.")
+		c.console.Info("Applying overwrite to all subsequent files; removals are still confirmed.")
 		return true, true, nil
 	default:
 		c.console.Info("Keeping existing file.")
@@ -418,9 +672,12 @@ func (c *MergeCommand) removeStaleFiles(targetDir string, keep map[string]struct
 		if err != nil {
 			return fmt.Errorf("stale-file rel path: %w", err)
 		}
-		if _, ok := keep[rel]; ok {
+		if _, ok := keep[rel]; ok || !c.scope.matches(rel) {
 			return nil
 		}
+		if owner, err := c.targetOwner(path); err != nil || owner != "" {
+			return err
+		}
 		remove := removeAll
 		if !removeAll {
 			confirmed, applyAll, err := c.confirmRemoval(path)
@@ -450,21 +707,15 @@ func (c *MergeCommand) confirmRemoval(path string) (bool, bool, error) {
 	defer c.promptMu.Unlock()
 
 	c.ensureConsole()
-	c.console.Prompt("Remove local file %s? [y/N/a]: ", path)
-
-	reader := bufio.NewReader(os.Stdin)
-	text, err := reader.ReadString('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
-		return false, false, fmt.Errorf("read confirmation input: %w", err)
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Remove local file %s? [y/N/a] (a: apply to all deletions): ", path))
+	if err != nil {
+		return false, false, err
 	}
 
-	switch strings.TrimSpace(strings.ToLower(text)) {
+	switch text {
 	case "y":
 		return true, false, nil
 	case "a":
-		if c.force != nil {
-			*c.force = true
-		}
 		c.console.Info("Applying removal to all subsequent files.")
 		return true, true, nil
 	default:
@@ -789,6 +1040,76 @@ func canonicalizeFlowMetadata(body []byte) []byte {
 	return []byte(trimmed)
 }
 
+// keepOwnedFlowKeys gives the source flow metadata the destination's ownership section, and the
+// destination's values of the keys that section marks, so a merge never overwrites them. Both bodies
+// are canonical metadata without the flow id.
+func keepOwnedFlowKeys(body, target []byte) ([]byte, error) {
+	ownership, err := state.ParseFlowOwnership(target)
+	if err != nil {
+		return nil, err
+	}
+	var source, dest yaml.Node
+	if yaml.Unmarshal(body, &source) != nil || yaml.Unmarshal(target, &dest) != nil {
+		return body, nil
+	}
+	sourceRoot, destRoot := documentMapping(&source), documentMapping(&dest)
+	if sourceRoot == nil || (ownership == nil && mappingValue(sourceRoot, "ownership") == nil) {
+		return body, nil
+	}
+
+	keys := []string{"ownership"}
+	if ownership != nil {
+		for key := range ownership.Keys {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		value := mappingValue(destRoot, key)
+		if value == nil {
+			removeMappingKey(sourceRoot, key)
+			continue
+		}
+		if existing := mappingValue(sourceRoot, key); existing != nil {
+			*existing = *value
+			continue
+		}
+		sourceRoot.Content = append(sourceRoot.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&source); err != nil {
+		return nil, fmt.Errorf("encode flow metadata: %w", err)
+	}
+	_ = enc.Close()
+	return buf.Bytes(), nil
+}
+
+// documentMapping returns the top-level mapping of a decoded YAML document, or nil.
+func documentMapping(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return node
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
 func flowEventKey(node *yaml.Node) string {
 	if node == nil {
 		return ""
@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/twinmind/newo-tool/internal/fsutil"
 	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
 )
 
 // MockCommand implements the cli.Command interface for testing purposes.
@@ -94,6 +96,7 @@ type tomlCustomer struct {
 	apiKey       string
 	customerType string
 	projects     []string
+	protected    bool
 }
 
 func buildCustomersToml(customers ...tomlCustomer) string {
@@ -104,6 +107,9 @@ func buildCustomersToml(customers ...tomlCustomer) string {
 		fmt.Fprintf(&b, "  idn = %q\n", c.idn)
 		fmt.Fprintf(&b, "  api_key = %q\n", c.apiKey)
 		fmt.Fprintf(&b, "  type = %q\n", c.customerType)
+		if c.protected {
+			b.WriteString("  protected = true\n")
+		}
 		for _, project := range c.projects {
 			b.WriteString("  [[customers.projects]]\n")
 			fmt.Fprintf(&b, "    idn = %q\n", project)
@@ -432,6 +438,13 @@ func TestMergeCommand_CopyProjectFiles(t *testing.T) {
 	if err := os.WriteFile(targetFile, []byte(`{"from":"target"}`), fsutil.FilePerm); err != nil {
 		t.Fatalf("write target file: %v", err)
 	}
+	sourceScript := filepath.Join(sourceDir, "flows", "main", "greet.nsl")
+	if err := os.MkdirAll(filepath.Dir(sourceScript), fsutil.DirPerm); err != nil {
+		t.Fatalf("ensure source script dir: %v", err)
+	}
+	if err := os.WriteFile(sourceScript, []byte("{{ user }} on {{NEWO_CUSTOMER_IDN}}\n"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write source script: %v", err)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd := NewMergeCommand(&stdout, &stderr)
@@ -473,4 +486,313 @@ func TestMergeCommand_CopyProjectFiles(t *testing.T) {
 	if string(got) != `{"from":"source"}` {
 		t.Fatalf("expected target file to be overwritten, got %s", string(got))
 	}
+
+	script, err := os.ReadFile(filepath.Join(targetDir, "flows", "main", "greet.nsl"))
+	if err != nil {
+		t.Fatalf("read target script: %v", err)
+	}
+	if string(script) != "{{ user }} on integration-customer\n" {
+		t.Fatalf("expected template variables to be substituted, got %q", string(script))
+	}
+}
+
+func TestMergeCommand_ReverseSkillScope(t *testing.T) {
+	toml := buildCustomersToml(
+		tomlCustomer{idn: "e2e-customer", apiKey: "e2e-key", customerType: "e2e", projects: []string{"test-project"}},
+		tomlCustomer{idn: "integration-customer", apiKey: "integration-key", customerType: "integration", projects: []string{"test-project"}},
+	)
+	restore := mustChdir(t, createTempNewoToml(t, toml))
+	defer restore()
+
+	outputRoot := fsutil.DefaultCustomersDir
+	e2eDir := prepareProjectState(t, outputRoot, "e2e", "e2e-customer", "test-project", "test-project")
+	integrationDir := prepareProjectState(t, outputRoot, "integration", "integration-customer", "test-project", "test-project")
+
+	files := map[string]string{
+		filepath.Join(e2eDir, "flows", "main", "greet.nsl"):                  "Hi from {{NEWO_CUSTOMER_IDN}}\n",
+		filepath.Join(e2eDir, "flows", "main", "bye.nsl"):                    "Bye\n",
+		filepath.Join(e2eDir, "flows", "other", "greet.nsl"):                 "Other\n",
+		filepath.Join(integrationDir, "flows", "main", "greet.nsl"):          "Hello from integration-customer\n",
+		filepath.Join(integrationDir, "flows", "main", "bye.nsl"):            "Bye, edited\n",
+		filepath.Join(integrationDir, "flows", "main", "greet.meta.yaml"):    "id: s1\nidn: greet\n",
+		filepath.Join(integrationDir, "flows", "other", "greet.nsl"):         "Other, edited\n",
+		filepath.Join(integrationDir, "flows", "main", "metadata.yaml"):      "id: f1\nidn: main\n",
+		filepath.Join(integrationDir, "flows", "main", "unrelated.guidance"): "x\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), fsutil.DirPerm); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), fsutil.FilePerm); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	run := func(flow, skill string) error {
+		var stdout, stderr bytes.Buffer
+		cmd := NewMergeCommand(&stdout, &stderr)
+		fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+		cmd.RegisterFlags(fs)
+		_ = fs.Set("force", "true")
+		_ = fs.Set("no-pull", "true")
+		_ = fs.Set("no-push", "true")
+		_ = fs.Set("reverse", "true")
+		_ = fs.Set("flow", flow)
+		_ = fs.Set("skill", skill)
+		return cmd.Run(context.Background(), []string{"test-project", "from", "e2e-customer"})
+	}
+
+	if err := run("", "greet"); err == nil || !strings.Contains(err.Error(), "several flows") {
+		t.Fatalf("expected an ambiguous skill error, got %v", err)
+	}
+	if err := run("missing", ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected a missing flow error, got %v", err)
+	}
+	if err := run("main", "greet"); err != nil {
+		t.Fatalf("merge command failed: %v", err)
+	}
+
+	want := map[string]string{
+		filepath.Join(e2eDir, "flows", "main", "greet.nsl"):       "Hello from {{NEWO_CUSTOMER_IDN}}\n",
+		filepath.Join(e2eDir, "flows", "main", "greet.meta.yaml"): "idn: greet\n",
+		filepath.Join(e2eDir, "flows", "main", "bye.nsl"):         "Bye\n",
+		filepath.Join(e2eDir, "flows", "other", "greet.nsl"):      "Other\n",
+	}
+	for path, content := range want {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if string(got) != content {
+			t.Fatalf("%s: expected %q, got %q", path, content, got)
+		}
+	}
+	for _, name := range []string{"metadata.yaml", "unrelated.guidance"} {
+		if _, err := os.Stat(filepath.Join(e2eDir, "flows", "main", name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s outside the scope to stay uncopied, got %v", name, err)
+		}
+	}
+}
+
+func TestMergeCommand_KeepsOwnedSkillsAndKeys(t *testing.T) {
+	toml := buildCustomersToml(
+		tomlCustomer{idn: "e2e-customer", apiKey: "e2e-key", customerType: "e2e", projects: []string{"test-project"}},
+		tomlCustomer{idn: "integration-customer", apiKey: "integration-key", customerType: "integration", projects: []string{"test-project"}},
+	)
+	restore := mustChdir(t, createTempNewoToml(t, toml))
+	defer restore()
+
+	outputRoot := fsutil.DefaultCustomersDir
+	sourceDir := prepareProjectState(t, outputRoot, "e2e", "e2e-customer", "test-project", "test-project")
+	targetDir := prepareProjectState(t, outputRoot, "integration", "integration-customer", "test-project", "test-project")
+	sourceFlow := filepath.Join(sourceDir, "flows", "main")
+	targetFlow := filepath.Join(targetDir, "flows", "main")
+
+	ownership := "ownership:\n  skills:\n    greet: local\n    custom: platform\n  keys:\n    title: local\n"
+	writeTestFile(t, filepath.Join(sourceFlow, "metadata.yaml"), "id: f1\nidn: main\ntitle: Source\ndescription: Shared\n")
+	writeTestFile(t, filepath.Join(sourceFlow, "greet.nsl"), "Source greeting\n")
+	writeTestFile(t, filepath.Join(sourceFlow, "faq.nsl"), "Source faq\n")
+	writeTestFile(t, filepath.Join(targetFlow, "metadata.yaml"), "id: f2\nidn: main\ntitle: Target\ndescription: Old\n"+ownership)
+	writeTestFile(t, filepath.Join(targetFlow, "greet.nsl"), "Target greeting\n")
+	writeTestFile(t, filepath.Join(targetFlow, "faq.nsl"), "Target faq\n")
+	writeTestFile(t, filepath.Join(targetFlow, "custom.nsl"), "Customised\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewMergeCommand(&stdout, &stderr)
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	cmd.RegisterFlags(fs)
+	_ = fs.Set("target-customer", "integration-customer")
+	_ = fs.Set("force", "true")
+	_ = fs.Set("no-pull", "true")
+	_ = fs.Set("no-push", "true")
+	if err := cmd.Run(context.Background(), []string{"test-project", "from", "e2e-customer"}); err != nil {
+		t.Fatalf("merge command failed: %v\n%s", err, stderr.String())
+	}
+
+	want := map[string]string{
+		"greet.nsl":     "Target greeting\n",
+		"faq.nsl":       "Source faq\n",
+		"custom.nsl":    "Customised\n",
+		"metadata.yaml": "id: f2\nidn: main\ntitle: Target\ndescription: Shared\n" + ownership,
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(targetFlow, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if string(got) != content {
+			t.Fatalf("%s: expected %q, got %q", name, content, got)
+		}
+	}
+}
+
+func TestMergeCommand_ProtectedTarget(t *testing.T) {
+	toml := buildCustomersToml(
+		tomlCustomer{idn: "e2e-customer", apiKey: "e2e-key", customerType: "e2e", projects: []string{"test-project"}},
+		tomlCustomer{idn: "integration-customer", apiKey: "integration-key", customerType: "integration", projects: []string{"test-project"}, protected: true},
+	)
+	restore := mustChdir(t, createTempNewoToml(t, toml))
+	defer restore()
+
+	outputRoot := fsutil.DefaultCustomersDir
+	sourceDir := prepareProjectState(t, outputRoot, "e2e", "e2e-customer", "test-project", "test-project")
+	targetDir := prepareProjectState(t, outputRoot, "integration", "integration-customer", "test-project", "test-project")
+	if err := os.WriteFile(filepath.Join(sourceDir, "project.json"), []byte(`{"from":"source"}`), fsutil.FilePerm); err != nil {
+		t.Fatalf("write source file: %v", err)
+	}
+
+	run := func(confirm string) error {
+		var stdout, stderr bytes.Buffer
+		cmd := NewMergeCommand(&stdout, &stderr)
+		fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+		cmd.RegisterFlags(fs)
+		_ = fs.Set("force", "true")
+		_ = fs.Set("no-pull", "true")
+		_ = fs.Set("no-push", "true")
+		_ = fs.Set("confirm-customer", confirm)
+		return cmd.Run(context.Background(), []string{"test-project", "from", "e2e-customer"})
+	}
+
+	if err := run("e2e-customer"); err == nil || !strings.Contains(err.Error(), "does not match protected customer integration-customer") {
+		t.Fatalf("expected a confirmation mismatch error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "project.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be copied without confirmation, got %v", err)
+	}
+	if err := run("integration-customer"); err != nil {
+		t.Fatalf("merge command failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "project.json")); err != nil {
+		t.Fatalf("expected the confirmed merge to copy files: %v", err)
+	}
+}
+
+func TestMergeCommand_IgnoresLineEndingsUnderAutoCRLF(t *testing.T) {
+	if err := util.ConfigureLineEndings(util.AutoCRLFInput); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = util.ConfigureLineEndings("") })
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	r, w, _ := os.Pipe()
+	_ = w.Close()
+	os.Stdin = r
+
+	toml := buildCustomersToml(
+		tomlCustomer{idn: "e2e-customer", apiKey: "e2e-key", customerType: "e2e", projects: []string{"test-project"}},
+		tomlCustomer{idn: "integration-customer", apiKey: "integration-key", customerType: "integration", projects: []string{"test-project"}},
+	)
+	restore := mustChdir(t, createTempNewoToml(t, toml))
+	defer restore()
+
+	outputRoot := fsutil.DefaultCustomersDir
+	sourceDir := prepareProjectState(t, outputRoot, "e2e", "e2e-customer", "test-project", "test-project")
+	targetDir := prepareProjectState(t, outputRoot, "integration", "integration-customer", "test-project", "test-project")
+	writeTestFile(t, filepath.Join(sourceDir, "flows", "main", "greet.nsl"), "Hello\nWorld\n")
+	writeTestFile(t, filepath.Join(targetDir, "flows", "main", "greet.nsl"), "Hello\r\nWorld\r\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewMergeCommand(&stdout, &stderr)
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	cmd.RegisterFlags(fs)
+	_ = fs.Set("target-customer", "integration-customer")
+	_ = fs.Set("no-pull", "true")
+	_ = fs.Set("no-push", "true")
+	if err := cmd.Run(context.Background(), []string{"test-project", "from", "e2e-customer"}); err != nil {
+		t.Fatalf("merge command failed: %v\n%s", err, stderr.String())
+	}
+	if output := stdout.String() + stderr.String(); strings.Contains(output, "not confirmed") {
+		t.Fatalf("expected a line-ending difference not to need confirmation, got:\n%s", output)
+	}
+}
+
+func TestMergeCommand_ApplyAllOverwritesStillConfirmsRemovals(t *testing.T) {
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	r, w, _ := os.Pipe()
+	_, _ = w.WriteString("a\n")
+	_ = w.Close()
+	os.Stdin = r
+
+	toml := buildCustomersToml(
+		tomlCustomer{idn: "e2e-customer", apiKey: "e2e-key", customerType: "e2e", projects: []string{"test-project"}},
+		tomlCustomer{idn: "integration-customer", apiKey: "integration-key", customerType: "integration", projects: []string{"test-project"}},
+	)
+	restore := mustChdir(t, createTempNewoToml(t, toml))
+	defer restore()
+
+	outputRoot := fsutil.DefaultCustomersDir
+	sourceDir := prepareProjectState(t, outputRoot, "e2e", "e2e-customer", "test-project", "test-project")
+	targetDir := prepareProjectState(t, outputRoot, "integration", "integration-customer", "test-project", "test-project")
+	writeTestFile(t, filepath.Join(sourceDir, "flows", "main", "a.nsl"), "new a\n")
+	writeTestFile(t, filepath.Join(sourceDir, "flows", "main", "b.nsl"), "new b\n")
+	writeTestFile(t, filepath.Join(targetDir, "flows", "main", "a.nsl"), "old a\n")
+	writeTestFile(t, filepath.Join(targetDir, "flows", "main", "b.nsl"), "old b\n")
+	stale := filepath.Join(targetDir, "flows", "main", "stale.nsl")
+	writeTestFile(t, stale, "stale\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewMergeCommand(&stdout, &stderr)
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	cmd.RegisterFlags(fs)
+	_ = fs.Set("target-customer", "integration-customer")
+	_ = fs.Set("no-pull", "true")
+	_ = fs.Set("no-push", "true")
+	if err := cmd.Run(context.Background(), []string{"test-project", "from", "e2e-customer"}); err != nil {
+		t.Fatalf("merge command failed: %v\n%s", err, stderr.String())
+	}
+
+	for name, want := range map[string]string{"a.nsl": "new a\n", "b.nsl": "new b\n"} {
+		data, err := os.ReadFile(filepath.Join(targetDir, "flows", "main", name))
+		if err != nil || string(data) != want {
+			t.Fatalf("expected %s to be overwritten with %q, got %q (%v)", name, want, data, err)
+		}
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Fatalf("expected the stale file to be kept when its removal was not confirmed: %v", err)
+	}
+	if output := stdout.String(); !strings.Contains(output, "apply to all deletions") {
+		t.Fatalf("expected a separate removal prompt, got:\n%s", output)
+	}
+}
+
+func TestMergeCommand_HoldsTargetLock(t *testing.T) {
+	toml := buildCustomersToml(
+		tomlCustomer{idn: "e2e-customer", apiKey: "e2e-key", customerType: "e2e", projects: []string{"test-project"}},
+		tomlCustomer{idn: "integration-customer", apiKey: "integration-key", customerType: "integration", projects: []string{"test-project"}},
+	)
+	restore := mustChdir(t, createTempNewoToml(t, toml))
+	defer restore()
+
+	outputRoot := fsutil.DefaultCustomersDir
+	e2eDir := prepareProjectState(t, outputRoot, "e2e", "e2e-customer", "test-project", "test-project")
+	integrationDir := prepareProjectState(t, outputRoot, "integration", "integration-customer", "test-project", "test-project")
+	source := filepath.Join(e2eDir, "flows", "main", "greet.nsl")
+	if err := os.MkdirAll(filepath.Dir(source), fsutil.DirPerm); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(source, []byte("Hi\n"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	release, err := fsutil.AcquireLock("integration-customer", "push")
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	defer func() { _ = release() }()
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewMergeCommand(&stdout, &stderr)
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	cmd.RegisterFlags(fs)
+	_ = fs.Set("force", "true")
+	_ = fs.Set("no-pull", "true")
+	_ = fs.Set("no-push", "true")
+	err = cmd.Run(context.Background(), []string{"test-project", "from", "e2e-customer"})
+	if !errors.Is(err, fsutil.ErrLocked) {
+		t.Fatalf("expected the merge to stop on the target's lock, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(integrationDir, "flows", "main", "greet.nsl")); !os.IsNotExist(err) {
+		t.Fatalf("expected the locked target to stay untouched, got %v", err)
+	}
 }
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/blobstore"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	skillsync "github.com/twinmind/newo-tool/internal/sync"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// mergeToolEnv names the merge tool offered for push conflicts.
+const mergeToolEnv = "NEWO_MERGETOOL"
+
+// maxBaseVersions bounds how many platform versions are searched for the script of the last pull.
+const maxBaseVersions = 20
+
+var mergeToolVariable = regexp.MustCompile(`\$\{?(BASE|LOCAL|REMOTE|MERGED)\b|%(BASE|LOCAL|REMOTE|MERGED)%`)
+
+// skillVersionSource is the part of the platform client that finds the base of a conflict.
+type skillVersionSource interface {
+	ListSkillVersions(ctx context.Context, skillID string) ([]platform.SkillVersion, error)
+	GetSkillVersion(ctx context.Context, skillID, versionID string) (platform.SkillVersion, error)
+}
+
+// conflictResolver returns the handler for skills that changed both locally and on the platform, or
+// nil when no merge tool is configured.
+func (c *PushCommand) conflictResolver(ctx context.Context, versions skillVersionSource) skillsync.ResolveConflictFunc {
+	tool := strings.TrimSpace(os.Getenv(mergeToolEnv))
+	if tool == "" {
+		return nil
+	}
+	return func(req skillsync.ResolveConflictRequest) ([]byte, error) {
+		c.ensureConsole()
+		c.console.Warn("%s changed on the platform since the last pull.", req.Path)
+		text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Resolve %s in the merge tool? [y/N]: ", req.Path))
+		if err != nil {
+			return nil, err
+		}
+		if text != "y" {
+			return nil, nil
+		}
+
+		base := findBaseScript(ctx, versions, req.SkillID, req.BaseHash)
+		if base == nil {
+			c.console.Warn("The version of the last pull was not found; the merge tool gets an empty base.")
+		}
+		merged, err := runMergeTool(tool, req.Path, base, req.Local, req.Remote)
+		if err != nil {
+			c.console.Warn("Merge tool failed: %v", err)
+			return nil, nil
+		}
+		return merged, nil
+	}
+}
+
+// findBaseScript returns the script both sides of a conflict started from: from the blob cache when
+// pull stored it there, otherwise from the recent versions of the skill on the platform. It returns
+// nil when the script cannot be found.
+func findBaseScript(ctx context.Context, versions skillVersionSource, skillID, baseHash string) []byte {
+	if data, err := os.ReadFile(blobstore.Open(fsutil.BlobsDir()).Path(baseHash)); err == nil && util.ContentHash(data) == baseHash {
+		return data
+	}
+	if versions == nil || skillID == "" {
+		return nil
+	}
+	listed, err := versions.ListSkillVersions(ctx, skillID)
+	if err != nil {
+		return nil
+	}
+	for i, version := range listed {
+		if i == maxBaseVersions {
+			break
+		}
+		if version.PromptScript == "" {
+			if version, err = versions.GetSkillVersion(ctx, skillID, version.ID); err != nil {
+				continue
+			}
+		}
+		if util.ContentHashString(version.PromptScript) == baseHash {
+			return []byte(version.PromptScript)
+		}
+	}
+	return nil
+}
+
+// runMergeTool writes the three versions of a script to a temporary directory, runs the merge tool, and
+// returns the merged result. The merged file starts as the local version; a tool that exits with an
+// error abandons the merge.
+func runMergeTool(tool, path string, base, local, remote []byte) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "newo-merge-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	files := map[string][]byte{"BASE": base, "LOCAL": local, "REMOTE": remote, "MERGED": local}
+	paths := map[string]string{}
+	for side, content := range files {
+		paths[side] = filepath.Join(dir, stem+"."+side+ext)
+		if err := os.WriteFile(paths[side], content, fsutil.FilePerm); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := mergeToolCommand(tool, paths["BASE"], paths["LOCAL"], paths["REMOTE"], paths["MERGED"])
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w", tool, err)
+	}
+	return os.ReadFile(paths["MERGED"])
+}
+
+// mergeToolCommand builds the merge tool invocation. A command that refers to $BASE, $LOCAL, $REMOTE,
+// or $MERGED runs in the shell with those set, like git's mergetool.<tool>.cmd. Well-known tools get
+// their three-way arguments, and any other tool is given base, local, remote, and merged in order.
+func mergeToolCommand(tool, base, local, remote, merged string) *exec.Cmd {
+	if mergeToolVariable.MatchString(tool) {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", tool)
+		} else {
+			cmd = exec.Command("sh", "-c", tool)
+		}
+		cmd.Env = append(os.Environ(), "BASE="+base, "LOCAL="+local, "REMOTE="+remote, "MERGED="+merged)
+		return cmd
+	}
+
+	fields := strings.Fields(tool)
+	name := strings.TrimSuffix(filepath.Base(fields[0]), ".exe")
+	args := append([]string{}, fields[1:]...)
+	switch name {
+	case "code", "code-insiders", "codium":
+		args = append(args, "--wait", "--merge", local, remote, base, merged)
+	case "meld":
+		args = append(args, local, base, remote, "--output", merged)
+	case "kdiff3":
+		args = append(args, base, local, remote, "-o", merged)
+	case "vimdiff", "nvim", "vim":
+		args = append(args, "-d", merged, local, base, remote)
+	default:
+		args = append(args, base, local, remote, merged)
+	}
+	return exec.Command(fields[0], args...)
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+func TestMergeToolCommandArguments(t *testing.T) {
+	cases := map[string][]string{
+		"meld":           {"meld", "L", "B", "R", "--output", "M"},
+		"code":           {"code", "--wait", "--merge", "L", "R", "B", "M"},
+		"kdiff3":         {"kdiff3", "B", "L", "R", "-o", "M"},
+		"nvim":           {"nvim", "-d", "M", "L", "B", "R"},
+		"my-merge --gui": {"my-merge", "--gui", "B", "L", "R", "M"},
+	}
+	for tool, want := range cases {
+		if got := mergeToolCommand(tool, "B", "L", "R", "M").Args; !slices.Equal(got, want) {
+			t.Fatalf("%s: expected %v, got %v", tool, want, got)
+		}
+	}
+	if cmd := mergeToolCommand(`meld "$LOCAL" "$REMOTE"`, "B", "L", "R", "M"); !slices.Contains(cmd.Env, "MERGED=M") {
+		t.Fatalf("expected a shell command with the merge variables, got %v", cmd.Args)
+	}
+}
+
+func TestRunMergeToolReturnsMergedScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command as the merge tool")
+	}
+	merged, err := runMergeTool(`cat "$BASE" "$REMOTE" > "$MERGED"`, "flows/main/greet.nsl", []byte("base\n"), []byte("local\n"), []byte("remote\n"))
+	if err != nil {
+		t.Fatalf("runMergeTool: %v", err)
+	}
+	if string(merged) != "base\nremote\n" {
+		t.Fatalf("expected the merged file written by the tool, got %q", merged)
+	}
+
+	if _, err := runMergeTool("false", "greet.nsl", nil, []byte("local"), []byte("remote")); err == nil {
+		t.Fatal("expected a failing merge tool to abandon the merge")
+	}
+}
+
+type fakeVersionSource struct {
+	versions []platform.SkillVersion
+	scripts  map[string]string
+}
+
+func (f fakeVersionSource) ListSkillVersions(context.Context, string) ([]platform.SkillVersion, error) {
+	return f.versions, nil
+}
+
+func (f fakeVersionSource) GetSkillVersion(_ context.Context, _, versionID string) (platform.SkillVersion, error) {
+	return platform.SkillVersion{ID: versionID, PromptScript: f.scripts[versionID]}, nil
+}
+
+func TestFindBaseScriptSearchesPlatformVersions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	source := fakeVersionSource{
+		versions: []platform.SkillVersion{{ID: "v3", PromptScript: "newest"}, {ID: "v2"}, {ID: "v1"}},
+		scripts:  map[string]string{"v2": "pulled", "v1": "oldest"},
+	}
+	if got := findBaseScript(context.Background(), source, "skill-id", util.SHA256String("pulled")); string(got) != "pulled" {
+		t.Fatalf("expected the pulled version, got %q", got)
+	}
+	if got := findBaseScript(context.Background(), source, "skill-id", util.SHA256String("unknown")); got != nil {
+		t.Fatalf("expected no base for an unknown hash, got %q", got)
+	}
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/metrics"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// finishRunMetrics writes the run summary when --metrics-out is set and passes runErr through.
+// A failure to write the summary is reported but never masks the command's own result.
+func finishRunMetrics(path *string, runErr error, w *console.Writer) error {
+	if path == nil || strings.TrimSpace(*path) == "" {
+		return runErr
+	}
+	if err := metrics.WriteFile(userPath(*path), metrics.Snapshot(runErr)); err != nil {
+		w.Warn("Metrics: %v", err)
+	}
+	return runErr
+}
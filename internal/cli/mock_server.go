@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// MockServerCommand runs an in-memory fake of the NEWO platform, so pull, push, and merge can be
+// exercised end to end in CI without real credentials. It is not listed in the usage text.
+type MockServerCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+
+	addr   *string
+	data   *string
+	apiKey *string
+}
+
+// NewMockServerCommand constructs a mock-server command.
+func NewMockServerCommand(stdout, stderr io.Writer) *MockServerCommand {
+	return &MockServerCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *MockServerCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *MockServerCommand) Name() string {
+	return "mock-server"
+}
+
+func (c *MockServerCommand) Summary() string {
+	return "Serve an in-memory fake of the NEWO platform API for integration tests"
+}
+
+// Hidden keeps the command out of the usage text; it is a testing aid, not part of the workflow.
+func (c *MockServerCommand) Hidden() bool {
+	return true
+}
+
+func (c *MockServerCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.addr = fs.String("addr", "127.0.0.1:8787", "address to listen on")
+	c.data = fs.String("data", "", "JSON fixture with the customer, projects, and attributes to serve")
+	c.apiKey = fs.String("api-key", "", "API key token requests must present (default: accept any)")
+}
+
+func (c *MockServerCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+	if len(args) != 0 {
+		return errors.New("usage: newo mock-server [--addr <host:port>] [--data <fixture.json>] [--api-key <key>]")
+	}
+
+	var fixture httpmock.Fixture
+	if dataPath := flagValue(c.data); dataPath != "" {
+		loaded, err := httpmock.LoadFixture(userPath(dataPath))
+		if err != nil {
+			return fmt.Errorf("load fixture: %w", err)
+		}
+		fixture = loaded
+	}
+
+	listener, err := net.Listen("tcp", flagValue(c.addr))
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	server := &http.Server{
+		Handler:           httpmock.NewServer(fixture, flagValue(c.apiKey)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	c.console.Info("Mock NEWO platform listening on http://%s (state at /mock/state); press Ctrl+C to stop", listener.Addr())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("stop mock server: %w", err)
+	}
+	return nil
+}
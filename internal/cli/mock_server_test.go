@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/backup"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+func TestPullAndPushAgainstMockServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "mock-customer"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN:    "main",
+			Skills: []*httpmock.Skill{{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello"}},
+		}}}}}},
+	}, "secret")
+	client, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"mock-customer\"\napi_key = \"secret\"\n  [[customers.projects]]\n  idn = \"shop\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	app := New(&stdout, &stderr)
+	if err := app.Execute(context.Background(), []string{"pull", "--strict-api"}); err != nil {
+		t.Fatalf("pull: %v\n%s", err, stderr.String())
+	}
+	if strings.Contains(stderr.String(), "unknown field") {
+		t.Fatalf("expected the mock server to match the client's schema, got:\n%s", stderr.String())
+	}
+	if !regexp.MustCompile(`(?m)^mock-customer\s+1\s+1\s+1\s+0\s+0\s+0\s+0\s+\S+\s+\d+$`).MatchString(stdout.String()) {
+		t.Fatalf("expected a pull summary row for the customer, got:\n%s", stdout.String())
+	}
+	scriptPath := filepath.Join("mock-customer", "shop", "bot", "flows", "main", "greet.nsl")
+	content, err := os.ReadFile(scriptPath)
+	if err != nil || string(content) != "Hello" {
+		t.Fatalf("expected the pulled script, got %q, %v", content, err)
+	}
+
+	if err := os.WriteFile(scriptPath, []byte("Hello again"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if err := app.Execute(context.Background(), []string{"push", "--force", "--allow-dirty"}); err != nil {
+		t.Fatalf("push: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Push summary") || !regexp.MustCompile(`(?m)^mock-customer\s+1\s+0\s+0\s+0\s+0\s+1\s`).MatchString(stdout.String()) {
+		t.Fatalf("expected a push summary row for the customer, got:\n%s", stdout.String())
+	}
+
+	flow := server.Snapshot().Projects[0].Agents[0].Flows[0]
+	if skill := flow.Skills[0]; skill.PromptScript != "Hello again" || len(skill.Versions) != 1 || skill.Versions[0].PromptScript != "Hello" {
+		t.Fatalf("expected the pushed script with the old one kept as a version, got %+v", skill)
+	}
+	if flow.Published != 1 {
+		t.Fatalf("expected the flow to be published once, got %d", flow.Published)
+	}
+
+	stderr.Reset()
+	if err := app.Execute(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stderr.String(), "mock-server") {
+		t.Fatalf("expected mock-server to be hidden from the usage text:\n%s", stderr.String())
+	}
+}
+
+func TestPushNormalizesCRLFAgainstMockServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Cleanup(func() { _ = util.ConfigureLineEndings("") })
+
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "mock-customer"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN:    "main",
+			Skills: []*httpmock.Skill{{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello\nWorld\n"}},
+		}}}}}},
+	}, "secret")
+	client, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\nautocrlf = \"input\"\n\n[[customers]]\nidn = \"mock-customer\"\napi_key = \"secret\"\n  [[customers.projects]]\n  idn = \"shop\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	app := New(&stdout, &stderr)
+	if err := app.Execute(context.Background(), []string{"pull"}); err != nil {
+		t.Fatalf("pull: %v\n%s", err, stderr.String())
+	}
+
+	// An editor that saves with CRLF leaves nothing to push.
+	scriptPath := filepath.Join("mock-customer", "shop", "bot", "flows", "main", "greet.nsl")
+	if err := os.WriteFile(scriptPath, []byte("Hello\r\nWorld\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Execute(context.Background(), []string{"push", "--force", "--allow-dirty"}); err != nil {
+		t.Fatalf("push: %v\n%s", err, stderr.String())
+	}
+	if skill := server.Snapshot().Projects[0].Agents[0].Flows[0].Skills[0]; len(skill.Versions) != 0 {
+		t.Fatalf("expected a line-ending change not to be pushed, got %+v", skill)
+	}
+
+	if err := os.WriteFile(scriptPath, []byte("Hello\r\nThere\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Execute(context.Background(), []string{"push", "--force", "--allow-dirty"}); err != nil {
+		t.Fatalf("push: %v\n%s", err, stderr.String())
+	}
+	if skill := server.Snapshot().Projects[0].Agents[0].Flows[0].Skills[0]; skill.PromptScript != "Hello\nThere\n" {
+		t.Fatalf("expected the edit to be uploaded with LF line endings, got %q", skill.PromptScript)
+	}
+}
+
+func TestGrepRemoteAgainstMockServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "mock-customer"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN: "main",
+			Skills: []*httpmock.Skill{
+				{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello\n{{ old_attr }}"},
+				{IDN: "bye", RunnerType: "nsl", PromptScript: "Bye"},
+			},
+		}}}}}},
+	}, "secret")
+	client, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"mock-customer\"\napi_key = \"secret\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	app := New(&stdout, &stderr)
+	if err := app.Execute(context.Background(), []string{"grep", "--remote", "old_attr"}); err != nil {
+		t.Fatalf("grep: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "shop/bot/main/greet:2:4: {{ old_attr }}") || strings.Contains(stdout.String(), "bye") {
+		t.Fatalf("expected only the greet match, got:\n%s", stdout.String())
+	}
+
+	if err := app.Execute(context.Background(), []string{"grep", "--remote", "missing"}); exitCode(err) != exitGeneric {
+		t.Fatalf("expected no match to exit with %d, got %v", exitGeneric, err)
+	}
+}
+
+func TestBackupAgainstMockServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "mock-customer"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN:    "main",
+			Skills: []*httpmock.Skill{{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello"}},
+		}}}}}},
+		Attributes: []*httpmock.Attribute{{ID: "a1", IDN: "secret_attr", Value: "x", IsHidden: true}},
+	}, "secret")
+	client, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"mock-customer\"\napi_key = \"secret\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	app := New(&stdout, &stderr)
+	if err := app.Execute(context.Background(), []string{"backup", "--dir", "out"}); err != nil {
+		t.Fatalf("backup: %v\n%s", err, stderr.String())
+	}
+	archives, err := filepath.Glob(filepath.Join("out", "mock-customer-*.tar.gz"))
+	if err != nil || len(archives) != 1 {
+		t.Fatalf("expected one archive, got %v (%v)", archives, err)
+	}
+
+	file, err := os.Open(archives[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := backup.Verify(file)
+	_ = file.Close()
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if manifest.Counts != (backup.Counts{Projects: 1, Agents: 1, Flows: 1, Skills: 1, Attributes: 1}) {
+		t.Fatalf("unexpected counts: %+v", manifest.Counts)
+	}
+	if _, ok := manifest.Files["projects/shop/agents/bot/flows/main/skills/greet.json"]; !ok {
+		t.Fatalf("expected the skill in the backup, got %v", manifest.Files)
+	}
+
+	stdout.Reset()
+	if err := app.Execute(context.Background(), []string{"backup", "verify", archives[0]}); err != nil {
+		t.Fatalf("backup verify: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "match the manifest") {
+		t.Fatalf("expected verify summary, got:\n%s", stdout.String())
+	}
+}
+
+func TestPushUploadsEditMergeAgainstMockServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "mock-customer"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN:    "main",
+			Skills: []*httpmock.Skill{{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello"}},
+		}}}}}},
+	}, "secret")
+	client, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"mock-customer\"\napi_key = \"secret\"\n  [[customers.projects]]\n  idn = \"shop\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	app := New(&stdout, &stderr)
+	if err := app.Execute(context.Background(), []string{"pull"}); err != nil {
+		t.Fatalf("pull: %v\n%s", err, stderr.String())
+	}
+	scriptPath := filepath.Join("mock-customer", "shop", "bot", "flows", "main", "greet.nsl")
+	if err := os.WriteFile(scriptPath, []byte("Hello locally"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Someone edits the skill in the platform UI meanwhile.
+	skillID := server.Snapshot().Projects[0].Agents[0].Flows[0].Skills[0].ID
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/designer/flows/skills/"+skillID, strings.NewReader(`{"idn":"greet","runner_type":"nsl","prompt_script":"Hello from the UI"}`))
+	req.Header.Set("Authorization", "Bearer token")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("remote edit: %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	// The editor stands in for a hand merge of both versions.
+	t.Setenv("EDITOR", "sh "+filepath.Join(t.TempDir(), "merge.sh"))
+	editor := strings.Fields(os.Getenv("EDITOR"))[1]
+	if err := os.WriteFile(editor, []byte("printf 'Hello from the UI, locally' > \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.WriteString("e\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() {
+		os.Stdin = originalStdin
+		_ = stdin.Close()
+	})
+
+	if err := app.Execute(context.Background(), []string{"pull"}); err != nil {
+		t.Fatalf("pull with merge: %v\n%s", err, stderr.String())
+	}
+	if content, _ := os.ReadFile(scriptPath); string(content) != "Hello from the UI, locally" {
+		t.Fatalf("expected the merged script on disk, got %q", content)
+	}
+
+	if err := app.Execute(context.Background(), []string{"push", "--force", "--allow-dirty"}); err != nil {
+		t.Fatalf("push: %v\n%s", err, stderr.String())
+	}
+	if skill := server.Snapshot().Projects[0].Agents[0].Flows[0].Skills[0]; skill.PromptScript != "Hello from the UI, locally" {
+		t.Fatalf("expected push to upload the merge, got %q", skill.PromptScript)
+	}
+}
+
+func TestPublishProtectedCustomerAgainstMockServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "mock-customer"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN:    "main",
+			Skills: []*httpmock.Skill{{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello"}},
+		}}}}}},
+	}, "secret")
+	client, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"mock-customer\"\napi_key = \"secret\"\nprotected = true\n  [[customers.projects]]\n  idn = \"shop\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	app := New(&stdout, &stderr)
+	if err := app.Execute(context.Background(), []string{"pull"}); err != nil {
+		t.Fatalf("pull: %v\n%s", err, stderr.String())
+	}
+	err := app.Execute(context.Background(), []string{"--yes", "publish", "--all"})
+	if err == nil || !strings.Contains(err.Error(), "customer mock-customer is protected") {
+		t.Fatalf("expected publish to a protected customer to need confirmation, got %v", err)
+	}
+	if published := server.Snapshot().Projects[0].Agents[0].Flows[0].Published; published != 0 {
+		t.Fatalf("expected nothing to be published, got %d", published)
+	}
+
+	if err := app.Execute(context.Background(), []string{"publish", "--all", "--confirm-customer", "mock-customer"}); err != nil {
+		t.Fatalf("publish: %v\n%s", err, stderr.String())
+	}
+	if published := server.Snapshot().Projects[0].Agents[0].Flows[0].Published; published != 1 {
+		t.Fatalf("expected the confirmed publish to go through, got %d", published)
+	}
+}
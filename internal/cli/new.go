@@ -0,0 +1,387 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/serialize"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// defaultRunnerType is used for new flows and skills when nothing in the local state suggests another runner.
+const defaultRunnerType = "nsl"
+
+// NewCommand scaffolds skills and flows inside pulled projects so push can create them remotely.
+type NewCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	project  *string
+	agent    *string
+	flow     *string
+	title    *string
+	runner   *string
+	model    *string
+}
+
+// scaffoldTarget is a pulled project location that a new skill or flow can be added to.
+type scaffoldTarget struct {
+	CustomerIDN  string
+	CustomerType string
+	OutputRoot   string
+	ProjectIDN   string
+	ProjectSlug  string
+	AgentIDN     string
+	FlowIDN      string
+	Project      state.ProjectData
+}
+
+func (t scaffoldTarget) String() string {
+	parts := []string{t.CustomerIDN, t.ProjectIDN, t.AgentIDN}
+	if t.FlowIDN != "" {
+		parts = append(parts, t.FlowIDN)
+	}
+	return strings.Join(parts, "/")
+}
+
+// NewNewCommand constructs a new command.
+func NewNewCommand(stdout, stderr io.Writer) *NewCommand {
+	return &NewCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *NewCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *NewCommand) Name() string {
+	return "new"
+}
+
+func (c *NewCommand) Summary() string {
+	return "Scaffold a new skill or flow in a pulled project"
+}
+
+func (c *NewCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias owning the project")
+	c.project = fs.String("project", "", "project IDN (required when the flow or agent IDN is ambiguous)")
+	c.agent = fs.String("agent", "", "agent IDN (required for new flows)")
+	c.flow = fs.String("flow", "", "flow IDN (required for new skills)")
+	c.title = fs.String("title", "", "title (defaults to the IDN)")
+	c.runner = fs.String("runner", "", "runner type (defaults to the flow's default runner)")
+	c.model = fs.String("model", "", "model as provider/model (defaults to the flow's default model)")
+}
+
+func (c *NewCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo new <skill|flow> <idn> [--flow <idn>] [--agent <idn>] [--project <idn>] [--customer <idn>]"
+	if len(args) != 2 {
+		return errors.New(usage)
+	}
+	idn := strings.TrimSpace(args[1])
+	if !validScaffoldIDN(idn) {
+		return fmt.Errorf("invalid IDN %q: use letters, digits, '_' and '-'", args[1])
+	}
+
+	switch args[0] {
+	case "skill":
+		flowIDN := flagValue(c.flow)
+		if flowIDN == "" {
+			return errors.New("usage: newo new skill <idn> --flow <flow_idn> [--agent <idn>] [--project <idn>]")
+		}
+		target, err := c.resolve(flowIDN)
+		if err != nil {
+			return err
+		}
+		return c.newSkill(target, idn)
+	case "flow":
+		if flagValue(c.agent) == "" {
+			return errors.New("usage: newo new flow <idn> --agent <agent_idn> [--project <idn>]")
+		}
+		target, err := c.resolve("")
+		if err != nil {
+			return err
+		}
+		return c.newFlow(target, idn)
+	default:
+		return fmt.Errorf("unknown new subcommand %q; %s", args[0], usage)
+	}
+}
+
+// resolve finds the single pulled agent (or flow, when flowIDN is set) matching the flags.
+func (c *NewCommand) resolve(flowIDN string) (scaffoldTarget, error) {
+	env, err := config.LoadEnv()
+	if err != nil {
+		return scaffoldTarget{}, err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return scaffoldTarget{}, err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return scaffoldTarget{}, err
+	}
+
+	customerFilter := flagValue(c.customer)
+	seen := map[string]bool{}
+	var matches []scaffoldTarget
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || seen[strings.ToLower(idn)] || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+			continue
+		}
+		seen[strings.ToLower(idn)] = true
+		projectMap, err := state.LoadProjectMap(idn)
+		if err != nil {
+			return scaffoldTarget{}, err
+		}
+		matches = append(matches, scaffoldTargets(idn, entry.Type, projectMap, flagValue(c.project), flagValue(c.agent), flowIDN)...)
+	}
+
+	what := "agent " + flagValue(c.agent)
+	if flowIDN != "" {
+		what = "flow " + flowIDN
+	}
+	switch len(matches) {
+	case 0:
+		return scaffoldTarget{}, fmt.Errorf("%s not found in local state; run `newo pull` first", what)
+	case 1:
+		target := matches[0]
+		target.OutputRoot = env.OutputRoot
+		target.ProjectSlug = projectSlugFromState(target.ProjectIDN, target.Project)
+		return target, nil
+	}
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, match.String())
+	}
+	sort.Strings(names)
+	return scaffoldTarget{}, fmt.Errorf("%s is ambiguous (%s); narrow it down with --customer, --project, or --agent", what, strings.Join(names, ", "))
+}
+
+// scaffoldTargets lists the agents, or flows when flowIDN is set, of a project map that match the filters.
+func scaffoldTargets(customerIDN, customerType string, projectMap state.ProjectMap, projectIDN, agentIDN, flowIDN string) []scaffoldTarget {
+	var matches []scaffoldTarget
+	for currentProject, projectData := range projectMap.Projects {
+		if projectIDN != "" && !strings.EqualFold(currentProject, projectIDN) {
+			continue
+		}
+		for currentAgent, agentData := range projectData.Agents {
+			if agentIDN != "" && !strings.EqualFold(currentAgent, agentIDN) {
+				continue
+			}
+			base := scaffoldTarget{
+				CustomerIDN:  customerIDN,
+				CustomerType: customerType,
+				ProjectIDN:   currentProject,
+				AgentIDN:     currentAgent,
+				Project:      projectData,
+			}
+			if flowIDN == "" {
+				matches = append(matches, base)
+				continue
+			}
+			for currentFlow := range agentData.Flows {
+				if strings.EqualFold(currentFlow, flowIDN) {
+					base.FlowIDN = currentFlow
+					matches = append(matches, base)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func (c *NewCommand) newSkill(target scaffoldTarget, skillIDN string) error {
+	flowData := target.Project.Agents[target.AgentIDN].Flows[target.FlowIDN]
+	for existing := range flowData.Skills {
+		if strings.EqualFold(existing, skillIDN) {
+			return fmt.Errorf("skill %s already exists in %s", existing, target)
+		}
+	}
+
+	runner := firstNonEmpty(flagValue(c.runner), flowData.RunnerType, defaultRunnerType)
+	model := platform.ModelConfig{ProviderIDN: flowData.Model["provider_idn"], ModelIDN: flowData.Model["model_idn"]}
+	if value := flagValue(c.model); value != "" {
+		parsed, err := parseModelFlag(value)
+		if err != nil {
+			return err
+		}
+		model = parsed
+	}
+
+	root := target.OutputRoot
+	metaPath := fsutil.ExportSkillMetadataPath(root, target.CustomerType, target.CustomerIDN, target.ProjectSlug, target.AgentIDN, target.FlowIDN, skillIDN)
+	scriptPath := fsutil.ExportSkillScriptPath(root, target.CustomerType, target.CustomerIDN, target.ProjectSlug, target.AgentIDN, target.FlowIDN, skillIDN+"."+platform.ScriptExtension(runner))
+	for _, path := range []string{metaPath, scriptPath} {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", filepath.ToSlash(path))
+		}
+	}
+
+	meta, err := serialize.SkillMetadata(platform.Skill{
+		IDN:        skillIDN,
+		Title:      firstNonEmpty(flagValue(c.title), skillIDN),
+		RunnerType: runner,
+		Model:      model,
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeScaffoldFile(metaPath, meta); err != nil {
+		return err
+	}
+	if err := writeScaffoldFile(scriptPath, nil); err != nil {
+		return err
+	}
+	c.console.Success("Created %s", filepath.ToSlash(scriptPath))
+	c.console.Success("Created %s", filepath.ToSlash(metaPath))
+	c.console.Info("Run `newo push` to create the skill remotely.")
+	return nil
+}
+
+func (c *NewCommand) newFlow(target scaffoldTarget, flowIDN string) error {
+	agentData := target.Project.Agents[target.AgentIDN]
+	for existing := range agentData.Flows {
+		if strings.EqualFold(existing, flowIDN) {
+			return fmt.Errorf("flow %s already exists in %s", existing, target)
+		}
+	}
+	if !fsutil.HasAgentDirs(target.CustomerType) && len(target.Project.Agents) > 1 {
+		return fmt.Errorf("project %s keeps the flows of %d agents in one directory, so push cannot tell which agent a new flow belongs to; create it in the designer instead", target.ProjectIDN, len(target.Project.Agents))
+	}
+
+	runner, model := commonFlowDefaults(agentData)
+	runner = firstNonEmpty(flagValue(c.runner), runner, defaultRunnerType)
+	if value := flagValue(c.model); value != "" {
+		parsed, err := parseModelFlag(value)
+		if err != nil {
+			return err
+		}
+		model = map[string]string{"provider_idn": parsed.ProviderIDN, "model_idn": parsed.ModelIDN}
+	}
+	if model == nil {
+		model = map[string]string{"provider_idn": "", "model_idn": ""}
+	}
+
+	root := target.OutputRoot
+	flowDir := fsutil.ExportFlowDir(root, target.CustomerType, target.CustomerIDN, target.ProjectSlug, target.AgentIDN, flowIDN)
+	if _, err := os.Stat(flowDir); err == nil {
+		return fmt.Errorf("%s already exists", filepath.ToSlash(flowDir))
+	}
+	data, err := yaml.Marshal(flowMetadataYAML{
+		IDN:               flowIDN,
+		Title:             firstNonEmpty(flagValue(c.title), flowIDN),
+		DefaultRunnerType: runner,
+		DefaultModel:      model,
+		Events:            []state.FlowEventInfo{},
+		StateFields:       []state.FlowStateInfo{},
+	})
+	if err != nil {
+		return fmt.Errorf("encode flow metadata: %w", err)
+	}
+	metaPath := fsutil.ExportFlowMetadataPath(root, target.CustomerType, target.CustomerIDN, target.ProjectSlug, target.AgentIDN, flowIDN)
+	if err := writeScaffoldFile(metaPath, data); err != nil {
+		return err
+	}
+	c.console.Success("Created %s", filepath.ToSlash(metaPath))
+	c.console.Info("Add skills with `newo new skill <idn> --flow %s`, then run `newo push` to create the flow remotely.", flowIDN)
+	return nil
+}
+
+// commonFlowDefaults returns the runner type and model used by most flows of the agent.
+func commonFlowDefaults(agent state.AgentData) (string, map[string]string) {
+	type defaults struct {
+		runner string
+		model  map[string]string
+	}
+	counts := map[string]int{}
+	values := map[string]defaults{}
+	for _, flow := range agent.Flows {
+		key := flow.RunnerType + "\x00" + flow.Model["provider_idn"] + "\x00" + flow.Model["model_idn"]
+		counts[key]++
+		values[key] = defaults{runner: flow.RunnerType, model: flow.Model}
+	}
+	best := ""
+	for key, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && key < best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return "", nil
+	}
+	return values[best].runner, values[best].model
+}
+
+func parseModelFlag(value string) (platform.ModelConfig, error) {
+	provider, model, ok := strings.Cut(value, "/")
+	provider, model = strings.TrimSpace(provider), strings.TrimSpace(model)
+	if !ok || provider == "" || model == "" {
+		return platform.ModelConfig{}, fmt.Errorf("invalid --model %q: expected provider/model", value)
+	}
+	return platform.ModelConfig{ProviderIDN: provider, ModelIDN: model}, nil
+}
+
+func validScaffoldIDN(idn string) bool {
+	if idn == "" {
+		return false
+	}
+	for _, r := range idn {
+		if !(r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeScaffoldFile(path string, content []byte) error {
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.ToSlash(path), err)
+	}
+	return nil
+}
+
+func flagValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+func TestNewSkillUsesFlowDefaults(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"booking": {
+			ProjectID: "p-1",
+			Agents: map[string]state.AgentData{
+				"receptionist": {
+					ID: "a-1",
+					Flows: map[string]state.FlowData{
+						"main": {
+							ID:         "f-1",
+							RunnerType: "guidance",
+							Model:      map[string]string{"provider_idn": "openai", "model_idn": "gpt-4o"},
+							Skills:     map[string]state.SkillMetadataInfo{"greet": {ID: "s-1", RunnerType: "guidance"}},
+						},
+					},
+				},
+				"billing": {ID: "a-2", Flows: map[string]state.FlowData{"main": {ID: "f-2"}}},
+			},
+		},
+	}}
+
+	if got := scaffoldTargets("acme", "", projectMap, "", "", "main"); len(got) != 2 {
+		t.Fatalf("expected flow main to be ambiguous across agents, got %v", got)
+	}
+	targets := scaffoldTargets("acme", "", projectMap, "booking", "receptionist", "MAIN")
+	if len(targets) != 1 {
+		t.Fatalf("expected a single target, got %v", targets)
+	}
+	target := targets[0]
+	target.OutputRoot = "projects"
+	target.ProjectSlug = "booking"
+
+	cmd := NewNewCommand(&bytes.Buffer{}, &bytes.Buffer{})
+	cmd.RegisterFlags(flag.NewFlagSet("new", flag.ContinueOnError))
+	if err := cmd.newSkill(target, "greet"); err == nil {
+		t.Fatalf("expected existing skill to be rejected")
+	}
+	if err := cmd.newSkill(target, "farewell"); err != nil {
+		t.Fatalf("newSkill: %v", err)
+	}
+
+	flowDir := filepath.Join("projects", "acme", "booking", "receptionist", "flows", "main")
+	if _, err := os.Stat(filepath.Join(flowDir, "farewell.guidance")); err != nil {
+		t.Fatalf("expected script file: %v", err)
+	}
+	meta, err := os.ReadFile(filepath.Join(flowDir, "farewell.meta.yaml"))
+	if err != nil {
+		t.Fatalf("read metadata: %v", err)
+	}
+	for _, want := range []string{"idn: farewell", "runner_type: guidance", "provideridn: openai", "modelidn: gpt-4o"} {
+		if !strings.Contains(string(meta), want) {
+			t.Fatalf("expected %q in metadata:\n%s", want, meta)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/notify"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// sendNotifications posts failed runs and runs that changed the platform to the configured webhooks.
+// Delivery problems only warn: the command itself has already finished.
+func sendNotifications(ctx context.Context, writer *console.Writer, targets []config.Notification, events []notify.Event) {
+	if len(targets) == 0 {
+		return
+	}
+	var relevant []notify.Event
+	for _, event := range events {
+		if !event.Success || event.Changed() {
+			relevant = append(relevant, event)
+		}
+	}
+	if len(relevant) == 0 {
+		return
+	}
+	// An interrupted command still reports its failure, so the webhook calls must outlive the cancellation.
+	if err := notify.NewSender(nil).Send(context.WithoutCancel(ctx), targets, relevant); err != nil {
+		writer.Warn("Notification failed: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/nsl/ast"
+	"github.com/twinmind/newo-tool/internal/nsl/lexer"
+	"github.com/twinmind/newo-tool/internal/nsl/parser"
+	"github.com/twinmind/newo-tool/internal/nsl/printer"
+	"github.com/twinmind/newo-tool/internal/nsl/xref"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// NSLCommand converts NSL scripts to their typed JSON AST and back, so external tools can analyse or
+// rewrite skills without reimplementing the parser, and cross-references the symbols of a project.
+type NSLCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+	json    *bool
+}
+
+// NewNSLCommand constructs an nsl command.
+func NewNSLCommand(stdout, stderr io.Writer) *NSLCommand {
+	return &NSLCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *NSLCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *NSLCommand) Name() string {
+	return "nsl"
+}
+
+func (c *NSLCommand) Summary() string {
+	return "Parse NSL scripts into a JSON AST, render an AST back to source, or cross-reference symbols"
+}
+
+func (c *NSLCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.json = fs.Bool("json", false, "print the parsed AST or the cross-reference as JSON")
+}
+
+func (c *NSLCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo nsl parse <file> [--json] | newo nsl render <ast.json> | newo nsl xref <project-dir> [--json]"
+	if len(args) != 2 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "parse":
+		return c.parse(args[1], c.json != nil && *c.json)
+	case "render":
+		return c.render(args[1])
+	case "xref":
+		return c.xref(args[1], c.json != nil && *c.json)
+	default:
+		return fmt.Errorf("unknown nsl subcommand %q; %s", args[0], usage)
+	}
+}
+
+func (c *NSLCommand) parse(path string, asJSON bool) error {
+	source, err := readNSLInput(path)
+	if err != nil {
+		return err
+	}
+	p := parser.New(lexer.NewTemplate(string(source)))
+	program := p.ParseProgram()
+	if errs := p.SyntaxErrors(); len(errs) > 0 {
+		return fmt.Errorf("parse %s:\n%s", path, formatSyntaxErrors(string(source), errs))
+	}
+
+	if !asJSON {
+		c.console.Success("Parsed %s: %d top-level statement(s).", path, len(program.Statements))
+		return nil
+	}
+	data, err := json.MarshalIndent(program, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode AST: %w", err)
+	}
+	_, err = fmt.Fprintln(c.stdout, string(data))
+	return err
+}
+
+func (c *NSLCommand) render(path string) error {
+	data, err := readNSLInput(path)
+	if err != nil {
+		return err
+	}
+	var program ast.Program
+	if err := json.Unmarshal(data, &program); err != nil {
+		return fmt.Errorf("decode AST %s: %w", path, err)
+	}
+	_, err = io.WriteString(c.stdout, printer.NewTemplate().Print(&program))
+	return err
+}
+
+func (c *NSLCommand) xref(dir string, asJSON bool) error {
+	report, err := xref.Build(userPath(dir))
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode cross-reference: %w", err)
+		}
+		_, err = fmt.Fprintln(c.stdout, string(data))
+		return err
+	}
+
+	titles := map[string]string{xref.KindStateField: "State fields", xref.KindAttribute: "Attributes", xref.KindVariable: "Set variables"}
+	kind, flagged := "", 0
+	for _, symbol := range report.Symbols {
+		if symbol.Kind != kind {
+			kind = symbol.Kind
+			c.console.Section(titles[kind])
+		}
+		header := "  " + symbol.Name
+		if len(symbol.Declared) > 0 {
+			header += fmt.Sprintf(" (declared in %s)", strings.Join(symbol.Declared, ", "))
+		}
+		c.console.RawLine("%s", header)
+		for _, ref := range symbol.References {
+			c.console.RawLine("    %-5s  %s:%d:%d", ref.Access, ref.Path, ref.Line, ref.Column)
+		}
+		for _, problem := range symbol.Problems {
+			c.console.RawLine("    ! %s", problem)
+		}
+		if len(symbol.Problems) > 0 {
+			flagged++
+		}
+	}
+	for _, skipped := range report.Skipped {
+		c.console.Warn("Skipped %s: %s", skipped.Path, skipped.Error)
+	}
+	if len(report.Symbols) == 0 {
+		c.console.Info("No state fields, attributes, or set variables referenced under %s.", dir)
+		return nil
+	}
+	c.console.Info("%d symbol(s), %d flagged.", len(report.Symbols), flagged)
+	return nil
+}
+
+// readNSLInput reads a file argument; "-" reads stdin so the subcommands can be piped together.
+func readNSLInput(path string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(userPath(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// formatSyntaxErrors lists parser errors, each followed by the offending source line and a caret.
+func formatSyntaxErrors(source string, errs []*parser.Error) string {
+	var out strings.Builder
+	for i, err := range errs {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("  " + err.Error())
+		if context := err.Context(source); context != "" {
+			for _, line := range strings.Split(context, "\n") {
+				out.WriteString("\n    " + line)
+			}
+		}
+	}
+	return out.String()
+}
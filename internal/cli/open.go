@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// OpenCommand opens the NEWO designer page for a local project, flow, or skill file.
+type OpenCommand struct {
+	stdout    io.Writer
+	stderr    io.Writer
+	console   *console.Writer
+	customer  *string
+	printOnly *bool
+}
+
+// designerTarget identifies the remote objects behind a local path.
+type designerTarget struct {
+	ProjectIDN string
+	ProjectID  string
+	FlowIDN    string
+	FlowID     string
+	SkillIDN   string
+	SkillID    string
+	// ScriptPath is the export path of the skill script when a skill was matched.
+	ScriptPath string
+}
+
+// NewOpenCommand constructs an open command.
+func NewOpenCommand(stdout, stderr io.Writer) *OpenCommand {
+	return &OpenCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *OpenCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *OpenCommand) Name() string {
+	return "open"
+}
+
+func (c *OpenCommand) Summary() string {
+	return "Open the designer page for a local project, flow, or skill file"
+}
+
+func (c *OpenCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias owning the path")
+	c.printOnly = fs.Bool("print", false, "print the URL instead of opening a browser")
+}
+
+func (c *OpenCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return errors.New("usage: newo open <path> [--customer <idn>] [--print]")
+	}
+	target, err := filepath.Abs(userPath(args[0]))
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", args[0], err)
+	}
+	customerFilter := ""
+	if c.customer != nil {
+		customerFilter = strings.TrimSpace(*c.customer)
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Resolution only needs the local project map, so customers are identified without logging in.
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+			continue
+		}
+		projectMap, err := state.LoadProjectMap(idn)
+		if err != nil {
+			return err
+		}
+		found, ok := locateDesignerTarget(target, env.OutputRoot, entry.Type, idn, projectMap)
+		if !ok {
+			continue
+		}
+		if found.ProjectID == "" {
+			return fmt.Errorf("project %s has no remote identifier; run `newo pull`", found.ProjectIDN)
+		}
+
+		url := designerURL(session.BaseURL(env, entry), found)
+		if c.printOnly != nil && *c.printOnly {
+			c.console.RawLine("%s", url)
+			return nil
+		}
+		if err := openBrowser(ctx, url); err != nil {
+			c.console.Warn("Could not open a browser: %v", err)
+			c.console.RawLine("%s", url)
+			return nil
+		}
+		c.console.Success("Opened %s", url)
+		return nil
+	}
+	return fmt.Errorf("%s does not belong to a pulled project", filepath.ToSlash(args[0]))
+}
+
+// locateDesignerTarget matches an absolute path against the export layout of every project in the map.
+// Skill scripts and skill metadata resolve to the skill, anything else inside a flow directory to the
+// flow, and any other path inside the project directory to the project.
+func locateDesignerTarget(target, outputRoot, customerType, customerIDN string, projectMap state.ProjectMap) (designerTarget, bool) {
+	for projectIDN, projectData := range projectMap.Projects {
+		slug := projectSlugFromState(projectIDN, projectData)
+		projectDir := fsutil.ExportProjectDir(outputRoot, customerType, customerIDN, slug)
+		if !pathWithin(target, projectDir) {
+			continue
+		}
+		result := designerTarget{ProjectIDN: projectIDN, ProjectID: projectData.ProjectID}
+		for agentIDN, agentData := range projectData.Agents {
+			for flowIDN, flowData := range agentData.Flows {
+				flowDir := fsutil.ExportFlowDir(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN)
+				if !pathWithin(target, flowDir) {
+					continue
+				}
+				result.FlowIDN = flowIDN
+				result.FlowID = flowData.ID
+				for skillIDN, skill := range flowData.Skills {
+					script := fsutil.ExportSkillScriptPath(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN, skillIDN+"."+platform.ScriptExtension(skill.RunnerType))
+					meta := fsutil.ExportSkillMetadataPath(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN, skillIDN)
+					if samePath(target, script) || samePath(target, meta) {
+						result.SkillIDN = skillIDN
+						result.SkillID = skill.ID
+						result.ScriptPath = script
+						break
+					}
+				}
+				return result, true
+			}
+		}
+		return result, true
+	}
+	return designerTarget{}, false
+}
+
+// designerURL builds the web designer link for the most specific object that has a remote identifier.
+func designerURL(baseURL string, target designerTarget) string {
+	url := strings.TrimRight(baseURL, "/") + "/designer/projects/" + target.ProjectID
+	if target.FlowID == "" {
+		return url
+	}
+	url += "/flows/" + target.FlowID
+	if target.SkillID == "" {
+		return url
+	}
+	return url + "/skills/" + target.SkillID
+}
+
+func pathWithin(target, dir string) bool {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(abs, target)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func samePath(target, path string) bool {
+	abs, err := filepath.Abs(path)
+	return err == nil && abs == target
+}
+
+func openBrowser(ctx context.Context, url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "open", url)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.CommandContext(ctx, "xdg-open", url)
+	}
+	return cmd.Start()
+}
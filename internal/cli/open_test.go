@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+func TestLocateDesignerTarget(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"booking": {
+			ProjectID: "p-1",
+			Agents: map[string]state.AgentData{
+				"receptionist": {
+					ID: "a-1",
+					Flows: map[string]state.FlowData{
+						"main": {
+							ID: "f-1",
+							Skills: map[string]state.SkillMetadataInfo{
+								"greet": {ID: "s-1", RunnerType: "nsl"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"projects/acme/booking/receptionist/flows/main/greet.nsl", "https://app.newo.ai/designer/projects/p-1/flows/f-1/skills/s-1"},
+		{"projects/acme/booking/receptionist/flows/main/metadata.yaml", "https://app.newo.ai/designer/projects/p-1/flows/f-1"},
+		{"projects/acme/booking/flows.yaml", "https://app.newo.ai/designer/projects/p-1"},
+	}
+	for _, tc := range cases {
+		target, err := filepath.Abs(tc.path)
+		if err != nil {
+			t.Fatalf("abs: %v", err)
+		}
+		found, ok := locateDesignerTarget(target, "projects", "", "acme", projectMap)
+		if !ok {
+			t.Fatalf("expected %s to resolve", tc.path)
+		}
+		if got := designerURL("https://app.newo.ai/", found); got != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.path, tc.want, got)
+		}
+	}
+
+	outside, _ := filepath.Abs("projects/acme/other/flows.yaml")
+	if _, ok := locateDesignerTarget(outside, "projects", "", "acme", projectMap); ok {
+		t.Fatalf("expected path outside pulled projects not to resolve")
+	}
+}
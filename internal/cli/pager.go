@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/redact"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/ui/tui"
+)
+
+// pageDiff shows a confirmation diff. A diff taller than the terminal goes through the pager, so the
+// question that follows is not buried under hundreds of scrolled lines; other diffs, and every diff
+// when the pager cannot be started, are written to the console as usual.
+func pageDiff(stdout io.Writer, con *console.Writer, text string) {
+	if text == "" {
+		return
+	}
+	if f, ok := stdout.(*os.File); ok && isTerminalFile(f) {
+		_, rows := tui.TerminalSize(f)
+		// Leave room for the prompt below the diff.
+		if strings.Count(text, "\n") > rows-2 && runPager(f, text) {
+			return
+		}
+	}
+	con.Write(text)
+}
+
+// pagerCommand returns the pager to use: $NEWO_PAGER, then $PAGER, then less -R when it is installed.
+// An empty value or cat turns paging off.
+func pagerCommand() string {
+	for _, name := range []string{"NEWO_PAGER", "PAGER"} {
+		if value, ok := os.LookupEnv(name); ok {
+			value = strings.TrimSpace(value)
+			if value == "cat" {
+				return ""
+			}
+			return value
+		}
+	}
+	if _, err := exec.LookPath("less"); err != nil {
+		return ""
+	}
+	return "less -R"
+}
+
+// runPager pipes text through the pager and reports whether it was shown. less keeps colours and
+// leaves the diff on screen when it quits, as with git, unless LESS is already set.
+func runPager(out *os.File, text string) bool {
+	command := pagerCommand()
+	if command == "" {
+		return false
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = strings.NewReader(redact.String(text))
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	// Once the pager has started the diff is its to show; a non-zero exit, such as quitting early,
+	// must not print it a second time.
+	_ = cmd.Wait()
+	return true
+}
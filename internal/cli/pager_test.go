@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("PAGER", "more")
+	t.Setenv("NEWO_PAGER", "less -S")
+	if got := pagerCommand(); got != "less -S" {
+		t.Fatalf("expected NEWO_PAGER to win, got %q", got)
+	}
+	t.Setenv("NEWO_PAGER", "cat")
+	if got := pagerCommand(); got != "" {
+		t.Fatalf("expected cat to turn paging off, got %q", got)
+	}
+	os.Unsetenv("NEWO_PAGER")
+	if got := pagerCommand(); got != "more" {
+		t.Fatalf("expected PAGER to be used, got %q", got)
+	}
+}
+
+func TestPageDiffWritesDirectlyWithoutTerminal(t *testing.T) {
+	// The pager would append the diff to this file instead of the console.
+	marker := filepath.Join(t.TempDir(), "paged")
+	t.Setenv("NEWO_PAGER", "cat > "+marker)
+
+	var stdout bytes.Buffer
+	text := strings.Repeat("line\n", 500)
+	pageDiff(&stdout, console.New(&stdout, &stdout), text)
+	if stdout.String() != text {
+		t.Fatalf("expected the diff on the console, got %d bytes", stdout.Len())
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("expected the pager not to run without a terminal, got %v", err)
+	}
+}
+
+func TestRunPagerPipesTheDiff(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command as the pager")
+	}
+	out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	t.Setenv("NEWO_PAGER", "tr a-z A-Z")
+	if !runPager(out, "diff skill.nsl\n") {
+		t.Fatal("expected the pager to run")
+	}
+	if got, _ := os.ReadFile(out.Name()); string(got) != "DIFF SKILL.NSL\n" {
+		t.Fatalf("expected the diff to go through the pager, got %q", got)
+	}
+
+	t.Setenv("NEWO_PAGER", "")
+	if runPager(out, "diff skill.nsl\n") {
+		t.Fatal("expected an empty NEWO_PAGER to turn paging off")
+	}
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// ProjectsCommand lists remote projects and shows which of them are pulled locally.
+type ProjectsCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	format   *string
+}
+
+// projectRow describes a project known remotely, locally, or both.
+type projectRow struct {
+	Customer  string `json:"customer"`
+	ID        string `json:"id"`
+	IDN       string `json:"idn"`
+	Title     string `json:"title"`
+	UpdatedAt string `json:"updated_at"`
+	Remote    bool   `json:"remote"`
+	Pulled    bool   `json:"pulled"`
+	Path      string `json:"path,omitempty"`
+}
+
+// NewProjectsCommand constructs a projects command.
+func NewProjectsCommand(stdout, stderr io.Writer) *ProjectsCommand {
+	return &ProjectsCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *ProjectsCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *ProjectsCommand) Name() string {
+	return "projects"
+}
+
+func (c *ProjectsCommand) Summary() string {
+	return "List remote projects and whether they are pulled locally"
+}
+
+func (c *ProjectsCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to list")
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *ProjectsCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo projects list [--customer <idn>] [--format text|json]"
+	if len(args) != 1 || args[0] != "list" {
+		return errors.New(usage)
+	}
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+	customerFilter := ""
+	if c.customer != nil {
+		customerFilter = strings.TrimSpace(*c.customer)
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	rows := []projectRow{}
+	registryDirty := false
+	matched := false
+	processed := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		session, err := session.New(ctx, env, entry, registry)
+		if err != nil {
+			return err
+		}
+		if session.RegistryUpdated {
+			registryDirty = true
+		}
+		if customerFilter != "" && !matchesCustomerToken(entry, session.IDN, customerFilter) {
+			continue
+		}
+		matched = true
+		key := strings.ToLower(session.IDN)
+		if processed[key] {
+			continue
+		}
+		processed[key] = true
+
+		remote, err := session.Client.ListProjects(ctx)
+		if err != nil {
+			return fmt.Errorf("list projects for %s: %w", session.IDN, err)
+		}
+		projectMap, err := state.LoadProjectMap(session.IDN)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, projectRows(session.IDN, remote, projectMap)...)
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	if registryDirty {
+		if err := registry.Save(); err != nil {
+			return err
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(rows); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+	c.printTable(rows)
+	return nil
+}
+
+// projectRows matches remote projects with the local project map by project ID or IDN.
+// Projects that exist only in the project map are reported as missing remotely.
+func projectRows(customerIDN string, remote []platform.Project, projectMap state.ProjectMap) []projectRow {
+	local := map[string]bool{}
+	var rows []projectRow
+	for _, project := range remote {
+		row := projectRow{
+			Customer:  customerIDN,
+			ID:        project.ID,
+			IDN:       project.IDN,
+			Title:     project.Title,
+			UpdatedAt: project.UpdatedAt,
+			Remote:    true,
+		}
+		for localIDN, data := range projectMap.Projects {
+			if (data.ProjectID != "" && data.ProjectID == project.ID) || strings.EqualFold(localIDN, project.IDN) {
+				row.Pulled = true
+				row.Path = projectSlugFromState(localIDN, data)
+				local[localIDN] = true
+				break
+			}
+		}
+		rows = append(rows, row)
+	}
+	for localIDN, data := range projectMap.Projects {
+		if local[localIDN] {
+			continue
+		}
+		rows = append(rows, projectRow{
+			Customer: customerIDN,
+			ID:       data.ProjectID,
+			IDN:      localIDN,
+			Pulled:   true,
+			Path:     projectSlugFromState(localIDN, data),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].IDN < rows[j].IDN
+	})
+	return rows
+}
+
+func (c *ProjectsCommand) printTable(rows []projectRow) {
+	if len(rows) == 0 {
+		c.console.Info("No projects found.")
+		return
+	}
+
+	header := []string{"PROJECT", "TITLE", "UPDATED", "LOCAL"}
+	var table [][]string
+	for idx, row := range rows {
+		if idx == 0 || rows[idx-1].Customer != row.Customer {
+			if table != nil {
+				writeTable(c.console, header, table)
+			}
+			table = [][]string{}
+			c.console.Section(fmt.Sprintf("Projects %s", strings.ToUpper(row.Customer)))
+		}
+		local := "not pulled"
+		switch {
+		case row.Pulled && !row.Remote:
+			local = "pulled (missing remotely)"
+		case row.Pulled:
+			local = "pulled"
+		}
+		table = append(table, []string{row.IDN, orDash(row.Title), orDash(row.UpdatedAt), local})
+	}
+	writeTable(c.console, header, table)
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+func TestProjectRows(t *testing.T) {
+	remote := []platform.Project{
+		{ID: "p-1", IDN: "booking", Title: "Booking"},
+		{ID: "p-2", IDN: "support", Title: "Support"},
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"booking": {ProjectID: "p-1", Path: "booking-v2"},
+		"legacy":  {ProjectID: "p-9"},
+	}}
+
+	rows := projectRows("acme", remote, projectMap)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d: %+v", len(rows), rows)
+	}
+
+	byIDN := map[string]projectRow{}
+	for _, row := range rows {
+		byIDN[row.IDN] = row
+	}
+	if row := byIDN["booking"]; !row.Remote || !row.Pulled || row.Path != "booking-v2" {
+		t.Fatalf("expected booking to be pulled into booking-v2, got %+v", row)
+	}
+	if row := byIDN["support"]; !row.Remote || row.Pulled {
+		t.Fatalf("expected support to be remote only, got %+v", row)
+	}
+	if row := byIDN["legacy"]; row.Remote || !row.Pulled || row.ID != "p-9" {
+		t.Fatalf("expected legacy to be missing remotely, got %+v", row)
+	}
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/ui/tui"
+)
+
+// errNoAnswer is returned when a question needs a typed answer that cannot be given: by --yes and --no,
+// and once the input is exhausted.
+var errNoAnswer = errors.New("the question needs a typed answer")
+
+// Prompter answers the questions commands ask before they change files or the platform. Commands get
+// it from Execute, which picks one by --yes, --no, and whether stdin is a terminal.
+type Prompter interface {
+	// Confirm shows question and returns the answer in lower case, trimmed; an empty answer takes the
+	// question's default.
+	Confirm(con *console.Writer, question string) (string, error)
+	// Input shows question and returns the trimmed line typed in reply, such as a customer IDN. It fails
+	// with errNoAnswer once the input is exhausted.
+	Input(con *console.Writer, question string) (string, error)
+	// Secret reads a line like Input without showing it, for API keys.
+	Secret(con *console.Writer, question string) (string, error)
+}
+
+// NewPrompter returns a prompter reading answers from in: a terminal prompter when in is a terminal,
+// and a stream prompter, which echoes the answers it reads, otherwise.
+func NewPrompter(in io.Reader) Prompter {
+	if f, ok := in.(*os.File); ok && isTerminalFile(f) {
+		return &stdinPrompter{reader: bufio.NewReader(in), terminal: f}
+	}
+	return &stdinPrompter{reader: bufio.NewReader(in), echo: true}
+}
+
+// stdinPrompter reads one line per question. All questions share one buffered reader, so piped input
+// with several answers is consumed a line at a time. Once the input is exhausted every question takes
+// its default.
+type stdinPrompter struct {
+	mu     sync.Mutex
+	reader *bufio.Reader
+	// echo writes each answer after its question, since input that is not typed does not show up.
+	echo bool
+	// terminal is the terminal answers are typed on, whose echo is turned off for secrets.
+	terminal *os.File
+}
+
+func (p *stdinPrompter) Confirm(con *console.Writer, question string) (string, error) {
+	line, err := p.read(con, question)
+	if errors.Is(err, io.EOF) {
+		return "", nil
+	}
+	return strings.ToLower(line), err
+}
+
+func (p *stdinPrompter) Input(con *console.Writer, question string) (string, error) {
+	line, err := p.read(con, question)
+	if errors.Is(err, io.EOF) {
+		return "", errNoAnswer
+	}
+	return line, err
+}
+
+func (p *stdinPrompter) Secret(con *console.Writer, question string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	con.Prompt("%s", question)
+	if p.terminal != nil {
+		restore, err := tui.DisableEcho(p.terminal)
+		if err != nil && !errors.Is(err, tui.ErrNotTerminal) {
+			return "", err
+		}
+		if restore != nil {
+			defer func() {
+				restore()
+				// The newline typed after the secret was not echoed either.
+				con.Write("\n")
+			}()
+		}
+	}
+	line, err := p.readLine()
+	if errors.Is(err, io.EOF) {
+		return "", errNoAnswer
+	}
+	return line, err
+}
+
+func (p *stdinPrompter) read(con *console.Writer, question string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	con.Prompt("%s", question)
+	answer, err := p.readLine()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if p.echo {
+		con.Write(answer + "\n")
+	}
+	return answer, err
+}
+
+// readLine returns the next trimmed line, or io.EOF when the input ended without one.
+func (p *stdinPrompter) readLine() (string, error) {
+	text, err := p.reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read confirmation input: %w", err)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" && errors.Is(err, io.EOF) {
+		return "", io.EOF
+	}
+	return text, nil
+}
+
+// fixedPrompter gives the same answer to every confirmation, for --yes and --no. Questions that need a
+// typed answer fail with errNoAnswer.
+type fixedPrompter struct {
+	answer string
+}
+
+func (p fixedPrompter) Confirm(con *console.Writer, question string) (string, error) {
+	con.Prompt("%s", question)
+	con.Write(p.answer + "\n")
+	return p.answer, nil
+}
+
+func (p fixedPrompter) Input(con *console.Writer, question string) (string, error) {
+	return "", errNoAnswer
+}
+
+func (p fixedPrompter) Secret(con *console.Writer, question string) (string, error) {
+	return "", errNoAnswer
+}
+
+// promptedCommand is implemented by commands that ask questions, so Execute can give them its
+// prompter.
+type promptedCommand interface {
+	SetPrompter(p Prompter)
+}
+
+// prompts is embedded by commands that ask questions and answers them through the command's Prompter.
+// Commands built without Execute, as in tests, read from os.Stdin. It is safe for concurrent use, since
+// pull and push ask from several goroutines.
+type prompts struct {
+	mu       sync.Mutex
+	prompter Prompter
+	// stdin is the fallback prompter and the file it reads, renewed when os.Stdin is replaced.
+	stdin     Prompter
+	stdinFile *os.File
+}
+
+// SetPrompter sets where the command's questions are answered; nil restores os.Stdin.
+func (p *prompts) SetPrompter(prompter Prompter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prompter = prompter
+}
+
+func (p *prompts) prompt() Prompter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.prompter != nil {
+		return p.prompter
+	}
+	if p.stdin == nil || p.stdinFile != os.Stdin {
+		p.stdin, p.stdinFile = NewPrompter(os.Stdin), os.Stdin
+	}
+	return p.stdin
+}
+
+// newPrompter picks the prompter for --yes and --no, or reads answers from stdin.
+func newPrompter(yes, no bool) (Prompter, error) {
+	switch {
+	case yes && no:
+		return nil, errors.New("--yes and --no cannot be used together")
+	case yes:
+		return fixedPrompter{answer: "y"}, nil
+	case no:
+		return fixedPrompter{answer: "n"}, nil
+	default:
+		return NewPrompter(os.Stdin), nil
+	}
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+func TestStdinPrompterReadsOneLinePerQuestion(t *testing.T) {
+	var out bytes.Buffer
+	con := console.New(&out, &out)
+	prompter := NewPrompter(strings.NewReader("y\n  A \nShop\n"))
+
+	for _, want := range []string{"y", "a"} {
+		got, err := prompter.Confirm(con, "Push changes? [y/N/a]: ")
+		if err != nil || got != want {
+			t.Fatalf("expected %q, got %q (%v)", want, got, err)
+		}
+	}
+	if got, err := prompter.Input(con, "Type the customer IDN to continue: "); err != nil || got != "Shop" {
+		t.Fatalf("expected the typed IDN with its case kept, got %q (%v)", got, err)
+	}
+	if got, err := prompter.Confirm(con, "Remove 2 item(s)? [y/N]: "); err != nil || got != "" {
+		t.Fatalf("expected the default once input runs out, got %q (%v)", got, err)
+	}
+	if _, err := prompter.Input(con, "Customer IDN: "); !errors.Is(err, errNoAnswer) {
+		t.Fatalf("expected typed answers to fail once input runs out, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Push changes? [y/N/a]: y\n") {
+		t.Fatalf("expected piped answers to be echoed, got %q", out.String())
+	}
+}
+
+func TestStdinPrompterDoesNotEchoSecrets(t *testing.T) {
+	var out bytes.Buffer
+	con := console.New(&out, &out)
+	prompter := NewPrompter(strings.NewReader(" key-123 \n"))
+
+	if got, err := prompter.Secret(con, "API key: "); err != nil || got != "key-123" {
+		t.Fatalf("expected the trimmed key, got %q (%v)", got, err)
+	}
+	if strings.Contains(out.String(), "key-123") {
+		t.Fatalf("expected the key not to be echoed, got %q", out.String())
+	}
+}
+
+func TestFixedPrompterAnswersConfirmations(t *testing.T) {
+	var out bytes.Buffer
+	con := console.New(&out, &out)
+
+	yes, err := newPrompter(true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := yes.Confirm(con, "Apply these changes? [y/N]: "); got != "y" {
+		t.Fatalf("expected --yes to answer y, got %q", got)
+	}
+	no, _ := newPrompter(false, true)
+	if got, _ := no.Confirm(con, "Apply these changes? [y/N]: "); got != "n" {
+		t.Fatalf("expected --no to answer n, got %q", got)
+	}
+	if _, err := yes.Input(con, "API key: "); !errors.Is(err, errNoAnswer) {
+		t.Fatalf("expected typed answers to be refused, got %v", err)
+	}
+	if _, err := newPrompter(true, true); err == nil {
+		t.Fatal("expected --yes and --no together to be rejected")
+	}
+}
+
+func TestProtectedCustomerIsNotConfirmedByYes(t *testing.T) {
+	var out bytes.Buffer
+	con := console.New(&out, &out)
+	entry := customer.Entry{Protected: true}
+
+	err := confirmProtectedCustomer(con, fixedPrompter{answer: "y"}, entry, "prod", "")
+	if err == nil || !strings.Contains(err.Error(), "--confirm-customer prod") {
+		t.Fatalf("expected --yes to leave the protected check to --confirm-customer, got %v", err)
+	}
+	if err := confirmProtectedCustomer(con, NewPrompter(strings.NewReader("prod\n")), entry, "prod", ""); err != nil {
+		t.Fatalf("expected the typed IDN to confirm the customer, got %v", err)
+	}
+}
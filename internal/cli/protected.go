@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// confirmProtectedCustomer guards writes to customers marked `protected = true` in newo.toml: the
+// customer IDN must be passed with --confirm-customer or typed at the prompt. --force does not skip it.
+func confirmProtectedCustomer(writer *console.Writer, prompter Prompter, entry customer.Entry, customerIDN, confirmed string) error {
+	if !entry.Protected {
+		return nil
+	}
+	if confirmed != "" {
+		if confirmed == customerIDN {
+			return nil
+		}
+		return fmt.Errorf("--confirm-customer %s does not match protected customer %s", confirmed, customerIDN)
+	}
+
+	writer.Warn("Customer %s is protected.", customerIDN)
+	text, err := prompter.Input(writer, "Type the customer IDN to continue: ")
+	if err != nil && !errors.Is(err, errNoAnswer) {
+		return err
+	}
+	if text != customerIDN {
+		return fmt.Errorf("customer %s is protected; type its IDN or pass --confirm-customer %s to write to it", customerIDN, customerIDN)
+	}
+	return nil
+}
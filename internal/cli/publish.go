@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/notify"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	skillsync "github.com/twinmind/newo-tool/internal/sync"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// PublishCommand publishes flows on the platform without uploading any local changes.
+type PublishCommand struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	console    *console.Writer
+	customer   *string
+	project    *string
+	flow       *string
+	all        *bool
+	force      *bool
+	verbose    *bool
+	confirmIDN *string
+
+	prompts
+}
+
+// NewPublishCommand constructs a publish command.
+func NewPublishCommand(stdout, stderr io.Writer) *PublishCommand {
+	return &PublishCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *PublishCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *PublishCommand) Name() string {
+	return "publish"
+}
+
+func (c *PublishCommand) Summary() string {
+	return "Publish flows without pushing skill changes"
+}
+
+func (c *PublishCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to publish")
+	c.project = fs.String("project", "", "only publish flows of this project IDN")
+	c.flow = fs.String("flow", "", "comma-separated flow IDNs to publish")
+	c.all = fs.Bool("all", false, "publish every flow in the project map")
+	c.force = fs.Bool("force", false, "also publish flows whose metadata.yaml sets publish: false")
+	c.verbose = fs.Bool("verbose", false, "enable verbose logging")
+	c.confirmIDN = fs.String("confirm-customer", "", "IDN of a protected customer, confirming the publish to it")
+}
+
+func (c *PublishCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo publish (--flow <idn>[,<idn>...] | --all) [--customer <idn>] [--project <idn>] [--force] [--confirm-customer <idn>] [--verbose]"
+	flows := map[string]bool{}
+	for _, name := range strings.Split(flagValue(c.flow), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			flows[strings.ToLower(name)] = true
+		}
+	}
+	all := c.all != nil && *c.all
+	if len(args) != 0 || (len(flows) == 0) == !all {
+		return errors.New(usage)
+	}
+	customerFilter := flagValue(c.customer)
+	projectFilter := flagValue(c.project)
+	verbose := c.verbose != nil && *c.verbose
+	force := c.force != nil && *c.force
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	var events []notify.Event
+	defer func() {
+		sendNotifications(ctx, c.console, env.Notifications, events)
+	}()
+
+	registryDirty := false
+	matched := false
+	found := map[string]bool{}
+	processed := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		session, err := session.New(ctx, env, entry, registry)
+		if err != nil {
+			return err
+		}
+		if session.RegistryUpdated {
+			registryDirty = true
+		}
+		if customerFilter != "" && !matchesCustomerToken(entry, session.IDN, customerFilter) {
+			continue
+		}
+		matched = true
+		key := strings.ToLower(session.IDN)
+		if processed[key] {
+			continue
+		}
+		processed[key] = true
+
+		projectMap, err := state.LoadProjectMap(session.IDN)
+		if err != nil {
+			return err
+		}
+		targets := publishTargets(env.OutputRoot, session.CustomerType, session.IDN, projectMap, projectFilter, flows, found)
+		if len(targets) == 0 {
+			continue
+		}
+		if err := confirmProtectedCustomer(c.console, c.prompt(), entry, session.IDN, flagValue(c.confirmIDN)); err != nil {
+			return err
+		}
+
+		started := time.Now()
+		event := notify.Event{Command: "publish", Customer: session.IDN}
+		var result skillsync.PublishResult
+		err = withCustomerLock(c.console, session.IDN, "publish", verbose, func() error {
+			service := skillsync.NewSkillSyncService(session.Client, nil)
+			var publishErr error
+			result, publishErr = service.Publish(ctx, skillsync.PublishRequest{
+				Targets:      targets,
+				IgnoreOptOut: force,
+				Verbose:      verbose,
+				Reporter:     consoleReporter{writer: c.console},
+			})
+			return publishErr
+		})
+		event.Published = result.Published
+		event.Finish(started, err)
+		events = append(events, event)
+		for _, name := range result.OptedOut {
+			c.console.Info("Not publishing %s: publish is disabled in its metadata.yaml (use --force to publish anyway)", name)
+		}
+		if err != nil {
+			return err
+		}
+		c.console.Success("Published %d flow(s) for %s", result.Published, session.IDN)
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	var missing []string
+	for name := range flows {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+
+	if registryDirty {
+		if err := registry.Save(); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("flow(s) not found in the project map: %s; run `newo pull` first", strings.Join(missing, ", "))
+	}
+	if all && len(found) == 0 {
+		c.console.Info("No flows to publish. Run `newo pull` first.")
+	}
+	return nil
+}
+
+// publishTargets lists the flows of a project map that match the project and flow filters, sorted by path.
+// Every matched flow IDN is marked in found.
+func publishTargets(outputRoot, customerType, customerIDN string, projectMap state.ProjectMap, projectFilter string, flows, found map[string]bool) []skillsync.PublishTarget {
+	var targets []skillsync.PublishTarget
+	for projectIDN, projectData := range projectMap.Projects {
+		if projectFilter != "" && !strings.EqualFold(projectIDN, projectFilter) {
+			continue
+		}
+		slug := projectSlugFromState(projectIDN, projectData)
+		for agentIDN, agentData := range projectData.Agents {
+			for flowIDN, flowData := range agentData.Flows {
+				key := strings.ToLower(flowIDN)
+				if len(flows) > 0 && !flows[key] {
+					continue
+				}
+				found[key] = true
+				targets = append(targets, skillsync.PublishTarget{
+					ProjectIDN:   projectIDN,
+					AgentIDN:     agentIDN,
+					FlowIDN:      flowIDN,
+					FlowID:       flowData.ID,
+					MetadataPath: fsutil.ExportFlowMetadataPath(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN),
+				})
+			}
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		a, b := targets[i], targets[j]
+		return a.ProjectIDN+"/"+a.AgentIDN+"/"+a.FlowIDN < b.ProjectIDN+"/"+b.AgentIDN+"/"+b.FlowIDN
+	})
+	return targets
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,14 +9,21 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/twinmind/newo-tool/internal/blobstore"
 	"github.com/twinmind/newo-tool/internal/config"
 	"github.com/twinmind/newo-tool/internal/customer"
 	"github.com/twinmind/newo-tool/internal/diff"
 	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/gitutil"
+	"github.com/twinmind/newo-tool/internal/metrics"
 	"github.com/twinmind/newo-tool/internal/platform"
 	"github.com/twinmind/newo-tool/internal/serialize"
 	"github.com/twinmind/newo-tool/internal/session"
@@ -28,6 +34,13 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// remoteCopySuffix is appended to a conflicting file name when the remote version is written alongside it.
+const remoteCopySuffix = ".remote"
+
+// pullCheckpointMaxAge bounds how old an interrupted pull may be and still be resumed; older
+// checkpoints are discarded because the skipped flows may have changed remotely in the meantime.
+const pullCheckpointMaxAge = 24 * time.Hour
+
 func (c *PullCommand) projectSlug(project platform.Project) string {
 	slug := strings.ToLower(strings.TrimSpace(project.IDN))
 	slug = strings.ReplaceAll(slug, " ", "-")
@@ -49,13 +62,58 @@ type PullCommand struct {
 	force             *bool
 	verbose           *bool
 	customer          *string
+	group             *string
 	projectUUID       *string
 	projectIDN        *string
+	metricsOut        *string
+	noResume          *bool
+	gitCommit         *bool
+	sinceLast         *bool
+	prune             *bool
+	concurrency       *int
+	limits            config.PerformanceConfig
 	outputRoot        string
 	slugPrefix        string
 	verboseOn         bool
 	applyAllOverwrite bool
 	promptMu          sync.Mutex
+	progress          *console.Progress
+	checkpointMu      sync.Mutex
+	checkpoints       map[string]*state.PullCheckpoint
+	blobs             *blobstore.Store
+	hashCache         *state.FileHashCache
+	pulled            []pulledCustomer
+	changes           []state.ChangelogEntry
+	pulledAt          time.Time
+	tally             *runTally
+	conflicts         int
+
+	// previousSkills indexes the skills of the last pull by remote ID, so a skill renamed on the
+	// platform can take its local files along. movedHashes holds the baselines of files moved that
+	// way, under their new paths. renameAll and pruneAll are set once the user answers "a" to a
+	// rename or prune prompt.
+	previousSkills map[string]previousSkill
+	renameMu       sync.Mutex
+	movedHashes    state.HashStore
+	renameAll      bool
+	pruneAll       bool
+
+	prompts
+}
+
+// previousSkill is where the last pull wrote a skill.
+type previousSkill struct {
+	projectIDN string
+	agentIDN   string
+	flowIDN    string
+	idn        string
+	runnerType string
+}
+
+// pulledCustomer records which projects a pull refreshed, for the --git-commit message.
+type pulledCustomer struct {
+	IDN      string
+	Projects []string
 }
 
 // NewPullCommand constructs a pull command using provided output writers.
@@ -64,6 +122,7 @@ func NewPullCommand(stdout, stderr io.Writer) *PullCommand {
 		stdout:  stdout,
 		stderr:  stderr,
 		console: console.New(stdout, stderr),
+		limits:  config.DefaultPerformance,
 	}
 }
 
@@ -85,16 +144,29 @@ func (c *PullCommand) RegisterFlags(fs *flag.FlagSet) {
 	c.force = fs.Bool("force", false, "overwrite local skill scripts without prompting")
 	c.verbose = fs.Bool("verbose", false, "enable verbose logging")
 	c.customer = fs.String("customer", "", "customer IDN to limit the pull to")
+	c.group = fs.String("group", "", "pull every customer in this newo.toml group")
 	c.projectUUID = fs.String("project-uuid", "", "restrict pull to a single project UUID")
 	c.projectIDN = fs.String("project-idn", "", "restrict pull to a single project IDN")
+	c.metricsOut = fs.String("metrics-out", "", "write a run summary (JSON, or Prometheus text for .prom) to this file")
+	c.noResume = fs.Bool("no-resume", false, "ignore flows saved by an interrupted pull and fetch everything again")
+	c.gitCommit = fs.Bool("git-commit", false, "commit the pulled files to git afterwards")
+	c.sinceLast = fs.Bool("since-last", false, "skip projects whose updated_at has not changed since the last pull")
+	c.prune = fs.Bool("prune", false, "delete local files of skills, flows, and agents removed on the platform")
+	c.concurrency = fs.Int("concurrency", 0, concurrencyUsage)
 }
 
-func (c *PullCommand) Run(ctx context.Context, _ []string) error {
+func (c *PullCommand) Run(ctx context.Context, args []string) error {
 	c.ensureConsole()
+	metrics.Reset(c.Name())
+	return finishRunMetrics(c.metricsOut, c.run(ctx, args), c.console)
+}
+
+func (c *PullCommand) run(ctx context.Context, _ []string) error {
 	force := c.force != nil && *c.force
 	verbose := c.verbose != nil && *c.verbose
 	c.verboseOn = verbose
 	c.applyAllOverwrite = force
+	c.conflicts = 0
 	customerFilter := ""
 	if c.customer != nil {
 		customerFilter = strings.TrimSpace(*c.customer)
@@ -104,6 +176,9 @@ func (c *PullCommand) Run(ctx context.Context, _ []string) error {
 	if err != nil {
 		return err
 	}
+	if c.limits, err = performanceLimits(env, c.concurrency); err != nil {
+		return err
+	}
 
 	projectUUIDFilter := ""
 	if c.projectUUID != nil {
@@ -117,24 +192,27 @@ func (c *PullCommand) Run(ctx context.Context, _ []string) error {
 
 	c.outputRoot = env.OutputRoot
 	c.slugPrefix = env.SlugPrefix
+	c.tally = newRunTally("Pull summary",
+		tallyColumn{"PROJECTS", "projects_pulled"},
+		tallyColumn{"FLOWS", "flows_pulled"},
+		tallyColumn{"WRITTEN", "skills_written"},
+		tallyColumn{"UNCHANGED", "skills_unchanged"},
+		tallyColumn{"SKIPPED", "skills_skipped"},
+		tallyColumn{"CONFLICTED", "skills_conflicted"},
+		tallyColumn{"MERGED", "skills_merged"},
+	)
+	if env.BlobCache {
+		c.blobs = blobstore.Open(fsutil.BlobsDir())
+	}
 
 	cfg, err := customer.FromEnv(env)
 	if err != nil {
 		return err
 	}
-
-	releaseLock, err := fsutil.AcquireLock("pull")
+	entries, err := groupEntries(cfg, flagValue(c.group), customerFilter)
 	if err != nil {
-		if errors.Is(err, fsutil.ErrLocked) {
-			return fmt.Errorf("another operation is already running; please retry later")
-		}
 		return err
 	}
-	defer func() {
-		if err := releaseLock(); err != nil && verbose {
-			c.console.Warn("Release lock: %v", err)
-		}
-	}()
 
 	registry, err := state.LoadAPIKeyRegistry()
 	if err != nil {
@@ -146,8 +224,10 @@ func (c *PullCommand) Run(ctx context.Context, _ []string) error {
 	var matchedFilter bool
 	var registryDirty bool
 
-	for _, entry := range cfg.Entries {
+	for _, entry := range entries {
+		endAuth := metrics.Phase("auth")
 		session, err := session.New(ctx, env, entry, registry)
+		endAuth()
 		if err != nil {
 			return err
 		}
@@ -172,7 +252,14 @@ func (c *PullCommand) Run(ctx context.Context, _ []string) error {
 			effectiveProjectIDN = env.ProjectIDN // 3. Global config
 		}
 
-		if err := c.syncCustomer(ctx, session, projectUUIDFilter, effectiveProjectIDN, session.CustomerType, session.IDN, verbose, force); err != nil {
+		endPull := metrics.Phase("pull")
+		err = c.tally.measure(session.IDN, func() error {
+			return withCustomerLock(c.console, session.IDN, "pull", verbose, func() error {
+				return c.syncCustomer(ctx, session, projectUUIDFilter, effectiveProjectIDN, session.CustomerType, session.IDN, verbose, force)
+			})
+		})
+		endPull()
+		if err != nil {
 			return err
 		}
 
@@ -197,10 +284,21 @@ func (c *PullCommand) Run(ctx context.Context, _ []string) error {
 		}
 	}
 
+	if c.gitCommit != nil && *c.gitCommit && len(c.pulled) > 0 {
+		c.commitPull(ctx)
+	}
+
 	if !processed && verbose {
 		c.console.Info("No customers matched the selection.")
 	}
 
+	c.tally.print(c.console)
+	if c.conflicts > 0 {
+		return exitError{
+			msg:  fmt.Sprintf("%d conflicting file(s) kept their local edits; resolve them and pull again", c.conflicts),
+			code: exitConflicts,
+		}
+	}
 	return nil
 }
 
@@ -234,6 +332,16 @@ func (c *PullCommand) syncCustomer(
 		return err
 	}
 	newHashes := state.HashStore{}
+	c.previousSkills = indexPreviousSkills(projectMapValue)
+	c.movedHashes = state.HashStore{}
+	c.renameAll = false
+	c.pruneAll = false
+	c.changes = nil
+	c.pulledAt = time.Now().UTC()
+	c.hashCache, err = state.LoadFileHashCache(session.IDN, c.blobs != nil)
+	if err != nil {
+		return err
+	}
 
 	var projects []platform.Project
 	var pulledProjectIDs []string
@@ -286,9 +394,12 @@ func (c *PullCommand) syncCustomer(
 		c.console.Info("Pulling %d project(s) for %s", len(projects), session.IDN)
 	}
 
+	c.progress = c.console.StartProgress(fmt.Sprintf("Pull %s", session.IDN), "skills")
+	defer c.progress.Finish()
+
 	var mu sync.Mutex
 	g, gCtx := errgroup.WithContext(ctx)
-	g.SetLimit(4) // Limit concurrency to 4 projects at a time
+	g.SetLimit(c.limits.Projects)
 
 	for _, project := range projects {
 		project := project // https://golang.org/doc/faq#closures_and_goroutines
@@ -306,21 +417,47 @@ func (c *PullCommand) syncCustomer(
 	if err := g.Wait(); err != nil {
 		return err
 	}
+	c.progress.Finish()
+	metrics.Add("projects_pulled", len(pulledProjectIDs))
 
 	c.exportAttributes(ctx, session, projectMap.Projects, hashes, newHashes, session.CustomerType, session.IDN, verbose, force, &mu)
 
+	var projectDirs []string
+	for _, projectIDN := range pulledProjectIDs {
+		slug := projectSlugFromState(projectIDN, projectMap.Projects[projectIDN])
+		projectDirs = append(projectDirs, filepath.ToSlash(fsutil.ExportProjectDir(c.outputRoot, customerType, customerIDN, slug)))
+	}
+	if err := c.pruneStale(hashes, newHashes, projectDirs, force); err != nil {
+		return err
+	}
+
 	if err := state.SaveProjectMap(session.IDN, *projectMap); err != nil {
 		return err
 	}
 	if err := state.SaveHashes(session.IDN, newHashes); err != nil {
 		return err
 	}
+	if err := c.hashCache.Save(); err != nil {
+		return err
+	}
+	if err := state.AppendChangelog(session.IDN, c.changes); err != nil {
+		return err
+	}
+	if len(c.changes) > 0 {
+		c.console.Info("%d skill(s) changed on the platform since the last sync; see `newo log`", len(c.changes))
+	}
+	for _, projectIDN := range pulledProjectIDs {
+		if err := state.RemovePullCheckpoint(session.IDN, projectIDN); err != nil {
+			return err
+		}
+	}
 
 	projectLabel := "no projects"
 	if len(pulledProjectIDs) > 0 {
 
 		unique := uniqueStrings(pulledProjectIDs)
 		projectLabel = strings.Join(unique, ", ")
+		c.pulled = append(c.pulled, pulledCustomer{IDN: session.IDN, Projects: unique})
 	}
 	c.console.Success("Pull complete for %s (%s)", projectLabel, session.IDN)
 	return nil
@@ -342,18 +479,25 @@ func (c *PullCommand) pullProject(
 ) error {
 	c.ensureConsole()
 	if verbose {
-		c.console.Info("Project %s (%s)", project.Title, project.IDN)
+		c.console.Scope(project.IDN).Info("Project %s", project.Title)
 	}
 
 	slug := c.projectSlug(project)
+	if c.sinceLast != nil && *c.sinceLast && c.reuseProject(customerType, customerIDNForPath, project, slug, projectMap, oldHashes, newHashes, mu) {
+		c.console.Info("Project %s unchanged since the last pull (updated_at %s); skipped", project.IDN, project.UpdatedAt)
+		metrics.Add("projects_unchanged", 1)
+		return nil
+	}
 	if err := os.MkdirAll(fsutil.ExportProjectDir(c.outputRoot, customerType, customerIDNForPath, slug), fsutil.DirPerm); err != nil {
 		return fmt.Errorf("ensure project directory: %w", err)
 	}
+	c.startCheckpoint(customerIDN, project)
 
 	projectData := state.ProjectData{
 		ProjectID:  project.ID,
 		ProjectIDN: project.IDN,
 		Path:       slug,
+		UpdatedAt:  project.UpdatedAt,
 		Agents:     map[string]state.AgentData{},
 	}
 
@@ -363,7 +507,7 @@ func (c *PullCommand) pullProject(
 	}
 
 	g, gCtx := errgroup.WithContext(ctx)
-	g.SetLimit(8)
+	g.SetLimit(c.limits.Flows)
 
 	for _, agent := range agents {
 		agent := agent
@@ -392,6 +536,43 @@ func (c *PullCommand) pullProject(
 	return nil
 }
 
+// reuseProject keeps the result of the previous pull of a project whose updated_at has not moved, and
+// reports whether the project can be skipped. Projects whose tracked files are no longer all on disk
+// are pulled again so the missing files come back.
+func (c *PullCommand) reuseProject(customerType, customerIDN string, project platform.Project, slug string, projectMap *state.ProjectMap, oldHashes, newHashes state.HashStore, mu *sync.Mutex) bool {
+	if strings.TrimSpace(project.UpdatedAt) == "" {
+		return false
+	}
+	mu.Lock()
+	previous, ok := projectMap.Projects[project.IDN]
+	mu.Unlock()
+	if !ok || previous.ProjectID != project.ID || previous.UpdatedAt != project.UpdatedAt || previous.Path != slug {
+		return false
+	}
+
+	projectDir := filepath.ToSlash(fsutil.ExportProjectDir(c.outputRoot, customerType, customerIDN, slug)) + "/"
+	kept := state.HashStore{}
+	for path, hash := range oldHashes {
+		if !strings.HasPrefix(path, projectDir) {
+			continue
+		}
+		if _, err := os.Stat(filepath.FromSlash(path)); err != nil {
+			return false
+		}
+		kept[path] = hash
+	}
+	if len(kept) == 0 {
+		return false
+	}
+
+	mu.Lock()
+	for path, hash := range kept {
+		newHashes[path] = hash
+	}
+	mu.Unlock()
+	return true
+}
+
 func (c *PullCommand) pullAgent(
 	ctx context.Context,
 	client *platform.Client,
@@ -410,7 +591,7 @@ func (c *PullCommand) pullAgent(
 ) error {
 	c.ensureConsole()
 	if verbose {
-		c.console.Info("  Agent %s (%s)", agent.Title, agent.IDN)
+		c.console.Scope(project.IDN, agent.IDN).Info("Agent %s", agent.Title)
 	}
 
 	agentData := state.AgentData{
@@ -421,10 +602,13 @@ func (c *PullCommand) pullAgent(
 	}
 
 	g, gCtx := errgroup.WithContext(ctx)
-	g.SetLimit(8)
+	g.SetLimit(c.limits.Flows)
 
 	for _, flow := range agent.Flows {
 		flow := flow
+		if c.resumeFlow(project.IDN, agent.IDN, flow, &agentData, newHashes, mu) {
+			continue
+		}
 		g.Go(func() error {
 			return c.pullFlow(gCtx, client, customerIDN, projectSlug, project, agent, flow, &agentData, oldHashes, newHashes, customerType, customerIDNForPath, verbose, force, mu)
 		})
@@ -458,15 +642,16 @@ func (c *PullCommand) pullFlow(
 	mu *sync.Mutex,
 ) error {
 	c.ensureConsole()
+	scope := c.console.Scope(project.IDN, agent.IDN, flow.IDN)
 	if verbose {
-		c.console.Info("    Flow %s (%s)", flow.Title, flow.IDN)
+		scope.Info("Flow %s", flow.Title)
 	}
 
 	events, err := client.ListFlowEvents(ctx, flow.ID)
 	if err != nil {
 		if apiErr, ok := err.(*platform.APIError); ok && apiErr.Status == http.StatusNotFound {
 			if verbose {
-				c.console.Warn("Events missing for flow %s: %v", flow.IDN, err)
+				scope.Warn("Events missing: %v", err)
 			}
 		} else {
 			return fmt.Errorf("list flow events: %w", err)
@@ -477,7 +662,7 @@ func (c *PullCommand) pullFlow(
 	if err != nil {
 		if apiErr, ok := err.(*platform.APIError); ok && apiErr.Status == http.StatusNotFound {
 			if verbose {
-				c.console.Warn("States missing for flow %s: %v", flow.IDN, err)
+				scope.Warn("States missing: %v", err)
 			}
 		} else {
 			return fmt.Errorf("list flow states: %w", err)
@@ -485,12 +670,8 @@ func (c *PullCommand) pullFlow(
 		states = nil
 	}
 
-	skills, err := client.ListFlowSkills(ctx, flow.ID)
+	ownership, err := c.exportFlowMetadata(customerType, customerIDN, projectSlug, agent.IDN, flow.IDN, flow, events, states, oldHashes, newHashes, force, mu)
 	if err != nil {
-		return fmt.Errorf("list flow skills: %w", err)
-	}
-
-	if err := c.exportFlowMetadata(customerType, customerIDN, projectSlug, agent.IDN, flow.IDN, flow, events, states, oldHashes, newHashes, force, mu); err != nil {
 		return fmt.Errorf("export flow metadata %s: %w", flow.IDN, err)
 	}
 
@@ -508,16 +689,23 @@ func (c *PullCommand) pullFlow(
 		StateFields: convertFlowStates(states),
 	}
 
+	// Skills are written while the listing is still being read. Go blocks while the limit is reached,
+	// which bounds how many prompt scripts are held in memory at once.
 	var g errgroup.Group
-	g.SetLimit(16)
+	g.SetLimit(c.limits.Skills)
 
-	for _, skill := range skills {
-		skill := skill
+	listErr := client.EachFlowSkill(ctx, flow.ID, func(skill platform.Skill) error {
+		c.progress.Add(project.IDN, 1)
 		g.Go(func() error {
-			if err := c.exportSkill(customerType, customerIDN, projectSlug, agent.IDN, flow.IDN, skill, oldHashes, newHashes, force, mu); err != nil {
+			if err := c.followRemoteRename(customerType, customerIDN, projectSlug, project.IDN, agent.IDN, flow.IDN, skill, oldHashes, force); err != nil {
+				return err
+			}
+			c.recordRemoteChange(customerType, customerIDN, projectSlug, project.IDN, agent.IDN, flow.IDN, skill, oldHashes, mu)
+			owner := ownership.Skill(skill.IDN)
+			if err := c.exportSkill(customerType, customerIDN, projectSlug, agent.IDN, flow.IDN, skill, owner, oldHashes, newHashes, force, mu); err != nil {
 				return fmt.Errorf("export skill script %s: %w", skill.IDN, err)
 			}
-			if err := c.exportSkillMetadata(customerType, customerIDN, projectSlug, agent.IDN, flow.IDN, skill, oldHashes, newHashes, force, mu); err != nil {
+			if err := c.exportSkillMetadata(customerType, customerIDN, projectSlug, agent.IDN, flow.IDN, skill, owner, oldHashes, newHashes, force, mu); err != nil {
 				return fmt.Errorf("export skill metadata %s: %w", skill.IDN, err)
 			}
 
@@ -538,18 +726,129 @@ func (c *PullCommand) pullFlow(
 				UpdatedAt:  skill.UpdatedAt,
 			}
 			mu.Unlock()
+			c.progress.Done(project.IDN, 1)
 			return nil
 		})
-	}
-
+		return nil
+	})
 	if err := g.Wait(); err != nil {
 		return err
 	}
+	if listErr != nil {
+		return fmt.Errorf("list flow skills: %w", listErr)
+	}
 
+	flowDir := filepath.ToSlash(fsutil.ExportFlowDir(c.outputRoot, customerType, customerIDNForPath, projectSlug, agent.IDN, flow.IDN)) + "/"
+	written := state.HashStore{}
 	mu.Lock()
 	agentData.Flows[flow.IDN] = flowData
+	for path, hash := range newHashes {
+		if strings.HasPrefix(path, flowDir) {
+			written[path] = hash
+		}
+	}
 	mu.Unlock()
-	return nil
+	metrics.Add("flows_pulled", 1)
+	return c.recordFlow(customerIDN, project.IDN, state.PullCheckpointKey(agent.IDN, flow.IDN), state.PullFlowCheckpoint{Flow: flowData, Hashes: written})
+}
+
+// startCheckpoint loads the checkpoint an interrupted pull left for the project, or starts a new one.
+func (c *PullCommand) startCheckpoint(customerIDN string, project platform.Project) {
+	checkpoint, err := state.LoadPullCheckpoint(customerIDN, project.IDN)
+	if err != nil {
+		c.console.Warn("Ignoring the checkpoint of an interrupted pull for %s: %v", project.IDN, err)
+		checkpoint = nil
+	}
+	resume := checkpoint != nil && len(checkpoint.Flows) > 0 &&
+		(c.noResume == nil || !*c.noResume) &&
+		checkpoint.ProjectID == project.ID &&
+		time.Since(checkpoint.StartedAt) < pullCheckpointMaxAge
+	if resume {
+		c.console.Info("Resuming interrupted pull of %s: %d flow(s) already written", project.IDN, len(checkpoint.Flows))
+	} else {
+		checkpoint = state.NewPullCheckpoint(project.IDN, project.ID, time.Now().UTC())
+	}
+
+	c.checkpointMu.Lock()
+	defer c.checkpointMu.Unlock()
+	if c.checkpoints == nil {
+		c.checkpoints = map[string]*state.PullCheckpoint{}
+	}
+	c.checkpoints[project.IDN] = checkpoint
+}
+
+// resumeFlow reuses a flow written by an interrupted pull and reports whether it could be skipped.
+func (c *PullCommand) resumeFlow(projectIDN, agentIDN string, flow platform.Flow, agentData *state.AgentData, newHashes state.HashStore, mu *sync.Mutex) bool {
+	c.checkpointMu.Lock()
+	checkpoint := c.checkpoints[projectIDN]
+	var done state.PullFlowCheckpoint
+	found := false
+	if checkpoint != nil {
+		done, found = checkpoint.Flows[state.PullCheckpointKey(agentIDN, flow.IDN)]
+	}
+	c.checkpointMu.Unlock()
+	if !found || done.Flow.ID != flow.ID {
+		return false
+	}
+
+	mu.Lock()
+	agentData.Flows[flow.IDN] = done.Flow
+	for path, hash := range done.Hashes {
+		newHashes[path] = hash
+	}
+	mu.Unlock()
+	c.progress.Add(projectIDN, len(done.Flow.Skills))
+	c.progress.Done(projectIDN, len(done.Flow.Skills))
+	return true
+}
+
+// recordFlow adds a finished flow to the project checkpoint and saves it right away.
+func (c *PullCommand) recordFlow(customerIDN, projectIDN, key string, done state.PullFlowCheckpoint) error {
+	c.checkpointMu.Lock()
+	defer c.checkpointMu.Unlock()
+	checkpoint := c.checkpoints[projectIDN]
+	if checkpoint == nil {
+		return nil
+	}
+	checkpoint.Flows[key] = done
+	return state.SavePullCheckpoint(customerIDN, checkpoint)
+}
+
+// flowMetadataYAML is the layout of a flow's metadata.yaml.
+type flowMetadataYAML struct {
+	ID                string                `yaml:"id"`
+	IDN               string                `yaml:"idn"`
+	Title             string                `yaml:"title"`
+	Description       string                `yaml:"description,omitempty"`
+	DefaultRunnerType string                `yaml:"default_runner_type"`
+	DefaultModel      map[string]string     `yaml:"default_model"`
+	Events            []state.FlowEventInfo `yaml:"events"`
+	StateFields       []state.FlowStateInfo `yaml:"state_fields"`
+	// Publish is a local setting with no remote counterpart, so pull carries it over from the existing file.
+	Publish *bool `yaml:"publish,omitempty"`
+	// Ownership is local too. Keys it marks as locally managed also keep their value from the existing file.
+	Ownership *state.FlowOwnership `yaml:"ownership,omitempty"`
+}
+
+// keepLocalFlowKeys copies the keys the ownership section marks as locally managed from the existing
+// metadata into the freshly pulled one.
+func keepLocalFlowKeys(meta *flowMetadataYAML, local flowMetadataYAML) {
+	for _, key := range local.Ownership.KeysOwnedBy(state.OwnerLocal) {
+		switch key {
+		case "title":
+			meta.Title = local.Title
+		case "description":
+			meta.Description = local.Description
+		case "default_runner_type":
+			meta.DefaultRunnerType = local.DefaultRunnerType
+		case "default_model":
+			meta.DefaultModel = local.DefaultModel
+		case "events":
+			meta.Events = local.Events
+		case "state_fields":
+			meta.StateFields = local.StateFields
+		}
+	}
 }
 
 func (c *PullCommand) exportFlowMetadata(
@@ -560,18 +859,7 @@ func (c *PullCommand) exportFlowMetadata(
 	oldHashes, newHashes state.HashStore,
 	force bool,
 	mu *sync.Mutex,
-) error {
-	type flowMetadataYAML struct {
-		ID                string                `yaml:"id"`
-		IDN               string                `yaml:"idn"`
-		Title             string                `yaml:"title"`
-		Description       string                `yaml:"description,omitempty"`
-		DefaultRunnerType string                `yaml:"default_runner_type"`
-		DefaultModel      map[string]string     `yaml:"default_model"`
-		Events            []state.FlowEventInfo `yaml:"events"`
-		StateFields       []state.FlowStateInfo `yaml:"state_fields"`
-	}
-
+) (*state.FlowOwnership, error) {
 	meta := flowMetadataYAML{
 		ID:                flow.ID,
 		IDN:               flow.IDN,
@@ -585,23 +873,49 @@ func (c *PullCommand) exportFlowMetadata(
 		Events:      convertFlowEvents(events),
 		StateFields: convertFlowStates(states),
 	}
+	path := fsutil.ExportFlowMetadataPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN)
+	if existing, err := os.ReadFile(path); err == nil {
+		var local flowMetadataYAML
+		if yaml.Unmarshal(existing, &local) == nil {
+			if err := local.Ownership.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.ToSlash(path), err)
+			}
+			meta.Publish = local.Publish
+			meta.Ownership = local.Ownership
+			keepLocalFlowKeys(&meta, local)
+		}
+	}
 
 	data, err := yaml.Marshal(meta)
 	if err != nil {
-		return fmt.Errorf("encode flow metadata: %w", err)
+		return nil, fmt.Errorf("encode flow metadata: %w", err)
 	}
-
-	path := fsutil.ExportFlowMetadataPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN)
-	return c.writeFileWithHash(oldHashes, newHashes, path, data, force, mu)
+	return meta.Ownership, c.writeFileWithHash(oldHashes, newHashes, path, data, force, mu)
 }
 
-func (c *PullCommand) exportSkillMetadata(customerType, customerIDN, projectSlug, agentIDN, flowIDN string, skill platform.Skill, oldHashes, newHashes state.HashStore, force bool, mu *sync.Mutex) error {
-	data, err := serialize.SkillMetadata(skill)
+func (c *PullCommand) exportSkillMetadata(customerType, customerIDN, projectSlug, agentIDN, flowIDN string, skill platform.Skill, owner string, oldHashes, newHashes state.HashStore, force bool, mu *sync.Mutex) error {
+	path := fsutil.ExportSkillMetadataPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, skill.IDN)
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read existing %s: %w", filepath.ToSlash(path), err)
+	}
+	// Tags live only in the workspace, so they are carried over from the local file.
+	tags, _ := state.ParseSkillTags(existing)
+	data, err := serialize.TaggedSkillMetadata(skill, tags)
 	if err != nil {
 		return err
 	}
-	path := fsutil.ExportSkillMetadataPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, skill.IDN)
-	return c.writeFileWithHash(oldHashes, newHashes, path, data, force, mu)
+	if existing != nil && serialize.SameSkillMetadata(existing, data) {
+		// The local file already says the same, perhaps formatted differently after a tag was added.
+		if mu != nil {
+			mu.Lock()
+			defer mu.Unlock()
+		}
+		newHashes[filepath.ToSlash(path)] = util.ContentHash(existing)
+		return nil
+	}
+	_, err = c.syncOwnedFile(owner, oldHashes, newHashes, path, data, force, mu)
+	return err
 }
 
 func parametersForMap(skill platform.Skill) []map[string]any {
@@ -656,10 +970,256 @@ func convertFlowStates(states []platform.FlowState) []state.FlowStateInfo {
 	return converted
 }
 
-func (c *PullCommand) exportSkill(customerType, customerIDN, projectSlug, agentIDN, flowIDN string, skill platform.Skill, oldHashes, newHashes state.HashStore, force bool, mu *sync.Mutex) error {
+// indexPreviousSkills maps the remote ID of every skill in the project map to where it was written.
+func indexPreviousSkills(projectMap state.ProjectMap) map[string]previousSkill {
+	index := map[string]previousSkill{}
+	for projectIDN, projectData := range projectMap.Projects {
+		for agentIDN, agentData := range projectData.Agents {
+			for flowIDN, flowData := range agentData.Flows {
+				for skillIDN, skill := range flowData.Skills {
+					if id := strings.TrimSpace(skill.ID); id != "" {
+						index[id] = previousSkill{projectIDN: projectIDN, agentIDN: agentIDN, flowIDN: flowIDN, idn: skillIDN, runnerType: skill.RunnerType}
+					}
+				}
+			}
+		}
+	}
+	return index
+}
+
+// followRemoteRename moves the local script and metadata of a skill whose IDN changed on the platform
+// to the new name, so local edits carry over and no stale files are left behind. The baseline hashes
+// move along, which keeps conflict detection working for the renamed files.
+func (c *PullCommand) followRemoteRename(customerType, customerIDN, projectSlug, projectIDN, agentIDN, flowIDN string, skill platform.Skill, oldHashes state.HashStore, force bool) error {
+	previous, ok := c.previousSkills[strings.TrimSpace(skill.ID)]
+	if !ok || previous.idn == skill.IDN || previous.projectIDN != projectIDN || previous.agentIDN != agentIDN || previous.flowIDN != flowIDN {
+		return nil
+	}
+	moves := [][2]string{
+		{
+			fsutil.ExportSkillScriptPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, previous.idn+"."+platform.ScriptExtension(previous.runnerType)),
+			fsutil.ExportSkillScriptPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, skill.IDN+"."+platform.ScriptExtension(skill.RunnerType)),
+		},
+		{
+			fsutil.ExportSkillMetadataPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, previous.idn),
+			fsutil.ExportSkillMetadataPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, skill.IDN),
+		},
+	}
+	if _, err := os.Stat(moves[0][0]); err != nil {
+		return nil
+	}
+	for _, move := range moves {
+		if _, err := os.Stat(move[1]); err == nil {
+			c.console.Warn("Skill %s was renamed to %s on the platform, but %s already exists; keeping both", previous.idn, skill.IDN, filepath.ToSlash(move[1]))
+			return nil
+		}
+	}
+
+	if !force {
+		apply, err := c.confirmRename(previous.idn, skill.IDN)
+		if err != nil || !apply {
+			return err
+		}
+	}
+	for _, move := range moves {
+		if err := os.Rename(move[0], move[1]); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("rename %s: %w", filepath.ToSlash(move[0]), err)
+		}
+		if hash, tracked := oldHashes[filepath.ToSlash(move[0])]; tracked {
+			c.renameMu.Lock()
+			c.movedHashes[filepath.ToSlash(move[1])] = hash
+			c.renameMu.Unlock()
+		}
+	}
+	c.console.Info("Skill %s was renamed to %s on the platform; local files renamed", previous.idn, skill.IDN)
+	return nil
+}
+
+// confirmRename asks whether to follow a remote skill rename locally.
+func (c *PullCommand) confirmRename(oldIDN, newIDN string) (bool, error) {
+	c.promptMu.Lock()
+	defer c.promptMu.Unlock()
+
+	c.ensureConsole()
+	if c.renameAll {
+		return true, nil
+	}
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Skill %s was renamed to %s on the platform. Rename the local files? [y/N/a]: ", oldIDN, newIDN))
+	if err != nil {
+		return false, err
+	}
+	switch text {
+	case "y":
+		return true, nil
+	case "a":
+		c.renameAll = true
+		return true, nil
+	default:
+		c.console.Info("Keeping %s; the renamed skill is written as a new file.", oldIDN)
+		return false, nil
+	}
+}
+
+// baseline returns the hash recorded for path at the last sync, following files moved by a remote rename.
+func (c *PullCommand) baseline(oldHashes state.HashStore, path string) (string, bool) {
+	if hash, ok := oldHashes[path]; ok {
+		return hash, true
+	}
+	c.renameMu.Lock()
+	defer c.renameMu.Unlock()
+	hash, ok := c.movedHashes[path]
+	return hash, ok
+}
+
+// pruneStale handles the files the previous pull wrote under projectDirs that this pull did not write
+// again, because their skill, flow, or agent was deleted on the platform. With --prune they are deleted
+// after confirmation, along with directories left empty; otherwise they are only counted. Their hashes
+// are already gone, since only files written by this pull are recorded.
+func (c *PullCommand) pruneStale(oldHashes, newHashes state.HashStore, projectDirs []string, force bool) error {
+	c.ensureConsole()
+	var stale []string
+	for path := range oldHashes {
+		if _, written := newHashes[path]; written {
+			continue
+		}
+		if !slices.ContainsFunc(projectDirs, func(dir string) bool { return pathUnder(path, dir) }) {
+			continue
+		}
+		if _, err := os.Stat(filepath.FromSlash(path)); err != nil {
+			continue
+		}
+		stale = append(stale, path)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(stale)
+	if c.prune == nil || !*c.prune {
+		c.console.Info("%d local file(s) belong to skills, flows, or agents deleted on the platform; run `newo pull --prune` to remove them", len(stale))
+		return nil
+	}
+
+	pruned := 0
+	for _, path := range stale {
+		if !force && !c.pruneAll {
+			edited := false
+			if hash, err := c.hashCache.Hash(filepath.FromSlash(path)); err == nil && hash != oldHashes[path] {
+				edited = true
+			}
+			remove, err := c.confirmPrune(path, edited)
+			if err != nil {
+				return err
+			}
+			if !remove {
+				continue
+			}
+		}
+		if err := os.Remove(filepath.FromSlash(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", path, err)
+		}
+		pruned++
+		for _, dir := range projectDirs {
+			if pathUnder(path, dir) {
+				removeEmptyParents(path, dir)
+			}
+		}
+	}
+	metrics.Add("files_pruned", pruned)
+	if pruned > 0 {
+		c.console.Success("Pruned %d file(s) deleted on the platform", pruned)
+	}
+	return nil
+}
+
+// confirmPrune asks whether to delete a local file whose remote counterpart is gone.
+func (c *PullCommand) confirmPrune(path string, edited bool) (bool, error) {
+	c.promptMu.Lock()
+	defer c.promptMu.Unlock()
+
+	c.ensureConsole()
+	if edited {
+		c.console.Warn("%s has local edits", path)
+	}
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("%s was deleted on the platform. Delete the local file? [y/N/a]: ", path))
+	if err != nil {
+		return false, err
+	}
+	switch text {
+	case "y":
+		return true, nil
+	case "a":
+		c.pruneAll = true
+		return true, nil
+	default:
+		c.console.Info("Keeping %s.", path)
+		return false, nil
+	}
+}
+
+// removeEmptyParents removes the directories between path and root that a deletion left empty. root
+// itself is kept.
+func removeEmptyParents(path, root string) {
+	for dir := filepath.Dir(filepath.FromSlash(path)); filepath.ToSlash(dir) != root && pathUnder(filepath.ToSlash(dir), root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// recordRemoteChange adds a changelog entry when the remote script of a skill differs from the baseline
+// of the last sync. It runs before the script is written, so the previous size can still be read from
+// the local file when that file is unchanged.
+func (c *PullCommand) recordRemoteChange(customerType, customerIDN, projectSlug, projectIDN, agentIDN, flowIDN string, skill platform.Skill, oldHashes state.HashStore, mu *sync.Mutex) {
 	fileName := skill.IDN + "." + platform.ScriptExtension(skill.RunnerType)
 	path := fsutil.ExportSkillScriptPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, fileName)
-	return c.writeFileWithHash(oldHashes, newHashes, path, []byte(skill.PromptScript), force, mu)
+	normalized := filepath.ToSlash(path)
+	oldHash, tracked := oldHashes[normalized]
+	newHash := util.ContentHashString(skill.PromptScript)
+	if !tracked || oldHash == newHash {
+		return
+	}
+
+	entry := state.ChangelogEntry{
+		PulledAt:   c.pulledAt,
+		ProjectIDN: projectIDN,
+		AgentIDN:   agentIDN,
+		FlowIDN:    flowIDN,
+		SkillIDN:   skill.IDN,
+		Path:       normalized,
+		OldHash:    oldHash,
+		NewHash:    newHash,
+		UpdatedAt:  skill.UpdatedAt,
+		Size:       len(skill.PromptScript),
+	}
+	if localHash, err := c.hashCache.Hash(path); err == nil && localHash == oldHash {
+		if info, err := os.Stat(path); err == nil {
+			delta := entry.Size - int(info.Size())
+			entry.SizeDelta = &delta
+		}
+	} else if c.blobs != nil && c.blobs.Has(oldHash) {
+		if info, err := os.Stat(c.blobs.Path(oldHash)); err == nil {
+			delta := entry.Size - int(info.Size())
+			entry.SizeDelta = &delta
+		}
+	}
+
+	mu.Lock()
+	c.changes = append(c.changes, entry)
+	mu.Unlock()
+}
+
+func (c *PullCommand) exportSkill(customerType, customerIDN, projectSlug, agentIDN, flowIDN string, skill platform.Skill, owner string, oldHashes, newHashes state.HashStore, force bool, mu *sync.Mutex) error {
+	fileName := skill.IDN + "." + platform.ScriptExtension(skill.RunnerType)
+	path := fsutil.ExportSkillScriptPath(c.outputRoot, customerType, customerIDN, projectSlug, agentIDN, flowIDN, fileName)
+	outcome, err := c.syncOwnedFile(owner, oldHashes, newHashes, path, []byte(skill.PromptScript), force, mu)
+	if err != nil {
+		return err
+	}
+	metrics.Add("skills_"+string(outcome), 1)
+	return nil
 }
 
 func (c *PullCommand) writeProjectJSON(oldHashes, newHashes state.HashStore, customerType, customerIDN string, project platform.Project, slug string, force bool, mu *sync.Mutex) error {
@@ -732,15 +1292,12 @@ func (c *PullCommand) confirmOverwrite(path string, lines []diff.Line) (bool, bo
 
 	c.ensureConsole()
 	c.console.Write(diff.Format(path, lines))
-	c.console.Prompt("Overwrite local file %s? [y/N/a]: ", path)
-
-	reader := bufio.NewReader(os.Stdin)
-	text, err := reader.ReadString('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
-		return false, false, fmt.Errorf("read confirmation input: %w", err)
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Overwrite local file %s? [y/N/a]: ", path))
+	if err != nil {
+		return false, false, err
 	}
 
-	response := strings.TrimSpace(strings.ToLower(text))
+	response := text
 	switch response {
 	case "y":
 		return true, false, nil
@@ -754,9 +1311,142 @@ func (c *PullCommand) confirmOverwrite(path string, lines []diff.Line) (bool, bo
 	}
 }
 
+type conflictChoice int
+
+const (
+	conflictKeepLocal conflictChoice = iota
+	conflictTakeRemote
+	conflictKeepBoth
+	// conflictMerged means the user merged both versions into the local file by hand.
+	conflictMerged
+)
+
+// resolveConflict asks how to handle a file that changed both locally and remotely.
+func (c *PullCommand) resolveConflict(path, normalized string, lines []diff.Line, remote []byte) (conflictChoice, error) {
+	c.promptMu.Lock()
+	defer c.promptMu.Unlock()
+
+	c.ensureConsole()
+	if c.applyAllOverwrite {
+		return conflictTakeRemote, nil
+	}
+	c.console.Write(diff.Format(normalized, lines))
+
+	for {
+		text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Conflict in %s: [k]eep local, [t]ake remote, [e]dit in $EDITOR, [b]oth (write %s), [a]ll remote? [K/t/e/b/a]: ", normalized, filepath.Base(path)+remoteCopySuffix))
+		if err != nil {
+			return conflictKeepLocal, err
+		}
+
+		switch text {
+		case "t", "y":
+			return conflictTakeRemote, nil
+		case "a":
+			c.applyAllOverwrite = true
+			c.console.Info("Taking remote version for all subsequent files.")
+			return conflictTakeRemote, nil
+		case "b":
+			if err := writeFile(path+remoteCopySuffix, remote); err != nil {
+				return conflictKeepLocal, err
+			}
+			c.console.Info("Remote version written to %s%s; keeping local file.", normalized, remoteCopySuffix)
+			return conflictKeepBoth, nil
+		case "e":
+			if err := c.editConflict(path, remote); err != nil {
+				c.console.Warn("Editor failed: %v", err)
+				continue
+			}
+			c.console.Info("Keeping the merged local file.")
+			return conflictMerged, nil
+		default:
+			c.console.Info("Keeping existing file.")
+			return conflictKeepLocal, nil
+		}
+	}
+}
+
+// editConflict opens the local file next to the remote version in $EDITOR so the user can merge by hand.
+func (c *PullCommand) editConflict(path string, remote []byte) error {
+	remotePath := path + remoteCopySuffix
+	if err := writeFile(remotePath, remote); err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(remotePath)
+	}()
+
+	cmd := editorCommand(os.Getenv("EDITOR"), path, remotePath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// editorCommand builds a side-by-side diff invocation for well-known editors and falls back to opening both files.
+func editorCommand(editor, local, remote string) *exec.Cmd {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+	name := filepath.Base(fields[0])
+	args := append([]string{}, fields[1:]...)
+	switch name {
+	case "vim", "nvim", "gvim", "mvim":
+		args = append(args, "-d", local, remote)
+	case "code", "code-insiders", "codium":
+		args = append(args, "--wait", "--diff", local, remote)
+	default:
+		args = append(args, local, remote)
+	}
+	return exec.Command(fields[0], args...)
+}
+
+// fileOutcome is what a pull did with one exported file; the values name the skills_* counters of
+// the pull summary.
+type fileOutcome string
+
+const (
+	fileWritten    fileOutcome = "written"
+	fileUnchanged  fileOutcome = "unchanged"
+	fileSkipped    fileOutcome = "skipped"
+	fileConflicted fileOutcome = "conflicted"
+	fileMerged     fileOutcome = "merged"
+)
+
+// syncOwnedFile writes a file of a skill whose ownership is set in the flow's metadata.yaml. A locally
+// managed file that exists is left alone; its hash becomes that of the remote content, so push sees the
+// difference as a local change. A platform-managed file is overwritten without asking.
+func (c *PullCommand) syncOwnedFile(owner string, oldHashes, newHashes state.HashStore, path string, content []byte, force bool, mu *sync.Mutex) (fileOutcome, error) {
+	switch owner {
+	case state.OwnerLocal:
+		if _, err := os.Stat(path); err == nil {
+			if mu != nil {
+				mu.Lock()
+				defer mu.Unlock()
+			}
+			newHashes[filepath.ToSlash(path)] = util.ContentHash(content)
+			if c.verboseOn {
+				c.ensureConsole()
+				c.console.Info("Keeping locally managed %s", filepath.ToSlash(path))
+			}
+			return fileSkipped, nil
+		}
+	case state.OwnerPlatform:
+		force = true
+	}
+	return c.syncFile(oldHashes, newHashes, path, content, force, mu)
+}
+
 func (c *PullCommand) writeFileWithHash(oldHashes, newHashes state.HashStore, path string, content []byte, force bool, mu *sync.Mutex) error {
+	_, err := c.syncFile(oldHashes, newHashes, path, content, force, mu)
+	return err
+}
+
+// syncFile writes content to path unless the local file already has it, has local edits the user
+// keeps, or the user declines the overwrite, and reports which of those happened.
+func (c *PullCommand) syncFile(oldHashes, newHashes state.HashStore, path string, content []byte, force bool, mu *sync.Mutex) (fileOutcome, error) {
 	if newHashes == nil {
-		return fmt.Errorf("hash store not initialised")
+		return "", fmt.Errorf("hash store not initialised")
 	}
 	if c.applyAllOverwrite {
 		force = true
@@ -764,7 +1454,7 @@ func (c *PullCommand) writeFileWithHash(oldHashes, newHashes state.HashStore, pa
 
 	c.ensureConsole()
 	normalized := filepath.ToSlash(path)
-	targetHash := util.SHA256Bytes(content)
+	targetHash := util.ContentHash(content)
 	setHash := func(value string) {
 		if mu != nil {
 			mu.Lock()
@@ -776,37 +1466,53 @@ func (c *PullCommand) writeFileWithHash(oldHashes, newHashes state.HashStore, pa
 	}
 
 	fileExists := true
-	existing, err := os.ReadFile(path)
+	existingHash, err := c.hashCache.Hash(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			fileExists = false
+			existingHash = util.ContentHash(nil)
 		} else {
-			return fmt.Errorf("read existing %s: %w", normalized, err)
+			return "", fmt.Errorf("read existing %s: %w", normalized, err)
 		}
 	}
 
-	existingHash := util.SHA256Bytes(existing)
-
 	// If content is unchanged, do nothing.
 	if fileExists && existingHash == targetHash {
 		setHash(targetHash)
-		return nil
+		return fileUnchanged, nil
+	}
+
+	var existing []byte
+	if fileExists {
+		if existing, err = os.ReadFile(path); err != nil {
+			return "", fmt.Errorf("read existing %s: %w", normalized, err)
+		}
 	}
 
 	// The file on disk is different from the content we are about to write.
 	// Check for uncommitted local changes first.
 	forceOverwrite := force || c.applyAllOverwrite
-	if oldHash, ok := oldHashes[normalized]; ok && oldHash != existingHash && fileExists {
+	if oldHash, ok := c.baseline(oldHashes, normalized); ok && oldHash != existingHash && fileExists {
 		if !forceOverwrite {
 			c.console.Warn("Local changes detected in %s", normalized)
 			lines := diff.Generate(existing, content, 3)
-			confirmed, _, err := c.confirmOverwrite(normalized, lines)
+			choice, err := c.resolveConflict(path, normalized, lines, content)
 			if err != nil {
-				return err
+				return "", err
+			}
+			if choice == conflictMerged {
+				// The merge already contains the remote changes, so the remote script is the new baseline
+				// and push uploads the merge instead of seeing a conflict again.
+				setHash(targetHash)
+				return fileMerged, nil
 			}
-			if !confirmed {
+			if choice != conflictTakeRemote {
+				// The local edits stay on disk; keeping the baseline hash marks them as pending for push.
 				setHash(oldHash)
-				return nil
+				c.promptMu.Lock()
+				c.conflicts++
+				c.promptMu.Unlock()
+				return fileConflicted, nil
 			}
 			forceOverwrite = true
 		}
@@ -822,28 +1528,80 @@ func (c *PullCommand) writeFileWithHash(oldHashes, newHashes state.HashStore, pa
 		lines := diff.Generate(existing, content, context)
 		confirmed, _, err := c.confirmOverwrite(normalized, lines)
 		if err != nil {
-			return err
+			return "", err
 		}
 		if !confirmed {
 			// We didn't write the new content, so the hash is the existing one.
 			setHash(existingHash)
-			return nil
+			return fileSkipped, nil
 		}
 	}
 
-	if err := writeFile(path, content); err != nil {
-		return err
+	if err := c.writeExport(path, util.WorkingTreeEOL(content)); err != nil {
+		return "", err
 	}
+	c.hashCache.Record(path, targetHash)
+	metrics.Add("files_written", 1)
 
 	setHash(targetHash)
-	return nil
+	return fileWritten, nil
+}
+
+// commitPull commits the export directory after a successful pull. Failures only warn: the pull itself
+// has already been saved.
+func (c *PullCommand) commitPull(ctx context.Context) {
+	repo, err := gitutil.Open(ctx, ".")
+	if err != nil {
+		c.console.Warn("Skipping --git-commit: %v", err)
+		return
+	}
+	exportDir, ok := repo.Rel(c.outputRoot)
+	if !ok {
+		c.console.Warn("Skipping --git-commit: %s is outside the git work tree", c.outputRoot)
+		return
+	}
+
+	var customers []string
+	var body strings.Builder
+	for _, pulled := range c.pulled {
+		customers = append(customers, pulled.IDN)
+		fmt.Fprintf(&body, "- %s: %s\n", pulled.IDN, strings.Join(pulled.Projects, ", "))
+	}
+	subject := "newo pull: " + strings.Join(customers, ", ")
+	if len(c.pulled) == 1 {
+		subject += " (" + strings.Join(c.pulled[0].Projects, ", ") + ")"
+	}
+
+	stat, err := repo.Stage(ctx, exportDir)
+	if err != nil {
+		c.console.Warn("Git commit failed: %v", err)
+		return
+	}
+	if stat.Total() == 0 {
+		c.console.Info("Nothing to commit: the pulled files match git.")
+		return
+	}
+	message := subject + "\n\n" + body.String() + "\n" + stat.String() + "\n"
+	if err := repo.Commit(ctx, message, exportDir); err != nil {
+		c.console.Warn("Git commit failed: %v", err)
+		return
+	}
+	c.console.Success("Committed pulled files to git (%s)", stat)
+}
+
+// writeExport writes a pulled file, through the blob store when the cache is enabled.
+func (c *PullCommand) writeExport(path string, content []byte) error {
+	if c.blobs == nil {
+		return writeFile(path, content)
+	}
+	return c.blobs.WriteFile(path, content)
 }
 
 func writeFile(path string, content []byte) error {
 	if err := fsutil.EnsureParentDir(path); err != nil {
 		return err
 	}
-	return os.WriteFile(path, content, 0o644)
+	return fsutil.AtomicWrite(path, content, fsutil.FilePerm)
 }
 
 func uniqueStrings(values []string) []string {
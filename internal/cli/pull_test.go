@@ -9,8 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/twinmind/newo-tool/internal/fsutil"
 	"github.com/twinmind/newo-tool/internal/platform"
 	"github.com/twinmind/newo-tool/internal/state"
 	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
@@ -288,3 +292,455 @@ func TestWriteFileWithHashSkipsOnDecline(t *testing.T) {
 		t.Fatalf("expected skip message in stdout, got %q", out.String())
 	}
 }
+
+func TestWriteFileWithHashConflictWritesBoth(t *testing.T) {
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+	_, _ = w.WriteString("b\n")
+	_ = w.Close()
+
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "conflict.nsl")
+	if err := os.WriteFile(path, []byte("local-content"), 0o644); err != nil {
+		t.Fatalf("write local file: %v", err)
+	}
+
+	normalized := filepath.ToSlash(path)
+	oldHashes := state.HashStore{
+		normalized: util.SHA256Bytes([]byte("previous-remote")),
+	}
+	newHashes := state.HashStore{}
+
+	cmd := &PullCommand{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+	if err := cmd.writeFileWithHash(oldHashes, newHashes, path, []byte("new-remote"), false, nil); err != nil {
+		t.Fatalf("writeFileWithHash: %v", err)
+	}
+
+	local, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if string(local) != "local-content" {
+		t.Fatalf("local file overwritten, got %q", string(local))
+	}
+	remote, err := os.ReadFile(path + remoteCopySuffix)
+	if err != nil {
+		t.Fatalf("read remote copy: %v", err)
+	}
+	if string(remote) != "new-remote" {
+		t.Fatalf("unexpected remote copy %q", string(remote))
+	}
+	if newHashes[normalized] != oldHashes[normalized] {
+		t.Fatalf("expected baseline hash preserved")
+	}
+}
+
+func TestEditorCommandUsesDiffMode(t *testing.T) {
+	cmd := editorCommand("nvim", "a.nsl", "a.nsl.remote")
+	if got := strings.Join(cmd.Args[1:], " "); got != "-d a.nsl a.nsl.remote" {
+		t.Fatalf("unexpected editor args %q", got)
+	}
+	cmd = editorCommand("nano -w", "a.nsl", "a.nsl.remote")
+	if got := strings.Join(cmd.Args[1:], " "); got != "-w a.nsl a.nsl.remote" {
+		t.Fatalf("unexpected editor args %q", got)
+	}
+}
+
+func TestPullResumesFromCheckpoint(t *testing.T) {
+	tmp := t.TempDir()
+	t.Chdir(tmp)
+
+	checkpointPath := filepath.Join(".newo", "test-customer", "pull-project-a.json")
+	var failFlowB atomic.Bool
+	failFlowB.Store(true)
+	var flowASkillRequests atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/auth/api-key/token":
+			_ = json.NewEncoder(w).Encode(platform.TokenResponse{AccessToken: "access", RefreshToken: "refresh"})
+		case "/api/v1/customer/profile":
+			_ = json.NewEncoder(w).Encode(platform.CustomerProfile{ID: "cust-123", IDN: "test-customer"})
+		case "/api/v1/designer/projects":
+			_ = json.NewEncoder(w).Encode([]platform.Project{{ID: "proj-uuid-a", IDN: "project-a"}})
+		case "/api/v1/bff/agents/list":
+			_ = json.NewEncoder(w).Encode([]platform.Agent{{
+				ID:    "agent-uuid-1",
+				IDN:   "agent-a",
+				Flows: []platform.Flow{{ID: "flow-uuid-a", IDN: "flow-a"}, {ID: "flow-uuid-b", IDN: "flow-b"}},
+			}})
+		case "/api/v1/designer/flows/flow-uuid-a/events", "/api/v1/designer/flows/flow-uuid-a/states",
+			"/api/v1/designer/flows/flow-uuid-b/states", "/api/v1/designer/flows/flow-uuid-b/skills":
+			_ = json.NewEncoder(w).Encode([]any{})
+		case "/api/v1/designer/flows/flow-uuid-a/skills":
+			flowASkillRequests.Add(1)
+			_ = json.NewEncoder(w).Encode([]platform.Skill{{ID: "skill-1", IDN: "greet", RunnerType: "nsl", PromptScript: "hello"}})
+		case "/api/v1/designer/flows/flow-uuid-b/events":
+			if failFlowB.Load() {
+				// Fail only once flow-a has been checkpointed, so the interruption lands mid-project.
+				for i := 0; i < 500; i++ {
+					if _, err := os.Stat(checkpointPath); err == nil {
+						break
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]any{})
+		case "/api/v1/bff/customer/attributes":
+			_ = json.NewEncoder(w).Encode(platform.CustomerAttributesResponse{Attributes: []platform.CustomerAttribute{}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client, transport := httpmock.New(handler)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"test-customer\"\napi_key = \"dummy-key\"\n  [[customers.projects]]\n  idn = \"project-a\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewPullCommand(&bytes.Buffer{}, &bytes.Buffer{}).Run(context.Background(), nil); err == nil {
+		t.Fatalf("expected the first pull to fail")
+	}
+	checkpoint, err := state.LoadPullCheckpoint("test-customer", "project-a")
+	if err != nil || checkpoint == nil {
+		t.Fatalf("expected a checkpoint after the failed pull, got %v, %v", checkpoint, err)
+	}
+	if _, ok := checkpoint.Flows["agent-a/flow-a"]; !ok {
+		t.Fatalf("expected flow-a in the checkpoint, got %v", checkpoint.Flows)
+	}
+
+	failFlowB.Store(false)
+	var out bytes.Buffer
+	if err := NewPullCommand(&out, &bytes.Buffer{}).Run(context.Background(), nil); err != nil {
+		t.Fatalf("resumed pull failed: %v", err)
+	}
+	if got := flowASkillRequests.Load(); got != 1 {
+		t.Fatalf("expected flow-a to be fetched once, got %d", got)
+	}
+	if !strings.Contains(out.String(), "Resuming interrupted pull of project-a") {
+		t.Fatalf("expected a resume notice, got:\n%s", out.String())
+	}
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the checkpoint to be removed, got %v", err)
+	}
+	hashes, err := state.LoadHashes("test-customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes["test-customer/project-a/agent-a/flows/flow-a/greet.nsl"] != util.SHA256String("hello") {
+		t.Fatalf("expected the resumed flow's hashes to be kept, got %v", hashes)
+	}
+	projectMap, err := state.LoadProjectMap("test-customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flows := projectMap.Projects["project-a"].Agents["agent-a"].Flows; len(flows) != 2 || flows["flow-a"].Skills["greet"].ID != "skill-1" {
+		t.Fatalf("unexpected flows in the project map: %+v", flows)
+	}
+}
+
+func TestPullSinceLastSkipsUnchangedProject(t *testing.T) {
+	tmp := t.TempDir()
+	t.Chdir(tmp)
+
+	var updatedAt atomic.Value
+	updatedAt.Store("2026-01-01T00:00:00Z")
+	var agentRequests atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/auth/api-key/token":
+			_ = json.NewEncoder(w).Encode(platform.TokenResponse{AccessToken: "access", RefreshToken: "refresh"})
+		case "/api/v1/customer/profile":
+			_ = json.NewEncoder(w).Encode(platform.CustomerProfile{ID: "cust-123", IDN: "test-customer"})
+		case "/api/v1/designer/projects":
+			_ = json.NewEncoder(w).Encode([]platform.Project{{ID: "proj-uuid-a", IDN: "project-a", UpdatedAt: updatedAt.Load().(string)}})
+		case "/api/v1/bff/agents/list":
+			agentRequests.Add(1)
+			_ = json.NewEncoder(w).Encode([]platform.Agent{{ID: "agent-uuid-1", IDN: "agent-a", Flows: []platform.Flow{{ID: "flow-uuid-a", IDN: "flow-a"}}}})
+		case "/api/v1/designer/flows/flow-uuid-a/events", "/api/v1/designer/flows/flow-uuid-a/states":
+			_ = json.NewEncoder(w).Encode([]any{})
+		case "/api/v1/designer/flows/flow-uuid-a/skills":
+			_ = json.NewEncoder(w).Encode([]platform.Skill{{ID: "skill-1", IDN: "greet", RunnerType: "nsl", PromptScript: "hello"}})
+		case "/api/v1/bff/customer/attributes":
+			_ = json.NewEncoder(w).Encode(platform.CustomerAttributesResponse{Attributes: []platform.CustomerAttribute{}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client, transport := httpmock.New(handler)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	toml := fmt.Sprintf("[defaults]\nbase_url = %q\noutput_root = \".\"\n\n[[customers]]\nidn = \"test-customer\"\napi_key = \"dummy-key\"\n  [[customers.projects]]\n  idn = \"project-a\"\n", httpmock.BaseURL)
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sinceLast := true
+	pull := func() string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewPullCommand(&out, &bytes.Buffer{})
+		cmd.sinceLast = &sinceLast
+		if err := cmd.Run(context.Background(), nil); err != nil {
+			t.Fatalf("pull failed: %v", err)
+		}
+		return out.String()
+	}
+	scriptPath := "test-customer/project-a/agent-a/flows/flow-a/greet.nsl"
+
+	pull()
+	pull()
+	if got := agentRequests.Load(); got != 1 {
+		t.Fatalf("expected the unchanged project to be skipped, got %d agent listings", got)
+	}
+	hashes, err := state.LoadHashes("test-customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes[scriptPath] != util.SHA256String("hello") {
+		t.Fatalf("expected the skipped project's hashes to be kept, got %v", hashes)
+	}
+	projectMap, err := state.LoadProjectMap("test-customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skills := projectMap.Projects["project-a"].Agents["agent-a"].Flows["flow-a"].Skills; skills["greet"].ID != "skill-1" {
+		t.Fatalf("expected the project map to be kept, got %+v", projectMap.Projects["project-a"])
+	}
+
+	if err := os.Remove(scriptPath); err != nil {
+		t.Fatal(err)
+	}
+	pull()
+	if got := agentRequests.Load(); got != 2 {
+		t.Fatalf("expected a missing file to force a pull, got %d agent listings", got)
+	}
+
+	updatedAt.Store("2026-02-01T00:00:00Z")
+	pull()
+	if got := agentRequests.Load(); got != 3 {
+		t.Fatalf("expected a newer updated_at to force a pull, got %d agent listings", got)
+	}
+}
+
+func TestRecordRemoteChange(t *testing.T) {
+	tmp := t.TempDir()
+	cmd := &PullCommand{outputRoot: tmp}
+	skill := platform.Skill{IDN: "greet", RunnerType: "guidance", PromptScript: "hello there", UpdatedAt: "2024-05-01T10:00:00Z"}
+	path := fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "booking", "agent", "main", "greet."+platform.ScriptExtension("guidance"))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	normalized := filepath.ToSlash(path)
+	var mu sync.Mutex
+
+	// Unchanged and untracked skills are not logged.
+	cmd.recordRemoteChange("e2e", "acme", "booking", "booking", "agent", "main", skill, state.HashStore{normalized: util.SHA256String(skill.PromptScript)}, &mu)
+	cmd.recordRemoteChange("e2e", "acme", "booking", "booking", "agent", "main", skill, state.HashStore{}, &mu)
+	if len(cmd.changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", cmd.changes)
+	}
+
+	cmd.recordRemoteChange("e2e", "acme", "booking", "booking", "agent", "main", skill, state.HashStore{normalized: util.SHA256Bytes([]byte("hello"))}, &mu)
+	if len(cmd.changes) != 1 {
+		t.Fatalf("expected one change, got %+v", cmd.changes)
+	}
+	change := cmd.changes[0]
+	if change.SkillIDN != "greet" || change.Path != normalized || change.UpdatedAt != skill.UpdatedAt || change.Size != len(skill.PromptScript) {
+		t.Fatalf("unexpected change %+v", change)
+	}
+	if change.SizeDelta == nil || *change.SizeDelta != len(" there") {
+		t.Fatalf("expected size delta %d, got %v", len(" there"), change.SizeDelta)
+	}
+
+	// A locally edited file no longer tells the previous size.
+	cmd.changes = nil
+	cmd.recordRemoteChange("e2e", "acme", "booking", "booking", "agent", "main", skill, state.HashStore{normalized: util.SHA256String("older")}, &mu)
+	if len(cmd.changes) != 1 || cmd.changes[0].SizeDelta != nil {
+		t.Fatalf("expected change without size delta, got %+v", cmd.changes)
+	}
+}
+
+func TestFollowRemoteRenameMovesFilesAndBaseline(t *testing.T) {
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+	_, _ = w.WriteString("y\n")
+	_ = w.Close()
+
+	tmp := t.TempDir()
+	oldScript := fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "booking", "agent", "main", "greet.nsl")
+	oldMeta := fsutil.ExportSkillMetadataPath(tmp, "e2e", "acme", "booking", "agent", "main", "greet")
+	writeTestFile(t, oldScript, "hello, edited")
+	writeTestFile(t, oldMeta, "id: skill-1\nidn: greet\n")
+	oldHashes := state.HashStore{filepath.ToSlash(oldScript): util.SHA256String("hello")}
+
+	cmd := &PullCommand{
+		stdout:         &bytes.Buffer{},
+		stderr:         &bytes.Buffer{},
+		outputRoot:     tmp,
+		previousSkills: map[string]previousSkill{"skill-1": {projectIDN: "booking", agentIDN: "agent", flowIDN: "main", idn: "greet", runnerType: "nsl"}},
+		movedHashes:    state.HashStore{},
+	}
+	skill := platform.Skill{ID: "skill-1", IDN: "welcome", RunnerType: "nsl", PromptScript: "hello"}
+	if err := cmd.followRemoteRename("e2e", "acme", "booking", "booking", "agent", "main", skill, oldHashes, false); err != nil {
+		t.Fatalf("followRemoteRename: %v", err)
+	}
+
+	newScript := fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "booking", "agent", "main", "welcome.nsl")
+	if content, err := os.ReadFile(newScript); err != nil || string(content) != "hello, edited" {
+		t.Fatalf("expected the local script under the new name, got %q, %v", content, err)
+	}
+	if _, err := os.Stat(fsutil.ExportSkillMetadataPath(tmp, "e2e", "acme", "booking", "agent", "main", "welcome")); err != nil {
+		t.Fatalf("expected the metadata under the new name: %v", err)
+	}
+	for _, path := range []string{oldScript, oldMeta} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be moved, got %v", path, err)
+		}
+	}
+	if hash, ok := cmd.baseline(oldHashes, filepath.ToSlash(newScript)); !ok || hash != util.SHA256String("hello") {
+		t.Fatalf("expected the baseline to follow the rename, got %q, %v", hash, ok)
+	}
+}
+
+func TestFollowRemoteRenameAsksDespiteOverwriteAll(t *testing.T) {
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+	_, _ = w.WriteString("n\n")
+	_ = w.Close()
+
+	tmp := t.TempDir()
+	oldScript := fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "booking", "agent", "main", "greet.nsl")
+	writeTestFile(t, oldScript, "hello")
+
+	// Answering "a" to an overwrite prompt covers overwrites only; renames keep their own prompt.
+	cmd := &PullCommand{
+		stdout:            &bytes.Buffer{},
+		stderr:            &bytes.Buffer{},
+		outputRoot:        tmp,
+		applyAllOverwrite: true,
+		previousSkills:    map[string]previousSkill{"skill-1": {projectIDN: "booking", agentIDN: "agent", flowIDN: "main", idn: "greet", runnerType: "nsl"}},
+		movedHashes:       state.HashStore{},
+	}
+	skill := platform.Skill{ID: "skill-1", IDN: "welcome", RunnerType: "nsl", PromptScript: "hello"}
+	if err := cmd.followRemoteRename("e2e", "acme", "booking", "booking", "agent", "main", skill, state.HashStore{}, false); err != nil {
+		t.Fatalf("followRemoteRename: %v", err)
+	}
+	if _, err := os.Stat(oldScript); err != nil {
+		t.Fatalf("expected the declined rename to keep the old file: %v", err)
+	}
+}
+
+func TestPruneStaleRemovesFilesDeletedRemotely(t *testing.T) {
+	tmp := t.TempDir()
+	projectDir := filepath.ToSlash(fsutil.ExportProjectDir(tmp, "e2e", "acme", "booking"))
+	kept := filepath.ToSlash(fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "booking", "agent", "main", "greet.nsl"))
+	staleSkill := filepath.ToSlash(fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "booking", "agent", "main", "gone.nsl"))
+	staleFlow := filepath.ToSlash(fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "booking", "agent", "old", "skill.nsl"))
+	outside := filepath.ToSlash(fsutil.ExportSkillScriptPath(tmp, "e2e", "acme", "other", "agent", "main", "skill.nsl"))
+	oldHashes := state.HashStore{}
+	for _, path := range []string{kept, staleSkill, staleFlow, outside} {
+		writeTestFile(t, path, "content")
+		oldHashes[path] = util.SHA256String("content")
+	}
+	newHashes := state.HashStore{kept: oldHashes[kept]}
+
+	prune := false
+	cmd := &PullCommand{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, prune: &prune}
+	if err := cmd.pruneStale(oldHashes, newHashes, []string{projectDir}, true); err != nil {
+		t.Fatalf("pruneStale: %v", err)
+	}
+	if _, err := os.Stat(staleSkill); err != nil {
+		t.Fatalf("expected files to stay without --prune: %v", err)
+	}
+
+	prune = true
+	if err := cmd.pruneStale(oldHashes, newHashes, []string{projectDir}, true); err != nil {
+		t.Fatalf("pruneStale: %v", err)
+	}
+	for _, path := range []string{staleSkill, staleFlow, filepath.Dir(staleFlow)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be pruned, got %v", path, err)
+		}
+	}
+	for _, path := range []string{kept, outside} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to be kept: %v", path, err)
+		}
+	}
+}
+
+func TestSyncOwnedFileRespectsOwnership(t *testing.T) {
+	tmp := t.TempDir()
+	local := filepath.Join(tmp, "local.nsl")
+	platformManaged := filepath.Join(tmp, "platform.nsl")
+	writeTestFile(t, local, "customised")
+	writeTestFile(t, platformManaged, "edited")
+	oldHashes := state.HashStore{
+		filepath.ToSlash(local):           util.SHA256String("previous"),
+		filepath.ToSlash(platformManaged): util.SHA256String("previous"),
+	}
+	newHashes := state.HashStore{}
+
+	cmd := &PullCommand{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+	outcome, err := cmd.syncOwnedFile(state.OwnerLocal, oldHashes, newHashes, local, []byte("remote"), false, nil)
+	if err != nil || outcome != fileSkipped {
+		t.Fatalf("expected the locally managed file to be skipped, got %q, %v", outcome, err)
+	}
+	if content, _ := os.ReadFile(local); string(content) != "customised" {
+		t.Fatalf("expected the locally managed file to be kept, got %q", content)
+	}
+	if newHashes[filepath.ToSlash(local)] != util.SHA256String("remote") {
+		t.Fatalf("expected the remote hash to be recorded so push sees the local version as a change")
+	}
+
+	outcome, err = cmd.syncOwnedFile(state.OwnerPlatform, oldHashes, newHashes, platformManaged, []byte("remote"), false, nil)
+	if err != nil || outcome != fileWritten {
+		t.Fatalf("expected the platform-managed file to be written, got %q, %v", outcome, err)
+	}
+	if content, _ := os.ReadFile(platformManaged); string(content) != "remote" {
+		t.Fatalf("expected the platform-managed file to be overwritten, got %q", content)
+	}
+}
+
+func TestSyncFileWritesCRLFAndKeepsSlashKeys(t *testing.T) {
+	if err := util.ConfigureLineEndings(util.AutoCRLFTrue); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = util.ConfigureLineEndings("") })
+
+	path := filepath.Join(t.TempDir(), "flows", "main", "greet.nsl")
+	newHashes := state.HashStore{}
+	cmd := &PullCommand{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+	if outcome, err := cmd.syncFile(state.HashStore{}, newHashes, path, []byte("Hello\nWorld\n"), false, nil); err != nil || outcome != fileWritten {
+		t.Fatalf("expected the script to be written, got %q, %v", outcome, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "Hello\r\nWorld\r\n" {
+		t.Fatalf("expected CRLF line endings on disk, got %q, %v", content, err)
+	}
+	key := filepath.ToSlash(path)
+	if newHashes[key] != util.SHA256String("Hello\nWorld\n") || strings.Contains(key, `\`) {
+		t.Fatalf("expected the LF hash under a slash-separated key, got %v", newHashes)
+	}
+
+	// A second pull of the same script finds the CRLF checkout unchanged.
+	if outcome, err := cmd.syncFile(newHashes, state.HashStore{}, path, []byte("Hello\nWorld\n"), false, nil); err != nil || outcome != fileUnchanged {
+		t.Fatalf("expected the CRLF file to match the remote script, got %q, %v", outcome, err)
+	}
+}
@@ -1,19 +1,23 @@
 package cli
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/twinmind/newo-tool/internal/config"
 	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/deploy"
 	"github.com/twinmind/newo-tool/internal/diff"
 	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/gitutil"
+	"github.com/twinmind/newo-tool/internal/metrics"
+	"github.com/twinmind/newo-tool/internal/notify"
 	"github.com/twinmind/newo-tool/internal/session"
 	"github.com/twinmind/newo-tool/internal/state"
 	skillsync "github.com/twinmind/newo-tool/internal/sync"
@@ -22,16 +26,38 @@ import (
 
 // PushCommand uploads local script changes to the NEWO platform.
 type PushCommand struct {
-	stdout    io.Writer
-	stderr    io.Writer
-	console   *console.Writer
-	verbose   *bool
-	customer  *string
-	noPublish *bool
-	force     *bool
+	stdout     io.Writer
+	stderr     io.Writer
+	console    *console.Writer
+	verbose    *bool
+	customer   *string
+	group      *string
+	tag        *string
+	noPublish  *bool
+	force      *bool
+	undoLast   *bool
+	noHooks    *bool
+	allowDirty *bool
+	metricsOut *string
+	confirmIDN *string
+
+	// concurrency is the --concurrency flag; limits resolves it against [performance] in newo.toml.
+	concurrency *int
+	limits      config.PerformanceConfig
 
 	outputRoot string
 	slugPrefix string
+	blobCache  bool
+	tally      *runTally
+	conflicts  int
+
+	// notifications are the webhooks from newo.toml; events collects one summary per pushed customer.
+	// Merge sets skipNotify and reports the push as part of its own summary.
+	notifications []config.Notification
+	events        []notify.Event
+	skipNotify    bool
+
+	prompts
 }
 
 // NewPushCommand constructs a push command.
@@ -60,12 +86,30 @@ func (c *PushCommand) Summary() string {
 func (c *PushCommand) RegisterFlags(fs *flag.FlagSet) {
 	c.verbose = fs.Bool("verbose", false, "show detailed output")
 	c.customer = fs.String("customer", "", "customer IDN to push")
+	c.group = fs.String("group", "", "push every customer in this newo.toml group")
+	c.tag = fs.String("tag", "", "only push skills with one of these comma-separated tags in their .meta.yaml")
 	c.noPublish = fs.Bool("no-publish", false, "skip publishing flows after upload")
 	c.force = fs.Bool("force", false, "skip interactive diff and confirmation")
+	c.undoLast = fs.Bool("undo-last", false, "revert the skills updated by the most recent push")
+	c.noHooks = fs.Bool("no-hooks", false, "skip the pre-push hooks configured in newo.toml")
+	c.allowDirty = fs.Bool("allow-dirty", false, "push even when git has uncommitted changes outside the export directory")
+	c.metricsOut = fs.String("metrics-out", "", "write a run summary (JSON, or Prometheus text for .prom) to this file")
+	c.confirmIDN = fs.String("confirm-customer", "", "IDN of a protected customer, confirming the push to it")
+	c.concurrency = fs.Int("concurrency", 0, concurrencyUsage)
 }
 
 func (c *PushCommand) Run(ctx context.Context, args []string) error {
 	c.ensureConsole()
+	metrics.Reset(c.Name())
+	c.events = nil
+	err := c.run(ctx, args)
+	if !c.skipNotify {
+		sendNotifications(ctx, c.console, c.notifications, c.events)
+	}
+	return finishRunMetrics(c.metricsOut, err, c.console)
+}
+
+func (c *PushCommand) run(ctx context.Context, args []string) error {
 	if len(args) > 0 {
 		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
 	}
@@ -77,45 +121,69 @@ func (c *PushCommand) Run(ctx context.Context, args []string) error {
 	}
 	shouldPublish := c.noPublish == nil || !*c.noPublish
 	force := c.force != nil && *c.force
+	undoLast := c.undoLast != nil && *c.undoLast
+	if undoLast && flagValue(c.tag) != "" {
+		return errors.New("--tag cannot be combined with --undo-last")
+	}
 
 	env, err := config.LoadEnv()
 	if err != nil {
 		return err
 	}
 
+	if c.limits, err = performanceLimits(env, c.concurrency); err != nil {
+		return err
+	}
 	c.outputRoot = env.OutputRoot
+	c.blobCache = env.BlobCache
 	c.slugPrefix = env.SlugPrefix
+	c.notifications = env.Notifications
+	c.conflicts = 0
+	c.tally = newRunTally("Push summary",
+		tallyColumn{"UPDATED", "skills_updated"},
+		tallyColumn{"CREATED", "skills_created"},
+		tallyColumn{"REMOVED", "skills_removed"},
+		tallyColumn{"SKIPPED", "skills_skipped"},
+		tallyColumn{"CONFLICTED", "skills_conflicted"},
+		tallyColumn{"PUBLISHED", "flows_published"},
+	)
+
+	if !undoLast && len(env.PrePushHooks) > 0 && (c.noHooks == nil || !*c.noHooks) {
+		endHooks := metrics.Phase("hooks")
+		err := runPrePushHooks(ctx, env.PrePushHooks, customerFilter, c.stdout, c.stderr)
+		endHooks()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.checkGitClean(ctx, c.allowDirty != nil && *c.allowDirty); err != nil {
+		return err
+	}
 
 	cfg, err := customer.FromEnv(env)
 	if err != nil {
 		return err
 	}
-
-	registry, err := state.LoadAPIKeyRegistry()
+	entries, err := groupEntries(cfg, flagValue(c.group), customerFilter)
 	if err != nil {
 		return err
 	}
 
-	releaseLock, err := fsutil.AcquireLock("push")
+	registry, err := state.LoadAPIKeyRegistry()
 	if err != nil {
-		if errors.Is(err, fsutil.ErrLocked) {
-			return fmt.Errorf("another operation is already running; please retry later")
-		}
 		return err
 	}
-	defer func() {
-		if err := releaseLock(); err != nil && verbose {
-			c.console.Warn("Release lock: %v", err)
-		}
-	}()
 
 	registryDirty := false
 	matchedFilter := false
 	requestedCustomer := customerFilter
 	processed := map[string]bool{}
 
-	for _, entry := range cfg.Entries {
+	for _, entry := range entries {
+		endAuth := metrics.Phase("auth")
 		session, err := session.New(ctx, env, entry, registry)
+		endAuth()
 		if err != nil {
 			return err
 		}
@@ -137,7 +205,23 @@ func (c *PushCommand) Run(ctx context.Context, args []string) error {
 			continue
 		}
 
-		if err := c.pushCustomer(ctx, session, shouldPublish, verbose, force); err != nil {
+		if err := confirmProtectedCustomer(c.console, c.prompt(), entry, session.IDN, flagValue(c.confirmIDN)); err != nil {
+			return err
+		}
+
+		started := time.Now()
+		event := notify.Event{Command: "push", Customer: session.IDN, PublishSkipped: !shouldPublish}
+		err = c.tally.measure(session.IDN, func() error {
+			return withCustomerLock(c.console, session.IDN, "push", verbose, func() error {
+				if undoLast {
+					return c.undoCustomer(ctx, session, shouldPublish, verbose, force, &event)
+				}
+				return c.pushCustomer(ctx, session, shouldPublish, verbose, force, &event)
+			})
+		})
+		event.Finish(started, err)
+		c.events = append(c.events, event)
+		if err != nil {
 			return err
 		}
 		processed[key] = true
@@ -162,10 +246,17 @@ func (c *PushCommand) Run(ctx context.Context, args []string) error {
 		}
 	}
 
+	c.tally.print(c.console)
+	if c.conflicts > 0 {
+		return exitError{
+			msg:  fmt.Sprintf("%d skill(s) changed on the platform since the last pull and were not pushed; run `newo pull` and push again", c.conflicts),
+			code: exitConflicts,
+		}
+	}
 	return nil
 }
 
-func (c *PushCommand) pushCustomer(ctx context.Context, session *session.Session, shouldPublish bool, verbose bool, force bool) error {
+func (c *PushCommand) pushCustomer(ctx context.Context, session *session.Session, shouldPublish bool, verbose bool, force bool, event *notify.Event) error {
 	c.ensureConsole()
 	if verbose {
 		c.console.Section(fmt.Sprintf("Push %s", session.IDN))
@@ -180,6 +271,16 @@ func (c *PushCommand) pushCustomer(ctx context.Context, session *session.Session
 		return nil
 	}
 
+	recreated, err := c.recreateMissingProjects(ctx, session, projectMap, force)
+	if err != nil {
+		return err
+	}
+	if recreated {
+		if projectMap, err = state.LoadProjectMap(session.IDN); err != nil {
+			return err
+		}
+	}
+
 	hashes, err := state.LoadHashes(session.IDN)
 	if err != nil {
 		return err
@@ -189,25 +290,57 @@ func (c *PushCommand) pushCustomer(ctx context.Context, session *session.Session
 		return nil
 	}
 
+	hashCache, err := state.LoadFileHashCache(session.IDN, c.blobCache)
+	if err != nil {
+		return err
+	}
+
 	service := skillsync.NewSkillSyncService(session.Client, nil)
 	reporter := consoleReporter{writer: c.console}
 
+	progress := c.console.StartProgress(fmt.Sprintf("Push %s", session.IDN), "skills")
+	defer progress.Finish()
+
+	endPush := metrics.Phase("push")
+	defer endPush()
 	result, err := service.SyncCustomer(ctx, skillsync.SkillSyncRequest{
-		SessionIDN:    session.IDN,
-		CustomerType:  session.CustomerType,
-		OutputRoot:    c.outputRoot,
-		ProjectMap:    &projectMap,
-		Hashes:        hashes,
-		ShouldPublish: shouldPublish,
-		Verbose:       verbose,
-		Force:         force,
-		Reporter:      reporter,
-		ProjectSlugger: func(projectIDN string, data state.ProjectData) string {
-			return c.projectSlug(projectIDN, data)
-		},
-		ConfirmPush:     c.confirmSkillUpdate,
-		ConfirmDeletion: c.confirmSkillRemoval,
+		SessionIDN:           session.IDN,
+		CustomerType:         session.CustomerType,
+		OutputRoot:           c.outputRoot,
+		ProjectMap:           &projectMap,
+		Hashes:               hashes,
+		ShouldPublish:        shouldPublish,
+		Verbose:              verbose,
+		Force:                force,
+		Reporter:             reporter,
+		Progress:             progress,
+		SlugPrefix:           c.slugPrefix,
+		ConfirmPush:          c.confirmSkillUpdate,
+		ConfirmDeletion:      c.confirmSkillRemoval,
+		ConfirmAgentDeletion: c.confirmAgentRemoval,
+		ConfirmFlowChanges:   c.confirmFlowChanges,
+		ResolveConflict:      c.conflictResolver(ctx, session.Client),
+		Tags:                 splitRuleList(c.tag),
+		HashCache:            hashCache,
+		Concurrency:          c.limits.Uploads,
 	})
+	progress.Finish()
+	if saveErr := hashCache.Save(); saveErr != nil && verbose {
+		c.console.Warn("Save file hash cache: %v", saveErr)
+	}
+	event.SkillsUpdated = result.Updated
+	event.SkillsCreated = result.Created
+	event.SkillsDeleted = result.Removed
+	event.Published = result.Published
+	event.Counts = map[string]int{
+		"agents_created": result.AgentsCreated,
+		"agents_removed": result.AgentsRemoved,
+		"flows_created":  result.FlowsCreated,
+		"flow_changes":   result.FlowChanges,
+	}
+	for _, warning := range result.Warnings {
+		event.Warnings = append(event.Warnings, warning.Message)
+	}
 	if err != nil {
 		return err
 	}
@@ -215,8 +348,25 @@ func (c *PushCommand) pushCustomer(ctx context.Context, session *session.Session
 	if result.Force && c.force != nil {
 		*c.force = true
 	}
-
-	if result.Updated == 0 && result.Removed == 0 && result.Created == 0 {
+	metrics.Add("skills_updated", result.Updated)
+	metrics.Add("skills_created", result.Created)
+	metrics.Add("skills_removed", result.Removed)
+	metrics.Add("skills_renamed", result.Renamed)
+	metrics.Add("agents_created", result.AgentsCreated)
+	metrics.Add("agents_removed", result.AgentsRemoved)
+	metrics.Add("flows_created", result.FlowsCreated)
+	metrics.Add("flow_changes", result.FlowChanges)
+	metrics.Add("flows_published", result.Published)
+	metrics.Add("skills_already_remote", result.AlreadyRemote)
+	metrics.Add("skills_skipped", result.Skipped)
+	metrics.Add("skills_conflicted", result.Conflicted)
+	c.conflicts += result.Conflicted
+
+	if result.AlreadyRemote > 0 {
+		c.console.Info("%d edited skill(s) already matched the platform; upload skipped", result.AlreadyRemote)
+	}
+
+	if !result.Changed() {
 		c.console.Info("No changes to push for %s.", session.IDN)
 		return nil
 	}
@@ -234,6 +384,21 @@ func (c *PushCommand) pushCustomer(ctx context.Context, session *session.Session
 	if result.Created > 0 {
 		c.console.Success("Created %d skill(s) for %s", result.Created, session.IDN)
 	}
+	if result.Renamed > 0 {
+		c.console.Success("Renamed %d skill(s) for %s", result.Renamed, session.IDN)
+	}
+	if result.AgentsCreated > 0 {
+		c.console.Success("Created %d agent(s) for %s", result.AgentsCreated, session.IDN)
+	}
+	if result.AgentsRemoved > 0 {
+		c.console.Success("Removed %d agent(s) for %s", result.AgentsRemoved, session.IDN)
+	}
+	if result.FlowsCreated > 0 {
+		c.console.Success("Created %d flow(s) for %s", result.FlowsCreated, session.IDN)
+	}
+	if result.FlowChanges > 0 {
+		c.console.Success("Applied %d flow definition change(s) for %s", result.FlowChanges, session.IDN)
+	}
 	if shouldPublish && result.Published > 0 && verbose {
 		c.console.Info("Published %d flow(s) for %s", result.Published, session.IDN)
 	}
@@ -241,21 +406,221 @@ func (c *PushCommand) pushCustomer(ctx context.Context, session *session.Session
 	return nil
 }
 
+func (c *PushCommand) undoCustomer(ctx context.Context, session *session.Session, shouldPublish bool, verbose bool, force bool, event *notify.Event) error {
+	c.ensureConsole()
+
+	journal, err := state.LoadPushJournal(session.IDN)
+	if err != nil {
+		return err
+	}
+	record, err := journal.LastPush()
+	if err != nil {
+		if errors.Is(err, state.ErrJournalEmpty) {
+			c.console.Info("No recorded push to undo for %s.", session.IDN)
+			return nil
+		}
+		return err
+	}
+
+	if !force {
+		text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Revert %d skill(s) pushed to %s at %s? [y/N]: ", len(record.Entries), session.IDN, record.CreatedAt.Local().Format("2006-01-02 15:04:05")))
+		if err != nil {
+			return err
+		}
+		if text != "y" {
+			c.console.Info("Skipping.")
+			return nil
+		}
+	}
+
+	hashes, err := state.LoadHashes(session.IDN)
+	if err != nil {
+		return err
+	}
+
+	service := skillsync.NewSkillSyncService(session.Client, nil)
+	result, undoErr := service.UndoPush(ctx, skillsync.UndoRequest{
+		Record:        record,
+		Hashes:        hashes,
+		ShouldPublish: shouldPublish,
+		Verbose:       verbose,
+		Force:         force,
+		Reporter:      consoleReporter{writer: c.console},
+	})
+	event.Counts = map[string]int{"skills_reverted": result.Reverted, "skills_not_reverted": result.Skipped}
+	event.SkillsUpdated = result.Reverted
+	event.Published = result.Published
+	if result.Reverted > 0 {
+		if err := state.SaveHashes(session.IDN, result.Hashes); err != nil {
+			return err
+		}
+	}
+	if undoErr != nil {
+		return undoErr
+	}
+
+	if result.Skipped == 0 {
+		journal.DropLastPush()
+		if err := state.SavePushJournal(session.IDN, journal); err != nil {
+			return err
+		}
+	} else {
+		c.console.Warn("%d skill(s) were not reverted; the push remains in the journal.", result.Skipped)
+	}
+
+	c.console.Success("Reverted %d skill(s) for %s", result.Reverted, session.IDN)
+	return nil
+}
+
+// recreateMissingProjects checks every mapped project against the platform. A project deleted remotely is
+// re-created from the local workspace via the deploy service, which rebinds the IDs stored in the project map
+// and hashes. It reports whether any project was re-created.
+func (c *PushCommand) recreateMissingProjects(ctx context.Context, session *session.Session, projectMap state.ProjectMap, force bool) (bool, error) {
+	projects, err := session.Client.ListProjects(ctx)
+	if err != nil {
+		return false, fmt.Errorf("list projects: %w", err)
+	}
+	remote := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		remote[strings.TrimSpace(project.ID)] = true
+	}
+
+	projectIDNs := make([]string, 0, len(projectMap.Projects))
+	for projectIDN := range projectMap.Projects {
+		projectIDNs = append(projectIDNs, projectIDN)
+	}
+	sort.Strings(projectIDNs)
+
+	recreated := false
+	for _, projectIDN := range projectIDNs {
+		projectID := strings.TrimSpace(projectMap.Projects[projectIDN].ProjectID)
+		if projectID == "" || remote[projectID] {
+			continue
+		}
+		if fsutil.HasAgentDirs(session.CustomerType) {
+			return recreated, fmt.Errorf("project %s (%s) no longer exists on NEWO; run `newo pull --customer %s` to refresh local state", projectIDN, projectID, session.IDN)
+		}
+		if !force && !c.confirmProjectRecreate(projectIDN, projectID) {
+			return recreated, fmt.Errorf("project %s (%s) no longer exists on NEWO; push aborted", projectIDN, projectID)
+		}
+
+		plan, err := deploy.LoadSourceProject(deploy.SourceConfig{
+			OutputRoot:   c.outputRoot,
+			CustomerType: session.CustomerType,
+			CustomerIDN:  session.IDN,
+			ProjectIDN:   projectIDN,
+			SlugPrefix:   c.slugPrefix,
+		})
+		if err != nil {
+			return recreated, fmt.Errorf("load project %s: %w", projectIDN, err)
+		}
+		result, err := deploy.NewService(session.Client).Deploy(ctx, deploy.DeployRequest{
+			Project:            plan,
+			TargetCustomerIDN:  session.IDN,
+			TargetCustomerType: session.CustomerType,
+			OutputRoot:         c.outputRoot,
+			WorkspaceDir:       ".",
+			Reporter:           consoleReporter{writer: c.console},
+			MergeState:         true,
+		})
+		if err != nil {
+			return recreated, fmt.Errorf("re-create project %s: %w", projectIDN, err)
+		}
+		recreated = true
+		c.console.Success("Re-created project %s for %s (ID %s)", projectIDN, session.IDN, result.ProjectID)
+		c.warnPinnedProjectID(session.IDN, projectIDN, result.ProjectID)
+	}
+	return recreated, nil
+}
+
+// checkGitClean refuses to push while the git work tree has uncommitted changes outside the export
+// directory, so every platform change can be traced back to committed sources. Workspaces outside git
+// are not checked.
+func (c *PushCommand) checkGitClean(ctx context.Context, allowDirty bool) error {
+	repo, err := gitutil.Open(ctx, ".")
+	if err != nil {
+		return nil
+	}
+	changes, err := repo.Changes(ctx)
+	if err != nil {
+		c.console.Warn("Could not check git status: %v", err)
+		return nil
+	}
+	exportDir, exportInRepo := repo.Rel(c.outputRoot)
+	stateDir, _ := repo.Rel(fsutil.StateDirName)
+
+	var outside []string
+	for _, change := range changes {
+		if (exportInRepo && pathUnder(change.Path, exportDir)) || pathUnder(change.Path, stateDir) {
+			continue
+		}
+		outside = append(outside, change.Path)
+	}
+	if len(outside) == 0 {
+		return nil
+	}
+
+	const shown = 10
+	listing := strings.Join(outside[:min(len(outside), shown)], ", ")
+	if len(outside) > shown {
+		listing += fmt.Sprintf(" and %d more", len(outside)-shown)
+	}
+	if allowDirty {
+		c.console.Warn("Pushing with uncommitted git changes outside %s: %s", c.outputRoot, listing)
+		return nil
+	}
+	return fmt.Errorf("git has uncommitted changes outside %s (%s); commit them first or pass --allow-dirty", c.outputRoot, listing)
+}
+
+// pathUnder reports whether a slash-separated path equals dir or lies inside it.
+func pathUnder(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	return dir == "." || path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+func (c *PushCommand) confirmProjectRecreate(projectIDN, projectID string) bool {
+	c.ensureConsole()
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Project %s (%s) no longer exists on NEWO. Re-create it from the local workspace? [y/N]: ", projectIDN, projectID))
+	return err == nil && text == "y"
+}
+
+// warnPinnedProjectID points out newo.toml entries that still pin a re-created project to its old ID.
+func (c *PushCommand) warnPinnedProjectID(customerIDN, projectIDN, projectID string) {
+	cfg, err := config.LoadToml(config.TomlPath())
+	if err != nil {
+		return
+	}
+	customers, err := cfg.ActiveCustomers()
+	if err != nil {
+		return
+	}
+	for _, entry := range customers {
+		if !strings.EqualFold(entry.IDN, customerIDN) {
+			continue
+		}
+		for _, project := range entry.Projects {
+			if strings.EqualFold(project.IDN, projectIDN) && project.ID != "" && project.ID != projectID {
+				c.console.Warn("newo.toml pins project %s to %s; update it to %s", projectIDN, project.ID, projectID)
+			}
+		}
+	}
+}
+
 func (c *PushCommand) confirmSkillUpdate(req skillsync.ConfirmPushRequest) (skillsync.Decision, error) {
 	c.ensureConsole()
 
 	if len(req.Diff) > 0 {
-		c.console.Write(diff.Format(req.Path, req.Diff))
+		pageDiff(c.stdout, c.console, diff.Format(req.Path, req.Diff))
 	}
 
-	c.console.Prompt("Push changes? [y/N/a]: ")
-	reader := bufio.NewReader(os.Stdin)
-	text, err := reader.ReadString('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
+	text, err := c.prompt().Confirm(c.console, "Push changes? [y/N/a]: ")
+	if err != nil {
 		return skillsync.Decision{}, err
 	}
 
-	switch strings.TrimSpace(strings.ToLower(text)) {
+	switch text {
 	case "y":
 		return skillsync.Decision{Apply: true}, nil
 	case "a":
@@ -268,13 +633,11 @@ func (c *PushCommand) confirmSkillUpdate(req skillsync.ConfirmPushRequest) (skil
 
 func (c *PushCommand) confirmSkillRemoval(path, skillIDN string) (skillsync.Decision, error) {
 	c.ensureConsole()
-	c.console.Prompt("Skill %s missing locally. Delete remote version %s? [y/N/a]: ", skillIDN, path)
-	reader := bufio.NewReader(os.Stdin)
-	text, err := reader.ReadString('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Skill %s missing locally. Delete remote version %s? [y/N/a]: ", skillIDN, path))
+	if err != nil {
 		return skillsync.Decision{}, err
 	}
-	switch strings.TrimSpace(strings.ToLower(text)) {
+	switch text {
 	case "y":
 		return skillsync.Decision{Apply: true}, nil
 	case "a":
@@ -285,6 +648,50 @@ func (c *PushCommand) confirmSkillRemoval(path, skillIDN string) (skillsync.Deci
 	}
 }
 
+func (c *PushCommand) confirmAgentRemoval(path, agentIDN string) (skillsync.Decision, error) {
+	c.ensureConsole()
+	text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Agent %s missing locally (%s). Delete remote agent and all its flows? [y/N/a]: ", agentIDN, path))
+	if err != nil {
+		return skillsync.Decision{}, err
+	}
+	switch text {
+	case "y":
+		return skillsync.Decision{Apply: true}, nil
+	case "a":
+		return skillsync.Decision{Apply: true, ApplyAll: true}, nil
+	default:
+		c.console.Info("Keeping remote agent.")
+		return skillsync.Decision{}, nil
+	}
+}
+
+func (c *PushCommand) confirmFlowChanges(req skillsync.ConfirmFlowChangesRequest) (skillsync.Decision, error) {
+	c.ensureConsole()
+	c.console.Info("Flow %s/%s definition changed (%s):", req.ProjectIDN, req.FlowIDN, req.Path)
+	lines := make([]string, 0, len(req.Changes))
+	for _, change := range req.Changes {
+		lines = append(lines, fmt.Sprintf("%s %s %s", change.Action, change.Kind, change.IDN))
+	}
+	c.console.List(lines)
+	if len(req.Diff) > 0 {
+		pageDiff(c.stdout, c.console, diff.Format(req.Path, req.Diff))
+	}
+
+	text, err := c.prompt().Confirm(c.console, "Apply flow changes? [y/N/a]: ")
+	if err != nil {
+		return skillsync.Decision{}, err
+	}
+	switch text {
+	case "y":
+		return skillsync.Decision{Apply: true}, nil
+	case "a":
+		return skillsync.Decision{Apply: true, ApplyAll: true}, nil
+	default:
+		c.console.Info("Skipping.")
+		return skillsync.Decision{}, nil
+	}
+}
+
 type consoleReporter struct {
 	writer *console.Writer
 }
@@ -306,20 +713,3 @@ func (r consoleReporter) Successf(format string, args ...any) {
 		r.writer.Success(format, args...)
 	}
 }
-
-func (c *PushCommand) projectSlug(projectIDN string, data state.ProjectData) string {
-	slug := strings.TrimSpace(data.Path)
-	if slug != "" {
-		return slug
-	}
-
-	base := strings.TrimSpace(projectIDN)
-	if base == "" {
-		base = "project"
-	}
-
-	if c.slugPrefix != "" {
-		return c.slugPrefix + strings.ToLower(base)
-	}
-	return strings.ToLower(base)
-}
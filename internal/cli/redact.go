@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/redact"
+)
+
+// configureRedaction installs the [redact] patterns from newo.toml on top of the built-in secret filters.
+// A missing or unparsable newo.toml leaves only the built-ins active; the command itself reports parse errors.
+func configureRedaction() error {
+	var cfg config.TomlConfig
+	data, err := os.ReadFile(config.TomlPath())
+	if err == nil {
+		_, _ = toml.Decode(string(data), &cfg)
+	}
+	if err := redact.Configure(cfg.Redact.Patterns); err != nil {
+		return fmt.Errorf("%s [redact]: %w", config.TomlPath(), err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// sedContextLines is the number of unchanged lines shown around each replacement in the preview.
+const sedContextLines = 3
+
+// SedCommand replaces a regular expression in the skill scripts of the workspace.
+type SedCommand struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	console    *console.Writer
+	customer   *string
+	project    *string
+	flow       *string
+	skill      *string
+	ignoreCase *bool
+	fixed      *bool
+	force      *bool
+	push       *bool
+
+	prompts
+	// pushCmdFactory builds the push run by --push; tests replace it.
+	pushCmdFactory func(stdout, stderr io.Writer) Command
+}
+
+// NewSedCommand constructs a sed command.
+func NewSedCommand(stdout, stderr io.Writer) *SedCommand {
+	return &SedCommand{
+		stdout:         stdout,
+		stderr:         stderr,
+		console:        console.New(stdout, stderr),
+		pushCmdFactory: func(stdout, stderr io.Writer) Command { return NewPushCommand(stdout, stderr) },
+	}
+}
+
+func (c *SedCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *SedCommand) Name() string {
+	return "sed"
+}
+
+func (c *SedCommand) Summary() string {
+	return "Replace a regular expression across skill scripts"
+}
+
+func (c *SedCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to edit")
+	c.project = fs.String("project", "", "only edit skills of this project IDN")
+	c.flow = fs.String("flow", "", "only edit skills of this flow IDN")
+	c.skill = fs.String("skill", "", "only edit the skill with this IDN")
+	c.ignoreCase = fs.Bool("ignore-case", false, "match letters regardless of case")
+	c.fixed = fs.Bool("fixed", false, "treat the pattern and replacement as literal text")
+	c.force = fs.Bool("force", false, "apply every replacement without asking")
+	c.push = fs.Bool("push", false, "push the edited customers afterwards")
+}
+
+func (c *SedCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo sed <pattern> <replacement> [--customer <idn>] [--project <idn>] [--flow <idn>] [--skill <idn>] [--ignore-case] [--fixed] [--force] [--push]"
+	if len(args) != 2 || args[0] == "" {
+		return errors.New(usage)
+	}
+	fixed := c.fixed != nil && *c.fixed
+	pattern, err := grepPattern(args[0], fixed, c.ignoreCase != nil && *c.ignoreCase)
+	if err != nil {
+		return err
+	}
+	replace := func(content string) string {
+		if fixed {
+			return pattern.ReplaceAllLiteralString(content, args[1])
+		}
+		return pattern.ReplaceAllString(content, args[1])
+	}
+	filter := grepFilter{project: flagValue(c.project), flow: flagValue(c.flow), skill: flagValue(c.skill)}
+	customerFilter := flagValue(c.customer)
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	matched := false
+	processed := map[string]bool{}
+	applyAll := c.force != nil && *c.force
+	var edited []string
+	total := 0
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+			continue
+		}
+		matched = true
+		if processed[strings.ToLower(idn)] {
+			continue
+		}
+		processed[strings.ToLower(idn)] = true
+
+		count := 0
+		err := withCustomerLock(c.console, idn, "sed", false, func() error {
+			scripts, err := workspaceScripts(env.OutputRoot, entry.Type, idn, filter)
+			if err != nil {
+				return err
+			}
+			count, err = c.replaceScripts(idn, scripts, pattern, replace, &applyAll)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			edited = append(edited, idn)
+			total += count
+		}
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	if total == 0 {
+		c.console.Info("No skills changed.")
+		return nil
+	}
+	c.console.Success("Edited %d skill(s). Run `newo push` to upload them.", total)
+	if c.push == nil || !*c.push {
+		return nil
+	}
+	for _, idn := range edited {
+		if err := c.runPush(ctx, idn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceScripts previews and writes the replacements in one customer's scripts and returns how many
+// scripts it changed. The hashes of the last pull are left alone, so push sees the edits as local
+// changes; the file hash cache learns the new digests so the push does not read the files again.
+func (c *SedCommand) replaceScripts(customerIDN string, scripts []workspaceScript, pattern *regexp.Regexp, replace func(string) string, applyAll *bool) (int, error) {
+	hashCache, err := state.LoadFileHashCache(customerIDN, false)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, script := range scripts {
+		content, err := os.ReadFile(script.Path)
+		if err != nil {
+			return changed, fmt.Errorf("read %s: %w", script.Path, err)
+		}
+		if !pattern.Match(content) {
+			continue
+		}
+		updated := []byte(replace(string(content)))
+		if string(updated) == string(content) {
+			continue
+		}
+
+		path := filepath.ToSlash(script.Path)
+		pageDiff(c.stdout, c.console, diff.Format(path, diff.Generate(content, updated, sedContextLines)))
+		if !*applyAll {
+			text, err := c.prompt().Confirm(c.console, fmt.Sprintf("Apply to %s? [y/N/a]: ", path))
+			if err != nil {
+				return changed, err
+			}
+			switch text {
+			case "y":
+			case "a":
+				*applyAll = true
+			default:
+				c.console.Info("Skipping.")
+				continue
+			}
+		}
+
+		if err := writeFile(script.Path, updated); err != nil {
+			return changed, fmt.Errorf("write %s: %w", script.Path, err)
+		}
+		hashCache.Record(script.Path, util.ContentHash(updated))
+		changed++
+	}
+	return changed, hashCache.Save()
+}
+
+// runPush pushes one customer with the same prompter, so its confirmations are answered the same way.
+func (c *SedCommand) runPush(ctx context.Context, customerIDN string) error {
+	pushCmd := c.pushCmdFactory(c.stdout, c.stderr)
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	pushCmd.RegisterFlags(fs)
+	if prompted, ok := pushCmd.(promptedCommand); ok {
+		prompted.SetPrompter(c.prompt())
+	}
+	_ = fs.Set("customer", customerIDN)
+
+	c.console.Section(fmt.Sprintf("Push %s", strings.ToUpper(customerIDN)))
+	if err := pushCmd.Run(ctx, nil); err != nil {
+		return fmt.Errorf("push %s: %w", customerIDN, err)
+	}
+	return nil
+}
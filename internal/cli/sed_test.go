@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+func TestSedCommandReplacesConfirmedScriptsAndPushes(t *testing.T) {
+	toml := "[defaults]\noutput_root = \".\"\n" + buildCustomersToml(tomlCustomer{idn: "acme", apiKey: "acme-key", customerType: "integration"})
+	t.Chdir(createTempNewoToml(t, toml))
+
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"shop": {ProjectID: "p1", Path: "shop", Agents: map[string]state.AgentData{
+			"bot": {ID: "a1", Flows: map[string]state.FlowData{"main": {ID: "f1"}}},
+		}},
+	}}
+	if err := state.SaveProjectMap("acme", projectMap); err != nil {
+		t.Fatalf("save project map: %v", err)
+	}
+	hashes := state.HashStore{"pulled.nsl": "pulled"}
+	if err := state.SaveHashes("acme", hashes); err != nil {
+		t.Fatalf("save hashes: %v", err)
+	}
+	greeting := fsutil.ExportSkillScriptPath(".", "integration", "acme", "shop", "bot", "main", "Greeting.nsl")
+	farewell := fsutil.ExportSkillScriptPath(".", "integration", "acme", "shop", "bot", "main", "Farewell.nsl")
+	writeTestFile(t, greeting, "{{ get_state(\"user_name\") }} {{ user_name }}")
+	writeTestFile(t, farewell, "Bye {{ user_name }}")
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewSedCommand(&stdout, &stderr)
+	var pushed []string
+	cmd.pushCmdFactory = func(stdout, stderr io.Writer) Command {
+		var customer *string
+		return &MockCommand{
+			name:          "push",
+			registerFlags: func(fs *flag.FlagSet) { customer = fs.String("customer", "", "") },
+			run: func(context.Context, []string) error {
+				pushed = append(pushed, *customer)
+				return nil
+			},
+		}
+	}
+	fs := flag.NewFlagSet("sed", flag.ContinueOnError)
+	cmd.RegisterFlags(fs)
+	if err := fs.Parse([]string{"--push", `\buser_name\b`, "customer_name"}); err != nil {
+		t.Fatal(err)
+	}
+	// Farewell is listed first and declined; Greeting is applied.
+	cmd.SetPrompter(NewPrompter(strings.NewReader("n\ny\n")))
+
+	if err := cmd.Run(context.Background(), fs.Args()); err != nil {
+		t.Fatalf("sed: %v\n%s", err, stderr.String())
+	}
+	if content, _ := os.ReadFile(greeting); string(content) != "{{ get_state(\"customer_name\") }} {{ customer_name }}" {
+		t.Fatalf("expected Greeting to be rewritten, got %q", content)
+	}
+	if content, _ := os.ReadFile(farewell); string(content) != "Bye {{ user_name }}" {
+		t.Fatalf("expected the declined Farewell to stay, got %q", content)
+	}
+	if saved, err := state.LoadHashes("acme"); err != nil || len(saved) != 1 || saved["pulled.nsl"] != "pulled" {
+		t.Fatalf("expected the pull hashes to stay, got %v (%v)", saved, err)
+	}
+	if len(pushed) != 1 || pushed[0] != "acme" {
+		t.Fatalf("expected one push of acme, got %v", pushed)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 
@@ -13,32 +12,30 @@ import (
 	"github.com/twinmind/newo-tool/internal/config"
 	"github.com/twinmind/newo-tool/internal/fsutil"
 	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
 )
 
-type tomlConfig struct {
-	Defaults struct {
-		OutputRoot *string `toml:"output_root"`
-	} `toml:"defaults"`
-}
-
 // getOutputRoot returns the configured output root for customer data.
 func getOutputRoot() (string, error) {
 	if root := strings.TrimSpace(os.Getenv("NEWO_OUTPUT_ROOT")); root != "" {
 		return root, nil
 	}
 
-	path := filepath.Join(".", config.DefaultTomlPath)
+	path := config.TomlPath()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fsutil.DefaultCustomersDir, nil
 		}
-		return "", fmt.Errorf("read %s: %w", config.DefaultTomlPath, err)
+		return "", fmt.Errorf("read %s: %w", path, err)
 	}
 
-	var cfg tomlConfig
+	var cfg config.TomlConfig
 	if err := toml.Unmarshal(data, &cfg); err != nil {
-		return "", fmt.Errorf("parse %s: %w", config.DefaultTomlPath, err)
+		return "", fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := cfg.ApplyProfile(config.ActiveProfile()); err != nil {
+		return "", err
 	}
 
 	if cfg.Defaults.OutputRoot != nil {
@@ -80,18 +77,9 @@ type customerDefinition struct {
 }
 
 func loadCustomerDefinition(token string) (*customerDefinition, error) {
-	path := filepath.Join(".", config.DefaultTomlPath)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("read %s: %w", config.DefaultTomlPath, err)
-	}
-
-	var cfg config.TomlConfig
-	if _, err := toml.Decode(string(data), &cfg); err != nil {
-		return nil, fmt.Errorf("parse %s: %w", config.DefaultTomlPath, err)
+	cfg, err := loadCustomerToml()
+	if err != nil || cfg == nil {
+		return nil, err
 	}
 
 	token = strings.TrimSpace(token)
@@ -122,6 +110,40 @@ func loadCustomerDefinition(token string) (*customerDefinition, error) {
 	return nil, nil
 }
 
+// loadCustomerDefinitions returns every customer configured in newo.toml, or none without one.
+func loadCustomerDefinitions() ([]customerDefinition, error) {
+	cfg, err := loadCustomerToml()
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	definitions := make([]customerDefinition, 0, len(cfg.Customers))
+	for _, c := range cfg.Customers {
+		definitions = append(definitions, customerDefinition{IDN: strings.TrimSpace(c.IDN), Alias: strings.TrimSpace(c.Alias), Type: strings.TrimSpace(c.Type)})
+	}
+	return definitions, nil
+}
+
+// loadCustomerToml reads newo.toml with the active profile applied. It returns nil when the file is missing.
+func loadCustomerToml() (*config.TomlConfig, error) {
+	path := config.TomlPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var cfg config.TomlConfig
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := cfg.ApplyProfile(config.ActiveProfile()); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
 func customerProjectDirectories(outputRoot string, definition *customerDefinition) ([]string, bool, error) {
 	projectMap, err := state.LoadProjectMap(definition.IDN)
 	if err != nil {
@@ -157,3 +179,34 @@ func projectSlugFromState(projectIDN string, data state.ProjectData) string {
 	}
 	return strings.ToLower(base)
 }
+
+// writeTable prints rows as left-aligned columns under the given header.
+func writeTable(w *console.Writer, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for _, line := range append([][]string{header}, rows...) {
+		for i, cell := range line {
+			if i < len(widths) && len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	w.RawLine("%s", formatTableLine(header, widths))
+	for _, line := range rows {
+		w.RawLine("%s", formatTableLine(line, widths))
+	}
+}
+
+func formatTableLine(cells []string, widths []int) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		parts[i] = fmt.Sprintf("%-*s", widths[i], cell)
+	}
+	return strings.TrimRight(strings.Join(parts, "  "), " ")
+}
+
+func orDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
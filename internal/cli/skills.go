@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// Local status values reported for each skill script.
+const (
+	skillStatusClean     = "clean"
+	skillStatusModified  = "modified"
+	skillStatusMissing   = "missing"
+	skillStatusUntracked = "untracked"
+)
+
+// SkillsCommand lists the skills of pulled projects together with their remote metadata.
+type SkillsCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	project  *string
+	format   *string
+}
+
+// skillRow is a single line of the skills listing.
+type skillRow struct {
+	Customer    string `json:"customer"`
+	Project     string `json:"project"`
+	Agent       string `json:"agent"`
+	Flow        string `json:"flow"`
+	IDN         string `json:"idn"`
+	Title       string `json:"title"`
+	RunnerType  string `json:"runner_type"`
+	Model       string `json:"model"`
+	UpdatedAt   string `json:"updated_at"`
+	Path        string `json:"path"`
+	LocalStatus string `json:"local_status"`
+	Remote      bool   `json:"remote"`
+}
+
+// NewSkillsCommand constructs a skills command.
+func NewSkillsCommand(stdout, stderr io.Writer) *SkillsCommand {
+	return &SkillsCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *SkillsCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *SkillsCommand) Name() string {
+	return "skills"
+}
+
+func (c *SkillsCommand) Summary() string {
+	return "List skills with runner type, model, last update, and local status"
+}
+
+func (c *SkillsCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to list")
+	c.project = fs.String("project", "", "only list skills of this project IDN")
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *SkillsCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo skills list [--customer <idn>] [--project <idn>] [--format text|json]"
+	if len(args) != 1 || args[0] != "list" {
+		return errors.New(usage)
+	}
+
+	format, err := listFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+	customerFilter := ""
+	if c.customer != nil {
+		customerFilter = strings.TrimSpace(*c.customer)
+	}
+	projectFilter := ""
+	if c.project != nil {
+		projectFilter = strings.TrimSpace(*c.project)
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	rows := []skillRow{}
+	registryDirty := false
+	matched := false
+	processed := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		session, err := session.New(ctx, env, entry, registry)
+		if err != nil {
+			return err
+		}
+		if session.RegistryUpdated {
+			registryDirty = true
+		}
+		if customerFilter != "" && !matchesCustomerToken(entry, session.IDN, customerFilter) {
+			continue
+		}
+		matched = true
+		key := strings.ToLower(session.IDN)
+		if processed[key] {
+			continue
+		}
+		processed[key] = true
+
+		customerRows, err := c.collect(ctx, session, env.OutputRoot, projectFilter)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, customerRows...)
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	if registryDirty {
+		if err := registry.Save(); err != nil {
+			return err
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(rows); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+	c.printTable(rows)
+	return nil
+}
+
+// collect combines the project map of a customer with the skills returned by the platform.
+func (c *SkillsCommand) collect(ctx context.Context, session *session.Session, outputRoot, projectFilter string) ([]skillRow, error) {
+	projectMap, err := state.LoadProjectMap(session.IDN)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := state.LoadHashes(session.IDN)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []skillRow
+	for projectIDN, projectData := range projectMap.Projects {
+		if projectFilter != "" && !strings.EqualFold(projectIDN, projectFilter) {
+			continue
+		}
+		slug := projectSlugFromState(projectIDN, projectData)
+		for agentIDN, agentData := range projectData.Agents {
+			for flowIDN, flowData := range agentData.Flows {
+				var remoteSkills []platform.Skill
+				if strings.TrimSpace(flowData.ID) != "" {
+					remoteSkills, err = session.Client.ListFlowSkills(ctx, flowData.ID)
+					if err != nil {
+						return nil, fmt.Errorf("list skills for %s/%s: %w", projectIDN, flowIDN, err)
+					}
+				}
+
+				base := skillRow{Customer: session.IDN, Project: projectIDN, Agent: agentIDN, Flow: flowIDN}
+				seen := map[string]bool{}
+				for _, skill := range remoteSkills {
+					seen[skill.IDN] = true
+					row := base
+					row.IDN = skill.IDN
+					row.Title = skill.Title
+					row.RunnerType = skill.RunnerType
+					row.Model = formatModel(skill.Model.ProviderIDN, skill.Model.ModelIDN)
+					row.UpdatedAt = skill.UpdatedAt
+					row.Remote = true
+					row.Path, row.LocalStatus = c.localStatus(outputRoot, session, slug, agentIDN, flowIDN, skill.IDN, skill.RunnerType, hashes)
+					rows = append(rows, row)
+				}
+				for skillIDN, meta := range flowData.Skills {
+					if seen[skillIDN] {
+						continue
+					}
+					row := base
+					row.IDN = skillIDN
+					row.Title = meta.Title
+					row.RunnerType = meta.RunnerType
+					row.Model = formatModel(meta.Model["provider_idn"], meta.Model["model_idn"])
+					row.UpdatedAt = meta.UpdatedAt
+					row.Path, row.LocalStatus = c.localStatus(outputRoot, session, slug, agentIDN, flowIDN, skillIDN, meta.RunnerType, hashes)
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Agent != b.Agent {
+			return a.Agent < b.Agent
+		}
+		if a.Flow != b.Flow {
+			return a.Flow < b.Flow
+		}
+		return a.IDN < b.IDN
+	})
+	return rows, nil
+}
+
+func (c *SkillsCommand) localStatus(outputRoot string, session *session.Session, slug, agentIDN, flowIDN, skillIDN, runnerType string, hashes state.HashStore) (string, string) {
+	fileName := skillIDN + "." + platform.ScriptExtension(runnerType)
+	path := filepath.ToSlash(fsutil.ExportSkillScriptPath(outputRoot, session.CustomerType, session.IDN, slug, agentIDN, flowIDN, fileName))
+	return path, skillScriptStatus(path, hashes)
+}
+
+// skillScriptStatus compares a script on disk with the hash recorded at the last pull or push.
+func skillScriptStatus(path string, hashes state.HashStore) string {
+	content, err := os.ReadFile(filepath.FromSlash(path))
+	if err != nil {
+		return skillStatusMissing
+	}
+	stored, tracked := hashes[path]
+	if !tracked {
+		return skillStatusUntracked
+	}
+	if util.ContentHash(content) != stored {
+		return skillStatusModified
+	}
+	return skillStatusClean
+}
+
+func formatModel(provider, model string) string {
+	provider = strings.TrimSpace(provider)
+	model = strings.TrimSpace(model)
+	switch {
+	case provider == "" && model == "":
+		return "-"
+	case provider == "":
+		return model
+	case model == "":
+		return provider
+	}
+	return provider + "/" + model
+}
+
+func (c *SkillsCommand) printTable(rows []skillRow) {
+	if len(rows) == 0 {
+		c.console.Info("No skills found. Run `newo pull` first.")
+		return
+	}
+
+	header := []string{"PROJECT", "FLOW", "SKILL", "TITLE", "RUNNER", "MODEL", "UPDATED", "LOCAL"}
+	var table [][]string
+	for idx, row := range rows {
+		if idx == 0 || rows[idx-1].Customer != row.Customer {
+			if table != nil {
+				writeTable(c.console, header, table)
+			}
+			table = [][]string{}
+			c.console.Section(fmt.Sprintf("Skills %s", strings.ToUpper(row.Customer)))
+		}
+		status := row.LocalStatus
+		if !row.Remote {
+			status += " (not on remote)"
+		}
+		table = append(table, []string{row.Project, row.Flow, row.IDN, row.Title, row.RunnerType, row.Model, orDash(row.UpdatedAt), status})
+	}
+	writeTable(c.console, header, table)
+}
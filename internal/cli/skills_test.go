@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+func TestSkillScriptStatus(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.ToSlash(filepath.Join(dir, "skill.nsl"))
+	if err := os.WriteFile(path, []byte("{{ greeting }}"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	hashes := state.HashStore{}
+	if got := skillScriptStatus(path, hashes); got != skillStatusUntracked {
+		t.Fatalf("expected %q, got %q", skillStatusUntracked, got)
+	}
+
+	hashes[path] = util.SHA256String("{{ greeting }}")
+	if got := skillScriptStatus(path, hashes); got != skillStatusClean {
+		t.Fatalf("expected %q, got %q", skillStatusClean, got)
+	}
+
+	hashes[path] = util.SHA256String("{{ farewell }}")
+	if got := skillScriptStatus(path, hashes); got != skillStatusModified {
+		t.Fatalf("expected %q, got %q", skillStatusModified, got)
+	}
+
+	if got := skillScriptStatus(filepath.ToSlash(filepath.Join(dir, "gone.nsl")), hashes); got != skillStatusMissing {
+		t.Fatalf("expected %q, got %q", skillStatusMissing, got)
+	}
+}
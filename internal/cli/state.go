@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// StateCommand manages snapshots of the local .newo state directory.
+type StateCommand struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	console *console.Writer
+
+	customer *string
+}
+
+// NewStateCommand constructs a state command.
+func NewStateCommand(stdout, stderr io.Writer) *StateCommand {
+	return &StateCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *StateCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *StateCommand) Name() string {
+	return "state"
+}
+
+func (c *StateCommand) Summary() string {
+	return "Snapshot, list, and restore local state (map.json and file hashes)"
+}
+
+func (c *StateCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias (defaults to all customers with local state)")
+}
+
+func (c *StateCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	const usage = "usage: newo state <snapshot|list|restore <snapshot-id>> [--customer <idn>]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	customerFlag := ""
+	if c.customer != nil {
+		customerFlag = strings.TrimSpace(*c.customer)
+	}
+
+	switch args[0] {
+	case "snapshot":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		customers, err := resolveStateCustomers(customerFlag)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, idn := range customers {
+			snapshot, err := state.CreateSnapshot(idn, now)
+			if err != nil {
+				return err
+			}
+			c.console.Success("Snapshot %s created for %s (%s)", snapshot.ID, idn, strings.Join(snapshot.Files, ", "))
+		}
+		return nil
+	case "list":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		customers, err := resolveStateCustomers(customerFlag)
+		if err != nil {
+			return err
+		}
+		for _, idn := range customers {
+			snapshots, err := state.ListSnapshots(idn)
+			if err != nil {
+				return err
+			}
+			c.console.Section(fmt.Sprintf("Snapshots %s", strings.ToUpper(idn)))
+			if len(snapshots) == 0 {
+				c.console.Info("No snapshots. Run `newo state snapshot` to create one.")
+				continue
+			}
+			items := make([]string, 0, len(snapshots))
+			for _, snapshot := range snapshots {
+				items = append(items, fmt.Sprintf("%s  %s  %s", snapshot.ID, snapshot.CreatedAt.Local().Format(time.RFC3339), strings.Join(snapshot.Files, ", ")))
+			}
+			c.console.List(items)
+		}
+		return nil
+	case "restore":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		customers, err := resolveStateCustomers(customerFlag)
+		if err != nil {
+			return err
+		}
+		if len(customers) != 1 {
+			return fmt.Errorf("multiple customers have local state (%s); specify one with --customer", strings.Join(customers, ", "))
+		}
+		return c.restore(customers[0], strings.TrimSpace(args[1]))
+	default:
+		return fmt.Errorf("unknown state subcommand %q; %s", args[0], usage)
+	}
+}
+
+func (c *StateCommand) restore(customerIDN, snapshotID string) error {
+	release, err := fsutil.AcquireLock(customerIDN, "state restore")
+	if err != nil {
+		return lockError(err)
+	}
+	defer func() {
+		_ = release()
+	}()
+
+	// Keep the current state so an accidental restore can itself be undone.
+	backup, err := state.CreateSnapshot(customerIDN, time.Now())
+	if err == nil {
+		c.console.Info("Current state saved as snapshot %s", backup.ID)
+	}
+
+	snapshot, err := state.RestoreSnapshot(customerIDN, snapshotID)
+	if err != nil {
+		return err
+	}
+	c.console.Success("Restored %s for %s from snapshot %s", strings.Join(snapshot.Files, ", "), customerIDN, snapshot.ID)
+	return nil
+}
+
+// resolveStateCustomers returns the customer IDNs addressed by the --customer flag,
+// or every customer with local state when the flag is empty.
+func resolveStateCustomers(token string) ([]string, error) {
+	if token != "" {
+		definition, err := loadCustomerDefinition(token)
+		if err != nil {
+			return nil, err
+		}
+		if definition != nil {
+			return []string{definition.IDN}, nil
+		}
+		if _, err := os.Stat(fsutil.CustomerStateDir(token)); err != nil {
+			return nil, fmt.Errorf("customer %s not configured or has no local state", token)
+		}
+		return []string{token}, nil
+	}
+
+	customers, err := listCustomersWithState()
+	if err != nil {
+		return nil, err
+	}
+	if len(customers) == 0 {
+		return nil, fmt.Errorf("no customers with local state; run `newo pull` first")
+	}
+	return customers, nil
+}
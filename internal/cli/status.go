@@ -25,6 +25,7 @@ type StatusCommand struct {
 	console  *console.Writer
 	verbose  *bool
 	customer *string
+	group    *string
 }
 
 // NewStatusCommand constructs a status command.
@@ -53,6 +54,7 @@ func (c *StatusCommand) Summary() string {
 func (c *StatusCommand) RegisterFlags(fs *flag.FlagSet) {
 	c.verbose = fs.Bool("verbose", false, "show detailed information")
 	c.customer = fs.String("customer", "", "customer IDN to inspect")
+	c.group = fs.String("group", "", "inspect every customer in this newo.toml group")
 }
 
 func (c *StatusCommand) Run(ctx context.Context, _ []string) error {
@@ -72,6 +74,11 @@ func (c *StatusCommand) Run(ctx context.Context, _ []string) error {
 	if err != nil {
 		return err
 	}
+	group := flagValue(c.group)
+	entries, err := groupEntries(cfg, group, customerFlag)
+	if err != nil {
+		return err
+	}
 
 	registry, err := state.LoadAPIKeyRegistry()
 	if err != nil {
@@ -124,12 +131,26 @@ func (c *StatusCommand) Run(ctx context.Context, _ []string) error {
 		return err
 	}
 
-	for _, idn := range customers {
-		targetList = append(targetList, idn)
+	if group != "" {
+		seen := map[string]bool{}
+		for _, entry := range entries {
+			idn := strings.TrimSpace(entry.HintIDN)
+			if idn == "" {
+				idn, _ = registry.Lookup(entry.APIKey)
+			}
+			if idn != "" && !seen[strings.ToLower(idn)] {
+				seen[strings.ToLower(idn)] = true
+				targetList = append(targetList, idn)
+			}
+		}
+	} else {
+		for _, idn := range customers {
+			targetList = append(targetList, idn)
+		}
 	}
 	sort.Strings(targetList)
 
-	if len(targetList) == 0 && cfg.DefaultCustomer != "" {
+	if len(targetList) == 0 && group == "" && cfg.DefaultCustomer != "" {
 		targetList = append(targetList, cfg.DefaultCustomer)
 	}
 
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/metrics"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// runTally collects the per-customer rows of the summary table pull and push print when they finish.
+// Each row holds how far the named metrics counters moved while the customer was synced, followed by
+// the duration and the number of API calls.
+type runTally struct {
+	title   string
+	columns []tallyColumn
+	rows    [][]string
+}
+
+// tallyColumn names the metrics counter shown under a table heading.
+type tallyColumn struct {
+	header  string
+	counter string
+}
+
+func newRunTally(title string, columns ...tallyColumn) *runTally {
+	return &runTally{title: title, columns: columns}
+}
+
+// measure runs fn for one customer and records its row, also when fn fails.
+func (t *runTally) measure(customerIDN string, fn func() error) error {
+	before := metrics.Snapshot(nil)
+	started := time.Now()
+	err := fn()
+	after := metrics.Snapshot(nil)
+
+	row := []string{customerIDN}
+	for _, column := range t.columns {
+		row = append(row, strconv.FormatInt(after.Counters[column.counter]-before.Counters[column.counter], 10))
+	}
+	row = append(row, time.Since(started).Round(time.Millisecond).String(), strconv.FormatInt(after.APICalls-before.APICalls, 10))
+	t.rows = append(t.rows, row)
+	return err
+}
+
+// print writes the table, or nothing when no customer was synced.
+func (t *runTally) print(w *console.Writer) {
+	if t == nil || len(t.rows) == 0 {
+		return
+	}
+	header := []string{"CUSTOMER"}
+	for _, column := range t.columns {
+		header = append(header, column.header)
+	}
+	w.Section(t.title)
+	writeTable(w, append(header, "DURATION", "API CALLS"), t.rows)
+}
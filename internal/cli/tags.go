@@ -0,0 +1,16 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+// scriptTagged reports whether the .meta.yaml next to a skill script lists one of tags.
+func scriptTagged(scriptPath string, tags []string) bool {
+	metaPath := strings.TrimSuffix(scriptPath, filepath.Ext(scriptPath)) + fsutil.SkillMetaFileExt
+	skillTags, err := state.LoadSkillTags(metaPath)
+	return err == nil && state.HasAnyTag(skillTags, tags)
+}
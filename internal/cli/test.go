@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/nsl/evaluator"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/skilltest"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+)
+
+// TestCommand runs the skill test suites stored in the tests/ directory of each project.
+type TestCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	project  *string
+	remote   *bool
+	update   *bool
+	verbose  *bool
+	tag      *string
+}
+
+// testSkill is a skill referenced by a suite, resolved against the project map.
+type testSkill struct {
+	ID         string
+	IDN        string
+	RunnerType string
+	ScriptPath string
+}
+
+// NewTestCommand constructs a test command.
+func NewTestCommand(stdout, stderr io.Writer) *TestCommand {
+	return &TestCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *TestCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *TestCommand) Name() string {
+	return "test"
+}
+
+func (c *TestCommand) Summary() string {
+	return "Run skill test cases from each project's tests/ directory"
+}
+
+func (c *TestCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to test")
+	c.project = fs.String("project", "", "only run the tests of this project IDN")
+	c.remote = fs.Bool("remote", false, "run every case on the platform instead of rendering locally")
+	c.update = fs.Bool("update", false, "record rendered outputs as the new golden files")
+	c.verbose = fs.Bool("verbose", false, "print the output of passing cases too")
+	c.tag = fs.String("tag", "", "only run suites of skills with one of these comma-separated tags in their .meta.yaml")
+}
+
+func (c *TestCommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 0 {
+		return errors.New("usage: newo test [--customer <idn>] [--project <idn>] [--remote | --update] [--verbose]")
+	}
+	customerFilter := flagValue(c.customer)
+	projectFilter := flagValue(c.project)
+	remote := c.remote != nil && *c.remote
+	update := c.update != nil && *c.update
+	if remote && update {
+		return errors.New("--update records local renders and cannot be combined with --remote")
+	}
+	// Golden files hold local renders; remote output is not deterministic enough to snapshot.
+	opts := skilltest.Options{Snapshots: !remote, UpdateSnapshots: update}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	var results []skilltest.Result
+	matched := false
+	processed := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		// Local rendering needs no login, so sessions are only opened for suites that run remotely.
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+			continue
+		}
+		matched = true
+		if processed[strings.ToLower(idn)] {
+			continue
+		}
+		processed[strings.ToLower(idn)] = true
+
+		projectMap, err := state.LoadProjectMap(idn)
+		if err != nil {
+			return err
+		}
+		projectIDNs := make([]string, 0, len(projectMap.Projects))
+		for projectIDN := range projectMap.Projects {
+			if projectFilter == "" || strings.EqualFold(projectIDN, projectFilter) {
+				projectIDNs = append(projectIDNs, projectIDN)
+			}
+		}
+		sort.Strings(projectIDNs)
+
+		var sess *session.Session
+		for _, projectIDN := range projectIDNs {
+			projectData := projectMap.Projects[projectIDN]
+			slug := projectSlugFromState(projectIDN, projectData)
+			suites, err := skilltest.LoadSuites(fsutil.ExportTestsDir(env.OutputRoot, entry.Type, idn, slug))
+			if err != nil {
+				return err
+			}
+			if tags := splitRuleList(c.tag); len(tags) > 0 {
+				suites = slices.DeleteFunc(suites, func(suite skilltest.Suite) bool {
+					skill, err := resolveTestSkill(env.OutputRoot, entry.Type, idn, slug, projectData, suite.Skill)
+					return err != nil || !scriptTagged(skill.ScriptPath, tags)
+				})
+			}
+			if len(suites) == 0 {
+				continue
+			}
+
+			c.console.Section(fmt.Sprintf("Tests %s/%s", strings.ToUpper(idn), projectIDN))
+			execute := func(ctx context.Context, suite skilltest.Suite, tc skilltest.Case) (string, error) {
+				skill, err := resolveTestSkill(env.OutputRoot, entry.Type, idn, slug, projectData, suite.Skill)
+				if err != nil {
+					return "", err
+				}
+				if suite.Mode == skilltest.ModeRender && !remote {
+					return renderSkill(skill, tc)
+				}
+				if sess == nil {
+					if sess, err = session.New(ctx, env, entry, registry); err != nil {
+						return "", err
+					}
+					if sess.RegistryUpdated {
+						if err := registry.Save(); err != nil {
+							return "", err
+						}
+					}
+				}
+				return executeSkill(ctx, sess.Client, skill, tc)
+			}
+			projectResults := skilltest.Run(ctx, suites, execute, opts)
+			c.printResults(projectResults)
+			results = append(results, projectResults...)
+		}
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+
+	if len(results) == 0 {
+		c.console.Info("No test cases found. Add YAML suites to a project's %s/ directory.", fsutil.TestsDir)
+		return nil
+	}
+	failed, updated := 0, 0
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+		if result.Updated {
+			updated++
+		}
+	}
+	if updated > 0 {
+		c.console.Info("Updated %d golden file(s).", updated)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test case(s) failed", failed, len(results))
+	}
+	c.console.Success("All %d test case(s) passed.", len(results))
+	return nil
+}
+
+func (c *TestCommand) printResults(results []skilltest.Result) {
+	verbose := c.verbose != nil && *c.verbose
+	for _, result := range results {
+		label := fmt.Sprintf("%s › %s (%s)", result.Skill, result.Case, result.Duration.Round(time.Millisecond))
+		if result.Passed() {
+			c.console.RawLine("PASS %s", label)
+		} else {
+			c.console.RawLine("FAIL %s", label)
+			c.console.List(result.Failures)
+		}
+		if result.Updated {
+			c.console.RawLine("    recorded %s", result.Golden)
+		}
+		switch {
+		case len(result.Diff) > 0:
+			c.console.Write(diff.Format(result.Golden, result.Diff))
+		case result.Output != "" && (verbose || !result.Passed()):
+			c.console.RawLine("    output:")
+			c.console.RawLine("%s", indentOutput(result.Output))
+		}
+	}
+}
+
+func indentOutput(output string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "      " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolveTestSkill finds the skill a suite refers to. The reference is either "flow/skill" or a bare
+// skill IDN, which must then be unique in the project.
+func resolveTestSkill(outputRoot, customerType, customerIDN, slug string, projectData state.ProjectData, ref string) (testSkill, error) {
+	flowRef, skillRef, scoped := strings.Cut(ref, "/")
+	if !scoped {
+		skillRef, flowRef = flowRef, ""
+	}
+
+	var found []testSkill
+	for agentIDN, agentData := range projectData.Agents {
+		for flowIDN, flowData := range agentData.Flows {
+			if scoped && !strings.EqualFold(flowIDN, flowRef) {
+				continue
+			}
+			for skillIDN, meta := range flowData.Skills {
+				if !strings.EqualFold(skillIDN, skillRef) {
+					continue
+				}
+				fileName := skillIDN + "." + platform.ScriptExtension(meta.RunnerType)
+				found = append(found, testSkill{
+					ID:         meta.ID,
+					IDN:        skillIDN,
+					RunnerType: meta.RunnerType,
+					ScriptPath: fsutil.ExportSkillScriptPath(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN, fileName),
+				})
+			}
+		}
+	}
+	switch len(found) {
+	case 0:
+		return testSkill{}, fmt.Errorf("skill %s not found in the project map; run `newo pull`", ref)
+	case 1:
+		return found[0], nil
+	}
+	return testSkill{}, fmt.Errorf("skill %s exists in several flows; use flow/skill", ref)
+}
+
+func renderSkill(skill testSkill, tc skilltest.Case) (string, error) {
+	if platform.ScriptExtension(skill.RunnerType) != "nsl" {
+		return "", fmt.Errorf("skill %s uses the %s runner; only NSL skills render locally (set mode: exec)", skill.IDN, skill.RunnerType)
+	}
+	script, err := os.ReadFile(skill.ScriptPath)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", skill.ScriptPath, err)
+	}
+	// Included skills are looked up next to the skill itself, in the same flow directory.
+	flowDir := filepath.Dir(skill.ScriptPath)
+	load := func(idn string) (string, error) {
+		if strings.ContainsAny(idn, `/\`) {
+			return "", fmt.Errorf("invalid skill IDN %q", idn)
+		}
+		data, err := os.ReadFile(filepath.Join(flowDir, idn+".nsl"))
+		if err != nil {
+			return "", fmt.Errorf("skill %s not found in the flow", idn)
+		}
+		return string(data), nil
+	}
+	return evaluator.RenderStringWithLoader(string(script), tc.Variables(), load)
+}
+
+func executeSkill(ctx context.Context, client *platform.Client, skill testSkill, tc skilltest.Case) (string, error) {
+	if skill.ID == "" {
+		return "", fmt.Errorf("skill %s has no remote identifier; push it first", skill.IDN)
+	}
+	resp, err := client.ExecuteSkill(ctx, skill.ID, platform.ExecuteSkillRequest{Arguments: tc.Parameters, Context: tc.Context})
+	if err != nil {
+		return "", fmt.Errorf("execute %s: %w", skill.IDN, err)
+	}
+	if resp.Error != "" {
+		return resp.Result, fmt.Errorf("skill %s failed: %s", skill.IDN, resp.Error)
+	}
+	return resp.Result, nil
+}
@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/session"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/ui/tui"
+)
+
+// UICommand opens an interactive dashboard of customers, projects, flows, and skills.
+type UICommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+}
+
+// uiItem identifies what a dashboard row stands for. Empty fields mean the row is higher up the tree.
+type uiItem struct {
+	Entry    customer.Entry
+	Customer string
+	Project  string
+	Agent    string
+	Flow     string
+	Skill    string
+	SkillID  string
+	Path     string
+}
+
+// NewUICommand constructs a ui command.
+func NewUICommand(stdout, stderr io.Writer) *UICommand {
+	return &UICommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *UICommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *UICommand) Name() string {
+	return "ui"
+}
+
+func (c *UICommand) Summary() string {
+	return "Browse pulled projects with drift indicators and run pull, push, or diff"
+}
+
+func (c *UICommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "only show this customer IDN or alias")
+}
+
+func (c *UICommand) Run(ctx context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 0 {
+		return errors.New("usage: newo ui [--customer <idn>]")
+	}
+	customerFilter := flagValue(c.customer)
+
+	err := tui.Run(os.Stdin, c.stdout, tui.Options{
+		Title: "newo · " + config.TomlPath(),
+		Actions: []tui.Action{
+			{Key: 'p', Label: "pull"},
+			{Key: 'u', Label: "push"},
+			{Key: 'd', Label: "diff"},
+			{Key: 'r', Label: "refresh"},
+		},
+		Load: func() ([]*tui.Node, error) {
+			return loadDashboard(customerFilter)
+		},
+		Do: func(key rune, node *tui.Node) error {
+			item, _ := node.Value.(uiItem)
+			switch key {
+			case 'p':
+				args := []string{"--customer", item.Customer}
+				if item.Project != "" {
+					args = append(args, "--project-idn", item.Project)
+				}
+				return c.runCommand(ctx, NewPullCommand(c.stdout, c.stderr), args)
+			case 'u':
+				return c.runCommand(ctx, NewPushCommand(c.stdout, c.stderr), []string{"--customer", item.Customer})
+			case 'd':
+				return c.showDiff(ctx, node)
+			}
+			return nil
+		},
+	})
+	if errors.Is(err, tui.ErrNotTerminal) {
+		return fmt.Errorf("%w; use `newo status` and `newo skills list` in scripts", err)
+	}
+	return err
+}
+
+// runCommand runs another command in-process with the given flags, as if it had been typed on the command line.
+func (c *UICommand) runCommand(ctx context.Context, cmd Command, args []string) error {
+	fs := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	cmd.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c.console.Section(fmt.Sprintf("newo %s %s", cmd.Name(), strings.Join(args, " ")))
+	return cmd.Run(ctx, fs.Args())
+}
+
+// showDiff compares the local scripts of every drifted skill under the node with the remote versions,
+// in the direction a push would apply them.
+func (c *UICommand) showDiff(ctx context.Context, node *tui.Node) error {
+	var skills []uiItem
+	var collect func(n *tui.Node)
+	collect = func(n *tui.Node) {
+		if item, ok := n.Value.(uiItem); ok && item.Skill != "" && n.Drift {
+			skills = append(skills, item)
+		}
+		for _, child := range n.Children {
+			collect(child)
+		}
+	}
+	collect(node)
+	if len(skills) == 0 {
+		c.console.Info("No local changes under %s.", node.Label)
+		return nil
+	}
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+	sess, err := session.New(ctx, env, skills[0].Entry, registry)
+	if err != nil {
+		return err
+	}
+	if sess.RegistryUpdated {
+		if err := registry.Save(); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range skills {
+		local, err := os.ReadFile(filepath.FromSlash(item.Path))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("read %s: %w", item.Path, err)
+		}
+		if item.SkillID == "" {
+			c.console.Info("%s is not on the platform yet; push will create it.", item.Path)
+			continue
+		}
+		remote, err := sess.Client.GetSkill(ctx, item.SkillID)
+		if err != nil {
+			return fmt.Errorf("get skill %s: %w", item.Skill, err)
+		}
+		lines := diff.Generate([]byte(remote.PromptScript), local, 3)
+		if len(lines) == 0 {
+			c.console.Info("%s matches the platform.", item.Path)
+			continue
+		}
+		c.console.RawLine("%s", diff.Format(item.Path, lines))
+	}
+	return nil
+}
+
+// loadDashboard builds the dashboard tree from the local project maps and hashes, without contacting the platform.
+func loadDashboard(customerFilter string) ([]*tui.Node, error) {
+	env, err := config.LoadEnv()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*tui.Node
+	seen := map[string]bool{}
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || seen[strings.ToLower(idn)] || (customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter)) {
+			continue
+		}
+		seen[strings.ToLower(idn)] = true
+		projectMap, err := state.LoadProjectMap(idn)
+		if err != nil {
+			return nil, err
+		}
+		hashes, err := state.LoadHashes(idn)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, customerNode(entry, idn, env.OutputRoot, projectMap, hashes))
+	}
+	return nodes, nil
+}
+
+// customerNode builds the subtree of one customer. Parents report how many skills below them have drifted.
+func customerNode(entry customer.Entry, customerIDN, outputRoot string, projectMap state.ProjectMap, hashes state.HashStore) *tui.Node {
+	base := uiItem{Entry: entry, Customer: customerIDN}
+	root := &tui.Node{Label: customerIDN, Detail: entry.Alias, Value: base}
+
+	for _, projectIDN := range sortedKeys(projectMap.Projects) {
+		projectData := projectMap.Projects[projectIDN]
+		slug := projectSlugFromState(projectIDN, projectData)
+		projectItem := base
+		projectItem.Project = projectIDN
+		projectNode := &tui.Node{Label: projectIDN, Value: projectItem}
+
+		for _, agentIDN := range sortedKeys(projectData.Agents) {
+			agentData := projectData.Agents[agentIDN]
+			agentItem := projectItem
+			agentItem.Agent = agentIDN
+			agentNode := &tui.Node{Label: agentIDN, Value: agentItem}
+
+			for _, flowIDN := range sortedKeys(agentData.Flows) {
+				flowData := agentData.Flows[flowIDN]
+				flowItem := agentItem
+				flowItem.Flow = flowIDN
+				flowNode := &tui.Node{Label: flowIDN, Value: flowItem}
+
+				for _, skillIDN := range sortedKeys(flowData.Skills) {
+					skill := flowData.Skills[skillIDN]
+					fileName := skillIDN + "." + platform.ScriptExtension(skill.RunnerType)
+					path := filepath.ToSlash(fsutil.ExportSkillScriptPath(outputRoot, entry.Type, customerIDN, slug, agentIDN, flowIDN, fileName))
+					status := skillScriptStatus(path, hashes)
+					skillItem := flowItem
+					skillItem.Skill = skillIDN
+					skillItem.SkillID = skill.ID
+					skillItem.Path = path
+					flowNode.Children = append(flowNode.Children, &tui.Node{
+						Label:  skillIDN,
+						Detail: skill.Title,
+						Status: status,
+						Drift:  status != skillStatusClean,
+						Value:  skillItem,
+					})
+				}
+				agentNode.Children = append(agentNode.Children, summarize(flowNode))
+			}
+			projectNode.Children = append(projectNode.Children, summarize(agentNode))
+		}
+		root.Children = append(root.Children, summarize(projectNode))
+	}
+	if len(root.Children) == 0 {
+		root.Status = "not pulled"
+		return root
+	}
+	return summarize(root)
+}
+
+// summarize sets a parent's status from the number of drifted skills below it.
+func summarize(node *tui.Node) *tui.Node {
+	drifted := 0
+	var count func(n *tui.Node)
+	count = func(n *tui.Node) {
+		for _, child := range n.Children {
+			if len(child.Children) == 0 && child.Drift {
+				drifted++
+			}
+			count(child)
+		}
+	}
+	count(node)
+	node.Status = skillStatusClean
+	if drifted > 0 {
+		node.Status = fmt.Sprintf("%d changed", drifted)
+		node.Drift = true
+	}
+	return node
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
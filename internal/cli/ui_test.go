@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+func TestCustomerNodeReportsDrift(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	flowDir := filepath.Join("projects", "acme", "booking", "receptionist", "flows", "main")
+	if err := os.MkdirAll(flowDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	greet := filepath.ToSlash(filepath.Join(flowDir, "greet.nsl"))
+	farewell := filepath.ToSlash(filepath.Join(flowDir, "farewell.nsl"))
+	if err := os.WriteFile(greet, []byte("edited"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(farewell, []byte("bye"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	hashes := state.HashStore{
+		greet:    util.SHA256Bytes([]byte("original")),
+		farewell: util.SHA256Bytes([]byte("bye")),
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"booking": {Agents: map[string]state.AgentData{
+			"receptionist": {Flows: map[string]state.FlowData{
+				"main": {Skills: map[string]state.SkillMetadataInfo{
+					"greet":    {ID: "s-1", RunnerType: "nsl"},
+					"farewell": {ID: "s-2", RunnerType: "nsl"},
+				}},
+			}},
+		}},
+	}}
+
+	root := customerNode(customer.Entry{}, "acme", "projects", projectMap, hashes)
+	if root.Status != "1 changed" || !root.Drift {
+		t.Fatalf("expected one drifted skill at the customer level, got %q", root.Status)
+	}
+	skills := root.Children[0].Children[0].Children[0].Children
+	if len(skills) != 2 || skills[0].Label != "farewell" || skills[0].Status != skillStatusClean || skills[1].Status != skillStatusModified {
+		t.Fatalf("unexpected skill rows: %+v, %+v", skills[0], skills[1])
+	}
+	if item := skills[1].Value.(uiItem); item.Path != greet || item.SkillID != "s-1" || item.Project != "booking" {
+		t.Fatalf("unexpected skill item: %+v", item)
+	}
+}
@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/validate"
+)
+
+// ValidateCommand checks the structure of exported projects before they are pushed.
+type ValidateCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	format   *string
+}
+
+// NewValidateCommand constructs a validate command.
+func NewValidateCommand(stdout, stderr io.Writer) *ValidateCommand {
+	return &ValidateCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *ValidateCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *ValidateCommand) Name() string {
+	return "validate"
+}
+
+func (c *ValidateCommand) Summary() string {
+	return "Validate the structure of exported projects (YAML, metadata, scripts, IDNs)"
+}
+
+func (c *ValidateCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to validate")
+	c.format = fs.String("format", "text", "output format: text or json")
+}
+
+func (c *ValidateCommand) Run(_ context.Context, _ []string) error {
+	c.ensureConsole()
+
+	format := "text"
+	if c.format != nil && strings.TrimSpace(*c.format) != "" {
+		format = strings.ToLower(strings.TrimSpace(*c.format))
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported validate format %q (expected text or json)", format)
+	}
+	if format == "json" {
+		c.console = console.New(c.stderr, c.stderr)
+	}
+	c.console.Section("Validate")
+
+	outputRoot, err := getOutputRoot()
+	if err != nil {
+		return err
+	}
+	if outputRoot == "" {
+		outputRoot = "."
+	}
+
+	filter := ""
+	if c.customer != nil {
+		filter = strings.TrimSpace(*c.customer)
+	}
+	targets, err := validateTargets(outputRoot, filter)
+	if err != nil {
+		return err
+	}
+
+	var report validate.Report
+	for _, target := range targets {
+		if _, err := os.Stat(target.dir); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		result, err := validate.Run(target.dir, target.customerType)
+		if err != nil {
+			return fmt.Errorf("validate %s: %w", target.dir, err)
+		}
+		report.Projects = append(report.Projects, result.Projects...)
+		report.Issues = append(report.Issues, result.Issues...)
+	}
+	for i := range report.Projects {
+		report.Projects[i] = displayLintPath(report.Projects[i])
+	}
+	for i := range report.Issues {
+		report.Issues[i].Path = displayLintPath(filepath.FromSlash(report.Issues[i].Path))
+	}
+	if report.Projects == nil {
+		report.Projects = []string{}
+	}
+	if report.Issues == nil {
+		report.Issues = []validate.Issue{}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("write json report: %w", err)
+		}
+	} else {
+		c.printReport(report)
+	}
+
+	if len(report.Issues) > 0 {
+		return newSilentExitError(1)
+	}
+	return nil
+}
+
+// validateTarget is a directory of exported projects together with the type of their customer, which
+// decides the layout of the scripts inside.
+type validateTarget struct {
+	dir          string
+	customerType string
+}
+
+// validateTargets returns the project directories of the customer matching filter, or of every
+// configured customer that has been pulled. Without newo.toml the whole output root is validated with
+// the default layout.
+func validateTargets(outputRoot, filter string) ([]validateTarget, error) {
+	var definitions []customerDefinition
+	if filter != "" {
+		definition, err := loadCustomerDefinition(filter)
+		if err != nil {
+			return nil, err
+		}
+		if definition == nil {
+			return nil, fmt.Errorf("customer %s not configured", filter)
+		}
+		definitions = []customerDefinition{*definition}
+	} else {
+		var err error
+		if definitions, err = loadCustomerDefinitions(); err != nil {
+			return nil, err
+		}
+		if len(definitions) == 0 {
+			return []validateTarget{{dir: outputRoot}}, nil
+		}
+	}
+
+	var targets []validateTarget
+	for i := range definitions {
+		dirs, pulled, err := customerProjectDirectories(outputRoot, &definitions[i])
+		if err != nil {
+			return nil, err
+		}
+		if !pulled && filter != "" {
+			return nil, fmt.Errorf("no project map for %s; run `newo pull --customer %s` first", definitions[i].IDN, definitions[i].IDN)
+		}
+		for _, dir := range dirs {
+			targets = append(targets, validateTarget{dir: dir, customerType: definitions[i].Type})
+		}
+	}
+	return targets, nil
+}
+
+func (c *ValidateCommand) printReport(report validate.Report) {
+	if len(report.Projects) == 0 {
+		c.console.Info("No exported projects found. Run `newo pull` first.")
+		return
+	}
+	if len(report.Issues) == 0 {
+		c.console.Success("%d project(s) valid.", len(report.Projects))
+		return
+	}
+
+	grouped := make(map[string][]validate.Issue)
+	for _, issue := range report.Issues {
+		grouped[issue.Path] = append(grouped[issue.Path], issue)
+	}
+	files := make([]string, 0, len(grouped))
+	for file := range grouped {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for idx, file := range files {
+		if idx > 0 {
+			c.console.RawLine("")
+		}
+		c.console.Section(file)
+		for _, issue := range grouped[file] {
+			c.console.RawLine("  %-14s | %s", issue.Check, issue.Message)
+		}
+	}
+	c.console.Warn("Summary: %d issue(s) in %d file(s) across %d project(s)", len(report.Issues), len(files), len(report.Projects))
+}
@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/serialize"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/ui/console"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// Kinds of inconsistencies found by verify.
+const (
+	verifyMissingProject  = "missing project directory"
+	verifyMissingScript   = "missing script"
+	verifyMissingMetadata = "missing metadata"
+	verifyUntrackedScript = "untracked script"
+	verifyStaleHash       = "stale hash"
+)
+
+// VerifyCommand cross-checks the project map, the hash store, and the export tree, so that state left
+// inconsistent by an interrupted write or a hand edit is found before push trips over it.
+type VerifyCommand struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	console  *console.Writer
+	customer *string
+	repair   *bool
+}
+
+// verifyIssue is one inconsistency. Repairable issues can be fixed offline; the others need a pull.
+type verifyIssue struct {
+	Kind     string
+	Customer string
+	Path     string
+	Hint     string
+	// metadata is the skill whose metadata file can be rebuilt from the project map.
+	metadata *state.SkillMetadataInfo
+}
+
+func (i verifyIssue) repairable() bool {
+	return i.Kind == verifyStaleHash || i.metadata != nil
+}
+
+// NewVerifyCommand constructs a verify command.
+func NewVerifyCommand(stdout, stderr io.Writer) *VerifyCommand {
+	return &VerifyCommand{
+		stdout:  stdout,
+		stderr:  stderr,
+		console: console.New(stdout, stderr),
+	}
+}
+
+func (c *VerifyCommand) ensureConsole() {
+	if c.console == nil {
+		c.console = console.New(c.stdout, c.stderr)
+	}
+}
+
+func (c *VerifyCommand) Name() string {
+	return "verify"
+}
+
+func (c *VerifyCommand) Summary() string {
+	return "Check that the project map, file hashes, and exported files agree"
+}
+
+func (c *VerifyCommand) RegisterFlags(fs *flag.FlagSet) {
+	c.customer = fs.String("customer", "", "customer IDN or alias to check")
+	c.repair = fs.Bool("repair", false, "drop stale hashes and rebuild missing skill metadata from the project map")
+}
+
+func (c *VerifyCommand) Run(_ context.Context, args []string) error {
+	c.ensureConsole()
+
+	if len(args) != 0 {
+		return errors.New("usage: newo verify [--customer <idn>] [--repair]")
+	}
+	customerFilter := flagValue(c.customer)
+
+	env, err := config.LoadEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := customer.FromEnv(env)
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadAPIKeyRegistry()
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	matched := false
+	checked := 0
+	var issues []verifyIssue
+	for _, entry := range cfg.Entries {
+		idn := strings.TrimSpace(entry.HintIDN)
+		if idn == "" {
+			idn, _ = registry.Lookup(entry.APIKey)
+		}
+		if idn == "" || seen[strings.ToLower(idn)] {
+			continue
+		}
+		seen[strings.ToLower(idn)] = true
+		if customerFilter != "" && !matchesCustomerToken(entry, idn, customerFilter) {
+			continue
+		}
+		matched = true
+		if _, err := os.Stat(fsutil.MapPath(idn)); err != nil {
+			continue
+		}
+		found, err := verifyCustomer(env.OutputRoot, entry.Type, idn)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, found...)
+		checked++
+	}
+	if customerFilter != "" && !matched {
+		return fmt.Errorf("customer %s not configured", customerFilter)
+	}
+	if checked == 0 {
+		c.console.Info("No pulled customers to verify. Run `newo pull` first.")
+		return nil
+	}
+	if len(issues) == 0 {
+		c.console.Success("Project map, hashes, and files are consistent.")
+		return nil
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Customer != issues[j].Customer {
+			return issues[i].Customer < issues[j].Customer
+		}
+		return issues[i].Path < issues[j].Path
+	})
+	table := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		table = append(table, []string{issue.Kind, issue.Customer, issue.Path, issue.Hint})
+	}
+	c.console.Section("Inconsistencies")
+	writeTable(c.console, []string{"KIND", "CUSTOMER", "PATH", "FIX"}, table)
+
+	remaining := len(issues)
+	if c.repair != nil && *c.repair {
+		repaired, err := c.repairIssues(issues)
+		if err != nil {
+			return err
+		}
+		remaining -= repaired
+		c.console.Success("Repaired %d issue(s).", repaired)
+	}
+	if remaining > 0 {
+		c.console.Warn("%d issue(s) remain.", remaining)
+		return newSilentExitError(1)
+	}
+	return nil
+}
+
+// repairIssues fixes what can be fixed offline, holding each customer's lock while its state changes.
+func (c *VerifyCommand) repairIssues(issues []verifyIssue) (int, error) {
+	byCustomer := map[string][]verifyIssue{}
+	var order []string
+	for _, issue := range issues {
+		if !issue.repairable() {
+			continue
+		}
+		if _, ok := byCustomer[issue.Customer]; !ok {
+			order = append(order, issue.Customer)
+		}
+		byCustomer[issue.Customer] = append(byCustomer[issue.Customer], issue)
+	}
+
+	repaired := 0
+	for _, customerIDN := range order {
+		group := byCustomer[customerIDN]
+		err := withCustomerLock(c.console, customerIDN, "verify", false, func() error {
+			hashes, err := state.LoadHashes(customerIDN)
+			if err != nil {
+				return err
+			}
+			for _, issue := range group {
+				if issue.Kind == verifyStaleHash {
+					delete(hashes, issue.Path)
+					repaired++
+					continue
+				}
+				// Tags exist only in the file, so keep them when it can still be read.
+				tags, _ := state.LoadSkillTags(filepath.FromSlash(issue.Path))
+				data, err := serialize.TaggedSkillMetadata(skillFromMetadataInfo(*issue.metadata), tags)
+				if err != nil {
+					return fmt.Errorf("encode %s: %w", issue.Path, err)
+				}
+				if err := writeFile(filepath.FromSlash(issue.Path), data); err != nil {
+					return err
+				}
+				hashes[issue.Path] = util.ContentHash(data)
+				repaired++
+			}
+			return state.SaveHashes(customerIDN, hashes)
+		})
+		if err != nil {
+			return repaired, err
+		}
+	}
+	return repaired, nil
+}
+
+// verifyCustomer checks one customer's project map against the export tree and hash store.
+func verifyCustomer(outputRoot, customerType, customerIDN string) ([]verifyIssue, error) {
+	projectMap, err := state.LoadProjectMap(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := state.LoadHashes(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []verifyIssue
+	add := func(kind, path, hint string) {
+		issues = append(issues, verifyIssue{Kind: kind, Customer: customerIDN, Path: filepath.ToSlash(path), Hint: hint})
+	}
+	scripts := map[string]bool{}
+	var missingDirs []string
+	for projectIDN, projectData := range projectMap.Projects {
+		slug := projectSlugFromState(projectIDN, projectData)
+		projectDir := fsutil.ExportProjectDir(outputRoot, customerType, customerIDN, slug)
+		if _, err := os.Stat(projectDir); errors.Is(err, os.ErrNotExist) {
+			add(verifyMissingProject, projectDir, "run `newo pull`")
+			missingDirs = append(missingDirs, filepath.ToSlash(projectDir))
+			continue
+		}
+		for agentIDN, agentData := range projectData.Agents {
+			for flowIDN, flowData := range agentData.Flows {
+				for skillIDN, skill := range flowData.Skills {
+					fileName := skillIDN + "." + platform.ScriptExtension(skill.RunnerType)
+					script := filepath.ToSlash(fsutil.ExportSkillScriptPath(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN, fileName))
+					scripts[script] = true
+					if _, err := os.Stat(script); errors.Is(err, os.ErrNotExist) {
+						add(verifyMissingScript, script, "run `newo pull` to restore it; push would delete the remote skill")
+					} else if _, tracked := hashes[script]; !tracked {
+						add(verifyUntrackedScript, script, "run `newo pull` to record its hash")
+					}
+
+					metadata := fsutil.ExportSkillMetadataPath(outputRoot, customerType, customerIDN, slug, agentIDN, flowIDN, skillIDN)
+					if _, err := os.Stat(metadata); errors.Is(err, os.ErrNotExist) {
+						skill := skill
+						if skill.IDN == "" {
+							skill.IDN = skillIDN
+						}
+						issues = append(issues, verifyIssue{Kind: verifyMissingMetadata, Customer: customerIDN,
+							Path: filepath.ToSlash(metadata), Hint: "--repair rebuilds it from the project map", metadata: &skill})
+					}
+				}
+			}
+		}
+	}
+
+	// Hashes of mapped scripts are left to the missing-script check, and those of missing projects to
+	// the next pull.
+	for path := range hashes {
+		if scripts[path] || slices.ContainsFunc(missingDirs, func(dir string) bool { return pathUnder(path, dir) }) {
+			continue
+		}
+		if _, err := os.Stat(filepath.FromSlash(path)); errors.Is(err, os.ErrNotExist) {
+			add(verifyStaleHash, path, "--repair drops it")
+		}
+	}
+	return issues, nil
+}
+
+// skillFromMetadataInfo rebuilds the skill a metadata file describes from its project map entry.
+func skillFromMetadataInfo(info state.SkillMetadataInfo) platform.Skill {
+	skill := platform.Skill{
+		ID:         info.ID,
+		IDN:        info.IDN,
+		Title:      info.Title,
+		RunnerType: info.RunnerType,
+		Model: platform.ModelConfig{
+			ModelIDN:    info.Model["model_idn"],
+			ProviderIDN: info.Model["provider_idn"],
+		},
+	}
+	for _, param := range info.Parameters {
+		name, _ := param["name"].(string)
+		value := ""
+		if raw, ok := param["default_value"]; ok && raw != nil {
+			value = fmt.Sprint(raw)
+		}
+		skill.Parameters = append(skill.Parameters, platform.SkillParameter{Name: name, DefaultValue: value})
+	}
+	return skill
+}
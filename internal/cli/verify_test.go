@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+func TestVerifyFindsAndRepairsInconsistencies(t *testing.T) {
+	t.Chdir(t.TempDir())
+	toml := "[defaults]\noutput_root = \"out\"\n\n[[customers]]\nidn = \"acme\"\napi_key = \"key\"\n"
+	if err := os.WriteFile("newo.toml", []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"support": {Path: "support", Agents: map[string]state.AgentData{
+			"agent": {Flows: map[string]state.FlowData{
+				"main": {Skills: map[string]state.SkillMetadataInfo{
+					"greet":   {ID: "s1", IDN: "greet", Title: "Greet", RunnerType: "guidance", Model: map[string]string{"model_idn": "gpt"}},
+					"deleted": {ID: "s2", IDN: "deleted", RunnerType: "nsl"},
+					"fresh":   {ID: "s3", IDN: "fresh", RunnerType: "nsl"},
+				}},
+			}},
+		}},
+		"archived": {Path: "archived"},
+	}}
+	if err := state.SaveProjectMap("acme", projectMap); err != nil {
+		t.Fatal(err)
+	}
+	flowDir := fsutil.ExportFlowDir("out", "", "acme", "support", "agent", "main")
+	writeTestFile(t, filepath.Join(flowDir, "greet.guidance"), "hi")
+	writeTestFile(t, filepath.Join(flowDir, "deleted.meta.yaml"), "idn: deleted\n")
+	writeTestFile(t, filepath.Join(flowDir, "fresh.nsl"), "x")
+	writeTestFile(t, filepath.Join(flowDir, "fresh.meta.yaml"), "idn: fresh\n")
+	greet := filepath.ToSlash(filepath.Join(flowDir, "greet.guidance"))
+	deleted := filepath.ToSlash(filepath.Join(flowDir, "deleted.nsl"))
+	if err := state.SaveHashes("acme", state.HashStore{
+		greet:                          "h1",
+		deleted:                        "h2",
+		"out/acme/support/gone.nsl":    "h3",
+		"out/acme/archived/flows.yaml": "h4",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := verifyCustomer("out", "", "acme")
+	if err != nil {
+		t.Fatalf("verifyCustomer: %v", err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Kind+" "+issue.Path)
+	}
+	sort.Strings(got)
+	want := []string{
+		verifyMissingMetadata + " " + filepath.ToSlash(filepath.Join(flowDir, "greet.meta.yaml")),
+		verifyMissingProject + " out/acme/archived",
+		verifyMissingScript + " " + deleted,
+		verifyStaleHash + " out/acme/support/gone.nsl",
+		verifyUntrackedScript + " " + filepath.ToSlash(filepath.Join(flowDir, "fresh.nsl")),
+	}
+	sort.Strings(want)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected issues:\n got %v\nwant %v", got, want)
+	}
+
+	cmd := NewVerifyCommand(&bytes.Buffer{}, &bytes.Buffer{})
+	repair := true
+	cmd.repair = &repair
+	if err := cmd.Run(context.Background(), nil); err == nil {
+		t.Fatalf("expected the issues that need a pull to fail the run")
+	}
+	metadata, err := os.ReadFile(filepath.Join(flowDir, "greet.meta.yaml"))
+	if err != nil || !strings.Contains(string(metadata), "title: Greet") || !strings.Contains(string(metadata), "modelidn: gpt") {
+		t.Fatalf("expected the metadata to be rebuilt from the map, got %q, %v", metadata, err)
+	}
+	hashes, err := state.LoadHashes("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hashes["out/acme/support/gone.nsl"]; ok {
+		t.Fatalf("expected the stale hash to be dropped, got %v", hashes)
+	}
+	if _, ok := hashes[filepath.ToSlash(filepath.Join(flowDir, "greet.meta.yaml"))]; !ok || hashes[deleted] != "h2" {
+		t.Fatalf("expected the rebuilt metadata to be tracked and the missing script's hash kept, got %v", hashes)
+	}
+}
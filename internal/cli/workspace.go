@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/config"
+)
+
+// invocationDir is the directory the CLI was started from, before switching to the workspace root.
+var invocationDir string
+
+// enterWorkspace resolves the active newo.toml and makes its directory the working directory, so
+// state, output, and relative settings in the configuration resolve the same way from any subdirectory.
+// The returned function restores the previous working directory.
+func enterWorkspace(explicit string) (func(), error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("determine working directory: %w", err)
+	}
+	invocationDir = cwd
+
+	path, err := config.ResolveToml(explicit, cwd)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		config.SetTomlPath("")
+		return func() { invocationDir = "" }, nil
+	}
+
+	root := filepath.Dir(path)
+	if err := os.Chdir(root); err != nil {
+		return nil, fmt.Errorf("enter workspace %s: %w", root, err)
+	}
+	config.SetTomlPath(filepath.Base(path))
+	return func() {
+		_ = os.Chdir(cwd)
+		config.SetTomlPath("")
+		invocationDir = ""
+	}, nil
+}
+
+// userPath resolves a path typed by the user against the directory the CLI was started from.
+func userPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" || filepath.IsAbs(path) || invocationDir == "" {
+		return path
+	}
+	return filepath.Join(invocationDir, path)
+}
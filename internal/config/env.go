@@ -6,9 +6,13 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
 )
 
 // Env holds validated environment variables required by the CLI.
@@ -27,6 +31,43 @@ type Env struct {
 	OutputRoot          string
 	SlugPrefix          string
 	FileLLMs            []LLMConfig
+	PrePushHooks        []string
+	Profile             string
+	Credentials         CredentialsConfig
+	// BlobCache enables the content-addressed blob store and the file hash cache ([cache] blobs).
+	BlobCache bool
+	// Notifications lists the webhooks called after push, merge, and deploy ([[notifications]]).
+	Notifications []Notification
+	// Performance holds the concurrency limits of pull and push, with every level resolved ([performance]).
+	Performance PerformanceConfig
+}
+
+// Notification types accepted in the [[notifications]] section of newo.toml.
+const (
+	NotificationSlack = "slack"
+	NotificationHTTP  = "http"
+)
+
+// Notification describes a webhook that receives a summary after commands that change the platform.
+type Notification struct {
+	Type string `toml:"type"`
+	URL  string `toml:"url"`
+	// Events limits the commands (push, merge, deploy) that trigger the webhook; empty means all.
+	Events  []string          `toml:"events"`
+	Headers map[string]string `toml:"headers"`
+}
+
+// Wants reports whether the webhook should fire for the given command.
+func (n Notification) Wants(command string) bool {
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, event := range n.Events {
+		if strings.EqualFold(strings.TrimSpace(event), command) {
+			return true
+		}
+	}
+	return false
 }
 
 // FileCustomer describes a customer defined in newo.toml.
@@ -36,6 +77,16 @@ type FileCustomer struct {
 	APIKey   string
 	Type     string
 	Projects []Project
+	// Vars holds placeholder values substituted into skill scripts merged or deployed into this customer.
+	Vars map[string]string
+	// Protected requires the customer IDN to be confirmed before commands write to the customer.
+	Protected bool
+	// BaseURL overrides the platform URL for this customer; APIVersion (for example "v2") replaces the
+	// v1 prefix of its API paths.
+	BaseURL    string
+	APIVersion string
+	// Group names the fleet the customer belongs to, so pull, push, and status can select it with --group.
+	Group string
 }
 
 // Project describes a project defined within a customer in newo.toml.
@@ -74,6 +125,7 @@ func LoadEnv() (Env, error) {
 		DefaultCustomer: strings.TrimSpace(os.Getenv("NEWO_DEFAULT_CUSTOMER")),
 		OutputRoot:      strings.TrimSpace(os.Getenv("NEWO_OUTPUT_ROOT")),
 		SlugPrefix:      strings.TrimSpace(os.Getenv("NEWO_SLUG_PREFIX")),
+		Performance:     DefaultPerformance,
 	}
 
 	var isOutputRootSetInToml bool
@@ -84,6 +136,11 @@ func LoadEnv() (Env, error) {
 	if env.OutputRoot == "" && !isOutputRootSetInToml {
 		env.OutputRoot = defaultCustomersRoot
 	}
+	// Every exported path, and so every path recorded in state, is built from the output root.
+	if filepath.IsAbs(env.OutputRoot) {
+		env.OutputRoot = filepath.FromSlash(fsutil.WorkspaceRelative(env.OutputRoot))
+	}
+	fsutil.SetOutputRoot(env.OutputRoot)
 
 	if env.ProjectID != "" && !looksLikeUUID(env.ProjectID) {
 		return Env{}, fmt.Errorf("NEWO_PROJECT_ID must be a valid UUID, got %q", env.ProjectID)
@@ -97,6 +154,8 @@ func LoadEnv() (Env, error) {
 	return env, nil
 }
 
+var apiVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
 func validateURL(raw, name string) error {
 	u, err := url.Parse(raw)
 	if err != nil || u.Scheme == "" || u.Host == "" {
@@ -143,19 +202,151 @@ type TomlConfig struct {
 		DefaultCustomerIDN string  `toml:"default_customer"`
 		ProjectID          string  `toml:"project_id"`
 		ProjectIDN         string  `toml:"project_idn"`
+		// AutoCRLF sets how line endings are compared and written, as git's core.autocrlf does.
+		AutoCRLF AutoCRLF `toml:"autocrlf"`
 	} `toml:"defaults"`
-	Customers []struct {
-		IDN      string    `toml:"idn"`
-		Alias    string    `toml:"alias"`
-		APIKey   string    `toml:"api_key"`
-		Type     string    `toml:"type"`
-		Projects []Project `toml:"projects"`
-	} `toml:"customers"`
-	LLMs []struct {
+	Customers []FileCustomerWritable `toml:"customers"`
+	LLMs      []struct {
 		Provider string `toml:"provider"`
 		Model    string `toml:"model"`
 		APIKey   string `toml:"api_key"`
 	} `toml:"llms"`
+	Lint   LintConfig `toml:"lint"`
+	Redact struct {
+		Patterns []string `toml:"patterns"`
+	} `toml:"redact"`
+	Hooks struct {
+		PrePush []string `toml:"pre_push"`
+	} `toml:"hooks"`
+	Cache struct {
+		Blobs bool `toml:"blobs"`
+	} `toml:"cache"`
+	Notifications []Notification     `toml:"notifications"`
+	Profiles      map[string]Profile `toml:"profiles"`
+	Credentials   CredentialsConfig  `toml:"credentials"`
+	HTTP          HTTPConfig         `toml:"http"`
+	Performance   PerformanceConfig  `toml:"performance"`
+}
+
+// AutoCRLF is the autocrlf setting in the [defaults] section of newo.toml. Like git's core.autocrlf
+// it is true, false, or "input", written as a boolean or a string.
+type AutoCRLF string
+
+// UnmarshalTOML accepts both the boolean and the string forms.
+func (a *AutoCRLF) UnmarshalTOML(value any) error {
+	switch v := value.(type) {
+	case bool:
+		*a = AutoCRLF(strconv.FormatBool(v))
+	case string:
+		*a = AutoCRLF(v)
+	default:
+		return fmt.Errorf("autocrlf must be true, false, or \"input\", got %v", value)
+	}
+	return nil
+}
+
+// PerformanceConfig describes the [performance] section of newo.toml, which sets how many platform
+// requests pull and push make in parallel at each level. Zero leaves a level to Concurrency, and then
+// to the defaults.
+type PerformanceConfig struct {
+	// Concurrency applies to every level that is not set on its own.
+	Concurrency int `toml:"concurrency"`
+	// Projects pulled at once.
+	Projects int `toml:"projects"`
+	// Agents and flows fetched at once within a project or agent.
+	Flows int `toml:"flows"`
+	// Skills written at once within a flow during pull.
+	Skills int `toml:"skills"`
+	// Skills uploaded, created, or published at once within a flow during push.
+	Uploads int `toml:"uploads"`
+}
+
+// DefaultPerformance is used for the levels newo.toml does not set.
+var DefaultPerformance = PerformanceConfig{Projects: 4, Flows: 8, Skills: 16, Uploads: 8}
+
+// WithConcurrency sets every level to n, as --concurrency does; n below one keeps the limits.
+func (p PerformanceConfig) WithConcurrency(n int) PerformanceConfig {
+	if n < 1 {
+		return p
+	}
+	return PerformanceConfig{Concurrency: n, Projects: n, Flows: n, Skills: n, Uploads: n}
+}
+
+// resolve fills in the unset levels. The defaults never exceed maxConns, the [http]
+// max_conns_per_host limit, so that a connection cap set for a rate-limited platform also keeps
+// requests from queueing behind it.
+func (p PerformanceConfig) resolve(maxConns int) (PerformanceConfig, error) {
+	if p.Concurrency < 0 {
+		return PerformanceConfig{}, fmt.Errorf("performance.concurrency: must not be negative")
+	}
+	resolved := p
+	for _, level := range []struct {
+		name     string
+		value    *int
+		fallback int
+	}{
+		{"projects", &resolved.Projects, DefaultPerformance.Projects},
+		{"flows", &resolved.Flows, DefaultPerformance.Flows},
+		{"skills", &resolved.Skills, DefaultPerformance.Skills},
+		{"uploads", &resolved.Uploads, DefaultPerformance.Uploads},
+	} {
+		if *level.value < 0 {
+			return PerformanceConfig{}, fmt.Errorf("performance.%s: must not be negative", level.name)
+		}
+		if *level.value > 0 {
+			continue
+		}
+		*level.value = level.fallback
+		if p.Concurrency > 0 {
+			*level.value = p.Concurrency
+		} else if maxConns > 0 && maxConns < level.fallback {
+			*level.value = maxConns
+		}
+	}
+	return resolved, nil
+}
+
+// HTTPConfig describes the [http] section of newo.toml, which configures how the platform is reached.
+type HTTPConfig struct {
+	// Proxy is the URL of an HTTP(S) proxy. When empty, HTTPS_PROXY, HTTP_PROXY, and NO_PROXY apply.
+	Proxy string `toml:"proxy"`
+	// CABundle is a PEM file of CA certificates trusted in addition to the system ones.
+	CABundle string `toml:"ca_bundle"`
+	// InsecureSkipVerify disables TLS certificate verification. It is meant for debugging only.
+	InsecureSkipVerify bool `toml:"insecure_skip_verify"`
+	// RequestTimeout bounds each API request and OperationTimeout a whole command, as Go durations
+	// such as "45s" or "20m". An empty OperationTimeout means no deadline.
+	RequestTimeout   string `toml:"request_timeout"`
+	OperationTimeout string `toml:"operation_timeout"`
+	// Connection pool tuning; zero keeps the defaults.
+	MaxIdleConnsPerHost int    `toml:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int    `toml:"max_conns_per_host"`
+	IdleConnTimeout     string `toml:"idle_conn_timeout"`
+	DisableKeepAlives   bool   `toml:"disable_keep_alives"`
+	// CompressRequests gzip-compresses large request bodies such as long prompt scripts.
+	CompressRequests bool `toml:"compress_requests"`
+}
+
+// CredentialsConfig describes the [credentials] section of newo.toml, which selects where API keys
+// of customers without an api_key entry are read from.
+type CredentialsConfig struct {
+	Backend string `toml:"backend"`
+	Get     string `toml:"get"`
+	Store   string `toml:"store"`
+	Erase   string `toml:"erase"`
+}
+
+// LintSettings disables linter rules or overrides their severity ("error", "warning", or "off").
+type LintSettings struct {
+	Disable  []string          `toml:"disable"`
+	Severity map[string]string `toml:"severity"`
+}
+
+// LintConfig describes the [lint] section of newo.toml; Projects holds per-project overrides keyed by project IDN.
+type LintConfig struct {
+	Disable  []string                `toml:"disable"`
+	Severity map[string]string       `toml:"severity"`
+	Projects map[string]LintSettings `toml:"projects"`
 }
 
 func validateCustomers(customers []FileCustomer) error {
@@ -184,20 +375,28 @@ func validateCustomers(customers []FileCustomer) error {
 }
 
 func mergeTomlConfig(env *Env, isOutputRootSetInToml *bool) error {
-	path := filepath.Join(".", DefaultTomlPath)
+	path := TomlPath()
+	profile := ActiveProfile()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
+			if profile != "" {
+				return fmt.Errorf("profile %q requires %s", profile, path)
+			}
 			return nil
 		}
-		return fmt.Errorf("read %s: %w", DefaultTomlPath, err)
+		return fmt.Errorf("read %s: %w", path, err)
 	}
 
 	var cfg TomlConfig
 	// Use Decode instead of Unmarshal to get better error messages with line numbers.
 	if _, err := toml.Decode(string(data), &cfg); err != nil {
-		return fmt.Errorf("parse %s: %w", DefaultTomlPath, err)
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := cfg.ApplyProfile(profile); err != nil {
+		return err
 	}
+	env.Profile = profile
 
 	if base := strings.TrimSpace(cfg.Defaults.BaseURL); base != "" && env.BaseURL == defaultBaseURL {
 		env.BaseURL = base
@@ -222,10 +421,22 @@ func mergeTomlConfig(env *Env, isOutputRootSetInToml *bool) error {
 
 	for _, c := range cfg.Customers {
 		apiKey := strings.TrimSpace(c.APIKey)
-		if apiKey == "" {
+		// Without an api_key the key comes from the credentials backend, which needs the IDN to look it up.
+		if apiKey == "" && strings.TrimSpace(c.IDN) == "" {
 			continue
 		}
 
+		baseURL := strings.TrimSpace(c.BaseURL)
+		if baseURL != "" {
+			if err := validateURL(baseURL, fmt.Sprintf("base_url of customer %s", c.IDN)); err != nil {
+				return err
+			}
+		}
+		apiVersion := strings.ToLower(strings.TrimSpace(c.APIVersion))
+		if apiVersion != "" && !apiVersionPattern.MatchString(apiVersion) {
+			return fmt.Errorf("api_version of customer %s must look like v1 or v2, got %q", c.IDN, c.APIVersion)
+		}
+
 		var projects []Project
 		for _, p := range c.Projects {
 			projects = append(projects, Project{
@@ -235,11 +446,16 @@ func mergeTomlConfig(env *Env, isOutputRootSetInToml *bool) error {
 		}
 
 		env.FileCustomers = append(env.FileCustomers, FileCustomer{
-			IDN:      strings.TrimSpace(c.IDN),
-			Alias:    strings.TrimSpace(c.Alias),
-			APIKey:   apiKey,
-			Type:     strings.TrimSpace(c.Type),
-			Projects: projects,
+			IDN:        strings.TrimSpace(c.IDN),
+			Alias:      strings.TrimSpace(c.Alias),
+			APIKey:     apiKey,
+			Type:       strings.TrimSpace(c.Type),
+			Projects:   projects,
+			Vars:       c.Vars,
+			Protected:  c.Protected,
+			BaseURL:    baseURL,
+			APIVersion: apiVersion,
+			Group:      strings.TrimSpace(c.Group),
 		})
 	}
 
@@ -256,6 +472,41 @@ func mergeTomlConfig(env *Env, isOutputRootSetInToml *bool) error {
 		})
 	}
 
+	env.Credentials = cfg.Credentials
+	env.BlobCache = cfg.Cache.Blobs
+	performance, err := cfg.Performance.resolve(cfg.HTTP.MaxConnsPerHost)
+	if err != nil {
+		return err
+	}
+	env.Performance = performance
+
+	for _, hook := range cfg.Hooks.PrePush {
+		if hook = strings.TrimSpace(hook); hook != "" {
+			env.PrePushHooks = append(env.PrePushHooks, hook)
+		}
+	}
+
+	for i, n := range cfg.Notifications {
+		// Webhook URLs and tokens are secrets, so they may be taken from the environment.
+		n.Type = strings.ToLower(strings.TrimSpace(n.Type))
+		n.URL = strings.TrimSpace(os.ExpandEnv(n.URL))
+		if n.Type == "" {
+			n.Type = NotificationHTTP
+		}
+		if n.Type != NotificationSlack && n.Type != NotificationHTTP {
+			return fmt.Errorf("notifications[%d]: unknown type %q (use slack or http)", i, n.Type)
+		}
+		if err := validateURL(n.URL, fmt.Sprintf("notifications[%d].url", i)); err != nil {
+			return err
+		}
+		headers := map[string]string{}
+		for name, value := range n.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+		n.Headers = headers
+		env.Notifications = append(env.Notifications, n)
+	}
+
 	if err := validateCustomers(env.FileCustomers); err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -52,6 +53,25 @@ func TestLoadEnvDefaults(t *testing.T) {
 	}
 }
 
+func TestLoadEnvMakesOutputRootWorkspaceRelative(t *testing.T) {
+	dir := withTempDir(t)
+	withChdir(t, dir)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+
+	t.Setenv("NEWO_API_KEY", "dummy-key")
+	t.Setenv("NEWO_OUTPUT_ROOT", filepath.Join(cwd, "exports"))
+	env, err := LoadEnv()
+	if err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	if env.OutputRoot != "exports" {
+		t.Fatalf("expected a workspace-relative output root, got %q", env.OutputRoot)
+	}
+}
+
 func TestTomlLoading(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -194,3 +214,118 @@ func TestLooksLikeUUID(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadEnvNotifications(t *testing.T) {
+	dir := withTempDir(t)
+	withChdir(t, dir)
+
+	content := `
+[[notifications]]
+type = "slack"
+url = "${SLACK_WEBHOOK}"
+events = ["push"]
+
+[[notifications]]
+url = "https://ci.example.com/newo"
+headers = { Authorization = "Bearer ${CI_TOKEN}" }
+`
+	if err := os.WriteFile("newo.toml", []byte(content), fsutil.FilePerm); err != nil {
+		t.Fatalf("write toml: %v", err)
+	}
+	t.Setenv("NEWO_API_KEY", "dummy")
+	t.Setenv("SLACK_WEBHOOK", "https://hooks.slack.com/services/T/B/x")
+	t.Setenv("CI_TOKEN", "secret")
+
+	env, err := LoadEnv()
+	if err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	want := []Notification{
+		{Type: NotificationSlack, URL: "https://hooks.slack.com/services/T/B/x", Events: []string{"push"}, Headers: map[string]string{}},
+		{Type: NotificationHTTP, URL: "https://ci.example.com/newo", Headers: map[string]string{"Authorization": "Bearer secret"}},
+	}
+	if diff := cmp.Diff(want, env.Notifications); diff != "" {
+		t.Fatalf("Notifications mismatch (-want +got):\n%s", diff)
+	}
+	if !env.Notifications[0].Wants("PUSH") || env.Notifications[0].Wants("deploy") || !env.Notifications[1].Wants("deploy") {
+		t.Fatalf("unexpected event filtering: %+v", env.Notifications)
+	}
+
+	if err := os.WriteFile("newo.toml", []byte("[[notifications]]\ntype = \"email\"\nurl = \"https://example.com\"\n"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write toml: %v", err)
+	}
+	if _, err := LoadEnv(); err == nil || !strings.Contains(err.Error(), "unknown type") {
+		t.Fatalf("expected unknown type error, got %v", err)
+	}
+}
+
+func TestLoadEnvCustomerBaseURL(t *testing.T) {
+	dir := withTempDir(t)
+	withChdir(t, dir)
+
+	content := `
+[[customers]]
+idn = "eu"
+api_key = "key"
+base_url = "https://eu.newo.example"
+api_version = "V2"
+`
+	if err := os.WriteFile("newo.toml", []byte(content), fsutil.FilePerm); err != nil {
+		t.Fatalf("write toml: %v", err)
+	}
+	env, err := LoadEnv()
+	if err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	if got := env.FileCustomers[0]; got.BaseURL != "https://eu.newo.example" || got.APIVersion != "v2" {
+		t.Fatalf("unexpected customer: %+v", got)
+	}
+
+	for _, bad := range []string{`base_url = "eu.newo.example"`, `api_version = "2"`} {
+		if err := os.WriteFile("newo.toml", []byte("[[customers]]\nidn = \"eu\"\napi_key = \"key\"\n"+bad+"\n"), fsutil.FilePerm); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+		if _, err := LoadEnv(); err == nil || !strings.Contains(err.Error(), "customer eu") {
+			t.Fatalf("%s: expected a validation error, got %v", bad, err)
+		}
+	}
+}
+
+func TestLoadEnvPerformance(t *testing.T) {
+	dir := withTempDir(t)
+	withChdir(t, dir)
+	t.Setenv("NEWO_API_KEY", "dummy")
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		want    PerformanceConfig
+	}{
+		{"defaults", "", DefaultPerformance},
+		{"levels", "[performance]\nskills = 4\n", PerformanceConfig{Projects: 4, Flows: 8, Skills: 4, Uploads: 8}},
+		{"concurrency", "[performance]\nconcurrency = 2\nskills = 6\n", PerformanceConfig{Concurrency: 2, Projects: 2, Flows: 2, Skills: 6, Uploads: 2}},
+		{"connection cap", "[http]\nmax_conns_per_host = 6\n", PerformanceConfig{Projects: 4, Flows: 6, Skills: 6, Uploads: 6}},
+	} {
+		if err := os.WriteFile("newo.toml", []byte(tc.content), fsutil.FilePerm); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+		env, err := LoadEnv()
+		if err != nil {
+			t.Fatalf("%s: LoadEnv: %v", tc.name, err)
+		}
+		if env.Performance != tc.want {
+			t.Fatalf("%s: expected %+v, got %+v", tc.name, tc.want, env.Performance)
+		}
+	}
+
+	if got := DefaultPerformance.WithConcurrency(3); got.Projects != 3 || got.Skills != 3 || got.Uploads != 3 {
+		t.Fatalf("expected --concurrency to set every level, got %+v", got)
+	}
+
+	if err := os.WriteFile("newo.toml", []byte("[performance]\nflows = -1\n"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write toml: %v", err)
+	}
+	if _, err := LoadEnv(); err == nil || !strings.Contains(err.Error(), "performance.flows") {
+		t.Fatalf("expected a negative limit to be rejected, got %v", err)
+	}
+}
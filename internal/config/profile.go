@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProfileEnvVar names the environment variable that selects a profile when --profile is not given.
+const ProfileEnvVar = "NEWO_PROFILE"
+
+// Profile describes a [profiles.<name>] section of newo.toml. A profile targets another platform
+// environment: its settings replace the top-level ones while it is active, and when it lists
+// customers they replace the top-level [[customers]] entirely.
+type Profile struct {
+	BaseURL            string                 `toml:"base_url,omitempty"`
+	OutputRoot         *string                `toml:"output_root,omitempty"`
+	DefaultCustomerIDN string                 `toml:"default_customer,omitempty"`
+	Customers          []FileCustomerWritable `toml:"customers,omitempty"`
+}
+
+var (
+	profileMu sync.RWMutex
+	profile   string
+)
+
+// SetProfile selects the profile applied by LoadEnv and the other newo.toml readers.
+func SetProfile(name string) {
+	profileMu.Lock()
+	profile = strings.TrimSpace(name)
+	profileMu.Unlock()
+}
+
+// ActiveProfile returns the profile chosen with SetProfile, falling back to NEWO_PROFILE.
+func ActiveProfile() string {
+	profileMu.RLock()
+	name := profile
+	profileMu.RUnlock()
+	if name != "" {
+		return name
+	}
+	return strings.TrimSpace(os.Getenv(ProfileEnvVar))
+}
+
+// ApplyProfile overlays the named profile onto the top-level settings. An empty name is a no-op.
+func (cfg *TomlConfig) ApplyProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	selected, ok := cfg.Profiles[name]
+	if !ok {
+		return unknownProfileError(name, cfg.Profiles)
+	}
+	if base := strings.TrimSpace(selected.BaseURL); base != "" {
+		cfg.Defaults.BaseURL = base
+	}
+	if selected.OutputRoot != nil {
+		cfg.Defaults.OutputRoot = selected.OutputRoot
+	}
+	if len(selected.Customers) > 0 {
+		// The top-level default usually names a customer of the other environment.
+		cfg.Customers = selected.Customers
+		cfg.Defaults.DefaultCustomerIDN = ""
+	}
+	if customer := strings.TrimSpace(selected.DefaultCustomerIDN); customer != "" {
+		cfg.Defaults.DefaultCustomerIDN = customer
+	}
+	return nil
+}
+
+// ActiveCustomers returns the customer entries in effect for the active profile. Entries share
+// storage with cfg, so changes made through the returned slice are written back by SaveToml.
+func (cfg *TomlFile) ActiveCustomers() ([]FileCustomerWritable, error) {
+	name := ActiveProfile()
+	if name == "" {
+		return cfg.Customers, nil
+	}
+	selected, ok := cfg.Profiles[name]
+	if !ok {
+		return nil, unknownProfileError(name, cfg.Profiles)
+	}
+	if len(selected.Customers) > 0 {
+		return selected.Customers, nil
+	}
+	return cfg.Customers, nil
+}
+
+func unknownProfileError(name string, profiles map[string]Profile) error {
+	if len(profiles) == 0 {
+		return fmt.Errorf("profile %q is not defined: %s has no [profiles] section", name, TomlPath())
+	}
+	names := make([]string, 0, len(profiles))
+	for known := range profiles {
+		names = append(names, known)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("profile %q is not defined in %s (available: %s)", name, TomlPath(), strings.Join(names, ", "))
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const profileToml = `
+[defaults]
+base_url = "https://app.newo.ai"
+default_customer = "acme"
+
+[[customers]]
+idn = "acme"
+api_key = "prod-key"
+
+[profiles.staging]
+base_url = "https://staging.newo.ai"
+output_root = "newo_staging"
+
+[[profiles.staging.customers]]
+idn = "acme-stg"
+api_key = "staging-key"
+projects = [{ idn = "support" }]
+`
+
+func TestLoadEnvProfile(t *testing.T) {
+	dir := withTempDir(t)
+	withChdir(t, dir)
+	if err := os.WriteFile(DefaultTomlPath, []byte(profileToml), 0o644); err != nil {
+		t.Fatalf("write toml: %v", err)
+	}
+	t.Setenv("NEWO_BASE_URL", "")
+	t.Setenv(ProfileEnvVar, "")
+	t.Cleanup(func() { SetProfile("") })
+
+	env, err := LoadEnv()
+	if err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	if len(env.FileCustomers) != 1 || env.FileCustomers[0].IDN != "acme" || env.DefaultCustomer != "acme" {
+		t.Fatalf("expected top-level customers without a profile, got %+v", env.FileCustomers)
+	}
+
+	SetProfile("staging")
+	env, err = LoadEnv()
+	if err != nil {
+		t.Fatalf("LoadEnv staging: %v", err)
+	}
+	if env.Profile != "staging" || env.BaseURL != "https://staging.newo.ai" || env.OutputRoot != "newo_staging" {
+		t.Fatalf("unexpected staging env: profile=%q base=%q output=%q", env.Profile, env.BaseURL, env.OutputRoot)
+	}
+	if len(env.FileCustomers) != 1 || env.FileCustomers[0].IDN != "acme-stg" || env.FileCustomers[0].APIKey != "staging-key" {
+		t.Fatalf("expected staging customers, got %+v", env.FileCustomers)
+	}
+	if env.DefaultCustomer != "" {
+		t.Fatalf("expected production default customer to be dropped, got %q", env.DefaultCustomer)
+	}
+
+	if err := AddProjectToToml(DefaultTomlPath, "acme-stg", "support", "11111111-1111-1111-1111-111111111111"); err != nil {
+		t.Fatalf("AddProjectToToml: %v", err)
+	}
+	cfg, err := LoadToml(DefaultTomlPath)
+	if err != nil {
+		t.Fatalf("LoadToml: %v", err)
+	}
+	if got := cfg.Profiles["staging"].Customers[0].Projects[0].ID; got != "11111111-1111-1111-1111-111111111111" {
+		t.Fatalf("expected project ID recorded under the profile, got %q", got)
+	}
+	if len(cfg.Customers) != 1 || len(cfg.Customers[0].Projects) != 0 {
+		t.Fatalf("expected top-level customers untouched, got %+v", cfg.Customers)
+	}
+
+	SetProfile("")
+	t.Setenv(ProfileEnvVar, "prod")
+	if _, err := LoadEnv(); err == nil || !strings.Contains(err.Error(), `profile "prod" is not defined`) {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
@@ -13,11 +13,19 @@ import (
 
 // FileCustomerWritable mirrors FileCustomer but is writable back to TOML.
 type FileCustomerWritable struct {
-	IDN      string    `toml:"idn"`
-	Alias    string    `toml:"alias"`
-	APIKey   string    `toml:"api_key"`
-	Type     string    `toml:"type"`
-	Projects []Project `toml:"projects"`
+	IDN      string            `toml:"idn"`
+	Alias    string            `toml:"alias"`
+	APIKey   string            `toml:"api_key"`
+	Type     string            `toml:"type"`
+	Projects []Project         `toml:"projects"`
+	Vars     map[string]string `toml:"vars,omitempty"`
+	// Protected customers need their IDN confirmed before push, merge, deploy, or apply writes to them.
+	Protected bool `toml:"protected,omitempty"`
+	// BaseURL and APIVersion point the customer at a regional or self-hosted platform.
+	BaseURL    string `toml:"base_url,omitempty"`
+	APIVersion string `toml:"api_version,omitempty"`
+	// Group names the fleet the customer belongs to, selected with --group.
+	Group string `toml:"group,omitempty"`
 }
 
 // TomlFile represents the structure of newo.toml.
@@ -30,6 +38,8 @@ type TomlFile struct {
 		DefaultCustomerIDN string  `toml:"default_customer"`
 		ProjectID          string  `toml:"project_id"`
 		ProjectIDN         string  `toml:"project_idn"`
+		// AutoCRLF sets how line endings are compared and written, as git's core.autocrlf does.
+		AutoCRLF AutoCRLF `toml:"autocrlf,omitempty"`
 	} `toml:"defaults"`
 	Customers []FileCustomerWritable `toml:"customers"`
 	LLMs      []struct {
@@ -37,6 +47,11 @@ type TomlFile struct {
 		Model    string `toml:"model"`
 		APIKey   string `toml:"api_key"`
 	} `toml:"llms"`
+	Cache struct {
+		Blobs bool `toml:"blobs,omitempty"`
+	} `toml:"cache,omitempty"`
+	Notifications []Notification     `toml:"notifications,omitempty"`
+	Profiles      map[string]Profile `toml:"profiles,omitempty"`
 }
 
 // LoadToml loads newo.toml into a TomlFile structure.
@@ -89,9 +104,13 @@ func AddProjectToToml(path, customerIDN, projectIDN, projectID string) error {
 		return err
 	}
 
+	customers, err := cfg.ActiveCustomers()
+	if err != nil {
+		return err
+	}
 	idx := -1
-	for i := range cfg.Customers {
-		if strings.EqualFold(cfg.Customers[i].IDN, customerIDN) {
+	for i := range customers {
+		if strings.EqualFold(customers[i].IDN, customerIDN) {
 			idx = i
 			break
 		}
@@ -100,18 +119,18 @@ func AddProjectToToml(path, customerIDN, projectIDN, projectID string) error {
 		return fmt.Errorf("%w: %s", errCustomerNotFound, customerIDN)
 	}
 
-	projects := cfg.Customers[idx].Projects
+	projects := customers[idx].Projects
 	updated := false
 	for i := range projects {
 		if strings.EqualFold(projects[i].IDN, projectIDN) {
-			cfg.Customers[idx].Projects[i].ID = projectID
+			customers[idx].Projects[i].ID = projectID
 			updated = true
 			break
 		}
 	}
 
 	if !updated {
-		cfg.Customers[idx].Projects = append(cfg.Customers[idx].Projects, Project{IDN: projectIDN, ID: projectID})
+		customers[idx].Projects = append(customers[idx].Projects, Project{IDN: projectIDN, ID: projectID})
 	}
 
 	return SaveToml(path, cfg)
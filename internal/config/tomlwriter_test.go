@@ -70,6 +70,13 @@ func TestAddProjectToToml(t *testing.T) {
 
   [[customers.projects]]
   idn = "existing"
+
+[cache]
+blobs = true
+
+[[notifications]]
+type = "slack"
+url = "${SLACK_WEBHOOK}"
 `
 	dir := t.TempDir()
 	path := filepath.Join(dir, "cfg.toml")
@@ -91,6 +98,9 @@ func TestAddProjectToToml(t *testing.T) {
 	if cfg.Customers[0].Projects[1].ID != "uuid-123" {
 		t.Fatalf("missing project id: %#v", cfg.Customers[0].Projects[1])
 	}
+	if !cfg.Cache.Blobs || len(cfg.Notifications) != 1 || cfg.Notifications[0].URL != "${SLACK_WEBHOOK}" {
+		t.Fatalf("expected cache and notification sections to survive the rewrite: %#v", cfg)
+	}
 
 	// Update existing.
 	if err := AddProjectToToml(path, "cust", "existing", "uuid-existing"); err != nil {
@@ -104,3 +114,26 @@ func TestAddProjectToToml(t *testing.T) {
 		t.Fatalf("expected updated ID, got %#v", cfg.Customers[0].Projects[0])
 	}
 }
+
+func TestTomlAutoCRLFKeepsBothForms(t *testing.T) {
+	dir := t.TempDir()
+	for literal, want := range map[string]AutoCRLF{"true": "true", `"input"`: "input"} {
+		path := filepath.Join(dir, "crlf.toml")
+		if err := os.WriteFile(path, []byte("[defaults]\nautocrlf = "+literal+"\n"), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+		cfg, err := LoadToml(path)
+		if err != nil {
+			t.Fatalf("LoadToml(%s): %v", literal, err)
+		}
+		if cfg.Defaults.AutoCRLF != want {
+			t.Fatalf("expected autocrlf %q, got %q", want, cfg.Defaults.AutoCRLF)
+		}
+		if err := SaveToml(path, cfg); err != nil {
+			t.Fatalf("SaveToml: %v", err)
+		}
+		if saved, err := LoadToml(path); err != nil || saved.Defaults.AutoCRLF != want {
+			t.Fatalf("expected autocrlf to survive a rewrite, got %q, %v", saved.Defaults.AutoCRLF, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ConfigEnvVar names the environment variable that points at a newo.toml outside the current directory.
+const ConfigEnvVar = "NEWO_CONFIG"
+
+var (
+	tomlPathMu sync.RWMutex
+	tomlPath   = DefaultTomlPath
+)
+
+// TomlPath returns the path of the active newo.toml, relative to the workspace root.
+func TomlPath() string {
+	tomlPathMu.RLock()
+	defer tomlPathMu.RUnlock()
+	return tomlPath
+}
+
+// SetTomlPath selects the configuration file read by LoadEnv and the other newo.toml readers.
+func SetTomlPath(path string) {
+	if strings.TrimSpace(path) == "" {
+		path = DefaultTomlPath
+	}
+	tomlPathMu.Lock()
+	tomlPath = path
+	tomlPathMu.Unlock()
+}
+
+// ResolveToml locates the configuration file to use. An explicit path (the --config flag) wins,
+// then NEWO_CONFIG, then the nearest newo.toml in start or one of its parents, the way git finds
+// its repository. It returns an absolute path, or "" when no configuration exists.
+func ResolveToml(explicit, start string) (string, error) {
+	for _, candidate := range []struct{ path, source string }{
+		{strings.TrimSpace(explicit), "--config"},
+		{strings.TrimSpace(os.Getenv(ConfigEnvVar)), ConfigEnvVar},
+	} {
+		if candidate.path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(candidate.path)
+		if err != nil {
+			return "", fmt.Errorf("resolve %s %s: %w", candidate.source, candidate.path, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return "", fmt.Errorf("%s %s: %w", candidate.source, candidate.path, err)
+		}
+		if info.IsDir() {
+			abs = filepath.Join(abs, DefaultTomlPath)
+			if _, err := os.Stat(abs); err != nil {
+				return "", fmt.Errorf("%s %s: %w", candidate.source, candidate.path, err)
+			}
+		}
+		return abs, nil
+	}
+
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", fmt.Errorf("resolve working directory: %w", err)
+	}
+	for {
+		candidate := filepath.Join(dir, DefaultTomlPath)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("stat %s: %w", candidate, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
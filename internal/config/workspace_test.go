@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveToml(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "integrations", "acme", "flows")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	rootToml := filepath.Join(root, DefaultTomlPath)
+	if err := os.WriteFile(rootToml, []byte("[defaults]\n"), 0o644); err != nil {
+		t.Fatalf("write toml: %v", err)
+	}
+	t.Setenv(ConfigEnvVar, "")
+
+	got, err := ResolveToml("", nested)
+	if err != nil || got != rootToml {
+		t.Fatalf("expected upward search to find %s, got %q (%v)", rootToml, got, err)
+	}
+
+	other := filepath.Join(root, "staging.toml")
+	if err := os.WriteFile(other, []byte("[defaults]\n"), 0o644); err != nil {
+		t.Fatalf("write toml: %v", err)
+	}
+	t.Setenv(ConfigEnvVar, other)
+	if got, err := ResolveToml("", nested); err != nil || got != other {
+		t.Fatalf("expected %s from %s, got %q (%v)", other, ConfigEnvVar, got, err)
+	}
+	if got, err := ResolveToml(root, nested); err != nil || got != rootToml {
+		t.Fatalf("expected --config directory to resolve to %s, got %q (%v)", rootToml, got, err)
+	}
+	if _, err := ResolveToml(filepath.Join(root, "missing.toml"), nested); err == nil {
+		t.Fatalf("expected error for missing --config file")
+	}
+
+	t.Setenv(ConfigEnvVar, "")
+	if got, err := ResolveToml("", t.TempDir()); err != nil || got != "" {
+		t.Fatalf("expected no configuration, got %q (%v)", got, err)
+	}
+}
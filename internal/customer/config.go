@@ -2,10 +2,13 @@ package customer
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/state"
 )
 
 // Entry represents a single customer bootstrap configuration.
@@ -16,6 +19,11 @@ type Entry struct {
 	HintIDN    string
 	Alias      string
 	Type       string // Added to hold customer type
+	Vars       map[string]string
+	Protected  bool
+	BaseURL    string
+	APIVersion string
+	Group      string
 }
 
 // Configuration aggregates customer entries and default selection.
@@ -39,19 +47,68 @@ func (cfg Configuration) FindCustomer(id string) (*Entry, error) {
 	return nil, fmt.Errorf("customer %s not configured", token)
 }
 
+// InGroup lists the entries of the customers in the named group.
+func (cfg Configuration) InGroup(group string) []Entry {
+	var entries []Entry
+	for _, entry := range cfg.Entries {
+		if entry.Group != "" && strings.EqualFold(entry.Group, strings.TrimSpace(group)) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Groups lists the group names in use, sorted.
+func (cfg Configuration) Groups() []string {
+	seen := map[string]bool{}
+	var groups []string
+	for _, entry := range cfg.Entries {
+		if entry.Group != "" && !seen[strings.ToLower(entry.Group)] {
+			seen[strings.ToLower(entry.Group)] = true
+			groups = append(groups, entry.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
 // FromEnv parses customer configuration from environment variables.
 func FromEnv(env config.Env) (Configuration, error) {
 	var entries []Entry
 
 	// First, prioritize customers from the TOML file.
 	if len(env.FileCustomers) > 0 {
+		var store state.CredentialStore
 		for _, fileCustomer := range env.FileCustomers {
+			apiKey := fileCustomer.APIKey
+			if apiKey == "" {
+				if store == nil {
+					opened, err := OpenCredentialStore(env)
+					if err != nil {
+						return Configuration{}, err
+					}
+					store = opened
+				}
+				stored, err := store.Get(fileCustomer.IDN)
+				if err != nil {
+					if errors.Is(err, state.ErrCredentialNotFound) {
+						return Configuration{}, fmt.Errorf("no API key for customer %s: set api_key in newo.toml or run `newo auth login --customer %s`", fileCustomer.IDN, fileCustomer.IDN)
+					}
+					return Configuration{}, fmt.Errorf("read API key for customer %s from %s: %w", fileCustomer.IDN, store.Name(), err)
+				}
+				apiKey = stored
+			}
 			alias := strings.TrimSpace(fileCustomer.Alias)
 			entry := Entry{
-				APIKey:  fileCustomer.APIKey,
-				HintIDN: fileCustomer.IDN,
-				Alias:   alias,
-				Type:    fileCustomer.Type,
+				APIKey:     apiKey,
+				HintIDN:    fileCustomer.IDN,
+				Alias:      alias,
+				Type:       fileCustomer.Type,
+				Vars:       fileCustomer.Vars,
+				Protected:  fileCustomer.Protected,
+				BaseURL:    fileCustomer.BaseURL,
+				APIVersion: fileCustomer.APIVersion,
+				Group:      fileCustomer.Group,
 			}
 			if len(fileCustomer.Projects) == 0 {
 				entries = append(entries, entry)
@@ -101,6 +158,16 @@ func FromEnv(env config.Env) (Configuration, error) {
 	}, nil
 }
 
+// OpenCredentialStore returns the credential backend selected in the [credentials] section of newo.toml.
+func OpenCredentialStore(env config.Env) (state.CredentialStore, error) {
+	return state.OpenCredentialStore(state.CredentialConfig{
+		Backend: env.Credentials.Backend,
+		Get:     env.Credentials.Get,
+		Store:   env.Credentials.Store,
+		Erase:   env.Credentials.Erase,
+	})
+}
+
 func parseAPIKeysJSON(payload string) ([]Entry, error) {
 	var raw []json.RawMessage
 	if err := json.Unmarshal([]byte(payload), &raw); err != nil {
@@ -1,6 +1,7 @@
 package customer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/twinmind/newo-tool/internal/config"
@@ -87,3 +88,48 @@ func TestFromEnvPrecedence(t *testing.T) {
 		}
 	})
 }
+
+func TestFromEnvReadsCredentialStore(t *testing.T) {
+	env := config.Env{
+		FileCustomers: []config.FileCustomer{{IDN: "acme"}},
+		Credentials:   config.CredentialsConfig{Backend: "env"},
+	}
+	t.Setenv("NEWO_API_KEY_ACME", "stored-key")
+
+	cfg, err := FromEnv(env)
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if len(cfg.Entries) != 1 || cfg.Entries[0].APIKey != "stored-key" {
+		t.Fatalf("expected key from credential store, got %#v", cfg.Entries)
+	}
+
+	t.Setenv("NEWO_API_KEY_ACME", "")
+	if _, err := FromEnv(env); err == nil || !strings.Contains(err.Error(), "newo auth login --customer acme") {
+		t.Fatalf("expected login hint, got %v", err)
+	}
+}
+
+func TestFromEnvGroups(t *testing.T) {
+	env := config.Env{
+		FileCustomers: []config.FileCustomer{
+			{IDN: "shop-us", APIKey: "k1", Group: "us-prod", Projects: []config.Project{{IDN: "a"}, {IDN: "b"}}},
+			{IDN: "shop-eu", APIKey: "k2", Group: "eu-prod"},
+			{IDN: "sandbox", APIKey: "k3"},
+		},
+	}
+	cfg, err := FromEnv(env)
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	entries := cfg.InGroup("US-Prod")
+	if len(entries) != 2 || entries[0].HintIDN != "shop-us" || entries[1].ProjectIDN != "b" {
+		t.Fatalf("expected both project entries of shop-us, got %#v", entries)
+	}
+	if groups := cfg.Groups(); len(groups) != 2 || groups[0] != "eu-prod" || groups[1] != "us-prod" {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+	if entries := cfg.InGroup(""); len(entries) != 0 {
+		t.Fatalf("expected customers without a group to match no group, got %#v", entries)
+	}
+}
@@ -0,0 +1,710 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	skillsync "github.com/twinmind/newo-tool/internal/sync"
+)
+
+// ApplyClient extends DeployClient with the calls the sync service uses to change and remove existing
+// resources.
+type ApplyClient interface {
+	DeployClient
+	skillsync.SkillSyncClient
+}
+
+// ApplyRequest configures an apply run.
+type ApplyRequest struct {
+	Project            ProjectPlan
+	TargetCustomerIDN  string
+	TargetCustomerType string
+	OutputRoot         string
+	WorkspaceDir       string
+	Reporter           Reporter
+	// Vars are substituted into {{NAME}} placeholders of every skill script before upload.
+	Vars map[string]string
+	// Confirm is shown the plan before anything changes; nil applies it without asking.
+	Confirm ConfirmApplyFunc
+}
+
+// Kinds of resources an apply changes, besides the event and state kinds of the sync service.
+const (
+	KindProject = "project"
+	KindAgent   = "agent"
+	KindFlow    = "flow"
+	KindSkill   = "skill"
+)
+
+// applyDiffContext is the number of unchanged lines shown around each edit in a plan diff.
+const applyDiffContext = 3
+
+// Change is one remote change of an apply plan. Action is one of the sync service's create, update, or
+// delete actions; Path locates the resource as agent/flow/skill; Diff shows what an update edits.
+type Change struct {
+	Action string
+	Kind   string
+	Path   string
+	Diff   []diff.Line
+}
+
+// ApplyPlan lists the changes of an apply in the order they are made.
+type ApplyPlan struct {
+	ProjectIDN string
+	Changes    []Change
+}
+
+// Counts returns the number of resources the plan creates, updates, and deletes.
+func (p ApplyPlan) Counts() (created, updated, deleted int) {
+	for _, change := range p.Changes {
+		switch change.Action {
+		case skillsync.FlowChangeCreate:
+			created++
+		case skillsync.FlowChangeUpdate:
+			updated++
+		case skillsync.FlowChangeDelete:
+			deleted++
+		}
+	}
+	return created, updated, deleted
+}
+
+// ConfirmApplyFunc is shown the plan before anything changes and reports whether to go ahead.
+type ConfirmApplyFunc func(plan ApplyPlan) (bool, error)
+
+// ApplyResult summarises the changes made to bring the remote project in line with its manifest.
+// Declined is set when the plan was not confirmed, in which case nothing changed.
+type ApplyResult struct {
+	ProjectID      string
+	ProjectCreated bool
+	Plan           ApplyPlan
+	Declined       bool
+	Created        int
+	Updated        int
+	Deleted        int
+}
+
+// Changed reports whether the apply modified anything remotely.
+func (r ApplyResult) Changed() bool {
+	return r.ProjectCreated || r.Created != 0 || r.Updated != 0 || r.Deleted != 0
+}
+
+// Applier reconciles a remote project with a manifest: missing resources are created, drifted ones are
+// updated, and resources the manifest no longer declares are deleted. Projects that do not exist yet
+// are created by the deploy service; events and state fields are reconciled by the sync service.
+type Applier struct {
+	client   ApplyClient
+	deployer *Service
+	flows    *skillsync.SkillSyncService
+}
+
+// NewApplier constructs an applier.
+func NewApplier(client ApplyClient) *Applier {
+	return &Applier{
+		client:   client,
+		deployer: NewService(client),
+		flows:    skillsync.NewSkillSyncService(client, nil),
+	}
+}
+
+// applyRun collects the changes of one pass over the plan. A dry run only records them.
+type applyRun struct {
+	dryRun   bool
+	reporter Reporter
+	changes  []Change
+}
+
+var applyVerbs = map[string]string{
+	skillsync.FlowChangeCreate: "Creating",
+	skillsync.FlowChangeUpdate: "Updating",
+	skillsync.FlowChangeDelete: "Deleting",
+}
+
+func (r *applyRun) record(change Change) {
+	r.changes = append(r.changes, change)
+	if !r.dryRun {
+		r.reporter.Infof("%s %s %q", applyVerbs[change.Action], change.Kind, change.Path)
+	}
+}
+
+// Apply plans the changes, asks req.Confirm when set, and then reconciles the remote project and
+// rewrites its local files and state to match the manifest.
+func (a *Applier) Apply(ctx context.Context, req ApplyRequest) (ApplyResult, error) {
+	if a.client == nil {
+		return ApplyResult{}, fmt.Errorf("apply client is required")
+	}
+	if strings.TrimSpace(req.TargetCustomerIDN) == "" {
+		return ApplyResult{}, fmt.Errorf("target customer idn is required")
+	}
+	if strings.TrimSpace(req.OutputRoot) == "" {
+		return ApplyResult{}, fmt.Errorf("output root is required")
+	}
+	if req.Project.IDN == "" {
+		return ApplyResult{}, fmt.Errorf("project idn is required")
+	}
+	if req.WorkspaceDir == "" {
+		req.WorkspaceDir = "."
+	}
+	absWorkspace, err := filepath.Abs(req.WorkspaceDir)
+	if err != nil {
+		return ApplyResult{}, fmt.Errorf("resolve workspace dir: %w", err)
+	}
+	reporter := req.Reporter
+	if reporter == nil {
+		reporter = noopReporter{}
+	}
+
+	projectID, err := a.findProject(ctx, req.Project.IDN)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	deployReq := DeployRequest{
+		Project:            req.Project,
+		TargetCustomerIDN:  req.TargetCustomerIDN,
+		TargetCustomerType: req.TargetCustomerType,
+		OutputRoot:         req.OutputRoot,
+		WorkspaceDir:       req.WorkspaceDir,
+		Reporter:           reporter,
+		RollbackOnFailure:  true,
+		MergeState:         true,
+	}
+	if len(req.Vars) > 0 {
+		deployReq.Project = substituteVars(deployReq.Project, req.Vars, reporter)
+	}
+
+	result := ApplyResult{ProjectID: projectID, Plan: ApplyPlan{ProjectIDN: req.Project.IDN}}
+	if projectID == "" {
+		result.Plan.Changes = planNewProject(deployReq.Project)
+	} else {
+		dryRun := &applyRun{dryRun: true, reporter: reporter}
+		planned := clonePlan(deployReq.Project)
+		if _, err := a.reconcile(ctx, &planned, projectID, dryRun); err != nil {
+			return ApplyResult{}, err
+		}
+		result.Plan.Changes = dryRun.changes
+	}
+	if len(result.Plan.Changes) == 0 {
+		return result, nil
+	}
+	if req.Confirm != nil {
+		ok, err := req.Confirm(result.Plan)
+		if err != nil {
+			return ApplyResult{}, err
+		}
+		if !ok {
+			result.Declined = true
+			return result, nil
+		}
+	}
+
+	if projectID == "" {
+		deployed, err := a.deployer.Deploy(ctx, deployReq)
+		if err != nil {
+			return ApplyResult{}, err
+		}
+		result.ProjectID = deployed.ProjectID
+		result.ProjectCreated = true
+		result.Created = deployed.AgentsCreated + deployed.FlowsCreated + deployed.SkillsCreated + deployed.EventsCreated + deployed.StatesCreated
+		return result, nil
+	}
+
+	run := &applyRun{reporter: reporter}
+	projectData, err := a.reconcile(ctx, &deployReq.Project, projectID, run)
+	result.Created, result.Updated, result.Deleted = ApplyPlan{Changes: run.changes}.Counts()
+	if err != nil {
+		return result, err
+	}
+
+	previous, err := state.LoadProjectMap(req.TargetCustomerIDN)
+	if err != nil {
+		return result, err
+	}
+	stale, err := pruneLocal(deployReq, previous.Projects[req.Project.IDN], projectData, absWorkspace)
+	if err != nil {
+		return result, err
+	}
+	written := DeployResult{
+		ProjectID:   projectID,
+		ProjectSlug: req.Project.Slug,
+		TargetRoot:  fsutil.ExportProjectDir(req.OutputRoot, req.TargetCustomerType, req.TargetCustomerIDN, req.Project.Slug),
+		Hashes:      state.HashStore{},
+	}
+	if err := a.deployer.writeLocalArtifacts(projectID, deployReq, projectData, absWorkspace, &written); err != nil {
+		return result, err
+	}
+	if err := saveState(deployReq, projectData, written.Hashes, stale); err != nil {
+		return result, err
+	}
+
+	reporter.Successf("Applied project %s (%s): %d created, %d updated, %d deleted", req.Project.IDN, projectID, result.Created, result.Updated, result.Deleted)
+	return result, nil
+}
+
+func (a *Applier) findProject(ctx context.Context, projectIDN string) (string, error) {
+	projects, err := a.client.ListProjects(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list projects: %w", err)
+	}
+	for _, project := range projects {
+		if strings.EqualFold(strings.TrimSpace(project.IDN), projectIDN) {
+			return strings.TrimSpace(project.ID), nil
+		}
+	}
+	return "", nil
+}
+
+// planNewProject lists everything the deploy service creates for a project that does not exist yet.
+func planNewProject(plan ProjectPlan) []Change {
+	changes := []Change{{Action: skillsync.FlowChangeCreate, Kind: KindProject, Path: plan.IDN}}
+	for _, agentPlan := range plan.Agents {
+		changes = append(changes, Change{Action: skillsync.FlowChangeCreate, Kind: KindAgent, Path: agentPlan.IDN})
+		for _, flowPlan := range agentPlan.Flows {
+			changes = append(changes, planNewFlow(agentPlan.IDN, flowPlan)...)
+		}
+	}
+	return changes
+}
+
+// planNewFlow lists a flow that does not exist yet together with everything created inside it.
+func planNewFlow(agentIDN string, flowPlan FlowPlan) []Change {
+	flowPath := agentIDN + "/" + flowPlan.IDN
+	changes := []Change{{Action: skillsync.FlowChangeCreate, Kind: KindFlow, Path: flowPath}}
+	for _, skillPlan := range flowPlan.Skills {
+		changes = append(changes, Change{Action: skillsync.FlowChangeCreate, Kind: KindSkill, Path: flowPath + "/" + skillPlan.IDN})
+	}
+	for _, field := range flowPlan.States {
+		changes = append(changes, Change{Action: skillsync.FlowChangeCreate, Kind: skillsync.FlowChangeState, Path: flowPath + "/" + field.IDN})
+	}
+	for _, event := range flowPlan.Events {
+		changes = append(changes, Change{Action: skillsync.FlowChangeCreate, Kind: skillsync.FlowChangeEvent, Path: flowPath + "/" + event.IDN})
+	}
+	return changes
+}
+
+// reconcile walks the plan top-down. Remote agents and flows the plan does not declare are deleted
+// before anything is created, so IDNs can move between agents in a single apply.
+func (a *Applier) reconcile(ctx context.Context, plan *ProjectPlan, projectID string, run *applyRun) (state.ProjectData, error) {
+	remoteAgents, err := a.client.ListAgents(ctx, projectID)
+	if err != nil {
+		return state.ProjectData{}, fmt.Errorf("list agents: %w", err)
+	}
+
+	declaredAgents := map[string]bool{}
+	declaredFlows := map[string]bool{}
+	for _, agentPlan := range plan.Agents {
+		declaredAgents[strings.ToLower(agentPlan.IDN)] = true
+		for _, flowPlan := range agentPlan.Flows {
+			declaredFlows[strings.ToLower(agentPlan.IDN+"/"+flowPlan.IDN)] = true
+		}
+	}
+	agentsByIDN := map[string]platform.Agent{}
+	for _, remote := range remoteAgents {
+		if !declaredAgents[strings.ToLower(remote.IDN)] {
+			run.record(Change{Action: skillsync.FlowChangeDelete, Kind: KindAgent, Path: remote.IDN})
+			if !run.dryRun {
+				if err := a.client.DeleteAgent(ctx, remote.ID); err != nil {
+					return state.ProjectData{}, fmt.Errorf("delete agent %s: %w", remote.IDN, err)
+				}
+			}
+			continue
+		}
+		agentsByIDN[strings.ToLower(remote.IDN)] = remote
+		for _, flow := range remote.Flows {
+			if declaredFlows[strings.ToLower(remote.IDN+"/"+flow.IDN)] {
+				continue
+			}
+			run.record(Change{Action: skillsync.FlowChangeDelete, Kind: KindFlow, Path: remote.IDN + "/" + flow.IDN})
+			if !run.dryRun {
+				if err := a.client.DeleteFlow(ctx, flow.ID); err != nil {
+					return state.ProjectData{}, fmt.Errorf("delete flow %s: %w", flow.IDN, err)
+				}
+			}
+		}
+	}
+
+	projectData := state.ProjectData{
+		ProjectID:  projectID,
+		ProjectIDN: plan.IDN,
+		Path:       plan.Slug,
+		Agents:     map[string]state.AgentData{},
+	}
+	for idx := range plan.Agents {
+		agentPlan := &plan.Agents[idx]
+		remote, exists := agentsByIDN[strings.ToLower(agentPlan.IDN)]
+		agentID := strings.TrimSpace(remote.ID)
+		if !exists {
+			run.record(Change{Action: skillsync.FlowChangeCreate, Kind: KindAgent, Path: agentPlan.IDN})
+			if run.dryRun {
+				for _, flowPlan := range agentPlan.Flows {
+					run.changes = append(run.changes, planNewFlow(agentPlan.IDN, flowPlan)...)
+				}
+				continue
+			}
+			resp, err := a.client.CreateAgent(ctx, projectID, platform.CreateAgentRequest{
+				IDN:         agentPlan.IDN,
+				Title:       agentPlan.Title,
+				Description: agentPlan.Description,
+			})
+			if err != nil {
+				return state.ProjectData{}, fmt.Errorf("create agent %s: %w", agentPlan.IDN, err)
+			}
+			agentID = strings.TrimSpace(resp.ID)
+			if agentID == "" {
+				return state.ProjectData{}, fmt.Errorf("agent %s: empty id", agentPlan.IDN)
+			}
+		}
+
+		remoteFlows := map[string]platform.Flow{}
+		for _, flow := range remote.Flows {
+			remoteFlows[strings.ToLower(flow.IDN)] = flow
+		}
+		agentData := state.AgentData{
+			ID:          agentID,
+			Title:       agentPlan.Title,
+			Description: agentPlan.Description,
+			Flows:       map[string]state.FlowData{},
+		}
+		for fidx := range agentPlan.Flows {
+			flowPlan := &agentPlan.Flows[fidx]
+			if flow, ok := remoteFlows[strings.ToLower(flowPlan.IDN)]; ok {
+				flowPlan.CreatedFlowID = strings.TrimSpace(flow.ID)
+				continue
+			}
+			if run.dryRun {
+				run.changes = append(run.changes, planNewFlow(agentPlan.IDN, *flowPlan)...)
+				continue
+			}
+			run.record(Change{Action: skillsync.FlowChangeCreate, Kind: KindFlow, Path: agentPlan.IDN + "/" + flowPlan.IDN})
+			resp, err := a.client.CreateFlow(ctx, agentID, platform.CreateFlowRequest{
+				IDN:         flowPlan.IDN,
+				Title:       flowPlan.Title,
+				Description: flowPlan.Description,
+			})
+			if err != nil {
+				return state.ProjectData{}, fmt.Errorf("create flow %s: %w", flowPlan.IDN, err)
+			}
+			flowPlan.CreatedFlowID = strings.TrimSpace(resp.ID)
+		}
+		if run.dryRun {
+			for _, flowPlan := range agentPlan.Flows {
+				if flow, ok := remoteFlows[strings.ToLower(flowPlan.IDN)]; ok {
+					if err := a.reconcileFlow(ctx, agentPlan.IDN, &flowPlan, flow, true, run); err != nil {
+						return state.ProjectData{}, err
+					}
+				}
+			}
+			continue
+		}
+		if err := a.deployer.populateFlowIDs(ctx, projectID, *agentPlan); err != nil {
+			return state.ProjectData{}, err
+		}
+
+		for fidx := range agentPlan.Flows {
+			flowPlan := &agentPlan.Flows[fidx]
+			remoteFlow, existed := remoteFlows[strings.ToLower(flowPlan.IDN)]
+			if !existed {
+				remoteFlow = platform.Flow{IDN: flowPlan.IDN, Title: flowPlan.Title, Description: flowPlan.Description}
+			}
+			if err := a.reconcileFlow(ctx, agentPlan.IDN, flowPlan, remoteFlow, existed, run); err != nil {
+				return state.ProjectData{}, err
+			}
+			agentData.Flows[flowPlan.IDN] = flowStateData(*flowPlan)
+		}
+		projectData.Agents[agentPlan.IDN] = agentData
+	}
+	return projectData, nil
+}
+
+// reconcileFlow brings the settings, skills, events, and state fields of one flow in line with its plan.
+// Settings left blank in the plan keep the remote values, which are recorded back into the plan. A flow
+// created by this run only reports the changes of an existing flow when it was not just created.
+func (a *Applier) reconcileFlow(ctx context.Context, agentIDN string, flowPlan *FlowPlan, remote platform.Flow, existed bool, run *applyRun) error {
+	flowID := flowPlan.CreatedFlowID
+	flowPath := agentIDN + "/" + flowPlan.IDN
+	settings := platform.UpdateFlowRequest{
+		IDN:               flowPlan.IDN,
+		Title:             flowPlan.Title,
+		Description:       flowPlan.Description,
+		DefaultRunnerType: fallback(flowPlan.DefaultRunnerType, remote.DefaultRunnerType),
+		DefaultModel:      effectiveModel(remote.DefaultModel, flowPlan.DefaultModel),
+	}
+	if settings.Title != remote.Title || settings.Description != remote.Description ||
+		settings.DefaultRunnerType != remote.DefaultRunnerType || settings.DefaultModel != remote.DefaultModel {
+		if existed {
+			run.record(Change{
+				Action: skillsync.FlowChangeUpdate,
+				Kind:   KindFlow,
+				Path:   flowPath,
+				Diff: diff.Generate(
+					flowSettingsText(remote.Title, remote.Description, remote.DefaultRunnerType, remote.DefaultModel),
+					flowSettingsText(settings.Title, settings.Description, settings.DefaultRunnerType, settings.DefaultModel),
+					applyDiffContext),
+			})
+		}
+		if !run.dryRun {
+			if err := a.client.UpdateFlow(ctx, flowID, settings); err != nil {
+				return fmt.Errorf("update flow %s: %w", flowPlan.IDN, err)
+			}
+		}
+	}
+	flowPlan.DefaultRunnerType = settings.DefaultRunnerType
+	flowPlan.DefaultModel = settings.DefaultModel
+
+	remoteSkills, err := a.client.ListFlowSkills(ctx, flowID)
+	if err != nil {
+		return fmt.Errorf("list skills for %s: %w", flowPlan.IDN, err)
+	}
+	declared := map[string]bool{}
+	for _, skillPlan := range flowPlan.Skills {
+		declared[strings.ToLower(skillPlan.IDN)] = true
+	}
+	skillsByIDN := map[string]platform.Skill{}
+	for _, skill := range remoteSkills {
+		if declared[strings.ToLower(skill.IDN)] {
+			skillsByIDN[strings.ToLower(skill.IDN)] = skill
+			continue
+		}
+		run.record(Change{Action: skillsync.FlowChangeDelete, Kind: KindSkill, Path: flowPath + "/" + skill.IDN})
+		if !run.dryRun {
+			if err := a.client.DeleteSkill(ctx, skill.ID); err != nil {
+				return fmt.Errorf("delete skill %s: %w", skill.IDN, err)
+			}
+		}
+	}
+	for sidx := range flowPlan.Skills {
+		skillPlan := &flowPlan.Skills[sidx]
+		skillPath := flowPath + "/" + skillPlan.IDN
+		skill, ok := skillsByIDN[strings.ToLower(skillPlan.IDN)]
+		if !ok {
+			run.record(Change{Action: skillsync.FlowChangeCreate, Kind: KindSkill, Path: skillPath})
+			if !run.dryRun {
+				if err := a.deployer.createSkill(ctx, agentIDN, flowPlan, skillPlan, noopReporter{}); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		skillPlan.CreatedSkillID = strings.TrimSpace(skill.ID)
+		update := platform.UpdateSkillRequest{
+			ID:           skill.ID,
+			IDN:          skillPlan.IDN,
+			Title:        skillPlan.Title,
+			PromptScript: string(skillPlan.Script),
+			RunnerType:   fallback(skillPlan.RunnerType, skill.RunnerType),
+			Model:        effectiveModel(skill.Model, skillPlan.Model),
+			Parameters:   convertParametersToPlatform(skillPlan.Parameters),
+			Path:         skill.Path,
+		}
+		skillPlan.RunnerType = update.RunnerType
+		skillPlan.Model = update.Model
+		if skillMatches(skill, update) {
+			continue
+		}
+		run.record(Change{Action: skillsync.FlowChangeUpdate, Kind: KindSkill, Path: skillPath, Diff: skillDiff(skill, update)})
+		if !run.dryRun {
+			if err := a.client.UpdateSkill(ctx, skill.ID, update); err != nil {
+				return fmt.Errorf("update skill %s: %w", skillPlan.IDN, err)
+			}
+		}
+	}
+
+	remoteEvents, err := a.client.ListFlowEvents(ctx, flowID)
+	if err != nil {
+		return fmt.Errorf("list events for %s: %w", flowPlan.IDN, err)
+	}
+	remoteStates, err := a.client.ListFlowStates(ctx, flowID)
+	if err != nil {
+		return fmt.Errorf("list states for %s: %w", flowPlan.IDN, err)
+	}
+	data := flowStateData(*flowPlan)
+	plan := skillsync.PlanFlowDefinition(data.Events, data.StateFields, remoteEvents, remoteStates)
+	for _, change := range plan.Changes() {
+		run.record(Change{Action: change.Action, Kind: change.Kind, Path: flowPath + "/" + change.IDN})
+	}
+	if run.dryRun {
+		return nil
+	}
+	stateIDs, err := a.flows.ApplyFlowDefinition(ctx, flowID, plan)
+	if err != nil {
+		return fmt.Errorf("flow %s: %w", flowPlan.IDN, err)
+	}
+	for sidx := range flowPlan.States {
+		flowPlan.States[sidx].CreatedStateID = stateIDs[flowPlan.States[sidx].IDN]
+	}
+	return nil
+}
+
+// pruneLocal removes the files of flows and skills that the previous state recorded but the applied
+// project no longer has, and returns their workspace-relative paths.
+func pruneLocal(req DeployRequest, previous, current state.ProjectData, workspace string) ([]string, error) {
+	kept := map[string]map[string]bool{}
+	for agentIDN, agent := range current.Agents {
+		for flowIDN, flow := range agent.Flows {
+			dir := fsutil.ExportFlowDir(req.OutputRoot, req.TargetCustomerType, req.TargetCustomerIDN, req.Project.Slug, agentIDN, flowIDN)
+			kept[dir] = map[string]bool{}
+			for skillIDN := range flow.Skills {
+				kept[dir][skillIDN] = true
+			}
+		}
+	}
+
+	var removed []string
+	for agentIDN, agent := range previous.Agents {
+		for flowIDN, flow := range agent.Flows {
+			dir := fsutil.ExportFlowDir(req.OutputRoot, req.TargetCustomerType, req.TargetCustomerIDN, req.Project.Slug, agentIDN, flowIDN)
+			var paths []string
+			if skills, ok := kept[dir]; !ok {
+				paths = append(paths, dir)
+			} else {
+				for skillIDN, meta := range flow.Skills {
+					if skills[skillIDN] {
+						continue
+					}
+					ext := platform.ScriptExtension(meta.RunnerType)
+					if meta.Path != "" {
+						ext = scriptExtension(meta.Path)
+					}
+					paths = append(paths, filepath.Join(dir, skillIDN+"."+ext), filepath.Join(dir, skillIDN+fsutil.SkillMetaFileExt))
+				}
+			}
+			for _, path := range paths {
+				if err := os.RemoveAll(path); err != nil {
+					return nil, fmt.Errorf("remove %s: %w", path, err)
+				}
+				rel, err := workspaceRelative(workspace, path)
+				if err != nil {
+					return nil, err
+				}
+				removed = append(removed, rel)
+			}
+		}
+	}
+	return removed, nil
+}
+
+// flowStateData converts a reconciled flow plan into its project map entry.
+func flowStateData(flowPlan FlowPlan) state.FlowData {
+	data := state.FlowData{
+		ID:          flowPlan.CreatedFlowID,
+		Title:       flowPlan.Title,
+		Description: flowPlan.Description,
+		RunnerType:  flowPlan.DefaultRunnerType,
+		Model: map[string]string{
+			"model_idn":    flowPlan.DefaultModel.ModelIDN,
+			"provider_idn": flowPlan.DefaultModel.ProviderIDN,
+		},
+		Skills:      map[string]state.SkillMetadataInfo{},
+		Events:      []state.FlowEventInfo{},
+		StateFields: []state.FlowStateInfo{},
+	}
+	for _, skillPlan := range flowPlan.Skills {
+		data.Skills[skillPlan.IDN] = state.SkillMetadataInfo{
+			ID:         skillPlan.CreatedSkillID,
+			IDN:        skillPlan.IDN,
+			Title:      skillPlan.Title,
+			RunnerType: skillPlan.RunnerType,
+			Model: map[string]string{
+				"model_idn":    skillPlan.Model.ModelIDN,
+				"provider_idn": skillPlan.Model.ProviderIDN,
+			},
+			Parameters: convertParametersToState(skillPlan.Parameters),
+			Path:       skillPlan.ScriptRelPath,
+		}
+	}
+	for _, event := range flowPlan.Events {
+		data.Events = append(data.Events, state.FlowEventInfo{
+			IDN:            event.IDN,
+			Title:          event.Title,
+			Description:    event.Description,
+			SkillSelector:  event.SkillSelector,
+			SkillIDN:       event.SkillIDN,
+			StateIDN:       event.StateIDN,
+			IntegrationIDN: event.IntegrationIDN,
+			ConnectorIDN:   event.ConnectorIDN,
+			InterruptMode:  event.InterruptMode,
+		})
+	}
+	for _, field := range flowPlan.States {
+		data.StateFields = append(data.StateFields, state.FlowStateInfo{
+			ID:           field.CreatedStateID,
+			IDN:          field.IDN,
+			Title:        field.Title,
+			DefaultValue: field.DefaultValue,
+			Scope:        field.Scope,
+		})
+	}
+	return data
+}
+
+// effectiveModel overlays the non-blank fields of the declared model on the remote one.
+func effectiveModel(remote, declared platform.ModelConfig) platform.ModelConfig {
+	return platform.ModelConfig{
+		ModelIDN:    fallback(declared.ModelIDN, remote.ModelIDN),
+		ProviderIDN: fallback(declared.ProviderIDN, remote.ProviderIDN),
+	}
+}
+
+func skillMatches(remote platform.Skill, want platform.UpdateSkillRequest) bool {
+	if remote.Title != want.Title || remote.PromptScript != want.PromptScript ||
+		remote.RunnerType != want.RunnerType || remote.Model != want.Model ||
+		len(remote.Parameters) != len(want.Parameters) {
+		return false
+	}
+	for idx := range remote.Parameters {
+		if remote.Parameters[idx] != want.Parameters[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// skillDiff shows the script edit of a skill update, or its settings when only those change.
+func skillDiff(remote platform.Skill, want platform.UpdateSkillRequest) []diff.Line {
+	if remote.PromptScript != want.PromptScript {
+		return diff.Generate([]byte(remote.PromptScript), []byte(want.PromptScript), applyDiffContext)
+	}
+	return diff.Generate(
+		skillSettingsText(remote.Title, remote.RunnerType, remote.Model, remote.Parameters),
+		skillSettingsText(want.Title, want.RunnerType, want.Model, want.Parameters),
+		applyDiffContext)
+}
+
+func skillSettingsText(title, runnerType string, model platform.ModelConfig, params []platform.SkillParameter) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "title: %s\nrunner_type: %s\nmodel: %s/%s\n", title, runnerType, model.ProviderIDN, model.ModelIDN)
+	for _, p := range params {
+		fmt.Fprintf(&b, "parameter: %s=%s\n", p.Name, p.DefaultValue)
+	}
+	return []byte(b.String())
+}
+
+func flowSettingsText(title, description, runnerType string, model platform.ModelConfig) []byte {
+	return []byte(fmt.Sprintf("title: %s\ndescription: %s\ndefault_runner_type: %s\ndefault_model: %s/%s\n",
+		title, description, runnerType, model.ProviderIDN, model.ModelIDN))
+}
+
+// clonePlan copies the agent, flow, and skill slices of a plan so a dry run can fill in identifiers
+// without touching the original.
+func clonePlan(plan ProjectPlan) ProjectPlan {
+	agents := make([]AgentPlan, len(plan.Agents))
+	for ai, agent := range plan.Agents {
+		flows := make([]FlowPlan, len(agent.Flows))
+		for fi, flow := range agent.Flows {
+			flow.Skills = append([]SkillPlan(nil), flow.Skills...)
+			flow.Events = append([]FlowEventPlan(nil), flow.Events...)
+			flow.States = append([]FlowStatePlan(nil), flow.States...)
+			flows[fi] = flow
+		}
+		agent.Flows = flows
+		agents[ai] = agent
+	}
+	plan.Agents = agents
+	return plan
+}
@@ -0,0 +1,352 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+type fakeApplyClient struct {
+	fakeDeployClient
+	agents []platform.Agent
+	skills map[string][]platform.Skill
+	states map[string][]platform.FlowState
+	calls  []string
+}
+
+func (f *fakeApplyClient) ListAgents(context.Context, string) ([]platform.Agent, error) {
+	return f.agents, nil
+}
+
+func (f *fakeApplyClient) DeleteAgent(_ context.Context, id string) error {
+	f.calls = append(f.calls, "delete agent "+id)
+	return nil
+}
+
+func (f *fakeApplyClient) DeleteFlow(_ context.Context, id string) error {
+	f.calls = append(f.calls, "delete flow "+id)
+	return nil
+}
+
+func (f *fakeApplyClient) CreateFlow(_ context.Context, agentID string, payload platform.CreateFlowRequest) (platform.CreateFlowResponse, error) {
+	f.calls = append(f.calls, "create flow "+payload.IDN+" in "+agentID)
+	return platform.CreateFlowResponse{ID: f.id("flow")}, nil
+}
+
+func (f *fakeApplyClient) UpdateFlow(_ context.Context, flowID string, _ platform.UpdateFlowRequest) error {
+	f.calls = append(f.calls, "update flow "+flowID)
+	return nil
+}
+
+func (f *fakeApplyClient) ListFlowSkills(_ context.Context, flowID string) ([]platform.Skill, error) {
+	return f.skills[flowID], nil
+}
+
+func (f *fakeApplyClient) GetSkill(context.Context, string) (platform.Skill, error) {
+	return platform.Skill{}, nil
+}
+
+func (f *fakeApplyClient) CreateSkill(_ context.Context, flowID string, payload platform.CreateSkillRequest) (platform.CreateSkillResponse, error) {
+	f.calls = append(f.calls, "create skill "+payload.IDN+" in "+flowID)
+	return platform.CreateSkillResponse{ID: f.id("skill")}, nil
+}
+
+func (f *fakeApplyClient) UpdateSkill(_ context.Context, skillID string, _ platform.UpdateSkillRequest) error {
+	f.calls = append(f.calls, "update skill "+skillID)
+	return nil
+}
+
+func (f *fakeApplyClient) DeleteSkill(_ context.Context, skillID string) error {
+	f.calls = append(f.calls, "delete skill "+skillID)
+	return nil
+}
+
+func (f *fakeApplyClient) PublishFlow(context.Context, string, platform.PublishFlowRequest) error {
+	return nil
+}
+
+func (f *fakeApplyClient) ListFlowEvents(context.Context, string) ([]platform.FlowEvent, error) {
+	return nil, nil
+}
+
+func (f *fakeApplyClient) CreateFlowEvent(_ context.Context, flowID string, payload platform.CreateFlowEventRequest) (platform.CreateFlowEventResponse, error) {
+	f.calls = append(f.calls, "create event "+payload.IDN+" in "+flowID)
+	return platform.CreateFlowEventResponse{ID: f.id("event")}, nil
+}
+
+func (f *fakeApplyClient) UpdateFlowEvent(context.Context, string, platform.UpdateFlowEventRequest) error {
+	return nil
+}
+
+func (f *fakeApplyClient) DeleteFlowEvent(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeApplyClient) ListFlowStates(_ context.Context, flowID string) ([]platform.FlowState, error) {
+	return f.states[flowID], nil
+}
+
+func (f *fakeApplyClient) UpdateFlowState(context.Context, string, platform.UpdateFlowStateRequest) error {
+	return nil
+}
+
+func (f *fakeApplyClient) DeleteFlowState(_ context.Context, stateID string) error {
+	f.calls = append(f.calls, "delete state "+stateID)
+	return nil
+}
+
+func writeManifest(t *testing.T, dir, manifest string, scripts map[string]string) string {
+	t.Helper()
+	for name, content := range scripts {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	path := filepath.Join(dir, "newo.project.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeManifest(t, dir, `idn: Demo
+agents:
+  - idn: agent
+    flows:
+      - idn: MainFlow
+        default_runner_type: guidance
+        skills:
+          - idn: Greet
+            runner_type: nsl
+            script: skills/Greet.nsl
+            parameters: [{name: user_name}]
+        states:
+          - {idn: step, scope: user}
+`, map[string]string{"skills/Greet.nsl": "Hi"})
+
+	plan, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if plan.IDN != "Demo" || plan.Title != "Demo" || plan.Slug != "demo" {
+		t.Fatalf("unexpected project: %+v", plan)
+	}
+	skill := plan.Agents[0].Flows[0].Skills[0]
+	if string(skill.Script) != "Hi" || skill.ScriptRelPath != "flows/MainFlow/Greet.nsl" || skill.Parameters[0].Name != "user_name" {
+		t.Fatalf("unexpected skill: %+v", skill)
+	}
+
+	for name, manifest := range map[string]string{
+		"unknown key":     "idn: Demo\nagnets: []\n",
+		"duplicate flow":  "idn: Demo\nagents:\n  - idn: a\n    flows: [{idn: F}]\n  - idn: b\n    flows: [{idn: F}]\n",
+		"missing script":  "idn: Demo\nagents:\n  - idn: a\n    flows:\n      - idn: F\n        skills: [{idn: S, script: missing.nsl}]\n",
+		"missing project": "agents: []\n",
+	} {
+		if _, err := LoadManifest(writeManifest(t, t.TempDir(), manifest, nil)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestApplyReconcilesExistingProject(t *testing.T) {
+	chdirTemp(t)
+
+	client := &fakeApplyClient{
+		fakeDeployClient: fakeDeployClient{projectsExist: []platform.Project{{ID: "p1", IDN: "demo"}}},
+		agents: []platform.Agent{
+			{ID: "a1", IDN: "agent", Flows: []platform.Flow{
+				{ID: "f1", IDN: "flow", Title: "flow"},
+				{ID: "f2", IDN: "retired", Title: "retired"},
+			}},
+			{ID: "a2", IDN: "legacy"},
+		},
+		skills: map[string][]platform.Skill{"f1": {
+			{ID: "s1", IDN: "first", Title: "first", PromptScript: "a"},
+			{ID: "s2", IDN: "second", Title: "second", PromptScript: "old"},
+			{ID: "s3", IDN: "gone", Title: "gone"},
+		}},
+		states: map[string][]platform.FlowState{"f1": {{ID: "st1", IDN: "obsolete"}}},
+	}
+	if err := state.SaveProjectMap("target", state.ProjectMap{Projects: map[string]state.ProjectData{
+		"demo": {ProjectID: "p1", ProjectIDN: "demo", Path: "demo", Agents: map[string]state.AgentData{
+			"agent": {ID: "a1", Flows: map[string]state.FlowData{
+				"flow": {ID: "f1", Skills: map[string]state.SkillMetadataInfo{"gone": {ID: "s3", IDN: "gone", RunnerType: "nsl"}}},
+			}},
+		}},
+	}}); err != nil {
+		t.Fatalf("save project map: %v", err)
+	}
+	stalePath := fsutil.ExportSkillScriptPath("out", "integration", "target", "demo", "agent", "flow", "gone.nsl")
+	if err := os.MkdirAll(filepath.Dir(stalePath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(stalePath, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write stale skill: %v", err)
+	}
+
+	plan := testProjectPlan()
+	plan.Agents[0].Flows[0].Title = "flow"
+	plan.Agents[0].Flows[0].Skills[0].Title = "first"
+	plan.Agents[0].Flows[0].Skills[1].Title = "second"
+	plan.Agents[0].Flows[0].Skills = append(plan.Agents[0].Flows[0].Skills, SkillPlan{IDN: "third", Title: "third", ScriptRelPath: "third.nsl", Script: []byte("c")})
+	plan.Agents[0].Flows[0].Events = []FlowEventPlan{{IDN: "user_message", SkillIDN: "first"}}
+
+	var planned ApplyPlan
+	result, err := NewApplier(client).Apply(context.Background(), ApplyRequest{
+		Project:            plan,
+		TargetCustomerIDN:  "target",
+		TargetCustomerType: "integration",
+		OutputRoot:         "out",
+		Confirm: func(plan ApplyPlan) (bool, error) {
+			if len(client.calls) != 0 {
+				t.Fatalf("expected no remote changes before confirmation, got %v", client.calls)
+			}
+			planned = plan
+			return true, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	want := []string{
+		"delete flow f2",
+		"delete agent a2",
+		"delete skill s3",
+		"update skill s2",
+		"create skill third in f1",
+		"delete state st1",
+		"create event user_message in f1",
+	}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("unexpected calls:\n got %v\nwant %v", client.calls, want)
+	}
+	if result.ProjectCreated || result.Created != 2 || result.Updated != 1 || result.Deleted != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if created, updated, deleted := planned.Counts(); created != 2 || updated != 1 || deleted != 4 {
+		t.Fatalf("expected the plan to match the applied changes, got %+v", planned.Changes)
+	}
+	for _, change := range planned.Changes {
+		if change.Kind == KindSkill && change.Action == "update" && (change.Path != "agent/flow/second" || len(change.Diff) == 0) {
+			t.Fatalf("expected a script diff for agent/flow/second, got %+v", change)
+		}
+	}
+
+	if _, err := os.Stat(stalePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the deleted skill's script to be removed, got %v", err)
+	}
+	projectMap, err := state.LoadProjectMap("target")
+	if err != nil {
+		t.Fatalf("load project map: %v", err)
+	}
+	skills := projectMap.Projects["demo"].Agents["agent"].Flows["flow"].Skills
+	if len(skills) != 3 || skills["second"].ID != "s2" || skills["third"].ID == "" {
+		t.Fatalf("unexpected recorded skills: %+v", skills)
+	}
+	hashes, err := state.LoadHashes("target")
+	if err != nil {
+		t.Fatalf("load hashes: %v", err)
+	}
+	for path := range hashes {
+		if strings.Contains(path, "gone") {
+			t.Fatalf("expected no hash for the deleted skill, got %s", path)
+		}
+	}
+}
+
+func TestApplyDeclinedPlanChangesNothing(t *testing.T) {
+	chdirTemp(t)
+
+	client := &fakeApplyClient{}
+	result, err := NewApplier(client).Apply(context.Background(), ApplyRequest{
+		Project:           testProjectPlan(),
+		TargetCustomerIDN: "target",
+		OutputRoot:        "out",
+		Confirm:           func(ApplyPlan) (bool, error) { return false, nil },
+	})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if !result.Declined || result.Changed() || len(client.created) != 0 {
+		t.Fatalf("expected nothing to change, got %+v (created %v)", result, client.created)
+	}
+	var paths []string
+	for _, change := range result.Plan.Changes {
+		paths = append(paths, change.Action+" "+change.Kind+" "+change.Path)
+	}
+	want := []string{"create project demo", "create agent agent", "create flow agent/flow", "create skill agent/flow/first", "create skill agent/flow/second"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("unexpected plan:\n got %v\nwant %v", paths, want)
+	}
+}
+
+func TestImportRoundTripsThroughApply(t *testing.T) {
+	chdirTemp(t)
+
+	client := &fakeApplyClient{
+		fakeDeployClient: fakeDeployClient{projectsExist: []platform.Project{{ID: "p1", IDN: "demo", Title: "Demo"}}},
+		agents: []platform.Agent{{ID: "a1", IDN: "agent", Title: "Agent", Flows: []platform.Flow{
+			{ID: "f1", IDN: "flow", Title: "Flow", DefaultRunnerType: "guidance"},
+		}}},
+		skills: map[string][]platform.Skill{"f1": {
+			{ID: "s1", IDN: "greet", Title: "Greet", RunnerType: "nsl", PromptScript: "Hi",
+				Parameters: []platform.SkillParameter{{Name: "user_name", DefaultValue: "friend"}}},
+		}},
+		states: map[string][]platform.FlowState{"f1": {{ID: "st1", IDN: "step", Scope: "user"}}},
+	}
+
+	dir := t.TempDir()
+	applier := NewApplier(client)
+	manifest, err := applier.Import(context.Background(), "demo", dir)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	path := filepath.Join(dir, "newo.project.yaml")
+	if err := WriteManifest(path, manifest); err != nil {
+		t.Fatalf("WriteManifest: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "skills", "flow", "greet.nsl")); err != nil || string(data) != "Hi" {
+		t.Fatalf("expected the skill script to be written, got %q (%v)", data, err)
+	}
+
+	plan, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	result, err := applier.Apply(context.Background(), ApplyRequest{
+		Project:            plan,
+		TargetCustomerIDN:  "target",
+		TargetCustomerType: "integration",
+		OutputRoot:         "out",
+		Confirm: func(plan ApplyPlan) (bool, error) {
+			t.Fatalf("expected an imported manifest to need no changes, got %+v", plan.Changes)
+			return false, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if result.Changed() || len(client.calls) != 0 {
+		t.Fatalf("expected no changes, got %+v (calls %v)", result, client.calls)
+	}
+
+	if _, err := applier.Import(context.Background(), "missing", t.TempDir()); err == nil {
+		t.Fatal("expected an error for an unknown project")
+	}
+}
@@ -0,0 +1,121 @@
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// ErrNoCheckpoint indicates there is no partial deploy to resume.
+var ErrNoCheckpoint = errors.New("no partial deploy found")
+
+// Checkpoint records remote resources created by a deploy run so that it can be resumed or rolled back.
+type Checkpoint struct {
+	ProjectIDN string                     `json:"project_idn"`
+	ProjectID  string                     `json:"project_id"`
+	Agents     map[string]AgentCheckpoint `json:"agents"`
+}
+
+// AgentCheckpoint tracks an agent and the flows created under it.
+type AgentCheckpoint struct {
+	ID    string                    `json:"id"`
+	Flows map[string]FlowCheckpoint `json:"flows"`
+}
+
+// FlowCheckpoint tracks a flow together with its created skills, events, and states keyed by IDN.
+type FlowCheckpoint struct {
+	ID     string            `json:"id"`
+	Skills map[string]string `json:"skills"`
+	Events map[string]string `json:"events"`
+	States map[string]string `json:"states"`
+}
+
+func newCheckpoint(projectIDN string) *Checkpoint {
+	return &Checkpoint{ProjectIDN: projectIDN, Agents: map[string]AgentCheckpoint{}}
+}
+
+// Empty reports whether the checkpoint references any remote resource.
+func (c *Checkpoint) Empty() bool {
+	return c == nil || (c.ProjectID == "" && len(c.Agents) == 0)
+}
+
+func (c *Checkpoint) agentID(agentIDN string) string {
+	return c.Agents[agentIDN].ID
+}
+
+func (c *Checkpoint) recordAgent(agentIDN, id string) {
+	agent := c.Agents[agentIDN]
+	agent.ID = id
+	if agent.Flows == nil {
+		agent.Flows = map[string]FlowCheckpoint{}
+	}
+	c.Agents[agentIDN] = agent
+}
+
+func (c *Checkpoint) flow(agentIDN, flowIDN string) FlowCheckpoint {
+	return c.Agents[agentIDN].Flows[flowIDN]
+}
+
+func (c *Checkpoint) updateFlow(agentIDN, flowIDN string, update func(*FlowCheckpoint)) {
+	agent := c.Agents[agentIDN]
+	if agent.Flows == nil {
+		agent.Flows = map[string]FlowCheckpoint{}
+	}
+	flow := agent.Flows[flowIDN]
+	if flow.Skills == nil {
+		flow.Skills = map[string]string{}
+	}
+	if flow.Events == nil {
+		flow.Events = map[string]string{}
+	}
+	if flow.States == nil {
+		flow.States = map[string]string{}
+	}
+	update(&flow)
+	agent.Flows[flowIDN] = flow
+	c.Agents[agentIDN] = agent
+}
+
+// LoadCheckpoint reads the partial deploy state for a project in the target customer.
+func LoadCheckpoint(customerIDN, projectIDN string) (*Checkpoint, error) {
+	data, err := os.ReadFile(fsutil.DeployCheckpointPath(customerIDN, projectIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w for project %s", ErrNoCheckpoint, projectIDN)
+		}
+		return nil, fmt.Errorf("read deploy checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("decode deploy checkpoint: %w", err)
+	}
+	if checkpoint.Agents == nil {
+		checkpoint.Agents = map[string]AgentCheckpoint{}
+	}
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint persists partial deploy state for later resumption.
+func SaveCheckpoint(customerIDN string, checkpoint *Checkpoint) error {
+	path := fsutil.DeployCheckpointPath(customerIDN, checkpoint.ProjectIDN)
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode deploy checkpoint: %w", err)
+	}
+	if err := writeFile(path, data); err != nil {
+		return fmt.Errorf("write deploy checkpoint: %w", err)
+	}
+	return nil
+}
+
+// RemoveCheckpoint deletes persisted partial deploy state, if any.
+func RemoveCheckpoint(customerIDN, projectIDN string) error {
+	if err := os.Remove(fsutil.DeployCheckpointPath(customerIDN, projectIDN)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove deploy checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+)
+
+// Manifest declares the complete desired shape of a project for `newo apply`.
+type Manifest struct {
+	IDN         string          `yaml:"idn"`
+	Title       string          `yaml:"title,omitempty"`
+	Description string          `yaml:"description,omitempty"`
+	Slug        string          `yaml:"slug,omitempty"`
+	Agents      []ManifestAgent `yaml:"agents"`
+}
+
+// ManifestAgent declares an agent and its flows.
+type ManifestAgent struct {
+	IDN         string         `yaml:"idn"`
+	Title       string         `yaml:"title,omitempty"`
+	Description string         `yaml:"description,omitempty"`
+	Flows       []ManifestFlow `yaml:"flows"`
+}
+
+// ManifestFlow declares a flow with its skills, events, and state fields. Blank runner and model
+// settings keep whatever the platform has.
+type ManifestFlow struct {
+	IDN               string               `yaml:"idn"`
+	Title             string               `yaml:"title,omitempty"`
+	Description       string               `yaml:"description,omitempty"`
+	DefaultRunnerType string               `yaml:"default_runner_type,omitempty"`
+	DefaultModel      ManifestModel        `yaml:"default_model,omitempty"`
+	Skills            []ManifestSkill      `yaml:"skills"`
+	Events            []ManifestEvent      `yaml:"events,omitempty"`
+	States            []ManifestStateField `yaml:"states,omitempty"`
+}
+
+// ManifestModel names a model and its provider.
+type ManifestModel struct {
+	ModelIDN    string `yaml:"model_idn,omitempty"`
+	ProviderIDN string `yaml:"provider_idn,omitempty"`
+}
+
+// ManifestSkill declares a skill. Script is a path relative to the manifest file.
+type ManifestSkill struct {
+	IDN        string              `yaml:"idn"`
+	Title      string              `yaml:"title,omitempty"`
+	RunnerType string              `yaml:"runner_type,omitempty"`
+	Model      ManifestModel       `yaml:"model,omitempty"`
+	Script     string              `yaml:"script"`
+	Parameters []ManifestParameter `yaml:"parameters,omitempty"`
+}
+
+// ManifestParameter declares a skill parameter.
+type ManifestParameter struct {
+	Name         string `yaml:"name"`
+	DefaultValue string `yaml:"default_value,omitempty"`
+}
+
+// ManifestEvent declares a flow event.
+type ManifestEvent struct {
+	IDN            string `yaml:"idn"`
+	Description    string `yaml:"description,omitempty"`
+	SkillSelector  string `yaml:"skill_selector,omitempty"`
+	SkillIDN       string `yaml:"skill_idn,omitempty"`
+	StateIDN       string `yaml:"state_idn,omitempty"`
+	IntegrationIDN string `yaml:"integration_idn,omitempty"`
+	ConnectorIDN   string `yaml:"connector_idn,omitempty"`
+	InterruptMode  string `yaml:"interrupt_mode,omitempty"`
+}
+
+// ManifestStateField declares a flow state field.
+type ManifestStateField struct {
+	IDN          string `yaml:"idn"`
+	Title        string `yaml:"title,omitempty"`
+	DefaultValue string `yaml:"default_value,omitempty"`
+	Scope        string `yaml:"scope,omitempty"`
+}
+
+// LoadManifest reads a project manifest and the skill scripts it references into a deployment plan.
+// Unknown keys are rejected so a misspelt field does not silently drop part of the project.
+func LoadManifest(path string) (ProjectPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ProjectPlan{}, fmt.Errorf("read manifest: %w", err)
+	}
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return ProjectPlan{}, fmt.Errorf("decode manifest %s: %w", path, err)
+	}
+	return manifest.Plan(filepath.Dir(path))
+}
+
+// Plan validates the manifest and converts it into a deployment plan, reading skill scripts relative
+// to dir. Skills are planned at the default location of the integration layout.
+func (m Manifest) Plan(dir string) (ProjectPlan, error) {
+	projectIDN := strings.TrimSpace(m.IDN)
+	if projectIDN == "" {
+		return ProjectPlan{}, fmt.Errorf("manifest: project idn is required")
+	}
+	plan := ProjectPlan{
+		IDN:         projectIDN,
+		Title:       fallback(m.Title, projectIDN),
+		Description: m.Description,
+		Slug:        fallback(m.Slug, strings.ToLower(projectIDN)),
+		RootDir:     dir,
+	}
+
+	agents := map[string]bool{}
+	flows := map[string]bool{}
+	for _, agent := range m.Agents {
+		agentIDN := strings.TrimSpace(agent.IDN)
+		if agentIDN == "" {
+			return ProjectPlan{}, fmt.Errorf("manifest: agent idn is required")
+		}
+		if agents[agentIDN] {
+			return ProjectPlan{}, fmt.Errorf("manifest: duplicate agent %s", agentIDN)
+		}
+		agents[agentIDN] = true
+
+		agentPlan := AgentPlan{IDN: agentIDN, Title: fallback(agent.Title, agentIDN), Description: agent.Description}
+		for _, flow := range agent.Flows {
+			flowIDN := strings.TrimSpace(flow.IDN)
+			if flowIDN == "" {
+				return ProjectPlan{}, fmt.Errorf("manifest: flow idn is required in agent %s", agentIDN)
+			}
+			// Flow directories are shared by all agents of a project, so flow IDNs must be unique across them.
+			if flows[flowIDN] {
+				return ProjectPlan{}, fmt.Errorf("manifest: duplicate flow %s", flowIDN)
+			}
+			flows[flowIDN] = true
+			flowPlan, err := flow.plan(dir, flowIDN)
+			if err != nil {
+				return ProjectPlan{}, err
+			}
+			agentPlan.Flows = append(agentPlan.Flows, flowPlan)
+		}
+		plan.Agents = append(plan.Agents, agentPlan)
+	}
+	return plan, nil
+}
+
+func (f ManifestFlow) plan(dir, flowIDN string) (FlowPlan, error) {
+	flowDirRel := filepath.ToSlash(filepath.Join(fsutil.FlowsDir, flowIDN))
+	plan := FlowPlan{
+		IDN:               flowIDN,
+		Title:             fallback(f.Title, flowIDN),
+		Description:       f.Description,
+		DefaultRunnerType: strings.TrimSpace(f.DefaultRunnerType),
+		DefaultModel:      f.DefaultModel.config(),
+		FlowDirRel:        flowDirRel,
+		MetadataRelPath:   flowDirRel + "/" + fsutil.MetadataYAML,
+	}
+
+	skills := map[string]bool{}
+	for _, skill := range f.Skills {
+		skillIDN := strings.TrimSpace(skill.IDN)
+		if skillIDN == "" {
+			return FlowPlan{}, fmt.Errorf("manifest: skill idn is required in flow %s", flowIDN)
+		}
+		if skills[skillIDN] {
+			return FlowPlan{}, fmt.Errorf("manifest: duplicate skill %s in flow %s", skillIDN, flowIDN)
+		}
+		skills[skillIDN] = true
+		if strings.TrimSpace(skill.Script) == "" {
+			return FlowPlan{}, fmt.Errorf("manifest: skill %s/%s has no script", flowIDN, skillIDN)
+		}
+		scriptPath := filepath.Join(dir, filepath.FromSlash(skill.Script))
+		script, err := os.ReadFile(scriptPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return FlowPlan{}, fmt.Errorf("%w: %s", ErrSkillScriptMissing, scriptPath)
+			}
+			return FlowPlan{}, fmt.Errorf("read script %s: %w", scriptPath, err)
+		}
+
+		params := make([]SkillParameterPlan, 0, len(skill.Parameters))
+		for _, p := range skill.Parameters {
+			params = append(params, SkillParameterPlan{Name: p.Name, DefaultValue: p.DefaultValue})
+		}
+		ext := strings.TrimPrefix(filepath.Ext(skill.Script), ".")
+		if ext == "" {
+			ext = platform.ScriptExtension(skill.RunnerType)
+		}
+		plan.Skills = append(plan.Skills, SkillPlan{
+			IDN:             skillIDN,
+			Title:           fallback(skill.Title, skillIDN),
+			RunnerType:      strings.TrimSpace(skill.RunnerType),
+			Model:           skill.Model.config(),
+			Parameters:      params,
+			ScriptPath:      scriptPath,
+			ScriptRelPath:   flowDirRel + "/" + skillIDN + "." + ext,
+			MetadataRelPath: flowDirRel + "/" + skillIDN + fsutil.SkillMetaFileExt,
+			Script:          script,
+		})
+	}
+
+	for _, event := range f.Events {
+		plan.Events = append(plan.Events, FlowEventPlan{
+			IDN:            event.IDN,
+			Description:    event.Description,
+			SkillSelector:  event.SkillSelector,
+			SkillIDN:       event.SkillIDN,
+			StateIDN:       event.StateIDN,
+			IntegrationIDN: event.IntegrationIDN,
+			ConnectorIDN:   event.ConnectorIDN,
+			InterruptMode:  event.InterruptMode,
+		})
+	}
+	for _, field := range f.States {
+		plan.States = append(plan.States, FlowStatePlan{
+			IDN:          field.IDN,
+			Title:        field.Title,
+			DefaultValue: field.DefaultValue,
+			Scope:        field.Scope,
+		})
+	}
+	return plan, nil
+}
+
+func (m ManifestModel) config() platform.ModelConfig {
+	return platform.ModelConfig{ModelIDN: strings.TrimSpace(m.ModelIDN), ProviderIDN: strings.TrimSpace(m.ProviderIDN)}
+}
+
+// Import describes an existing remote project as a manifest and writes its skill scripts under dir,
+// at skills/<flow>/<skill>.<ext>, where the manifest refers to them. Applying the result unchanged
+// changes nothing.
+func (a *Applier) Import(ctx context.Context, projectIDN, dir string) (Manifest, error) {
+	projects, err := a.client.ListProjects(ctx)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("list projects: %w", err)
+	}
+	var project *platform.Project
+	for idx := range projects {
+		if strings.EqualFold(strings.TrimSpace(projects[idx].IDN), projectIDN) {
+			project = &projects[idx]
+			break
+		}
+	}
+	if project == nil {
+		return Manifest{}, fmt.Errorf("project %s not found on the platform", projectIDN)
+	}
+
+	manifest := Manifest{IDN: project.IDN, Title: project.Title, Description: project.Description}
+	agents, err := a.client.ListAgents(ctx, project.ID)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("list agents: %w", err)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].IDN < agents[j].IDN })
+	for _, agent := range agents {
+		entry := ManifestAgent{IDN: agent.IDN, Title: agent.Title, Description: agent.Description}
+		flows := append([]platform.Flow(nil), agent.Flows...)
+		sort.Slice(flows, func(i, j int) bool { return flows[i].IDN < flows[j].IDN })
+		for _, flow := range flows {
+			imported, err := a.importFlow(ctx, flow, dir)
+			if err != nil {
+				return Manifest{}, err
+			}
+			entry.Flows = append(entry.Flows, imported)
+		}
+		manifest.Agents = append(manifest.Agents, entry)
+	}
+	return manifest, nil
+}
+
+func (a *Applier) importFlow(ctx context.Context, flow platform.Flow, dir string) (ManifestFlow, error) {
+	entry := ManifestFlow{
+		IDN:               flow.IDN,
+		Title:             flow.Title,
+		Description:       flow.Description,
+		DefaultRunnerType: flow.DefaultRunnerType,
+		DefaultModel:      ManifestModel{ModelIDN: flow.DefaultModel.ModelIDN, ProviderIDN: flow.DefaultModel.ProviderIDN},
+	}
+
+	skills, err := a.client.ListFlowSkills(ctx, flow.ID)
+	if err != nil {
+		return ManifestFlow{}, fmt.Errorf("list skills for %s: %w", flow.IDN, err)
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].IDN < skills[j].IDN })
+	for _, skill := range skills {
+		script := path.Join("skills", flow.IDN, skill.IDN+"."+platform.ScriptExtension(skill.RunnerType))
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(script)), []byte(skill.PromptScript)); err != nil {
+			return ManifestFlow{}, fmt.Errorf("write script %s: %w", script, err)
+		}
+		imported := ManifestSkill{
+			IDN:        skill.IDN,
+			Title:      skill.Title,
+			RunnerType: skill.RunnerType,
+			Model:      ManifestModel{ModelIDN: skill.Model.ModelIDN, ProviderIDN: skill.Model.ProviderIDN},
+			Script:     script,
+		}
+		for _, p := range skill.Parameters {
+			imported.Parameters = append(imported.Parameters, ManifestParameter{Name: p.Name, DefaultValue: p.DefaultValue})
+		}
+		entry.Skills = append(entry.Skills, imported)
+	}
+
+	events, err := a.client.ListFlowEvents(ctx, flow.ID)
+	if err != nil {
+		return ManifestFlow{}, fmt.Errorf("list events for %s: %w", flow.IDN, err)
+	}
+	for _, event := range events {
+		entry.Events = append(entry.Events, ManifestEvent{
+			IDN:            event.IDN,
+			Description:    event.Description,
+			SkillSelector:  event.SkillSelector,
+			SkillIDN:       event.SkillIDN,
+			StateIDN:       event.StateIDN,
+			IntegrationIDN: event.IntegrationIDN,
+			ConnectorIDN:   event.ConnectorIDN,
+			InterruptMode:  event.InterruptMode,
+		})
+	}
+	fields, err := a.client.ListFlowStates(ctx, flow.ID)
+	if err != nil {
+		return ManifestFlow{}, fmt.Errorf("list states for %s: %w", flow.IDN, err)
+	}
+	for _, field := range fields {
+		entry.States = append(entry.States, ManifestStateField{
+			IDN:          field.IDN,
+			Title:        field.Title,
+			DefaultValue: field.DefaultValue,
+			Scope:        field.Scope,
+		})
+	}
+	return entry, nil
+}
+
+// WriteManifest encodes a manifest as YAML at path.
+func WriteManifest(path string, manifest Manifest) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	return writeFile(path, buf.Bytes())
+}
@@ -2,10 +2,8 @@ package deploy
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -13,6 +11,7 @@ import (
 	"github.com/twinmind/newo-tool/internal/platform"
 	"github.com/twinmind/newo-tool/internal/serialize"
 	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
 )
 
 // DeployClient captures the platform API calls used by the deploy service.
@@ -22,6 +21,9 @@ type DeployClient interface {
 	CreateAgent(ctx context.Context, projectID string, payload platform.CreateAgentRequest) (platform.CreateAgentResponse, error)
 	CreateFlow(ctx context.Context, agentID string, payload platform.CreateFlowRequest) (platform.CreateFlowResponse, error)
 	ListAgents(ctx context.Context, projectID string) ([]platform.Agent, error)
+	DeleteProject(ctx context.Context, projectID string) error
+	DeleteAgent(ctx context.Context, agentID string) error
+	DeleteFlow(ctx context.Context, flowID string) error
 	CreateSkill(ctx context.Context, flowID string, payload platform.CreateSkillRequest) (platform.CreateSkillResponse, error)
 	CreateFlowEvent(ctx context.Context, flowID string, payload platform.CreateFlowEventRequest) (platform.CreateFlowEventResponse, error)
 	CreateFlowState(ctx context.Context, flowID string, payload platform.CreateFlowStateRequest) (platform.CreateFlowStateResponse, error)
@@ -42,6 +44,15 @@ type DeployRequest struct {
 	OutputRoot         string
 	WorkspaceDir       string
 	Reporter           Reporter
+	// RollbackOnFailure deletes every remote resource created by the run when a step fails.
+	RollbackOnFailure bool
+	// Resume continues a previously failed deploy using its persisted checkpoint.
+	Resume bool
+	// MergeState adds the deployed project to the target customer's existing project map and hashes
+	// instead of replacing them. Used when re-creating a project for the customer it was pulled from.
+	MergeState bool
+	// Vars are substituted into {{NAME}} placeholders of every skill script before upload.
+	Vars map[string]string
 }
 
 // DeployResult summarises the performed operations.
@@ -97,27 +108,58 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 		reporter = noopReporter{}
 	}
 
-	reporter.Infof("Checking for existing project %q", req.Project.IDN)
-	exists, err := s.projectExists(ctx, req.Project.IDN)
-	if err != nil {
-		return DeployResult{}, err
+	checkpoint := newCheckpoint(req.Project.IDN)
+	if req.Resume {
+		checkpoint, err = LoadCheckpoint(req.TargetCustomerIDN, req.Project.IDN)
+		if err != nil {
+			return DeployResult{}, err
+		}
+		reporter.Infof("Resuming deploy of project %q", req.Project.IDN)
 	}
-	if exists {
-		return DeployResult{}, fmt.Errorf("project %s already exists for target customer", req.Project.IDN)
+
+	if len(req.Vars) > 0 {
+		req.Project = substituteVars(req.Project, req.Vars, reporter)
 	}
 
-	reporter.Infof("Creating project %q", req.Project.IDN)
-	createProjResp, err := s.client.CreateProject(ctx, platform.CreateProjectRequest{
-		IDN:         req.Project.IDN,
-		Title:       req.Project.Title,
-		Description: req.Project.Description,
-	})
+	result, err := s.deploy(ctx, req, absWorkspace, reporter, checkpoint)
 	if err != nil {
-		return DeployResult{}, fmt.Errorf("create project: %w", err)
+		return DeployResult{}, s.handleFailure(ctx, req, reporter, checkpoint, err)
+	}
+
+	if err := RemoveCheckpoint(req.TargetCustomerIDN, req.Project.IDN); err != nil {
+		reporter.Warnf("%v", err)
 	}
-	projectID := strings.TrimSpace(createProjResp.ID)
+
+	reporter.Successf("Deployment completed: project %s (%s)", req.Project.IDN, result.ProjectID)
+	return result, nil
+}
+
+func (s *Service) deploy(ctx context.Context, req DeployRequest, absWorkspace string, reporter Reporter, checkpoint *Checkpoint) (DeployResult, error) {
+	projectID := checkpoint.ProjectID
 	if projectID == "" {
-		return DeployResult{}, fmt.Errorf("create project: empty project id returned")
+		reporter.Infof("Checking for existing project %q", req.Project.IDN)
+		exists, err := s.projectExists(ctx, req.Project.IDN)
+		if err != nil {
+			return DeployResult{}, err
+		}
+		if exists {
+			return DeployResult{}, fmt.Errorf("project %s already exists for target customer", req.Project.IDN)
+		}
+
+		reporter.Infof("Creating project %q", req.Project.IDN)
+		createProjResp, err := s.client.CreateProject(ctx, platform.CreateProjectRequest{
+			IDN:         req.Project.IDN,
+			Title:       req.Project.Title,
+			Description: req.Project.Description,
+		})
+		if err != nil {
+			return DeployResult{}, fmt.Errorf("create project: %w", err)
+		}
+		projectID = strings.TrimSpace(createProjResp.ID)
+		if projectID == "" {
+			return DeployResult{}, fmt.Errorf("create project: empty project id returned")
+		}
+		checkpoint.ProjectID = projectID
 	}
 
 	targetRoot := fsutil.ExportProjectDir(req.OutputRoot, req.TargetCustomerType, req.TargetCustomerIDN, req.Project.Slug)
@@ -140,20 +182,24 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 	}
 
 	for _, agentPlan := range req.Project.Agents {
-		reporter.Infof("Creating agent %q", agentPlan.IDN)
-		agentResp, err := s.client.CreateAgent(ctx, projectID, platform.CreateAgentRequest{
-			IDN:         agentPlan.IDN,
-			Title:       agentPlan.Title,
-			Description: agentPlan.Description,
-		})
-		if err != nil {
-			return DeployResult{}, fmt.Errorf("create agent %s: %w", agentPlan.IDN, err)
-		}
-		agentID := strings.TrimSpace(agentResp.ID)
+		agentID := checkpoint.agentID(agentPlan.IDN)
 		if agentID == "" {
-			return DeployResult{}, fmt.Errorf("agent %s: empty id", agentPlan.IDN)
+			reporter.Infof("Creating agent %q", agentPlan.IDN)
+			agentResp, err := s.client.CreateAgent(ctx, projectID, platform.CreateAgentRequest{
+				IDN:         agentPlan.IDN,
+				Title:       agentPlan.Title,
+				Description: agentPlan.Description,
+			})
+			if err != nil {
+				return DeployResult{}, fmt.Errorf("create agent %s: %w", agentPlan.IDN, err)
+			}
+			agentID = strings.TrimSpace(agentResp.ID)
+			if agentID == "" {
+				return DeployResult{}, fmt.Errorf("agent %s: empty id", agentPlan.IDN)
+			}
+			checkpoint.recordAgent(agentPlan.IDN, agentID)
+			result.AgentsCreated++
 		}
-		result.AgentsCreated++
 
 		agentData := state.AgentData{
 			ID:          agentID,
@@ -165,6 +211,10 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 		// Create flows under this agent.
 		for idx := range agentPlan.Flows {
 			flowPlan := &agentPlan.Flows[idx]
+			if existing := checkpoint.flow(agentPlan.IDN, flowPlan.IDN).ID; existing != "" {
+				flowPlan.CreatedFlowID = existing
+				continue
+			}
 			reporter.Infof("Creating flow %q", flowPlan.IDN)
 			flowResp, err := s.client.CreateFlow(ctx, agentID, platform.CreateFlowRequest{
 				IDN:   flowPlan.IDN,
@@ -174,6 +224,9 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 				return DeployResult{}, fmt.Errorf("create flow %s: %w", flowPlan.IDN, err)
 			}
 			flowPlan.CreatedFlowID = strings.TrimSpace(flowResp.ID)
+			if flowPlan.CreatedFlowID != "" {
+				checkpoint.updateFlow(agentPlan.IDN, flowPlan.IDN, func(fc *FlowCheckpoint) { fc.ID = flowPlan.CreatedFlowID })
+			}
 			result.FlowsCreated++
 		}
 
@@ -181,6 +234,9 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 		if err := s.populateFlowIDs(ctx, projectID, agentPlan); err != nil {
 			return DeployResult{}, err
 		}
+		for _, flowPlan := range agentPlan.Flows {
+			checkpoint.updateFlow(agentPlan.IDN, flowPlan.IDN, func(fc *FlowCheckpoint) { fc.ID = flowPlan.CreatedFlowID })
+		}
 
 		for idx := range agentPlan.Flows {
 			flowPlan := &agentPlan.Flows[idx]
@@ -205,28 +261,14 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 			// Create skills
 			for sidx := range flowPlan.Skills {
 				skillPlan := &flowPlan.Skills[sidx]
-				reporter.Infof("Creating skill %q/%q/%q", agentPlan.IDN, flowPlan.IDN, skillPlan.IDN)
-				createReq := platform.CreateSkillRequest{
-					IDN:          skillPlan.IDN,
-					Title:        skillPlan.Title,
-					PromptScript: string(skillPlan.Script),
-					RunnerType:   skillPlan.RunnerType,
-					Model: platform.ModelConfig{
-						ModelIDN:    skillPlan.Model.ModelIDN,
-						ProviderIDN: skillPlan.Model.ProviderIDN,
-					},
-					Path:       "",
-					Parameters: convertParametersToPlatform(skillPlan.Parameters),
-				}
-				skillResp, err := s.client.CreateSkill(ctx, flowPlan.CreatedFlowID, createReq)
-				if err != nil {
-					return DeployResult{}, fmt.Errorf("create skill %s: %w", skillPlan.IDN, err)
-				}
-				skillPlan.CreatedSkillID = strings.TrimSpace(skillResp.ID)
-				if skillPlan.CreatedSkillID == "" {
-					return DeployResult{}, fmt.Errorf("skill %s: empty id returned", skillPlan.IDN)
+				if existing := checkpoint.flow(agentPlan.IDN, flowPlan.IDN).Skills[skillPlan.IDN]; existing != "" {
+					skillPlan.CreatedSkillID = existing
+				} else if err := s.createSkill(ctx, agentPlan.IDN, flowPlan, skillPlan, reporter); err != nil {
+					return DeployResult{}, err
+				} else {
+					checkpoint.updateFlow(agentPlan.IDN, flowPlan.IDN, func(fc *FlowCheckpoint) { fc.Skills[skillPlan.IDN] = skillPlan.CreatedSkillID })
+					result.SkillsCreated++
 				}
-				result.SkillsCreated++
 
 				flowData.Skills[skillPlan.IDN] = state.SkillMetadataInfo{
 					ID:         skillPlan.CreatedSkillID,
@@ -241,26 +283,30 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 					Path:       skillPlan.ScriptRelPath,
 				}
 			}
-
 			// Create flow events
 			for eidx := range flowPlan.Events {
 				eventPlan := &flowPlan.Events[eidx]
-				reporter.Infof("Creating event %q on flow %q", eventPlan.IDN, flowPlan.IDN)
-				resp, err := s.client.CreateFlowEvent(ctx, flowPlan.CreatedFlowID, platform.CreateFlowEventRequest{
-					IDN:            eventPlan.IDN,
-					Description:    eventPlan.Description,
-					SkillSelector:  eventPlan.SkillSelector,
-					SkillIDN:       eventPlan.SkillIDN,
-					StateIDN:       eventPlan.StateIDN,
-					InterruptMode:  eventPlan.InterruptMode,
-					IntegrationIDN: eventPlan.IntegrationIDN,
-					ConnectorIDN:   eventPlan.ConnectorIDN,
-				})
-				if err != nil {
-					return DeployResult{}, fmt.Errorf("create event %s: %w", eventPlan.IDN, err)
+				if existing := checkpoint.flow(agentPlan.IDN, flowPlan.IDN).Events[eventPlan.IDN]; existing != "" {
+					eventPlan.CreatedID = existing
+				} else {
+					reporter.Infof("Creating event %q on flow %q", eventPlan.IDN, flowPlan.IDN)
+					resp, err := s.client.CreateFlowEvent(ctx, flowPlan.CreatedFlowID, platform.CreateFlowEventRequest{
+						IDN:            eventPlan.IDN,
+						Description:    eventPlan.Description,
+						SkillSelector:  eventPlan.SkillSelector,
+						SkillIDN:       eventPlan.SkillIDN,
+						StateIDN:       eventPlan.StateIDN,
+						InterruptMode:  eventPlan.InterruptMode,
+						IntegrationIDN: eventPlan.IntegrationIDN,
+						ConnectorIDN:   eventPlan.ConnectorIDN,
+					})
+					if err != nil {
+						return DeployResult{}, fmt.Errorf("create event %s: %w", eventPlan.IDN, err)
+					}
+					eventPlan.CreatedID = strings.TrimSpace(resp.ID)
+					checkpoint.updateFlow(agentPlan.IDN, flowPlan.IDN, func(fc *FlowCheckpoint) { fc.Events[eventPlan.IDN] = eventPlan.CreatedID })
+					result.EventsCreated++
 				}
-				eventPlan.CreatedID = strings.TrimSpace(resp.ID)
-				result.EventsCreated++
 
 				flowData.Events = append(flowData.Events, state.FlowEventInfo{
 					IDN:            eventPlan.IDN,
@@ -278,18 +324,23 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 			// Create flow states
 			for sidx := range flowPlan.States {
 				statePlan := &flowPlan.States[sidx]
-				reporter.Infof("Creating state %q on flow %q", statePlan.IDN, flowPlan.IDN)
-				resp, err := s.client.CreateFlowState(ctx, flowPlan.CreatedFlowID, platform.CreateFlowStateRequest{
-					Title:        statePlan.Title,
-					IDN:          statePlan.IDN,
-					DefaultValue: statePlan.DefaultValue,
-					Scope:        statePlan.Scope,
-				})
-				if err != nil {
-					return DeployResult{}, fmt.Errorf("create state %s: %w", statePlan.IDN, err)
+				if existing := checkpoint.flow(agentPlan.IDN, flowPlan.IDN).States[statePlan.IDN]; existing != "" {
+					statePlan.CreatedStateID = existing
+				} else {
+					reporter.Infof("Creating state %q on flow %q", statePlan.IDN, flowPlan.IDN)
+					resp, err := s.client.CreateFlowState(ctx, flowPlan.CreatedFlowID, platform.CreateFlowStateRequest{
+						Title:        statePlan.Title,
+						IDN:          statePlan.IDN,
+						DefaultValue: statePlan.DefaultValue,
+						Scope:        statePlan.Scope,
+					})
+					if err != nil {
+						return DeployResult{}, fmt.Errorf("create state %s: %w", statePlan.IDN, err)
+					}
+					statePlan.CreatedStateID = strings.TrimSpace(resp.ID)
+					checkpoint.updateFlow(agentPlan.IDN, flowPlan.IDN, func(fc *FlowCheckpoint) { fc.States[statePlan.IDN] = statePlan.CreatedStateID })
+					result.StatesCreated++
 				}
-				statePlan.CreatedStateID = strings.TrimSpace(resp.ID)
-				result.StatesCreated++
 
 				flowData.StateFields = append(flowData.StateFields, state.FlowStateInfo{
 					ID:           statePlan.CreatedStateID,
@@ -317,19 +368,146 @@ func (s *Service) Deploy(ctx context.Context, req DeployRequest) (DeployResult,
 		return DeployResult{}, err
 	}
 
-	// Persist state.
+	if err := saveState(req, projectData, result.Hashes, nil); err != nil {
+		return DeployResult{}, err
+	}
+
+	return result, nil
+}
+
+// saveState persists the project map and hashes of the deployed project. With MergeState the project
+// is added to the customer's existing state, and hashes of the stale paths, or of files below them,
+// are dropped.
+func saveState(req DeployRequest, projectData state.ProjectData, hashes state.HashStore, stale []string) error {
 	if err := fsutil.EnsureWorkspace(req.TargetCustomerIDN); err != nil {
-		return DeployResult{}, fmt.Errorf("ensure workspace: %w", err)
+		return fmt.Errorf("ensure workspace: %w", err)
+	}
+	savedMap := state.ProjectMap{Projects: map[string]state.ProjectData{req.Project.IDN: projectData}}
+	savedHashes := hashes
+	if req.MergeState {
+		existingMap, err := state.LoadProjectMap(req.TargetCustomerIDN)
+		if err != nil {
+			return err
+		}
+		if existingMap.Projects == nil {
+			existingMap.Projects = map[string]state.ProjectData{}
+		}
+		existingMap.Projects[req.Project.IDN] = projectData
+		savedMap = existingMap
+
+		existingHashes, err := state.LoadHashes(req.TargetCustomerIDN)
+		if err != nil {
+			return err
+		}
+		if existingHashes == nil {
+			existingHashes = state.HashStore{}
+		}
+		for path := range existingHashes {
+			for _, prefix := range stale {
+				if path == prefix || strings.HasPrefix(path, prefix+"/") {
+					delete(existingHashes, path)
+				}
+			}
+		}
+		for path, hash := range hashes {
+			existingHashes[path] = hash
+		}
+		savedHashes = existingHashes
 	}
-	if err := state.SaveProjectMap(req.TargetCustomerIDN, projectMap); err != nil {
-		return DeployResult{}, fmt.Errorf("save project map: %w", err)
+	if err := state.SaveProjectMap(req.TargetCustomerIDN, savedMap); err != nil {
+		return fmt.Errorf("save project map: %w", err)
 	}
-	if err := state.SaveHashes(req.TargetCustomerIDN, result.Hashes); err != nil {
-		return DeployResult{}, fmt.Errorf("save hashes: %w", err)
+	if err := state.SaveHashes(req.TargetCustomerIDN, savedHashes); err != nil {
+		return fmt.Errorf("save hashes: %w", err)
 	}
+	return nil
+}
 
-	reporter.Successf("Deployment completed: project %s (%s)", req.Project.IDN, projectID)
-	return result, nil
+func (s *Service) createSkill(ctx context.Context, agentIDN string, flowPlan *FlowPlan, skillPlan *SkillPlan, reporter Reporter) error {
+	reporter.Infof("Creating skill %q/%q/%q", agentIDN, flowPlan.IDN, skillPlan.IDN)
+	createReq := platform.CreateSkillRequest{
+		IDN:          skillPlan.IDN,
+		Title:        skillPlan.Title,
+		PromptScript: string(skillPlan.Script),
+		RunnerType:   skillPlan.RunnerType,
+		Model: platform.ModelConfig{
+			ModelIDN:    skillPlan.Model.ModelIDN,
+			ProviderIDN: skillPlan.Model.ProviderIDN,
+		},
+		Path:       "",
+		Parameters: convertParametersToPlatform(skillPlan.Parameters),
+	}
+	skillResp, err := s.client.CreateSkill(ctx, flowPlan.CreatedFlowID, createReq)
+	if err != nil {
+		return fmt.Errorf("create skill %s: %w", skillPlan.IDN, err)
+	}
+	skillPlan.CreatedSkillID = strings.TrimSpace(skillResp.ID)
+	if skillPlan.CreatedSkillID == "" {
+		return fmt.Errorf("skill %s: empty id returned", skillPlan.IDN)
+	}
+	return nil
+}
+
+// handleFailure either rolls back resources created so far or persists them for a later --resume.
+func (s *Service) handleFailure(ctx context.Context, req DeployRequest, reporter Reporter, checkpoint *Checkpoint, cause error) error {
+	if checkpoint.Empty() {
+		return cause
+	}
+
+	if !req.RollbackOnFailure {
+		if err := SaveCheckpoint(req.TargetCustomerIDN, checkpoint); err != nil {
+			reporter.Warnf("Unable to save partial deploy state: %v", err)
+			return cause
+		}
+		reporter.Warnf("Partial deploy state saved; rerun with --resume to continue or --rollback-on-failure to clean up")
+		return cause
+	}
+
+	reporter.Warnf("Deploy failed, rolling back created resources")
+	// Rollback must run even when the original context was cancelled.
+	if err := s.rollback(context.WithoutCancel(ctx), checkpoint, reporter); err != nil {
+		if saveErr := SaveCheckpoint(req.TargetCustomerIDN, checkpoint); saveErr != nil {
+			reporter.Warnf("Unable to save partial deploy state: %v", saveErr)
+		}
+		return fmt.Errorf("%w (rollback incomplete: %v)", cause, err)
+	}
+	if err := RemoveCheckpoint(req.TargetCustomerIDN, req.Project.IDN); err != nil {
+		reporter.Warnf("%v", err)
+	}
+	return fmt.Errorf("%w (rolled back)", cause)
+}
+
+// rollback deletes flows, agents, and finally the project recorded in the checkpoint.
+// Successfully deleted resources are removed from the checkpoint so a failed rollback can be retried.
+func (s *Service) rollback(ctx context.Context, checkpoint *Checkpoint, reporter Reporter) error {
+	for _, agentIDN := range sortedKeys(checkpoint.Agents) {
+		agent := checkpoint.Agents[agentIDN]
+		for _, flowIDN := range sortedKeys(agent.Flows) {
+			flow := agent.Flows[flowIDN]
+			if flow.ID != "" {
+				reporter.Infof("Deleting flow %q", flowIDN)
+				if err := s.client.DeleteFlow(ctx, flow.ID); err != nil {
+					return fmt.Errorf("delete flow %s: %w", flowIDN, err)
+				}
+			}
+			delete(agent.Flows, flowIDN)
+		}
+		if agent.ID != "" {
+			reporter.Infof("Deleting agent %q", agentIDN)
+			if err := s.client.DeleteAgent(ctx, agent.ID); err != nil {
+				return fmt.Errorf("delete agent %s: %w", agentIDN, err)
+			}
+		}
+		delete(checkpoint.Agents, agentIDN)
+	}
+	if checkpoint.ProjectID != "" {
+		reporter.Infof("Deleting project %q", checkpoint.ProjectIDN)
+		if err := s.client.DeleteProject(ctx, checkpoint.ProjectID); err != nil {
+			return fmt.Errorf("delete project %s: %w", checkpoint.ProjectIDN, err)
+		}
+		checkpoint.ProjectID = ""
+	}
+	return nil
 }
 
 func (s *Service) projectExists(ctx context.Context, projectIDN string) (bool, error) {
@@ -478,7 +656,11 @@ func (s *Service) writeLocalArtifacts(projectID string, req DeployRequest, proje
 				}
 				result.Hashes[hashKey] = hashBytes(scriptBytes)
 
-				metaBytes, err = serialize.SkillMetadata(platform.Skill{
+				tags, err := state.LoadSkillTags(planSkill.MetadataPath)
+				if err != nil {
+					return fmt.Errorf("read skill tags for %s: %w", skillIDN, err)
+				}
+				metaBytes, err = serialize.TaggedSkillMetadata(platform.Skill{
 					ID:         skill.ID,
 					IDN:        skill.IDN,
 					Title:      skill.Title,
@@ -489,7 +671,7 @@ func (s *Service) writeLocalArtifacts(projectID string, req DeployRequest, proje
 					},
 					Parameters: convertParametersInfo(skill.Parameters),
 					Path:       planSkill.ScriptRelPath,
-				})
+				}, tags)
 				if err != nil {
 					return fmt.Errorf("serialize skill metadata for %s: %w", skillIDN, err)
 				}
@@ -642,7 +824,11 @@ func scriptExtension(relPath string) string {
 }
 
 func workspaceRelative(workspace, path string) (string, error) {
-	rel, err := filepath.Rel(workspace, path)
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve path %s: %w", path, err)
+	}
+	rel, err := filepath.Rel(workspace, absPath)
 	if err != nil {
 		return "", fmt.Errorf("compute relative path for %s: %w", path, err)
 	}
@@ -653,12 +839,11 @@ func writeFile(path string, data []byte) error {
 	if err := fsutil.EnsureParentDir(path); err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, fsutil.FilePerm)
+	return fsutil.AtomicWrite(path, data, fsutil.FilePerm)
 }
 
 func hashBytes(data []byte) string {
-	sum := sha256.Sum256(data)
-	return fmt.Sprintf("%x", sum[:])
+	return util.ContentHash(data)
 }
 
 type noopReporter struct{}
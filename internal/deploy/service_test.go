@@ -0,0 +1,215 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+type fakeDeployClient struct {
+	nextID        int
+	failSkillIDN  string
+	created       []string
+	deleted       []string
+	projectsExist []platform.Project
+}
+
+func (f *fakeDeployClient) id(prefix string) string {
+	f.nextID++
+	id := fmt.Sprintf("%s-%d", prefix, f.nextID)
+	f.created = append(f.created, id)
+	return id
+}
+
+func (f *fakeDeployClient) ListProjects(context.Context) ([]platform.Project, error) {
+	return f.projectsExist, nil
+}
+
+func (f *fakeDeployClient) CreateProject(context.Context, platform.CreateProjectRequest) (platform.CreateProjectResponse, error) {
+	return platform.CreateProjectResponse{ID: f.id("project")}, nil
+}
+
+func (f *fakeDeployClient) CreateAgent(context.Context, string, platform.CreateAgentRequest) (platform.CreateAgentResponse, error) {
+	return platform.CreateAgentResponse{ID: f.id("agent")}, nil
+}
+
+func (f *fakeDeployClient) CreateFlow(context.Context, string, platform.CreateFlowRequest) (platform.CreateFlowResponse, error) {
+	return platform.CreateFlowResponse{ID: f.id("flow")}, nil
+}
+
+func (f *fakeDeployClient) ListAgents(context.Context, string) ([]platform.Agent, error) {
+	return nil, nil
+}
+
+func (f *fakeDeployClient) CreateSkill(_ context.Context, _ string, payload platform.CreateSkillRequest) (platform.CreateSkillResponse, error) {
+	if payload.IDN == f.failSkillIDN {
+		return platform.CreateSkillResponse{}, errors.New("boom")
+	}
+	return platform.CreateSkillResponse{ID: f.id("skill")}, nil
+}
+
+func (f *fakeDeployClient) CreateFlowEvent(context.Context, string, platform.CreateFlowEventRequest) (platform.CreateFlowEventResponse, error) {
+	return platform.CreateFlowEventResponse{ID: f.id("event")}, nil
+}
+
+func (f *fakeDeployClient) CreateFlowState(context.Context, string, platform.CreateFlowStateRequest) (platform.CreateFlowStateResponse, error) {
+	return platform.CreateFlowStateResponse{ID: f.id("state")}, nil
+}
+
+func (f *fakeDeployClient) DeleteProject(_ context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeDeployClient) DeleteAgent(_ context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeDeployClient) DeleteFlow(_ context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func testProjectPlan() ProjectPlan {
+	return ProjectPlan{
+		IDN:  "demo",
+		Slug: "demo",
+		Agents: []AgentPlan{{
+			IDN: "agent",
+			Flows: []FlowPlan{{
+				IDN: "flow",
+				Skills: []SkillPlan{
+					{IDN: "first", ScriptRelPath: "first.nsl", Script: []byte("a")},
+					{IDN: "second", ScriptRelPath: "second.nsl", Script: []byte("b")},
+				},
+			}},
+		}},
+	}
+}
+
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestDeployRollbackOnFailure(t *testing.T) {
+	chdirTemp(t)
+
+	client := &fakeDeployClient{failSkillIDN: "second"}
+	_, err := NewService(client).Deploy(context.Background(), DeployRequest{
+		Project:           testProjectPlan(),
+		TargetCustomerIDN: "target",
+		OutputRoot:        "out",
+		RollbackOnFailure: true,
+	})
+	if err == nil {
+		t.Fatalf("expected deploy error")
+	}
+
+	want := []string{"flow-3", "agent-2", "project-1"}
+	if fmt.Sprint(client.deleted) != fmt.Sprint(want) {
+		t.Fatalf("unexpected rollback order: got %v want %v", client.deleted, want)
+	}
+	if _, err := os.Stat(fsutil.DeployCheckpointPath("target", "demo")); !os.IsNotExist(err) {
+		t.Fatalf("expected checkpoint to be removed after rollback, got %v", err)
+	}
+}
+
+func TestDeployResumeAfterFailure(t *testing.T) {
+	chdirTemp(t)
+
+	client := &fakeDeployClient{failSkillIDN: "second"}
+	service := NewService(client)
+	req := DeployRequest{
+		Project:           testProjectPlan(),
+		TargetCustomerIDN: "target",
+		OutputRoot:        "out",
+	}
+	if _, err := service.Deploy(context.Background(), req); err == nil {
+		t.Fatalf("expected deploy error")
+	}
+	if len(client.deleted) != 0 {
+		t.Fatalf("expected no rollback without flag, got %v", client.deleted)
+	}
+
+	checkpoint, err := LoadCheckpoint("target", "demo")
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if checkpoint.ProjectID != "project-1" {
+		t.Fatalf("unexpected checkpoint project id %q", checkpoint.ProjectID)
+	}
+
+	client.failSkillIDN = ""
+	req.Project = testProjectPlan()
+	req.Resume = true
+	result, err := service.Deploy(context.Background(), req)
+	if err != nil {
+		t.Fatalf("resume deploy: %v", err)
+	}
+	if result.ProjectID != "project-1" {
+		t.Fatalf("expected resumed project id, got %q", result.ProjectID)
+	}
+	if result.AgentsCreated != 0 || result.FlowsCreated != 0 || result.SkillsCreated != 1 {
+		t.Fatalf("unexpected counts on resume: %+v", result)
+	}
+	if _, err := LoadCheckpoint("target", "demo"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Fatalf("expected checkpoint cleared after success, got %v", err)
+	}
+}
+
+func TestDeployMergeStateKeepsOtherProjects(t *testing.T) {
+	chdirTemp(t)
+
+	if err := state.SaveProjectMap("target", state.ProjectMap{Projects: map[string]state.ProjectData{
+		"other": {ProjectID: "other-id", ProjectIDN: "other", Path: "other"},
+		"demo":  {ProjectID: "deleted-id", ProjectIDN: "demo", Path: "demo"},
+	}}); err != nil {
+		t.Fatalf("save project map: %v", err)
+	}
+	if err := state.SaveHashes("target", state.HashStore{"out/target/other/project.json": "keep"}); err != nil {
+		t.Fatalf("save hashes: %v", err)
+	}
+
+	result, err := NewService(&fakeDeployClient{}).Deploy(context.Background(), DeployRequest{
+		Project:           testProjectPlan(),
+		TargetCustomerIDN: "target",
+		OutputRoot:        "out",
+		MergeState:        true,
+	})
+	if err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+
+	projectMap, err := state.LoadProjectMap("target")
+	if err != nil {
+		t.Fatalf("load project map: %v", err)
+	}
+	if projectMap.Projects["other"].ProjectID != "other-id" {
+		t.Fatalf("expected other project to be kept, got %+v", projectMap.Projects)
+	}
+	if projectMap.Projects["demo"].ProjectID != result.ProjectID {
+		t.Fatalf("expected demo to be rebound to %s, got %+v", result.ProjectID, projectMap.Projects["demo"])
+	}
+	hashes, err := state.LoadHashes("target")
+	if err != nil {
+		t.Fatalf("load hashes: %v", err)
+	}
+	if hashes["out/target/other/project.json"] != "keep" || len(hashes) <= 1 {
+		t.Fatalf("expected hashes to be merged, got %v", hashes)
+	}
+}
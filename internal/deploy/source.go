@@ -55,6 +55,12 @@ func LoadSourceProject(cfg SourceConfig) (ProjectPlan, error) {
 	}
 
 	projectDir := filepath.Clean(fsutil.ExportProjectDir(cfg.OutputRoot, cfg.CustomerType, customerIDN, slug))
+	return LoadProjectFromDir(projectDir, slug, projectIDN, projectData)
+}
+
+// LoadProjectFromDir builds a deployment plan from an integration-layout project directory
+// described by the given state metadata.
+func LoadProjectFromDir(projectDir, slug, projectIDN string, projectData state.ProjectData) (ProjectPlan, error) {
 	if _, err := os.Stat(projectDir); err != nil {
 		if os.IsNotExist(err) {
 			return ProjectPlan{}, fmt.Errorf("%w: %s", ErrProjectDirMissing, projectDir)
@@ -62,7 +68,7 @@ func LoadSourceProject(cfg SourceConfig) (ProjectPlan, error) {
 		return ProjectPlan{}, fmt.Errorf("stat project directory %s: %w", projectDir, err)
 	}
 
-	projectJSONPath := filepath.Clean(fsutil.ExportProjectJSONPath(cfg.OutputRoot, cfg.CustomerType, customerIDN, slug))
+	projectJSONPath := filepath.Join(projectDir, fsutil.ProjectJSON)
 	projectJSON, err := readProjectJSON(projectJSONPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -82,12 +88,12 @@ func LoadSourceProject(cfg SourceConfig) (ProjectPlan, error) {
 		ProjectJSON:       projectJSON,
 	}
 
-	flowsPath := filepath.Clean(fsutil.ExportFlowsYAMLPath(cfg.OutputRoot, cfg.CustomerType, customerIDN, slug))
+	flowsPath := filepath.Join(projectDir, fsutil.FlowsYAML)
 	if _, err := os.Stat(flowsPath); err == nil {
 		plan.FlowsYAMLPath = flowsPath
 	}
 
-	attributesPath := filepath.Clean(fsutil.ExportAttributesPath(cfg.OutputRoot, cfg.CustomerType, customerIDN, slug))
+	attributesPath := filepath.Join(projectDir, fsutil.AttributesYAML)
 	if _, err := os.Stat(attributesPath); err == nil {
 		plan.AttributesPath = attributesPath
 	}
@@ -192,6 +198,10 @@ func buildSkillPlan(projectDir, flowIDN string, meta state.SkillMetadataInfo) (S
 	}
 
 	scriptRel := strings.TrimSpace(meta.Path)
+	if scriptRel == "" && strings.TrimSpace(meta.RunnerType) != "" {
+		// Skills created by push are recorded without a path; they live at the default location.
+		scriptRel = filepath.ToSlash(filepath.Join(fsutil.FlowsDir, flowIDN, meta.IDN+"."+platform.ScriptExtension(meta.RunnerType)))
+	}
 	if scriptRel == "" {
 		return SkillPlan{}, fmt.Errorf("skill %s missing script path", meta.IDN)
 	}
@@ -0,0 +1,32 @@
+package deploy
+
+import (
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/templatevars"
+)
+
+// substituteVars returns a copy of the plan with template variables expanded in every skill script.
+// The caller's plan is left untouched so a failed deploy can be retried with different values.
+func substituteVars(plan ProjectPlan, vars map[string]string, reporter Reporter) ProjectPlan {
+	agents := make([]AgentPlan, len(plan.Agents))
+	for ai, agent := range plan.Agents {
+		flows := make([]FlowPlan, len(agent.Flows))
+		for fi, flow := range agent.Flows {
+			skills := make([]SkillPlan, len(flow.Skills))
+			for si, skill := range flow.Skills {
+				if missing := templatevars.Unresolved(skill.Script, vars); len(missing) > 0 {
+					reporter.Warnf("Skill %s/%s uses undefined template variables: %s", flow.IDN, skill.IDN, strings.Join(missing, ", "))
+				}
+				skill.Script = templatevars.Expand(skill.Script, vars)
+				skills[si] = skill
+			}
+			flow.Skills = skills
+			flows[fi] = flow
+		}
+		agent.Flows = flows
+		agents[ai] = agent
+	}
+	plan.Agents = agents
+	return plan
+}
@@ -3,13 +3,17 @@ package diff
 import (
 	"fmt"
 	"strings"
-	"unicode/utf8"
+
+	"github.com/twinmind/newo-tool/internal/redact"
 )
 
 const (
 	redColor   = "\033[31m"
 	greenColor = "\033[32m"
 	resetColor = "\033[0m"
+	// emphasis marks changed words within a changed line.
+	emphasis    = "\033[7m"
+	endEmphasis = "\033[27m"
 )
 
 // Line represents a single line in a diff output.
@@ -18,6 +22,9 @@ type Line struct {
 	Text       string
 	LocalLine  int
 	RemoteLine int
+	// Changes marks the words of a deleted or added line that differ from the line it replaces. It is
+	// empty when the whole line changed.
+	Changes []Span
 }
 
 // Generate computes the diff between two byte slices and returns a slice of Lines.
@@ -27,71 +34,83 @@ func Generate(local, remote []byte, context int) []Line {
 		return nil
 	}
 	full := fullLines(local, remote)
+	highlightWords(full)
 	if context < 0 {
 		return full
 	}
 	return trimContext(full, context)
 }
 
-// Format takes a slice of diff Lines and formats them into a human-readable, colored string with headers.
+// Format takes a slice of diff Lines and formats them into a human-readable, colored string with
+// headers, in the style selected with SetStyle.
 func Format(path string, lines []Line) string {
 	if len(lines) == 0 {
 		return ""
 	}
-
-	firstLocal, firstRemote := headerLineNumbers(lines)
-	header := fmt.Sprintf("diff %s (@@ -%d +%d @@)", path, firstLocal, firstRemote)
-
-	type row struct {
-		displayNumber string
-		plainNumber   string
-		displayText   string
-		plainText     string
+	switch currentStyle() {
+	case StyleSideBySide:
+		return formatSideBySide(path, lines)
+	case StyleMinimal:
+		return formatMinimal(path, lines)
 	}
+	return formatUnified(path, lines)
+}
 
-	rows := make([]row, 0, len(lines))
+// formatUnified renders one row per line, numbered on the side the line belongs to.
+func formatUnified(path string, lines []Line) string {
+	rows := make([][]cell, 0, len(lines))
 	for _, line := range lines {
 		displayNumber, plainNumber := formatLineNumber(line)
 		displayText, plainText := formatLineText(line)
-		rows = append(rows, row{
-			displayNumber: displayNumber,
-			plainNumber:   plainNumber,
-			displayText:   displayText,
-			plainText:     plainText,
+		rows = append(rows, []cell{
+			{display: displayNumber, plain: plainNumber, alignRight: true},
+			{display: displayText, plain: plainText},
 		})
 	}
+	return renderTable(diffHeader(path, lines), rows, []int{2, 0})
+}
 
-	col1Width := 0
-	col2Width := 0
-	for _, r := range rows {
-		if l := visibleLength(r.plainNumber); l > col1Width {
-			col1Width = l
-		}
-		if l := visibleLength(r.plainText); l > col2Width {
-			col2Width = l
+func diffHeader(path string, lines []Line) string {
+	firstLocal, firstRemote := headerLineNumbers(lines)
+	return fmt.Sprintf("diff %s (@@ -%d +%d @@)", path, firstLocal, firstRemote)
+}
+
+// cell is one column of a table row. Display may carry colour codes; plain is what takes up space.
+type cell struct {
+	display    string
+	plain      string
+	alignRight bool
+}
+
+// renderTable draws rows under a centred header. Columns are at least minWidths wide, and the last one
+// grows when the header needs more room.
+func renderTable(header string, rows [][]cell, minWidths []int) string {
+	widths := append([]int(nil), minWidths...)
+	for _, row := range rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], visibleLength(c.plain))
 		}
 	}
-	if col1Width < 2 {
-		col1Width = 2
-	}
 
-	tableWidth := col1Width + col2Width + 7
+	tableWidth := 1
+	for _, width := range widths {
+		tableWidth += width + 3
+	}
 	headerInnerWidth := tableWidth - 4
 	if headerLen := visibleLength(header); headerLen > headerInnerWidth {
-		col2Width += headerLen - headerInnerWidth
-		tableWidth = col1Width + col2Width + 7
-		headerInnerWidth = tableWidth - 4
+		widths[len(widths)-1] += headerLen - headerInnerWidth
+		headerInnerWidth = headerLen
 	}
 
 	var builder strings.Builder
-	topBorder := buildBorderLine(col1Width, col2Width)
-	builder.WriteString(topBorder)
+	border := buildBorderLine(widths)
+	builder.WriteString(border)
 	builder.WriteString(buildHeaderLine(header, headerInnerWidth))
-	builder.WriteString(topBorder)
-	for _, r := range rows {
-		builder.WriteString(buildDataLine(r.displayNumber, r.plainNumber, col1Width, true, r.displayText, r.plainText, col2Width))
+	builder.WriteString(border)
+	for _, row := range rows {
+		builder.WriteString(buildDataLine(row, widths))
 	}
-	builder.WriteString(topBorder)
+	builder.WriteString(border)
 	return builder.String()
 }
 
@@ -260,24 +279,48 @@ func formatLineNumber(line Line) (display string, plain string) {
 	return display, plain
 }
 
+// formatLineText masks secrets before styling so column widths match what is printed. Changed words
+// are shown in reverse video; they are not marked when masking moved them.
 func formatLineText(line Line) (display string, plain string) {
+	text := redact.String(line.Text)
+	changes := line.Changes
+	if text != line.Text {
+		changes = nil
+	}
 	switch line.Kind {
 	case "del":
-		return fmt.Sprintf("%s%s%s", redColor, line.Text, resetColor), line.Text
+		return highlight(text, changes, redColor), text
 	case "add":
-		return fmt.Sprintf("%s%s%s", greenColor, line.Text, resetColor), line.Text
+		return highlight(text, changes, greenColor), text
 	default:
-		return line.Text, line.Text
+		return text, text
+	}
+}
+
+func highlight(text string, changes []Span, color string) string {
+	var builder strings.Builder
+	builder.WriteString(color)
+	last := 0
+	for _, span := range changes {
+		builder.WriteString(text[last:span.Start])
+		builder.WriteString(emphasis)
+		builder.WriteString(text[span.Start:span.End])
+		builder.WriteString(endEmphasis)
+		last = span.End
 	}
+	builder.WriteString(text[last:])
+	builder.WriteString(resetColor)
+	return builder.String()
 }
 
-func buildBorderLine(col1Width, col2Width int) string {
+func buildBorderLine(widths []int) string {
 	var builder strings.Builder
 	builder.WriteString("  +")
-	builder.WriteString(strings.Repeat("-", col1Width+2))
-	builder.WriteString("+")
-	builder.WriteString(strings.Repeat("-", col2Width+2))
-	builder.WriteString("+\n")
+	for _, width := range widths {
+		builder.WriteString(strings.Repeat("-", width+2))
+		builder.WriteString("+")
+	}
+	builder.WriteString("\n")
 	return builder.String()
 }
 
@@ -297,13 +340,15 @@ func buildHeaderLine(header string, innerWidth int) string {
 	return builder.String()
 }
 
-func buildDataLine(displayNumber, plainNumber string, widthNumber int, alignRight bool, displayText, plainText string, widthText int) string {
+func buildDataLine(row []cell, widths []int) string {
 	var builder strings.Builder
-	builder.WriteString("  | ")
-	builder.WriteString(padANSI(displayNumber, plainNumber, widthNumber, alignRight))
-	builder.WriteString(" | ")
-	builder.WriteString(padANSI(displayText, plainText, widthText, false))
-	builder.WriteString(" |\n")
+	builder.WriteString("  |")
+	for i, c := range row {
+		builder.WriteString(" ")
+		builder.WriteString(padANSI(c.display, c.plain, widths[i], c.alignRight))
+		builder.WriteString(" |")
+	}
+	builder.WriteString("\n")
 	return builder.String()
 }
 
@@ -319,5 +364,5 @@ func padANSI(display, plain string, width int, alignRight bool) string {
 }
 
 func visibleLength(s string) int {
-	return utf8.RuneCountInString(s)
+	return displayWidth(s)
 }
@@ -54,3 +54,101 @@ var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 func stripANSI(s string) string {
 	return ansiPattern.ReplaceAllString(s, "")
 }
+
+func TestFormatRedactsSecrets(t *testing.T) {
+	lines := []Line{{Kind: "add", Text: "Authorization: Bearer abcdefgh12345", RemoteLine: 1}}
+	out := stripANSI(Format("skill.nsl", lines))
+	if strings.Contains(out, "abcdefgh12345") {
+		t.Fatalf("expected token to be redacted, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Bearer [REDACTED]") {
+		t.Fatalf("expected redaction marker, got:\n%s", out)
+	}
+}
+
+func TestGenerateMarksChangedWords(t *testing.T) {
+	lines := Generate([]byte("Greet the guest warmly.\n"), []byte("Greet the caller warmly.\n"), 0)
+	if len(lines) != 2 {
+		t.Fatalf("expected one removed and one added line, got %+v", lines)
+	}
+	for _, line := range lines {
+		if len(line.Changes) != 1 {
+			t.Fatalf("expected one changed span in %q, got %+v", line.Text, line.Changes)
+		}
+		span := line.Changes[0]
+		word := line.Text[span.Start:span.End]
+		if (line.Kind == "del" && word != "guest") || (line.Kind == "add" && word != "caller") {
+			t.Fatalf("unexpected changed word %q in %s line", word, line.Kind)
+		}
+	}
+	if got := Format("skill.nsl", lines); !strings.Contains(got, emphasis+"caller"+endEmphasis) {
+		t.Fatalf("expected the changed word to be emphasised, got %q", got)
+	}
+
+	unrelated := Generate([]byte("alpha\n"), []byte("omega\n"), 0)
+	if unrelated[0].Changes != nil || unrelated[1].Changes != nil {
+		t.Fatalf("expected lines without shared words to change as a whole, got %+v", unrelated)
+	}
+}
+
+func TestWordChangesKeepEmojiAndAccentsWhole(t *testing.T) {
+	// The new line spells José with a combining accent and swaps a waving hand with a skin tone for a
+	// family joined with zero-width joiners.
+	oldText := "Say hi \U0001F44B\U0001F3FD to Jos\u00e9"
+	newText := "Say hi \U0001F468\u200d\U0001F469\u200d\U0001F467 to Jose\u0301"
+	oldSpans, newSpans := wordChanges(oldText, newText)
+	var changed []string
+	for _, span := range oldSpans {
+		changed = append(changed, oldText[span.Start:span.End])
+	}
+	for _, span := range newSpans {
+		changed = append(changed, newText[span.Start:span.End])
+	}
+	want := []string{"\U0001F44B\U0001F3FD", "Jos\u00e9", "\U0001F468\u200d\U0001F469\u200d\U0001F467", "Jose\u0301"}
+	if strings.Join(changed, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected whole clusters to change, got %q", changed)
+	}
+}
+
+func TestFormatAlignsWideCharacters(t *testing.T) {
+	lines := []Line{
+		{Kind: "del", Text: "こんにちは", LocalLine: 1},
+		{Kind: "add", Text: "Hi 👋🏽 👨‍👩‍👧", RemoteLine: 1},
+		{Kind: "context", Text: "plain", LocalLine: 2, RemoteLine: 2},
+	}
+	rows := strings.Split(strings.TrimSuffix(stripANSI(Format("x", lines)), "\n"), "\n")
+	for _, row := range rows {
+		if got, want := displayWidth(row), displayWidth(rows[0]); got != want {
+			t.Fatalf("expected every row to be %d columns wide, got %d for %q", want, got, row)
+		}
+	}
+}
+
+func TestFormatStyles(t *testing.T) {
+	t.Cleanup(func() { _ = SetStyle("") })
+	lines := []Line{
+		{Kind: "context", Text: "same", LocalLine: 1, RemoteLine: 1},
+		{Kind: "del", Text: "old", LocalLine: 2},
+		{Kind: "add", Text: "new", RemoteLine: 2},
+	}
+
+	if err := SetStyle(StyleSideBySide); err != nil {
+		t.Fatal(err)
+	}
+	sideBySide := stripANSI(Format("file.txt", lines))
+	if !regexp.MustCompile(`(?m)^  \| +-2 \| old +\| +\+2 \| new +\|$`).MatchString(sideBySide) {
+		t.Fatalf("expected the edited line next to its replacement, got:\n%s", sideBySide)
+	}
+
+	if err := SetStyle(StyleMinimal); err != nil {
+		t.Fatal(err)
+	}
+	want := "  diff file.txt (@@ -1 +1 @@)\n  -2 old\n  +2 new\n"
+	if got := stripANSI(Format("file.txt", lines)); got != want {
+		t.Fatalf("unexpected minimal diff.\nwant:\n%s\ngot:\n%s", want, got)
+	}
+
+	if err := SetStyle("fancy"); err == nil {
+		t.Fatal("expected an unknown style to be rejected")
+	}
+}
@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/twinmind/newo-tool/internal/redact"
+)
+
+// Diff styles accepted by --diff-style.
+const (
+	// StyleUnified lists removed and added lines one under the other, with context.
+	StyleUnified = "unified"
+	// StyleSideBySide shows the two versions in adjacent columns, a changed line next to its replacement.
+	StyleSideBySide = "side-by-side"
+	// StyleMinimal prints only the changed lines, without a table, for narrow terminals and CI logs.
+	StyleMinimal = "minimal"
+)
+
+// Styles lists the accepted diff styles.
+var Styles = []string{StyleUnified, StyleSideBySide, StyleMinimal}
+
+var (
+	styleMu sync.RWMutex
+	style   = StyleUnified
+)
+
+// SetStyle selects how Format renders diffs; an empty style means StyleUnified.
+func SetStyle(s string) error {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		s = StyleUnified
+	case StyleUnified, StyleSideBySide, StyleMinimal:
+	default:
+		return fmt.Errorf("unknown diff style %q (use %s)", s, strings.Join(Styles, ", "))
+	}
+	styleMu.Lock()
+	style = s
+	styleMu.Unlock()
+	return nil
+}
+
+func currentStyle() string {
+	styleMu.RLock()
+	defer styleMu.RUnlock()
+	return style
+}
+
+// formatSideBySide pairs each deleted line with the added line at the same position in its change, so
+// an edited line and its replacement share a row.
+func formatSideBySide(path string, lines []Line) string {
+	var rows [][]cell
+	side := func(line *Line) (cell, cell) {
+		if line == nil {
+			return cell{alignRight: true}, cell{}
+		}
+		displayNumber, plainNumber := formatLineNumber(*line)
+		displayText, plainText := formatLineText(*line)
+		return cell{display: displayNumber, plain: plainNumber, alignRight: true}, cell{display: displayText, plain: plainText}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == "context" {
+			number := fmt.Sprintf("%d", lines[i].LocalLine)
+			remoteNumber := fmt.Sprintf("%d", lines[i].RemoteLine)
+			text := redact.String(lines[i].Text)
+			rows = append(rows, []cell{
+				{display: number, plain: number, alignRight: true}, {display: text, plain: text},
+				{display: remoteNumber, plain: remoteNumber, alignRight: true}, {display: text, plain: text},
+			})
+			i++
+			continue
+		}
+		var dels, adds []*Line
+		for ; i < len(lines) && lines[i].Kind != "context"; i++ {
+			if lines[i].Kind == "del" {
+				dels = append(dels, &lines[i])
+			} else {
+				adds = append(adds, &lines[i])
+			}
+		}
+		for k := 0; k < max(len(dels), len(adds)); k++ {
+			var del, add *Line
+			if k < len(dels) {
+				del = dels[k]
+			}
+			if k < len(adds) {
+				add = adds[k]
+			}
+			delNumber, delText := side(del)
+			addNumber, addText := side(add)
+			rows = append(rows, []cell{delNumber, delText, addNumber, addText})
+		}
+	}
+	return renderTable(diffHeader(path, lines), rows, []int{2, 0, 2, 0})
+}
+
+// formatMinimal prints the changed lines with their numbers under a one-line header.
+func formatMinimal(path string, lines []Line) string {
+	numberWidth := 0
+	for _, line := range lines {
+		if line.Kind == "context" {
+			continue
+		}
+		_, plain := formatLineNumber(line)
+		numberWidth = max(numberWidth, len(plain))
+	}
+
+	var builder strings.Builder
+	builder.WriteString("  ")
+	builder.WriteString(diffHeader(path, lines))
+	builder.WriteString("\n")
+	for _, line := range lines {
+		if line.Kind == "context" {
+			continue
+		}
+		displayNumber, plainNumber := formatLineNumber(line)
+		displayText, _ := formatLineText(line)
+		builder.WriteString("  ")
+		builder.WriteString(padANSI(displayNumber, plainNumber, numberWidth, true))
+		builder.WriteString(" ")
+		builder.WriteString(displayText)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,195 @@
+package diff
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxWordCells bounds the token comparison of one pair of lines; longer pairs are shown as whole-line
+// changes.
+const maxWordCells = 1 << 20
+
+// Span is a byte range of a line's text.
+type Span struct {
+	Start int
+	End   int
+}
+
+// token is a word, a run of whitespace, or a single symbol together with the marks and joiners
+// attached to it, so that accented letters and emoji sequences are never split.
+type token struct {
+	text  string
+	start int
+	space bool
+}
+
+// highlightWords pairs the deleted and added lines of each change in order and marks the words that
+// differ between the two lines of a pair.
+func highlightWords(lines []Line) {
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == "context" {
+			i++
+			continue
+		}
+		var dels, adds []int
+		for ; i < len(lines) && lines[i].Kind != "context"; i++ {
+			if lines[i].Kind == "del" {
+				dels = append(dels, i)
+			} else {
+				adds = append(adds, i)
+			}
+		}
+		for k := 0; k < len(dels) && k < len(adds); k++ {
+			lines[dels[k]].Changes, lines[adds[k]].Changes = wordChanges(lines[dels[k]].Text, lines[adds[k]].Text)
+		}
+	}
+}
+
+// wordChanges returns the spans of a and of b outside their longest common token sequence. It returns
+// nil spans when the lines share no words, since highlighting all of both would say nothing.
+func wordChanges(a, b string) ([]Span, []Span) {
+	ta, tb := tokenize(a), tokenize(b)
+	m, n := len(ta), len(tb)
+	if m == 0 || n == 0 || m*n > maxWordCells {
+		return nil, nil
+	}
+
+	lcs := make([][]int, m+1)
+	for i := range lcs {
+		lcs[i] = make([]int, n+1)
+	}
+	for i := m - 1; i >= 0; i-- {
+		for j := n - 1; j >= 0; j-- {
+			if ta[i].text == tb[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changedA, changedB := make([]bool, m), make([]bool, n)
+	sharedWord := false
+	for i, j := 0, 0; i < m || j < n; {
+		switch {
+		case i < m && j < n && ta[i].text == tb[j].text:
+			if !ta[i].space {
+				sharedWord = true
+			}
+			i++
+			j++
+		case j < n && (i == m || lcs[i][j+1] >= lcs[i+1][j]):
+			changedB[j] = true
+			j++
+		default:
+			changedA[i] = true
+			i++
+		}
+	}
+	if !sharedWord {
+		return nil, nil
+	}
+	return changedSpans(ta, changedA), changedSpans(tb, changedB)
+}
+
+// changedSpans merges changed tokens into spans. Whitespace between two changed tokens joins them, so
+// a rewritten phrase is marked as one span rather than word by word.
+func changedSpans(tokens []token, changed []bool) []Span {
+	var spans []Span
+	for i := 0; i < len(tokens); i++ {
+		if !changed[i] {
+			continue
+		}
+		start := tokens[i].start
+		end := i
+		for k := i + 1; k < len(tokens); k++ {
+			if changed[k] {
+				end = k
+			} else if !tokens[k].space {
+				break
+			}
+		}
+		spans = append(spans, Span{Start: start, End: tokens[end].start + len(tokens[end].text)})
+		i = end
+	}
+	return spans
+}
+
+// tokenize splits text into words, whitespace runs, and symbols.
+func tokenize(text string) []token {
+	var tokens []token
+	joined := false
+	for offset := 0; offset < len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		attach := len(tokens) > 0 && (joined || extendsCluster(r) ||
+			(isWordRune(r) && isWordToken(tokens[len(tokens)-1].text)) ||
+			(unicode.IsSpace(r) && tokens[len(tokens)-1].space) ||
+			(isRegionalIndicator(r) && isLoneRegionalIndicator(tokens[len(tokens)-1].text)))
+		if attach {
+			last := &tokens[len(tokens)-1]
+			last.text = text[last.start : offset+size]
+		} else {
+			tokens = append(tokens, token{text: text[offset : offset+size], start: offset, space: unicode.IsSpace(r)})
+		}
+		joined = r == zeroWidthJoiner
+		offset += size
+	}
+	return tokens
+}
+
+const zeroWidthJoiner = '\u200d'
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isWordToken(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return isWordRune(r)
+}
+
+// extendsCluster reports whether r belongs to the character before it: combining marks, joiners,
+// variation selectors, and emoji modifiers.
+func extendsCluster(r rune) bool {
+	return r == zeroWidthJoiner ||
+		unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(r >= 0xfe00 && r <= 0xfe0f) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) ||
+		(r >= 0xe0020 && r <= 0xe007f)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isLoneRegionalIndicator reports whether text is the first half of a flag.
+func isLoneRegionalIndicator(text string) bool {
+	r, size := utf8.DecodeRuneInString(text)
+	return size == len(text) && isRegionalIndicator(r)
+}
+
+// displayWidth is the number of terminal columns s takes: wide East Asian characters and emoji take
+// two, and marks, joiners, and the characters joined to an emoji take none.
+func displayWidth(s string) int {
+	width := 0
+	joined := false
+	for _, r := range s {
+		if !joined && (!extendsCluster(r) || unicode.Is(unicode.Mc, r)) {
+			width += runeWidth(r)
+		}
+		joined = r == zeroWidthJoiner
+	}
+	return width
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0x1f300 && r <= 0x1faff, r >= 0x2e80 && r <= 0xa4cf, r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff, r >= 0xfe30 && r <= 0xfe4f, r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6, r >= 0x1100 && r <= 0x115f, r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
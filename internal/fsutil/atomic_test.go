@@ -0,0 +1,37 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAtomicWriteReplacesFileWithoutLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "skill.nsl")
+	if err := os.WriteFile(path, []byte("old"), FilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AtomicWrite(path, []byte("new content"), FilePerm); err != nil {
+		t.Fatalf("AtomicWrite: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new content" {
+		t.Fatalf("expected the new content, got %q, %v", data, err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != FilePerm {
+			t.Fatalf("expected mode %o, got %v, %v", FilePerm, info.Mode().Perm(), err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the target file in %s, got %v, %v", dir, entries, err)
+	}
+
+	if err := AtomicWrite(filepath.Join(dir, "missing", "file"), []byte("x"), FilePerm); err == nil {
+		t.Fatalf("expected an error when the parent directory does not exist")
+	}
+}
@@ -0,0 +1,334 @@
+package fsutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrLocked indicates the workspace is already locked by another process.
+var ErrLocked = errors.New("workspace is locked")
+
+// Lock states reported by LockInfo.State.
+const (
+	// LockHeld means the owning process is still running on this host.
+	LockHeld = "held"
+	// LockDead means the owning process ran on this host and has exited.
+	LockDead = "dead"
+	// LockStale means the owner cannot be checked and the lock is older than the staleness window.
+	LockStale = "stale"
+	// LockUnknown means the owner cannot be checked, for example because it runs on another host.
+	LockUnknown = "unknown"
+)
+
+const lockFileExt = ".lock"
+
+// fileLockRetry is how often AcquireFileLock checks whether a held lock was released.
+const fileLockRetry = 20 * time.Millisecond
+
+// takeoverFileExt marks the guard file held while an abandoned lock is replaced. A guard older than
+// takeoverStaleAfter was left by a process that died during the takeover.
+const (
+	takeoverFileExt    = ".takeover"
+	takeoverStaleAfter = time.Minute
+)
+
+var (
+	heldLocksMu sync.Mutex
+	heldLocks   = map[string]*os.File{}
+
+	// takeoverHook runs once a lock has been judged abandoned; tests use it to line up takeovers.
+	takeoverHook = func() {}
+)
+
+// LockInfo records who holds a customer's workspace lock. It is stored as JSON in the lock file.
+type LockInfo struct {
+	Customer  string    `json:"customer"`
+	Operation string    `json:"operation"`
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"started_at"`
+	// Path is the lock file the information was read from.
+	Path string `json:"-"`
+}
+
+// State reports whether the lock owner is still around. Owners on this host are checked directly;
+// for any other owner the staleness window decides.
+func (i LockInfo) State(now time.Time) string {
+	if i.PID > 0 && i.Hostname != "" && i.Hostname == hostname() {
+		if alive, known := processAlive(i.PID); known {
+			if alive {
+				return LockHeld
+			}
+			return LockDead
+		}
+	}
+	if now.Sub(i.StartedAt) > lockStaleAfter {
+		return LockStale
+	}
+	return LockUnknown
+}
+
+// Describe summarises the owner for messages, for example "push (pid 4242 on build-1, started 3m ago)".
+func (i LockInfo) Describe(now time.Time) string {
+	owner := "unknown process"
+	if i.PID > 0 {
+		owner = fmt.Sprintf("pid %d", i.PID)
+		if i.Hostname != "" {
+			owner += " on " + i.Hostname
+		}
+	}
+	operation := i.Operation
+	if operation == "" {
+		operation = "unknown operation"
+	}
+	return fmt.Sprintf("%s (%s, started %s ago)", operation, owner, now.Sub(i.StartedAt).Round(time.Second))
+}
+
+// LockedError reports a lock held by another process. It matches ErrLocked with errors.Is.
+type LockedError struct {
+	Info LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("customer %s is locked by %s", e.Info.Customer, e.Info.Describe(time.Now()))
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+func lockDirectory() string {
+	return filepath.Join(StateDirName, LocksDirName)
+}
+
+func lockPath(customerIDN string) string {
+	name := strings.ToLower(strings.TrimSpace(customerIDN))
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	return filepath.Join(lockDirectory(), name+lockFileExt)
+}
+
+// AcquireLock creates the lock file of a customer, preventing concurrent destructive operations on its
+// state while leaving other customers free. A lock left behind by a process that has exited, or one older
+// than the staleness window whose owner cannot be checked, is taken over.
+func AcquireLock(customerIDN, operation string) (func() error, error) {
+	if strings.TrimSpace(customerIDN) == "" {
+		return nil, errors.New("lock: customer IDN is required")
+	}
+	if err := EnsureDir(lockDirectory()); err != nil {
+		return nil, fmt.Errorf("ensure lock directory: %w", err)
+	}
+	return createLock(lockPath(customerIDN), customerIDN, operation)
+}
+
+// AcquireFileLock locks path for a short read-modify-write of a file shared by every customer, such
+// as a registry. Unlike a customer lock it waits up to wait for another holder to finish.
+func AcquireFileLock(path, operation string, wait time.Duration) (func() error, error) {
+	if err := EnsureParentDir(path); err != nil {
+		return nil, fmt.Errorf("ensure lock directory: %w", err)
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		release, err := createLock(path, strings.TrimSuffix(filepath.Base(path), lockFileExt), operation)
+		if err == nil || !errors.Is(err, ErrLocked) || time.Now().After(deadline) {
+			return release, err
+		}
+		time.Sleep(fileLockRetry)
+	}
+}
+
+// createLock creates the lock file at path on behalf of name, taking over a lock whose owner has exited
+// or gone stale.
+func createLock(path, name, operation string) (func() error, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, FilePerm)
+	if errors.Is(err, os.ErrExist) {
+		file, err = takeOverLock(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create lock file: %w", err)
+	}
+
+	info := LockInfo{Customer: name, Operation: operation, PID: os.Getpid(), Hostname: hostname(), StartedAt: time.Now().UTC()}
+	if data, err := json.Marshal(info); err == nil {
+		_, _ = file.Write(data)
+	}
+
+	heldLocksMu.Lock()
+	heldLocks[path] = file
+	heldLocksMu.Unlock()
+
+	release := func() error {
+		heldLocksMu.Lock()
+		delete(heldLocks, path)
+		heldLocksMu.Unlock()
+		_ = file.Close()
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove lock file: %w", err)
+		}
+		return nil
+	}
+	return release, nil
+}
+
+// takeOverLock replaces the lock file at path when its owner has exited or gone stale. Processes that
+// find the same abandoned lock take turns through a guard file, and each checks under the guard that the
+// lock is still the one it judged abandoned, so a lock that another process has just taken over is never
+// removed.
+func takeOverLock(path string) (*os.File, error) {
+	info, err := ReadLock(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return createLockFile(path)
+		}
+		return nil, err
+	}
+	if state := info.State(time.Now()); state != LockDead && state != LockStale {
+		return nil, &LockedError{Info: info}
+	}
+	takeoverHook()
+
+	guardPath := path + takeoverFileExt
+	guard, err := os.OpenFile(guardPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, FilePerm)
+	if errors.Is(err, os.ErrExist) {
+		// A guard outlives its process only if that process died mid-takeover.
+		if stat, statErr := os.Stat(guardPath); statErr == nil && time.Since(stat.ModTime()) > takeoverStaleAfter {
+			_ = os.Remove(guardPath)
+		}
+		return nil, &LockedError{Info: info}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create lock file: %w", err)
+	}
+	defer func() {
+		_ = guard.Close()
+		_ = os.Remove(guardPath)
+	}()
+
+	current, err := ReadLock(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	case current.PID != info.PID || current.Hostname != info.Hostname || !current.StartedAt.Equal(info.StartedAt):
+		return nil, &LockedError{Info: current}
+	default:
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("remove abandoned lock file: %w", err)
+		}
+	}
+	return createLockFile(path)
+}
+
+// createLockFile creates the lock file at path, failing with a LockedError if it already exists.
+func createLockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, FilePerm)
+	if errors.Is(err, os.ErrExist) {
+		info, _ := ReadLock(path)
+		return nil, &LockedError{Info: info}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create lock file: %w", err)
+	}
+	return file, nil
+}
+
+// ReleaseLocks removes every lock file held by this process. It is meant for forced exits, where the
+// release functions returned by AcquireLock would not get a chance to run.
+func ReleaseLocks() {
+	heldLocksMu.Lock()
+	defer heldLocksMu.Unlock()
+	for path, file := range heldLocks {
+		_ = file.Close()
+		_ = os.Remove(path)
+		delete(heldLocks, path)
+	}
+}
+
+// ReadLock reads the owner of a lock file. Lock files written by older versions carry no owner, so
+// only the file name and the modification time are known.
+func ReadLock(path string) (LockInfo, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return LockInfo{}, fmt.Errorf("read lock file: %w", err)
+	}
+	info := LockInfo{}
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		_ = json.Unmarshal(data, &info)
+	}
+	if info.Customer == "" {
+		info.Customer = strings.TrimSuffix(filepath.Base(path), lockFileExt)
+	}
+	if info.StartedAt.IsZero() {
+		info.StartedAt = stat.ModTime()
+	}
+	info.Path = path
+	return info, nil
+}
+
+// ListLocks returns the locks currently present in the workspace, sorted by customer.
+func ListLocks() ([]LockInfo, error) {
+	entries, err := os.ReadDir(lockDirectory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read lock directory: %w", err)
+	}
+	var locks []LockInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), lockFileExt) {
+			continue
+		}
+		info, err := ReadLock(filepath.Join(lockDirectory(), entry.Name()))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		locks = append(locks, info)
+	}
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Customer < locks[j].Customer
+	})
+	return locks, nil
+}
+
+// BreakLock removes a customer's lock. Unless force is set, only locks whose owner has exited or gone stale are removed.
+func BreakLock(customerIDN string, force bool) (LockInfo, error) {
+	info, err := ReadLock(lockPath(customerIDN))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return LockInfo{}, fmt.Errorf("no lock is held for %s", customerIDN)
+		}
+		return LockInfo{}, err
+	}
+	if state := info.State(time.Now()); !force && state != LockDead && state != LockStale {
+		return info, fmt.Errorf("lock of %s is %s, taken by %s; use --force if you are sure it is abandoned", customerIDN, state, info.Describe(time.Now()))
+	}
+	if err := os.Remove(info.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return info, fmt.Errorf("remove lock file: %w", err)
+	}
+	return info, nil
+}
+
+var (
+	hostnameOnce  sync.Once
+	hostnameValue string
+)
+
+func hostname() string {
+	hostnameOnce.Do(func() {
+		hostnameValue, _ = os.Hostname()
+	})
+	return hostnameValue
+}
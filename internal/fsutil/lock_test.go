@@ -0,0 +1,189 @@
+package fsutil
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReleaseLocksRemovesHeldLocks(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	release, err := AcquireLock("acme", "push")
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	if _, err := AcquireLock("acme", "push"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+
+	other, err := AcquireLock("globex", "pull")
+	if err != nil {
+		t.Fatalf("expected another customer to be lockable: %v", err)
+	}
+	_ = other()
+
+	ReleaseLocks()
+	again, err := AcquireLock("acme", "push")
+	if err != nil {
+		t.Fatalf("expected the lock to be free after ReleaseLocks: %v", err)
+	}
+	if err := again(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := release(); err != nil {
+		t.Fatalf("expected a second release to be harmless: %v", err)
+	}
+}
+
+func TestAcquireLockTakesOverDeadOwner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process liveness is not checked on Windows")
+	}
+	t.Chdir(t.TempDir())
+	if err := EnsureDir(lockDirectory()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A child process that has already exited stands in for a crashed owner.
+	dead := LockInfo{Customer: "acme", Operation: "pull", PID: exitedPID(t), Hostname: hostname(), StartedAt: time.Now()}
+	writeLockInfo(t, dead)
+	if state := dead.State(time.Now()); state != LockDead {
+		t.Fatalf("expected dead owner, got %s", state)
+	}
+	release, err := AcquireLock("acme", "pull")
+	if err != nil {
+		t.Fatalf("expected a dead owner's lock to be taken over: %v", err)
+	}
+	info, err := ReadLock(lockPath("acme"))
+	if err != nil || info.PID != os.Getpid() {
+		t.Fatalf("expected the lock to record this process, got %+v, %v", info, err)
+	}
+	_ = release()
+
+	remote := LockInfo{Customer: "acme", Operation: "push", PID: 1, Hostname: "elsewhere", StartedAt: time.Now()}
+	writeLockInfo(t, remote)
+	var locked *LockedError
+	if _, err := AcquireLock("acme", "push"); !errors.As(err, &locked) || locked.Info.Hostname != "elsewhere" {
+		t.Fatalf("expected a LockedError naming the other host, got %v", err)
+	}
+	if _, err := BreakLock("acme", false); err == nil {
+		t.Fatalf("expected break without --force to refuse a lock of unknown state")
+	}
+	if _, err := BreakLock("acme", true); err != nil {
+		t.Fatalf("BreakLock --force: %v", err)
+	}
+
+	remote.StartedAt = time.Now().Add(-2 * lockStaleAfter)
+	writeLockInfo(t, remote)
+	locks, err := ListLocks()
+	if err != nil || len(locks) != 1 || locks[0].State(time.Now()) != LockStale {
+		t.Fatalf("expected one stale lock, got %+v, %v", locks, err)
+	}
+	if _, err := BreakLock("acme", false); err != nil {
+		t.Fatalf("expected a stale lock to be breakable: %v", err)
+	}
+}
+
+func writeLockInfo(t *testing.T, info LockInfo) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockPath(info.Customer), data, FilePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proc, err := os.StartProcess(exe, []string{exe, "-test.run=^$"}, &os.ProcAttr{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proc.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	return proc.Pid
+}
+
+func TestAcquireFileLockWaitsForHolder(t *testing.T) {
+	t.Chdir(t.TempDir())
+	path := ".newo/registry.json.lock"
+
+	release, err := AcquireFileLock(path, "save", time.Second)
+	if err != nil {
+		t.Fatalf("AcquireFileLock: %v", err)
+	}
+	if _, err := AcquireFileLock(path, "save", 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked without waiting, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = release()
+	}()
+	again, err := AcquireFileLock(path, "save", 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected the lock once the holder released it: %v", err)
+	}
+	if err := again(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+}
+
+func TestAcquireLockConcurrentTakeoversHaveOneWinner(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := EnsureDir(lockDirectory()); err != nil {
+		t.Fatal(err)
+	}
+	writeLockInfo(t, LockInfo{Customer: "acme", Operation: "pull", PID: 1, Hostname: "elsewhere", StartedAt: time.Now().Add(-2 * lockStaleAfter)})
+
+	// Both contenders judge the stale lock abandoned before either replaces it.
+	const contenders = 2
+	var judged sync.WaitGroup
+	judged.Add(contenders)
+	takeoverHook = func() {
+		judged.Done()
+		judged.Wait()
+	}
+	t.Cleanup(func() { takeoverHook = func() {} })
+
+	var wg sync.WaitGroup
+	releases := make(chan func() error, contenders)
+	for i := 0; i < contenders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := AcquireLock("acme", "push")
+			if err == nil {
+				releases <- release
+			} else if !errors.Is(err, ErrLocked) {
+				t.Errorf("AcquireLock: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(releases)
+
+	if len(releases) != 1 {
+		t.Fatalf("expected exactly one contender to take over the stale lock, got %d", len(releases))
+	}
+	for release := range releases {
+		if err := release(); err != nil {
+			t.Fatalf("release: %v", err)
+		}
+	}
+	if _, err := os.Stat(lockPath("acme") + takeoverFileExt); !os.IsNotExist(err) {
+		t.Fatalf("expected the takeover guard to be removed, got %v", err)
+	}
+}
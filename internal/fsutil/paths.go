@@ -1,7 +1,6 @@
 package fsutil
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,7 +11,7 @@ import (
 const (
 	DefaultCustomersDir = "newo_customers"
 	StateDirName        = ".newo"
-	lockDirName         = "locks"
+	LocksDirName        = "locks"
 	lockStaleAfter      = 15 * time.Minute
 
 	// Directory and file permissions used across the workspace.
@@ -27,13 +26,80 @@ const (
 	FlowsYAML        = "flows.yaml"
 	MapJSON          = "map.json"
 	HashesJSON       = "hashes.json"
+	HashesDirName    = "hashes"
+	HashIndexJSON    = "index.json"
+	FileHashesJSON   = "file-hashes.json"
+	BlobsDirName     = "blobs"
 	APIKeysJSON      = "api-keys.json"
 	MetadataYAML     = "metadata.yaml"
 	SkillMetaFileExt = ".meta.yaml"
+	SnapshotsDirName = "snapshots"
+	PushJournalJSON  = "push-journal.json"
+	ChangelogJSON    = "changelog.json"
+	TestsDir         = "tests"
 )
 
-// ErrLocked indicates the workspace is already locked by another process.
-var ErrLocked = errors.New("workspace is locked")
+// WorkspaceRelative returns path relative to the workspace root, with forward slashes. The CLI runs
+// from the workspace root, so absolute paths are made relative to the working directory; relative
+// paths are only cleaned. Paths recorded in state use this form so the workspace can be cloned or
+// moved without invalidating it.
+func WorkspaceRelative(path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// outputRoot is the export root of the workspace, as configured; AnchorStatePath re-anchors paths on it.
+var outputRoot = DefaultCustomersDir
+
+// SetOutputRoot records the workspace's export root for AnchorStatePath.
+func SetOutputRoot(root string) {
+	if strings.TrimSpace(root) == "" {
+		root = "."
+	}
+	outputRoot = filepath.Clean(root)
+}
+
+// AnchorStatePath rewrites an absolute path that older versions recorded in state as a
+// workspace-relative one. A path inside the working directory is made relative to it. A path from a
+// workspace that has since been moved or cloned is re-anchored on the output root: whatever follows
+// the last directory named like the output root (or its _e2e sibling) is joined to the current root.
+func AnchorStatePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	rel := WorkspaceRelative(path)
+	if rel != ".." && !strings.HasPrefix(rel, "../") && !filepath.IsAbs(filepath.FromSlash(rel)) {
+		return rel
+	}
+
+	base := filepath.Base(outputRoot)
+	if base == "." || base == ".." || base == string(filepath.Separator) {
+		return rel
+	}
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		root := outputRoot
+		switch segments[i] {
+		case base:
+		case base + "_e2e":
+			root += "_e2e"
+		default:
+			continue
+		}
+		return filepath.ToSlash(filepath.Join(append([]string{root}, segments[i+1:]...)...))
+	}
+	return rel
+}
 
 // ExportProjectRoot returns the root directory for exported project assets.
 func ExportProjectRoot(root, projectSlug string) string {
@@ -83,14 +149,36 @@ func ExportFlowsYAMLPath(root, customerType, customerIDN, projectSlug string) st
 	return filepath.Join(ExportProjectDir(root, customerType, customerIDN, projectSlug), FlowsYAML)
 }
 
-// ExportFlowDir returns the directory for a flow's assets.
-func ExportFlowDir(root, customerType, customerIDN, projectSlug, agentIDN, flowIDN string) string {
-	baseDir := ExportProjectDir(root, customerType, customerIDN, projectSlug)
+// ExportTestsDir returns the directory holding a project's skill test suites.
+func ExportTestsDir(root, customerType, customerIDN, projectSlug string) string {
+	return filepath.Join(ExportProjectDir(root, customerType, customerIDN, projectSlug), TestsDir)
+}
+
+// HasAgentDirs reports whether exports for the customer type keep each agent in its own directory.
+// Integration and e2e exports place every flow directly under the project.
+func HasAgentDirs(customerType string) bool {
 	customerType = strings.ToLower(strings.TrimSpace(customerType))
-	if customerType == "integration" || customerType == "e2e" {
-		return filepath.Join(baseDir, FlowsDir, flowIDN)
+	return customerType != "integration" && customerType != "e2e"
+}
+
+// ExportAgentDir returns the directory holding an agent's flows. For layouts without agent
+// directories this is the project directory.
+func ExportAgentDir(root, customerType, customerIDN, projectSlug, agentIDN string) string {
+	return ProjectAgentDir(ExportProjectDir(root, customerType, customerIDN, projectSlug), customerType, agentIDN)
+}
+
+// ProjectAgentDir returns the directory holding an agent's flows inside an exported project directory.
+// The prompt_script paths in flows.yaml are relative to it.
+func ProjectAgentDir(projectDir, customerType, agentIDN string) string {
+	if !HasAgentDirs(customerType) {
+		return projectDir
 	}
-	return filepath.Join(baseDir, agentIDN, FlowsDir, flowIDN)
+	return filepath.Join(projectDir, agentIDN)
+}
+
+// ExportFlowDir returns the directory for a flow's assets.
+func ExportFlowDir(root, customerType, customerIDN, projectSlug, agentIDN, flowIDN string) string {
+	return filepath.Join(ExportAgentDir(root, customerType, customerIDN, projectSlug, agentIDN), FlowsDir, flowIDN)
 }
 
 // ExportFlowMetadataPath returns the path for a flow's metadata YAML file.
@@ -138,43 +226,6 @@ func EnsureParentDir(filePath string) error {
 	return EnsureDir(dir)
 }
 
-func lockDirectory() string {
-	return filepath.Join(StateDirName, lockDirName)
-}
-
-// AcquireLock creates a lock file preventing concurrent destructive operations.
-func AcquireLock(operation string) (func() error, error) {
-	if err := EnsureDir(lockDirectory()); err != nil {
-		return nil, fmt.Errorf("ensure lock directory: %w", err)
-	}
-	lockPath := filepath.Join(lockDirectory(), fmt.Sprintf("%s.lock", operation))
-
-	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, FilePerm)
-	if err != nil {
-		if errors.Is(err, os.ErrExist) {
-			info, statErr := os.Stat(lockPath)
-			if statErr == nil {
-				if time.Since(info.ModTime()) > lockStaleAfter {
-					_ = os.Remove(lockPath)
-					// retry once after removing stale lock
-					return AcquireLock(operation)
-				}
-			}
-			return nil, ErrLocked
-		}
-		return nil, fmt.Errorf("create lock file: %w", err)
-	}
-
-	release := func() error {
-		_ = file.Close()
-		if err := os.Remove(lockPath); err != nil && !errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("remove lock file: %w", err)
-		}
-		return nil
-	}
-	return release, nil
-}
-
 func projectDir(customerIDN, projectIDN string) string {
 	return filepath.Join(CustomerRoot(customerIDN), ProjectsDir, projectIDN)
 }
@@ -241,11 +292,51 @@ func MapPath(customerIDN string) string {
 	return filepath.Join(CustomerStateDir(customerIDN), MapJSON)
 }
 
-// HashesPath returns hashes.json path.
+// HashesPath returns the legacy single-file hashes.json path.
 func HashesPath(customerIDN string) string {
 	return filepath.Join(CustomerStateDir(customerIDN), HashesJSON)
 }
 
+// HashesDir returns the directory holding the bucketed hash store and its index.
+func HashesDir(customerIDN string) string {
+	return filepath.Join(CustomerStateDir(customerIDN), HashesDirName)
+}
+
+// FileHashCachePath returns the path of the cache recording the size, modification time, and hash of exported files.
+func FileHashCachePath(customerIDN string) string {
+	return filepath.Join(CustomerStateDir(customerIDN), FileHashesJSON)
+}
+
+// BlobsDir returns the directory of the content-addressed blob store shared by all customers.
+func BlobsDir() string {
+	return filepath.Join(StateDirName, BlobsDirName)
+}
+
+// PushJournalPath returns the path of the journal recording scripts replaced by push.
+func PushJournalPath(customerIDN string) string {
+	return filepath.Join(CustomerStateDir(customerIDN), PushJournalJSON)
+}
+
+// ChangelogPath returns the path of the log of remote changes seen by pull.
+func ChangelogPath(customerIDN string) string {
+	return filepath.Join(CustomerStateDir(customerIDN), ChangelogJSON)
+}
+
+// SnapshotsDir returns the directory holding state snapshots for a customer.
+func SnapshotsDir(customerIDN string) string {
+	return filepath.Join(CustomerStateDir(customerIDN), SnapshotsDirName)
+}
+
+// DeployCheckpointPath returns the path storing partial deploy progress for a project.
+func DeployCheckpointPath(customerIDN, projectIDN string) string {
+	return filepath.Join(CustomerStateDir(customerIDN), fmt.Sprintf("deploy-%s.json", strings.ToLower(projectIDN)))
+}
+
+// PullCheckpointPath returns the path storing the flows an interrupted pull already wrote for a project.
+func PullCheckpointPath(customerIDN, projectIDN string) string {
+	return filepath.Join(CustomerStateDir(customerIDN), fmt.Sprintf("pull-%s.json", strings.ToLower(projectIDN)))
+}
+
 // AttributesPath returns attributes.yaml path.
 func AttributesPath(customerIDN string) string {
 	return filepath.Join(CustomerRoot(customerIDN), AttributesYAML)
@@ -2,6 +2,7 @@ package fsutil
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -34,3 +35,43 @@ func TestEnsureWorkspaceCreatesDirs(t *testing.T) {
 		t.Fatalf("state dir missing: %v", err)
 	}
 }
+
+func TestAnchorStatePathReanchorsMovedWorkspaces(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	t.Cleanup(func() { SetOutputRoot(DefaultCustomersDir) })
+
+	SetOutputRoot("exports/newo")
+	cases := map[string]string{
+		filepath.Join(cwd, "exports", "newo", "acme", "greet.nsl"):              "exports/newo/acme/greet.nsl",
+		filepath.Join("/", "old", "ws", "exports", "newo", "acme", "greet.nsl"): "exports/newo/acme/greet.nsl",
+		filepath.Join("/", "old", "ws", "newo_e2e", "acme", "shop", "a.nsl"):    "exports/newo_e2e/acme/shop/a.nsl",
+	}
+	for path, want := range cases {
+		if got := AnchorStatePath(path); got != want {
+			t.Fatalf("AnchorStatePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestWorkspaceRelative(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+
+	cases := map[string]string{
+		filepath.Join(cwd, "out", "acme", "greet.nsl"): "out/acme/greet.nsl",
+		filepath.Join(filepath.Dir(cwd), "shared"):     "../shared",
+		"./out/acme": "out/acme",
+	}
+	for path, want := range cases {
+		if got := WorkspaceRelative(path); got != want {
+			t.Fatalf("WorkspaceRelative(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
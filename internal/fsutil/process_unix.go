@@ -0,0 +1,14 @@
+//go:build !windows
+
+package fsutil
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given PID exists on this host.
+func processAlive(pid int) (alive bool, known bool) {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM), true
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package fsutil
+
+// processAlive cannot check other processes on Windows, so lock ownership falls back to the staleness window.
+func processAlive(int) (alive bool, known bool) {
+	return false, false
+}
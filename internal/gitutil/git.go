@@ -0,0 +1,149 @@
+// Package gitutil runs the few git commands newo needs to tie workspace changes to version control.
+package gitutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository indicates the directory is not inside a git work tree, or git is not installed.
+var ErrNotRepository = errors.New("not a git repository")
+
+// Repo is a git work tree.
+type Repo struct {
+	// Root is the absolute top-level directory of the work tree.
+	Root string
+}
+
+// Change is one entry of `git status`.
+type Change struct {
+	// Status is the two-letter porcelain status, for example " M" or "??".
+	Status string
+	// Path is relative to the repository root and uses forward slashes.
+	Path string
+}
+
+// DiffStat counts the staged changes of a commit by kind.
+type DiffStat struct {
+	Added    int
+	Modified int
+	Deleted  int
+}
+
+// Total returns the number of changed files.
+func (s DiffStat) Total() int {
+	return s.Added + s.Modified + s.Deleted
+}
+
+func (s DiffStat) String() string {
+	return fmt.Sprintf("%d file(s) changed: %d added, %d modified, %d deleted", s.Total(), s.Added, s.Modified, s.Deleted)
+}
+
+// Open finds the work tree containing dir.
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, ErrNotRepository
+	}
+	out, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, ErrNotRepository
+	}
+	root := strings.TrimSpace(out)
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	return &Repo{Root: root}, nil
+}
+
+// Rel returns path relative to the repository root with forward slashes. It reports false when the
+// path lies outside the work tree.
+func (r *Repo) Rel(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(r.Root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Changes lists uncommitted changes, including untracked files, in the whole work tree.
+func (r *Repo) Changes(ctx context.Context) ([]Change, error) {
+	out, err := run(ctx, r.Root, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		change := Change{Status: entry[:2], Path: entry[3:]}
+		changes = append(changes, change)
+		// Renames and copies are followed by their original path.
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return changes, nil
+}
+
+// Stage stages every change under paths, including deletions and new files, and counts what is staged there.
+func (r *Repo) Stage(ctx context.Context, paths ...string) (DiffStat, error) {
+	args := append([]string{"add", "-A", "--"}, paths...)
+	if _, err := run(ctx, r.Root, args...); err != nil {
+		return DiffStat{}, err
+	}
+	args = append([]string{"diff", "--cached", "--name-status", "--no-renames", "--"}, paths...)
+	out, err := run(ctx, r.Root, args...)
+	if err != nil {
+		return DiffStat{}, err
+	}
+	var stat DiffStat
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "A"):
+			stat.Added++
+		case strings.HasPrefix(line, "D"):
+			stat.Deleted++
+		case line != "":
+			stat.Modified++
+		}
+	}
+	return stat, nil
+}
+
+// Commit records the staged changes under paths, leaving anything else the user has staged untouched.
+func (r *Repo) Commit(ctx context.Context, message string, paths ...string) error {
+	args := append([]string{"commit", "--quiet", "-m", message, "--"}, paths...)
+	_, err := run(ctx, r.Root, args...)
+	return err
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,116 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	if _, err := run(context.Background(), dir, "init", "--quiet"); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+	repo, err := Open(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return repo
+}
+
+func writeRepoFile(t *testing.T, repo *Repo, rel, content string) {
+	t.Helper()
+	path := filepath.Join(repo.Root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", rel, err)
+	}
+}
+
+func TestOpenOutsideRepository(t *testing.T) {
+	t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+	if _, err := Open(context.Background(), t.TempDir()); err != ErrNotRepository {
+		t.Fatalf("expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestStageAndCommitOnlyTouchPaths(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	writeRepoFile(t, repo, "out/acme/a.guidance", "a")
+	writeRepoFile(t, repo, "out/acme/b.guidance", "b")
+	writeRepoFile(t, repo, "notes.txt", "draft")
+
+	stat, err := repo.Stage(ctx, "out")
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if stat != (DiffStat{Added: 2}) {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+	if err := repo.Commit(ctx, "first", "out"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	writeRepoFile(t, repo, "out/acme/a.guidance", "changed")
+	if err := os.Remove(filepath.Join(repo.Root, "out", "acme", "b.guidance")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	writeRepoFile(t, repo, "out/acme/c.guidance", "c")
+	stat, err = repo.Stage(ctx, "out")
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if stat != (DiffStat{Added: 1, Modified: 1, Deleted: 1}) {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+	if err := repo.Commit(ctx, "second", "out"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	changes, err := repo.Changes(ctx)
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "notes.txt" || changes[0].Status != "??" {
+		t.Fatalf("expected only notes.txt to remain uncommitted, got %+v", changes)
+	}
+	log, err := run(ctx, repo.Root, "log", "--format=%s")
+	if err != nil {
+		t.Fatalf("git log: %v", err)
+	}
+	if strings.Fields(log)[0] != "second" {
+		t.Fatalf("unexpected log %q", log)
+	}
+}
+
+func TestRel(t *testing.T) {
+	repo := newTestRepo(t)
+
+	rel, ok := repo.Rel(filepath.Join(repo.Root, "out", "acme"))
+	if !ok || rel != "out/acme" {
+		t.Fatalf("Rel inside = %q, %v", rel, ok)
+	}
+	if rel, ok := repo.Rel(repo.Root); !ok || rel != "." {
+		t.Fatalf("Rel root = %q, %v", rel, ok)
+	}
+	if _, ok := repo.Rel(filepath.Dir(repo.Root)); ok {
+		t.Fatal("expected parent directory to be outside the repository")
+	}
+}
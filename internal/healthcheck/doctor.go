@@ -0,0 +1,143 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+)
+
+// Check outcomes reported by the doctor checks.
+const (
+	StatusOK      = "ok"
+	StatusDenied  = "denied"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Result is the outcome of one doctor check.
+type Result struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// PermissionClient is the read-only part of the platform API that ProbePermissions exercises.
+type PermissionClient interface {
+	ListProjects(ctx context.Context) ([]platform.Project, error)
+	GetCustomerAttributes(ctx context.Context, includeHidden bool) (platform.CustomerAttributesResponse, error)
+	ListAgents(ctx context.Context, projectID string) ([]platform.Agent, error)
+	ListFlowSkills(ctx context.Context, flowID string) ([]platform.Skill, error)
+}
+
+// ProbePermissions runs a read-only set of API calls and reports which of them the API key may not
+// make. The skill listing is tried on the first flow of the first project that has one; calls that
+// depend on a denied one are skipped.
+func ProbePermissions(ctx context.Context, client PermissionClient) []Result {
+	var results []Result
+
+	projects, err := client.ListProjects(ctx)
+	results = append(results, permissionResult("list projects", err, fmt.Sprintf("%d project(s)", len(projects))))
+
+	attributes, err := client.GetCustomerAttributes(ctx, true)
+	results = append(results, permissionResult("read customer attributes", err, fmt.Sprintf("%d attribute(s)", len(attributes.Attributes))))
+
+	if len(projects) == 0 {
+		return append(results,
+			Result{Name: "list agents", Status: StatusSkipped, Detail: "no project to probe"},
+			Result{Name: "list skills", Status: StatusSkipped, Detail: "no project to probe"},
+		)
+	}
+
+	var flow *platform.Flow
+	var agentsErr error
+	for _, project := range projects {
+		agents, err := client.ListAgents(ctx, project.ID)
+		if err != nil {
+			agentsErr = err
+			break
+		}
+		for _, agent := range agents {
+			if len(agent.Flows) > 0 {
+				flow = &agent.Flows[0]
+				break
+			}
+		}
+		if flow != nil {
+			break
+		}
+	}
+	results = append(results, permissionResult("list agents", agentsErr, ""))
+	switch {
+	case agentsErr != nil:
+		return append(results, Result{Name: "list skills", Status: StatusSkipped, Detail: "agents could not be listed"})
+	case flow == nil:
+		return append(results, Result{Name: "list skills", Status: StatusSkipped, Detail: "no flow to probe"})
+	}
+
+	skills, err := client.ListFlowSkills(ctx, flow.ID)
+	return append(results, permissionResult("list skills", err, fmt.Sprintf("%d skill(s) in flow %s", len(skills), flow.IDN)))
+}
+
+// permissionResult classifies an API error: 401 and 403 mean the key lacks the permission, anything
+// else is a failure that says nothing about permissions.
+func permissionResult(name string, err error, detail string) Result {
+	if err == nil {
+		return Result{Name: name, Status: StatusOK, Detail: detail}
+	}
+	var apiErr *platform.APIError
+	if errors.As(err, &apiErr) && (apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden) {
+		return Result{Name: name, Status: StatusDenied, Detail: fmt.Sprintf("the API key lacks permission (status %d)", apiErr.Status)}
+	}
+	return Result{Name: name, Status: StatusFailed, Detail: err.Error()}
+}
+
+// CheckWorkspace reports whether the directories a customer's commands write to are writable and
+// whether the customer's workspace lock is free.
+func CheckWorkspace(outputRoot, customerIDN string) []Result {
+	if outputRoot == "" {
+		outputRoot = fsutil.DefaultCustomersDir
+	}
+	results := []Result{
+		writableResult("output directory", outputRoot),
+		writableResult("state directory", fsutil.CustomerStateDir(customerIDN)),
+	}
+
+	locks, err := fsutil.ListLocks()
+	if err != nil {
+		return append(results, Result{Name: "workspace lock", Status: StatusFailed, Detail: err.Error()})
+	}
+	now := time.Now()
+	for _, lock := range locks {
+		if !strings.EqualFold(lock.Customer, customerIDN) {
+			continue
+		}
+		switch state := lock.State(now); state {
+		case fsutil.LockHeld, fsutil.LockUnknown:
+			return append(results, Result{Name: "workspace lock", Status: StatusFailed, Detail: "held by " + lock.Describe(now)})
+		default:
+			return append(results, Result{Name: "workspace lock", Status: StatusOK, Detail: fmt.Sprintf("%s lock left by %s will be taken over", state, lock.Describe(now))})
+		}
+	}
+	return append(results, Result{Name: "workspace lock", Status: StatusOK, Detail: "free"})
+}
+
+func writableResult(name, dir string) Result {
+	if err := fsutil.EnsureDir(dir); err != nil {
+		return Result{Name: name, Status: StatusFailed, Detail: fmt.Sprintf("cannot create %s: %v", dir, err)}
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return Result{Name: name, Status: StatusFailed, Detail: fmt.Sprintf("%s is not writable: %v", dir, err)}
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+	return Result{Name: name, Status: StatusOK, Detail: filepath.Clean(dir)}
+}
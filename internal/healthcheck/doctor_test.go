@@ -0,0 +1,50 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+)
+
+type fakePermissionClient struct {
+	attributesErr error
+	skillsErr     error
+}
+
+func (f fakePermissionClient) ListProjects(context.Context) ([]platform.Project, error) {
+	return []platform.Project{{ID: "p1", IDN: "demo"}}, nil
+}
+
+func (f fakePermissionClient) GetCustomerAttributes(context.Context, bool) (platform.CustomerAttributesResponse, error) {
+	return platform.CustomerAttributesResponse{}, f.attributesErr
+}
+
+func (f fakePermissionClient) ListAgents(context.Context, string) ([]platform.Agent, error) {
+	return []platform.Agent{{IDN: "agent", Flows: []platform.Flow{{ID: "f1", IDN: "MainFlow"}}}}, nil
+}
+
+func (f fakePermissionClient) ListFlowSkills(context.Context, string) ([]platform.Skill, error) {
+	return nil, f.skillsErr
+}
+
+func TestProbePermissions(t *testing.T) {
+	t.Parallel()
+
+	results := ProbePermissions(context.Background(), fakePermissionClient{
+		attributesErr: &platform.APIError{Method: http.MethodGet, Path: "/attributes", Status: http.StatusForbidden},
+		skillsErr:     errors.New("connection reset"),
+	})
+
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Name+"="+result.Status)
+	}
+	want := []string{"list projects=ok", "read customer attributes=denied", "list agents=ok", "list skills=failed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected results:\n got %v\nwant %v", got, want)
+	}
+}
@@ -19,7 +19,7 @@ import (
 
 // CheckConfig performs checks on the newo.toml configuration file.
 func CheckConfig() error {
-	path := config.DefaultTomlPath // "newo.toml"
+	path := config.TomlPath()
 
 	// 1. Check for newo.toml existence and readability, and format validity.
 	data, err := os.ReadFile(path)
@@ -84,7 +84,7 @@ func CheckFilesystem(env config.Env) error {
 	_ = os.Remove(testFilePath) // Clean up the test file
 
 	// 2. Check lock file creation/deletion capabilities.
-	releaseLock, err := fsutil.AcquireLock("healthcheck_test")
+	releaseLock, err := fsutil.AcquireLock("healthcheck_test", "healthcheck")
 	if err != nil {
 		return fmt.Errorf("failed to create lock file: %w", err)
 	}
@@ -98,12 +98,14 @@ func CheckFilesystem(env config.Env) error {
 type apiKeyEntry struct {
 	Key         string
 	CustomerIDN string
+	BaseURL     string
+	APIVersion  string
 }
 
 // CheckPlatformConnectivity performs checks on NEWO platform connectivity and API key validity.
 func CheckPlatformConnectivity(ctx context.Context, env config.Env) (string, error) {
 	// 1. Check basic network connectivity to BaseURL.
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := &http.Client{Timeout: 5 * time.Second, Transport: platform.Transport()}
 	resp, err := client.Get(env.BaseURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to connect to base URL '%s': %w", env.BaseURL, err)
@@ -115,13 +117,17 @@ func CheckPlatformConnectivity(ctx context.Context, env config.Env) (string, err
 
 	// Prioritize API key from environment variable
 	if env.APIKey != "" {
-		apiKeysToTest = append(apiKeysToTest, apiKeyEntry{Key: env.APIKey, CustomerIDN: "(from NEWO_API_KEY)"})
+		apiKeysToTest = append(apiKeysToTest, apiKeyEntry{Key: env.APIKey, CustomerIDN: "(from NEWO_API_KEY)", BaseURL: env.BaseURL})
 	}
 
 	// Add API keys from file customers
 	for _, customer := range env.FileCustomers {
 		if customer.APIKey != "" {
-			apiKeysToTest = append(apiKeysToTest, apiKeyEntry{Key: customer.APIKey, CustomerIDN: customer.IDN})
+			baseURL := customer.BaseURL
+			if baseURL == "" {
+				baseURL = env.BaseURL
+			}
+			apiKeysToTest = append(apiKeysToTest, apiKeyEntry{Key: customer.APIKey, CustomerIDN: customer.IDN, BaseURL: baseURL, APIVersion: customer.APIVersion})
 		}
 	}
 
@@ -135,14 +141,14 @@ func CheckPlatformConnectivity(ctx context.Context, env config.Env) (string, err
 		defer cancel()
 
 		// Exchange API key for an access token
-		tokenResp, err := platform.ExchangeAPIKeyForToken(childCtx, env.BaseURL, entry.Key)
+		tokenResp, err := platform.ExchangeAPIKeyForToken(childCtx, entry.BaseURL, entry.Key)
 		if err != nil {
 			lastErr = fmt.Errorf("failed to exchange API key for access token for customer '%s': %w", entry.CustomerIDN, err)
 			continue
 		}
 		accessToken := tokenResp.AccessToken
 
-		platformClient, err := platform.NewClient(env.BaseURL, accessToken)
+		platformClient, err := platform.NewClient(entry.BaseURL, accessToken, platform.WithAPIVersion(entry.APIVersion))
 		if err != nil {
 			lastErr = fmt.Errorf("failed to create platform client for customer '%s': %w", entry.CustomerIDN, err)
 			continue
@@ -0,0 +1,170 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rule identifiers reported with every lint issue.
+const (
+	RuleCyrillic             = "cyrillic"
+	RuleNSLComment           = "nsl-comment"
+	RuleUnbalancedDelimiters = "unbalanced-delimiters"
+	RuleBlockTermination     = "block-termination"
+	RuleSyntaxError          = "syntax-error"
+	RuleUndefinedVariable    = "undefined-variable"
+	RuleUnknownFilter        = "unknown-filter"
+	RuleInvalidSubscript     = "invalid-subscript"
+	RuleFilterArguments      = "filter-arguments"
+	RuleUnknownInclude       = "unknown-include"
+	RuleDuplicateEvent       = "duplicate-event"
+	RuleEventUnknownSkill    = "event-unknown-skill"
+	RuleEventUnknownState    = "event-unknown-state"
+	RuleTrailingWhitespace   = "trailing-whitespace"
+	RuleTagSpacing           = "tag-spacing"
+	// RuleInternal marks files that could not be read or analysed; it cannot be disabled.
+	RuleInternal = "internal"
+)
+
+// severityOff disables a rule when used as a severity override.
+const severityOff = "off"
+
+var defaultSeverities = map[string]Severity{
+	RuleCyrillic:             SeverityWarning,
+	RuleNSLComment:           SeverityWarning,
+	RuleUnbalancedDelimiters: SeverityError,
+	RuleBlockTermination:     SeverityError,
+	RuleSyntaxError:          SeverityWarning,
+	RuleUndefinedVariable:    SeverityError,
+	RuleUnknownFilter:        SeverityWarning,
+	RuleInvalidSubscript:     SeverityWarning,
+	RuleFilterArguments:      SeverityError,
+	RuleUnknownInclude:       SeverityError,
+	RuleDuplicateEvent:       SeverityWarning,
+	RuleEventUnknownSkill:    SeverityWarning,
+	RuleEventUnknownState:    SeverityWarning,
+	RuleTrailingWhitespace:   SeverityWarning,
+	RuleTagSpacing:           SeverityWarning,
+}
+
+// Rules returns the configurable rule IDs in alphabetical order.
+func Rules() []string {
+	rules := make([]string, 0, len(defaultSeverities))
+	for id := range defaultSeverities {
+		rules = append(rules, id)
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+// Config selects which rules run and the severity they report with.
+// Project-specific settings are layered over the global ones by ForProject.
+type Config struct {
+	Disabled map[string]bool
+	Severity map[string]Severity
+	Projects map[string]Config
+}
+
+// NewConfig validates rule IDs and severities and builds a Config.
+// A severity of "off" disables the rule.
+func NewConfig(disable []string, severity map[string]string) (Config, error) {
+	cfg := Config{Disabled: map[string]bool{}, Severity: map[string]Severity{}}
+	for _, rule := range disable {
+		rule = strings.TrimSpace(rule)
+		if err := validateRule(rule); err != nil {
+			return Config{}, err
+		}
+		cfg.Disabled[rule] = true
+	}
+	for rule, value := range severity {
+		rule = strings.TrimSpace(rule)
+		if err := validateRule(rule); err != nil {
+			return Config{}, err
+		}
+		switch level := strings.ToLower(strings.TrimSpace(value)); level {
+		case severityOff:
+			cfg.Disabled[rule] = true
+		case string(SeverityError), string(SeverityWarning):
+			cfg.Severity[rule] = Severity(level)
+		default:
+			return Config{}, fmt.Errorf("invalid severity %q for rule %s (expected error, warning, or off)", value, rule)
+		}
+	}
+	return cfg, nil
+}
+
+// ForProject returns the global settings merged with the overrides for the given project.
+func (c Config) ForProject(projectIDN string) Config {
+	merged := Config{Disabled: map[string]bool{}, Severity: map[string]Severity{}}
+	for rule, disabled := range c.Disabled {
+		merged.Disabled[rule] = disabled
+	}
+	for rule, severity := range c.Severity {
+		merged.Severity[rule] = severity
+	}
+
+	project, ok := c.Projects[strings.ToLower(strings.TrimSpace(projectIDN))]
+	if !ok {
+		return merged
+	}
+	for rule, disabled := range project.Disabled {
+		merged.Disabled[rule] = disabled
+	}
+	for rule, severity := range project.Severity {
+		merged.Severity[rule] = severity
+		if _, off := project.Disabled[rule]; !off {
+			// An explicit project severity re-enables a globally disabled rule.
+			delete(merged.Disabled, rule)
+		}
+	}
+	return merged
+}
+
+// Toggle enables and disables rules on a resolved configuration, as requested on the command line.
+// Disabling wins when a rule is listed in both.
+func (c Config) Toggle(enable, disable []string) Config {
+	toggled := Config{Disabled: map[string]bool{}, Severity: c.Severity, Projects: c.Projects}
+	for rule, disabled := range c.Disabled {
+		toggled.Disabled[rule] = disabled
+	}
+	for _, rule := range enable {
+		delete(toggled.Disabled, rule)
+	}
+	for _, rule := range disable {
+		toggled.Disabled[rule] = true
+	}
+	return toggled
+}
+
+// Apply drops disabled rules and rewrites severities according to the configuration.
+func (c Config) Apply(issues []LintError) []LintError {
+	filtered := make([]LintError, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Rule != RuleInternal && c.Disabled[issue.Rule] {
+			continue
+		}
+		if severity, ok := c.Severity[issue.Rule]; ok {
+			issue.Severity = severity
+		}
+		filtered = append(filtered, issue)
+	}
+	return filtered
+}
+
+// ValidateRules reports the first unknown rule ID.
+func ValidateRules(rules []string) error {
+	for _, rule := range rules {
+		if err := validateRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRule(rule string) error {
+	if _, ok := defaultSeverities[rule]; !ok {
+		return fmt.Errorf("unknown lint rule %q (known rules: %s)", rule, strings.Join(Rules(), ", "))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package linter
+
+import "testing"
+
+func TestConfigApply(t *testing.T) {
+	cfg, err := NewConfig([]string{RuleCyrillic}, map[string]string{RuleUndefinedVariable: "warning"})
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	project, err := NewConfig(nil, map[string]string{RuleCyrillic: "error", RuleNSLComment: "off"})
+	if err != nil {
+		t.Fatalf("NewConfig project: %v", err)
+	}
+	cfg.Projects = map[string]Config{"support": project}
+
+	issues := []LintError{
+		{Rule: RuleCyrillic, Severity: SeverityWarning},
+		{Rule: RuleNSLComment, Severity: SeverityWarning},
+		{Rule: RuleUndefinedVariable, Severity: SeverityError},
+		{Rule: RuleInternal, Severity: SeverityError},
+	}
+
+	global := cfg.ForProject("other").Apply(issues)
+	if len(global) != 3 || global[0].Rule != RuleNSLComment {
+		t.Fatalf("expected cyrillic to be disabled globally, got %+v", global)
+	}
+	if global[1].Severity != SeverityWarning {
+		t.Fatalf("expected undefined-variable downgraded to warning, got %s", global[1].Severity)
+	}
+
+	scoped := cfg.ForProject("Support").Apply(issues)
+	if len(scoped) != 3 || scoped[0].Rule != RuleCyrillic || scoped[0].Severity != SeverityError {
+		t.Fatalf("expected project overrides to apply, got %+v", scoped)
+	}
+
+	toggled := cfg.ForProject("support").Toggle([]string{RuleNSLComment}, []string{RuleUndefinedVariable}).Apply(issues)
+	if len(toggled) != 3 || toggled[1].Rule != RuleNSLComment || toggled[2].Rule != RuleInternal {
+		t.Fatalf("expected command-line toggles to win, got %+v", toggled)
+	}
+}
+
+func TestNewConfigRejectsUnknownRules(t *testing.T) {
+	if _, err := NewConfig([]string{"no-such-rule"}, nil); err == nil {
+		t.Fatalf("expected unknown rule to fail")
+	}
+	if _, err := NewConfig(nil, map[string]string{RuleCyrillic: "fatal"}); err == nil {
+		t.Fatalf("expected invalid severity to fail")
+	}
+}
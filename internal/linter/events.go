@@ -0,0 +1,137 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// flowEvent mirrors an event entry in a flow's metadata.yaml. Events are serialised without YAML tags,
+// so their keys are the lower-cased field names.
+type flowEvent struct {
+	IDN           string `yaml:"idn"`
+	SkillSelector string `yaml:"skillselector"`
+	SkillIDN      string `yaml:"skillidn"`
+	StateIDN      string `yaml:"stateidn"`
+}
+
+// isFlowMetadata reports whether path is the metadata.yaml of a flow directory (flows/<flow>/metadata.yaml).
+func isFlowMetadata(path string) bool {
+	return filepath.Base(path) == fsutil.MetadataYAML &&
+		filepath.Base(filepath.Dir(filepath.Dir(path))) == fsutil.FlowsDir
+}
+
+// lintFlowEvents checks the events of a flow against its skills and state fields.
+func lintFlowEvents(metadataPath string) ([]LintError, error) {
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata struct {
+		Events      []yaml.Node `yaml:"events"`
+		StateFields []struct {
+			IDN string `yaml:"idn"`
+		} `yaml:"state_fields"`
+	}
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", fsutil.MetadataYAML, err)
+	}
+
+	states := make(map[string]struct{}, len(metadata.StateFields))
+	for _, field := range metadata.StateFields {
+		states[strings.TrimSpace(field.IDN)] = struct{}{}
+	}
+	skills, err := flowSkillIDNs(filepath.Dir(metadataPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var errors []LintError
+	seen := make(map[string]string)
+	for _, node := range metadata.Events {
+		var event flowEvent
+		if err := node.Decode(&event); err != nil {
+			return nil, fmt.Errorf("failed to decode event at line %d: %w", node.Line, err)
+		}
+		name := strings.TrimSpace(event.IDN)
+		skill := strings.TrimSpace(event.SkillIDN)
+		selector := strings.TrimSpace(event.SkillSelector)
+		stateIDN := strings.TrimSpace(event.StateIDN)
+
+		if skill != "" {
+			key := skill + "\x00" + selector
+			if first, ok := seen[key]; ok {
+				errors = append(errors, LintError{
+					FilePath: metadataPath,
+					Line:     node.Line,
+					Rule:     RuleDuplicateEvent,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("event '%s' duplicates event '%s': both target skill '%s' with the same selector", name, first, skill),
+					Snippet:  name,
+				})
+			} else {
+				seen[key] = name
+			}
+
+			if _, ok := skills[skill]; !ok {
+				errors = append(errors, LintError{
+					FilePath: metadataPath,
+					Line:     node.Line,
+					Rule:     RuleEventUnknownSkill,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("event '%s' references skill '%s', which does not exist in this flow", name, skill),
+					Snippet:  name,
+				})
+			}
+		}
+
+		if stateIDN != "" {
+			if _, ok := states[stateIDN]; !ok {
+				errors = append(errors, LintError{
+					FilePath: metadataPath,
+					Line:     node.Line,
+					Rule:     RuleEventUnknownState,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("event '%s' references state '%s', which is missing from state_fields", name, stateIDN),
+					Snippet:  name,
+				})
+			}
+		}
+	}
+	return errors, nil
+}
+
+// flowSkillIDNs collects the skill IDNs declared by the <skill>.meta.yaml files of a flow directory.
+func flowSkillIDNs(flowDir string) (map[string]struct{}, error) {
+	entries, err := os.ReadDir(flowDir)
+	if err != nil {
+		return nil, err
+	}
+
+	skills := make(map[string]struct{})
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fsutil.SkillMetaFileExt) {
+			continue
+		}
+		idn := strings.TrimSuffix(name, fsutil.SkillMetaFileExt)
+		data, err := os.ReadFile(filepath.Join(flowDir, name))
+		if err != nil {
+			return nil, err
+		}
+		var meta struct {
+			IDN string `yaml:"idn"`
+		}
+		if err := yaml.Unmarshal(data, &meta); err == nil && strings.TrimSpace(meta.IDN) != "" {
+			idn = strings.TrimSpace(meta.IDN)
+		}
+		skills[idn] = struct{}{}
+	}
+	return skills, nil
+}
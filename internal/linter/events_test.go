@@ -0,0 +1,60 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintNSLFilesChecksFlowEvents(t *testing.T) {
+	root := t.TempDir()
+	flowDir := filepath.Join(root, "flows", "MainFlow")
+	if err := os.MkdirAll(flowDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	files := map[string]string{
+		"greet.meta.yaml": "idn: greet\nparameters: []\n",
+		"greet.nsl":       "{{ true }}\n",
+		"metadata.yaml": `idn: MainFlow
+events:
+  - idn: on_start
+    skillselector: skill_idn
+    skillidn: greet
+  - idn: on_start_again
+    skillselector: skill_idn
+    skillidn: greet
+  - idn: on_missing
+    skillselector: skill_idn
+    skillidn: farewell
+  - idn: on_state
+    skillselector: skill_idn
+    skillidn: greet
+    stateidn: mood
+state_fields:
+  - idn: user_name
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(flowDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	issues, err := LintNSLFiles(root)
+	if err != nil {
+		t.Fatalf("LintNSLFiles: %v", err)
+	}
+
+	rules := map[string]int{}
+	for _, issue := range issues {
+		rules[issue.Rule]++
+		if issue.Severity != SeverityWarning {
+			t.Fatalf("expected warnings only, got %+v", issue)
+		}
+	}
+	// on_start_again and on_state both duplicate on_start.
+	if rules[RuleDuplicateEvent] != 2 || rules[RuleEventUnknownSkill] != 1 || rules[RuleEventUnknownState] != 1 || len(issues) != 4 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
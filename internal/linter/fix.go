@@ -0,0 +1,277 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/nsl/builtin"
+	"github.com/twinmind/newo-tool/internal/nsl/lexer"
+	"github.com/twinmind/newo-tool/internal/nsl/token"
+)
+
+// TextEdit replaces the content of a single 1-based line, without its line terminator. When Column is set,
+// only the columns from Column up to, but excluding, EndColumn are replaced.
+type TextEdit struct {
+	Line      int
+	Column    int
+	EndColumn int
+	NewText   string
+}
+
+// Fixer is implemented by rules that can repair their own issues. Fix receives the current lines of the
+// file (without terminators) and returns the edits resolving the issue, or false when nothing applies.
+type Fixer interface {
+	Fix(lines []string, issue LintError) ([]TextEdit, bool)
+}
+
+// FixerFunc adapts a function to the Fixer interface.
+type FixerFunc func(lines []string, issue LintError) ([]TextEdit, bool)
+
+// Fix calls f.
+func (f FixerFunc) Fix(lines []string, issue LintError) ([]TextEdit, bool) {
+	return f(lines, issue)
+}
+
+var fixers = map[string]Fixer{
+	RuleNSLComment:         FixerFunc(fixLine(convertNSLComment)),
+	RuleTrailingWhitespace: FixerFunc(fixLine(trimTrailingWhitespace)),
+	RuleTagSpacing:         FixerFunc(fixTagSpacing),
+	RuleUnknownFilter:      FixerFunc(fixFilterCase),
+}
+
+// FixerFor returns the fixer registered for an issue's rule.
+func FixerFor(issue LintError) (Fixer, bool) {
+	fixer, ok := fixers[issue.Rule]
+	return fixer, ok
+}
+
+// FixFile applies fixes for the given issues of a single file. accept is asked before each fixable issue;
+// returning false skips it. The file is rewritten once, and the number of applied fixes is returned.
+func FixFile(path string, issues []LintError, accept func(LintError) (bool, error)) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	carriage := make([]bool, len(lines))
+	for i, line := range lines {
+		if strings.HasSuffix(line, "\r") {
+			lines[i] = strings.TrimSuffix(line, "\r")
+			carriage[i] = true
+		}
+	}
+
+	applied := 0
+	for _, issue := range issues {
+		fixer, ok := FixerFor(issue)
+		if !ok {
+			continue
+		}
+		edits, ok := fixer.Fix(lines, issue)
+		if !ok {
+			continue
+		}
+		proceed, err := accept(issue)
+		if err != nil {
+			return applied, err
+		}
+		if !proceed {
+			continue
+		}
+		for _, edit := range edits {
+			if edit.Line <= 0 || edit.Line > len(lines) {
+				return applied, fmt.Errorf("%s: fix for line %d out of range", path, edit.Line)
+			}
+			line := lines[edit.Line-1]
+			if edit.Column == 0 {
+				lines[edit.Line-1] = edit.NewText
+				continue
+			}
+			if edit.Column > edit.EndColumn || edit.EndColumn-1 > len(line) {
+				return applied, fmt.Errorf("%s: fix for line %d, columns %d-%d out of range", path, edit.Line, edit.Column, edit.EndColumn)
+			}
+			lines[edit.Line-1] = line[:edit.Column-1] + edit.NewText + line[edit.EndColumn-1:]
+		}
+		applied++
+	}
+	if applied == 0 {
+		return 0, nil
+	}
+
+	for i := range lines {
+		if carriage[i] {
+			lines[i] += "\r"
+		}
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+		return applied, fmt.Errorf("write %s: %w", path, err)
+	}
+	return applied, nil
+}
+
+// fixLine builds a fixer that rewrites the issue's line with transform.
+func fixLine(transform func(string) (string, bool)) func([]string, LintError) ([]TextEdit, bool) {
+	return func(lines []string, issue LintError) ([]TextEdit, bool) {
+		if issue.Line <= 0 || issue.Line > len(lines) {
+			return nil, false
+		}
+		updated, ok := transform(lines[issue.Line-1])
+		if !ok || updated == lines[issue.Line-1] {
+			return nil, false
+		}
+		return []TextEdit{{Line: issue.Line, NewText: updated}}, true
+	}
+}
+
+// fixFilterCase renames an unknown filter that only differs from a built-in one by case, such as `Upper`.
+// The edit covers the filter name alone, so it relies on the issue's span still matching the line.
+func fixFilterCase(lines []string, issue LintError) ([]TextEdit, bool) {
+	if issue.Column <= 0 || issue.EndLine != issue.Line || issue.Line > len(lines) {
+		return nil, false
+	}
+	line := lines[issue.Line-1]
+	if issue.EndColumn-1 > len(line) || line[issue.Column-1:issue.EndColumn-1] != issue.Snippet {
+		return nil, false
+	}
+	name := strings.ToLower(issue.Snippet)
+	if _, ok := builtin.LookupFilter(name); !ok || name == issue.Snippet {
+		return nil, false
+	}
+	return []TextEdit{{Line: issue.Line, Column: issue.Column, EndColumn: issue.EndColumn, NewText: name}}, true
+}
+
+// convertNSLComment turns a comment occupying the whole line into a set statement that keeps its text,
+// and strips partial comment markers from lines that also contain code.
+func convertNSLComment(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{#") && strings.HasSuffix(trimmed, "#}") && len(trimmed) >= 4 {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		text := strings.TrimSpace(trimmed[2 : len(trimmed)-2])
+		text = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+		return fmt.Sprintf(`%s{%% set _comment = "%s" %%}`, indent, text), true
+	}
+
+	start := strings.Index(line, "{#")
+	end := strings.Index(line, "#}")
+	switch {
+	case start >= 0 && end >= 0 && end >= start:
+		return line[:start] + line[end+2:], true
+	case start >= 0:
+		return strings.TrimRight(line[:start], " \t"), true
+	case end >= 0:
+		return strings.TrimRight(line[:end], " \t"), true
+	}
+	return line, false
+}
+
+func trimTrailingWhitespace(line string) (string, bool) {
+	trimmed := strings.TrimRight(line, " \t")
+	return trimmed, trimmed != line
+}
+
+// fixTagSpacing rewrites the issue's line with its tag delimiters padded by a single space.
+func fixTagSpacing(lines []string, issue LintError) ([]TextEdit, bool) {
+	updated, ok := tagSpacingFixes(lines)[issue.Line]
+	if !ok {
+		return nil, false
+	}
+	return []TextEdit{{Line: issue.Line, NewText: updated}}, true
+}
+
+// tagDelimiter is a `{{`, `{%`, `}}` or `%}` found by the template lexer, at a 1-based line and column.
+type tagDelimiter struct {
+	line, column int
+	open         bool
+}
+
+// tagSpacingFixes returns, by 1-based line number, every line whose `{{ }}` and `{% %}` delimiters are
+// not padded with exactly one space, rewritten with that padding. Delimiters come from the template
+// lexer, so braces in prompt text, such as a JSON example, are left as written. Empty tags and padding
+// that runs onto another line are not touched.
+func tagSpacingFixes(lines []string) map[int]string {
+	var delimiters []tagDelimiter
+	// content reports whether the current tag holds anything besides whitespace-control markers.
+	content := false
+	l := lexer.NewTemplate(strings.Join(lines, "\n"))
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LBRACE, token.LPERCENT:
+			delimiters = append(delimiters, tagDelimiter{line: tok.Line, column: tok.Column, open: true})
+			content = false
+		case token.RBRACE, token.RPERCENT:
+			if !content && len(delimiters) > 0 && delimiters[len(delimiters)-1].open {
+				// An empty tag such as `{{ }}` has nothing to pad.
+				delimiters = delimiters[:len(delimiters)-1]
+				continue
+			}
+			delimiters = append(delimiters, tagDelimiter{line: tok.Line, column: tok.Column})
+		case token.MINUS, token.TEXT:
+		default:
+			content = true
+		}
+	}
+
+	fixes := map[int]string{}
+	// Later delimiters are padded first, so the columns of earlier ones on the line stay valid.
+	for i := len(delimiters) - 1; i >= 0; i-- {
+		d := delimiters[i]
+		if d.line <= 0 || d.line > len(lines) {
+			continue
+		}
+		line, ok := fixes[d.line]
+		if !ok {
+			line = lines[d.line-1]
+		}
+		if updated, changed := padDelimiter(line, d); changed {
+			fixes[d.line] = updated
+		}
+	}
+	for number, line := range fixes {
+		if line == lines[number-1] {
+			delete(fixes, number)
+		}
+	}
+	return fixes
+}
+
+// padDelimiter replaces the blanks between a delimiter and the tag's content with a single space. A
+// `-` whitespace-control marker stays next to the delimiter.
+func padDelimiter(line string, d tagDelimiter) (string, bool) {
+	at := d.column - 1
+	if at < 0 || at+2 > len(line) {
+		return line, false
+	}
+	isBlank := func(ch byte) bool { return ch == ' ' || ch == '\t' }
+	if d.open {
+		start := at + 2
+		if start < len(line) && line[start] == '-' {
+			start++
+		}
+		end := start
+		for end < len(line) && isBlank(line[end]) {
+			end++
+		}
+		if end == len(line) {
+			return line, false
+		}
+		return line[:start] + " " + line[end:], true
+	}
+	end := at
+	if end > 0 && line[end-1] == '-' {
+		end--
+	}
+	start := end
+	for start > 0 && isBlank(line[start-1]) {
+		start--
+	}
+	if start == 0 {
+		return line, false
+	}
+	return line[:start] + " " + line[end:], true
+}
@@ -0,0 +1,138 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		issue    LintError
+		expected string
+	}{
+		{
+			name:     "converts whole-line comment into set statement",
+			content:  "  {# greet the \"user\" #}\nkeep\n",
+			issue:    LintError{Line: 1, Rule: RuleNSLComment},
+			expected: "  {% set _comment = \"greet the \\\"user\\\"\" %}\nkeep\n",
+		},
+		{
+			name:     "trims trailing comment marker",
+			content:  "value {{ foo }} #}\n",
+			issue:    LintError{Line: 1, Rule: RuleNSLComment},
+			expected: "value {{ foo }}\n",
+		},
+		{
+			name:     "trims trailing whitespace and keeps CRLF",
+			content:  "a\r\nb \t\r\n",
+			issue:    LintError{Line: 2, Rule: RuleTrailingWhitespace},
+			expected: "a\r\nb\r\n",
+		},
+		{
+			name:     "normalizes tag spacing",
+			content:  "{%-if x   -%}{{y}}{% endif %}\n",
+			issue:    LintError{Line: 1, Rule: RuleTagSpacing},
+			expected: "{%- if x -%}{{ y }}{% endif %}\n",
+		},
+		{
+			name:     "pads tags but leaves JSON in prompt text alone",
+			content:  "Return JSON like {\"a\": {\"b\": {{n}}}}\n",
+			issue:    LintError{Line: 1, Rule: RuleTagSpacing},
+			expected: "Return JSON like {\"a\": {\"b\": {{ n }}}}\n",
+		},
+		{
+			name:     "lowercases a filter name within its span",
+			content:  "a\n{{ Upper | Upper }}\n",
+			issue:    LintError{Line: 2, Column: 12, EndLine: 2, EndColumn: 17, Rule: RuleUnknownFilter, Snippet: "Upper"},
+			expected: "a\n{{ Upper | upper }}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "script.nsl")
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("write file: %v", err)
+			}
+			applied, err := FixFile(path, []LintError{tc.issue}, func(LintError) (bool, error) { return true, nil })
+			if err != nil {
+				t.Fatalf("FixFile: %v", err)
+			}
+			if applied != 1 {
+				t.Fatalf("expected one fix, got %d", applied)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read file: %v", err)
+			}
+			if string(data) != tc.expected {
+				t.Fatalf("unexpected content: %q", string(data))
+			}
+		})
+	}
+}
+
+func TestTagSpacingFixes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lines    []string
+		expected map[int]string
+	}{
+		{
+			name:     "JSON in prompt text",
+			lines:    []string{`Return JSON like {"a": {"b": 1}}`},
+			expected: map[int]string{},
+		},
+		{
+			name:     "nested dict literal in a tag",
+			lines:    []string{`{{ {"a": {"b": 1}} }}`, `{% set d = {"x": {"y": 2}} %}`},
+			expected: map[int]string{},
+		},
+		{
+			name:     "tag next to JSON",
+			lines:    []string{`{{x}} and {"k": {"v": 2}}`},
+			expected: map[int]string{1: `{{ x }} and {"k": {"v": 2}}`},
+		},
+		{
+			name:     "empty tags and whitespace control",
+			lines:    []string{"{{ }}{%- -%}", "{%- if x -%}", "{{ y -}}"},
+			expected: map[int]string{},
+		},
+		{
+			name:     "tag spanning lines",
+			lines:    []string{"{%  if", "  x %}"},
+			expected: map[int]string{1: "{% if"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tagSpacingFixes(tc.lines)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+			for line, want := range tc.expected {
+				if got[line] != want {
+					t.Fatalf("line %d: expected %q, got %q", line, want, got[line])
+				}
+			}
+		})
+	}
+}
+
+func TestFixFileSkipsTrailingWhitespaceWithoutChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.nsl")
+	if err := os.WriteFile(path, []byte("clean\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	applied, err := FixFile(path, []LintError{{Line: 1, Rule: RuleTrailingWhitespace}}, func(LintError) (bool, error) { return true, nil })
+	if err != nil {
+		t.Fatalf("FixFile: %v", err)
+	}
+	if applied != 0 {
+		t.Fatalf("expected no fix for a line without trailing whitespace, got %d", applied)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/twinmind/newo-tool/internal/nsl/ast"
 	"github.com/twinmind/newo-tool/internal/nsl/lexer"
 	"github.com/twinmind/newo-tool/internal/nsl/parser"
+	"github.com/twinmind/newo-tool/internal/nsl/token"
 )
 
 type Severity string
@@ -22,20 +23,28 @@ const (
 	SeverityWarning Severity = "warning"
 )
 
-// LintError describes a linting issue.
+// LintError describes a linting issue. Column, EndLine and EndColumn are set by rules that check the parsed
+// AST and locate the offending node exactly; EndColumn is exclusive. Line-based rules leave them zero.
 type LintError struct {
-	FilePath string
-	Line     int
-	Severity Severity
-	Message  string
-	Snippet  string
+	FilePath  string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Rule      string
+	Severity  Severity
+	Message   string
+	Snippet   string
 }
 
 func (e LintError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.FilePath, e.Line, e.Column, e.Message)
+	}
 	return fmt.Sprintf("%s:%d: %s", e.FilePath, e.Line, e.Message)
 }
 
-// LintNSLFiles walks the given root path and lints all .nsl files.
+// LintNSLFiles walks the given root path and lints all .nsl files, along with the events declared in flow metadata.
 func LintNSLFiles(root string) ([]LintError, error) {
 	var errors []LintError
 
@@ -43,12 +52,31 @@ func LintNSLFiles(root string) ([]LintError, error) {
 		if err != nil {
 			return err
 		}
+		if !d.IsDir() && isFlowMetadata(path) {
+			eventErrors, err := lintFlowEvents(path)
+			if err != nil {
+				errors = append(errors, LintError{
+					FilePath: filepath.ToSlash(path),
+					Line:     0,
+					Rule:     RuleInternal,
+					Severity: SeverityError,
+					Message:  err.Error(),
+				})
+				return nil
+			}
+			for _, fe := range eventErrors {
+				fe.FilePath = filepath.ToSlash(fe.FilePath)
+				errors = append(errors, fe)
+			}
+			return nil
+		}
 		if !d.IsDir() && strings.HasSuffix(d.Name(), ".nsl") {
 			fileErrors, err := lintFile(path)
 			if err != nil {
 				errors = append(errors, LintError{
 					FilePath: filepath.ToSlash(path),
 					Line:     0,
+					Rule:     RuleInternal,
 					Severity: SeverityError,
 					Message:  err.Error(),
 				})
@@ -98,6 +126,7 @@ func lintFile(filePath string) ([]LintError, error) {
 				errors = append(errors, LintError{
 					FilePath: filePath,
 					Line:     lineNumber,
+					Rule:     RuleCyrillic,
 					Severity: SeverityWarning,
 					Message:  "Line contains Cyrillic characters",
 					Snippet:  trimmed,
@@ -111,11 +140,23 @@ func lintFile(filePath string) ([]LintError, error) {
 			errors = append(errors, LintError{
 				FilePath: filePath,
 				Line:     lineNumber,
+				Rule:     RuleNSLComment,
 				Severity: SeverityWarning,
 				Message:  "Line contains an NSL comment",
 				Snippet:  trimmed,
 			})
 		}
+
+		if strings.TrimRight(line, " \t") != line {
+			errors = append(errors, LintError{
+				FilePath: filePath,
+				Line:     lineNumber,
+				Rule:     RuleTrailingWhitespace,
+				Severity: SeverityWarning,
+				Message:  "Line has trailing whitespace",
+				Snippet:  trimmed,
+			})
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -124,6 +165,21 @@ func lintFile(filePath string) ([]LintError, error) {
 
 	contentStr := contentBuilder.String()
 
+	lines := strings.Split(strings.TrimSuffix(contentStr, "\n"), "\n")
+	spacing := tagSpacingFixes(lines)
+	for number := 1; number <= len(lines); number++ {
+		if _, ok := spacing[number]; ok {
+			errors = append(errors, LintError{
+				FilePath: filePath,
+				Line:     number,
+				Rule:     RuleTagSpacing,
+				Severity: SeverityWarning,
+				Message:  "Tag delimiters should be padded with a single space",
+				Snippet:  strings.TrimSpace(lines[number-1]),
+			})
+		}
+	}
+
 	// Existing checks for unbalanced delimiters across the whole file
 	delimiters := []struct {
 		open  string
@@ -134,10 +190,15 @@ func lintFile(filePath string) ([]LintError, error) {
 		{"{#", "#}"},
 	}
 	for _, d := range delimiters {
-		if strings.Count(contentStr, d.open) != strings.Count(contentStr, d.close) {
+		opened, closed := strings.Count(contentStr, d.open), strings.Count(contentStr, d.close)
+		if d.open == "{{" {
+			opened, closed = countOutputDelimiters(contentStr)
+		}
+		if opened != closed {
 			errors = append(errors, LintError{
 				FilePath: filePath,
 				Line:     1,
+				Rule:     RuleUnbalancedDelimiters,
 				Severity: SeverityError,
 				Message:  fmt.Sprintf("unbalanced delimiters across file: %s and %s", d.open, d.close),
 			})
@@ -148,17 +209,18 @@ func lintFile(filePath string) ([]LintError, error) {
 	blockErrors := checkBlockTermination(contentStr, filePath)
 	errors = append(errors, blockErrors...)
 
-	if len(errors) == 0 {
+	if !hasStructuralErrors(errors) {
 		program, parseErrors := parseNSLProgram(contentStr)
 		if len(parseErrors) > 0 {
-			return errors, nil
+			return append(errors, syntaxErrors(filePath, contentStr, parseErrors)...), nil
 		}
 
-		variableErrors, err := checkUndefinedVariables(filePath, program)
+		variableErrors, err := checkSymbols(filePath, program)
 		if err != nil {
 			errors = append(errors, LintError{
 				FilePath: filePath,
 				Line:     0,
+				Rule:     RuleInternal,
 				Severity: SeverityError,
 				Message:  err.Error(),
 			})
@@ -170,11 +232,56 @@ func lintFile(filePath string) ([]LintError, error) {
 	return errors, nil
 }
 
-func parseNSLProgram(content string) (*ast.Program, []string) {
-	l := lexer.New(content)
+// hasStructuralErrors reports whether the file is too broken to be parsed for symbol checks.
+func hasStructuralErrors(errors []LintError) bool {
+	for _, e := range errors {
+		if e.Rule == RuleUnbalancedDelimiters || e.Rule == RuleBlockTermination {
+			return true
+		}
+	}
+	return false
+}
+
+// countOutputDelimiters counts `{{` and `}}` tokens. Unlike a substring count it does not mistake the
+// closing braces of nested dict literals, as in `{"a": {"b": 1}}`, for the end of an output tag.
+func countOutputDelimiters(content string) (opened, closed int) {
+	l := lexer.NewTemplate(content)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LBRACE:
+			opened++
+		case token.RBRACE:
+			closed++
+		}
+	}
+	return opened, closed
+}
+
+func parseNSLProgram(content string) (*ast.Program, []*parser.Error) {
+	l := lexer.NewTemplate(content)
 	p := parser.New(l)
 	program := p.ParseProgram()
-	return program, p.Errors()
+	return program, p.SyntaxErrors()
+}
+
+// syntaxErrors reports parser errors with the offending source line and a caret as the snippet. Only the
+// first error is reported: the parser recovers at the next tag, and what follows is often a consequence.
+// The rule is a warning because the parser does not cover every construct the platform accepts.
+func syntaxErrors(filePath, content string, parseErrors []*parser.Error) []LintError {
+	first := parseErrors[0]
+	line := first.Line
+	if line == 0 {
+		line = 1
+	}
+	return []LintError{{
+		FilePath: filePath,
+		Line:     line,
+		Column:   first.Column,
+		Rule:     RuleSyntaxError,
+		Severity: SeverityWarning,
+		Message:  "syntax error: " + first.Message,
+		Snippet:  first.Context(content),
+	}}
 }
 
 var blockTagRegex = regexp.MustCompile(`\{%-?\s*(\w+)`)
@@ -186,8 +293,8 @@ func checkBlockTermination(content, filePath string) []LintError {
 	}
 
 	var stack []string
-	blockStarters := map[string]bool{"if": true, "for": true, "block": true}
-	blockEnders := map[string]string{"endif": "if", "endfor": "for", "endblock": "block"}
+	blockStarters := map[string]bool{"if": true, "for": true, "block": true, "macro": true}
+	blockEnders := map[string]string{"endif": "if", "endfor": "for", "endblock": "block", "endmacro": "macro"}
 
 	for _, match := range matches {
 		tag := match[1]
@@ -199,6 +306,7 @@ func checkBlockTermination(content, filePath string) []LintError {
 				return []LintError{{
 					FilePath: filePath,
 					Line:     1,
+					Rule:     RuleBlockTermination,
 					Severity: SeverityError,
 					Message:  fmt.Sprintf("unexpected closing tag: %s", tag),
 				}}
@@ -207,6 +315,7 @@ func checkBlockTermination(content, filePath string) []LintError {
 				return []LintError{{
 					FilePath: filePath,
 					Line:     1,
+					Rule:     RuleBlockTermination,
 					Severity: SeverityError,
 					Message:  fmt.Sprintf("mismatched closing tag: expected end for %s, but got %s", stack[len(stack)-1], tag),
 				}}
@@ -219,6 +328,7 @@ func checkBlockTermination(content, filePath string) []LintError {
 		return []LintError{{
 			FilePath: filePath,
 			Line:     1,
+			Rule:     RuleBlockTermination,
 			Severity: SeverityError,
 			Message:  fmt.Sprintf("unclosed block(s): %s", strings.Join(stack, ", ")),
 		}}
@@ -49,6 +49,15 @@ func TestLintNSLFiles(t *testing.T) {
 			errorCount:  1,
 			errorMsg:    "mismatched closing tag: expected end for if, but got endfor",
 		},
+		{
+			name: "syntax error",
+			files: map[string]string{
+				"broken.nsl": "Hello\n  {% set = 1 %}\n{% set x = 2 %}{% set = 3 %}\n",
+			},
+			expectError: true,
+			errorCount:  1,
+			errorMsg:    "syntax error: expected next token to be IDENT, got = instead",
+		},
 		{
 			name: "multiple files with errors",
 			files: map[string]string{
@@ -92,3 +101,11 @@ func TestLintNSLFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestCountOutputDelimiters(t *testing.T) {
+	content := "It's {{ {\"a\": {\"b\": [1.5]}} }} and {% set d = {\"x\": {}} %}{{ \"}}\" }}{# {{ #}\n"
+	opened, closed := countOutputDelimiters(content)
+	if opened != 2 || closed != 2 {
+		t.Fatalf("expected 2 opening and 2 closing delimiters, got %d and %d", opened, closed)
+	}
+}
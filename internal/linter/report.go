@@ -0,0 +1,215 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report formats understood by WriteReport.
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatSARIF  = "sarif"
+	FormatGitHub = "github"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// ValidateFormat reports whether format is a supported report format.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON, FormatSARIF, FormatGitHub:
+		return nil
+	default:
+		return fmt.Errorf("unsupported lint format %q (expected text, json, sarif, or github)", format)
+	}
+}
+
+// WriteReport renders issues in one of the machine-readable formats. Issues are written in the given order;
+// toolVersion is embedded in SARIF output.
+func WriteReport(w io.Writer, format string, issues []LintError, toolVersion string) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, issues)
+	case FormatSARIF:
+		return writeSARIF(w, issues, toolVersion)
+	case FormatGitHub:
+		return writeGitHub(w, issues)
+	default:
+		return fmt.Errorf("unsupported lint format %q", format)
+	}
+}
+
+type jsonIssue struct {
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column,omitempty"`
+	EndLine   int      `json:"endLine,omitempty"`
+	EndColumn int      `json:"endColumn,omitempty"`
+	Rule      string   `json:"rule"`
+	Severity  Severity `json:"severity"`
+	Message   string   `json:"message"`
+	Snippet   string   `json:"snippet,omitempty"`
+}
+
+func writeJSON(w io.Writer, issues []LintError) error {
+	payload := make([]jsonIssue, 0, len(issues))
+	for _, issue := range issues {
+		payload = append(payload, jsonIssue{
+			File:      issue.FilePath,
+			Line:      issue.Line,
+			Column:    issue.Column,
+			EndLine:   issue.EndLine,
+			EndColumn: issue.EndColumn,
+			Rule:      issue.Rule,
+			Severity:  issue.Severity,
+			Message:   issue.Message,
+			Snippet:   strings.TrimSpace(issue.Snippet),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(payload)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func writeSARIF(w io.Writer, issues []LintError, toolVersion string) error {
+	rules := make([]sarifRule, 0, len(defaultSeverities)+1)
+	for _, id := range Rules() {
+		rules = append(rules, sarifRule{ID: id, DefaultConfiguration: sarifConfiguration{Level: sarifLevel(defaultSeverities[id])}})
+	}
+	rules = append(rules, sarifRule{ID: RuleInternal, DefaultConfiguration: sarifConfiguration{Level: sarifLevel(SeverityError)}})
+
+	results := make([]sarifResult, 0, len(issues))
+	for _, issue := range issues {
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: issue.FilePath}}
+		if issue.Line > 0 {
+			location.Region = &sarifRegion{StartLine: issue.Line, StartColumn: issue.Column, EndLine: issue.EndLine, EndColumn: issue.EndColumn}
+		}
+		results = append(results, sarifResult{
+			RuleID:    issue.Rule,
+			Level:     sarifLevel(issue.Severity),
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "newo", Version: toolVersion, Rules: rules}},
+			Results: results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+func sarifLevel(severity Severity) string {
+	if severity == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// writeGitHub emits GitHub Actions workflow commands so issues appear as inline annotations.
+func writeGitHub(w io.Writer, issues []LintError) error {
+	for _, issue := range issues {
+		command := "error"
+		if issue.Severity == SeverityWarning {
+			command = "warning"
+		}
+		properties := "file=" + escapeGitHubProperty(issue.FilePath)
+		if issue.Line > 0 {
+			properties += fmt.Sprintf(",line=%d", issue.Line)
+		}
+		if issue.Column > 0 {
+			properties += fmt.Sprintf(",col=%d", issue.Column)
+		}
+		if issue.EndLine > 0 {
+			properties += fmt.Sprintf(",endLine=%d,endColumn=%d", issue.EndLine, issue.EndColumn)
+		}
+		if issue.Rule != "" {
+			properties += ",title=" + escapeGitHubProperty(issue.Rule)
+		}
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", command, properties, escapeGitHubData(issue.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeGitHubData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+func escapeGitHubProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
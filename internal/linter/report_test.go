@@ -0,0 +1,53 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteReportFormats(t *testing.T) {
+	issues := []LintError{
+		{FilePath: "flows/main/greet.nsl", Line: 3, Column: 4, EndLine: 3, EndColumn: 5, Rule: RuleUndefinedVariable, Severity: SeverityError, Message: "undefined variable: 'x'"},
+		{FilePath: "flows/main/greet.nsl", Line: 0, Rule: RuleNSLComment, Severity: SeverityWarning, Message: "50% done,\nnext"},
+	}
+
+	var sarif bytes.Buffer
+	if err := WriteReport(&sarif, FormatSARIF, issues, "1.2.3"); err != nil {
+		t.Fatalf("WriteReport sarif: %v", err)
+	}
+	var decoded sarifLog
+	if err := json.Unmarshal(sarif.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode sarif: %v", err)
+	}
+	results := decoded.Runs[0].Results
+	if decoded.Version != sarifVersion || len(results) != 2 {
+		t.Fatalf("unexpected sarif log: %+v", decoded)
+	}
+	if region := results[0].Locations[0].PhysicalLocation.Region; results[0].RuleID != RuleUndefinedVariable || results[0].Level != "error" || *region != (sarifRegion{StartLine: 3, StartColumn: 4, EndLine: 3, EndColumn: 5}) {
+		t.Fatalf("unexpected first result: %+v", results[0])
+	}
+	if results[1].Level != "warning" || results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Fatalf("unexpected second result: %+v", results[1])
+	}
+
+	var github bytes.Buffer
+	if err := WriteReport(&github, FormatGitHub, issues, ""); err != nil {
+		t.Fatalf("WriteReport github: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(github.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one annotation per issue, got %q", github.String())
+	}
+	if lines[0] != "::error file=flows/main/greet.nsl,line=3,col=4,endLine=3,endColumn=5,title=undefined-variable::undefined variable: 'x'" {
+		t.Fatalf("unexpected annotation: %q", lines[0])
+	}
+	if lines[1] != "::warning file=flows/main/greet.nsl,title=nsl-comment::50%25 done,%0Anext" {
+		t.Fatalf("unexpected escaped annotation: %q", lines[1])
+	}
+
+	if err := ValidateFormat("xml"); err == nil {
+		t.Fatalf("expected unsupported format to fail")
+	}
+}
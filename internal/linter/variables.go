@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/twinmind/newo-tool/internal/fsutil"
 	"github.com/twinmind/newo-tool/internal/nsl/ast"
+	"github.com/twinmind/newo-tool/internal/nsl/builtin"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,20 +19,61 @@ type SkillMetadata struct {
 	} `yaml:"parameters"`
 }
 
-func checkUndefinedVariables(filePath string, program *ast.Program) ([]LintError, error) {
+// flowMetadata holds the parts of a flow's metadata.yaml the linter inspects.
+type flowMetadata struct {
+	StateFields []struct {
+		IDN string `yaml:"idn"`
+	} `yaml:"state_fields"`
+}
+
+// checkSymbols builds a symbol table from skill parameters, flow state fields, `{% set %}` statements and
+// for-loop iterators, then reports undefined identifiers and unknown filters. Undefined identifiers are only
+// reported when the skill metadata file exists, since parameters cannot be known otherwise.
+func checkSymbols(filePath string, program *ast.Program) ([]LintError, error) {
 	declaredParams, err := getDeclaredParameters(filePath)
+	checkVariables := true
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to get declared parameters: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get declared parameters: %w", err)
+		checkVariables = false
+	}
+
+	stateFields, err := getFlowStateFields(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow state fields: %w", err)
 	}
 
-	analyzer := newASTAnalyzer(filePath, declaredParams)
+	analyzer := newASTAnalyzer(filePath, append(declaredParams, stateFields...))
+	analyzer.checkVariables = checkVariables
 	analyzer.analyzeProgram(program)
 	return analyzer.errors, nil
 }
 
+// getFlowStateFields returns the state field IDNs declared in the metadata.yaml of the skill's flow directory.
+func getFlowStateFields(nslFilePath string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(nslFilePath), fsutil.MetadataYAML))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var metadata flowMetadata
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata.yaml: %w", err)
+	}
+
+	var fields []string
+	for _, field := range metadata.StateFields {
+		if idn := strings.TrimSpace(field.IDN); idn != "" {
+			fields = append(fields, idn)
+		}
+	}
+	return fields, nil
+}
+
 func getDeclaredParameters(nslFilePath string) ([]string, error) {
 	metaPath := strings.TrimSuffix(nslFilePath, ".nsl") + ".meta.yaml"
 	if _, err := os.Stat(metaPath); os.IsNotExist(err) {
@@ -57,9 +100,11 @@ func getDeclaredParameters(nslFilePath string) ([]string, error) {
 }
 
 type astAnalyzer struct {
-	filePath string
-	scope    *scope
-	errors   []LintError
+	filePath       string
+	scope          *scope
+	checkVariables bool
+	errors         []LintError
+	flowSkills     map[string]struct{} // skill IDNs of the file's flow, loaded on the first include
 }
 
 type scope struct {
@@ -96,7 +141,7 @@ func newASTAnalyzer(filePath string, params []string) *astAnalyzer {
 	for name := range globalVars {
 		root.declare(name)
 	}
-	return &astAnalyzer{filePath: filePath, scope: root}
+	return &astAnalyzer{filePath: filePath, scope: root, checkVariables: true}
 }
 
 func (a *astAnalyzer) analyzeProgram(program *ast.Program) {
@@ -129,6 +174,23 @@ func (a *astAnalyzer) analyzeStatement(stmt ast.Statement) {
 			a.analyzeBlock(s.Body)
 		}
 		a.popScope()
+	case *ast.MacroStatement:
+		if s.Name != nil {
+			a.scope.declare(s.Name.Value)
+		}
+		a.pushScope()
+		for _, param := range s.Parameters {
+			a.scope.declare(param.Value)
+		}
+		if s.Body != nil {
+			a.analyzeBlock(s.Body)
+		}
+		a.popScope()
+	case *ast.IncludeStatement:
+		if s.Skill != nil {
+			a.analyzeExpression(s.Skill)
+		}
+		a.checkInclude(s)
 	case *ast.IfStatement:
 		if s.Condition != nil {
 			a.analyzeExpression(s.Condition)
@@ -177,18 +239,46 @@ func (a *astAnalyzer) analyzeExpression(expr ast.Expression) {
 		if e.Input != nil {
 			a.analyzeExpression(e.Input)
 		}
+		for _, arg := range e.Arguments {
+			a.analyzeExpression(arg)
+		}
+		a.checkFilter(e)
+	case *ast.IndexExpression:
+		a.analyzeExpression(e.Left)
+		a.analyzeExpression(e.Index)
+		a.checkSubscript(e)
+	case *ast.CallExpression:
+		// Function names come from the platform, so only attribute-access callees are resolved.
+		if _, isName := e.Function.(*ast.Identifier); !isName && e.Function != nil {
+			a.analyzeExpression(e.Function)
+		}
+		for _, arg := range e.Arguments {
+			a.analyzeExpression(arg)
+		}
 	case *ast.InfixExpression:
 		a.analyzeExpression(e.Left)
 		a.analyzeExpression(e.Right)
 	case *ast.PrefixExpression:
 		a.analyzeExpression(e.Right)
-	case *ast.Boolean, *ast.IntegerLiteral, *ast.StringLiteral:
+	case *ast.KeywordArgument:
+		// The name belongs to the callee's signature, so only the value is resolved.
+		a.analyzeExpression(e.Value)
+	case *ast.ListLiteral:
+		for _, element := range e.Elements {
+			a.analyzeExpression(element)
+		}
+	case *ast.DictLiteral:
+		for _, entry := range e.Entries {
+			a.analyzeExpression(entry.Key)
+			a.analyzeExpression(entry.Value)
+		}
+	case *ast.Boolean, *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral:
 		// literals: nothing to do
 	}
 }
 
 func (a *astAnalyzer) checkIdentifier(ident *ast.Identifier) {
-	if ident == nil {
+	if ident == nil || !a.checkVariables {
 		return
 	}
 	name := ident.Value
@@ -199,20 +289,110 @@ func (a *astAnalyzer) checkIdentifier(ident *ast.Identifier) {
 		return
 	}
 
-	line := ident.Token.Line
-	if line == 0 {
-		line = 1
-	}
-
-	a.errors = append(a.errors, LintError{
-		FilePath: a.filePath,
-		Line:     line,
+	a.report(ident, LintError{
+		Rule:     RuleUndefinedVariable,
 		Severity: SeverityError,
 		Message:  fmt.Sprintf("undefined variable: '%s' is used but not defined in parameters or in the skill", name),
 		Snippet:  name,
 	})
 }
 
+// checkFilter reports filters missing from the built-in registry and calls with the wrong number of arguments.
+func (a *astAnalyzer) checkFilter(expr *ast.FilterExpression) {
+	filter := expr.Filter
+	if filter == nil || filter.Value == "" {
+		return
+	}
+
+	arity, ok := builtin.LookupFilter(filter.Value)
+	if !ok {
+		a.report(filter, LintError{
+			Rule:     RuleUnknownFilter,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("unknown filter: '%s' is not a built-in filter", filter.Value),
+			Snippet:  filter.Value,
+		})
+		return
+	}
+	if arity.Accepts(len(expr.Arguments)) {
+		return
+	}
+
+	a.report(filter, LintError{
+		Rule:     RuleFilterArguments,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf("filter arguments: '%s' takes %s, got %d", filter.Value, arity, len(expr.Arguments)),
+		Snippet:  filter.Value,
+	})
+}
+
+// checkSubscript flags subscripts whose literal operands can never produce a value: integers and
+// booleans cannot be indexed, and a boolean is neither a list index nor a dict key.
+func (a *astAnalyzer) checkSubscript(expr *ast.IndexExpression) {
+	var message string
+	switch expr.Left.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.Boolean:
+		message = fmt.Sprintf("invalid subscript: '%s' is a literal that cannot be indexed", expr.Left.String())
+	}
+	if _, ok := expr.Index.(*ast.Boolean); ok && message == "" {
+		message = fmt.Sprintf("invalid subscript: '%s' is not a list index or dict key", expr.Index.String())
+	}
+	if message == "" {
+		return
+	}
+
+	a.report(expr, LintError{
+		Rule:     RuleInvalidSubscript,
+		Severity: SeverityWarning,
+		Message:  message,
+		Snippet:  expr.String(),
+	})
+}
+
+// checkInclude reports includes of skills that do not exist in the file's flow. Only literal skill names
+// can be checked, and only when the flow's skill metadata is present.
+func (a *astAnalyzer) checkInclude(stmt *ast.IncludeStatement) {
+	name, ok := stmt.Skill.(*ast.StringLiteral)
+	if !ok {
+		return
+	}
+	if a.flowSkills == nil {
+		skills, err := flowSkillIDNs(filepath.Dir(a.filePath))
+		if err != nil {
+			skills = map[string]struct{}{}
+		}
+		a.flowSkills = skills
+	}
+	if len(a.flowSkills) == 0 {
+		return
+	}
+	if _, ok := a.flowSkills[name.Value]; ok {
+		return
+	}
+
+	a.report(name, LintError{
+		Rule:     RuleUnknownInclude,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf("unknown include: skill '%s' does not exist in this flow", name.Value),
+		Snippet:  name.Value,
+	})
+}
+
+// report records an issue located at the span of node. Nodes without a position fall back to line 1.
+func (a *astAnalyzer) report(node ast.Node, issue LintError) {
+	span := node.Range()
+	issue.FilePath = a.filePath
+	issue.Line = span.Start.Line
+	if issue.Line == 0 {
+		issue.Line = 1
+	} else {
+		issue.Column = span.Start.Column
+		issue.EndLine = span.End.Line
+		issue.EndColumn = span.End.Column
+	}
+	a.errors = append(a.errors, issue)
+}
+
 func (a *astAnalyzer) pushScope() {
 	a.scope = newScope(a.scope)
 }
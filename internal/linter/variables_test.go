@@ -50,6 +50,14 @@ func TestCheckUndefinedVariables(t *testing.T) {
   - name: user`,
 			expectError: false,
 		},
+		{
+			name:        "invalid: undefined call argument, function name is not checked",
+			nslContent:  `{{ SendMessage("hi", 1) }}{{ GetUser(missing_id) }}`,
+			metaContent: `parameters: []`,
+			expectError: true,
+			errorCount:  1,
+			errorMsg:    "undefined variable: 'missing_id' is used but not defined in parameters or in the skill",
+		},
 		{
 			name:        "invalid: dot notation on an undeclared variable",
 			nslContent:  `{{ undefined_user.name }}`,
@@ -99,9 +107,9 @@ func TestCheckUndefinedVariables(t *testing.T) {
 				t.Fatalf("failed to parse NSL content: %v", parseErrors)
 			}
 
-			errors, err := checkUndefinedVariables(nslPath, program)
+			errors, err := checkSymbols(nslPath, program)
 			if err != nil {
-				t.Fatalf("checkUndefinedVariables failed: %v", err)
+				t.Fatalf("checkSymbols failed: %v", err)
 			}
 
 			if (len(errors) > 0) != tc.expectError {
@@ -128,3 +136,104 @@ func TestCheckUndefinedVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSymbolsStateFieldsAndFilters(t *testing.T) {
+	dir := t.TempDir()
+	nslContent := "{{ user_name | upper }}\n{{ user_name | shout }}\n{{ user_name | truncate(20) }}\n{{ user_name | replace(undefined_arg) }}\n"
+	nslPath := filepath.Join(dir, "greet.nsl")
+	if err := os.WriteFile(nslPath, []byte(nslContent), 0644); err != nil {
+		t.Fatalf("write nsl: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "greet.meta.yaml"), []byte("parameters: []\n"), 0644); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+	flowMeta := "idn: main\nstate_fields:\n  - idn: user_name\n    scope: user\n"
+	if err := os.WriteFile(filepath.Join(dir, "metadata.yaml"), []byte(flowMeta), 0644); err != nil {
+		t.Fatalf("write flow metadata: %v", err)
+	}
+
+	program, parseErrors := parseNSLProgram(nslContent)
+	if len(parseErrors) > 0 {
+		t.Fatalf("failed to parse NSL content: %v", parseErrors)
+	}
+	errors, err := checkSymbols(nslPath, program)
+	if err != nil {
+		t.Fatalf("checkSymbols failed: %v", err)
+	}
+	if len(errors) != 3 {
+		t.Fatalf("expected three issues, got %+v", errors)
+	}
+	if errors[0].Rule != RuleUnknownFilter || errors[0].Line != 2 || errors[0].Snippet != "shout" {
+		t.Fatalf("unexpected issue: %+v", errors[0])
+	}
+	if errors[1].Rule != RuleUndefinedVariable || errors[1].Snippet != "undefined_arg" {
+		t.Fatalf("unexpected issue: %+v", errors[1])
+	}
+	if errors[2].Rule != RuleFilterArguments || errors[2].Line != 4 || errors[2].Message != "filter arguments: 'replace' takes 2 to 3 arguments, got 1" {
+		t.Fatalf("unexpected issue: %+v", errors[2])
+	}
+}
+
+func TestCheckSymbolsSubscripts(t *testing.T) {
+	dir := t.TempDir()
+	nslContent := "{{ items[idx] }}\n{{ items[0][\"key\"] }}\n{{ 5[0] }}\n{{ items[true] }}\n"
+	nslPath := filepath.Join(dir, "pick.nsl")
+	if err := os.WriteFile(nslPath, []byte(nslContent), 0644); err != nil {
+		t.Fatalf("write nsl: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pick.meta.yaml"), []byte("parameters:\n  - name: items\n"), 0644); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+
+	program, parseErrors := parseNSLProgram(nslContent)
+	if len(parseErrors) > 0 {
+		t.Fatalf("failed to parse NSL content: %v", parseErrors)
+	}
+	issues, err := checkSymbols(nslPath, program)
+	if err != nil {
+		t.Fatalf("checkSymbols failed: %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected three issues, got %+v", issues)
+	}
+	if issues[0].Rule != RuleUndefinedVariable || issues[0].Snippet != "idx" {
+		t.Fatalf("unexpected first issue: %+v", issues[0])
+	}
+	if issues[1].Rule != RuleInvalidSubscript || issues[1].Line != 3 || issues[2].Rule != RuleInvalidSubscript || issues[2].Line != 4 {
+		t.Fatalf("unexpected subscript issues: %+v", issues[1:])
+	}
+}
+
+func TestCheckSymbolsMacrosAndIncludes(t *testing.T) {
+	dir := t.TempDir()
+	nslContent := "{% macro line(label) %}{{ label }}: {{ value }}{% endmacro %}\n{{ line(\"a\") }}{{ label }}\n{% include \"Footer\" %}\n{% include \"Missing\" %}\n"
+	nslPath := filepath.Join(dir, "Main.nsl")
+	files := map[string]string{
+		"Main.nsl":         nslContent,
+		"Main.meta.yaml":   "idn: Main\nparameters:\n  - name: value\n",
+		"Footer.meta.yaml": "idn: Footer\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	program, parseErrors := parseNSLProgram(nslContent)
+	if len(parseErrors) > 0 {
+		t.Fatalf("failed to parse NSL content: %v", parseErrors)
+	}
+	issues, err := checkSymbols(nslPath, program)
+	if err != nil {
+		t.Fatalf("checkSymbols failed: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected two issues, got %+v", issues)
+	}
+	if issues[0].Rule != RuleUndefinedVariable || issues[0].Snippet != "label" || issues[0].Line != 2 || issues[0].Column != 19 || issues[0].EndColumn != 24 {
+		t.Fatalf("macro parameters must not leak out of the macro: %+v", issues[0])
+	}
+	if issues[1].Rule != RuleUnknownInclude || issues[1].Snippet != "Missing" || issues[1].Line != 4 {
+		t.Fatalf("unexpected include issue: %+v", issues[1])
+	}
+}
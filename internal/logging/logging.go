@@ -0,0 +1,140 @@
+// Package logging provides the leveled diagnostic log used for post-mortem debugging.
+// It is silent unless --log-level or --log-file is given; console output is unaffected.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/twinmind/newo-tool/internal/redact"
+)
+
+// Options selects where and how much to log.
+type Options struct {
+	// Level is one of debug, info, warn, or error. Empty means info when File is set.
+	Level string
+	// File receives the log; when empty, logs go to stderr if Level is set and are discarded otherwise.
+	File string
+	// Format is text or json.
+	Format string
+}
+
+var current atomic.Pointer[slog.Logger]
+
+func init() {
+	current.Store(slog.New(slog.DiscardHandler))
+}
+
+// Setup installs the logger described by opts and returns a function that closes the log file.
+func Setup(opts Options, stderr io.Writer) (func() error, error) {
+	noop := func() error { return nil }
+	levelName := strings.ToLower(strings.TrimSpace(opts.Level))
+	file := strings.TrimSpace(opts.File)
+	if levelName == "" && file == "" {
+		current.Store(slog.New(slog.DiscardHandler))
+		return noop, nil
+	}
+
+	level, err := parseLevel(levelName)
+	if err != nil {
+		return noop, err
+	}
+
+	out := stderr
+	closer := noop
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return noop, fmt.Errorf("open log file: %w", err)
+		}
+		out = f
+		closer = f.Close
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(strings.TrimSpace(opts.Format)) {
+	case "", "text":
+		handler = slog.NewTextHandler(out, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		_ = closer()
+		return noop, fmt.Errorf("unsupported log format %q (expected text or json)", opts.Format)
+	}
+	current.Store(slog.New(redactingHandler{next: handler}))
+	return closer, nil
+}
+
+func parseLevel(name string) (slog.Level, error) {
+	switch name {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unsupported log level %q (expected debug, info, warn, or error)", name)
+}
+
+// Logger returns the active logger.
+func Logger() *slog.Logger {
+	return current.Load()
+}
+
+// Debug logs at debug level.
+func Debug(msg string, args ...any) { Logger().Debug(msg, args...) }
+
+// Info logs at info level.
+func Info(msg string, args ...any) { Logger().Info(msg, args...) }
+
+// Warn logs at warn level.
+func Warn(msg string, args ...any) { Logger().Warn(msg, args...) }
+
+// Error logs at error level.
+func Error(msg string, args ...any) { Logger().Error(msg, args...) }
+
+// redactingHandler masks secrets in messages and string attributes before they are written.
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	clean := slog.NewRecord(record.Time, record.Level, redact.String(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		clean.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	cleaned := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		cleaned[i] = redactAttr(attr)
+	}
+	return redactingHandler{next: h.next.WithAttrs(cleaned)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindString {
+		return slog.String(attr.Key, redact.String(attr.Value.String()))
+	}
+	return attr
+}
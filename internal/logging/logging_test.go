@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupWritesJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "newo.log")
+	closer, err := Setup(Options{Level: "info", File: path, Format: "json"}, io.Discard)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	t.Cleanup(func() { _, _ = Setup(Options{}, io.Discard) })
+
+	Debug("hidden")
+	Info("push started", "customer", "acme", "auth", "Bearer abcdefgh12345")
+	if err := closer(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected only the info record, got %q", string(data))
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("decode record: %v", err)
+	}
+	if record["msg"] != "push started" || record["customer"] != "acme" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+	if record["auth"] != "Bearer [REDACTED]" {
+		t.Fatalf("expected secret to be redacted, got %v", record["auth"])
+	}
+}
+
+func TestSetupRejectsUnknownLevel(t *testing.T) {
+	if _, err := Setup(Options{Level: "verbose"}, io.Discard); err == nil {
+		t.Fatalf("expected error for unknown level")
+	}
+}
@@ -0,0 +1,179 @@
+// Package metrics collects a per-run summary of API traffic, phase timings, and sync counters
+// that pull and push can write to disk with --metrics-out.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Summary is the machine-readable record of a single command run.
+type Summary struct {
+	Command       string           `json:"command"`
+	StartedAt     time.Time        `json:"started_at"`
+	DurationMS    int64            `json:"duration_ms"`
+	Success       bool             `json:"success"`
+	Error         string           `json:"error,omitempty"`
+	APICalls      int64            `json:"api_calls"`
+	APIErrors     int64            `json:"api_errors"`
+	BytesSent     int64            `json:"bytes_sent"`
+	BytesReceived int64            `json:"bytes_received"`
+	PhasesMS      map[string]int64 `json:"phases_ms"`
+	Counters      map[string]int64 `json:"counters"`
+}
+
+type collector struct {
+	mu            sync.Mutex
+	command       string
+	started       time.Time
+	apiCalls      int64
+	apiErrors     int64
+	bytesSent     int64
+	bytesReceived int64
+	phases        map[string]time.Duration
+	counters      map[string]int64
+}
+
+var current = &collector{started: time.Now(), phases: map[string]time.Duration{}, counters: map[string]int64{}}
+
+// Reset starts a new run summary for the named command.
+func Reset(command string) {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	current.command = command
+	current.started = time.Now()
+	current.apiCalls, current.apiErrors, current.bytesSent, current.bytesReceived = 0, 0, 0, 0
+	current.phases = map[string]time.Duration{}
+	current.counters = map[string]int64{}
+}
+
+// RecordAPICall counts one HTTP round trip. A status of 0 marks a transport failure.
+func RecordAPICall(status int, sent, received int64) {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	current.apiCalls++
+	if status == 0 || status >= 400 {
+		current.apiErrors++
+	}
+	current.bytesSent += sent
+	current.bytesReceived += received
+}
+
+// AddBytesReceived adds response bytes read after the call was recorded.
+func AddBytesReceived(n int64) {
+	current.mu.Lock()
+	current.bytesReceived += n
+	current.mu.Unlock()
+}
+
+// Add increments a named counter such as "skills_updated".
+func Add(name string, n int) {
+	if n == 0 {
+		return
+	}
+	current.mu.Lock()
+	current.counters[name] += int64(n)
+	current.mu.Unlock()
+}
+
+// Phase starts timing a named phase; call the returned function when it ends.
+// Repeated phases, for example one per customer, accumulate.
+func Phase(name string) func() {
+	started := time.Now()
+	return func() {
+		elapsed := time.Since(started)
+		current.mu.Lock()
+		current.phases[name] += elapsed
+		current.mu.Unlock()
+	}
+}
+
+// Snapshot returns the summary collected since the last Reset.
+func Snapshot(runErr error) Summary {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	summary := Summary{
+		Command:       current.command,
+		StartedAt:     current.started.UTC(),
+		DurationMS:    time.Since(current.started).Milliseconds(),
+		Success:       runErr == nil,
+		APICalls:      current.apiCalls,
+		APIErrors:     current.apiErrors,
+		BytesSent:     current.bytesSent,
+		BytesReceived: current.bytesReceived,
+		PhasesMS:      make(map[string]int64, len(current.phases)),
+		Counters:      make(map[string]int64, len(current.counters)),
+	}
+	if runErr != nil {
+		summary.Error = runErr.Error()
+	}
+	for name, d := range current.phases {
+		summary.PhasesMS[name] = d.Milliseconds()
+	}
+	for name, n := range current.counters {
+		summary.Counters[name] = n
+	}
+	return summary
+}
+
+// WriteFile stores the summary as JSON, or in the Prometheus text format when path ends in .prom
+// (suitable for the node_exporter textfile collector).
+func WriteFile(path string, summary Summary) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".prom") {
+		data = []byte(prometheusText(summary))
+	} else {
+		encoded, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode metrics: %w", err)
+		}
+		data = append(encoded, '\n')
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create metrics directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write metrics: %w", err)
+	}
+	return nil
+}
+
+func prometheusText(summary Summary) string {
+	var b strings.Builder
+	label := fmt.Sprintf(`command=%q`, summary.Command)
+	success := 0
+	if summary.Success {
+		success = 1
+	}
+	fmt.Fprintf(&b, "newo_run_timestamp_seconds{%s} %d\n", label, summary.StartedAt.Unix())
+	fmt.Fprintf(&b, "newo_run_duration_seconds{%s} %.3f\n", label, float64(summary.DurationMS)/1000)
+	fmt.Fprintf(&b, "newo_run_success{%s} %d\n", label, success)
+	fmt.Fprintf(&b, "newo_api_calls_total{%s} %d\n", label, summary.APICalls)
+	fmt.Fprintf(&b, "newo_api_errors_total{%s} %d\n", label, summary.APIErrors)
+	fmt.Fprintf(&b, "newo_api_bytes_sent_total{%s} %d\n", label, summary.BytesSent)
+	fmt.Fprintf(&b, "newo_api_bytes_received_total{%s} %d\n", label, summary.BytesReceived)
+	for _, name := range sortedKeys(summary.PhasesMS) {
+		fmt.Fprintf(&b, "newo_phase_duration_seconds{%s,phase=%q} %.3f\n", label, name, float64(summary.PhasesMS[name])/1000)
+	}
+	for _, name := range sortedKeys(summary.Counters) {
+		fmt.Fprintf(&b, "newo_sync_items_total{%s,kind=%q} %d\n", label, name, summary.Counters[name])
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
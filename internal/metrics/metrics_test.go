@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotAndWriteFile(t *testing.T) {
+	Reset("push")
+	RecordAPICall(200, 120, 0)
+	AddBytesReceived(300)
+	RecordAPICall(500, 10, 20)
+	Add("skills_updated", 2)
+	Add("skills_updated", 1)
+	Phase("upload")()
+
+	summary := Snapshot(errors.New("boom"))
+	if summary.APICalls != 2 || summary.APIErrors != 1 {
+		t.Fatalf("unexpected call counts: %+v", summary)
+	}
+	if summary.BytesSent != 130 || summary.BytesReceived != 320 {
+		t.Fatalf("unexpected byte counts: %+v", summary)
+	}
+	if summary.Counters["skills_updated"] != 3 || summary.Success || summary.Error != "boom" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if _, ok := summary.PhasesMS["upload"]; !ok {
+		t.Fatalf("expected upload phase, got %v", summary.PhasesMS)
+	}
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "run.json")
+	if err := WriteFile(jsonPath, summary); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("read json: %v", err)
+	}
+	var decoded Summary
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if decoded.Command != "push" || decoded.Counters["skills_updated"] != 3 {
+		t.Fatalf("unexpected decoded summary: %+v", decoded)
+	}
+
+	promPath := filepath.Join(dir, "run.prom")
+	if err := WriteFile(promPath, summary); err != nil {
+		t.Fatalf("write prom: %v", err)
+	}
+	prom, err := os.ReadFile(promPath)
+	if err != nil {
+		t.Fatalf("read prom: %v", err)
+	}
+	if !strings.Contains(string(prom), `newo_sync_items_total{command="push",kind="skills_updated"} 3`) {
+		t.Fatalf("unexpected prometheus output:\n%s", prom)
+	}
+
+	Reset("pull")
+	if fresh := Snapshot(nil); fresh.APICalls != 0 || len(fresh.Counters) != 0 || fresh.Command != "pull" {
+		t.Fatalf("expected reset summary, got %+v", fresh)
+	}
+}
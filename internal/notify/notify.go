@@ -0,0 +1,229 @@
+// Package notify posts a summary of push, merge, and deploy runs to the webhooks configured in newo.toml.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/config"
+	"github.com/twinmind/newo-tool/internal/version"
+)
+
+// requestTimeout bounds each webhook call so a slow endpoint cannot hold up the command.
+const requestTimeout = 10 * time.Second
+
+// Event summarises what one command changed on the platform for one customer.
+type Event struct {
+	Command        string         `json:"command"`
+	Customer       string         `json:"customer"`
+	SourceCustomer string         `json:"source_customer,omitempty"`
+	Project        string         `json:"project,omitempty"`
+	Success        bool           `json:"success"`
+	Error          string         `json:"error,omitempty"`
+	SkillsUpdated  int            `json:"skills_updated"`
+	SkillsCreated  int            `json:"skills_created"`
+	SkillsDeleted  int            `json:"skills_deleted"`
+	Published      int            `json:"flows_published"`
+	PublishSkipped bool           `json:"publish_skipped,omitempty"`
+	Counts         map[string]int `json:"counts,omitempty"`
+	Warnings       []string       `json:"warnings,omitempty"`
+	// Drifted lists the scripts a drift check found changed on the platform outside newo.
+	Drifted    []string  `json:"drifted,omitempty"`
+	User       string    `json:"user,omitempty"`
+	Host       string    `json:"host,omitempty"`
+	Version    string    `json:"version"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Finish stamps the event with its outcome, duration, and origin.
+func (e *Event) Finish(started time.Time, err error) {
+	e.Success = err == nil
+	if err != nil {
+		e.Error = err.Error()
+	}
+	e.StartedAt = started.UTC()
+	e.DurationMS = time.Since(started).Milliseconds()
+	e.Version = version.Version
+	if current, lookupErr := user.Current(); lookupErr == nil {
+		e.User = current.Username
+	}
+	e.Host, _ = os.Hostname()
+}
+
+// Changed reports whether the event records any change on the platform.
+func (e Event) Changed() bool {
+	if e.SkillsUpdated+e.SkillsCreated+e.SkillsDeleted+e.Published+len(e.Drifted) > 0 {
+		return true
+	}
+	for _, count := range e.Counts {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Sender delivers events to webhooks.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a sender using client, or a client with a short timeout when nil.
+func NewSender(client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Sender{client: client}
+}
+
+// Send posts every event to each target that wants its command. Failures are collected and returned
+// together so one broken webhook does not stop the others.
+func (s *Sender) Send(ctx context.Context, targets []config.Notification, events []Event) error {
+	var errs []error
+	for _, target := range targets {
+		for _, event := range events {
+			if !target.Wants(event.Command) {
+				continue
+			}
+			if err := s.post(ctx, target, event); err != nil {
+				errs = append(errs, fmt.Errorf("notify %s: %w", redactURL(target.URL), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sender) post(ctx context.Context, target config.Notification, event Event) error {
+	var payload any = event
+	if target.Type == config.NotificationSlack {
+		payload = map[string]string{"text": SlackText(event)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "newo-cli/"+version.Version)
+	for name, value := range target.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// SlackText renders an event as a one-message Slack summary.
+func SlackText(event Event) string {
+	if event.Command == "drift" {
+		return driftText(event)
+	}
+	var b strings.Builder
+	target := event.Customer
+	if event.Project != "" {
+		target = event.Project + " on " + target
+	}
+	if event.SourceCustomer != "" {
+		target += " from " + event.SourceCustomer
+	}
+	if event.Success {
+		fmt.Fprintf(&b, ":white_check_mark: newo %s to %s succeeded", event.Command, target)
+	} else {
+		fmt.Fprintf(&b, ":x: newo %s to %s failed", event.Command, target)
+	}
+	if event.User != "" {
+		fmt.Fprintf(&b, " (%s", event.User)
+		if event.Host != "" {
+			fmt.Fprintf(&b, "@%s", event.Host)
+		}
+		b.WriteString(")")
+	}
+
+	parts := []string{
+		fmt.Sprintf("%d skill(s) updated", event.SkillsUpdated),
+		fmt.Sprintf("%d created", event.SkillsCreated),
+		fmt.Sprintf("%d deleted", event.SkillsDeleted),
+	}
+	if event.PublishSkipped {
+		parts = append(parts, "publishing skipped")
+	} else {
+		parts = append(parts, fmt.Sprintf("%d flow(s) published", event.Published))
+	}
+	b.WriteString("\n" + strings.Join(parts, ", "))
+
+	if len(event.Counts) > 0 {
+		keys := make([]string, 0, len(event.Counts))
+		for key := range event.Counts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var counts []string
+		for _, key := range keys {
+			if event.Counts[key] > 0 {
+				counts = append(counts, fmt.Sprintf("%s: %d", strings.ReplaceAll(key, "_", " "), event.Counts[key]))
+			}
+		}
+		if len(counts) > 0 {
+			b.WriteString("\n" + strings.Join(counts, ", "))
+		}
+	}
+	for _, warning := range event.Warnings {
+		b.WriteString("\n:warning: " + warning)
+	}
+	if event.Error != "" {
+		b.WriteString("\nError: " + event.Error)
+	}
+	return b.String()
+}
+
+// driftText renders a drift check, which changes nothing itself, as the list of scripts edited outside
+// newo.
+func driftText(event Event) string {
+	var b strings.Builder
+	if !event.Success {
+		fmt.Fprintf(&b, ":x: newo drift check of %s failed", event.Customer)
+		if event.Error != "" {
+			b.WriteString("\nError: " + event.Error)
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, ":warning: %d skill(s) of %s changed on the platform outside newo", len(event.Drifted), event.Customer)
+	for _, path := range event.Drifted {
+		b.WriteString("\n• " + path)
+	}
+	return b.String()
+}
+
+// redactURL keeps webhook secrets, which are usually part of the path, out of error messages.
+func redactURL(raw string) string {
+	if idx := strings.Index(raw, "://"); idx >= 0 {
+		if slash := strings.Index(raw[idx+3:], "/"); slash >= 0 {
+			return raw[:idx+3+slash] + "/…"
+		}
+	}
+	return raw
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/config"
+)
+
+func TestSendPostsToMatchingTargets(t *testing.T) {
+	var slackBodies []map[string]string
+	var httpEvents []Event
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slack":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			slackBodies = append(slackBodies, body)
+		case "/http":
+			var event Event
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			httpEvents = append(httpEvents, event)
+			authHeader = r.Header.Get("Authorization")
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	targets := []config.Notification{
+		{Type: config.NotificationSlack, URL: server.URL + "/slack", Events: []string{"deploy"}},
+		{Type: config.NotificationHTTP, URL: server.URL + "/http", Headers: map[string]string{"Authorization": "Bearer token"}},
+	}
+	push := Event{Command: "push", Customer: "acme", Success: true, SkillsUpdated: 2, Published: 1}
+	deploy := Event{Command: "deploy", Customer: "globex", Project: "booking", Error: "boom"}
+
+	if err := NewSender(server.Client()).Send(context.Background(), targets, []Event{push, deploy}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(slackBodies) != 1 || !strings.Contains(slackBodies[0]["text"], "newo deploy to booking on globex failed") {
+		t.Fatalf("unexpected slack messages: %+v", slackBodies)
+	}
+	if len(httpEvents) != 2 || httpEvents[0].SkillsUpdated != 2 || httpEvents[1].Error != "boom" {
+		t.Fatalf("unexpected http events: %+v", httpEvents)
+	}
+	if authHeader != "Bearer token" {
+		t.Fatalf("expected configured header, got %q", authHeader)
+	}
+
+	broken := []config.Notification{{Type: config.NotificationHTTP, URL: server.URL + "/secret-token"}}
+	err := NewSender(server.Client()).Send(context.Background(), broken, []Event{push})
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("expected a redacted error, got %v", err)
+	}
+}
+
+func TestEventFinishAndChanged(t *testing.T) {
+	var event Event
+	if event.Changed() {
+		t.Fatal("empty event should not count as a change")
+	}
+	event.Counts = map[string]int{"flows_created": 1}
+	if !event.Changed() {
+		t.Fatal("expected counts to mark a change")
+	}
+
+	event.Finish(event.StartedAt, errors.New("failed"))
+	if event.Success || event.Error != "failed" || event.Version == "" {
+		t.Fatalf("unexpected finished event: %+v", event)
+	}
+}
+
+func TestSlackTextListsSummary(t *testing.T) {
+	text := SlackText(Event{
+		Command:        "merge",
+		Customer:       "acme",
+		SourceCustomer: "acme-e2e",
+		Project:        "booking",
+		Success:        true,
+		SkillsUpdated:  3,
+		SkillsCreated:  1,
+		PublishSkipped: true,
+		Counts:         map[string]int{"flows_created": 2, "agents_created": 0},
+		Warnings:       []string{"missing project identifier"},
+	})
+	for _, want := range []string{
+		":white_check_mark: newo merge to booking on acme from acme-e2e succeeded",
+		"3 skill(s) updated, 1 created, 0 deleted, publishing skipped",
+		"flows created: 2",
+		":warning: missing project identifier",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "agents created") {
+		t.Fatalf("zero counts should be left out:\n%s", text)
+	}
+}
+
+func TestSlackTextListsDriftedSkills(t *testing.T) {
+	event := Event{Command: "drift", Customer: "acme", Success: true, Drifted: []string{"shop/bot/main/greet"}}
+	if !event.Changed() {
+		t.Fatal("expected drift to count as a change")
+	}
+	text := SlackText(event)
+	for _, want := range []string{":warning: 1 skill(s) of acme changed on the platform outside newo", "• shop/bot/main/greet"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in:\n%s", want, text)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
+
 	"github.com/twinmind/newo-tool/internal/nsl/token"
 )
 
@@ -11,6 +13,7 @@ import (
 type Node interface {
 	TokenLiteral() string // Used for debugging and testing
 	String() string
+	Range() Span
 }
 
 // Statement nodes execute actions but do not produce values.
@@ -25,8 +28,33 @@ type Expression interface {
 	expressionNode()
 }
 
+// Span is the source range a node covers. End is exclusive: it points just past the node's last character.
+// Nodes embed it, so every node reports its own range; nodes built by hand have a zero span.
+type Span struct {
+	Start token.Position
+	End   token.Position
+}
+
+// Range returns the span itself and satisfies Node for the types embedding it.
+func (s Span) Range() Span { return s }
+
+// SetRange replaces the span. The parser calls it once a node's last token has been read.
+func (s *Span) SetRange(start, end token.Position) {
+	s.Start, s.End = start, end
+}
+
+// Contains reports whether pos lies inside the span.
+func (s Span) Contains(pos token.Position) bool {
+	return !before(pos, s.Start) && before(pos, s.End)
+}
+
+func before(a, b token.Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
+
 // Program is the root node of every AST our parser produces.
 type Program struct {
+	Span
 	Statements []Statement
 }
 
@@ -48,11 +76,13 @@ func (p *Program) String() string {
 // UnmarshalJSON customizes how Program is unmarshaled from JSON.
 func (p *Program) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Statements []json.RawMessage `json:"Statements"`
 	}
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	p.Span = temp.Span
 
 	p.Statements = make([]Statement, len(temp.Statements))
 	for i, rawStmt := range temp.Statements {
@@ -73,6 +103,7 @@ func (p *Program) UnmarshalJSON(data []byte) error {
 
 // Identifier represents a variable or function name.
 type Identifier struct {
+	Span
 	Token token.Token // the token.IDENT token
 	Value string
 }
@@ -83,6 +114,7 @@ func (i *Identifier) String() string       { return i.Value }
 
 // Literals
 type IntegerLiteral struct {
+	Span
 	Token token.Token
 	Value int64
 }
@@ -91,7 +123,18 @@ func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Span
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 type StringLiteral struct {
+	Span
 	Token token.Token
 	Value string
 }
@@ -101,6 +144,7 @@ func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
 type Boolean struct {
+	Span
 	Token token.Token
 	Value bool
 }
@@ -111,6 +155,7 @@ func (b *Boolean) String() string       { return b.Token.Literal }
 
 // Complex Expressions
 type PrefixExpression struct {
+	Span
 	Token    token.Token // The prefix token, e.g. !
 	Operator string
 	Right    Expression
@@ -122,6 +167,9 @@ func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
 	out.WriteString(pe.Operator)
+	if pe.Operator == "not" {
+		out.WriteString(" ")
+	}
 	out.WriteString(pe.Right.String())
 	out.WriteString(")")
 	return out.String()
@@ -130,6 +178,7 @@ func (pe *PrefixExpression) String() string {
 // UnmarshalJSON customizes how PrefixExpression is unmarshaled from JSON.
 func (pe *PrefixExpression) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token    json.RawMessage `json:"Token"`
 		Operator string          `json:"Operator"`
 		Right    json.RawMessage `json:"Right"`
@@ -137,6 +186,7 @@ func (pe *PrefixExpression) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	pe.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &pe.Token); err != nil {
 		return err
@@ -156,6 +206,7 @@ func (pe *PrefixExpression) UnmarshalJSON(data []byte) error {
 }
 
 type InfixExpression struct {
+	Span
 	Token    token.Token // The operator token, e.g. +
 	Left     Expression
 	Operator string
@@ -177,6 +228,7 @@ func (ie *InfixExpression) String() string {
 // UnmarshalJSON customizes how InfixExpression is unmarshaled from JSON.
 func (ie *InfixExpression) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token    json.RawMessage `json:"Token"`
 		Left     json.RawMessage `json:"Left"`
 		Operator string          `json:"Operator"`
@@ -185,6 +237,7 @@ func (ie *InfixExpression) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	ie.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &ie.Token); err != nil {
 		return err
@@ -214,6 +267,7 @@ func (ie *InfixExpression) UnmarshalJSON(data []byte) error {
 }
 
 type AttributeAccess struct {
+	Span
 	Token     token.Token // The . token
 	Object    Expression
 	Attribute *Identifier
@@ -228,6 +282,7 @@ func (aa *AttributeAccess) String() string {
 // UnmarshalJSON customizes how AttributeAccess is unmarshaled from JSON.
 func (aa *AttributeAccess) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token     json.RawMessage `json:"Token"`
 		Object    json.RawMessage `json:"Object"`
 		Attribute json.RawMessage `json:"Attribute"`
@@ -235,6 +290,7 @@ func (aa *AttributeAccess) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	aa.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &aa.Token); err != nil {
 		return err
@@ -258,28 +314,42 @@ func (aa *AttributeAccess) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// FilterExpression represents a filter applied to a value, e.g. `name | upper` or `text | truncate(100)`.
 type FilterExpression struct {
-	Token  token.Token // The | token
-	Input  Expression
-	Filter *Identifier
+	Span
+	Token     token.Token // The | token
+	Input     Expression
+	Filter    *Identifier
+	Arguments []Expression // nil when the filter is written without parentheses
 }
 
 func (fe *FilterExpression) expressionNode()      {}
 func (fe *FilterExpression) TokenLiteral() string { return fe.Token.Literal }
 func (fe *FilterExpression) String() string {
-	return fe.Input.String() + " | " + fe.Filter.String()
+	out := fe.Input.String() + " | " + fe.Filter.String()
+	if fe.Arguments == nil {
+		return out
+	}
+	args := make([]string, len(fe.Arguments))
+	for i, arg := range fe.Arguments {
+		args[i] = arg.String()
+	}
+	return out + "(" + strings.Join(args, ", ") + ")"
 }
 
 // UnmarshalJSON customizes how FilterExpression is unmarshaled from JSON.
 func (fe *FilterExpression) UnmarshalJSON(data []byte) error {
 	var temp struct {
-		Token  json.RawMessage `json:"Token"`
-		Input  json.RawMessage `json:"Input"`
-		Filter json.RawMessage `json:"Filter"`
+		Span
+		Token     json.RawMessage   `json:"Token"`
+		Input     json.RawMessage   `json:"Input"`
+		Filter    json.RawMessage   `json:"Filter"`
+		Arguments []json.RawMessage `json:"Arguments"`
 	}
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	fe.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &fe.Token); err != nil {
 		return err
@@ -300,6 +370,297 @@ func (fe *FilterExpression) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	fe.Filter = &filter
+
+	if temp.Arguments != nil {
+		fe.Arguments = make([]Expression, len(temp.Arguments))
+	}
+	for i, rawArg := range temp.Arguments {
+		node, err := unmarshalNode(rawArg)
+		if err != nil {
+			return err
+		}
+		arg, ok := node.(Expression)
+		if !ok {
+			return fmt.Errorf("expected expression, got %T", node)
+		}
+		fe.Arguments[i] = arg
+	}
+	return nil
+}
+
+// CallExpression represents a function call, e.g. `range(1, 5)`.
+type CallExpression struct {
+	Span
+	Token     token.Token // The ( token
+	Function  Expression  // Identifier or attribute access being called
+	Arguments []Expression
+}
+
+func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) String() string {
+	args := make([]string, len(ce.Arguments))
+	for i, arg := range ce.Arguments {
+		args[i] = arg.String()
+	}
+	return ce.Function.String() + "(" + strings.Join(args, ", ") + ")"
+}
+
+// UnmarshalJSON customizes how CallExpression is unmarshaled from JSON.
+func (ce *CallExpression) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Token     json.RawMessage   `json:"Token"`
+		Function  json.RawMessage   `json:"Function"`
+		Arguments []json.RawMessage `json:"Arguments"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	ce.Span = temp.Span
+
+	if err := json.Unmarshal(temp.Token, &ce.Token); err != nil {
+		return err
+	}
+
+	node, err := unmarshalNode(temp.Function)
+	if err != nil {
+		return err
+	}
+	function, ok := node.(Expression)
+	if !ok {
+		return fmt.Errorf("expected expression, got %T", node)
+	}
+	ce.Function = function
+
+	ce.Arguments = make([]Expression, len(temp.Arguments))
+	for i, rawArg := range temp.Arguments {
+		node, err := unmarshalNode(rawArg)
+		if err != nil {
+			return err
+		}
+		arg, ok := node.(Expression)
+		if !ok {
+			return fmt.Errorf("expected expression, got %T", node)
+		}
+		ce.Arguments[i] = arg
+	}
+	return nil
+}
+
+// KeywordArgument is a call or filter argument passed by name, e.g. `name="x"` in `GetState(name="x")`.
+type KeywordArgument struct {
+	Span
+	Token token.Token // The name token
+	Name  *Identifier
+	Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode()      {}
+func (ka *KeywordArgument) TokenLiteral() string { return ka.Token.Literal }
+func (ka *KeywordArgument) String() string {
+	return ka.Name.String() + "=" + ka.Value.String()
+}
+
+// UnmarshalJSON customizes how KeywordArgument is unmarshaled from JSON.
+func (ka *KeywordArgument) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Token json.RawMessage `json:"Token"`
+		Name  *Identifier     `json:"Name"`
+		Value json.RawMessage `json:"Value"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	ka.Span = temp.Span
+
+	if err := json.Unmarshal(temp.Token, &ka.Token); err != nil {
+		return err
+	}
+	ka.Name = temp.Name
+
+	value, err := unmarshalExpression(temp.Value)
+	if err != nil {
+		return err
+	}
+	ka.Value = value
+	return nil
+}
+
+// IndexExpression represents a subscript, e.g. `items[0]` or `user["name"]`.
+type IndexExpression struct {
+	Span
+	Token token.Token // The [ token
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	return ie.Left.String() + "[" + ie.Index.String() + "]"
+}
+
+// UnmarshalJSON customizes how IndexExpression is unmarshaled from JSON.
+func (ie *IndexExpression) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Token json.RawMessage `json:"Token"`
+		Left  json.RawMessage `json:"Left"`
+		Index json.RawMessage `json:"Index"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	ie.Span = temp.Span
+
+	if err := json.Unmarshal(temp.Token, &ie.Token); err != nil {
+		return err
+	}
+
+	node, err := unmarshalNode(temp.Left)
+	if err != nil {
+		return err
+	}
+	left, ok := node.(Expression)
+	if !ok {
+		return fmt.Errorf("expected expression, got %T", node)
+	}
+	ie.Left = left
+
+	node, err = unmarshalNode(temp.Index)
+	if err != nil {
+		return err
+	}
+	index, ok := node.(Expression)
+	if !ok {
+		return fmt.Errorf("expected expression, got %T", node)
+	}
+	ie.Index = index
+	return nil
+}
+
+// ListLiteral represents a list such as `[1, 2, 3]`.
+type ListLiteral struct {
+	Span
+	Token    token.Token // The [ token
+	Elements []Expression
+}
+
+func (ll *ListLiteral) expressionNode()      {}
+func (ll *ListLiteral) TokenLiteral() string { return ll.Token.Literal }
+func (ll *ListLiteral) String() string {
+	elements := make([]string, len(ll.Elements))
+	for i, element := range ll.Elements {
+		elements[i] = element.String()
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// UnmarshalJSON customizes how ListLiteral is unmarshaled from JSON.
+func (ll *ListLiteral) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Token    json.RawMessage   `json:"Token"`
+		Elements []json.RawMessage `json:"Elements"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	ll.Span = temp.Span
+
+	if err := json.Unmarshal(temp.Token, &ll.Token); err != nil {
+		return err
+	}
+
+	ll.Elements = make([]Expression, len(temp.Elements))
+	for i, rawElement := range temp.Elements {
+		element, err := unmarshalExpression(rawElement)
+		if err != nil {
+			return err
+		}
+		ll.Elements[i] = element
+	}
+	return nil
+}
+
+// DictLiteral represents a dict such as `{"a": 1}`. Entries keep their source order.
+type DictLiteral struct {
+	Span
+	Token   token.Token // The { token
+	Entries []*DictEntry
+}
+
+func (dl *DictLiteral) expressionNode()      {}
+func (dl *DictLiteral) TokenLiteral() string { return dl.Token.Literal }
+func (dl *DictLiteral) String() string {
+	entries := make([]string, len(dl.Entries))
+	for i, entry := range dl.Entries {
+		entries[i] = entry.String()
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+// UnmarshalJSON customizes how DictLiteral is unmarshaled from JSON.
+func (dl *DictLiteral) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Token   json.RawMessage   `json:"Token"`
+		Entries []json.RawMessage `json:"Entries"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	dl.Span = temp.Span
+
+	if err := json.Unmarshal(temp.Token, &dl.Token); err != nil {
+		return err
+	}
+
+	dl.Entries = make([]*DictEntry, len(temp.Entries))
+	for i, rawEntry := range temp.Entries {
+		var entry DictEntry
+		if err := json.Unmarshal(rawEntry, &entry); err != nil {
+			return err
+		}
+		dl.Entries[i] = &entry
+	}
+	return nil
+}
+
+// DictEntry is one `key: value` pair of a DictLiteral.
+type DictEntry struct {
+	Span
+	Key   Expression
+	Value Expression
+}
+
+func (de *DictEntry) String() string {
+	return de.Key.String() + ": " + de.Value.String()
+}
+
+// UnmarshalJSON customizes how DictEntry is unmarshaled from JSON.
+func (de *DictEntry) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Key   json.RawMessage `json:"Key"`
+		Value json.RawMessage `json:"Value"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	de.Span = temp.Span
+
+	key, err := unmarshalExpression(temp.Key)
+	if err != nil {
+		return err
+	}
+	value, err := unmarshalExpression(temp.Value)
+	if err != nil {
+		return err
+	}
+	de.Key, de.Value = key, value
 	return nil
 }
 
@@ -307,6 +668,7 @@ func (fe *FilterExpression) UnmarshalJSON(data []byte) error {
 
 // ExpressionStatement is a statement that consists of a single expression.
 type ExpressionStatement struct {
+	Span
 	Token      token.Token // the first token of the expression
 	Expression Expression
 }
@@ -323,12 +685,14 @@ func (es *ExpressionStatement) String() string {
 // UnmarshalJSON customizes how ExpressionStatement is unmarshaled from JSON.
 func (es *ExpressionStatement) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token      json.RawMessage `json:"Token"`
 		Expression json.RawMessage `json:"Expression"`
 	}
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	es.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &es.Token); err != nil {
 		return err
@@ -346,8 +710,20 @@ func (es *ExpressionStatement) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// TextStatement is raw template text outside of tags.
+type TextStatement struct {
+	Span
+	Token token.Token // the token.TEXT token
+	Value string
+}
+
+func (ts *TextStatement) statementNode()       {}
+func (ts *TextStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TextStatement) String() string       { return ts.Value }
+
 // SetStatement represents a `{% set my_var = ... %}` statement.
 type SetStatement struct {
+	Span
 	Token token.Token // the {% token
 	Name  *Identifier
 	Value Expression
@@ -369,6 +745,7 @@ func (ss *SetStatement) String() string {
 // UnmarshalJSON customizes how SetStatement is unmarshaled from JSON.
 func (ss *SetStatement) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token json.RawMessage `json:"Token"`
 		Name  json.RawMessage `json:"Name"`
 		Value json.RawMessage `json:"Value"`
@@ -376,6 +753,7 @@ func (ss *SetStatement) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	ss.Span = temp.Span
 
 	// Unmarshal Token
 	if err := json.Unmarshal(temp.Token, &ss.Token); err != nil {
@@ -404,6 +782,7 @@ func (ss *SetStatement) UnmarshalJSON(data []byte) error {
 
 // OutputStatement represents a `{{ ... }}` block.
 type OutputStatement struct {
+	Span
 	Token      token.Token // the {{ token
 	Expression Expression
 }
@@ -417,12 +796,14 @@ func (os *OutputStatement) String() string {
 // UnmarshalJSON customizes how OutputStatement is unmarshaled from JSON.
 func (os *OutputStatement) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token      json.RawMessage `json:"Token"`
 		Expression json.RawMessage `json:"Expression"`
 	}
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	os.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &os.Token); err != nil {
 		return err
@@ -442,6 +823,7 @@ func (os *OutputStatement) UnmarshalJSON(data []byte) error {
 
 // BlockStatement is a sequence of statements.
 type BlockStatement struct {
+	Span
 	Token      token.Token // the {% or {{ token that starts the block
 	Statements []Statement
 }
@@ -459,12 +841,14 @@ func (bs *BlockStatement) String() string {
 // UnmarshalJSON customizes how BlockStatement is unmarshaled from JSON.
 func (bs *BlockStatement) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token      json.RawMessage   `json:"Token"`
 		Statements []json.RawMessage `json:"Statements"`
 	}
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	bs.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &bs.Token); err != nil {
 		return err
@@ -487,6 +871,7 @@ func (bs *BlockStatement) UnmarshalJSON(data []byte) error {
 
 // IfStatement represents an `if-else` expression.
 type IfStatement struct {
+	Span
 	Token       token.Token // The {% token
 	Condition   Expression
 	Consequence *BlockStatement
@@ -511,6 +896,7 @@ func (is *IfStatement) String() string {
 // UnmarshalJSON customizes how IfStatement is unmarshaled from JSON.
 func (is *IfStatement) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token       json.RawMessage   `json:"Token"`
 		Condition   json.RawMessage   `json:"Condition"`
 		Consequence json.RawMessage   `json:"Consequence"`
@@ -520,6 +906,7 @@ func (is *IfStatement) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	is.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &is.Token); err != nil {
 		return err
@@ -550,7 +937,7 @@ func (is *IfStatement) UnmarshalJSON(data []byte) error {
 		is.ElseIfs[i] = &elif
 	}
 
-	if len(temp.Alternative) > 0 {
+	if len(temp.Alternative) > 0 && string(temp.Alternative) != "null" {
 		var alternative BlockStatement
 		if err := json.Unmarshal(temp.Alternative, &alternative); err != nil {
 			return err
@@ -561,6 +948,7 @@ func (is *IfStatement) UnmarshalJSON(data []byte) error {
 }
 
 type ElseIfClause struct {
+	Span
 	Token       token.Token
 	Condition   Expression
 	Consequence *BlockStatement
@@ -576,6 +964,7 @@ func (eic *ElseIfClause) String() string {
 // UnmarshalJSON customizes how ElseIfClause is unmarshaled from JSON.
 func (eic *ElseIfClause) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token       json.RawMessage `json:"Token"`
 		Condition   json.RawMessage `json:"Condition"`
 		Consequence json.RawMessage `json:"Consequence"`
@@ -583,6 +972,7 @@ func (eic *ElseIfClause) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	eic.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &eic.Token); err != nil {
 		return err
@@ -608,6 +998,7 @@ func (eic *ElseIfClause) UnmarshalJSON(data []byte) error {
 
 // ForStatement represents a `for` loop.
 type ForStatement struct {
+	Span
 	Token    token.Token // The {% token
 	Iterator *Identifier
 	Sequence Expression
@@ -626,6 +1017,7 @@ func (fs *ForStatement) String() string {
 // UnmarshalJSON customizes how ForStatement is unmarshaled from JSON.
 func (fs *ForStatement) UnmarshalJSON(data []byte) error {
 	var temp struct {
+		Span
 		Token    json.RawMessage `json:"Token"`
 		Iterator json.RawMessage `json:"Iterator"`
 		Sequence json.RawMessage `json:"Sequence"`
@@ -634,6 +1026,7 @@ func (fs *ForStatement) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
 	}
+	fs.Span = temp.Span
 
 	if err := json.Unmarshal(temp.Token, &fs.Token); err != nil {
 		return err
@@ -663,6 +1056,103 @@ func (fs *ForStatement) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MacroStatement defines a reusable template fragment: `{% macro greet(name) %}...{% endmacro %}`.
+type MacroStatement struct {
+	Span
+	Token      token.Token // The macro token
+	Name       *Identifier
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ms *MacroStatement) statementNode()       {}
+func (ms *MacroStatement) TokenLiteral() string { return ms.Token.Literal }
+func (ms *MacroStatement) String() string {
+	params := make([]string, len(ms.Parameters))
+	for i, param := range ms.Parameters {
+		params[i] = param.String()
+	}
+	return "macro " + ms.Name.String() + "(" + strings.Join(params, ", ") + ") " + ms.Body.String()
+}
+
+// UnmarshalJSON customizes how MacroStatement is unmarshaled from JSON.
+func (ms *MacroStatement) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Token      json.RawMessage   `json:"Token"`
+		Name       json.RawMessage   `json:"Name"`
+		Parameters []json.RawMessage `json:"Parameters"`
+		Body       json.RawMessage   `json:"Body"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	ms.Span = temp.Span
+
+	if err := json.Unmarshal(temp.Token, &ms.Token); err != nil {
+		return err
+	}
+
+	var name Identifier
+	if err := json.Unmarshal(temp.Name, &name); err != nil {
+		return err
+	}
+	ms.Name = &name
+
+	ms.Parameters = make([]*Identifier, len(temp.Parameters))
+	for i, rawParam := range temp.Parameters {
+		var param Identifier
+		if err := json.Unmarshal(rawParam, &param); err != nil {
+			return err
+		}
+		ms.Parameters[i] = &param
+	}
+
+	var body BlockStatement
+	if err := json.Unmarshal(temp.Body, &body); err != nil {
+		return err
+	}
+	ms.Body = &body
+	return nil
+}
+
+// IncludeStatement renders another skill of the flow in place: `{% include "other_skill" %}`.
+type IncludeStatement struct {
+	Span
+	Token token.Token // The include token
+	Skill Expression  // Usually a string literal naming the skill IDN
+}
+
+func (is *IncludeStatement) statementNode()       {}
+func (is *IncludeStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IncludeStatement) String() string {
+	return "include " + is.Skill.String()
+}
+
+// UnmarshalJSON customizes how IncludeStatement is unmarshaled from JSON.
+func (is *IncludeStatement) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Span
+		Token json.RawMessage `json:"Token"`
+		Skill json.RawMessage `json:"Skill"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	is.Span = temp.Span
+
+	if err := json.Unmarshal(temp.Token, &is.Token); err != nil {
+		return err
+	}
+
+	skill, err := unmarshalExpression(temp.Skill)
+	if err != nil {
+		return err
+	}
+	is.Skill = skill
+	return nil
+}
+
 // unmarshalNode is a helper function to unmarshal raw JSON into the correct ast.Node type.
 func unmarshalNode(raw json.RawMessage) (Node, error) {
 	var nw struct {
@@ -688,6 +1178,12 @@ func unmarshalNode(raw json.RawMessage) (Node, error) {
 			return nil, err
 		}
 		return &stmt, nil
+	case "TextStatement":
+		var stmt TextStatement
+		if err := json.Unmarshal(raw, &stmt); err != nil {
+			return nil, err
+		}
+		return &stmt, nil
 	case "ExpressionStatement":
 		var stmt ExpressionStatement
 		if err := json.Unmarshal(raw, &stmt); err != nil {
@@ -706,6 +1202,18 @@ func unmarshalNode(raw json.RawMessage) (Node, error) {
 			return nil, err
 		}
 		return &stmt, nil
+	case "MacroStatement":
+		var stmt MacroStatement
+		if err := json.Unmarshal(raw, &stmt); err != nil {
+			return nil, err
+		}
+		return &stmt, nil
+	case "IncludeStatement":
+		var stmt IncludeStatement
+		if err := json.Unmarshal(raw, &stmt); err != nil {
+			return nil, err
+		}
+		return &stmt, nil
 	case "BlockStatement":
 		var stmt BlockStatement
 		if err := json.Unmarshal(raw, &stmt); err != nil {
@@ -725,6 +1233,24 @@ func unmarshalNode(raw json.RawMessage) (Node, error) {
 			return nil, err
 		}
 		return &expr, nil
+	case "FloatLiteral":
+		var expr FloatLiteral
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return nil, err
+		}
+		return &expr, nil
+	case "ListLiteral":
+		var expr ListLiteral
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return nil, err
+		}
+		return &expr, nil
+	case "DictLiteral":
+		var expr DictLiteral
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return nil, err
+		}
+		return &expr, nil
 	case "StringLiteral":
 		var expr StringLiteral
 		if err := json.Unmarshal(raw, &expr); err != nil {
@@ -761,9 +1287,40 @@ func unmarshalNode(raw json.RawMessage) (Node, error) {
 			return nil, err
 		}
 		return &expr, nil
+	case "CallExpression":
+		var expr CallExpression
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return nil, err
+		}
+		return &expr, nil
+	case "KeywordArgument":
+		var expr KeywordArgument
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return nil, err
+		}
+		return &expr, nil
+	case "IndexExpression":
+		var expr IndexExpression
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return nil, err
+		}
+		return &expr, nil
 	case "Token": // Token is a struct, not an interface, so it should be unmarshaled directly
 		return nil, fmt.Errorf("token should not be unmarshaled via unmarshalNode")
 	default:
 		return nil, fmt.Errorf("unknown AST node type: %s", nw.Type)
 	}
 }
+
+// unmarshalExpression decodes a node that must be an expression.
+func unmarshalExpression(raw json.RawMessage) (Expression, error) {
+	node, err := unmarshalNode(raw)
+	if err != nil {
+		return nil, err
+	}
+	expr, ok := node.(Expression)
+	if !ok {
+		return nil, fmt.Errorf("expected expression, got %T", node)
+	}
+	return expr, nil
+}
@@ -1,6 +1,8 @@
 package ast
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/twinmind/newo-tool/internal/nsl/token"
@@ -32,3 +34,51 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
 	}
 }
+
+// TestJSONRoundTrip confirms that marshalled nodes carry their "_type" tags and decode back into the same tree.
+func TestJSONRoundTrip(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&TextStatement{Token: token.Token{Type: token.TEXT, Literal: "Hi "}, Value: "Hi "},
+			&IfStatement{
+				Token: token.Token{Type: token.IF, Literal: "if"},
+				Condition: &PrefixExpression{
+					Token:    token.Token{Type: token.BANG, Literal: "!"},
+					Operator: "!",
+					Right:    &Identifier{Token: token.Token{Type: token.IDENT, Literal: "quiet"}, Value: "quiet"},
+				},
+				Consequence: &BlockStatement{
+					Statements: []Statement{
+						&OutputStatement{
+							Expression: &FilterExpression{
+								Input:  &Identifier{Token: token.Token{Type: token.IDENT, Literal: "name"}, Value: "name"},
+								Filter: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "upper"}, Value: "upper"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(program)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, tag := range []string{`"_type":"Program"`, `"_type":"TextStatement"`, `"_type":"IfStatement"`, `"_type":"FilterExpression"`} {
+		if !strings.Contains(string(data), tag) {
+			t.Errorf("expected %s in %s", tag, data)
+		}
+	}
+
+	var decoded Program
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.String() != program.String() {
+		t.Errorf("expected %q, got %q", program.String(), decoded.String())
+	}
+	if ifStmt, ok := decoded.Statements[1].(*IfStatement); !ok || ifStmt.Alternative != nil {
+		t.Errorf("expected an if statement without alternative, got %#v", decoded.Statements[1])
+	}
+}
@@ -0,0 +1,176 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// marshalTyped encodes a node and prepends the "_type" tag that unmarshalNode dispatches on. node must be
+// a local alias of the node type so that encoding does not recurse into MarshalJSON.
+func marshalTyped(nodeType string, node any) ([]byte, error) {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 || data[0] != '{' {
+		return nil, fmt.Errorf("%s did not encode as an object", nodeType)
+	}
+	var out bytes.Buffer
+	out.WriteString(`{"_type":"` + nodeType + `"`)
+	if len(data) > 2 {
+		out.WriteByte(',')
+	}
+	out.Write(data[1:])
+	return out.Bytes(), nil
+}
+
+// MarshalJSON encodes Program together with its "_type" tag.
+func (p *Program) MarshalJSON() ([]byte, error) {
+	type alias Program
+	return marshalTyped("Program", (*alias)(p))
+}
+
+// MarshalJSON encodes Identifier together with its "_type" tag.
+func (i *Identifier) MarshalJSON() ([]byte, error) {
+	type alias Identifier
+	return marshalTyped("Identifier", (*alias)(i))
+}
+
+// MarshalJSON encodes IntegerLiteral together with its "_type" tag.
+func (il *IntegerLiteral) MarshalJSON() ([]byte, error) {
+	type alias IntegerLiteral
+	return marshalTyped("IntegerLiteral", (*alias)(il))
+}
+
+// MarshalJSON encodes FloatLiteral together with its "_type" tag.
+func (fl *FloatLiteral) MarshalJSON() ([]byte, error) {
+	type alias FloatLiteral
+	return marshalTyped("FloatLiteral", (*alias)(fl))
+}
+
+// MarshalJSON encodes StringLiteral together with its "_type" tag.
+func (sl *StringLiteral) MarshalJSON() ([]byte, error) {
+	type alias StringLiteral
+	return marshalTyped("StringLiteral", (*alias)(sl))
+}
+
+// MarshalJSON encodes Boolean together with its "_type" tag.
+func (b *Boolean) MarshalJSON() ([]byte, error) {
+	type alias Boolean
+	return marshalTyped("Boolean", (*alias)(b))
+}
+
+// MarshalJSON encodes PrefixExpression together with its "_type" tag.
+func (pe *PrefixExpression) MarshalJSON() ([]byte, error) {
+	type alias PrefixExpression
+	return marshalTyped("PrefixExpression", (*alias)(pe))
+}
+
+// MarshalJSON encodes InfixExpression together with its "_type" tag.
+func (ie *InfixExpression) MarshalJSON() ([]byte, error) {
+	type alias InfixExpression
+	return marshalTyped("InfixExpression", (*alias)(ie))
+}
+
+// MarshalJSON encodes AttributeAccess together with its "_type" tag.
+func (aa *AttributeAccess) MarshalJSON() ([]byte, error) {
+	type alias AttributeAccess
+	return marshalTyped("AttributeAccess", (*alias)(aa))
+}
+
+// MarshalJSON encodes FilterExpression together with its "_type" tag.
+func (fe *FilterExpression) MarshalJSON() ([]byte, error) {
+	type alias FilterExpression
+	return marshalTyped("FilterExpression", (*alias)(fe))
+}
+
+// MarshalJSON encodes CallExpression together with its "_type" tag.
+func (ce *CallExpression) MarshalJSON() ([]byte, error) {
+	type alias CallExpression
+	return marshalTyped("CallExpression", (*alias)(ce))
+}
+
+// MarshalJSON encodes IndexExpression together with its "_type" tag.
+func (ie *IndexExpression) MarshalJSON() ([]byte, error) {
+	type alias IndexExpression
+	return marshalTyped("IndexExpression", (*alias)(ie))
+}
+
+// MarshalJSON encodes KeywordArgument together with its "_type" tag.
+func (ka *KeywordArgument) MarshalJSON() ([]byte, error) {
+	type alias KeywordArgument
+	return marshalTyped("KeywordArgument", (*alias)(ka))
+}
+
+// MarshalJSON encodes ListLiteral together with its "_type" tag.
+func (ll *ListLiteral) MarshalJSON() ([]byte, error) {
+	type alias ListLiteral
+	return marshalTyped("ListLiteral", (*alias)(ll))
+}
+
+// MarshalJSON encodes DictLiteral together with its "_type" tag.
+func (dl *DictLiteral) MarshalJSON() ([]byte, error) {
+	type alias DictLiteral
+	return marshalTyped("DictLiteral", (*alias)(dl))
+}
+
+// MarshalJSON encodes ExpressionStatement together with its "_type" tag.
+func (es *ExpressionStatement) MarshalJSON() ([]byte, error) {
+	type alias ExpressionStatement
+	return marshalTyped("ExpressionStatement", (*alias)(es))
+}
+
+// MarshalJSON encodes TextStatement together with its "_type" tag.
+func (ts *TextStatement) MarshalJSON() ([]byte, error) {
+	type alias TextStatement
+	return marshalTyped("TextStatement", (*alias)(ts))
+}
+
+// MarshalJSON encodes SetStatement together with its "_type" tag.
+func (ss *SetStatement) MarshalJSON() ([]byte, error) {
+	type alias SetStatement
+	return marshalTyped("SetStatement", (*alias)(ss))
+}
+
+// MarshalJSON encodes OutputStatement together with its "_type" tag.
+func (os *OutputStatement) MarshalJSON() ([]byte, error) {
+	type alias OutputStatement
+	return marshalTyped("OutputStatement", (*alias)(os))
+}
+
+// MarshalJSON encodes BlockStatement together with its "_type" tag.
+func (bs *BlockStatement) MarshalJSON() ([]byte, error) {
+	type alias BlockStatement
+	return marshalTyped("BlockStatement", (*alias)(bs))
+}
+
+// MarshalJSON encodes IfStatement together with its "_type" tag.
+func (is *IfStatement) MarshalJSON() ([]byte, error) {
+	type alias IfStatement
+	return marshalTyped("IfStatement", (*alias)(is))
+}
+
+// MarshalJSON encodes ElseIfClause together with its "_type" tag.
+func (eic *ElseIfClause) MarshalJSON() ([]byte, error) {
+	type alias ElseIfClause
+	return marshalTyped("ElseIfClause", (*alias)(eic))
+}
+
+// MarshalJSON encodes ForStatement together with its "_type" tag.
+func (fs *ForStatement) MarshalJSON() ([]byte, error) {
+	type alias ForStatement
+	return marshalTyped("ForStatement", (*alias)(fs))
+}
+
+// MarshalJSON encodes MacroStatement together with its "_type" tag.
+func (ms *MacroStatement) MarshalJSON() ([]byte, error) {
+	type alias MacroStatement
+	return marshalTyped("MacroStatement", (*alias)(ms))
+}
+
+// MarshalJSON encodes IncludeStatement together with its "_type" tag.
+func (is *IncludeStatement) MarshalJSON() ([]byte, error) {
+	type alias IncludeStatement
+	return marshalTyped("IncludeStatement", (*alias)(is))
+}
@@ -0,0 +1,101 @@
+// Package builtin describes the filters available to NSL templates, independent of how they are evaluated.
+package builtin
+
+import "fmt"
+
+// Arity is the number of arguments a filter accepts after its input. Max is -1 when any number of
+// further arguments is accepted.
+type Arity struct {
+	Min int
+	Max int
+}
+
+// Accepts reports whether a call with n arguments is valid.
+func (a Arity) Accepts(n int) bool {
+	return n >= a.Min && (a.Max < 0 || n <= a.Max)
+}
+
+func (a Arity) String() string {
+	switch {
+	case a.Max < 0:
+		return fmt.Sprintf("at least %s", arguments(a.Min))
+	case a.Min == a.Max:
+		if a.Min == 0 {
+			return "no arguments"
+		}
+		return arguments(a.Min)
+	}
+	return fmt.Sprintf("%d to %s", a.Min, arguments(a.Max))
+}
+
+func arguments(n int) string {
+	if n == 1 {
+		return "1 argument"
+	}
+	return fmt.Sprintf("%d arguments", n)
+}
+
+// filters follows the Jinja signatures; keyword parameters count as optional positional ones.
+var filters = map[string]Arity{
+	"abs":            {0, 0},
+	"attr":           {1, 1},
+	"batch":          {1, 2},
+	"capitalize":     {0, 0},
+	"center":         {0, 1},
+	"count":          {0, 0},
+	"d":              {0, 2},
+	"default":        {0, 2},
+	"dictsort":       {0, 3},
+	"e":              {0, 0},
+	"escape":         {0, 0},
+	"filesizeformat": {0, 1},
+	"first":          {0, 0},
+	"float":          {0, 1},
+	"forceescape":    {0, 0},
+	"format":         {0, -1},
+	"fromjson":       {0, 0},
+	"groupby":        {1, 3},
+	"indent":         {0, 3},
+	"int":            {0, 2},
+	"items":          {0, 0},
+	"join":           {0, 2},
+	"last":           {0, 0},
+	"length":         {0, 0},
+	"list":           {0, 0},
+	"lower":          {0, 0},
+	"map":            {0, -1},
+	"max":            {0, 2},
+	"min":            {0, 2},
+	"pprint":         {0, 0},
+	"random":         {0, 0},
+	"reject":         {0, -1},
+	"rejectattr":     {0, -1},
+	"replace":        {2, 3},
+	"reverse":        {0, 0},
+	"round":          {0, 2},
+	"safe":           {0, 0},
+	"select":         {0, -1},
+	"selectattr":     {0, -1},
+	"slice":          {1, 2},
+	"sort":           {0, 3},
+	"string":         {0, 0},
+	"striptags":      {0, 0},
+	"sum":            {0, 2},
+	"title":          {0, 0},
+	"tojson":         {0, 1},
+	"trim":           {0, 1},
+	"truncate":       {0, 4},
+	"unique":         {0, 2},
+	"upper":          {0, 0},
+	"urlencode":      {0, 0},
+	"urlize":         {0, 4},
+	"wordcount":      {0, 0},
+	"wordwrap":       {0, 4},
+	"xmlattr":        {0, 1},
+}
+
+// LookupFilter returns the arity of a built-in filter.
+func LookupFilter(name string) (Arity, bool) {
+	arity, ok := filters[name]
+	return arity, ok
+}
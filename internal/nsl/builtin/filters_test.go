@@ -0,0 +1,38 @@
+package builtin
+
+import "testing"
+
+func TestArity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		calls    int
+		accepted bool
+		text     string
+	}{
+		{name: "upper", calls: 0, accepted: true, text: "no arguments"},
+		{name: "upper", calls: 1, accepted: false, text: "no arguments"},
+		{name: "attr", calls: 1, accepted: true, text: "1 argument"},
+		{name: "replace", calls: 1, accepted: false, text: "2 to 3 arguments"},
+		{name: "truncate", calls: 4, accepted: true, text: "0 to 4 arguments"},
+		{name: "format", calls: 7, accepted: true, text: "at least 0 arguments"},
+	}
+
+	for _, tt := range tests {
+		arity, ok := LookupFilter(tt.name)
+		if !ok {
+			t.Fatalf("%s: not registered", tt.name)
+		}
+		if got := arity.Accepts(tt.calls); got != tt.accepted {
+			t.Errorf("%s with %d arguments: expected %t, got %t", tt.name, tt.calls, tt.accepted, got)
+		}
+		if got := arity.String(); got != tt.text {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.text, got)
+		}
+	}
+
+	if _, ok := LookupFilter("shout"); ok {
+		t.Fatalf("unexpected filter shout")
+	}
+}
@@ -0,0 +1,793 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/nsl/ast"
+	"github.com/twinmind/newo-tool/internal/nsl/builtin"
+	"github.com/twinmind/newo-tool/internal/nsl/lexer"
+	"github.com/twinmind/newo-tool/internal/nsl/parser"
+	"github.com/twinmind/newo-tool/internal/nsl/token"
+)
+
+// Error is an evaluation failure at a position in the template.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// RenderString parses source as a template and renders it with the given variables.
+func RenderString(source string, vars map[string]any) (string, error) {
+	return RenderStringWithLoader(source, vars, nil)
+}
+
+// RenderStringWithLoader parses source as a template and renders it, resolving includes with load.
+func RenderStringWithLoader(source string, vars map[string]any, load Loader) (string, error) {
+	p := parser.New(lexer.NewTemplate(source))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return "", fmt.Errorf("parse: %s", strings.Join(errs, "; "))
+	}
+	return RenderWithLoader(program, vars, load)
+}
+
+// Render evaluates a parsed template and returns the text it produces. Variables may hold Go scalars,
+// slices, and string-keyed maps; undefined names evaluate to null and render as an empty string.
+func Render(program *ast.Program, vars map[string]any) (string, error) {
+	return RenderWithLoader(program, vars, nil)
+}
+
+// Loader returns the source of the skill an include statement names.
+type Loader func(skill string) (string, error)
+
+// maxIncludeDepth stops include cycles between skills.
+const maxIncludeDepth = 10
+
+// maxMacroDepth stops runaway macro recursion before it overflows the Go stack.
+const maxMacroDepth = 100
+
+// RenderWithLoader renders like Render and resolves include statements with load. Included skills are
+// rendered with the variables and macros visible at the include. A nil loader makes includes fail.
+func RenderWithLoader(program *ast.Program, vars map[string]any, load Loader) (string, error) {
+	e := &evaluator{scope: newScope(nil), out: &strings.Builder{}, load: load}
+	for name, value := range vars {
+		e.scope.vars[name] = normalize(value)
+	}
+	for _, stmt := range program.Statements {
+		if err := e.statement(stmt); err != nil {
+			return "", err
+		}
+	}
+	return e.out.String(), nil
+}
+
+type scope struct {
+	vars   map[string]any
+	parent *scope
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{vars: map[string]any{}, parent: parent}
+}
+
+func (s *scope) lookup(name string) (any, bool) {
+	for current := s; current != nil; current = current.parent {
+		if value, ok := current.vars[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+type evaluator struct {
+	scope    *scope
+	out      *strings.Builder
+	load     Loader
+	includes []string // skills being included, innermost last
+	macros   int      // macro calls in progress
+}
+
+// macro is a defined macro together with the scope it was defined in.
+type macro struct {
+	stmt  *ast.MacroStatement
+	scope *scope
+}
+
+func (e *evaluator) statement(stmt ast.Statement) error {
+	switch s := stmt.(type) {
+	case *ast.TextStatement:
+		e.out.WriteString(s.Value)
+	case *ast.OutputStatement:
+		value, err := e.expression(s.Expression)
+		if err != nil {
+			return err
+		}
+		e.out.WriteString(Format(value))
+	case *ast.ExpressionStatement:
+		value, err := e.expression(s.Expression)
+		if err != nil {
+			return err
+		}
+		e.out.WriteString(Format(value))
+	case *ast.SetStatement:
+		value, err := e.expression(s.Value)
+		if err != nil {
+			return err
+		}
+		e.scope.vars[s.Name.Value] = value
+	case *ast.IfStatement:
+		return e.ifStatement(s)
+	case *ast.ForStatement:
+		return e.forStatement(s)
+	case *ast.MacroStatement:
+		e.scope.vars[s.Name.Value] = &macro{stmt: s, scope: e.scope}
+	case *ast.IncludeStatement:
+		return e.include(s)
+	case *ast.BlockStatement:
+		return e.block(s)
+	default:
+		return &Error{Message: fmt.Sprintf("unsupported statement %T", stmt)}
+	}
+	return nil
+}
+
+func (e *evaluator) block(block *ast.BlockStatement) error {
+	if block == nil {
+		return nil
+	}
+	for _, stmt := range block.Statements {
+		if err := e.statement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *evaluator) ifStatement(stmt *ast.IfStatement) error {
+	cond, err := e.expression(stmt.Condition)
+	if err != nil {
+		return err
+	}
+	if Truthy(cond) {
+		return e.block(stmt.Consequence)
+	}
+	for _, clause := range stmt.ElseIfs {
+		cond, err := e.expression(clause.Condition)
+		if err != nil {
+			return err
+		}
+		if Truthy(cond) {
+			return e.block(clause.Consequence)
+		}
+	}
+	return e.block(stmt.Alternative)
+}
+
+// forStatement runs the body once per item in a child scope that also exposes a Jinja-style `loop`.
+func (e *evaluator) forStatement(stmt *ast.ForStatement) error {
+	seq, err := e.expression(stmt.Sequence)
+	if err != nil {
+		return err
+	}
+	items, err := iterate(seq)
+	if err != nil {
+		return errorAt(stmt.Token, err.Error())
+	}
+
+	outer := e.scope
+	defer func() { e.scope = outer }()
+	for idx, item := range items {
+		e.scope = newScope(outer)
+		e.scope.vars[stmt.Iterator.Value] = item
+		e.scope.vars["loop"] = map[string]any{
+			"index":  int64(idx + 1),
+			"index0": int64(idx),
+			"first":  idx == 0,
+			"last":   idx == len(items)-1,
+			"length": int64(len(items)),
+		}
+		if err := e.block(stmt.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *evaluator) expression(expr ast.Expression) (any, error) {
+	switch x := expr.(type) {
+	case *ast.Identifier:
+		value, _ := e.scope.lookup(x.Value)
+		return value, nil
+	case *ast.IntegerLiteral:
+		return x.Value, nil
+	case *ast.FloatLiteral:
+		return x.Value, nil
+	case *ast.StringLiteral:
+		return x.Value, nil
+	case *ast.ListLiteral:
+		items := make([]any, len(x.Elements))
+		for i, element := range x.Elements {
+			value, err := e.expression(element)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	case *ast.DictLiteral:
+		return e.dict(x)
+	case *ast.Boolean:
+		return x.Value, nil
+	case *ast.PrefixExpression:
+		right, err := e.expression(x.Right)
+		if err != nil {
+			return nil, err
+		}
+		return prefix(x, right)
+	case *ast.InfixExpression:
+		switch {
+		case x.Operator == "and" || x.Operator == "or":
+			return e.logical(x)
+		case isComparison(x.Operator) && isChainedComparison(x.Left):
+			return e.chainedComparison(x)
+		}
+		left, err := e.expression(x.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.expression(x.Right)
+		if err != nil {
+			return nil, err
+		}
+		return infix(x, left, right)
+	case *ast.AttributeAccess:
+		object, err := e.expression(x.Object)
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := object.(map[string]any); ok {
+			return m[x.Attribute.Value], nil
+		}
+		return nil, nil
+	case *ast.FilterExpression:
+		input, err := e.expression(x.Input)
+		if err != nil {
+			return nil, err
+		}
+		filter, ok := filters[x.Filter.Value]
+		if !ok {
+			return nil, errorAt(x.Filter.Token, fmt.Sprintf("unknown filter %q", x.Filter.Value))
+		}
+		if arity, ok := builtin.LookupFilter(x.Filter.Value); ok && !arity.Accepts(len(x.Arguments)) {
+			return nil, errorAt(x.Filter.Token, fmt.Sprintf("filter %s takes %s, got %d", x.Filter.Value, arity, len(x.Arguments)))
+		}
+		args := make([]any, len(x.Arguments))
+		for i, arg := range x.Arguments {
+			if args[i], err = e.expression(arg); err != nil {
+				return nil, err
+			}
+		}
+		value, err := filter(input, args)
+		if err != nil {
+			return nil, errorAt(x.Filter.Token, fmt.Sprintf("filter %s: %v", x.Filter.Value, err))
+		}
+		return value, nil
+	case *ast.IndexExpression:
+		left, err := e.expression(x.Left)
+		if err != nil {
+			return nil, err
+		}
+		index, err := e.expression(x.Index)
+		if err != nil {
+			return nil, err
+		}
+		return subscript(x, left, index)
+	case *ast.CallExpression:
+		return e.call(x)
+	case *ast.KeywordArgument:
+		return nil, errorAt(x.Token, fmt.Sprintf("keyword argument %s= is not supported when rendering locally", x.Name.Value))
+	case nil:
+		return nil, &Error{Message: "missing expression"}
+	default:
+		return nil, &Error{Message: fmt.Sprintf("unsupported expression %T", expr)}
+	}
+}
+
+// logical evaluates `and` and `or` with short-circuiting. Like Jinja, the result is the operand that
+// decided the outcome rather than a boolean, so `name or "guest"` yields a fallback value.
+func (e *evaluator) logical(x *ast.InfixExpression) (any, error) {
+	left, err := e.expression(x.Left)
+	if err != nil {
+		return nil, err
+	}
+	if Truthy(left) == (x.Operator == "or") {
+		return left, nil
+	}
+	return e.expression(x.Right)
+}
+
+// chainedComparison evaluates `a < b <= c` as `a < b and b <= c`, evaluating each operand once. The
+// parser builds such chains left-associatively, so the left operand is itself a comparison.
+func (e *evaluator) chainedComparison(x *ast.InfixExpression) (any, error) {
+	var links []*ast.InfixExpression
+	var first ast.Expression = x
+	for {
+		link, ok := first.(*ast.InfixExpression)
+		if !ok || !isComparison(link.Operator) {
+			break
+		}
+		links = append([]*ast.InfixExpression{link}, links...)
+		first = link.Left
+	}
+
+	left, err := e.expression(first)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		right, err := e.expression(link.Right)
+		if err != nil {
+			return nil, err
+		}
+		result, err := infix(link, left, right)
+		if err != nil {
+			return nil, err
+		}
+		if !Truthy(result) {
+			return false, nil
+		}
+		left = right
+	}
+	return true, nil
+}
+
+func isComparison(operator string) bool {
+	switch operator {
+	case "==", "!=", "<", ">", "<=", ">=":
+		return true
+	}
+	return false
+}
+
+func isChainedComparison(expr ast.Expression) bool {
+	infix, ok := expr.(*ast.InfixExpression)
+	return ok && isComparison(infix.Operator)
+}
+
+// dict builds a map from a dict literal. Keys must evaluate to strings; later duplicates win.
+func (e *evaluator) dict(x *ast.DictLiteral) (any, error) {
+	out := make(map[string]any, len(x.Entries))
+	for _, entry := range x.Entries {
+		key, err := e.expression(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, errorAt(x.Token, fmt.Sprintf("dict keys must be strings, got %s", typeName(key)))
+		}
+		value, err := e.expression(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = value
+	}
+	return out, nil
+}
+
+// call invokes a macro or a global function. Only plain names can be called; arguments are evaluated
+// left to right.
+func (e *evaluator) call(x *ast.CallExpression) (any, error) {
+	name, ok := x.Function.(*ast.Identifier)
+	if !ok {
+		return nil, errorAt(x.Token, fmt.Sprintf("cannot call %s", x.Function.String()))
+	}
+	value, _ := e.scope.lookup(name.Value)
+	m, isMacro := value.(*macro)
+	fn, isFunction := functions[name.Value]
+	if !isMacro && !isFunction {
+		return nil, errorAt(name.Token, fmt.Sprintf("unknown function %q", name.Value))
+	}
+	args := make([]any, len(x.Arguments))
+	for i, arg := range x.Arguments {
+		value, err := e.expression(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	if isMacro {
+		return e.callMacro(name, m, args)
+	}
+	value, err := fn(args)
+	if err != nil {
+		return nil, errorAt(name.Token, fmt.Sprintf("%s(): %v", name.Value, err))
+	}
+	return value, nil
+}
+
+// subscript looks up a list element or map entry. Negative list indexes count from the end; missing
+// entries evaluate to null like undefined attributes do.
+func subscript(x *ast.IndexExpression, left, index any) (any, error) {
+	switch container := left.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		key, ok := index.(string)
+		if !ok {
+			return nil, errorAt(x.Token, fmt.Sprintf("dict keys are strings, got %s", typeName(index)))
+		}
+		return container[key], nil
+	case []any, string:
+		n, ok := index.(int64)
+		if !ok {
+			return nil, errorAt(x.Token, fmt.Sprintf("%s indexes are integers, got %s", typeName(left), typeName(index)))
+		}
+		items, _ := iterate(container)
+		if n < 0 {
+			n += int64(len(items))
+		}
+		if n < 0 || n >= int64(len(items)) {
+			return nil, nil
+		}
+		return items[n], nil
+	}
+	return nil, errorAt(x.Token, fmt.Sprintf("cannot index %s", typeName(left)))
+}
+
+// callMacro renders a macro body in a scope that binds its parameters; missing arguments are null.
+func (e *evaluator) callMacro(name *ast.Identifier, m *macro, args []any) (any, error) {
+	if len(args) > len(m.stmt.Parameters) {
+		return nil, errorAt(name.Token, fmt.Sprintf("macro %s takes %d argument(s), got %d", name.Value, len(m.stmt.Parameters), len(args)))
+	}
+	if e.macros >= maxMacroDepth {
+		return nil, errorAt(name.Token, fmt.Sprintf("macro call depth exceeded calling %s", name.Value))
+	}
+	e.macros++
+	defer func() { e.macros-- }()
+	outerScope, outerOut := e.scope, e.out
+	defer func() { e.scope, e.out = outerScope, outerOut }()
+
+	e.scope = newScope(m.scope)
+	e.out = &strings.Builder{}
+	for i, param := range m.stmt.Parameters {
+		var value any
+		if i < len(args) {
+			value = args[i]
+		}
+		e.scope.vars[param.Value] = value
+	}
+	if err := e.block(m.stmt.Body); err != nil {
+		return nil, err
+	}
+	return e.out.String(), nil
+}
+
+// include renders another skill in place through the loader.
+func (e *evaluator) include(stmt *ast.IncludeStatement) error {
+	value, err := e.expression(stmt.Skill)
+	if err != nil {
+		return err
+	}
+	skill, ok := value.(string)
+	if !ok || skill == "" {
+		return errorAt(stmt.Token, fmt.Sprintf("include expects a skill name, got %s", typeName(value)))
+	}
+	if e.load == nil {
+		return errorAt(stmt.Token, fmt.Sprintf("cannot include %s: no skill loader", skill))
+	}
+	if len(e.includes) >= maxIncludeDepth {
+		return errorAt(stmt.Token, fmt.Sprintf("include depth exceeded: %s", strings.Join(append(e.includes, skill), " -> ")))
+	}
+
+	source, err := e.load(skill)
+	if err != nil {
+		return errorAt(stmt.Token, fmt.Sprintf("include %s: %v", skill, err))
+	}
+	p := parser.New(lexer.NewTemplate(source))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return errorAt(stmt.Token, fmt.Sprintf("include %s: parse: %s", skill, strings.Join(errs, "; ")))
+	}
+
+	e.includes = append(e.includes, skill)
+	defer func() { e.includes = e.includes[:len(e.includes)-1] }()
+	for _, included := range program.Statements {
+		if err := e.statement(included); err != nil {
+			return errorAt(stmt.Token, fmt.Sprintf("in %s: %v", skill, err))
+		}
+	}
+	return nil
+}
+
+func prefix(x *ast.PrefixExpression, right any) (any, error) {
+	switch x.Operator {
+	case "!", "not":
+		return !Truthy(right), nil
+	case "-":
+		switch v := right.(type) {
+		case int64:
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+		return nil, errorAt(x.Token, fmt.Sprintf("cannot negate %s", typeName(right)))
+	}
+	return nil, errorAt(x.Token, fmt.Sprintf("unknown operator %s", x.Operator))
+}
+
+func infix(x *ast.InfixExpression, left, right any) (any, error) {
+	switch x.Operator {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", ">", "<=", ">=":
+		cmp, ok := compare(left, right)
+		if !ok {
+			return nil, errorAt(x.Token, fmt.Sprintf("cannot compare %s and %s", typeName(left), typeName(right)))
+		}
+		switch x.Operator {
+		case "<":
+			return cmp < 0, nil
+		case ">":
+			return cmp > 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	case "+":
+		if ls, ok := left.(string); ok {
+			return ls + Format(right), nil
+		}
+		if rs, ok := right.(string); ok {
+			return Format(left) + rs, nil
+		}
+		if ll, ok := left.([]any); ok {
+			if rl, ok := right.([]any); ok {
+				return append(append([]any{}, ll...), rl...), nil
+			}
+		}
+	}
+
+	li, lInt := left.(int64)
+	ri, rInt := right.(int64)
+	if lInt && rInt {
+		switch x.Operator {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "/":
+			if ri == 0 {
+				return nil, errorAt(x.Token, "division by zero")
+			}
+			if li%ri == 0 {
+				return li / ri, nil
+			}
+			return float64(li) / float64(ri), nil
+		}
+	}
+	lf, lNum := toFloat(left)
+	rf, rNum := toFloat(right)
+	if lNum && rNum {
+		switch x.Operator {
+		case "+":
+			return lf + rf, nil
+		case "-":
+			return lf - rf, nil
+		case "*":
+			return lf * rf, nil
+		case "/":
+			if rf == 0 {
+				return nil, errorAt(x.Token, "division by zero")
+			}
+			return lf / rf, nil
+		}
+	}
+	return nil, errorAt(x.Token, fmt.Sprintf("unsupported operands for %s: %s and %s", x.Operator, typeName(left), typeName(right)))
+}
+
+// Truthy reports whether a value counts as true in a condition: null, false, zero, and empty
+// strings, lists, and maps are false.
+func Truthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	}
+	return true
+}
+
+// Format renders a value the way an output tag prints it.
+func Format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func equal(left, right any) bool {
+	if lf, ok := toFloat(left); ok {
+		if rf, ok := toFloat(right); ok {
+			return lf == rf
+		}
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func compare(left, right any) (int, bool) {
+	if lf, ok := toFloat(left); ok {
+		if rf, ok := toFloat(right); ok {
+			switch {
+			case lf < rf:
+				return -1, true
+			case lf > rf:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if lok && rok {
+		return strings.Compare(ls, rs), true
+	}
+	return 0, false
+}
+
+func iterate(value any) ([]any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		return v, nil
+	case map[string]any:
+		keys := make([]any, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			keys = append(keys, key)
+		}
+		return keys, nil
+	case string:
+		items := make([]any, 0, len(v))
+		for _, r := range v {
+			items = append(items, string(r))
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(value))
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// normalize converts input values into the evaluator's value set: int64, float64, string, bool,
+// nil, []any, and map[string]any.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string, int64:
+		return v
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = normalize(item)
+		}
+		return out
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32:
+		return normalize(rv.Float())
+	case reflect.Slice, reflect.Array:
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = normalize(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = normalize(iter.Value().Interface())
+		}
+		return out
+	}
+	return fmt.Sprint(value)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "dict"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func errorAt(tok token.Token, message string) *Error {
+	return &Error{Line: tok.Line, Column: tok.Column, Message: message}
+}
@@ -0,0 +1,171 @@
+package evaluator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/nsl/builtin"
+)
+
+func TestRenderString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		vars     map[string]any
+		expected string
+	}{
+		{name: "text", input: "Hello there.\n", expected: "Hello there.\n"},
+		{name: "output", input: "Hi {{ user.name | upper }}!", vars: map[string]any{"user": map[string]any{"name": "ada"}}, expected: "Hi ADA!"},
+		{name: "undefined", input: "[{{ missing }}]", expected: "[]"},
+		{name: "comment", input: "a{# note #}b", expected: "ab"},
+		{name: "set", input: "{% set total = price * 2 + 1 %}{{ total }}", vars: map[string]any{"price": 20}, expected: "41"},
+		{name: "division", input: "{{ 7 / 2 }} {{ 8 / 2 }}", expected: "3.5 4"},
+		{name: "concat", input: `{{ "n=" + 3 }}`, expected: "n=3"},
+		{
+			name:     "if_elif_else",
+			input:    "{% if score > 90 %}A{% elif score > 70 %}B{% else %}C{% endif %}",
+			vars:     map[string]any{"score": 75.0},
+			expected: "B",
+		},
+		{name: "not", input: "{% if !items %}empty{% endif %}", vars: map[string]any{"items": []string{}}, expected: "empty"},
+		{
+			name:     "for_loop",
+			input:    "{% for item in items %}{{ loop.index }}.{{ item }}{% if !loop.last %}, {% endif %}{% endfor %}",
+			vars:     map[string]any{"items": []string{"x", "y", "z"}},
+			expected: "1.x, 2.y, 3.z",
+		},
+		{name: "for_scope", input: "{% for i in items %}{% set last = i %}{% endfor %}[{{ last }}]", vars: map[string]any{"items": []int{1, 2}}, expected: "[]"},
+		{name: "map_keys", input: "{% for k in m %}{{ k }}{% endfor %}", vars: map[string]any{"m": map[string]int{"b": 1, "a": 2}}, expected: "ab"},
+		{
+			name:     "subscripts",
+			input:    `{{ items[0] }}{{ items[-1] }}[{{ items[5] }}]{{ user["name"] }}{{ user.tags[1] }}{{ "abc"[1] }}[{{ missing[0] }}]`,
+			vars:     map[string]any{"items": []string{"x", "y"}, "user": map[string]any{"name": "Ada", "tags": []string{"a", "b"}}},
+			expected: "xy[]Adabb[]",
+		},
+		{
+			name:     "filter_arguments",
+			input:    `{{ missing | default("n/a") }} {{ "" | default("-", true) }} {{ names | join(", ") }} {{ "a-b-c" | replace("-", "+", 1) }} {{ price | round(2) }} {{ price | round(0, "ceil") }} {{ "xxhixx" | trim("x") }}`,
+			vars:     map[string]any{"names": []string{"a", "b"}, "price": 2.567},
+			expected: "n/a - a, b a+b-c 2.57 3 hi",
+		},
+		{name: "truncate", input: `{{ "Hello brave new world" | truncate(16, false, "...", 0) }}|{{ "Hello brave new world" | truncate(10, true, "!", 0) }}|{{ "short" | truncate(3) }}`, expected: "Hello brave...|Hello bra!|short"},
+		{name: "float", input: "{{ 1.5 * 2 }} {{ 0.1 + 0.2 > 0.3 }} {{ 2.50 }}", expected: "3 true 2.5"},
+		{
+			name:     "collections",
+			input:    `{% set d = {"name": "Ada", "tags": ["x", n]} %}{{ d.name }} {{ d["tags"][1] }} {{ [3, 1, 2] | sort | join(",") }} {{ {"b": 1, "a": [true]} }}`,
+			vars:     map[string]any{"n": 7},
+			expected: `Ada 7 1,2,3 {"a":[true],"b":1}`,
+		},
+		{
+			name:     "logical",
+			input:    `{% if a and not b or c %}yes{% endif %}|{{ name or "guest" }}|{{ a and "on" }}|{{ missing and missing.x }}|{{ not a == 1 }}`,
+			vars:     map[string]any{"a": 1, "b": false, "c": false, "name": ""},
+			expected: "yes|guest|on||false",
+		},
+		{name: "comparisons", input: `{{ 3 >= 3 }} {{ 2 <= 1 }} {{ 0 < x <= 10 }} {{ 0 < y <= 10 }} {{ 1 == 1 == 1 }}`, vars: map[string]any{"x": 10, "y": 11}, expected: "true false true false true"},
+		{
+			name:     "macro",
+			input:    `{% set greeting = "Hi" %}{% macro greet(name, end) %}{{ greeting }} {{ name }}{{ end }}{% endmacro %}{{ greet("Ada", "!") }} {{ greet("Bob") | upper }}`,
+			expected: "Hi Ada! HI BOB",
+		},
+		{name: "range", input: "{% for i in range(1, 7, 2) %}{{ i }}{% endfor %} {{ range(3) | length }}", expected: "135 3"},
+		{name: "filters", input: "{{ names | sort | join }} {{ names | length }} {{ ' hi ' | trim | title }}", vars: map[string]any{"names": []string{"c", "a", "b"}}, expected: "abc 3 Hi"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := RenderString(tt.input, tt.vars)
+			if err != nil {
+				t.Fatalf("RenderString: %v", err)
+			}
+			if got != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderStringErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "division_by_zero", input: "line one\n{{ 1 / 0 }}", want: "line 2:6: division by zero"},
+		{name: "unknown_filter", input: "{{ x | shout }}", want: `unknown filter "shout"`},
+		{name: "compare", input: `{% if "a" > 1 %}{% endif %}`, want: "cannot compare string and integer"},
+		{name: "unknown_function", input: "{{ SendMessage(x) }}", want: `line 1:4: unknown function "SendMessage"`},
+		{name: "bad_arguments", input: "{{ range('a') }}", want: "range(): expected integer arguments"},
+		{name: "dict_index", input: "{% set m = dict() %}{{ m[1] }}", want: "dict keys are strings, got integer"},
+		{name: "index_number", input: "{{ 5[0] }}", want: "cannot index integer"},
+		{name: "filter_arity", input: `{{ x | replace("a") }}`, want: "filter replace takes 2 to 3 arguments, got 1"},
+		{name: "unsupported_argument", input: `{{ x | sort(true) }}`, want: "arguments are not supported when rendering locally"},
+		{name: "dict_key", input: "{{ {1: 2} }}", want: "line 1:4: dict keys must be strings, got integer"},
+		{name: "macro_arity", input: "{% macro m(a) %}{% endmacro %}{{ m(1, 2) }}", want: "macro m takes 1 argument(s), got 2"},
+		{name: "macro_recursion", input: "{% macro m() %}{{ m() }}{% endmacro %}{{ m() }}", want: "line 1:19: macro call depth exceeded calling m"},
+		{name: "include_without_loader", input: `{% include "Footer" %}`, want: "cannot include Footer: no skill loader"},
+		{name: "keyword_argument", input: `{{ range(stop=3) }}`, want: "keyword argument stop= is not supported when rendering locally"},
+		{name: "parse", input: "{% if x %}", want: "parse:"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := RenderString(tt.input, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFiltersAreRegistered(t *testing.T) {
+	t.Parallel()
+
+	for name := range filters {
+		if _, ok := builtin.LookupFilter(name); !ok {
+			t.Errorf("filter %s is missing from the builtin registry", name)
+		}
+	}
+}
+
+func TestRenderIncludes(t *testing.T) {
+	t.Parallel()
+
+	skills := map[string]string{
+		"Main":   `{% macro bold(s) %}*{{ s }}*{% endmacro %}Hello {% include "Footer" %}`,
+		"Footer": `{{ bold(name) }}{% include "Sign" %}`,
+		"Sign":   ` -- {{ team | default("the team") }}`,
+		"Loop":   `{% include "Loop" %}`,
+	}
+	load := func(skill string) (string, error) {
+		source, ok := skills[skill]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return source, nil
+	}
+
+	got, err := RenderStringWithLoader(skills["Main"], map[string]any{"name": "Ada"}, load)
+	if err != nil {
+		t.Fatalf("RenderStringWithLoader: %v", err)
+	}
+	if got != "Hello *Ada* -- the team" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	if _, err := RenderStringWithLoader(skills["Loop"], nil, load); err == nil || !strings.Contains(err.Error(), "include depth exceeded") {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+	if _, err := RenderStringWithLoader(`{% include "Missing" %}`, nil, load); err == nil || !strings.Contains(err.Error(), "include Missing: not found") {
+		t.Fatalf("expected missing include error, got %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type filterFunc func(value any, args []any) (any, error)
+
+// filters implements the built-in NSL filters the local renderer supports. Argument counts are checked
+// against the builtin registry before a filter runs.
+var filters = map[string]filterFunc{
+	"abs":        unary(absFilter),
+	"capitalize": unary(stringFilter(capitalize)),
+	"count":      unary(lengthFilter),
+	"d":          defaultFilter,
+	"default":    defaultFilter,
+	"first":      unary(firstFilter),
+	"float":      unary(floatFilter),
+	"int":        unary(intFilter),
+	"join":       joinFilter,
+	"last":       unary(lastFilter),
+	"length":     unary(lengthFilter),
+	"list":       unary(listFilter),
+	"lower":      unary(stringFilter(strings.ToLower)),
+	"replace":    replaceFilter,
+	"reverse":    unary(reverseFilter),
+	"round":      roundFilter,
+	"safe":       unary(identityFilter),
+	"sort":       unary(sortFilter),
+	"string":     unary(func(value any) (any, error) { return Format(value), nil }),
+	"title":      unary(stringFilter(title)),
+	"tojson":     unary(tojsonFilter),
+	"trim":       trimFilter,
+	"truncate":   truncateFilter,
+	"unique":     unary(uniqueFilter),
+	"upper":      unary(stringFilter(strings.ToUpper)),
+	"wordcount":  unary(func(value any) (any, error) { return int64(len(strings.Fields(Format(value)))), nil }),
+}
+
+// unary adapts a filter whose optional arguments the local renderer does not implement.
+func unary(fn func(value any) (any, error)) filterFunc {
+	return func(value any, args []any) (any, error) {
+		if len(args) > 0 {
+			return nil, errors.New("arguments are not supported when rendering locally")
+		}
+		return fn(value)
+	}
+}
+
+func stringFilter(fn func(string) string) func(any) (any, error) {
+	return func(value any) (any, error) {
+		return fn(Format(value)), nil
+	}
+}
+
+func identityFilter(value any) (any, error) {
+	return value, nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	lower := []rune(strings.ToLower(s))
+	lower[0] = unicode.ToUpper(lower[0])
+	return string(lower)
+}
+
+func title(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start {
+				runes[i] = unicode.ToUpper(r)
+			} else {
+				runes[i] = unicode.ToLower(r)
+			}
+			start = false
+			continue
+		}
+		start = true
+	}
+	return string(runes)
+}
+
+func absFilter(value any) (any, error) {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return -v, nil
+		}
+		return v, nil
+	case float64:
+		return math.Abs(v), nil
+	}
+	return nil, fmt.Errorf("expected a number, got %s", typeName(value))
+}
+
+func lengthFilter(value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return int64(0), nil
+	case string:
+		return int64(len([]rune(v))), nil
+	case []any:
+		return int64(len(v)), nil
+	case map[string]any:
+		return int64(len(v)), nil
+	}
+	return nil, fmt.Errorf("%s has no length", typeName(value))
+}
+
+func firstFilter(value any) (any, error) {
+	items, err := iterate(value)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func lastFilter(value any) (any, error) {
+	items, err := iterate(value)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[len(items)-1], nil
+}
+
+func listFilter(value any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []any{}
+	}
+	return items, nil
+}
+
+func joinFilter(value any, args []any) (any, error) {
+	if len(args) > 1 {
+		return nil, errors.New("the attribute argument is not supported when rendering locally")
+	}
+	separator := ""
+	if len(args) == 1 {
+		separator = Format(args[0])
+	}
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = Format(item)
+	}
+	return strings.Join(parts, separator), nil
+}
+
+func reverseFilter(value any) (any, error) {
+	if s, ok := value.(string); ok {
+		runes := []rune(s)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	}
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[len(items)-1-i] = item
+	}
+	return out, nil
+}
+
+func sortFilter(value any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]any{}, items...)
+	var cmpErr error
+	sort.SliceStable(out, func(i, j int) bool {
+		cmp, ok := compare(out[i], out[j])
+		if !ok && cmpErr == nil {
+			cmpErr = fmt.Errorf("cannot compare %s and %s", typeName(out[i]), typeName(out[j]))
+		}
+		return cmp < 0
+	})
+	if cmpErr != nil {
+		return nil, cmpErr
+	}
+	return out, nil
+}
+
+func uniqueFilter(value any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+	out := []any{}
+	for _, item := range items {
+		duplicate := false
+		for _, seen := range out {
+			if equal(seen, item) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func intFilter(value any) (any, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return int64(f), nil
+		}
+	}
+	return int64(0), nil
+}
+
+func floatFilter(value any) (any, error) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, nil
+		}
+	}
+	return float64(0), nil
+}
+
+func tojsonFilter(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// defaultFilter returns its argument when the value is undefined, or merely falsy when the second
+// argument is true.
+func defaultFilter(value any, args []any) (any, error) {
+	var fallback any = ""
+	if len(args) > 0 {
+		fallback = args[0]
+	}
+	if value == nil || (len(args) > 1 && Truthy(args[1]) && !Truthy(value)) {
+		return fallback, nil
+	}
+	return value, nil
+}
+
+func replaceFilter(value any, args []any) (any, error) {
+	count := int64(-1)
+	if len(args) > 2 {
+		n, ok := args[2].(int64)
+		if !ok {
+			return nil, fmt.Errorf("count must be an integer, got %s", typeName(args[2]))
+		}
+		count = n
+	}
+	return strings.Replace(Format(value), Format(args[0]), Format(args[1]), int(count)), nil
+}
+
+func roundFilter(value any, args []any) (any, error) {
+	number, ok := toFloat(value)
+	if !ok {
+		return nil, fmt.Errorf("expected a number, got %s", typeName(value))
+	}
+	precision := int64(0)
+	if len(args) > 0 {
+		if precision, ok = args[0].(int64); !ok {
+			return nil, fmt.Errorf("precision must be an integer, got %s", typeName(args[0]))
+		}
+	}
+	method := "common"
+	if len(args) > 1 {
+		method = Format(args[1])
+	}
+	scale := math.Pow(10, float64(precision))
+	switch method {
+	case "common":
+		return math.Round(number*scale) / scale, nil
+	case "ceil":
+		return math.Ceil(number*scale) / scale, nil
+	case "floor":
+		return math.Floor(number*scale) / scale, nil
+	}
+	return nil, fmt.Errorf("method must be common, ceil, or floor, got %q", method)
+}
+
+func trimFilter(value any, args []any) (any, error) {
+	if len(args) > 0 {
+		return strings.Trim(Format(value), Format(args[0])), nil
+	}
+	return strings.TrimSpace(Format(value)), nil
+}
+
+// truncateFilter follows Jinja: strings up to length+leeway characters are kept whole; longer ones are
+// cut to length including the end marker, at a word boundary unless killwords is set.
+func truncateFilter(value any, args []any) (any, error) {
+	length, killwords, end, leeway := int64(255), false, "...", int64(5)
+	if len(args) > 0 {
+		n, ok := args[0].(int64)
+		if !ok {
+			return nil, fmt.Errorf("length must be an integer, got %s", typeName(args[0]))
+		}
+		length = n
+	}
+	if len(args) > 1 {
+		killwords = Truthy(args[1])
+	}
+	if len(args) > 2 {
+		end = Format(args[2])
+	}
+	if len(args) > 3 {
+		n, ok := args[3].(int64)
+		if !ok {
+			return nil, fmt.Errorf("leeway must be an integer, got %s", typeName(args[3]))
+		}
+		leeway = n
+	}
+
+	runes := []rune(Format(value))
+	endLength := int64(len([]rune(end)))
+	if length < endLength {
+		return nil, fmt.Errorf("length %d is shorter than the end marker %q", length, end)
+	}
+	if int64(len(runes)) <= length+leeway {
+		return string(runes), nil
+	}
+	kept := string(runes[:length-endLength])
+	if !killwords {
+		if idx := strings.LastIndex(kept, " "); idx >= 0 {
+			kept = kept[:idx]
+		}
+	}
+	return kept + end, nil
+}
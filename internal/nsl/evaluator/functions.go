@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+)
+
+type function func(args []any) (any, error)
+
+// functions implements the global functions available to templates. Platform actions such as sending
+// messages are not available locally and fail as unknown functions.
+var functions = map[string]function{
+	"range": rangeFunction,
+	"dict":  dictFunction,
+}
+
+// maxRange bounds range() so that a typo cannot exhaust memory during a local render.
+const maxRange = 100000
+
+func rangeFunction(args []any) (any, error) {
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		n, ok := arg.(int64)
+		if !ok {
+			return nil, fmt.Errorf("expected integer arguments, got %s", typeName(arg))
+		}
+		bounds[i] = n
+	}
+
+	var start, stop, step int64 = 0, 0, 1
+	switch len(bounds) {
+	case 1:
+		stop = bounds[0]
+	case 2:
+		start, stop = bounds[0], bounds[1]
+	case 3:
+		start, stop, step = bounds[0], bounds[1], bounds[2]
+	default:
+		return nil, fmt.Errorf("expected 1 to 3 arguments, got %d", len(args))
+	}
+	if step == 0 {
+		return nil, errors.New("step must not be zero")
+	}
+
+	out := []any{}
+	for n := start; (step > 0 && n < stop) || (step < 0 && n > stop); n += step {
+		if len(out) == maxRange {
+			return nil, fmt.Errorf("range exceeds %d items", maxRange)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func dictFunction(args []any) (any, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected no arguments, got %d", len(args))
+	}
+	return map[string]any{}, nil
+}
@@ -1,6 +1,10 @@
 package lexer
 
-import "github.com/twinmind/newo-tool/internal/nsl/token"
+import (
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/nsl/token"
+)
 
 // Lexer transforms a string of source code into a stream of tokens.
 type Lexer struct {
@@ -10,15 +14,31 @@ type Lexer struct {
 	ch           byte // current char under examination
 	line         int  // current line number
 	column       int  // current column number
+	template     bool // emit raw text between tags as TEXT tokens
+	inTag        bool // inside a {{ }} or {% %} tag in template mode
+	dictDepth    int  // open dict literal braces inside the current tag
 }
 
 // New creates a new Lexer.
 func New(input string) *Lexer {
-	l := &Lexer{input: input, line: 1, column: 1}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
+// NewTemplate creates a Lexer that reads the input as a template: text outside of `{{ }}` and `{% %}`
+// tags is returned verbatim as TEXT tokens and `{# #}` comments are dropped.
+func NewTemplate(input string) *Lexer {
+	l := New(input)
+	l.template = true
+	return l
+}
+
+// Position returns the position of the next unread character, which is just past the last token returned.
+func (l *Lexer) Position() token.Position {
+	return token.Position{Line: l.line, Column: l.column}
+}
+
 // readChar gives us the next character and advances our position in the input string.
 func (l *Lexer) readChar() {
 	if l.readPosition >= len(l.input) {
@@ -35,6 +55,12 @@ func (l *Lexer) readChar() {
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
+	if l.template && !l.inTag {
+		if tok, ok := l.readText(); ok {
+			return tok
+		}
+	}
+
 	l.skipWhitespace()
 
 	// Store position before creating the token
@@ -89,26 +115,49 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.DOT, l.ch)
 	case '|':
 		tok = newToken(token.PIPE, l.ch)
+	case ',':
+		tok = newToken(token.COMMA, l.ch)
+	case '(':
+		tok = newToken(token.LPAREN, l.ch)
+	case ')':
+		tok = newToken(token.RPAREN, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
 	case '{':
-		if l.peekChar() == '{' {
+		if l.inTag && l.peekChar() != '{' && l.peekChar() != '%' {
+			tok = newToken(token.LCURLY, l.ch)
+			l.dictDepth++
+		} else if l.peekChar() == '{' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.LBRACE, Literal: literal}
+			l.inTag = true
 		} else if l.peekChar() == '%' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.LPERCENT, Literal: literal}
+			l.inTag = true
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
 	case '}':
-		if l.peekChar() == '}' {
+		if l.dictDepth > 0 {
+			// Inside a dict literal `}}` closes two dicts rather than the output tag.
+			tok = newToken(token.RCURLY, l.ch)
+			l.dictDepth--
+		} else if l.peekChar() == '}' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.RBRACE, Literal: literal}
+			l.inTag = false
+			l.dictDepth = 0
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
@@ -118,6 +167,8 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.RPERCENT, Literal: literal}
+			l.inTag = false
+			l.dictDepth = 0
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
@@ -135,8 +186,7 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Column = col
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			tok.Literal, tok.Type = l.readNumber()
 			tok.Line = line
 			tok.Column = col
 			return tok
@@ -151,6 +201,50 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
+// readText consumes raw text up to the next tag in template mode. Comments are skipped; the returned
+// flag is false when the lexer stands on a tag or at the end of the input.
+func (l *Lexer) readText() (token.Token, bool) {
+	line, col := l.line, l.column
+	var text strings.Builder
+	for l.ch != 0 {
+		if l.ch == '{' && (l.peekChar() == '{' || l.peekChar() == '%') {
+			break
+		}
+		if l.ch == '{' && l.peekChar() == '#' {
+			l.skipComment()
+			continue
+		}
+		text.WriteByte(l.ch)
+		l.advance()
+	}
+	if text.Len() == 0 {
+		return token.Token{}, false
+	}
+	return token.Token{Type: token.TEXT, Literal: text.String(), Line: line, Column: col}, true
+}
+
+// skipComment consumes a `{# ... #}` comment, or the rest of the input if it is not closed.
+func (l *Lexer) skipComment() {
+	l.advance()
+	l.advance()
+	for l.ch != 0 && !(l.ch == '#' && l.peekChar() == '}') {
+		l.advance()
+	}
+	if l.ch != 0 {
+		l.advance()
+		l.advance()
+	}
+}
+
+// advance reads the next character and keeps the line counter in step with newlines.
+func (l *Lexer) advance() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.readChar()
+}
+
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
 		if l.ch == '\n' {
@@ -169,12 +263,20 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer, or a float when the digits are followed by a dot and more digits.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	if l.ch != '.' || !isDigit(l.peekChar()) {
+		return l.input[position:l.position], token.INT
+	}
+	l.readChar()
+	for isDigit(l.ch) {
+		l.readChar()
+	}
+	return l.input[position:l.position], token.FLOAT
 }
 
 func (l *Lexer) readString(quote byte) string {
@@ -195,7 +297,7 @@ func (l *Lexer) peekChar() byte {
 }
 
 func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'z' || ch == '_'
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
 }
 
 func isDigit(ch byte) bool {
@@ -94,3 +94,83 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenTemplate(t *testing.T) {
+	input := "Hello {{ name }}!\n{# note #}{% if vip %}\nWelcome back.{% endif %}"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{token.TEXT, "Hello ", 1},
+		{token.LBRACE, "{{", 1},
+		{token.IDENT, "name", 1},
+		{token.RBRACE, "}}", 1},
+		{token.TEXT, "!\n", 1},
+		{token.LPERCENT, "{%", 2},
+		{token.IF, "if", 2},
+		{token.IDENT, "vip", 2},
+		{token.RPERCENT, "%}", 2},
+		{token.TEXT, "\nWelcome back.", 2},
+		{token.LPERCENT, "{%", 3},
+		{token.ENDIF, "endif", 3},
+		{token.RPERCENT, "%}", 3},
+		{token.EOF, "", 3},
+	}
+
+	l := NewTemplate(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine {
+			t.Fatalf("tests[%d] - expected line %d, got %d", i, tt.expectedLine, tok.Line)
+		}
+	}
+}
+
+func TestNextTokenLiterals(t *testing.T) {
+	input := `{{ {"a": [1, 2.5]}}} {% set d = {"b": {"c": 0}} %}`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LBRACE, "{{"},
+		{token.LCURLY, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.FLOAT, "2.5"},
+		{token.RBRACKET, "]"},
+		{token.RCURLY, "}"},
+		{token.RBRACE, "}}"},
+		{token.LPERCENT, "{%"},
+		{token.SET, "set"},
+		{token.IDENT, "d"},
+		{token.ASSIGN, "="},
+		{token.LCURLY, "{"},
+		{token.STRING, "b"},
+		{token.COLON, ":"},
+		{token.LCURLY, "{"},
+		{token.STRING, "c"},
+		{token.COLON, ":"},
+		{token.INT, "0"},
+		{token.RCURLY, "}"},
+		{token.RCURLY, "}"},
+		{token.RPERCENT, "%}"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
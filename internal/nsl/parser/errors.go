@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/nsl/token"
+)
+
+// Error is a syntax error at a position in the source.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Context returns the source line holding the error, without its indentation, and a caret under the
+// error column on the line below:
+//
+//	{% if x y %}
+//	        ^
+//
+// It returns an empty string when the position lies outside source.
+func (e *Error) Context(source string) string {
+	lines := strings.Split(source, "\n")
+	if e.Line < 1 || e.Line > len(lines) {
+		return ""
+	}
+	line := strings.TrimRight(lines[e.Line-1], "\r")
+	text := strings.TrimLeft(line, " \t")
+	offset := e.Column - 1 - (len(line) - len(text))
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(text) {
+		offset = len(text)
+	}
+
+	// Tabs are kept in the padding so the caret lines up however the source line is displayed.
+	var caret strings.Builder
+	for i := 0; i < offset; i++ {
+		if text[i] == '\t' {
+			caret.WriteByte('\t')
+		} else {
+			caret.WriteByte(' ')
+		}
+	}
+	caret.WriteByte('^')
+	return text + "\n" + caret.String()
+}
+
+// errorAt records a syntax error at the position of tok.
+func (p *Parser) errorAt(tok token.Token, format string, args ...interface{}) {
+	p.errors = append(p.errors, &Error{Line: tok.Line, Column: tok.Column, Message: fmt.Sprintf(format, args...)})
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/twinmind/newo-tool/internal/nsl/ast"
@@ -12,13 +11,16 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	OR
+	AND
+	NOT
 	EQUALS
 	LESSGREATER
 	SUM
 	PRODUCT
 	FILTER
-	ATTRIBUTE
 	PREFIX
+	ATTRIBUTE
 	CALL
 	INDEX
 )
@@ -26,14 +28,20 @@ const (
 var precedences = map[token.TokenType]int{
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
+	token.OR:       OR,
+	token.AND:      AND,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
+	token.LTE:      LESSGREATER,
+	token.GTE:      LESSGREATER,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
 	token.PIPE:     FILTER,
 	token.DOT:      ATTRIBUTE,
+	token.LPAREN:   CALL,
+	token.LBRACKET: INDEX,
 }
 
 type (
@@ -43,17 +51,19 @@ type (
 
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors []*Error
 
 	curToken  token.Token
 	peekToken token.Token
+	curEnd    token.Position // just past curToken
+	peekEnd   token.Position // just past peekToken
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
 
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
@@ -62,7 +72,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.NOT, p.parseNotExpression)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.LBRACKET, p.parseListLiteral)
+	p.registerPrefix(token.LCURLY, p.parseDictLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -73,8 +87,14 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LTE, p.parseInfixExpression)
+	p.registerInfix(token.GTE, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
 	p.registerInfix(token.DOT, p.parseAttributeAccess)
 	p.registerInfix(token.PIPE, p.parseFilterExpression)
+	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	p.nextToken()
 	p.nextToken()
@@ -82,13 +102,34 @@ func New(l *lexer.Lexer) *Parser {
 	return p
 }
 
+// Errors returns the syntax errors as "line L:C: message" strings.
 func (p *Parser) Errors() []string {
+	messages := make([]string, 0, len(p.errors))
+	for _, err := range p.errors {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+// SyntaxErrors returns the syntax errors with their positions, for callers that show source context.
+func (p *Parser) SyntaxErrors() []*Error {
 	return p.errors
 }
 
 func (p *Parser) nextToken() {
-	p.curToken = p.peekToken
+	p.curToken, p.curEnd = p.peekToken, p.peekEnd
 	p.peekToken = p.l.NextToken()
+	p.peekEnd = p.l.Position()
+}
+
+// spanned is implemented by every AST node through its embedded ast.Span.
+type spanned interface {
+	SetRange(start, end token.Position)
+}
+
+// finish sets the span of a completed node, from start to the end of the current token.
+func (p *Parser) finish(node spanned, start token.Position) {
+	node.SetRange(start, p.curEnd)
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -105,18 +146,28 @@ func (p *Parser) ParseProgram() *ast.Program {
 		p.nextToken()
 	}
 
+	program.SetRange(token.Position{Line: 1, Column: 1}, p.curToken.Pos())
 	return program
 }
 
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.curToken.Pos()
+	var stmt ast.Statement
 	switch p.curToken.Type {
 	case token.LPERCENT:
-		return p.parseTemplateStatement()
+		stmt = p.parseTemplateStatement()
 	case token.LBRACE:
-		return p.parseOutputStatement()
+		stmt = p.parseOutputStatement()
+	case token.TEXT:
+		stmt = &ast.TextStatement{Token: p.curToken, Value: p.curToken.Literal}
 	default:
-		return p.parseExpressionStatement()
+		stmt = p.parseExpressionStatement()
+	}
+	if stmt == nil {
+		return nil
 	}
+	p.finish(stmt.(spanned), start)
+	return stmt
 }
 
 func (p *Parser) parseTemplateStatement() ast.Statement {
@@ -130,21 +181,26 @@ func (p *Parser) parseTemplateStatement() ast.Statement {
 	case token.FOR:
 		p.nextToken()
 		return p.parseForStatement()
+	case token.MACRO:
+		p.nextToken()
+		return p.parseMacroStatement()
+	case token.INCLUDE:
+		p.nextToken()
+		return p.parseIncludeStatement()
 	default:
-		msg := fmt.Sprintf("unexpected template tag %q", p.peekToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.peekToken, "unexpected template tag %q", p.peekToken.Literal)
 		return nil
 	}
 }
 
-func (p *Parser) parseSetStatement() *ast.SetStatement {
+func (p *Parser) parseSetStatement() ast.Statement {
 	stmt := &ast.SetStatement{Token: p.curToken}
 
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	stmt.Name = p.newIdentifier()
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
@@ -164,7 +220,7 @@ func (p *Parser) parseSetStatement() *ast.SetStatement {
 	return stmt
 }
 
-func (p *Parser) parseIfStatement() *ast.IfStatement {
+func (p *Parser) parseIfStatement() ast.Statement {
 	stmt := &ast.IfStatement{Token: p.curToken} // curToken is 'if'
 	stmt.ElseIfs = []*ast.ElseIfClause{}
 
@@ -185,6 +241,7 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 		switch p.peekToken.Type {
 		case token.ELIF:
 			clause := &ast.ElseIfClause{}
+			start := p.curToken.Pos()
 			p.nextToken() // move to ELIF
 			clause.Token = p.curToken
 
@@ -199,6 +256,7 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 			}
 
 			clause.Consequence = p.parseBlockStatement()
+			clause.SetRange(start, clause.Consequence.End)
 			stmt.ElseIfs = append(stmt.ElseIfs, clause)
 		case token.ELSE:
 			p.nextToken() // move to ELSE
@@ -229,13 +287,13 @@ endifCheck:
 	return stmt
 }
 
-func (p *Parser) parseForStatement() *ast.ForStatement {
+func (p *Parser) parseForStatement() ast.Statement {
 	stmt := &ast.ForStatement{Token: p.curToken} // curToken is 'for'
 
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
-	stmt.Iterator = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	stmt.Iterator = p.newIdentifier()
 
 	if !p.expectPeek(token.IN) {
 		return nil
@@ -267,10 +325,69 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	return stmt
 }
 
+func (p *Parser) parseMacroStatement() ast.Statement {
+	stmt := &ast.MacroStatement{Token: p.curToken, Parameters: []*ast.Identifier{}} // curToken is 'macro'
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = p.newIdentifier()
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	for !p.peekTokenIs(token.RPAREN) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Parameters = append(stmt.Parameters, p.newIdentifier())
+		if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken() // move to )
+
+	if !p.expectPeek(token.RPERCENT) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if !p.curTokenIs(token.LPERCENT) || !p.peekTokenIs(token.ENDMACRO) {
+		p.peekError(token.ENDMACRO)
+		return nil
+	}
+
+	p.nextToken() // move to ENDMACRO
+
+	if !p.expectPeek(token.RPERCENT) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseIncludeStatement() ast.Statement {
+	stmt := &ast.IncludeStatement{Token: p.curToken} // curToken is 'include'
+
+	p.nextToken()
+	stmt.Skill = p.parseExpression(LOWEST)
+	if stmt.Skill == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.RPERCENT) {
+		return nil
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{}
 	block.Token = p.curToken
 	block.Statements = []ast.Statement{}
+	start := p.curEnd // the body starts right after the opening tag
 
 	p.nextToken()
 
@@ -285,23 +402,24 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	}
 
 	if p.curTokenIs(token.EOF) {
-		p.addError("unexpected EOF while parsing block starting with %q", block.Token.Literal)
+		p.errorAt(block.Token, "unexpected EOF while parsing block starting with %q", block.Token.Literal)
 	}
 
+	block.SetRange(start, p.curToken.Pos())
 	return block
 }
 
 func (p *Parser) isBlockEnd() bool {
 	if p.curToken.Type == token.LPERCENT {
 		switch p.peekToken.Type {
-		case token.ELSE, token.ELIF, token.ENDIF, token.ENDFOR, token.ENDBLOCK:
+		case token.ELSE, token.ELIF, token.ENDIF, token.ENDFOR, token.ENDBLOCK, token.ENDMACRO:
 			return true
 		}
 	}
 	return false
 }
 
-func (p *Parser) parseOutputStatement() *ast.OutputStatement {
+func (p *Parser) parseOutputStatement() ast.Statement {
 	stmt := &ast.OutputStatement{Token: p.curToken}
 	p.nextToken() // Consume {{
 	stmt.Expression = p.parseExpression(LOWEST)
@@ -314,7 +432,7 @@ func (p *Parser) parseOutputStatement() *ast.OutputStatement {
 	return stmt
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 	if stmt.Expression == nil {
@@ -330,7 +448,12 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		p.noPrefixParseFnError(p.curToken.Type)
 		return nil
 	}
+	// Infix expressions start where their left operand does, so every node in the chain shares start.
+	start := p.curToken.Pos()
 	leftExp := prefix()
+	if leftExp != nil {
+		p.finish(leftExp.(spanned), start)
+	}
 
 	for !p.peekTokenIs(token.RPERCENT) && !p.peekTokenIs(token.RBRACE) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
@@ -339,27 +462,78 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		}
 		p.nextToken()
 		leftExp = infix(leftExp)
+		if leftExp != nil {
+			p.finish(leftExp.(spanned), start)
+		}
 	}
 
 	return leftExp
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return p.newIdentifier()
+}
+
+// newIdentifier builds an identifier from the current token, for names that are not parsed as expressions.
+func (p *Parser) newIdentifier() *ast.Identifier {
+	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.finish(ident, p.curToken.Pos())
+	return ident
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, "could not parse %q as integer", p.curToken.Literal)
 		return nil
 	}
 	lit.Value = value
 	return lit
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.errorAt(p.curToken, "could not parse %q as float", p.curToken.Literal)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseListLiteral() ast.Expression {
+	list := &ast.ListLiteral{Token: p.curToken}
+	list.Elements = p.parseExpressionList(token.RBRACKET)
+	if list.Elements == nil {
+		return nil
+	}
+	return list
+}
+
+func (p *Parser) parseDictLiteral() ast.Expression {
+	dict := &ast.DictLiteral{Token: p.curToken, Entries: []*ast.DictEntry{}}
+	for !p.peekTokenIs(token.RCURLY) {
+		p.nextToken()
+		entry := &ast.DictEntry{Key: p.parseExpression(LOWEST)}
+		if entry.Key == nil || !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		if entry.Value = p.parseExpression(LOWEST); entry.Value == nil {
+			return nil
+		}
+		entry.SetRange(entry.Key.Range().Start, p.curEnd)
+		dict.Entries = append(dict.Entries, entry)
+		if !p.peekTokenIs(token.RCURLY) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+	return dict
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curToken.Type == token.TRUE}
 }
@@ -378,6 +552,18 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	return expression
 }
 
+// parseNotExpression parses the `not` keyword, which unlike `!` binds looser than comparisons:
+// `not a == b` negates the whole comparison.
+func (p *Parser) parseNotExpression() ast.Expression {
+	expression := &ast.PrefixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+	}
+	p.nextToken()
+	expression.Right = p.parseExpression(NOT)
+	return expression
+}
+
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	expression := &ast.InfixExpression{
 		Token:    p.curToken,
@@ -400,7 +586,7 @@ func (p *Parser) parseAttributeAccess(left ast.Expression) ast.Expression {
 		return nil
 	}
 
-	expression.Attribute = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	expression.Attribute = p.newIdentifier()
 	return expression
 }
 
@@ -414,10 +600,82 @@ func (p *Parser) parseFilterExpression(left ast.Expression) ast.Expression {
 		return nil
 	}
 
-	expression.Filter = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	expression.Filter = p.newIdentifier()
+	if p.peekTokenIs(token.LPAREN) {
+		p.nextToken()
+		expression.Arguments = p.parseArguments()
+		if expression.Arguments == nil {
+			return nil
+		}
+	}
 	return expression
 }
 
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	expression := &ast.CallExpression{Token: p.curToken, Function: function}
+	expression.Arguments = p.parseArguments()
+	if expression.Arguments == nil {
+		return nil
+	}
+	return expression
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	expression := &ast.IndexExpression{Token: p.curToken, Left: left}
+	p.nextToken()
+	expression.Index = p.parseExpression(LOWEST)
+	if expression.Index == nil || !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return expression
+}
+
+// parseExpressionList parses comma-separated expressions up to the end token. It returns nil on a
+// syntax error and an empty, non-nil slice for an empty list.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	return p.parseList(end, func() ast.Expression { return p.parseExpression(LOWEST) })
+}
+
+// parseArguments parses the parenthesised arguments of a call or filter, which may be passed by name.
+func (p *Parser) parseArguments() []ast.Expression {
+	return p.parseList(token.RPAREN, p.parseArgument)
+}
+
+func (p *Parser) parseArgument() ast.Expression {
+	if !p.curTokenIs(token.IDENT) || !p.peekTokenIs(token.ASSIGN) {
+		return p.parseExpression(LOWEST)
+	}
+	start := p.curToken.Pos()
+	arg := &ast.KeywordArgument{Token: p.curToken, Name: p.newIdentifier()}
+	p.nextToken() // move to =
+	p.nextToken()
+	if arg.Value = p.parseExpression(LOWEST); arg.Value == nil {
+		return nil
+	}
+	p.finish(arg, start)
+	return arg
+}
+
+func (p *Parser) parseList(end token.TokenType, element func() ast.Expression) []ast.Expression {
+	list := []ast.Expression{}
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+
+	p.nextToken()
+	list = append(list, element())
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		list = append(list, element())
+	}
+	if !p.expectPeek(end) {
+		return nil
+	}
+	return list
+}
+
 func (p *Parser) expectPeek(t token.TokenType) bool {
 	if p.peekTokenIs(t) {
 		p.nextToken()
@@ -436,13 +694,11 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.peekToken, "expected next token to be %s, got %s instead", t, p.peekToken.Type)
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.curToken, "no prefix parse function for %s found", t)
 }
 
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
@@ -467,10 +723,6 @@ func (p *Parser) curPrecedence() int {
 	return LOWEST
 }
 
-func (p *Parser) addError(format string, args ...interface{}) {
-	p.errors = append(p.errors, fmt.Sprintf(format, args...))
-}
-
 func (p *Parser) synchronize() {
 	for !p.curTokenIs(token.EOF) {
 		switch p.curToken.Type {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/twinmind/newo-tool/internal/nsl/ast"
 	"github.com/twinmind/newo-tool/internal/nsl/lexer"
+	"github.com/twinmind/newo-tool/internal/nsl/token"
 )
 
 func TestTemplateStatements(t *testing.T) {
@@ -212,6 +213,23 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{input: `a + b * c + d / e - f`, expected: "(((a + (b * c)) + (d / e)) - f)"},
 		{input: `5 > 4 == 3 < 4`, expected: "((5 > 4) == (3 < 4))"},
 		{input: `5 < 4 != 3 > 4`, expected: "((5 < 4) != (3 > 4))"},
+		{input: `!loop.last`, expected: "(!loop.last)"},
+		{input: `a or b and c`, expected: "(a or (b and c))"},
+		{input: `not a == b and !c`, expected: "((not (a == b)) and (!c))"},
+		{input: `a >= 1 or b <= 2 + x`, expected: "((a >= 1) or (b <= (2 + x)))"},
+		{input: `a and not b or c`, expected: "((a and (not b)) or c)"},
+		{input: `0 < x <= 10`, expected: "((0 < x) <= 10)"},
+		{input: `-a.b * c`, expected: "((-a.b) * c)"},
+		{input: `a + add(b * c, d)`, expected: "(a + add((b * c), d))"},
+		{input: `GetUser().name`, expected: "GetUser().name"},
+		{input: `-items[0] + a.b["c"][i + 1]`, expected: "((-items[0]) + a.b[c][(i + 1)])"},
+		{input: `rows[0] | first`, expected: "rows[0] | first"},
+		{input: `name | default("n/a") | truncate(n + 1, true)`, expected: "name | default(n/a) | truncate((n + 1), true)"},
+		{input: `items | join() + x`, expected: "(items | join() + x)"},
+		{input: `[1, 2.5, [a]][0] * -1.5`, expected: "([1, 2.5, [a]][0] * (-1.5))"},
+		{input: `{{ {"a": x + 1, b: [], }["a"] }}`, expected: "{{{a: (x + 1), b: []}[a]}}"},
+		{input: `{{ {} }}`, expected: "{{{}}}"},
+		{input: `user.get("name") | upper`, expected: "user.get(name) | upper"},
 	}
 
 	for _, tt := range tests {
@@ -312,6 +330,129 @@ func TestAttributeAccessFilterChain(t *testing.T) {
 	requireIdentifierExpression(t, profile.Object, "user")
 }
 
+func TestCallExpression(t *testing.T) {
+	t.Parallel()
+
+	program := parseProgram(t, `{{ SendMessage(text, 1, range()) }}`)
+	statements := requireStatements(t, program, 1)
+
+	output := requireOutputStatement(t, statements[0])
+	call, ok := output.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expected *ast.CallExpression, got %T", output.Expression)
+	}
+	requireIdentifierExpression(t, call.Function, "SendMessage")
+	if len(call.Arguments) != 3 {
+		t.Fatalf("expected 3 arguments, got %d", len(call.Arguments))
+	}
+	requireIdentifierExpression(t, call.Arguments[0], "text")
+	requireIntegerLiteral(t, call.Arguments[1], 1)
+	if inner, ok := call.Arguments[2].(*ast.CallExpression); !ok || len(inner.Arguments) != 0 {
+		t.Fatalf("expected empty nested call, got %#v", call.Arguments[2])
+	}
+
+	program = parseProgram(t, `{{ SetState(name="step", value=x | default(1)) }}`)
+	call = requireOutputStatement(t, program.Statements[0]).Expression.(*ast.CallExpression)
+	if len(call.Arguments) != 2 || call.String() != `SetState(name=step, value=x | default(1))` {
+		t.Fatalf("unexpected keyword call: %s", call.String())
+	}
+	keyword, ok := call.Arguments[0].(*ast.KeywordArgument)
+	if !ok || keyword.Name.Value != "name" {
+		t.Fatalf("expected keyword argument name, got %#v", call.Arguments[0])
+	}
+	requireStringLiteral(t, keyword.Value, "step")
+
+	for _, broken := range []string{`{{ f(a, ) }}`, `{{ [a=1] }}`, `{{ f(a=) }}`} {
+		p := New(lexer.New(broken))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected errors for %q", broken)
+		}
+	}
+}
+
+func TestMacroAndIncludeStatements(t *testing.T) {
+	t.Parallel()
+
+	input := `{% macro greet(name, punctuation) %}{{ name }}{{ punctuation }}{% endmacro %}{% macro empty() %}{% endmacro %}{% include "Footer" %}`
+	program := parseProgram(t, input)
+	statements := requireStatements(t, program, 3)
+
+	macro, ok := statements[0].(*ast.MacroStatement)
+	if !ok {
+		t.Fatalf("expected *ast.MacroStatement, got %T", statements[0])
+	}
+	if macro.Name.Value != "greet" || len(macro.Parameters) != 2 || macro.Parameters[1].Value != "punctuation" {
+		t.Fatalf("unexpected macro signature: %s", macro.String())
+	}
+	if len(macro.Body.Statements) != 2 {
+		t.Fatalf("expected 2 body statements, got %d", len(macro.Body.Statements))
+	}
+	if empty, ok := statements[1].(*ast.MacroStatement); !ok || len(empty.Parameters) != 0 {
+		t.Fatalf("expected parameterless macro, got %#v", statements[1])
+	}
+	include, ok := statements[2].(*ast.IncludeStatement)
+	if !ok {
+		t.Fatalf("expected *ast.IncludeStatement, got %T", statements[2])
+	}
+	requireStringLiteral(t, include.Skill, "Footer")
+
+	for _, broken := range []string{`{% macro greet(name %}{% endmacro %}`, `{% macro greet() %}`, `{% include %}`} {
+		p := New(lexer.New(broken))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected errors for %q", broken)
+		}
+	}
+}
+
+func TestNodeSpans(t *testing.T) {
+	t.Parallel()
+
+	input := "Hi {{ user.name | upper }}\n{% if a and f(b, \"x\") %}\n  {{ items[0] }}\n{% endif %}"
+	p := New(lexer.NewTemplate(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	statements := requireStatements(t, program, 4)
+
+	pos := func(line, column int) token.Position { return token.Position{Line: line, Column: column} }
+	output := requireOutputStatement(t, statements[1])
+	ifStmt := requireIfStatement(t, statements[3])
+	condition := ifStmt.Condition.(*ast.InfixExpression)
+	call := condition.Right.(*ast.CallExpression)
+	index := ifStmt.Consequence.Statements[1].(*ast.OutputStatement).Expression.(*ast.IndexExpression)
+
+	tests := []struct {
+		name string
+		node interface{ Range() ast.Span }
+		want ast.Span
+	}{
+		{name: "text", node: statements[0], want: ast.Span{Start: pos(1, 1), End: pos(1, 4)}},
+		{name: "output", node: output, want: ast.Span{Start: pos(1, 4), End: pos(1, 27)}},
+		{name: "filter", node: output.Expression, want: ast.Span{Start: pos(1, 7), End: pos(1, 24)}},
+		{name: "attribute", node: output.Expression.(*ast.FilterExpression).Input, want: ast.Span{Start: pos(1, 7), End: pos(1, 16)}},
+		{name: "filter_name", node: output.Expression.(*ast.FilterExpression).Filter, want: ast.Span{Start: pos(1, 19), End: pos(1, 24)}},
+		{name: "if", node: ifStmt, want: ast.Span{Start: pos(2, 1), End: pos(4, 12)}},
+		{name: "condition", node: condition, want: ast.Span{Start: pos(2, 7), End: pos(2, 22)}},
+		{name: "call", node: call, want: ast.Span{Start: pos(2, 13), End: pos(2, 22)}},
+		{name: "string_argument", node: call.Arguments[1], want: ast.Span{Start: pos(2, 18), End: pos(2, 21)}},
+		{name: "body", node: ifStmt.Consequence, want: ast.Span{Start: pos(2, 25), End: pos(4, 1)}},
+		{name: "index", node: index, want: ast.Span{Start: pos(3, 6), End: pos(3, 14)}},
+		{name: "program", node: program, want: ast.Span{Start: pos(1, 1), End: pos(4, 12)}},
+	}
+	for _, tt := range tests {
+		if got := tt.node.Range(); got != tt.want {
+			t.Errorf("%s: expected span %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+
+	if !call.Range().Contains(pos(2, 21)) || call.Range().Contains(pos(2, 22)) {
+		t.Errorf("Contains does not treat the end as exclusive: %+v", call.Range())
+	}
+}
+
 func TestParserReportsErrors(t *testing.T) {
 	t.Parallel()
 
@@ -326,6 +467,45 @@ func TestParserReportsErrors(t *testing.T) {
 	}
 }
 
+func TestSyntaxErrorPositions(t *testing.T) {
+	t.Parallel()
+
+	input := "Hello\n\t{% if a b %}x{% endif %}\n{{ 1 + }}"
+	p := New(lexer.NewTemplate(input))
+	p.ParseProgram()
+	errs := p.SyntaxErrors()
+	if len(errs) < 2 {
+		t.Fatalf("expected at least two errors, got %v", p.Errors())
+	}
+
+	first := errs[0]
+	if first.Line != 2 || first.Column != 10 || first.Error() != "line 2:10: expected next token to be %}, got IDENT instead" {
+		t.Fatalf("unexpected first error: %+v", first)
+	}
+	if got := first.Context(input); got != "{% if a b %}x{% endif %}\n        ^" {
+		t.Fatalf("unexpected context:\n%s", got)
+	}
+	if p.Errors()[0] != first.Error() {
+		t.Fatalf("Errors and SyntaxErrors disagree: %v", p.Errors())
+	}
+
+	var missing *Error
+	for _, err := range errs {
+		if err.Message == "no prefix parse function for }} found" {
+			missing = err
+		}
+	}
+	if missing == nil || missing.Line != 3 || missing.Column != 8 {
+		t.Fatalf("expected the missing operand at 3:8, got %v", p.Errors())
+	}
+	if got := missing.Context(input); got != "{{ 1 + }}\n       ^" {
+		t.Fatalf("unexpected context:\n%s", got)
+	}
+	if got := (&Error{Line: 9, Column: 1, Message: "x"}).Context(input); got != "" {
+		t.Fatalf("expected no context outside the source, got %q", got)
+	}
+}
+
 func TestParserRecoveryAfterStatementError(t *testing.T) {
 	t.Parallel()
 
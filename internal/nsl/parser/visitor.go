@@ -52,6 +52,12 @@ func Walk(v Visitor, node ast.Node) {
 		if n.Body != nil {
 			Walk(v, n.Body)
 		}
+	case *ast.MacroStatement:
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+	case *ast.IncludeStatement:
+		Walk(v, n.Skill)
 	case *ast.OutputStatement:
 		if v != nil {
 			v.VisitOutput(n)
@@ -63,6 +69,8 @@ func Walk(v Visitor, node ast.Node) {
 		}
 	case *ast.ExpressionStatement:
 		Walk(v, n.Expression)
+	case *ast.TextStatement:
+		// Raw text has nothing to visit.
 	case *ast.AttributeAccess:
 		if v != nil {
 			v.VisitExpression(n)
@@ -73,6 +81,43 @@ func Walk(v Visitor, node ast.Node) {
 			v.VisitExpression(n)
 		}
 		Walk(v, n.Input)
+		for _, arg := range n.Arguments {
+			Walk(v, arg)
+		}
+	case *ast.CallExpression:
+		if v != nil {
+			v.VisitExpression(n)
+		}
+		Walk(v, n.Function)
+		for _, arg := range n.Arguments {
+			Walk(v, arg)
+		}
+	case *ast.KeywordArgument:
+		if v != nil {
+			v.VisitExpression(n)
+		}
+		Walk(v, n.Value)
+	case *ast.ListLiteral:
+		if v != nil {
+			v.VisitExpression(n)
+		}
+		for _, element := range n.Elements {
+			Walk(v, element)
+		}
+	case *ast.DictLiteral:
+		if v != nil {
+			v.VisitExpression(n)
+		}
+		for _, entry := range n.Entries {
+			Walk(v, entry.Key)
+			Walk(v, entry.Value)
+		}
+	case *ast.IndexExpression:
+		if v != nil {
+			v.VisitExpression(n)
+		}
+		Walk(v, n.Left)
+		Walk(v, n.Index)
 	case *ast.InfixExpression:
 		if v != nil {
 			v.VisitExpression(n)
@@ -84,7 +129,7 @@ func Walk(v Visitor, node ast.Node) {
 			v.VisitExpression(n)
 		}
 		Walk(v, n.Right)
-	case *ast.Identifier, *ast.IntegerLiteral, *ast.StringLiteral, *ast.Boolean:
+	case *ast.Identifier, *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean:
 		if v != nil {
 			v.VisitExpression(n.(ast.Expression))
 		}
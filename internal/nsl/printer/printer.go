@@ -3,6 +3,7 @@ package printer
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/twinmind/newo-tool/internal/nsl/ast"
@@ -13,6 +14,7 @@ type Printer struct {
 	buffer            *bytes.Buffer
 	indentationLevel  int
 	indentationString string
+	template          bool // keep the layout carried by text statements instead of reformatting
 }
 
 // New creates a new Printer with default settings.
@@ -24,6 +26,14 @@ func New() *Printer {
 	}
 }
 
+// NewTemplate creates a Printer for programs parsed in template mode. Tags are printed in canonical form
+// but no indentation or newlines are added, since the surrounding text statements carry the layout.
+func NewTemplate() *Printer {
+	p := New()
+	p.template = true
+	return p
+}
+
 // Print takes an AST program and returns its formatted string representation.
 func (p *Printer) Print(program *ast.Program) string {
 	p.printProgram(program)
@@ -52,12 +62,24 @@ func (p *Printer) printStatement(stmt ast.Statement) {
 	case *ast.ForStatement:
 		p.writeIndent()
 		p.printForStatement(s)
+	case *ast.MacroStatement:
+		p.writeIndent()
+		p.printMacroStatement(s)
+	case *ast.IncludeStatement:
+		p.writeIndent()
+		p.writeString("{% include ")
+		p.printExpression(s.Skill)
+		p.writeString(" %}")
 	case *ast.ExpressionStatement:
 		p.writeIndent()
 		p.printExpressionStatement(s)
 	case *ast.BlockStatement:
 		// BlockStatement handles its own indentation for its children
 		p.printBlockStatement(s)
+	case *ast.TextStatement:
+		// Template text already carries its own layout.
+		p.writeString(s.Value)
+		return
 	default:
 		p.writeIndent()
 		p.writeString(fmt.Sprintf("/* UNKNOWN STATEMENT: %T */", s))
@@ -125,6 +147,25 @@ func (p *Printer) printForStatement(stmt *ast.ForStatement) {
 	p.writeString("{% endfor %}")
 }
 
+func (p *Printer) printMacroStatement(stmt *ast.MacroStatement) {
+	p.writeString("{% macro ")
+	p.writeString(stmt.Name.Value)
+	p.writeString("(")
+	for i, param := range stmt.Parameters {
+		if i > 0 {
+			p.writeString(", ")
+		}
+		p.writeString(param.Value)
+	}
+	p.writeString(") %}")
+	p.writeNewline() // Newline after {% macro ... %}
+	p.indent()
+	p.printBlockStatement(stmt.Body)
+	p.dedent()
+	p.writeIndent()
+	p.writeString("{% endmacro %}")
+}
+
 func (p *Printer) printExpressionStatement(stmt *ast.ExpressionStatement) {
 	p.printExpression(stmt.Expression)
 }
@@ -141,6 +182,32 @@ func (p *Printer) printExpression(expr ast.Expression) {
 		p.writeString(e.Value)
 	case *ast.IntegerLiteral:
 		p.writeString(fmt.Sprintf("%d", e.Value))
+	case *ast.FloatLiteral:
+		literal := strconv.FormatFloat(e.Value, 'f', -1, 64)
+		if !strings.Contains(literal, ".") {
+			literal += ".0" // keep the value a float when it is parsed again
+		}
+		p.writeString(literal)
+	case *ast.ListLiteral:
+		p.writeString("[")
+		for i, element := range e.Elements {
+			if i > 0 {
+				p.writeString(", ")
+			}
+			p.printExpression(element)
+		}
+		p.writeString("]")
+	case *ast.DictLiteral:
+		p.writeString("{")
+		for i, entry := range e.Entries {
+			if i > 0 {
+				p.writeString(", ")
+			}
+			p.printExpression(entry.Key)
+			p.writeString(": ")
+			p.printExpression(entry.Value)
+		}
+		p.writeString("}")
 	case *ast.StringLiteral:
 		p.writeString(fmt.Sprintf("\"%s\"", e.Value)) // Use double quotes for consistency
 	case *ast.Boolean:
@@ -151,6 +218,9 @@ func (p *Printer) printExpression(expr ast.Expression) {
 		p.printExpression(e.Right)
 	case *ast.PrefixExpression:
 		p.writeString(e.Operator)
+		if e.Operator == "not" {
+			p.writeString(" ")
+		}
 		p.printExpression(e.Right)
 	case *ast.AttributeAccess:
 		p.printExpression(e.Object)
@@ -160,12 +230,40 @@ func (p *Printer) printExpression(expr ast.Expression) {
 		p.printExpression(e.Input)
 		p.writeString(" | ")
 		p.writeString(e.Filter.Value)
+		if e.Arguments != nil {
+			p.printArguments(e.Arguments)
+		}
+	case *ast.IndexExpression:
+		p.printExpression(e.Left)
+		p.writeString("[")
+		p.printExpression(e.Index)
+		p.writeString("]")
+	case *ast.CallExpression:
+		p.printExpression(e.Function)
+		p.printArguments(e.Arguments)
+	case *ast.KeywordArgument:
+		p.writeString(e.Name.Value + "=")
+		p.printExpression(e.Value)
 	default:
 		p.writeString(fmt.Sprintf("/* UNKNOWN EXPRESSION: %T */", e))
 	}
 }
 
+func (p *Printer) printArguments(args []ast.Expression) {
+	p.writeString("(")
+	for i, arg := range args {
+		if i > 0 {
+			p.writeString(", ")
+		}
+		p.printExpression(arg)
+	}
+	p.writeString(")")
+}
+
 func (p *Printer) indent() {
+	if p.template {
+		return
+	}
 	p.indentationLevel++
 }
 
@@ -177,6 +275,9 @@ func (p *Printer) dedent() {
 }
 
 func (p *Printer) writeIndent() {
+	if p.template {
+		return
+	}
 	p.buffer.WriteString(strings.Repeat(p.indentationString, p.indentationLevel))
 }
 
@@ -185,5 +286,8 @@ func (p *Printer) writeString(s string) {
 }
 
 func (p *Printer) writeNewline() {
+	if p.template {
+		return
+	}
 	p.buffer.WriteByte('\n')
 }
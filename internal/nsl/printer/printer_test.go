@@ -118,3 +118,47 @@ func parseInput(t *testing.T, input string) *ast.Program {
 	}
 	return program
 }
+
+func TestPrintTemplateKeepsLayout(t *testing.T) {
+	input := "Hi {{ user.name | upper }}!\n{% if !items %}none{% elif x > 1 %}many{% else %}one{% endif %}\n{% for i in items %}{% set last = i %}-{% endfor %}"
+
+	l := lexer.NewTemplate(input)
+	ps := parser.New(l)
+	program := ps.ParseProgram()
+	if len(ps.Errors()) != 0 {
+		t.Fatalf("parser errors: %s", strings.Join(ps.Errors(), "; "))
+	}
+	output := NewTemplate().Print(program)
+
+	if output != input {
+		t.Errorf("expected %q, got %q", input, output)
+	}
+}
+
+func TestPrintLiterals(t *testing.T) {
+	input := `{% set config = {"sizes": [1, 2.0, 0.25], 'label': GetState(name='label')} %}`
+	expected := `{% set config = {"sizes": [1, 2.0, 0.25], "label": GetState(name="label")} %}` + "\n"
+
+	program := parseInput(t, input)
+	output := New().Print(program)
+
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestPrintMacroAndInclude(t *testing.T) {
+	input := `{% macro greet(name, end) %}{{ name | title }}{{ end }}{% endmacro %}{% include "Footer" %}`
+	expected := `{% macro greet(name, end) %}
+    {{ name | title }}
+    {{ end }}
+{% endmacro %}
+{% include "Footer" %}` + "\n"
+
+	program := parseInput(t, input)
+	output := New().Print(program)
+
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
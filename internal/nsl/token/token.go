@@ -11,6 +11,17 @@ type Token struct {
 	Column  int
 }
 
+// Position is a 1-based line and column in the source.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Pos returns the position of the token's first character.
+func (t Token) Pos() Position {
+	return Position{Line: t.Line, Column: t.Column}
+}
+
 const (
 	// Special tokens
 	ILLEGAL = "ILLEGAL" // An unknown token
@@ -19,7 +30,9 @@ const (
 	// Identifiers & Literals
 	IDENT  = "IDENT"  // my_variable, user
 	INT    = "INT"    // 12345
+	FLOAT  = "FLOAT"  // 3.14
 	STRING = "STRING" // "hello world"
+	TEXT   = "TEXT"   // raw template text outside of tags
 
 	// Delimiters
 	LBRACE   = "{{" // Left brace for output
@@ -36,6 +49,14 @@ const (
 	SLASH    = "/"
 	DOT      = "."
 	PIPE     = "|"
+	COMMA    = ","
+	LPAREN   = "("
+	RPAREN   = ")"
+	LBRACKET = "["
+	RBRACKET = "]"
+	LCURLY   = "{" // Opens a dict literal inside a tag
+	RCURLY   = "}" // Closes a dict literal inside a tag
+	COLON    = ":"
 
 	LT = "<"
 	GT = ">"
@@ -57,8 +78,14 @@ const (
 	IN       = "IN"
 	ENDFOR   = "ENDFOR"
 	SET      = "SET"
+	AND      = "AND"
+	OR       = "OR"
+	NOT      = "NOT"
 	BLOCK    = "BLOCK"
 	ENDBLOCK = "ENDBLOCK"
+	MACRO    = "MACRO"
+	ENDMACRO = "ENDMACRO"
+	INCLUDE  = "INCLUDE"
 )
 
 var keywords = map[string]TokenType{
@@ -73,8 +100,14 @@ var keywords = map[string]TokenType{
 	"in":       IN,
 	"endfor":   ENDFOR,
 	"set":      SET,
+	"and":      AND,
+	"or":       OR,
+	"not":      NOT,
 	"block":    BLOCK,
 	"endblock": ENDBLOCK,
+	"macro":    MACRO,
+	"endmacro": ENDMACRO,
+	"include":  INCLUDE,
 }
 
 // LookupIdent checks the keywords table to see whether the given identifier is a keyword.
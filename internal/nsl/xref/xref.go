@@ -0,0 +1,349 @@
+// Package xref builds a cross-reference of the state fields, attributes, and set-variables that the NSL
+// skills of a project read and write.
+package xref
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/nsl/ast"
+	"github.com/twinmind/newo-tool/internal/nsl/lexer"
+	"github.com/twinmind/newo-tool/internal/nsl/parser"
+)
+
+// Symbol kinds.
+const (
+	KindStateField = "state"
+	KindAttribute  = "attribute"
+	KindVariable   = "variable"
+)
+
+// Access modes of a reference.
+const (
+	Read  = "read"
+	Write = "write"
+)
+
+// Reference is one place where a skill uses a symbol.
+type Reference struct {
+	Flow   string `json:"flow"`
+	Skill  string `json:"skill"`
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Access string `json:"access"`
+}
+
+// Symbol collects the references to one name. Declared lists the flows whose metadata declares a state
+// field; Problems explains why the symbol deserves a look, such as a state field nothing references.
+type Symbol struct {
+	Kind       string      `json:"kind"`
+	Name       string      `json:"name"`
+	Declared   []string    `json:"declared,omitempty"`
+	References []Reference `json:"references"`
+	Problems   []string    `json:"problems,omitempty"`
+}
+
+// Skipped is a skill that could not be parsed and is missing from the report.
+type Skipped struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Report is the cross-reference of a project, with symbols ordered by kind and name.
+type Report struct {
+	Symbols []*Symbol `json:"symbols"`
+	Skipped []Skipped `json:"skipped,omitempty"`
+}
+
+// accessor describes a platform function that reads or writes a named symbol. The name is the string
+// passed as the keyword argument or at the given position.
+type accessor struct {
+	kind     string
+	access   string
+	keyword  string
+	position int
+}
+
+var accessors = map[string]accessor{
+	"GetState":             {KindStateField, Read, "name", 0},
+	"SetState":             {KindStateField, Write, "name", 0},
+	"GetCustomerAttribute": {KindAttribute, Read, "field", 0},
+	"SetCustomerAttribute": {KindAttribute, Write, "field", 0},
+	"GetPersonaAttribute":  {KindAttribute, Read, "field", 1},
+	"SetPersonaAttribute":  {KindAttribute, Write, "field", 1},
+}
+
+// kindOrder sorts the report sections.
+var kindOrder = map[string]int{KindStateField: 0, KindAttribute: 1, KindVariable: 2}
+
+// Build parses every .nsl file under root. A skill's flow is the directory holding it, and the flow's
+// metadata.yaml supplies the declared state fields.
+func Build(root string) (*Report, error) {
+	b := &builder{root: root, symbols: map[string]*Symbol{}, declared: map[string]map[string]bool{}}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".nsl") {
+			return nil
+		}
+		return b.addSkill(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.report(), nil
+}
+
+type builder struct {
+	root     string
+	symbols  map[string]*Symbol
+	declared map[string]map[string]bool // flow directory -> declared state fields
+	skipped  []Skipped
+}
+
+func (b *builder) addSkill(path string) error {
+	flowDir := filepath.Dir(path)
+	flow := filepath.Base(flowDir)
+	declared, err := b.stateFields(flowDir)
+	if err != nil {
+		return err
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filepath.ToSlash(path), err)
+	}
+	rel := filepath.ToSlash(path)
+	if r, err := filepath.Rel(b.root, path); err == nil {
+		rel = filepath.ToSlash(r)
+	}
+	p := parser.New(lexer.NewTemplate(string(source)))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		b.skipped = append(b.skipped, Skipped{Path: rel, Error: errs[0]})
+		return nil
+	}
+
+	v := &skillVisitor{
+		location: Reference{Flow: flow, Skill: strings.TrimSuffix(filepath.Base(path), ".nsl"), Path: rel},
+		sets:     map[string]bool{},
+	}
+	parser.Walk(v, program)
+	for _, ref := range v.refs {
+		kind, name := ref.kind, ref.name
+		// Names assigned with set are local variables of the skill; other names are state fields when
+		// the flow declares them, and otherwise parameters or globals outside the report.
+		if kind == "" {
+			switch {
+			case v.sets[name]:
+				kind = KindVariable
+			case declared[name]:
+				kind = KindStateField
+			default:
+				continue
+			}
+		}
+		b.symbol(kind, name).References = append(b.symbol(kind, name).References, ref.Reference)
+	}
+	return nil
+}
+
+// stateFields reads and caches the state fields declared in a flow's metadata.yaml. The declarations are
+// registered as symbols so that fields nothing references still appear in the report.
+func (b *builder) stateFields(flowDir string) (map[string]bool, error) {
+	if fields, ok := b.declared[flowDir]; ok {
+		return fields, nil
+	}
+	fields := map[string]bool{}
+	b.declared[flowDir] = fields
+
+	data, err := os.ReadFile(filepath.Join(flowDir, fsutil.MetadataYAML))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fields, nil
+		}
+		return nil, err
+	}
+	var metadata struct {
+		StateFields []struct {
+			IDN string `yaml:"idn"`
+		} `yaml:"state_fields"`
+	}
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", filepath.ToSlash(filepath.Join(flowDir, fsutil.MetadataYAML)), err)
+	}
+	flow := filepath.Base(flowDir)
+	for _, field := range metadata.StateFields {
+		idn := strings.TrimSpace(field.IDN)
+		if idn == "" {
+			continue
+		}
+		fields[idn] = true
+		symbol := b.symbol(KindStateField, idn)
+		symbol.Declared = append(symbol.Declared, flow)
+	}
+	return fields, nil
+}
+
+func (b *builder) symbol(kind, name string) *Symbol {
+	key := kind + "\x00" + name
+	symbol, ok := b.symbols[key]
+	if !ok {
+		symbol = &Symbol{Kind: kind, Name: name, References: []Reference{}}
+		b.symbols[key] = symbol
+	}
+	return symbol
+}
+
+func (b *builder) report() *Report {
+	report := &Report{Symbols: make([]*Symbol, 0, len(b.symbols)), Skipped: b.skipped}
+	for _, symbol := range b.symbols {
+		sort.Strings(symbol.Declared)
+		sort.SliceStable(symbol.References, func(i, j int) bool {
+			a, c := symbol.References[i], symbol.References[j]
+			if a.Path != c.Path {
+				return a.Path < c.Path
+			}
+			if a.Line != c.Line {
+				return a.Line < c.Line
+			}
+			return a.Column < c.Column
+		})
+		symbol.Problems = problems(symbol)
+		report.Symbols = append(report.Symbols, symbol)
+	}
+	sort.Slice(report.Symbols, func(i, j int) bool {
+		a, c := report.Symbols[i], report.Symbols[j]
+		if a.Kind != c.Kind {
+			return kindOrder[a.Kind] < kindOrder[c.Kind]
+		}
+		return a.Name < c.Name
+	})
+	return report
+}
+
+// problems flags declared state fields nothing references, state fields used by flows that do not declare
+// them, and variables a skill sets but never reads.
+func problems(symbol *Symbol) []string {
+	var out []string
+	switch symbol.Kind {
+	case KindStateField:
+		if len(symbol.Declared) > 0 && len(symbol.References) == 0 {
+			out = append(out, "declared but never referenced")
+		}
+		undeclared := map[string]bool{}
+		for _, ref := range symbol.References {
+			if !contains(symbol.Declared, ref.Flow) && !undeclared[ref.Flow] {
+				undeclared[ref.Flow] = true
+				out = append(out, fmt.Sprintf("used in %s, which does not declare it", ref.Flow))
+			}
+		}
+	case KindVariable:
+		read := map[string]bool{}
+		for _, ref := range symbol.References {
+			if ref.Access == Read {
+				read[ref.Path] = true
+			}
+		}
+		unused := map[string]bool{}
+		for _, ref := range symbol.References {
+			if ref.Access == Write && !read[ref.Path] && !unused[ref.Path] {
+				unused[ref.Path] = true
+				out = append(out, fmt.Sprintf("set but never read in %s/%s", ref.Flow, ref.Skill))
+			}
+		}
+	}
+	return out
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// skillReference is a reference found in one skill. An empty kind marks a bare name whose kind depends
+// on the rest of the skill and its flow.
+type skillReference struct {
+	Reference
+	kind string
+	name string
+}
+
+// skillVisitor collects the references of one skill.
+type skillVisitor struct {
+	location Reference
+	sets     map[string]bool
+	refs     []skillReference
+}
+
+func (v *skillVisitor) add(kind, name, access string, node ast.Node) {
+	ref := v.location
+	ref.Access = access
+	span := node.Range()
+	ref.Line, ref.Column = span.Start.Line, span.Start.Column
+	v.refs = append(v.refs, skillReference{Reference: ref, kind: kind, name: name})
+}
+
+func (v *skillVisitor) VisitProgram(*ast.Program) {}
+
+func (v *skillVisitor) VisitSet(stmt *ast.SetStatement) {
+	if stmt.Name == nil {
+		return
+	}
+	v.sets[stmt.Name.Value] = true
+	v.add(KindVariable, stmt.Name.Value, Write, stmt.Name)
+}
+
+func (v *skillVisitor) VisitIf(*ast.IfStatement) {}
+
+func (v *skillVisitor) VisitFor(*ast.ForStatement) {}
+
+func (v *skillVisitor) VisitOutput(*ast.OutputStatement) {}
+
+func (v *skillVisitor) VisitExpression(expr ast.Expression) {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		v.add("", e.Value, Read, e)
+	case *ast.CallExpression:
+		name, ok := e.Function.(*ast.Identifier)
+		if !ok {
+			return
+		}
+		acc, ok := accessors[name.Value]
+		if !ok {
+			return
+		}
+		if arg := symbolArgument(e.Arguments, acc); arg != nil {
+			v.add(acc.kind, arg.Value, acc.access, arg)
+		}
+	}
+}
+
+// symbolArgument returns the literal symbol name passed to an accessor, or nil when it is computed.
+func symbolArgument(args []ast.Expression, acc accessor) *ast.StringLiteral {
+	var value ast.Expression
+	for i, arg := range args {
+		if keyword, ok := arg.(*ast.KeywordArgument); ok {
+			if keyword.Name.Value == acc.keyword {
+				value = keyword.Value
+			}
+		} else if i == acc.position {
+			value = arg
+		}
+	}
+	literal, _ := value.(*ast.StringLiteral)
+	return literal
+}
@@ -0,0 +1,75 @@
+package xref
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	main := filepath.Join(root, "agent", "flows", "MainFlow")
+	writeFile(t, filepath.Join(main, "metadata.yaml"), "state_fields:\n  - idn: step\n  - idn: legacy\n")
+	writeFile(t, filepath.Join(main, "Greet.nsl"), "{% set greeting = \"Hi\" %}{% set unused = 1 %}\n{{ greeting }} {{ step }} {{ GetCustomerAttribute(field=\"business_name\") }}\n")
+	writeFile(t, filepath.Join(main, "Advance.nsl"), "{{ SetState(name=\"step\", value=step + 1) }}{{ SetCustomerAttribute(\"business_name\", x) }}")
+	writeFile(t, filepath.Join(root, "agent", "flows", "Other", "Peek.nsl"), "{{ GetState(\"step\") }}{{ GetState(name=key) }}")
+	writeFile(t, filepath.Join(root, "agent", "flows", "Other", "Broken.nsl"), "{{ 1 + }}")
+
+	report, err := Build(root)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	var names []string
+	symbols := map[string]*Symbol{}
+	for _, symbol := range report.Symbols {
+		names = append(names, symbol.Kind+":"+symbol.Name)
+		symbols[symbol.Name] = symbol
+	}
+	want := []string{"state:legacy", "state:step", "attribute:business_name", "variable:greeting", "variable:unused"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected symbols %v, got %v", want, names)
+	}
+
+	step := symbols["step"]
+	var refs []string
+	for _, ref := range step.References {
+		refs = append(refs, ref.Flow+"/"+ref.Skill+":"+ref.Access)
+	}
+	wantRefs := []string{"MainFlow/Advance:write", "MainFlow/Advance:read", "MainFlow/Greet:read", "Other/Peek:read"}
+	if !reflect.DeepEqual(refs, wantRefs) {
+		t.Fatalf("expected step references %v, got %v", wantRefs, refs)
+	}
+	if first := step.References[0]; first.Path != "agent/flows/MainFlow/Advance.nsl" || first.Line != 1 || first.Column != 18 {
+		t.Fatalf("unexpected location: %+v", first)
+	}
+	if !reflect.DeepEqual(step.Problems, []string{"used in Other, which does not declare it"}) {
+		t.Fatalf("unexpected step problems: %v", step.Problems)
+	}
+	if !reflect.DeepEqual(symbols["legacy"].Problems, []string{"declared but never referenced"}) {
+		t.Fatalf("unexpected legacy problems: %v", symbols["legacy"].Problems)
+	}
+	if len(symbols["business_name"].References) != 2 || symbols["business_name"].Problems != nil {
+		t.Fatalf("unexpected attribute: %+v", symbols["business_name"])
+	}
+	if symbols["greeting"].Problems != nil || !reflect.DeepEqual(symbols["unused"].Problems, []string{"set but never read in MainFlow/Greet"}) {
+		t.Fatalf("unexpected variable problems: %v, %v", symbols["greeting"].Problems, symbols["unused"].Problems)
+	}
+
+	if len(report.Skipped) != 1 || report.Skipped[0].Path != "agent/flows/Other/Broken.nsl" {
+		t.Fatalf("expected the broken skill to be skipped, got %+v", report.Skipped)
+	}
+}
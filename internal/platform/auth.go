@@ -64,7 +64,7 @@ func ExchangeAPIKeyForToken(ctx context.Context, baseURL, apiKey string) (TokenR
 	}()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return TokenResponse{}, fmt.Errorf("exchange api key: status %d", resp.StatusCode)
+		return TokenResponse{}, fmt.Errorf("exchange api key: %w", tokenStatusError(resp.StatusCode))
 	}
 
 	var tokens TokenResponse
@@ -114,7 +114,7 @@ func RefreshAccessToken(ctx context.Context, refreshURL, refreshToken string) (T
 	}()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return TokenResponse{}, fmt.Errorf("refresh access token: status %d", resp.StatusCode)
+		return TokenResponse{}, fmt.Errorf("refresh access token: %w", tokenStatusError(resp.StatusCode))
 	}
 
 	var tokens TokenResponse
@@ -133,6 +133,15 @@ func RefreshAccessToken(ctx context.Context, refreshURL, refreshToken string) (T
 	return tokens, nil
 }
 
+// tokenStatusError describes a failed token request; 400, 401, and 403 mean the credentials were refused.
+func tokenStatusError(status int) error {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return &AuthError{Status: status}
+	}
+	return fmt.Errorf("status %d", status)
+}
+
 // ExpiresInSeconds normalises the expires_in/ExpiresAt values into seconds.
 func (t TokenResponse) ExpiresInSeconds() int {
 	if len(t.ExpiresInRaw) > 0 {
@@ -61,7 +61,11 @@ func TestExchangeAPIKeyForTokenHTTPError(t *testing.T) {
 	client, _ := httpmock.New(handler)
 	t.Cleanup(SetHTTPClientForTesting(client))
 
-	if _, err := ExchangeAPIKeyForToken(context.Background(), httpmock.BaseURL, "key"); err == nil {
+	_, err := ExchangeAPIKeyForToken(context.Background(), httpmock.BaseURL, "key")
+	if err == nil {
 		t.Fatalf("expected error on non-2xx response")
 	}
+	if !IsAuthFailure(err) || err.Error() != "exchange api key: status 400" {
+		t.Fatalf("expected a rejected key to be an auth failure, got %v", err)
+	}
 }
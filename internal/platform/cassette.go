@@ -0,0 +1,240 @@
+package platform
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/redact"
+)
+
+// Cassette is a recording of platform traffic, written by --record and played back by --replay.
+type Cassette struct {
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response. Secrets are scrubbed from both bodies, and
+// request headers are not kept.
+type Interaction struct {
+	Method string `json:"method"`
+	// URL is the request path and query. The host is left out so a cassette replays against any base URL.
+	URL          string `json:"url"`
+	RequestBody  string `json:"request_body,omitempty"`
+	Status       int    `json:"status"`
+	ContentType  string `json:"content_type,omitempty"`
+	ResponseBody string `json:"response_body"`
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(file string) (*Cassette, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", file, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to file.
+func (c *Cassette) Save(file string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	return fsutil.AtomicWrite(file, append(data, '\n'), fsutil.FilePerm)
+}
+
+// secretFields matches JSON string fields that hold credentials, whatever the [redact] settings say.
+var secretFields = regexp.MustCompile(`(?i)"(access_token|refresh_token|token|api_key|apikey|password|secret)"(\s*:\s*)"[^"]*"`)
+
+func scrub(body string) string {
+	body = secretFields.ReplaceAllString(body, `"$1"$2"`+redact.Mask+`"`)
+	return redact.String(body)
+}
+
+func requestKey(method string, u *url.URL) string {
+	return method + " " + u.RequestURI()
+}
+
+// Recorder is a RoundTripper that passes requests on and keeps a scrubbed copy of each exchange.
+type Recorder struct {
+	base     http.RoundTripper
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder records the traffic that goes through base.
+func NewRecorder(base http.RoundTripper) *Recorder {
+	return &Recorder{base: base, cassette: Cassette{RecordedAt: time.Now().UTC()}}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			requestBody = readBody(body, req.Header.Get("Content-Encoding"))
+		}
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method:       req.Method,
+		URL:          req.URL.RequestURI(),
+		RequestBody:  scrub(string(requestBody)),
+		Status:       resp.StatusCode,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ResponseBody: scrub(string(responseBody)),
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Cassette returns a copy of what has been recorded so far.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cassette := r.cassette
+	cassette.Interactions = append([]Interaction(nil), r.cassette.Interactions...)
+	return cassette
+}
+
+func readBody(body io.ReadCloser, encoding string) []byte {
+	defer func() {
+		_ = body.Close()
+	}()
+	reader := io.Reader(body)
+	if encoding == "gzip" {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil
+		}
+		reader = zr
+	}
+	data, _ := io.ReadAll(reader)
+	return data
+}
+
+// Replayer is a RoundTripper that answers requests from a cassette without touching the network.
+// Requests are matched by method, path, and query; repeated requests get the recorded responses in
+// order, and the last one again once those run out.
+type Replayer struct {
+	mu      sync.Mutex
+	name    string
+	pending map[string][]Interaction
+}
+
+// NewReplayer plays back cassette; name identifies it in errors.
+func NewReplayer(name string, cassette *Cassette) *Replayer {
+	pending := map[string][]Interaction{}
+	for _, interaction := range cassette.Interactions {
+		u, err := url.Parse(interaction.URL)
+		if err != nil {
+			continue
+		}
+		key := requestKey(interaction.Method, u)
+		pending[key] = append(pending[key], interaction)
+	}
+	return &Replayer{name: name, pending: pending}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	key := requestKey(req.Method, req.URL)
+	r.mu.Lock()
+	queue := r.pending[key]
+	var interaction Interaction
+	found := len(queue) > 0
+	if found {
+		interaction = queue[0]
+		if len(queue) > 1 {
+			r.pending[key] = queue[1:]
+		}
+	}
+	r.mu.Unlock()
+
+	if !found {
+		if req.Method != http.MethodPost || path.Base(req.URL.Path) != "token" {
+			return nil, fmt.Errorf("replay %s: no recorded response for %s", r.name, key)
+		}
+		// A run recorded with cached tokens has no token exchange, so replay answers it with placeholders.
+		interaction = Interaction{Status: http.StatusOK, ContentType: "application/json",
+			ResponseBody: `{"access_token": "replay", "refresh_token": "replay", "expires_in": 3600}`}
+	}
+	header := http.Header{}
+	if interaction.ContentType != "" {
+		header.Set("Content-Type", interaction.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.ResponseBody)),
+		ContentLength: int64(len(interaction.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// StartRecording routes every platform client and token exchange created afterwards through a
+// Recorder. The returned function restores the previous transport and saves the cassette to file.
+func StartRecording(file string) func() error {
+	recorder := NewRecorder(defaultTransport)
+	restore := installTransport(recorder)
+	return func() error {
+		restore()
+		cassette := recorder.Cassette()
+		return cassette.Save(file)
+	}
+}
+
+// StartReplay answers every platform request made afterwards from the cassette in file. The returned
+// function restores the previous transport.
+func StartReplay(file string) (func(), error) {
+	cassette, err := LoadCassette(file)
+	if err != nil {
+		return nil, err
+	}
+	return installTransport(NewReplayer(file, cassette)), nil
+}
+
+func installTransport(rt http.RoundTripper) func() {
+	prevTransport, prevClient := defaultTransport, httpClient
+	defaultTransport = rt
+	httpClient = &http.Client{Transport: rt, Timeout: requestTimeout}
+	return func() {
+		defaultTransport, httpClient = prevTransport, prevClient
+	}
+}
@@ -0,0 +1,77 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
+)
+
+func TestCassetteRecordsAndReplays(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	_, transport := httpmock.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/api/v1/auth/api-key/token":
+			_, _ = w.Write([]byte(`{"access_token": "eyJhbGciOi.secret", "refresh_token": "r-secret"}`))
+		default:
+			_ = json.NewEncoder(w).Encode([]Project{{ID: "p1", IDN: strings.Repeat("a", calls)}})
+		}
+	}))
+	recorder := NewRecorder(transport)
+	client, err := NewClient(httpmock.BaseURL, "token", WithHTTPClient(&http.Client{Transport: recorder}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	tokenReq, _ := http.NewRequest(http.MethodPost, httpmock.BaseURL+"/api/v1/auth/api-key/token", nil)
+	tokenReq.Header.Set("x-api-key", "k-secret")
+	if _, err := recorder.RoundTrip(tokenReq); err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	for range 2 {
+		if _, err := client.ListProjects(context.Background()); err != nil {
+			t.Fatalf("ListProjects: %v", err)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := recorder.Cassette()
+	if err := cassette.Save(file); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadCassette(file)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	if len(loaded.Interactions) != 3 {
+		t.Fatalf("expected 3 interactions, got %d", len(loaded.Interactions))
+	}
+	if body := loaded.Interactions[0].ResponseBody; strings.Contains(body, "secret") {
+		t.Fatalf("expected tokens to be scrubbed, got %s", body)
+	}
+
+	replay, err := NewClient("https://elsewhere.example", "token", WithHTTPClient(&http.Client{Transport: NewReplayer(file, loaded)}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	var idns []string
+	for range 3 {
+		projects, err := replay.ListProjects(context.Background())
+		if err != nil {
+			t.Fatalf("replayed ListProjects: %v", err)
+		}
+		idns = append(idns, projects[0].IDN)
+	}
+	if strings.Join(idns, ",") != "aa,aaa,aaa" {
+		t.Fatalf("expected recorded responses in order, then the last again, got %v", idns)
+	}
+	if _, err := replay.ListAgents(context.Background(), "p1"); err == nil || !strings.Contains(err.Error(), "no recorded response for GET /api/v1/bff/agents/list?project_id=p1") {
+		t.Fatalf("expected a missing-recording error, got %v", err)
+	}
+}
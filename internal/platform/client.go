@@ -2,6 +2,7 @@ package platform
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
@@ -11,15 +12,30 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/twinmind/newo-tool/internal/logging"
+	"github.com/twinmind/newo-tool/internal/metrics"
 )
 
 const (
 	defaultHTTPTimeout = 30 * time.Second
 	maxErrorBodyBytes  = 512 << 10
+	// maxDrainBytes bounds how much of an unread response body is discarded so the connection can be reused.
+	maxDrainBytes = 64 << 10
+	// compressMinBytes is the smallest request body worth gzip-compressing.
+	compressMinBytes = 8 << 10
 )
 
-var defaultTransport http.RoundTripper = http.DefaultTransport
+var (
+	defaultTransport http.RoundTripper = newDefaultTransport()
+	requestTimeout                     = defaultHTTPTimeout
+	compressRequests                   = false
+)
 
 // SetTransportForTesting overrides the transport used for outbound HTTP calls. The caller must invoke the returned
 // cleanup function to restore the previous transport when finished.
@@ -38,8 +54,21 @@ func SetTransportForTesting(rt http.RoundTripper) func() {
 type Client struct {
 	base *url.URL
 	http *http.Client
+	auth *authTransport
+
+	renew   TokenRenewer
+	renewMu sync.Mutex
+
+	apiVersion string
+	// compress is cleared when the platform rejects a gzip-encoded body, so later requests go out plain.
+	compress atomic.Bool
+	// strict is set in --strict-api mode; responses are then checked against their Go types.
+	strict *strictDecoder
 }
 
+// TokenRenewer obtains a new access token after the platform rejected the current one.
+type TokenRenewer func(ctx context.Context) (string, error)
+
 // ClientOption customises the client behaviour.
 type ClientOption func(*Client)
 
@@ -52,6 +81,32 @@ func WithHTTPClient(h *http.Client) ClientOption {
 	}
 }
 
+// WithTokenRenewer makes the client renew its access token and retry once when a request
+// fails with 401 Unauthorized, so long runs survive token expiry.
+func WithTokenRenewer(renew TokenRenewer) ClientOption {
+	return func(c *Client) {
+		c.renew = renew
+	}
+}
+
+// WithAPIVersion serves the v1 API paths under another version prefix, such as /api/v2/, for platform
+// deployments that expose a different API version. Paths that already name a version other than v1 are
+// left unchanged.
+func WithAPIVersion(version string) ClientOption {
+	return func(c *Client) {
+		c.apiVersion = strings.TrimSpace(version)
+	}
+}
+
+// WithRequestCompression gzip-compresses large request bodies, such as long prompt scripts. A platform
+// that answers 415 Unsupported Media Type gets the request again uncompressed, and compression stays off
+// for the rest of the client's life.
+func WithRequestCompression(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.compress.Store(enabled)
+	}
+}
+
 // NewClient constructs a platform client using the supplied bearer token.
 func NewClient(baseURL, token string, opts ...ClientOption) (*Client, error) {
 	if token == "" {
@@ -66,7 +121,7 @@ func NewClient(baseURL, token string, opts ...ClientOption) (*Client, error) {
 	client := &Client{
 		base: u,
 		http: &http.Client{
-			Timeout: defaultHTTPTimeout,
+			Timeout: requestTimeout,
 			Transport: &authTransport{
 				base:  defaultTransport,
 				token: token,
@@ -74,23 +129,29 @@ func NewClient(baseURL, token string, opts ...ClientOption) (*Client, error) {
 		},
 	}
 
+	client.compress.Store(compressRequests)
+	client.strict = newStrictDecoder(strictAPIWarnings)
 	for _, opt := range opts {
 		opt(client)
 	}
 
 	// Ensure custom transport also wraps token
-	if _, ok := client.http.Transport.(*authTransport); !ok {
-		client.http.Transport = &authTransport{
+	transport, ok := client.http.Transport.(*authTransport)
+	if !ok {
+		transport = &authTransport{
 			base:  client.http.Transport,
 			token: token,
 		}
+		client.http.Transport = transport
 	}
+	client.auth = transport
 
 	return client, nil
 }
 
 type authTransport struct {
 	base  http.RoundTripper
+	mu    sync.RWMutex
 	token string
 }
 
@@ -99,11 +160,42 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		t.base = defaultTransport
 	}
 	req2 := cloneRequest(req)
-	req2.Header.Set("Authorization", "Bearer "+t.token)
+	req2.Header.Set("Authorization", "Bearer "+t.currentToken())
 	req2.Header.Set("Accept", "application/json")
 	return t.base.RoundTrip(req2)
 }
 
+func (t *authTransport) currentToken() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.token
+}
+
+func (t *authTransport) setToken(token string) {
+	t.mu.Lock()
+	t.token = token
+	t.mu.Unlock()
+}
+
+// renewToken replaces a rejected token. Concurrent requests that failed with the same token share
+// a single renewal; a request that raced with one simply retries with the token it produced.
+func (c *Client) renewToken(ctx context.Context, rejected string) error {
+	c.renewMu.Lock()
+	defer c.renewMu.Unlock()
+	if c.auth.currentToken() != rejected {
+		return nil
+	}
+	token, err := c.renew(ctx)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return errors.New("renewed access token is empty")
+	}
+	c.auth.setToken(token)
+	return nil
+}
+
 func cloneRequest(r *http.Request) *http.Request {
 	r2 := r.Clone(r.Context())
 	if r.Body != nil {
@@ -116,6 +208,9 @@ func cloneRequest(r *http.Request) *http.Request {
 }
 
 func (c *Client) buildURL(p string, query map[string]string) string {
+	if c.apiVersion != "" && strings.HasPrefix(p, "/api/v1/") {
+		p = "/api/" + c.apiVersion + strings.TrimPrefix(p, "/api/v1")
+	}
 	u := *c.base
 	u.Path = path.Join(c.base.Path, p)
 	if len(query) > 0 {
@@ -129,12 +224,58 @@ func (c *Client) buildURL(p string, query map[string]string) string {
 }
 
 func (c *Client) do(ctx context.Context, method, path string, query map[string]string, body any, dest any) error {
-	var reader io.Reader
+	var payload []byte
 	if body != nil {
-		payload, err := json.Marshal(body)
+		encoded, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("encode request: %w", err)
 		}
+		payload = encoded
+	}
+
+	token := c.auth.currentToken()
+	err := c.send(ctx, method, path, query, payload, dest)
+	var apiErr *APIError
+	if c.renew == nil || !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		return err
+	}
+
+	logging.Info("access token rejected; renewing", "method", method, "path", path)
+	if renewErr := c.renewToken(ctx, token); renewErr != nil {
+		return fmt.Errorf("%w (token renewal failed: %v)", err, renewErr)
+	}
+	return c.send(ctx, method, path, query, payload, dest)
+}
+
+// send performs a single request; payload is nil for requests without a body.
+func (c *Client) send(ctx context.Context, method, path string, query map[string]string, payload []byte, dest any) error {
+	if c.compress.Load() && len(payload) >= compressMinBytes {
+		err := c.sendBody(ctx, method, path, query, payload, true, dest)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnsupportedMediaType {
+			return err
+		}
+		logging.Info("platform rejected a compressed request; sending uncompressed", "method", method, "path", path)
+		c.compress.Store(false)
+	}
+	return c.sendBody(ctx, method, path, query, payload, false, dest)
+}
+
+func (c *Client) sendBody(ctx context.Context, method, path string, query map[string]string, payload []byte, compressed bool, dest any) error {
+	var reader io.Reader
+	if compressed {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return fmt.Errorf("compress request: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compress request: %w", err)
+		}
+		payload = buf.Bytes()
+	}
+	sent := int64(len(payload))
+	if payload != nil {
 		reader = bytes.NewReader(payload)
 	}
 
@@ -142,20 +283,33 @@ func (c *Client) do(ctx context.Context, method, path string, query map[string]s
 	if err != nil {
 		return fmt.Errorf("build request: %w", err)
 	}
-	if body != nil {
+	if payload != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 
+	started := time.Now()
 	resp, err := c.http.Do(req)
 	if err != nil {
+		metrics.RecordAPICall(0, sent, 0)
+		logging.Debug("api request failed", "method", method, "path", path, "duration", time.Since(started), "error", err.Error())
 		return fmt.Errorf("call %s %s: %w", method, path, networkError(err))
 	}
+	metrics.RecordAPICall(resp.StatusCode, sent, 0)
+	respBody := &countingReader{r: resp.Body}
 	defer func() {
+		// Reading the body to EOF lets the transport put the connection back into the pool.
+		_, _ = io.Copy(io.Discard, io.LimitReader(respBody, maxDrainBytes))
+		metrics.AddBytesReceived(respBody.n)
 		_ = resp.Body.Close()
 	}()
+	logging.Debug("api request", "method", method, "path", path, "status", resp.StatusCode, "duration", time.Since(started))
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		payload, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		payload, _ := io.ReadAll(io.LimitReader(respBody, maxErrorBodyBytes))
+		logging.Debug("api error response", "method", method, "path", path, "status", resp.StatusCode, "body", string(bytes.TrimSpace(payload)))
 		return &APIError{
 			Method: method,
 			Path:   path,
@@ -167,7 +321,20 @@ func (c *Client) do(ctx context.Context, method, path string, query map[string]s
 	if dest == nil {
 		return nil
 	}
-	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+	if c.strict != nil {
+		data, err := io.ReadAll(respBody)
+		if err != nil {
+			return fmt.Errorf("read response %s %s: %w", method, path, err)
+		}
+		if stream, ok := dest.(streamDest); ok {
+			return stream.decodeStrict(c.strict, method, path, data)
+		}
+		return c.strict.decode(method, path, data, dest)
+	}
+	if stream, ok := dest.(streamDest); ok {
+		return stream.decodeStream(respBody)
+	}
+	if err := json.NewDecoder(respBody).Decode(dest); err != nil {
 		if errors.Is(err, io.EOF) {
 			return nil
 		}
@@ -176,6 +343,18 @@ func (c *Client) do(ctx context.Context, method, path string, query map[string]s
 	return nil
 }
 
+// countingReader tracks response bytes for the run metrics.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // ListProjects returns all projects visible to the customer.
 func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
 	var projects []Project
@@ -227,6 +406,11 @@ func (c *Client) CreateAgent(ctx context.Context, projectID string, payload Crea
 	return resp, nil
 }
 
+// DeleteAgent removes an agent by ID.
+func (c *Client) DeleteAgent(ctx context.Context, agentID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/designer/agents/"+agentID, nil, nil, nil)
+}
+
 // ListFlowSkills returns skills in a flow.
 func (c *Client) ListFlowSkills(ctx context.Context, flowID string) ([]Skill, error) {
 	var skills []Skill
@@ -246,6 +430,16 @@ func (c *Client) CreateFlow(ctx context.Context, agentID string, payload CreateF
 	return resp, nil
 }
 
+// UpdateFlow updates the title, description, and defaults of a flow.
+func (c *Client) UpdateFlow(ctx context.Context, flowID string, payload UpdateFlowRequest) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/designer/flows/"+flowID, nil, payload, nil)
+}
+
+// DeleteFlow removes a flow by ID.
+func (c *Client) DeleteFlow(ctx context.Context, flowID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/designer/flows/"+flowID, nil, nil, nil)
+}
+
 // GetSkill retrieves a skill by ID.
 func (c *Client) GetSkill(ctx context.Context, skillID string) (Skill, error) {
 	var skill Skill
@@ -255,6 +449,56 @@ func (c *Client) GetSkill(ctx context.Context, skillID string) (Skill, error) {
 	return skill, nil
 }
 
+// ListSkillVersions returns the saved revisions of a skill, newest first.
+func (c *Client) ListSkillVersions(ctx context.Context, skillID string) ([]SkillVersion, error) {
+	var versions []SkillVersion
+	if err := c.do(ctx, http.MethodGet, "/api/v1/designer/flows/skills/"+skillID+"/versions", nil, nil, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetSkillVersion retrieves one revision of a skill including its script.
+func (c *Client) GetSkillVersion(ctx context.Context, skillID, versionID string) (SkillVersion, error) {
+	var version SkillVersion
+	path := fmt.Sprintf("/api/v1/designer/flows/skills/%s/versions/%s", skillID, versionID)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &version); err != nil {
+		return SkillVersion{}, err
+	}
+	return version, nil
+}
+
+// ExecuteSkill runs a skill once on the platform and returns its result.
+func (c *Client) ExecuteSkill(ctx context.Context, skillID string, payload ExecuteSkillRequest) (ExecuteSkillResponse, error) {
+	var resp ExecuteSkillResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/designer/flows/skills/"+skillID+"/execute", nil, payload, &resp); err != nil {
+		return ExecuteSkillResponse{}, err
+	}
+	return resp, nil
+}
+
+// ListActLogs fetches recent conversation act logs of the customer, newest first.
+func (c *Client) ListActLogs(ctx context.Context, query ActLogQuery) ([]ActLog, error) {
+	params := map[string]string{}
+	if query.FlowIDN != "" {
+		params["flow_idn"] = query.FlowIDN
+	}
+	if query.SkillIDN != "" {
+		params["skill_idn"] = query.SkillIDN
+	}
+	if !query.Since.IsZero() {
+		params["from_datetime"] = query.Since.UTC().Format(time.RFC3339)
+	}
+	if query.Limit > 0 {
+		params["per"] = strconv.Itoa(query.Limit)
+	}
+	var resp ActLogsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/bff/conversations/acts", params, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
 // ListFlowEvents returns events attached to a flow.
 func (c *Client) ListFlowEvents(ctx context.Context, flowID string) ([]FlowEvent, error) {
 	var events []FlowEvent
@@ -274,6 +518,7 @@ func (c *Client) ListFlowStates(ctx context.Context, flowID string) ([]FlowState
 }
 
 // UpdateSkill updates a flow skill with new metadata and script.
+// The designer API has no bulk variant, so push sends these updates concurrently instead.
 func (c *Client) UpdateSkill(ctx context.Context, skillID string, payload UpdateSkillRequest) error {
 	return c.do(ctx, http.MethodPut, "/api/v1/designer/flows/skills/"+skillID, nil, payload, nil)
 }
@@ -324,6 +569,20 @@ func (c *Client) GetCustomerAttributes(ctx context.Context, includeHidden bool)
 	return resp, nil
 }
 
+// CreateCustomerAttribute adds a customer attribute.
+func (c *Client) CreateCustomerAttribute(ctx context.Context, payload CustomerAttributeRequest) (CreateCustomerAttributeResponse, error) {
+	var resp CreateCustomerAttributeResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/customer/attributes", nil, payload, &resp); err != nil {
+		return CreateCustomerAttributeResponse{}, err
+	}
+	return resp, nil
+}
+
+// UpdateCustomerAttribute replaces the value and settings of a customer attribute.
+func (c *Client) UpdateCustomerAttribute(ctx context.Context, attributeID string, payload CustomerAttributeRequest) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/customer/attributes/"+attributeID, nil, payload, nil)
+}
+
 // PublishFlow publishes a flow after updates.
 func (c *Client) PublishFlow(ctx context.Context, flowID string, payload PublishFlowRequest) error {
 	return c.do(ctx, http.MethodPost, "/api/v1/designer/flows/"+flowID+"/publish", nil, payload, nil)
@@ -344,6 +603,11 @@ func (c *Client) DeleteFlowEvent(ctx context.Context, eventID string) error {
 	return c.do(ctx, http.MethodDelete, "/api/v1/designer/flows/events/"+eventID, nil, nil, nil)
 }
 
+// UpdateFlowEvent replaces the definition of an existing flow event.
+func (c *Client) UpdateFlowEvent(ctx context.Context, eventID string, payload UpdateFlowEventRequest) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/designer/flows/events/"+eventID, nil, payload, nil)
+}
+
 // CreateFlowState creates a state field for the specified flow.
 func (c *Client) CreateFlowState(ctx context.Context, flowID string, payload CreateFlowStateRequest) (CreateFlowStateResponse, error) {
 	var resp CreateFlowStateResponse
@@ -359,6 +623,11 @@ func (c *Client) DeleteFlowState(ctx context.Context, stateID string) error {
 	return c.do(ctx, http.MethodDelete, "/api/v1/designer/flows/states/"+stateID, nil, nil, nil)
 }
 
+// UpdateFlowState replaces the definition of an existing state field.
+func (c *Client) UpdateFlowState(ctx context.Context, stateID string, payload UpdateFlowStateRequest) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/designer/flows/states/"+stateID, nil, payload, nil)
+}
+
 func networkError(err error) error {
 	if ne, ok := err.(net.Error); ok && ne.Timeout() {
 		return fmt.Errorf("request timeout: %w", err)
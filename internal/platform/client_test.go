@@ -1,11 +1,15 @@
 package platform
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
 )
@@ -200,6 +204,29 @@ func TestClientCreateSkill(t *testing.T) {
 	}
 }
 
+func TestClientUpdateFlow(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v1/designer/flows/flow-1" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body UpdateFlowRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if body.DefaultRunnerType != "nsl" || body.DefaultModel.ModelIDN != "gpt4o" {
+			t.Fatalf("unexpected payload: %+v", body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	payload := UpdateFlowRequest{IDN: "Main", DefaultRunnerType: "nsl", DefaultModel: ModelConfig{ModelIDN: "gpt4o", ProviderIDN: "openai"}}
+	if err := client.UpdateFlow(context.Background(), "flow-1", payload); err != nil {
+		t.Fatalf("UpdateFlow: %v", err)
+	}
+}
+
 func TestClientDeleteSkill(t *testing.T) {
 	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
@@ -352,3 +379,286 @@ func TestClientDeleteFlowState(t *testing.T) {
 		t.Fatalf("DeleteFlowState: %v", err)
 	}
 }
+
+func TestClientRenewsTokenOnUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload CreateProjectRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.IDN != "proj" {
+			t.Fatalf("request body not replayed: %#v (%v)", payload, err)
+		}
+		_ = json.NewEncoder(w).Encode(CreateProjectResponse{ID: "project-1"})
+	})
+	stubClient, _ := httpmock.New(handler)
+	renewals := 0
+	client, err := NewClient(httpmock.BaseURL, "stale", WithHTTPClient(stubClient), WithTokenRenewer(func(context.Context) (string, error) {
+		renewals++
+		return "fresh", nil
+	}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	resp, err := client.CreateProject(context.Background(), CreateProjectRequest{IDN: "proj"})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if resp.ID != "project-1" || renewals != 1 || calls != 2 {
+		t.Fatalf("expected one renewal and a retry, got id=%q renewals=%d calls=%d", resp.ID, renewals, calls)
+	}
+
+	// A token the platform keeps rejecting is renewed once per request, not in a loop.
+	renewals = 0
+	stubClient, _ = httpmock.New(handler)
+	client, err = NewClient(httpmock.BaseURL, "stale", WithHTTPClient(stubClient), WithTokenRenewer(func(context.Context) (string, error) {
+		renewals++
+		return "still-stale", nil
+	}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	_, err = client.ListProjects(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized || renewals != 1 {
+		t.Fatalf("expected 401 after a single renewal, got %v (renewals=%d)", err, renewals)
+	}
+}
+
+func TestClientSkillVersions(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("method: %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/api/v1/designer/flows/skills/skill-1/versions":
+			_ = json.NewEncoder(w).Encode([]SkillVersion{{ID: "v-2", Version: 2}, {ID: "v-1", Version: 1}})
+		case "/api/v1/designer/flows/skills/skill-1/versions/v-1":
+			_ = json.NewEncoder(w).Encode(SkillVersion{ID: "v-1", Version: 1, PromptScript: "first"})
+		default:
+			t.Fatalf("path: %s", r.URL.Path)
+		}
+	}))
+
+	versions, err := client.ListSkillVersions(context.Background(), "skill-1")
+	if err != nil {
+		t.Fatalf("ListSkillVersions: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 {
+		t.Fatalf("unexpected versions: %#v", versions)
+	}
+	version, err := client.GetSkillVersion(context.Background(), "skill-1", "v-1")
+	if err != nil {
+		t.Fatalf("GetSkillVersion: %v", err)
+	}
+	if version.PromptScript != "first" {
+		t.Fatalf("unexpected version: %#v", version)
+	}
+}
+
+func TestClientListActLogs(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/bff/conversations/acts" {
+			t.Fatalf("path: %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("flow_idn") != "MainFlow" || query.Get("per") != "10" || query.Get("from_datetime") != "2024-05-01T10:00:00Z" {
+			t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+		}
+		if query.Has("skill_idn") {
+			t.Fatalf("empty skill filter sent: %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(ActLogsResponse{Items: []ActLog{{ID: "a1", FlowIDN: "MainFlow", Message: "hello"}}})
+	}))
+
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	acts, err := client.ListActLogs(context.Background(), ActLogQuery{FlowIDN: "MainFlow", Since: since, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListActLogs: %v", err)
+	}
+	if len(acts) != 1 || acts[0].Message != "hello" {
+		t.Fatalf("unexpected acts: %#v", acts)
+	}
+}
+
+func TestClientExecuteSkill(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/designer/flows/skills/skill-1/execute" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var payload ExecuteSkillRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if payload.Arguments["name"] != "Ada" || payload.Context["channel"] != "sms" {
+			t.Fatalf("unexpected payload: %#v", payload)
+		}
+		_ = json.NewEncoder(w).Encode(ExecuteSkillResponse{Result: "Hello Ada", Logs: []string{"started"}})
+	}))
+
+	resp, err := client.ExecuteSkill(context.Background(), "skill-1", ExecuteSkillRequest{
+		Arguments: map[string]any{"name": "Ada"},
+		Context:   map[string]any{"channel": "sms"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteSkill: %v", err)
+	}
+	if resp.Result != "Hello Ada" || len(resp.Logs) != 1 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+}
+
+func TestClientAPIVersion(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	stubClient, _ := httpmock.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "a1"})
+	}))
+	client, err := NewClient(httpmock.BaseURL, "token", WithHTTPClient(stubClient), WithAPIVersion("v3"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, _ = client.ListProjects(context.Background())
+	_, _ = client.CreateAgent(context.Background(), "p1", CreateAgentRequest{IDN: "agent"})
+	want := []string{"/api/v3/designer/projects", "/api/v2/designer/p1/agents"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+}
+
+func TestClientRequestCompression(t *testing.T) {
+	t.Parallel()
+
+	script := strings.Repeat("{{ Say(text=\"hello\") }}\n", 1000)
+	var encodings []string
+	rejectGzip := true
+	stubClient, _ := httpmock.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		encodings = append(encodings, encoding)
+		body := io.Reader(r.Body)
+		if encoding == "gzip" {
+			if rejectGzip {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatalf("gzip reader: %v", err)
+			}
+			body = zr
+		}
+		var payload UpdateSkillRequest
+		if err := json.NewDecoder(body).Decode(&payload); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if payload.ID != "id" {
+			t.Fatalf("unexpected payload: %+v", payload)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	large := UpdateSkillRequest{ID: "id", PromptScript: script}
+	small := UpdateSkillRequest{ID: "id", PromptScript: "short"}
+
+	// A platform without gzip support gets a plain retry, and compression stays off afterwards.
+	client, err := NewClient(httpmock.BaseURL, "token", WithHTTPClient(stubClient), WithRequestCompression(true))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for range 2 {
+		if err := client.UpdateSkill(context.Background(), "id", large); err != nil {
+			t.Fatalf("UpdateSkill: %v", err)
+		}
+	}
+	if got := strings.Join(encodings, ","); got != "gzip,," {
+		t.Fatalf("expected one rejected gzip request then plain ones, got %q", encodings)
+	}
+
+	encodings = nil
+	rejectGzip = false
+	client, err = NewClient(httpmock.BaseURL, "token", WithHTTPClient(stubClient), WithRequestCompression(true))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for _, payload := range []UpdateSkillRequest{large, small} {
+		if err := client.UpdateSkill(context.Background(), "id", payload); err != nil {
+			t.Fatalf("UpdateSkill: %v", err)
+		}
+	}
+	if got := strings.Join(encodings, ","); got != "gzip," {
+		t.Fatalf("expected only the large body to be compressed, got %q", encodings)
+	}
+}
+
+func TestClientEachFlowSkill(t *testing.T) {
+	t.Parallel()
+
+	body := `[{"id": "s1", "idn": "a", "prompt_script": "one"}, {"id": "s2", "idn": "b", "prompt_script": "two"}, {"id": "s3", "idn": "c"}]`
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/designer/flows/flow-1/skills" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+
+	var scripts []string
+	err := client.EachFlowSkill(context.Background(), "flow-1", func(skill Skill) error {
+		scripts = append(scripts, skill.IDN+"="+skill.PromptScript)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachFlowSkill: %v", err)
+	}
+	if got := strings.Join(scripts, ","); got != "a=one,b=two,c=" {
+		t.Fatalf("unexpected skills: %s", got)
+	}
+
+	stop := errors.New("stop")
+	seen := 0
+	err = client.EachFlowSkill(context.Background(), "flow-1", func(Skill) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Fatalf("expected the callback error to stop the listing, got %v after %d skill(s)", err, seen)
+	}
+}
+
+func TestClientUpdateCustomerAttribute(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Fatalf("method: %s", r.Method)
+		}
+		if !strings.HasSuffix(r.URL.Path, "/api/v1/customer/attributes/attr-1") {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		var body CustomerAttributeRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if body.IDN != "timezone" || body.Value != "UTC" {
+			t.Fatalf("unexpected body: %+v", body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	if err := client.UpdateCustomerAttribute(context.Background(), "attr-1", CustomerAttributeRequest{IDN: "timezone", Value: "UTC"}); err != nil {
+		t.Fatalf("UpdateCustomerAttribute: %v", err)
+	}
+}
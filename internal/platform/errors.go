@@ -1,7 +1,9 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 )
 
 // APIError describes an HTTP error returned by the NEWO platform.
@@ -20,6 +22,27 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.Path, e.Status, e.Body)
 }
 
+// AuthError reports a token request the platform rejected, for example because the API key was revoked.
+type AuthError struct {
+	Status int
+}
+
+// Error implements the error interface.
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("status %d", e.Status)
+}
+
+// IsAuthFailure reports whether err comes from rejected credentials: a refused token request, or an
+// API call that still answered 401 or 403 after the token was renewed.
+func IsAuthFailure(err error) bool {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden)
+}
+
 // Temporary reports whether the error may succeed on retry.
 func (e *APIError) Temporary() bool {
 	if e == nil {
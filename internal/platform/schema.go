@@ -0,0 +1,232 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/twinmind/newo-tool/internal/logging"
+)
+
+// strictAPIWarnings is where clients created from now on report unknown response fields; nil leaves
+// strict checks off.
+var strictAPIWarnings io.Writer
+
+// SetStrictAPI turns on response schema checks for clients created afterwards; see WithStrictAPI. A nil
+// writer turns them off again.
+func SetStrictAPI(warnings io.Writer) {
+	strictAPIWarnings = warnings
+}
+
+// WithStrictAPI checks every response against the type it is decoded into. Unknown fields are reported
+// to warnings once per type and field, so a long listing does not repeat them. A response that lacks a
+// field tagged `api:"required"` fails with a *SchemaError instead of leaving a zero value behind.
+func WithStrictAPI(warnings io.Writer) ClientOption {
+	return func(c *Client) {
+		c.strict = newStrictDecoder(warnings)
+	}
+}
+
+// strictDecoder decodes responses for a client in strict mode.
+type strictDecoder struct {
+	mu       sync.Mutex
+	warnings io.Writer
+	seen     map[string]bool
+}
+
+func newStrictDecoder(warnings io.Writer) *strictDecoder {
+	if warnings == nil {
+		return nil
+	}
+	return &strictDecoder{warnings: warnings, seen: map[string]bool{}}
+}
+
+// SchemaError reports required fields missing from an API response.
+type SchemaError struct {
+	Method  string
+	Path    string
+	Missing []string
+}
+
+func (e *SchemaError) Error() string {
+	missing := e.Missing
+	more := ""
+	if len(missing) > maxReportedMissing {
+		more = fmt.Sprintf(" and %d more", len(missing)-maxReportedMissing)
+		missing = missing[:maxReportedMissing]
+	}
+	return fmt.Sprintf("%s %s: response schema mismatch: required field(s) missing: %s%s; the platform API may have changed",
+		e.Method, e.Path, strings.Join(missing, ", "), more)
+}
+
+// maxReportedMissing bounds how many missing fields a SchemaError message lists.
+const maxReportedMissing = 5
+
+// decode decodes data into dest and compares the payload with dest's type.
+func (d *strictDecoder) decode(method, path string, data []byte, dest any) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return err
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	check := schemaCheck{unknown: map[string]string{}}
+	check.walk(raw, reflect.TypeOf(dest), "")
+
+	fields := make([]string, 0, len(check.unknown))
+	for field := range check.unknown {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	d.mu.Lock()
+	for _, field := range fields {
+		if d.seen[field] {
+			continue
+		}
+		d.seen[field] = true
+		fmt.Fprintf(d.warnings, "Warning: %s %s: unknown field %s (first at %s); it is ignored\n", method, path, field, check.unknown[field])
+		logging.Warn("unknown field in api response", "method", method, "path", path, "field", field)
+	}
+	d.mu.Unlock()
+
+	if len(check.missing) > 0 {
+		return &SchemaError{Method: method, Path: path, Missing: check.missing}
+	}
+	return nil
+}
+
+// schemaCheck walks a decoded JSON value alongside the Go type it was decoded into.
+type schemaCheck struct {
+	// unknown maps "Type.field" to the first location it was seen at.
+	unknown map[string]string
+	missing []string
+}
+
+func (s *schemaCheck) walk(value any, t reflect.Type, at string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		byName := make(map[string]reflect.StructField, len(fields))
+		for _, field := range fields {
+			byName[strings.ToLower(jsonName(field))] = field
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field, ok := byName[strings.ToLower(key)]
+			if !ok {
+				name := t.Name() + "." + key
+				if _, seen := s.unknown[name]; !seen {
+					s.unknown[name] = location(at)
+				}
+				continue
+			}
+			s.walk(object[key], field.Type, at+"."+key)
+		}
+		for _, field := range fields {
+			if field.Tag.Get("api") != "required" {
+				continue
+			}
+			name := jsonName(field)
+			if item, ok := lookupFold(object, name); !ok || item == nil || item == "" {
+				s.missing = append(s.missing, location(at+"."+name))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			s.walk(item, t.Elem(), fmt.Sprintf("%s[%d]", at, i))
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s.walk(object[key], t.Elem(), at+"."+key)
+		}
+	}
+}
+
+// jsonFields lists the fields encoding/json would fill, in declaration order, flattening embedded
+// structs. Keys are matched case-insensitively, as encoding/json does.
+func jsonFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func lookupFold(object map[string]any, name string) (any, bool) {
+	if item, ok := object[name]; ok {
+		return item, true
+	}
+	for key, item := range object {
+		if strings.EqualFold(key, name) {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func location(at string) string {
+	if at == "" {
+		return "the response"
+	}
+	return strings.TrimPrefix(at, ".")
+}
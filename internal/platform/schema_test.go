@@ -0,0 +1,77 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
+)
+
+func strictClient(t *testing.T, body string, warnings *bytes.Buffer) *Client {
+	t.Helper()
+	stubClient, _ := httpmock.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	client, err := NewClient(httpmock.BaseURL, "token", WithHTTPClient(stubClient), WithStrictAPI(warnings))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestStrictAPIWarnsAboutUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	var warnings bytes.Buffer
+	client := strictClient(t, `[
+		{"id": "s1", "idn": "greet", "runner_type": "nsl", "prompt_script": "hi", "prompt_template": "x"},
+		{"id": "s2", "idn": "bye", "runner_type": "nsl", "prompt_template": "y", "model": {"model_idn": "m", "temperature": 0.2}}
+	]`, &warnings)
+
+	skills, err := client.ListFlowSkills(context.Background(), "flow")
+	if err != nil {
+		t.Fatalf("ListFlowSkills: %v", err)
+	}
+	if len(skills) != 2 || skills[0].PromptScript != "hi" || skills[1].Model.ModelIDN != "m" {
+		t.Fatalf("unexpected skills: %+v", skills)
+	}
+	if _, err := client.ListFlowSkills(context.Background(), "flow"); err != nil {
+		t.Fatalf("ListFlowSkills: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(warnings.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one warning per unknown field, got:\n%s", warnings.String())
+	}
+	if !strings.Contains(lines[0], "unknown field ModelConfig.temperature (first at [1].model)") ||
+		!strings.Contains(lines[1], "unknown field Skill.prompt_template (first at [0])") {
+		t.Fatalf("unexpected warnings:\n%s", warnings.String())
+	}
+}
+
+func TestStrictAPIRequiresFields(t *testing.T) {
+	t.Parallel()
+
+	client := strictClient(t, `[{"id": "a1", "idn": "agent", "flows": [{"id": "f1", "idn": null}, {"title": "x"}]}]`, &bytes.Buffer{})
+	_, err := client.ListAgents(context.Background(), "project")
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected a schema error, got %v", err)
+	}
+	if got := strings.Join(schemaErr.Missing, " "); got != "[0].flows[0].idn [0].flows[1].id [0].flows[1].idn" {
+		t.Fatalf("unexpected missing fields: %s", got)
+	}
+
+	lenient, err := NewClient(httpmock.BaseURL, "token", WithHTTPClient(client.http))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := lenient.ListAgents(context.Background(), "project"); err != nil {
+		t.Fatalf("expected the default client to accept the response, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// streamDest is a response destination that consumes the body as it arrives instead of decoding it
+// into a single value first.
+type streamDest interface {
+	decodeStream(r io.Reader) error
+	decodeStrict(d *strictDecoder, method, path string, data []byte) error
+}
+
+// arrayStream hands the elements of a JSON array response to fn one at a time, so only the element
+// being handled is held in memory. An error from fn stops the stream and is returned as is.
+type arrayStream[T any] struct {
+	fn func(T) error
+}
+
+func (s arrayStream[T]) decodeStream(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected a JSON array, got %v", tok)
+	}
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		if err := s.fn(item); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// decodeStrict checks the whole response, which --strict-api needs to see at once, and then hands
+// the elements on.
+func (s arrayStream[T]) decodeStrict(d *strictDecoder, method, path string, data []byte) error {
+	var items []T
+	if err := d.decode(method, path, data, &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := s.fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EachFlowSkill streams the skills of a flow to fn as they are decoded, which keeps the memory of a
+// pull flat for flows with very large prompt scripts. An error from fn stops the listing.
+func (c *Client) EachFlowSkill(ctx context.Context, flowID string, fn func(Skill) error) error {
+	return c.do(ctx, http.MethodGet, "/api/v1/designer/flows/"+flowID+"/skills", nil, nil, arrayStream[Skill]{fn: fn})
+}
@@ -0,0 +1,140 @@
+package platform
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultMaxIdleConnsPerHost keeps enough idle connections for pull's nested fan-out. The net/http
+// default of two closes most connections after every burst, which leaves thousands of sockets in
+// TIME_WAIT on large pulls.
+const defaultMaxIdleConnsPerHost = 64
+
+// TransportOptions configures how the platform API is reached.
+type TransportOptions struct {
+	// Proxy is the URL of an http, https, or socks5 proxy. When empty, the proxy environment variables apply.
+	Proxy string
+	// CABundle is a PEM file of CA certificates trusted in addition to the system pool.
+	CABundle string
+	// InsecureSkipVerify disables TLS certificate verification.
+	InsecureSkipVerify bool
+
+	// RequestTimeout bounds each API request, including reading the response. Zero keeps the default.
+	RequestTimeout time.Duration
+	// Connection pool tuning; zero values keep the defaults.
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+
+	// CompressRequests gzip-compresses large request bodies; see WithRequestCompression.
+	CompressRequests bool
+}
+
+// IsZero reports whether the options leave the default transport unchanged.
+func (o TransportOptions) IsZero() bool {
+	return o == TransportOptions{}
+}
+
+func newDefaultTransport() *http.Transport {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		base = &http.Transport{Proxy: http.ProxyFromEnvironment, ForceAttemptHTTP2: true}
+	}
+	transport := base.Clone()
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if transport.MaxIdleConns < defaultMaxIdleConnsPerHost {
+		transport.MaxIdleConns = defaultMaxIdleConnsPerHost
+	}
+	return transport
+}
+
+// NewTransport builds an HTTP transport from the default one with the given proxy, TLS, and pool settings.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	transport := newDefaultTransport()
+
+	if proxy := strings.TrimSpace(opts.Proxy); proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("proxy must be an absolute URL, got %q", proxy)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("proxy must use http, https, or socks5, got %q", proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if bundle := strings.TrimSpace(opts.CABundle); bundle != "" || opts.InsecureSkipVerify {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+		if bundle != "" {
+			pem, err := os.ReadFile(bundle)
+			if err != nil {
+				return nil, fmt.Errorf("read CA bundle: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", bundle)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		tlsConfig.InsecureSkipVerify = opts.InsecureSkipVerify
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if opts.MaxIdleConnsPerHost < 0 || opts.MaxConnsPerHost < 0 || opts.IdleConnTimeout < 0 || opts.RequestTimeout < 0 {
+		return nil, fmt.Errorf("connection limits and timeouts must not be negative")
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		if transport.MaxIdleConns < opts.MaxIdleConnsPerHost {
+			transport.MaxIdleConns = opts.MaxIdleConnsPerHost
+		}
+	}
+	if opts.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+	if opts.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	return transport, nil
+}
+
+// ConfigureTransport installs a transport built from opts for every platform client and token
+// exchange made afterwards. Zero options keep the current transport.
+func ConfigureTransport(opts TransportOptions) error {
+	if opts.IsZero() {
+		return nil
+	}
+	transport, err := NewTransport(opts)
+	if err != nil {
+		return err
+	}
+	if opts.RequestTimeout > 0 {
+		requestTimeout = opts.RequestTimeout
+	}
+	compressRequests = opts.CompressRequests
+	defaultTransport = transport
+	httpClient = &http.Client{Transport: transport, Timeout: requestTimeout}
+	return nil
+}
+
+// Transport returns the transport platform clients use, for other calls that should go through the
+// same proxy and TLS settings.
+func Transport() http.RoundTripper {
+	return defaultTransport
+}
@@ -0,0 +1,117 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestNewTransportTrustsCABundle(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+
+	transport, err := NewTransport(TransportOptions{CABundle: bundle})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the bundle to be trusted: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if _, err := (&http.Client{Transport: http.DefaultTransport}).Get(server.URL); err == nil {
+		t.Fatal("expected the default transport to reject the test certificate")
+	}
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	t.Parallel()
+
+	transport, err := NewTransport(TransportOptions{Proxy: "http://proxy.example:3128"})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	proxy, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "app.newo.ai"}})
+	if err != nil || proxy == nil || proxy.Host != "proxy.example:3128" {
+		t.Fatalf("unexpected proxy: %v (%v)", proxy, err)
+	}
+
+	for _, opts := range []TransportOptions{
+		{Proxy: "proxy.example:3128"},
+		{Proxy: "ftp://proxy.example"},
+		{CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+	} {
+		if _, err := NewTransport(opts); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
+
+func TestClientReusesConnectionsUnderFanOut(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	opened := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Project{{ID: "1", IDN: "proj"}})
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			opened++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	transport, err := NewTransport(TransportOptions{})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	client, err := NewClient(server.URL, "token", WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	// Mirror pull's fan-out: bursts of 16 concurrent calls should keep reusing the same connections.
+	const workers, rounds = 16, 5
+	for round := 0; round < rounds; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.ListProjects(context.Background()); err != nil {
+					t.Errorf("ListProjects: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if opened > workers {
+		t.Fatalf("expected at most %d connections for %d requests, got %d", workers, workers*rounds, opened)
+	}
+}
@@ -1,9 +1,11 @@
 package platform
 
+import "time"
+
 // Project represents high-level project metadata.
 type Project struct {
-	ID          string `json:"id"`
-	IDN         string `json:"idn"`
+	ID          string `json:"id" api:"required"`
+	IDN         string `json:"idn" api:"required"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	CreatedAt   string `json:"created_at"`
@@ -24,13 +26,13 @@ type CreateProjectRequest struct {
 
 // CreateProjectResponse captures identifiers for a newly created project.
 type CreateProjectResponse struct {
-	ID string `json:"id"`
+	ID string `json:"id" api:"required"`
 }
 
 // Agent represents an agent belonging to a project.
 type Agent struct {
-	ID          string `json:"id"`
-	IDN         string `json:"idn"`
+	ID          string `json:"id" api:"required"`
+	IDN         string `json:"idn" api:"required"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Flows       []Flow `json:"flows"`
@@ -46,13 +48,13 @@ type CreateAgentRequest struct {
 
 // CreateAgentResponse captures the identifier assigned to a new agent.
 type CreateAgentResponse struct {
-	ID string `json:"id"`
+	ID string `json:"id" api:"required"`
 }
 
 // Flow describes a flow attached to an agent.
 type Flow struct {
-	ID                string      `json:"id"`
-	IDN               string      `json:"idn"`
+	ID                string      `json:"id" api:"required"`
+	IDN               string      `json:"idn" api:"required"`
 	Title             string      `json:"title"`
 	Description       string      `json:"description"`
 	DefaultRunnerType string      `json:"default_runner_type"`
@@ -68,7 +70,16 @@ type CreateFlowRequest struct {
 
 // CreateFlowResponse captures the identifier assigned to a new flow.
 type CreateFlowResponse struct {
-	ID string `json:"id"`
+	ID string `json:"id" api:"required"`
+}
+
+// UpdateFlowRequest represents the payload for updating flow settings.
+type UpdateFlowRequest struct {
+	IDN               string      `json:"idn"`
+	Title             string      `json:"title"`
+	Description       string      `json:"description,omitempty"`
+	DefaultRunnerType string      `json:"default_runner_type"`
+	DefaultModel      ModelConfig `json:"default_model"`
 }
 
 // ModelConfig contains model identifiers.
@@ -85,21 +96,72 @@ type SkillParameter struct {
 
 // Skill represents a skill returned by listFlowSkills.
 type Skill struct {
-	ID           string           `json:"id"`
-	IDN          string           `json:"idn"`
+	ID           string           `json:"id" api:"required"`
+	IDN          string           `json:"idn" api:"required"`
 	Title        string           `json:"title"`
 	PromptScript string           `json:"prompt_script"`
-	RunnerType   string           `json:"runner_type"`
+	RunnerType   string           `json:"runner_type" api:"required"`
 	Model        ModelConfig      `json:"model"`
 	Parameters   []SkillParameter `json:"parameters"`
 	Path         string           `json:"path"`
 	UpdatedAt    string           `json:"updated_at"`
 }
 
+// SkillVersion is a saved revision of a skill script. Listings may leave PromptScript empty.
+type SkillVersion struct {
+	ID           string `json:"id"`
+	Version      int    `json:"version"`
+	PromptScript string `json:"prompt_script"`
+	RunnerType   string `json:"runner_type"`
+	CreatedAt    string `json:"created_at"`
+	CreatedBy    string `json:"created_by"`
+	Comment      string `json:"comment"`
+}
+
+// ExecuteSkillRequest runs a skill with the given parameter values and conversation context.
+type ExecuteSkillRequest struct {
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Context   map[string]any `json:"context,omitempty"`
+}
+
+// ExecuteSkillResponse captures the outcome of a skill execution.
+type ExecuteSkillResponse struct {
+	Result     string   `json:"result"`
+	Error      string   `json:"error,omitempty"`
+	Logs       []string `json:"logs,omitempty"`
+	DurationMS int      `json:"duration_ms,omitempty"`
+}
+
+// ActLog is one entry of a customer's conversation act log.
+type ActLog struct {
+	ID             string         `json:"id"`
+	Datetime       string         `json:"datetime"`
+	Level          string         `json:"level"`
+	FlowIDN        string         `json:"flow_idn"`
+	SkillIDN       string         `json:"skill_idn"`
+	EventIDN       string         `json:"event_idn"`
+	ConversationID string         `json:"conversation_id"`
+	Message        string         `json:"message"`
+	Arguments      map[string]any `json:"arguments,omitempty"`
+}
+
+// ActLogQuery filters the act log. Empty fields are not sent.
+type ActLogQuery struct {
+	FlowIDN  string
+	SkillIDN string
+	Since    time.Time
+	Limit    int
+}
+
+// ActLogsResponse wraps a page of act log entries.
+type ActLogsResponse struct {
+	Items []ActLog `json:"items"`
+}
+
 // FlowEvent contains metadata for flow events.
 type FlowEvent struct {
 	ID             string `json:"id"`
-	IDN            string `json:"idn"`
+	IDN            string `json:"idn" api:"required"`
 	Description    string `json:"description"`
 	SkillSelector  string `json:"skill_selector"`
 	SkillIDN       string `json:"skill_idn"`
@@ -123,13 +185,25 @@ type CreateFlowEventRequest struct {
 
 // CreateFlowEventResponse captures identifier assigned to a new flow event.
 type CreateFlowEventResponse struct {
-	ID string `json:"id"`
+	ID string `json:"id" api:"required"`
+}
+
+// UpdateFlowEventRequest represents payload to update a flow event.
+type UpdateFlowEventRequest struct {
+	IDN            string `json:"idn"`
+	Description    string `json:"description,omitempty"`
+	SkillSelector  string `json:"skill_selector"`
+	SkillIDN       string `json:"skill_idn,omitempty"`
+	StateIDN       string `json:"state_idn,omitempty"`
+	InterruptMode  string `json:"interrupt_mode"`
+	IntegrationIDN string `json:"integration_idn"`
+	ConnectorIDN   string `json:"connector_idn"`
 }
 
 // FlowState captures state fields for a flow.
 type FlowState struct {
 	ID           string `json:"id"`
-	IDN          string `json:"idn"`
+	IDN          string `json:"idn" api:"required"`
 	Title        string `json:"title"`
 	DefaultValue string `json:"default_value"`
 	Scope        string `json:"scope"`
@@ -145,13 +219,21 @@ type CreateFlowStateRequest struct {
 
 // CreateFlowStateResponse captures identifier assigned to a new flow state.
 type CreateFlowStateResponse struct {
-	ID string `json:"id"`
+	ID string `json:"id" api:"required"`
+}
+
+// UpdateFlowStateRequest represents payload to update a flow state.
+type UpdateFlowStateRequest struct {
+	Title        string `json:"title"`
+	IDN          string `json:"idn"`
+	DefaultValue string `json:"default_value,omitempty"`
+	Scope        string `json:"scope"`
 }
 
 // CustomerProfile describes a NEWO customer.
 type CustomerProfile struct {
-	ID           string `json:"id"`
-	IDN          string `json:"idn"`
+	ID           string `json:"id" api:"required"`
+	IDN          string `json:"idn" api:"required"`
 	Organization string `json:"organization_name"`
 	Email        string `json:"email"`
 }
@@ -159,7 +241,7 @@ type CustomerProfile struct {
 // CustomerAttribute describes a customer attribute entry.
 type CustomerAttribute struct {
 	ID             string      `json:"id"`
-	IDN            string      `json:"idn"`
+	IDN            string      `json:"idn" api:"required"`
 	Value          interface{} `json:"value"`
 	Title          string      `json:"title"`
 	Description    string      `json:"description"`
@@ -174,6 +256,23 @@ type CustomerAttributesResponse struct {
 	Attributes []CustomerAttribute `json:"attributes"`
 }
 
+// CustomerAttributeRequest represents the payload for creating or updating a customer attribute.
+type CustomerAttributeRequest struct {
+	IDN            string      `json:"idn"`
+	Value          interface{} `json:"value"`
+	Title          string      `json:"title"`
+	Description    string      `json:"description"`
+	Group          string      `json:"group"`
+	IsHidden       bool        `json:"is_hidden"`
+	PossibleValues []string    `json:"possible_values"`
+	ValueType      string      `json:"value_type"`
+}
+
+// CreateCustomerAttributeResponse captures the identifier assigned to a new customer attribute.
+type CreateCustomerAttributeResponse struct {
+	ID string `json:"id" api:"required"`
+}
+
 // UpdateSkillRequest represents the payload for updating a skill.
 type UpdateSkillRequest struct {
 	ID           string           `json:"id"`
@@ -199,7 +298,7 @@ type CreateSkillRequest struct {
 
 // CreateSkillResponse captures the identifier assigned to a newly created skill.
 type CreateSkillResponse struct {
-	ID string `json:"id"`
+	ID string `json:"id" api:"required"`
 }
 
 // CreateSkillParameterRequest represents payload to create a skill parameter.
@@ -210,7 +309,7 @@ type CreateSkillParameterRequest struct {
 
 // CreateSkillParameterResponse captures identifier assigned to a new parameter.
 type CreateSkillParameterResponse struct {
-	ID string `json:"id"`
+	ID string `json:"id" api:"required"`
 }
 
 // PublishFlowRequest represents the payload used to publish a flow.
@@ -0,0 +1,86 @@
+// Package redact masks secrets in text before it reaches the terminal or CI logs.
+// Built-in patterns cover bearer tokens and common API key formats; newo.toml can add more
+// under [redact].patterns. A pattern with a capture group masks only the first group, so
+// `api_key\s*=\s*"([^"]+)"` keeps the key name visible.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+var builtinPatterns = []string{
+	`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{8,}=*)`,
+	`\bsk-[A-Za-z0-9_-]{16,}`,
+	`(?i)"(?:access|refresh)_token"\s*:\s*"([^"]+)"`,
+}
+
+var (
+	mu     sync.RWMutex
+	active = mustCompile(builtinPatterns)
+)
+
+// Configure replaces the active filters with the built-in patterns plus the given ones.
+func Configure(patterns []string) error {
+	compiled := mustCompile(builtinPatterns)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	mu.Lock()
+	active = compiled
+	mu.Unlock()
+	return nil
+}
+
+// String returns s with every match of the active patterns masked.
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	mu.RLock()
+	patterns := active
+	mu.RUnlock()
+	for _, re := range patterns {
+		s = apply(re, s)
+	}
+	return s
+}
+
+func apply(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, Mask)
+	}
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	out := make([]byte, 0, len(s))
+	last := 0
+	for _, m := range matches {
+		start, end := m[2], m[3]
+		if start < 0 {
+			start, end = m[0], m[1]
+		}
+		out = append(out, s[last:start]...)
+		out = append(out, Mask...)
+		last = end
+	}
+	out = append(out, s[last:]...)
+	return string(out)
+}
+
+func mustCompile(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		compiled = append(compiled, regexp.MustCompile(pattern))
+	}
+	return compiled
+}
@@ -1,6 +1,7 @@
 package serialize
 
 import (
+	"bytes"
 	"fmt"
 
 	"gopkg.in/yaml.v3"
@@ -48,6 +49,7 @@ type skillMetadata struct {
 	Model      platform.ModelConfig `yaml:"model"`
 	Parameters []parameterMetadata  `yaml:"parameters"`
 	Path       string               `yaml:"path,omitempty"`
+	Tags       []string             `yaml:"tags,omitempty"`
 }
 
 // ProjectMetadata converts a project to YAML bytes.
@@ -91,6 +93,12 @@ func FlowMetadata(flow platform.Flow, events []platform.FlowEvent, states []plat
 
 // SkillMetadata converts a skill to YAML bytes.
 func SkillMetadata(skill platform.Skill) ([]byte, error) {
+	return TaggedSkillMetadata(skill, nil)
+}
+
+// TaggedSkillMetadata converts a skill to YAML bytes with the workspace tags of the skill, which the
+// platform does not store.
+func TaggedSkillMetadata(skill platform.Skill, tags []string) ([]byte, error) {
 	params := make([]parameterMetadata, 0, len(skill.Parameters))
 	for _, p := range skill.Parameters {
 		params = append(params, parameterMetadata{
@@ -107,10 +115,24 @@ func SkillMetadata(skill platform.Skill) ([]byte, error) {
 		Model:      skill.Model,
 		Parameters: params,
 		Path:       skill.Path,
+		Tags:       tags,
 	}
 	return marshal(payload)
 }
 
+// SameSkillMetadata reports whether two skill .meta.yaml documents hold the same values, however they
+// are formatted.
+func SameSkillMetadata(a, b []byte) bool {
+	var left, right skillMetadata
+	if yaml.Unmarshal(a, &left) != nil || yaml.Unmarshal(b, &right) != nil {
+		return false
+	}
+	// Encoding both again renders an empty list and a missing one alike.
+	leftData, leftErr := yaml.Marshal(left)
+	rightData, rightErr := yaml.Marshal(right)
+	return leftErr == nil && rightErr == nil && bytes.Equal(leftData, rightData)
+}
+
 func marshal(value any) ([]byte, error) {
 	data, err := yaml.Marshal(value)
 	if err != nil {
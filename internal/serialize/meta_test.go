@@ -22,3 +22,29 @@ func TestProjectMetadataYAML(t *testing.T) {
 		t.Fatalf("unexpected idn: %#v", node)
 	}
 }
+
+func TestTaggedSkillMetadataRoundTripsTags(t *testing.T) {
+	skill := platform.Skill{ID: "id", IDN: "skill", RunnerType: "nsl"}
+	tagged, err := TaggedSkillMetadata(skill, []string{"checkout"})
+	if err != nil {
+		t.Fatalf("TaggedSkillMetadata: %v", err)
+	}
+	var node map[string]any
+	if err := yaml.Unmarshal(tagged, &node); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if tags, ok := node["tags"].([]any); !ok || len(tags) != 1 || tags[0] != "checkout" {
+		t.Fatalf("unexpected tags: %#v", node["tags"])
+	}
+
+	plain, err := SkillMetadata(skill)
+	if err != nil {
+		t.Fatalf("SkillMetadata: %v", err)
+	}
+	if SameSkillMetadata(plain, tagged) {
+		t.Fatalf("expected metadata with and without tags to differ")
+	}
+	if !SameSkillMetadata(tagged, []byte("idn: skill\nid: id\nrunner_type: nsl\ntags: [checkout]\n")) {
+		t.Fatalf("expected reformatted metadata to match")
+	}
+}
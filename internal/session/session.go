@@ -8,6 +8,7 @@ import (
 	"github.com/twinmind/newo-tool/internal/auth"
 	"github.com/twinmind/newo-tool/internal/config"
 	"github.com/twinmind/newo-tool/internal/customer"
+	"github.com/twinmind/newo-tool/internal/logging"
 	"github.com/twinmind/newo-tool/internal/platform"
 	"github.com/twinmind/newo-tool/internal/state"
 )
@@ -24,8 +25,17 @@ type Session struct {
 	CustomerType    string // Added to hold customer type
 }
 
+// BaseURL returns the platform URL for a customer: its own base_url from newo.toml, or the global one.
+func BaseURL(env config.Env, entry customer.Entry) string {
+	if base := strings.TrimSpace(entry.BaseURL); base != "" {
+		return base
+	}
+	return env.BaseURL
+}
+
 // New creates a new authenticated session for a given customer entry.
 func New(ctx context.Context, env config.Env, entry customer.Entry, registry *state.APIKeyRegistry) (*Session, error) {
+	baseURL := BaseURL(env, entry)
 	knownIDN := strings.TrimSpace(entry.HintIDN)
 	if knownIDN == "" {
 		if idn, ok := registry.Lookup(entry.APIKey); ok {
@@ -46,12 +56,13 @@ func New(ctx context.Context, env config.Env, entry customer.Entry, registry *st
 		}
 	}
 
+	logging.Debug("opening session", "customer", knownIDN, "cached_tokens", haveTokens)
+
 	refreshed := false
 	if haveTokens && tokens.IsExpired() && tokens.CanRefresh() && env.RefreshURL != "" {
-		// Verbose logging should be handled by the caller
 		resp, err := platform.RefreshAccessToken(ctx, env.RefreshURL, tokens.RefreshToken)
 		if err != nil {
-			// Log warning in caller
+			logging.Warn("token refresh failed; falling back to api key exchange", "customer", knownIDN, "error", err.Error())
 		} else {
 			fresh, convErr := auth.FromResponse(resp)
 			if convErr != nil {
@@ -63,8 +74,8 @@ func New(ctx context.Context, env config.Env, entry customer.Entry, registry *st
 	}
 
 	if !haveTokens || tokens.IsExpired() {
-		// Verbose logging in caller
-		resp, err := platform.ExchangeAPIKeyForToken(ctx, env.BaseURL, entry.APIKey)
+		logging.Debug("exchanging api key for tokens", "customer", knownIDN)
+		resp, err := platform.ExchangeAPIKeyForToken(ctx, baseURL, entry.APIKey)
 		if err != nil {
 			return nil, fmt.Errorf("exchange api key: %w", err)
 		}
@@ -76,7 +87,25 @@ func New(ctx context.Context, env config.Env, entry customer.Entry, registry *st
 		refreshed = true
 	}
 
-	client, err := platform.NewClient(env.BaseURL, tokens.AccessToken)
+	// customerIDN is filled in once the profile is known; renewals before that are not persisted.
+	customerIDN := knownIDN
+	current := tokens
+	renew := func(ctx context.Context) (string, error) {
+		fresh, err := renewTokens(ctx, env, baseURL, entry.APIKey, current)
+		if err != nil {
+			return "", err
+		}
+		current = fresh
+		if customerIDN != "" {
+			if err := auth.Save(strings.ToLower(customerIDN), fresh); err != nil {
+				return "", fmt.Errorf("persist tokens: %w", err)
+			}
+		}
+		logging.Info("access token renewed", "customer", customerIDN)
+		return fresh.AccessToken, nil
+	}
+
+	client, err := platform.NewClient(baseURL, tokens.AccessToken, platform.WithTokenRenewer(renew), platform.WithAPIVersion(entry.APIVersion))
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +118,9 @@ func New(ctx context.Context, env config.Env, entry customer.Entry, registry *st
 		return nil, fmt.Errorf("customer profile response missing idn")
 	}
 
+	customerIDN = profile.IDN
+	tokens = current
+
 	registryUpdated := false
 	if knownIDN == "" || !strings.EqualFold(knownIDN, profile.IDN) {
 		registry.Register(entry.APIKey, profile.IDN)
@@ -101,6 +133,8 @@ func New(ctx context.Context, env config.Env, entry customer.Entry, registry *st
 		}
 	}
 
+	logging.Info("session ready", "customer", profile.IDN, "type", entry.Type, "tokens_refreshed", refreshed)
+
 	return &Session{
 		IDN:             profile.IDN,
 		ProjectID:       entry.ProjectID,
@@ -112,3 +146,21 @@ func New(ctx context.Context, env config.Env, entry customer.Entry, registry *st
 		CustomerType:    entry.Type,
 	}, nil
 }
+
+// renewTokens replaces tokens the platform rejected mid-run. The refresh token is tried first when a
+// refresh endpoint is configured; the API key exchange is the fallback because it always works
+// while the key is valid.
+func renewTokens(ctx context.Context, env config.Env, baseURL, apiKey string, current auth.Tokens) (auth.Tokens, error) {
+	if current.CanRefresh() && env.RefreshURL != "" {
+		resp, err := platform.RefreshAccessToken(ctx, env.RefreshURL, current.RefreshToken)
+		if err == nil {
+			return auth.FromResponse(resp)
+		}
+		logging.Warn("token refresh failed; falling back to api key exchange", "error", err.Error())
+	}
+	resp, err := platform.ExchangeAPIKeyForToken(ctx, baseURL, apiKey)
+	if err != nil {
+		return auth.Tokens{}, fmt.Errorf("exchange api key: %w", err)
+	}
+	return auth.FromResponse(resp)
+}
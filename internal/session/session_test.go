@@ -110,3 +110,50 @@ func TestNewSession(t *testing.T) {
 		}
 	})
 }
+
+func TestSessionRenewsRejectedToken(t *testing.T) {
+	exchanges := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/api/v1/auth/api-key/token"):
+			exchanges++
+			_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","refresh_token":"ref","expires_in":%d}`, exchanges, int(time.Hour.Seconds()))
+		case strings.HasSuffix(r.URL.Path, "/api/v1/customer/profile"):
+			_, _ = w.Write([]byte(`{"id":"cust_123","idn":"ACME"}`))
+		case strings.HasSuffix(r.URL.Path, "/api/v1/designer/projects"):
+			// The platform revokes the first token halfway through the run.
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client, transport := httpmock.New(handler)
+	t.Cleanup(platform.SetHTTPClientForTesting(client))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	tmp := t.TempDir()
+	wd, _ := os.Getwd()
+	_ = os.Chdir(tmp)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	env := config.Env{BaseURL: httpmock.BaseURL}
+	s, err := New(context.Background(), env, customer.Entry{APIKey: "test-key"}, state.NewAPIKeyRegistry())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := s.Client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects after revocation: %v", err)
+	}
+	if exchanges != 2 {
+		t.Fatalf("expected the api key to be exchanged again, got %d exchanges", exchanges)
+	}
+	stored, ok, err := auth.Load("acme")
+	if err != nil || !ok || stored.AccessToken != "token-2" {
+		t.Fatalf("expected renewed token to be persisted, got %+v (ok=%v, err=%v)", stored, ok, err)
+	}
+}
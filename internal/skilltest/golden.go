@@ -0,0 +1,80 @@
+package skilltest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// GoldenDir is the directory next to the suites that holds recorded outputs.
+const GoldenDir = "golden"
+
+// GoldenPath returns the file recording the expected output of a case:
+// golden/<suite file name>/<case name>.txt next to the suite.
+func GoldenPath(suite Suite, tc Case) string {
+	dir := filepath.Dir(filepath.FromSlash(suite.Path))
+	name := strings.TrimSuffix(filepath.Base(suite.Path), filepath.Ext(suite.Path))
+	return filepath.Join(dir, GoldenDir, name, caseSlug(tc.Name)+".txt")
+}
+
+// checkGolden records the output of a case or compares it with the recorded one. A case without a
+// golden file passes until the snapshots are updated.
+func checkGolden(result *Result, suite Suite, tc Case, update bool) error {
+	path := GoldenPath(suite, tc)
+	result.Golden = filepath.ToSlash(path)
+	recorded, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read golden file %s: %w", result.Golden, err)
+	}
+	exists := err == nil
+
+	if update {
+		if exists && string(recorded) == result.Output {
+			return nil
+		}
+		if err := fsutil.EnsureParentDir(path); err != nil {
+			return err
+		}
+		if err := fsutil.AtomicWrite(path, []byte(result.Output), fsutil.FilePerm); err != nil {
+			return fmt.Errorf("write golden file %s: %w", result.Golden, err)
+		}
+		result.Updated = true
+		return nil
+	}
+
+	if !exists {
+		result.Golden = ""
+		return nil
+	}
+	if string(recorded) == result.Output {
+		return nil
+	}
+	result.Diff = diff.Generate(recorded, []byte(result.Output), 3)
+	return fmt.Errorf("output differs from %s; run `newo test --update` if the change is intended", result.Golden)
+}
+
+// caseSlug turns a case name into a file name: lower case letters and digits separated by dashes.
+func caseSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return "case"
+	}
+	return b.String()
+}
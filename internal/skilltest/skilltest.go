@@ -0,0 +1,228 @@
+// Package skilltest loads per-skill test suites and checks skill output against their expectations.
+package skilltest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/diff"
+)
+
+// Modes select how the cases of a suite are executed.
+const (
+	// ModeRender renders the local NSL script with the case variables.
+	ModeRender = "render"
+	// ModeExec runs the pushed skill on the platform.
+	ModeExec = "exec"
+)
+
+// Suite is one test file: a skill and the cases run against it.
+type Suite struct {
+	Path  string `yaml:"-"`
+	Skill string `yaml:"skill"`
+	Mode  string `yaml:"mode"`
+	Cases []Case `yaml:"cases"`
+}
+
+// Case is a single input and the assertions on the output it produces.
+type Case struct {
+	Name       string         `yaml:"name"`
+	Parameters map[string]any `yaml:"parameters"`
+	Context    map[string]any `yaml:"context"`
+	Expect     Expectation    `yaml:"expect"`
+}
+
+// Expectation lists the assertions on a skill's output. Every assertion must hold.
+type Expectation struct {
+	Equals      *string  `yaml:"equals"`
+	Contains    []string `yaml:"contains"`
+	NotContains []string `yaml:"not_contains"`
+	Matches     []string `yaml:"matches"`
+	NotMatches  []string `yaml:"not_matches"`
+}
+
+// Result is the outcome of one case. Diff is set when the output no longer matches the case's golden file.
+type Result struct {
+	Suite    string
+	Skill    string
+	Case     string
+	Output   string
+	Failures []string
+	Golden   string
+	Diff     []diff.Line
+	Updated  bool
+	Duration time.Duration
+}
+
+// Options control how suites are run.
+type Options struct {
+	// Snapshots compares the output of render-mode cases with their golden files when one exists.
+	Snapshots bool
+	// UpdateSnapshots writes the output of render-mode cases to their golden files instead of comparing.
+	UpdateSnapshots bool
+}
+
+// Passed reports whether every assertion of the case held.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Executor produces the output of a skill for one case.
+type Executor func(ctx context.Context, suite Suite, tc Case) (string, error)
+
+// LoadSuites reads every .yaml and .yml file in dir, sorted by name. A missing directory yields no suites.
+func LoadSuites(dir string) ([]Suite, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", filepath.ToSlash(dir), err)
+	}
+
+	var suites []Suite
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		suite, err := LoadSuite(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+	}
+	sort.Slice(suites, func(i, j int) bool { return suites[i].Path < suites[j].Path })
+	return suites, nil
+}
+
+// LoadSuite reads and validates a single test file. Unknown keys are rejected so that a misspelled
+// assertion does not silently pass.
+func LoadSuite(path string) (Suite, error) {
+	normalized := filepath.ToSlash(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Suite{}, fmt.Errorf("read %s: %w", normalized, err)
+	}
+	var suite Suite
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&suite); err != nil {
+		return Suite{}, fmt.Errorf("decode %s: %w", normalized, err)
+	}
+	suite.Path = normalized
+	suite.Skill = strings.TrimSpace(suite.Skill)
+	suite.Mode = strings.ToLower(strings.TrimSpace(suite.Mode))
+	if suite.Mode == "" {
+		suite.Mode = ModeRender
+	}
+
+	if suite.Skill == "" {
+		return Suite{}, fmt.Errorf("%s: skill is required", normalized)
+	}
+	if suite.Mode != ModeRender && suite.Mode != ModeExec {
+		return Suite{}, fmt.Errorf("%s: unsupported mode %q (expected %s or %s)", normalized, suite.Mode, ModeRender, ModeExec)
+	}
+	if len(suite.Cases) == 0 {
+		return Suite{}, fmt.Errorf("%s: no cases defined", normalized)
+	}
+	slugs := map[string]string{}
+	for idx := range suite.Cases {
+		tc := &suite.Cases[idx]
+		if strings.TrimSpace(tc.Name) == "" {
+			tc.Name = fmt.Sprintf("case %d", idx+1)
+		}
+		slug := caseSlug(tc.Name)
+		if other, exists := slugs[slug]; exists {
+			return Suite{}, fmt.Errorf("%s: cases %q and %q share the golden file name %s", normalized, other, tc.Name, slug)
+		}
+		slugs[slug] = tc.Name
+		for _, pattern := range append(append([]string{}, tc.Expect.Matches...), tc.Expect.NotMatches...) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return Suite{}, fmt.Errorf("%s: %s: invalid pattern %q: %w", normalized, tc.Name, pattern, err)
+			}
+		}
+	}
+	return suite, nil
+}
+
+// Variables returns the values a case exposes to a rendered template: the context entries, overridden
+// by parameters of the same name.
+func (tc Case) Variables() map[string]any {
+	vars := make(map[string]any, len(tc.Context)+len(tc.Parameters))
+	for name, value := range tc.Context {
+		vars[name] = value
+	}
+	for name, value := range tc.Parameters {
+		vars[name] = value
+	}
+	return vars
+}
+
+// Check returns a message for every assertion the output violates.
+func (e Expectation) Check(output string) []string {
+	var failures []string
+	if e.Equals != nil && output != *e.Equals {
+		failures = append(failures, fmt.Sprintf("output does not equal %q", *e.Equals))
+	}
+	for _, want := range e.Contains {
+		if !strings.Contains(output, want) {
+			failures = append(failures, fmt.Sprintf("output does not contain %q", want))
+		}
+	}
+	for _, unwanted := range e.NotContains {
+		if strings.Contains(output, unwanted) {
+			failures = append(failures, fmt.Sprintf("output contains %q", unwanted))
+		}
+	}
+	for _, pattern := range e.Matches {
+		if !regexp.MustCompile(pattern).MatchString(output) {
+			failures = append(failures, fmt.Sprintf("output does not match /%s/", pattern))
+		}
+	}
+	for _, pattern := range e.NotMatches {
+		if regexp.MustCompile(pattern).MatchString(output) {
+			failures = append(failures, fmt.Sprintf("output matches /%s/", pattern))
+		}
+	}
+	return failures
+}
+
+// Run executes every case of the suites in order. Execution errors are reported as failures of the
+// case so that one broken skill does not stop the run.
+func Run(ctx context.Context, suites []Suite, execute Executor, opts Options) []Result {
+	var results []Result
+	for _, suite := range suites {
+		for _, tc := range suite.Cases {
+			if ctx.Err() != nil {
+				return results
+			}
+			started := time.Now()
+			output, err := execute(ctx, suite, tc)
+			result := Result{Suite: suite.Path, Skill: suite.Skill, Case: tc.Name, Output: output}
+			if err != nil {
+				result.Failures = []string{err.Error()}
+			} else {
+				result.Failures = tc.Expect.Check(output)
+				if suite.Mode == ModeRender && (opts.Snapshots || opts.UpdateSnapshots) {
+					if err := checkGolden(&result, suite, tc, opts.UpdateSnapshots); err != nil {
+						result.Failures = append(result.Failures, err.Error())
+					}
+				}
+			}
+			result.Duration = time.Since(started)
+			results = append(results, result)
+		}
+	}
+	return results
+}
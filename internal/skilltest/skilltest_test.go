@@ -0,0 +1,161 @@
+package skilltest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSuite(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestLoadSuites(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeSuite(t, dir, "b.yaml", `
+skill: MainFlow/Greeting
+cases:
+  - name: by name
+    parameters: {name: Ada}
+    expect:
+      contains: ["Ada"]
+  - expect:
+      matches: ["^Hi"]
+`)
+	writeSuite(t, dir, "a.yml", "skill: Farewell\nmode: exec\ncases:\n  - name: bye\n")
+	writeSuite(t, dir, "notes.txt", "ignored")
+
+	suites, err := LoadSuites(dir)
+	if err != nil {
+		t.Fatalf("LoadSuites: %v", err)
+	}
+	if len(suites) != 2 || suites[0].Skill != "Farewell" || suites[0].Mode != ModeExec {
+		t.Fatalf("unexpected suites: %#v", suites)
+	}
+	if suites[1].Mode != ModeRender || suites[1].Cases[1].Name != "case 2" {
+		t.Fatalf("defaults not applied: %#v", suites[1])
+	}
+
+	missing, err := LoadSuites(filepath.Join(dir, "missing"))
+	if err != nil || missing != nil {
+		t.Fatalf("missing directory: %#v, %v", missing, err)
+	}
+}
+
+func TestLoadSuiteRejectsInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"unknown key":   "skill: A\ncases:\n  - expect:\n      contain: [x]\n",
+		"missing skill": "cases:\n  - name: x\n",
+		"no cases":      "skill: A\n",
+		"bad mode":      "skill: A\nmode: live\ncases:\n  - name: x\n",
+		"bad pattern":   "skill: A\ncases:\n  - expect:\n      matches: ['(']\n",
+	}
+	for name, content := range tests {
+		dir := t.TempDir()
+		writeSuite(t, dir, "suite.yaml", content)
+		if _, err := LoadSuite(filepath.Join(dir, "suite.yaml")); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	equals := "Hello Ada"
+	suites := []Suite{{
+		Path:  "tests/greeting.yaml",
+		Skill: "Greeting",
+		Cases: []Case{
+			{Name: "pass", Parameters: map[string]any{"name": "Ada"}, Expect: Expectation{Equals: &equals, NotMatches: []string{`\d`}}},
+			{Name: "fail", Parameters: map[string]any{"name": "Bob"}, Expect: Expectation{Contains: []string{"Ada"}, NotContains: []string{"Bob"}}},
+			{Name: "error", Parameters: map[string]any{"name": ""}},
+		},
+	}}
+	execute := func(_ context.Context, _ Suite, tc Case) (string, error) {
+		name, _ := tc.Variables()["name"].(string)
+		if name == "" {
+			return "", errors.New("name is required")
+		}
+		return "Hello " + name, nil
+	}
+
+	results := Run(context.Background(), suites, execute, Options{})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if !results[0].Passed() {
+		t.Fatalf("expected first case to pass: %v", results[0].Failures)
+	}
+	if len(results[1].Failures) != 2 {
+		t.Fatalf("expected two failures, got %v", results[1].Failures)
+	}
+	if results[2].Passed() || !strings.Contains(results[2].Failures[0], "name is required") {
+		t.Fatalf("expected execution error, got %v", results[2].Failures)
+	}
+}
+
+func TestCaseVariablesPreferParameters(t *testing.T) {
+	t.Parallel()
+
+	tc := Case{
+		Parameters: map[string]any{"name": "param"},
+		Context:    map[string]any{"name": "context", "channel": "sms"},
+	}
+	vars := tc.Variables()
+	if vars["name"] != "param" || vars["channel"] != "sms" {
+		t.Fatalf("unexpected variables: %#v", vars)
+	}
+}
+
+func TestRunGoldenFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	output := "Hello Ada\n"
+	suites := []Suite{{
+		Path:  filepath.ToSlash(filepath.Join(dir, "greeting.yaml")),
+		Skill: "Greeting",
+		Mode:  ModeRender,
+		Cases: []Case{{Name: "Greets by name!"}},
+	}}
+	execute := func(context.Context, Suite, Case) (string, error) { return output, nil }
+
+	results := Run(context.Background(), suites, execute, Options{Snapshots: true})
+	if !results[0].Passed() || results[0].Golden != "" {
+		t.Fatalf("case without golden file should pass: %#v", results[0])
+	}
+
+	results = Run(context.Background(), suites, execute, Options{UpdateSnapshots: true})
+	golden := filepath.Join(dir, GoldenDir, "greeting", "greets-by-name.txt")
+	if data, err := os.ReadFile(golden); err != nil || string(data) != output || !results[0].Updated {
+		t.Fatalf("golden file not written: %q, %v, %#v", data, err, results[0])
+	}
+
+	results = Run(context.Background(), suites, execute, Options{Snapshots: true})
+	if !results[0].Passed() || results[0].Updated {
+		t.Fatalf("unchanged output should pass: %#v", results[0])
+	}
+
+	output = "Hello Bob\n"
+	results = Run(context.Background(), suites, execute, Options{Snapshots: true})
+	if results[0].Passed() || len(results[0].Diff) == 0 {
+		t.Fatalf("changed output should fail with a diff: %#v", results[0])
+	}
+
+	suites[0].Mode = ModeExec
+	results = Run(context.Background(), suites, execute, Options{Snapshots: true})
+	if !results[0].Passed() {
+		t.Fatalf("exec suites are not snapshotted: %#v", results[0])
+	}
+}
@@ -5,28 +5,41 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/twinmind/newo-tool/internal/fsutil"
 	"github.com/twinmind/newo-tool/internal/util"
 )
 
+// registryLockWait bounds how long Save waits for another process saving the registry.
+const registryLockWait = 10 * time.Second
+
 // APIKeyRegistry keeps a mapping between API key fingerprints and customer IDNs.
 type APIKeyRegistry struct {
 	entries map[string]string
+	// registered holds the entries added since loading, which Save merges into the file on disk.
+	registered map[string]string
 }
 
 // NewAPIKeyRegistry creates a new, empty registry.
 func NewAPIKeyRegistry() *APIKeyRegistry {
-	return &APIKeyRegistry{entries: map[string]string{}}
+	return &APIKeyRegistry{entries: map[string]string{}, registered: map[string]string{}}
 }
 
 // LoadAPIKeyRegistry returns the persisted API key registry.
 func LoadAPIKeyRegistry() (*APIKeyRegistry, error) {
-	path := fsutil.APIKeyRegistryPath()
+	entries, err := readAPIKeyRegistry(fsutil.APIKeyRegistryPath())
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyRegistry{entries: entries, registered: map[string]string{}}, nil
+}
+
+func readAPIKeyRegistry(path string) (map[string]string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return NewAPIKeyRegistry(), nil
+			return map[string]string{}, nil
 		}
 		return nil, fmt.Errorf("read api key registry: %w", err)
 	}
@@ -35,25 +48,42 @@ func LoadAPIKeyRegistry() (*APIKeyRegistry, error) {
 	if err := json.Unmarshal(data, &payload); err != nil {
 		return nil, fmt.Errorf("decode api key registry: %w", err)
 	}
-	return &APIKeyRegistry{entries: payload}, nil
+	if payload == nil {
+		payload = map[string]string{}
+	}
+	return payload, nil
 }
 
-// Save persists the registry.
-func (r *APIKeyRegistry) Save() error {
-	if r.entries == nil {
-		r.entries = map[string]string{}
-	}
+// Save persists the registry. The registry is shared by every customer and parallel runs, so Save
+// holds the registry lock while it re-reads the file, merges in the entries registered since loading,
+// and replaces the file atomically; entries saved by other runs in the meantime are kept.
+func (r *APIKeyRegistry) Save() (err error) {
 	path := fsutil.APIKeyRegistryPath()
-	if err := fsutil.EnsureParentDir(path); err != nil {
+	release, err := fsutil.AcquireFileLock(path+".lock", "save api key registry", registryLockWait)
+	if err != nil {
+		return fmt.Errorf("lock api key registry: %w", err)
+	}
+	defer func() {
+		if releaseErr := release(); err == nil {
+			err = releaseErr
+		}
+	}()
+
+	entries, err := readAPIKeyRegistry(path)
+	if err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(r.entries, "", "  ")
+	for key, idn := range r.registered {
+		entries[key] = idn
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode api key registry: %w", err)
 	}
-	if err := os.WriteFile(path, data, fsutil.FilePerm); err != nil {
+	if err := fsutil.AtomicWrite(path, data, fsutil.FilePerm); err != nil {
 		return fmt.Errorf("write api key registry: %w", err)
 	}
+	r.entries = entries
 	return nil
 }
 
@@ -71,7 +101,11 @@ func (r *APIKeyRegistry) Register(apiKey, customerIDN string) {
 	if r.entries == nil {
 		r.entries = map[string]string{}
 	}
+	if r.registered == nil {
+		r.registered = map[string]string{}
+	}
 	r.entries[hashKey(apiKey)] = customerIDN
+	r.registered[hashKey(apiKey)] = customerIDN
 }
 
 func hashKey(apiKey string) string {
@@ -0,0 +1,34 @@
+package state
+
+import "testing"
+
+func TestAPIKeyRegistrySaveKeepsEntriesOfConcurrentRuns(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	first, err := LoadAPIKeyRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadAPIKeyRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Register("key-a", "CUST_A")
+	second.Register("key-b", "CUST_B")
+	if err := first.Save(); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	if err := second.Save(); err != nil {
+		t.Fatalf("second save: %v", err)
+	}
+
+	loaded, err := LoadAPIKeyRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"key-a": "CUST_A", "key-b": "CUST_B"} {
+		if got, ok := loaded.Lookup(key); !ok || got != want {
+			t.Fatalf("Lookup(%s) = %q, %v; want %q", key, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// maxChangelogEntries bounds how many remote changes are kept in the changelog.
+const maxChangelogEntries = 2000
+
+// Changelog lists remote skill changes detected by pull, oldest first.
+type Changelog struct {
+	Entries []ChangelogEntry `json:"entries"`
+}
+
+// ChangelogEntry records a skill script that changed on the platform since the previous sync.
+type ChangelogEntry struct {
+	PulledAt   time.Time `json:"pulled_at"`
+	ProjectIDN string    `json:"project_idn"`
+	AgentIDN   string    `json:"agent_idn"`
+	FlowIDN    string    `json:"flow_idn"`
+	SkillIDN   string    `json:"skill_idn"`
+	Path       string    `json:"path"`
+	OldHash    string    `json:"old_hash"`
+	NewHash    string    `json:"new_hash"`
+	UpdatedAt  string    `json:"updated_at,omitempty"`
+	Size       int       `json:"size"`
+	// SizeDelta is nil when the size of the previous version is unknown.
+	SizeDelta *int `json:"size_delta,omitempty"`
+}
+
+// LoadChangelog returns the changelog stored for the customer, or an empty one.
+func LoadChangelog(customerIDN string) (Changelog, error) {
+	data, err := os.ReadFile(fsutil.ChangelogPath(customerIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Changelog{}, nil
+		}
+		return Changelog{}, fmt.Errorf("read changelog: %w", err)
+	}
+
+	var changelog Changelog
+	if err := json.Unmarshal(data, &changelog); err != nil {
+		return Changelog{}, fmt.Errorf("decode changelog: %w", err)
+	}
+	for i := range changelog.Entries {
+		changelog.Entries[i].Path = migratePath(changelog.Entries[i].Path)
+	}
+	return changelog, nil
+}
+
+// SaveChangelog persists the changelog.
+func SaveChangelog(customerIDN string, changelog Changelog) error {
+	path := fsutil.ChangelogPath(customerIDN)
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(changelog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode changelog: %w", err)
+	}
+	if err := fsutil.AtomicWrite(path, data, fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write changelog: %w", err)
+	}
+	return nil
+}
+
+// AppendChangelog adds entries to the customer's changelog, dropping the oldest entries beyond the retention limit.
+func AppendChangelog(customerIDN string, entries []ChangelogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	changelog, err := LoadChangelog(customerIDN)
+	if err != nil {
+		return err
+	}
+	changelog.Entries = append(changelog.Entries, entries...)
+	if extra := len(changelog.Entries) - maxChangelogEntries; extra > 0 {
+		changelog.Entries = changelog.Entries[extra:]
+	}
+	return SaveChangelog(customerIDN, changelog)
+}
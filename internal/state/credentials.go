@@ -0,0 +1,252 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// Credential backend names accepted in the [credentials] section of newo.toml.
+const (
+	CredentialBackendKeychain = "keychain"
+	CredentialBackendEnv      = "env"
+	CredentialBackendCommand  = "command"
+)
+
+// credentialService is the service name API keys are filed under in the OS keychain.
+const credentialService = "newo-tool"
+
+// ErrCredentialNotFound is returned when a backend holds no API key for the requested customer.
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// CredentialStore keeps customer API keys outside newo.toml. Accounts are customer IDNs.
+type CredentialStore interface {
+	Name() string
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// CredentialConfig selects and configures a credential backend. Get, Store, and Erase are shell
+// commands for the command backend; {account} is replaced with the quoted customer IDN. Store receives
+// the API key on stdin and Get prints it on stdout.
+type CredentialConfig struct {
+	Backend string
+	Get     string
+	Store   string
+	Erase   string
+}
+
+// OpenCredentialStore returns the configured backend, defaulting to the OS keychain.
+func OpenCredentialStore(cfg CredentialConfig) (CredentialStore, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(cfg.Backend)); backend {
+	case "", CredentialBackendKeychain:
+		return keychainStore{}, nil
+	case CredentialBackendEnv:
+		return envStore{}, nil
+	case CredentialBackendCommand:
+		if strings.TrimSpace(cfg.Get) == "" {
+			return nil, errors.New("credentials backend \"command\" requires a get command")
+		}
+		return commandStore{get: cfg.Get, store: cfg.Store, erase: cfg.Erase}, nil
+	default:
+		return nil, fmt.Errorf("unknown credentials backend %q (expected keychain, env, or command)", cfg.Backend)
+	}
+}
+
+// CredentialEnvVar returns the environment variable the env backend reads for a customer,
+// for example NEWO_API_KEY_ACME_PROD for "acme-prod".
+func CredentialEnvVar(account string) string {
+	var b strings.Builder
+	b.WriteString("NEWO_API_KEY_")
+	for _, r := range strings.TrimSpace(account) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToUpper(r))
+			continue
+		}
+		b.WriteByte('_')
+	}
+	return b.String()
+}
+
+// envStore reads API keys from per-customer environment variables; it cannot store them.
+type envStore struct{}
+
+func (envStore) Name() string { return CredentialBackendEnv }
+
+func (envStore) Get(account string) (string, error) {
+	if value := strings.TrimSpace(os.Getenv(CredentialEnvVar(account))); value != "" {
+		return value, nil
+	}
+	return "", ErrCredentialNotFound
+}
+
+func (envStore) Set(account, _ string) error {
+	return fmt.Errorf("the env credentials backend is read-only; export %s instead", CredentialEnvVar(account))
+}
+
+func (envStore) Delete(account string) error {
+	return fmt.Errorf("the env credentials backend is read-only; unset %s instead", CredentialEnvVar(account))
+}
+
+// keychainStore uses the macOS keychain through security(1) and the Secret Service through secret-tool(1).
+type keychainStore struct{}
+
+func (keychainStore) Name() string { return CredentialBackendKeychain }
+
+func (keychainStore) Get(account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", credentialService, "-a", account, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", credentialService, "account", account)
+	default:
+		return "", keychainUnsupported()
+	}
+	out, err := runCredentialCommand(cmd, "")
+	if err != nil {
+		// Both tools exit non-zero when no item matches.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrCredentialNotFound
+		}
+		return "", err
+	}
+	if out == "" {
+		return "", ErrCredentialNotFound
+	}
+	return out, nil
+}
+
+func (keychainStore) Set(account, secret string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// In interactive mode security(1) reads its commands from stdin, which keeps the key out of the
+		// process list, where -w on the command line would show it.
+		cmd = exec.Command("security", "-i")
+		secret = fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", securityQuote(credentialService), securityQuote(account), securityQuote(secret))
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "store", "--label", "NEWO API key ("+account+")", "service", credentialService, "account", account)
+	default:
+		return keychainUnsupported()
+	}
+	_, err := runCredentialCommand(cmd, secret)
+	return err
+}
+
+func (keychainStore) Delete(account string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "delete-generic-password", "-s", credentialService, "-a", account)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "clear", "service", credentialService, "account", account)
+	default:
+		return keychainUnsupported()
+	}
+	_, err := runCredentialCommand(cmd, "")
+	return err
+}
+
+// securityQuote quotes an argument of a security(1) interactive command.
+func securityQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func keychainUnsupported() error {
+	return fmt.Errorf("the keychain credentials backend is not supported on %s; use the env or command backend", runtime.GOOS)
+}
+
+// commandStore delegates to user-supplied shell commands such as `pass show newo/{account}`.
+type commandStore struct {
+	get   string
+	store string
+	erase string
+}
+
+func (commandStore) Name() string { return CredentialBackendCommand }
+
+func (s commandStore) Get(account string) (string, error) {
+	cmd, err := accountCommand(s.get, account)
+	if err != nil {
+		return "", err
+	}
+	out, err := runCredentialCommand(cmd, "")
+	if err != nil {
+		return "", fmt.Errorf("credentials get command: %w", err)
+	}
+	// Helpers such as pass may print extra lines after the secret.
+	secret, _, _ := strings.Cut(out, "\n")
+	if secret = strings.TrimSpace(secret); secret == "" {
+		return "", ErrCredentialNotFound
+	}
+	return secret, nil
+}
+
+func (s commandStore) Set(account, secret string) error {
+	if strings.TrimSpace(s.store) == "" {
+		return errors.New("credentials backend \"command\" has no store command configured")
+	}
+	cmd, err := accountCommand(s.store, account)
+	if err != nil {
+		return err
+	}
+	if _, err := runCredentialCommand(cmd, secret+"\n"); err != nil {
+		return fmt.Errorf("credentials store command: %w", err)
+	}
+	return nil
+}
+
+func (s commandStore) Delete(account string) error {
+	if strings.TrimSpace(s.erase) == "" {
+		return errors.New("credentials backend \"command\" has no erase command configured")
+	}
+	cmd, err := accountCommand(s.erase, account)
+	if err != nil {
+		return err
+	}
+	if _, err := runCredentialCommand(cmd, ""); err != nil {
+		return fmt.Errorf("credentials erase command: %w", err)
+	}
+	return nil
+}
+
+// accountCommand builds the shell command with {account} replaced by the quoted customer IDN, so an
+// IDN cannot inject shell syntax. cmd.exe has no reliable quoting, so there IDNs are limited to
+// letters, digits, dots, dashes, and underscores.
+func accountCommand(command, account string) (*exec.Cmd, error) {
+	if runtime.GOOS == "windows" {
+		for _, r := range account {
+			if !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) && !strings.ContainsRune(".-_", r) {
+				return nil, fmt.Errorf("customer %q cannot be passed to a credentials command", account)
+			}
+		}
+		return exec.Command("cmd", "/C", strings.ReplaceAll(command, "{account}", account)), nil
+	}
+	quoted := "'" + strings.ReplaceAll(account, "'", `'\''`) + "'"
+	return exec.Command("sh", "-c", strings.ReplaceAll(command, "{account}", quoted)), nil
+}
+
+// runCredentialCommand feeds stdin to cmd and returns its trimmed stdout. Stderr is folded into the
+// error so helper diagnostics are not lost, but the secret itself never is.
+func runCredentialCommand(cmd *exec.Cmd, stdin string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); msg != "" && errors.As(err, &exitErr) {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
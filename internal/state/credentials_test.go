@@ -0,0 +1,86 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCredentialEnvVar(t *testing.T) {
+	if got := CredentialEnvVar("acme-prod.v2"); got != "NEWO_API_KEY_ACME_PROD_V2" {
+		t.Fatalf("unexpected variable name %q", got)
+	}
+}
+
+func TestEnvCredentialStore(t *testing.T) {
+	store, err := OpenCredentialStore(CredentialConfig{Backend: "env"})
+	if err != nil {
+		t.Fatalf("OpenCredentialStore: %v", err)
+	}
+	t.Setenv("NEWO_API_KEY_ACME", "secret")
+	if got, err := store.Get("acme"); err != nil || got != "secret" {
+		t.Fatalf("expected secret, got %q (%v)", got, err)
+	}
+	if _, err := store.Get("other"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("expected ErrCredentialNotFound, got %v", err)
+	}
+	if err := store.Set("acme", "x"); err == nil {
+		t.Fatalf("expected env backend to be read-only")
+	}
+}
+
+func TestCommandCredentialStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	// {account} is replaced with a quoted word, so it stays outside the quotes around the directory.
+	file := "'" + dir + "'/{account}.key"
+	store, err := OpenCredentialStore(CredentialConfig{
+		Backend: "command",
+		Get:     "cat " + file + " 2>/dev/null || true",
+		Store:   "cat > " + file,
+		Erase:   "rm -f " + file,
+	})
+	if err != nil {
+		t.Fatalf("OpenCredentialStore: %v", err)
+	}
+
+	if _, err := store.Get("acme"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("expected ErrCredentialNotFound before store, got %v", err)
+	}
+	if err := store.Set("acme", "secret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, err := store.Get("acme"); err != nil || got != "secret" {
+		t.Fatalf("expected stored secret, got %q (%v)", got, err)
+	}
+	if err := store.Delete("acme"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get("acme"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("expected ErrCredentialNotFound after delete, got %v", err)
+	}
+
+	// The IDN is quoted, so shell syntax in it stays part of the file name.
+	t.Chdir(dir)
+	if err := store.Set("x'; touch pwned; '", "secret"); err != nil {
+		t.Fatalf("Set with quotes: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); !os.IsNotExist(err) {
+		t.Fatalf("expected the customer IDN not to run as a command, got %v", err)
+	}
+	unquoted, err := OpenCredentialStore(CredentialConfig{Backend: "command", Get: "echo {account}"})
+	if err != nil {
+		t.Fatalf("OpenCredentialStore: %v", err)
+	}
+	if got, err := unquoted.Get("acme; echo injected"); err != nil || got != "acme; echo injected" {
+		t.Fatalf("expected the IDN echoed as one argument, got %q (%v)", got, err)
+	}
+
+	if _, err := OpenCredentialStore(CredentialConfig{Backend: "vault"}); err == nil {
+		t.Fatalf("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,159 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// racyWindow is how close to the last save a file may have been modified before its cached hash is
+// distrusted; an edit within the same timestamp tick as the save would otherwise go unnoticed.
+const racyWindow = time.Second
+
+// FileHashCache remembers the size, modification time, and SHA-256 digest of exported files so
+// unchanged files can be compared by hash without reading them. A nil cache reads every file.
+type FileHashCache struct {
+	customerIDN string
+	mu          sync.Mutex
+	entries     map[string]fileHashEntry
+	dirty       bool
+}
+
+type fileHashEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Hash    string `json:"hash"`
+	// EOL is the line-ending mode the hash was computed under; empty for byte-for-byte hashes.
+	EOL string `json:"eol,omitempty"`
+}
+
+type fileHashCacheFile struct {
+	SavedAt time.Time                `json:"saved_at"`
+	Entries map[string]fileHashEntry `json:"entries"`
+}
+
+// LoadFileHashCache opens the customer's file hash cache. When no cache exists it returns nil unless
+// create is set, in which case an empty cache is returned and written on the next Save.
+func LoadFileHashCache(customerIDN string, create bool) (*FileHashCache, error) {
+	cache := &FileHashCache{customerIDN: customerIDN, entries: map[string]fileHashEntry{}}
+	data, err := os.ReadFile(fsutil.FileHashCachePath(customerIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			if !create {
+				return nil, nil
+			}
+			return cache, nil
+		}
+		return nil, fmt.Errorf("read file hash cache: %w", err)
+	}
+
+	var file fileHashCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		// The cache only saves work; a damaged one is rebuilt from scratch.
+		return cache, nil
+	}
+	cutoff := file.SavedAt.Add(-racyWindow).UnixNano()
+	for path, entry := range file.Entries {
+		if entry.ModTime < cutoff {
+			cache.entries[path] = entry
+		}
+	}
+	return cache, nil
+}
+
+// Hash returns the digest of the file at path, reading the file only when its size or modification
+// time differ from the cached entry. Errors from stat or read are returned unwrapped.
+func (c *FileHashCache) Hash(path string) (string, error) {
+	if c == nil {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return util.ContentHash(data), nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	key := filepath.ToSlash(path)
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.Size == info.Size() && entry.ModTime == info.ModTime().UnixNano() && entry.EOL == cacheEOL() {
+		return entry.Hash, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	hash := util.ContentHash(data)
+	c.store(key, info, hash)
+	return hash, nil
+}
+
+// Record stores the digest of a file that was just written with known content.
+func (c *FileHashCache) Record(path, hash string) {
+	if c == nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	c.store(filepath.ToSlash(path), info, hash)
+}
+
+func (c *FileHashCache) store(key string, info os.FileInfo, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = fileHashEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Hash: hash, EOL: cacheEOL()}
+	c.dirty = true
+}
+
+// cacheEOL is the line-ending mode recorded with cached hashes, so that changing autocrlf in newo.toml
+// rehashes every file.
+func cacheEOL() string {
+	if mode := util.LineEndings(); mode != util.AutoCRLFFalse {
+		return mode
+	}
+	return ""
+}
+
+// Save writes the cache if it changed. Entries of files that no longer exist are dropped.
+func (c *FileHashCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	for path := range c.entries {
+		if _, err := os.Stat(filepath.FromSlash(path)); os.IsNotExist(err) {
+			delete(c.entries, path)
+		}
+	}
+
+	data, err := json.Marshal(fileHashCacheFile{SavedAt: time.Now().UTC(), Entries: c.entries})
+	if err != nil {
+		return fmt.Errorf("encode file hash cache: %w", err)
+	}
+	path := fsutil.FileHashCachePath(c.customerIDN)
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	if err := fsutil.AtomicWrite(path, data, fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write file hash cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,52 @@
+package state
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+func TestFileHashCacheSkipsUnchangedFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if err := os.WriteFile("a.nsl", []byte("one"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes("a.nsl", past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := LoadFileHashCache("acme", true)
+	if err != nil {
+		t.Fatalf("LoadFileHashCache: %v", err)
+	}
+	if hash, err := cache.Hash("a.nsl"); err != nil || hash != util.SHA256String("one") {
+		t.Fatalf("unexpected hash %q, %v", hash, err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := LoadFileHashCache("acme", false)
+	if err != nil || reloaded == nil {
+		t.Fatalf("expected the saved cache to load, got %v, %v", reloaded, err)
+	}
+	// A cached entry is trusted while size and modification time match, so the file is not read.
+	reloaded.entries["a.nsl"] = fileHashEntry{Size: 3, ModTime: past.UnixNano(), Hash: "cached"}
+	if hash, _ := reloaded.Hash("a.nsl"); hash != "cached" {
+		t.Fatalf("expected the cached hash, got %q", hash)
+	}
+	if err := os.WriteFile("a.nsl", []byte("two"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := reloaded.Hash("a.nsl"); hash != util.SHA256String("two") {
+		t.Fatalf("expected a modified file to be re-read, got %q", hash)
+	}
+
+	if missing, err := LoadFileHashCache("other", false); err != nil || missing != nil {
+		t.Fatalf("expected no cache without create, got %v, %v", missing, err)
+	}
+}
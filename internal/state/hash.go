@@ -2,46 +2,216 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/util"
 )
 
 // HashStore maps relative file paths to their SHA-256 digest.
 type HashStore map[string]string
 
+// hashStoreVersion is the layout version recorded in the hash index.
+const hashStoreVersion = 1
+
+// hashIndex lists the buckets of the on-disk hash store together with the digest of each bucket file,
+// so a save only rewrites the buckets whose entries changed.
+type hashIndex struct {
+	Version int               `json:"version"`
+	Buckets map[string]string `json:"buckets"`
+}
+
+// hashBucket spreads paths over 256 bucket files by the first byte of their digest.
+func hashBucket(path string) string {
+	return util.SHA256String(path)[:2]
+}
+
+func hashBucketPath(customerIDN, bucket string) string {
+	return filepath.Join(fsutil.HashesDir(customerIDN), bucket+".json")
+}
+
+func hashIndexPath(customerIDN string) string {
+	return filepath.Join(fsutil.HashesDir(customerIDN), fsutil.HashIndexJSON)
+}
+
 // LoadHashes returns hashes stored for the customer, or an empty map if none exist.
+// A legacy hashes.json file is migrated to the bucketed store on first load.
 func LoadHashes(customerIDN string) (HashStore, error) {
-	path := fsutil.HashesPath(customerIDN)
-	data, err := os.ReadFile(path)
+	index, err := loadHashIndex(customerIDN)
+	if errors.Is(err, os.ErrNotExist) {
+		return migrateLegacyHashes(customerIDN)
+	}
 	if err != nil {
-		if os.IsNotExist(err) {
-			return HashStore{}, nil
-		}
-		return nil, fmt.Errorf("read hashes: %w", err)
+		return nil, err
 	}
 
-	var hashes HashStore
-	if err := json.Unmarshal(data, &hashes); err != nil {
-		return nil, fmt.Errorf("decode hashes: %w", err)
+	hashes := HashStore{}
+	for bucket := range index.Buckets {
+		data, err := os.ReadFile(hashBucketPath(customerIDN, bucket))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read hashes: %w", err)
+		}
+		var entries HashStore
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("decode hashes bucket %s: %w", bucket, err)
+		}
+		for path, hash := range entries {
+			hashes[path] = hash
+		}
+	}
+	if migrateHashPaths(hashes) {
+		if err := SaveHashes(customerIDN, hashes); err != nil {
+			return nil, fmt.Errorf("migrate hashes: %w", err)
+		}
 	}
 	return hashes, nil
 }
 
-// SaveHashes persists the given hash store.
+// migrateHashPaths rewrites the absolute paths that older versions recorded for an absolute output
+// root as workspace-relative ones. It reports whether any path changed.
+func migrateHashPaths(hashes HashStore) bool {
+	changed := false
+	for path, hash := range hashes {
+		if !filepath.IsAbs(filepath.FromSlash(path)) {
+			continue
+		}
+		delete(hashes, path)
+		hashes[fsutil.AnchorStatePath(filepath.FromSlash(path))] = hash
+		changed = true
+	}
+	return changed
+}
+
+// SaveHashes persists the given hash store. Only buckets whose content changed since the last save are
+// written; the index is replaced last so an interrupted save never points at a missing bucket.
 func SaveHashes(customerIDN string, hashes HashStore) error {
-	path := fsutil.HashesPath(customerIDN)
-	if err := fsutil.EnsureParentDir(path); err != nil {
+	if err := fsutil.EnsureDir(fsutil.HashesDir(customerIDN)); err != nil {
+		return err
+	}
+
+	buckets := map[string]HashStore{}
+	for path, hash := range hashes {
+		bucket := hashBucket(path)
+		if buckets[bucket] == nil {
+			buckets[bucket] = HashStore{}
+		}
+		buckets[bucket][path] = hash
+	}
+
+	previous, err := loadHashIndex(customerIDN)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
+	next := hashIndex{Version: hashStoreVersion, Buckets: map[string]string{}}
+	for bucket, entries := range buckets {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode hashes: %w", err)
+		}
+		digest := util.SHA256Bytes(data)
+		next.Buckets[bucket] = digest
+		if previous.Buckets[bucket] == digest {
+			if _, err := os.Stat(hashBucketPath(customerIDN, bucket)); err == nil {
+				continue
+			}
+		}
+		if err := fsutil.AtomicWrite(hashBucketPath(customerIDN, bucket), data, fsutil.FilePerm); err != nil {
+			return fmt.Errorf("write hashes: %w", err)
+		}
+	}
 
-	data, err := json.MarshalIndent(hashes, "", "  ")
+	data, err := json.MarshalIndent(next, "", "  ")
 	if err != nil {
-		return fmt.Errorf("encode hashes: %w", err)
+		return fmt.Errorf("encode hash index: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return fmt.Errorf("write hashes: %w", err)
+	if err := fsutil.AtomicWrite(hashIndexPath(customerIDN), data, fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write hash index: %w", err)
+	}
+
+	for bucket := range previous.Buckets {
+		if _, kept := next.Buckets[bucket]; kept {
+			continue
+		}
+		if err := os.Remove(hashBucketPath(customerIDN, bucket)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove hashes bucket %s: %w", bucket, err)
+		}
+	}
+	if err := os.Remove(fsutil.HashesPath(customerIDN)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove legacy hashes: %w", err)
 	}
 	return nil
 }
+
+// RemoveHashes deletes the customer's hash store, including a legacy hashes.json file.
+func RemoveHashes(customerIDN string) error {
+	if err := os.RemoveAll(fsutil.HashesDir(customerIDN)); err != nil {
+		return fmt.Errorf("remove hashes: %w", err)
+	}
+	if err := os.Remove(fsutil.HashesPath(customerIDN)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove legacy hashes: %w", err)
+	}
+	return nil
+}
+
+// hashesExist reports whether the customer has a hash store in either layout.
+func hashesExist(customerIDN string) bool {
+	if _, err := os.Stat(hashIndexPath(customerIDN)); err == nil {
+		return true
+	}
+	_, err := os.Stat(fsutil.HashesPath(customerIDN))
+	return err == nil
+}
+
+func loadHashIndex(customerIDN string) (hashIndex, error) {
+	data, err := os.ReadFile(hashIndexPath(customerIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return hashIndex{}, err
+		}
+		return hashIndex{}, fmt.Errorf("read hash index: %w", err)
+	}
+	var index hashIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return hashIndex{}, fmt.Errorf("decode hash index: %w", err)
+	}
+	if index.Version > hashStoreVersion {
+		return hashIndex{}, fmt.Errorf("hash store version %d is newer than supported (%d); upgrade newo", index.Version, hashStoreVersion)
+	}
+	return index, nil
+}
+
+// migrateLegacyHashes reads the single-file hashes.json written by older versions and stores it in
+// the bucketed layout. Without a legacy file an empty store is returned.
+func migrateLegacyHashes(customerIDN string) (HashStore, error) {
+	data, err := os.ReadFile(fsutil.HashesPath(customerIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return HashStore{}, nil
+		}
+		return nil, fmt.Errorf("read hashes: %w", err)
+	}
+
+	hashes := HashStore{}
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, fmt.Errorf("decode hashes: %w", err)
+	}
+	migrateHashPaths(hashes)
+	if err := SaveHashes(customerIDN, hashes); err != nil {
+		return nil, fmt.Errorf("migrate hashes: %w", err)
+	}
+	return hashes, nil
+}
+
+// migratePath rewrites an absolute path recorded by an older version as a workspace-relative one.
+func migratePath(path string) string {
+	if path == "" || !filepath.IsAbs(filepath.FromSlash(path)) {
+		return path
+	}
+	return fsutil.AnchorStatePath(filepath.FromSlash(path))
+}
@@ -0,0 +1,120 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+func TestLoadHashesMigratesLegacyFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	customer := "acme"
+	if err := fsutil.EnsureDir(fsutil.CustomerStateDir(customer)); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"a.nsl": "1", "b.nsl": "2"}`
+	if err := os.WriteFile(fsutil.HashesPath(customer), []byte(legacy), fsutil.FilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	hashes, err := LoadHashes(customer)
+	if err != nil {
+		t.Fatalf("LoadHashes: %v", err)
+	}
+	if len(hashes) != 2 || hashes["a.nsl"] != "1" || hashes["b.nsl"] != "2" {
+		t.Fatalf("unexpected hashes: %v", hashes)
+	}
+	if _, err := os.Stat(fsutil.HashesPath(customer)); !os.IsNotExist(err) {
+		t.Fatalf("expected the legacy file to be removed, got %v", err)
+	}
+	again, err := LoadHashes(customer)
+	if err != nil || len(again) != 2 {
+		t.Fatalf("expected migrated hashes to load from the bucketed store, got %v, %v", again, err)
+	}
+}
+
+func TestLoadHashesMigratesAbsolutePaths(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customer := "acme"
+	absolute := filepath.ToSlash(filepath.Join(cwd, "newo_customers", "acme", "greet.nsl"))
+	if err := SaveHashes(customer, HashStore{absolute: "1", "newo_customers/acme/faq.nsl": "2"}); err != nil {
+		t.Fatalf("SaveHashes: %v", err)
+	}
+
+	hashes, err := LoadHashes(customer)
+	if err != nil {
+		t.Fatalf("LoadHashes: %v", err)
+	}
+	if len(hashes) != 2 || hashes["newo_customers/acme/greet.nsl"] != "1" || hashes["newo_customers/acme/faq.nsl"] != "2" {
+		t.Fatalf("expected workspace-relative paths, got %v", hashes)
+	}
+	again, err := LoadHashes(customer)
+	if err != nil || again[absolute] != "" || again["newo_customers/acme/greet.nsl"] != "1" {
+		t.Fatalf("expected the migration to be saved, got %v, %v", again, err)
+	}
+}
+
+func TestLoadHashesMigratesPathsOfMovedWorkspace(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	customer := "acme"
+	elsewhere := filepath.ToSlash(filepath.Join(t.TempDir(), "old-checkout", "newo_customers", "acme", "shop", "greet.nsl"))
+	if err := SaveHashes(customer, HashStore{elsewhere: "1"}); err != nil {
+		t.Fatalf("SaveHashes: %v", err)
+	}
+
+	hashes, err := LoadHashes(customer)
+	if err != nil {
+		t.Fatalf("LoadHashes: %v", err)
+	}
+	if len(hashes) != 1 || hashes["newo_customers/acme/shop/greet.nsl"] != "1" {
+		t.Fatalf("expected the path re-anchored on the output root, got %v", hashes)
+	}
+}
+
+func TestSaveHashesRewritesOnlyChangedBuckets(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	customer := "acme"
+	hashes := HashStore{"a.nsl": "1", "b.nsl": "2"}
+	if err := SaveHashes(customer, hashes); err != nil {
+		t.Fatalf("SaveHashes: %v", err)
+	}
+	untouched := hashBucketPath(customer, hashBucket("b.nsl"))
+	if hashBucket("a.nsl") == hashBucket("b.nsl") {
+		t.Skip("test paths share a bucket")
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(untouched, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	hashes["a.nsl"] = "changed"
+	if err := SaveHashes(customer, hashes); err != nil {
+		t.Fatalf("SaveHashes: %v", err)
+	}
+	if info, err := os.Stat(untouched); err != nil || !info.ModTime().Equal(past) {
+		t.Fatalf("expected the unchanged bucket to be left alone, got %v, %v", info, err)
+	}
+
+	delete(hashes, "a.nsl")
+	if err := SaveHashes(customer, hashes); err != nil {
+		t.Fatalf("SaveHashes: %v", err)
+	}
+	if _, err := os.Stat(hashBucketPath(customer, hashBucket("a.nsl"))); !os.IsNotExist(err) {
+		t.Fatalf("expected the emptied bucket to be removed, got %v", err)
+	}
+	loaded, err := LoadHashes(customer)
+	if err != nil || len(loaded) != 1 || loaded["b.nsl"] != "2" {
+		t.Fatalf("unexpected hashes after save: %v, %v", loaded, err)
+	}
+}
@@ -0,0 +1,111 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// maxJournalPushes bounds how many pushes are kept in the journal.
+const maxJournalPushes = 20
+
+// ErrJournalEmpty indicates there is no recorded push to undo.
+var ErrJournalEmpty = errors.New("no recorded push to undo")
+
+// PushJournal keeps the most recent pushes for a customer, oldest first.
+type PushJournal struct {
+	Pushes []PushRecord `json:"pushes"`
+}
+
+// PushRecord captures every skill update performed by a single push.
+type PushRecord struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Entries   []PushJournalEntry `json:"entries"`
+}
+
+// PushJournalEntry stores the remote script that a push replaced.
+type PushJournalEntry struct {
+	ProjectIDN     string `json:"project_idn"`
+	AgentIDN       string `json:"agent_idn"`
+	FlowIDN        string `json:"flow_idn"`
+	FlowID         string `json:"flow_id"`
+	SkillIDN       string `json:"skill_idn"`
+	SkillID        string `json:"skill_id"`
+	Path           string `json:"path"`
+	PreviousScript string `json:"previous_script"`
+	PreviousHash   string `json:"previous_hash"`
+	PushedHash     string `json:"pushed_hash"`
+}
+
+// LoadPushJournal returns the journal stored for the customer, or an empty one.
+func LoadPushJournal(customerIDN string) (PushJournal, error) {
+	data, err := os.ReadFile(fsutil.PushJournalPath(customerIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return PushJournal{}, nil
+		}
+		return PushJournal{}, fmt.Errorf("read push journal: %w", err)
+	}
+
+	var journal PushJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return PushJournal{}, fmt.Errorf("decode push journal: %w", err)
+	}
+	for _, push := range journal.Pushes {
+		for i := range push.Entries {
+			push.Entries[i].Path = migratePath(push.Entries[i].Path)
+		}
+	}
+	return journal, nil
+}
+
+// SavePushJournal persists the journal.
+func SavePushJournal(customerIDN string, journal PushJournal) error {
+	path := fsutil.PushJournalPath(customerIDN)
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode push journal: %w", err)
+	}
+	if err := os.WriteFile(path, data, fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write push journal: %w", err)
+	}
+	return nil
+}
+
+// AppendPush adds a push record to the customer's journal, dropping the oldest records beyond the retention limit.
+func AppendPush(customerIDN string, record PushRecord) error {
+	if len(record.Entries) == 0 {
+		return nil
+	}
+	journal, err := LoadPushJournal(customerIDN)
+	if err != nil {
+		return err
+	}
+	journal.Pushes = append(journal.Pushes, record)
+	if extra := len(journal.Pushes) - maxJournalPushes; extra > 0 {
+		journal.Pushes = journal.Pushes[extra:]
+	}
+	return SavePushJournal(customerIDN, journal)
+}
+
+// LastPush returns the most recent push record.
+func (j PushJournal) LastPush() (PushRecord, error) {
+	if len(j.Pushes) == 0 {
+		return PushRecord{}, ErrJournalEmpty
+	}
+	return j.Pushes[len(j.Pushes)-1], nil
+}
+
+// DropLastPush removes the most recent push record.
+func (j *PushJournal) DropLastPush() {
+	if len(j.Pushes) > 0 {
+		j.Pushes = j.Pushes[:len(j.Pushes)-1]
+	}
+}
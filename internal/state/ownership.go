@@ -0,0 +1,112 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Owners recorded in the ownership section of a flow's metadata.yaml.
+const (
+	// OwnerLocal marks what the workspace is authoritative for: pull and merge never overwrite it.
+	OwnerLocal = "local"
+	// OwnerPlatform marks what the platform is authoritative for: push never uploads it.
+	OwnerPlatform = "platform"
+)
+
+// OwnedFlowKeys are the metadata.yaml keys whose ownership can be set.
+var OwnedFlowKeys = []string{"title", "description", "default_runner_type", "default_model", "events", "state_fields"}
+
+// FlowOwnership is the ownership section of a flow's metadata.yaml. Skills are keyed by IDN and
+// metadata keys by their name in the file. Anything not listed is synced both ways as usual.
+type FlowOwnership struct {
+	Skills map[string]string `yaml:"skills,omitempty"`
+	Keys   map[string]string `yaml:"keys,omitempty"`
+}
+
+// Skill returns the owner of a skill, or "" when it is not marked.
+func (o *FlowOwnership) Skill(skillIDN string) string {
+	if o == nil {
+		return ""
+	}
+	return o.Skills[skillIDN]
+}
+
+// Key returns the owner of a metadata key, or "" when it is not marked.
+func (o *FlowOwnership) Key(key string) string {
+	if o == nil {
+		return ""
+	}
+	return o.Keys[key]
+}
+
+// KeysOwnedBy lists the metadata keys marked with owner, sorted.
+func (o *FlowOwnership) KeysOwnedBy(owner string) []string {
+	if o == nil {
+		return nil
+	}
+	var keys []string
+	for key, value := range o.Keys {
+		if value == owner {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate rejects owners other than local and platform, and keys that cannot be owned.
+func (o *FlowOwnership) Validate() error {
+	if o == nil {
+		return nil
+	}
+	for skillIDN, owner := range o.Skills {
+		if owner != OwnerLocal && owner != OwnerPlatform {
+			return fmt.Errorf("ownership of skill %s must be %q or %q, got %q", skillIDN, OwnerLocal, OwnerPlatform, owner)
+		}
+	}
+	for key, owner := range o.Keys {
+		if !slices.Contains(OwnedFlowKeys, key) {
+			return fmt.Errorf("ownership key %s is not one of %v", key, OwnedFlowKeys)
+		}
+		if owner != OwnerLocal && owner != OwnerPlatform {
+			return fmt.Errorf("ownership of key %s must be %q or %q, got %q", key, OwnerLocal, OwnerPlatform, owner)
+		}
+	}
+	return nil
+}
+
+// LoadFlowOwnership reads the ownership section of the metadata.yaml at path. It returns nil when
+// the file or the section does not exist.
+func LoadFlowOwnership(path string) (*FlowOwnership, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	ownership, err := ParseFlowOwnership(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.ToSlash(path), err)
+	}
+	return ownership, nil
+}
+
+// ParseFlowOwnership extracts the ownership section from the contents of a flow's metadata.yaml.
+func ParseFlowOwnership(data []byte) (*FlowOwnership, error) {
+	var doc struct {
+		Ownership *FlowOwnership `yaml:"ownership"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode ownership: %w", err)
+	}
+	if err := doc.Ownership.Validate(); err != nil {
+		return nil, err
+	}
+	return doc.Ownership, nil
+}
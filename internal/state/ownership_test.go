@@ -0,0 +1,29 @@
+package state
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFlowOwnership(t *testing.T) {
+	ownership, err := ParseFlowOwnership([]byte("idn: main\nownership:\n  skills:\n    greet: local\n  keys:\n    events: platform\n    title: local\n"))
+	if err != nil {
+		t.Fatalf("ParseFlowOwnership: %v", err)
+	}
+	if ownership.Skill("greet") != OwnerLocal || ownership.Skill("faq") != "" || ownership.Key("events") != OwnerPlatform {
+		t.Fatalf("unexpected ownership %+v", ownership)
+	}
+	if keys := ownership.KeysOwnedBy(OwnerLocal); len(keys) != 1 || keys[0] != "title" {
+		t.Fatalf("expected title to be the only local key, got %v", keys)
+	}
+
+	if ownership, err := ParseFlowOwnership([]byte("idn: main\n")); err != nil || ownership != nil || ownership.Skill("greet") != "" {
+		t.Fatalf("expected no ownership section, got %+v, %v", ownership, err)
+	}
+	if _, err := ParseFlowOwnership([]byte("ownership:\n  skills:\n    greet: mine\n")); err == nil || !strings.Contains(err.Error(), "greet") {
+		t.Fatalf("expected an invalid owner error, got %v", err)
+	}
+	if _, err := ParseFlowOwnership([]byte("ownership:\n  keys:\n    publish: local\n")); err == nil || !strings.Contains(err.Error(), "publish") {
+		t.Fatalf("expected an unknown key error, got %v", err)
+	}
+}
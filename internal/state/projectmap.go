@@ -15,10 +15,12 @@ type ProjectMap struct {
 
 // ProjectData keeps agent/flow/skill identifiers for a project.
 type ProjectData struct {
-	ProjectID  string               `json:"projectId"`
-	ProjectIDN string               `json:"projectIdn"`
-	Path       string               `json:"path"`
-	Agents     map[string]AgentData `json:"agents"`
+	ProjectID  string `json:"projectId"`
+	ProjectIDN string `json:"projectIdn"`
+	Path       string `json:"path"`
+	// UpdatedAt is the project's updated_at when it was last pulled; `pull --since-last` compares it.
+	UpdatedAt string               `json:"updated_at,omitempty"`
+	Agents    map[string]AgentData `json:"agents"`
 }
 
 // AgentData keeps flow identifiers for an agent.
@@ -112,7 +114,7 @@ func SaveProjectMap(customerIDN string, pm ProjectMap) error {
 		return fmt.Errorf("encode project map: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := fsutil.AtomicWrite(path, data, fsutil.FilePerm); err != nil {
 		return fmt.Errorf("write project map: %w", err)
 	}
 	return nil
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// PullCheckpoint records the flows a pull has finished writing for one project, so that a pull
+// interrupted by Ctrl+C or an error can skip them when it is run again.
+type PullCheckpoint struct {
+	ProjectIDN string                        `json:"project_idn"`
+	ProjectID  string                        `json:"project_id"`
+	StartedAt  time.Time                     `json:"started_at"`
+	Flows      map[string]PullFlowCheckpoint `json:"flows"`
+}
+
+// PullFlowCheckpoint keeps the project map entry of a finished flow and the hashes of the files written for it.
+type PullFlowCheckpoint struct {
+	Flow   FlowData  `json:"flow"`
+	Hashes HashStore `json:"hashes"`
+}
+
+// NewPullCheckpoint starts an empty checkpoint for a project.
+func NewPullCheckpoint(projectIDN, projectID string, startedAt time.Time) *PullCheckpoint {
+	return &PullCheckpoint{
+		ProjectIDN: projectIDN,
+		ProjectID:  projectID,
+		StartedAt:  startedAt,
+		Flows:      map[string]PullFlowCheckpoint{},
+	}
+}
+
+// PullCheckpointKey identifies a flow within a project checkpoint.
+func PullCheckpointKey(agentIDN, flowIDN string) string {
+	return agentIDN + "/" + flowIDN
+}
+
+// LoadPullCheckpoint returns the checkpoint of an interrupted pull, or nil when there is none.
+func LoadPullCheckpoint(customerIDN, projectIDN string) (*PullCheckpoint, error) {
+	data, err := os.ReadFile(fsutil.PullCheckpointPath(customerIDN, projectIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read pull checkpoint: %w", err)
+	}
+
+	var checkpoint PullCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("decode pull checkpoint: %w", err)
+	}
+	if checkpoint.Flows == nil {
+		checkpoint.Flows = map[string]PullFlowCheckpoint{}
+	}
+	for _, flow := range checkpoint.Flows {
+		migrateHashPaths(flow.Hashes)
+	}
+	return &checkpoint, nil
+}
+
+// SavePullCheckpoint persists the checkpoint after a flow has been written.
+func SavePullCheckpoint(customerIDN string, checkpoint *PullCheckpoint) error {
+	path := fsutil.PullCheckpointPath(customerIDN, checkpoint.ProjectIDN)
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode pull checkpoint: %w", err)
+	}
+	if err := fsutil.AtomicWrite(path, data, fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write pull checkpoint: %w", err)
+	}
+	return nil
+}
+
+// RemovePullCheckpoint deletes the checkpoint once the pull state has been saved.
+func RemovePullCheckpoint(customerIDN, projectIDN string) error {
+	if err := os.Remove(fsutil.PullCheckpointPath(customerIDN, projectIDN)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove pull checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+const snapshotIDLayout = "20060102T150405Z"
+
+// ErrSnapshotNotFound indicates the requested snapshot does not exist.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// snapshotFiles lists the state files captured by a snapshot.
+var snapshotFiles = []string{fsutil.MapJSON, fsutil.HashesJSON}
+
+// Snapshot describes a saved copy of a customer's state files.
+type Snapshot struct {
+	ID        string
+	CreatedAt time.Time
+	Files     []string
+}
+
+// CreateSnapshot copies the customer's map.json and hash store into a new timestamped snapshot. The hash
+// store is saved as a single hashes.json file regardless of its on-disk layout.
+func CreateSnapshot(customerIDN string, now time.Time) (Snapshot, error) {
+	baseID := now.UTC().Format(snapshotIDLayout)
+	id := baseID
+	for suffix := 1; ; suffix++ {
+		if _, err := os.Stat(filepath.Join(fsutil.SnapshotsDir(customerIDN), id)); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", baseID, suffix)
+	}
+
+	snapshotDir := filepath.Join(fsutil.SnapshotsDir(customerIDN), id)
+	snapshot := Snapshot{ID: id, CreatedAt: now.UTC()}
+	for _, name := range snapshotFiles {
+		data, err := readStateFile(customerIDN, name)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return Snapshot{}, fmt.Errorf("read %s: %w", name, err)
+		}
+		if err := fsutil.EnsureDir(snapshotDir); err != nil {
+			return Snapshot{}, err
+		}
+		if err := os.WriteFile(filepath.Join(snapshotDir, name), data, fsutil.FilePerm); err != nil {
+			return Snapshot{}, fmt.Errorf("write snapshot %s: %w", name, err)
+		}
+		snapshot.Files = append(snapshot.Files, name)
+	}
+	if len(snapshot.Files) == 0 {
+		return Snapshot{}, fmt.Errorf("no state to snapshot for customer %s", customerIDN)
+	}
+	return snapshot, nil
+}
+
+// ListSnapshots returns the customer's snapshots ordered from oldest to newest.
+func ListSnapshots(customerIDN string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(fsutil.SnapshotsDir(customerIDN))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read snapshots: %w", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snapshot, err := loadSnapshot(customerIDN, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID < snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// RestoreSnapshot overwrites the customer's state files with the contents of the given snapshot.
+// Files absent from the snapshot are removed so the state matches the snapshot exactly.
+func RestoreSnapshot(customerIDN, id string) (Snapshot, error) {
+	snapshot, err := loadSnapshot(customerIDN, id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshotDir := filepath.Join(fsutil.SnapshotsDir(customerIDN), snapshot.ID)
+	for _, name := range snapshotFiles {
+		data, err := os.ReadFile(filepath.Join(snapshotDir, name))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return Snapshot{}, fmt.Errorf("read snapshot %s: %w", name, err)
+			}
+			if err := removeStateFile(customerIDN, name); err != nil {
+				return Snapshot{}, fmt.Errorf("remove %s: %w", name, err)
+			}
+			continue
+		}
+		if err := writeStateFile(customerIDN, name, data); err != nil {
+			return Snapshot{}, fmt.Errorf("restore %s: %w", name, err)
+		}
+	}
+	return snapshot, nil
+}
+
+// readStateFile returns the content of a snapshotted state file. The hash store is encoded as JSON.
+func readStateFile(customerIDN, name string) ([]byte, error) {
+	if name != fsutil.HashesJSON {
+		return os.ReadFile(filepath.Join(fsutil.CustomerStateDir(customerIDN), name))
+	}
+	if !hashesExist(customerIDN) {
+		return nil, os.ErrNotExist
+	}
+	hashes, err := LoadHashes(customerIDN)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(hashes, "", "  ")
+}
+
+func writeStateFile(customerIDN, name string, data []byte) error {
+	if name != fsutil.HashesJSON {
+		return fsutil.AtomicWrite(filepath.Join(fsutil.CustomerStateDir(customerIDN), name), data, fsutil.FilePerm)
+	}
+	hashes := HashStore{}
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return fmt.Errorf("decode hashes: %w", err)
+	}
+	return SaveHashes(customerIDN, hashes)
+}
+
+func removeStateFile(customerIDN, name string) error {
+	if name == fsutil.HashesJSON {
+		return RemoveHashes(customerIDN)
+	}
+	if err := os.Remove(filepath.Join(fsutil.CustomerStateDir(customerIDN), name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func loadSnapshot(customerIDN, id string) (Snapshot, error) {
+	if id == "" || filepath.Base(id) != id {
+		return Snapshot{}, fmt.Errorf("%w: %q", ErrSnapshotNotFound, id)
+	}
+	dir := filepath.Join(fsutil.SnapshotsDir(customerIDN), id)
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return Snapshot{}, fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+	}
+
+	snapshot := Snapshot{ID: id, CreatedAt: info.ModTime().UTC()}
+	if parsed, err := time.Parse(snapshotIDLayout, id[:min(len(id), len(snapshotIDLayout))]); err == nil {
+		snapshot.CreatedAt = parsed
+	}
+	for _, name := range snapshotFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			snapshot.Files = append(snapshot.Files, name)
+		}
+	}
+	return snapshot, nil
+}
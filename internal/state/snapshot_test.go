@@ -0,0 +1,60 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestoreRoundTrip(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	customer := "acme"
+	if err := SaveHashes(customer, HashStore{"a.nsl": "good"}); err != nil {
+		t.Fatalf("SaveHashes: %v", err)
+	}
+	if err := SaveProjectMap(customer, ProjectMap{Projects: map[string]ProjectData{"proj": {ProjectID: "1"}}}); err != nil {
+		t.Fatalf("SaveProjectMap: %v", err)
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first, err := CreateSnapshot(customer, now)
+	if err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+	if first.ID != "20250102T030405Z" || len(first.Files) != 2 {
+		t.Fatalf("unexpected snapshot: %+v", first)
+	}
+	second, err := CreateSnapshot(customer, now)
+	if err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+	if second.ID == first.ID {
+		t.Fatalf("expected unique snapshot ids, got %q twice", first.ID)
+	}
+
+	if err := SaveHashes(customer, HashStore{"a.nsl": "corrupt"}); err != nil {
+		t.Fatalf("SaveHashes: %v", err)
+	}
+	if _, err := RestoreSnapshot(customer, first.ID); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	hashes, err := LoadHashes(customer)
+	if err != nil {
+		t.Fatalf("LoadHashes: %v", err)
+	}
+	if hashes["a.nsl"] != "good" {
+		t.Fatalf("expected restored hash, got %q", hashes["a.nsl"])
+	}
+
+	snapshots, err := ListSnapshots(customer)
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].ID != first.ID {
+		t.Fatalf("unexpected snapshot list: %+v", snapshots)
+	}
+
+	if _, err := RestoreSnapshot(customer, "../escape"); err == nil {
+		t.Fatalf("expected invalid snapshot id to fail")
+	}
+}
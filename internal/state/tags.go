@@ -0,0 +1,51 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSkillTags reads the tags list of the skill .meta.yaml at path. It returns nil when the file does
+// not exist.
+func LoadSkillTags(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	tags, err := ParseSkillTags(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.ToSlash(path), err)
+	}
+	return tags, nil
+}
+
+// ParseSkillTags extracts the tags list from the contents of a skill .meta.yaml. Tags are kept only in
+// the workspace; the platform does not store them.
+func ParseSkillTags(data []byte) ([]string, error) {
+	var doc struct {
+		Tags []string `yaml:"tags"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode tags: %w", err)
+	}
+	return doc.Tags, nil
+}
+
+// HasAnyTag reports whether tags include one of wanted, ignoring case.
+func HasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, want := range wanted {
+			if strings.EqualFold(strings.TrimSpace(tag), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
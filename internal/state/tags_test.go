@@ -0,0 +1,33 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+func TestLoadSkillTags(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "skill.meta.yaml")
+
+	tags, err := LoadSkillTags(path)
+	if err != nil || tags != nil {
+		t.Fatalf("expected no tags for a missing file, got %v (%v)", tags, err)
+	}
+
+	if err := os.WriteFile(path, []byte("idn: skill\ntags:\n  - Checkout\n  - billing\n"), fsutil.FilePerm); err != nil {
+		t.Fatal(err)
+	}
+	tags, err = LoadSkillTags(path)
+	if err != nil {
+		t.Fatalf("LoadSkillTags: %v", err)
+	}
+	if !HasAnyTag(tags, []string{"checkout"}) || !HasAnyTag(tags, []string{"search", "billing"}) {
+		t.Fatalf("expected tags to match, got %v", tags)
+	}
+	if HasAnyTag(tags, []string{"search"}) {
+		t.Fatalf("expected search not to match %v", tags)
+	}
+}
@@ -12,7 +12,6 @@ import (
 
 	"github.com/twinmind/newo-tool/internal/fsutil"
 	"github.com/twinmind/newo-tool/internal/state"
-	"github.com/twinmind/newo-tool/internal/util"
 )
 
 var scriptExtensions = map[string]bool{
@@ -40,6 +39,14 @@ func Run(customerIDN string, outputRoot string, verbose bool, stdout io.Writer,
 	if err != nil {
 		return 0, err
 	}
+	// The cache exists only when the blob cache is enabled; without it every tracked file is read.
+	hashCache, err := state.LoadFileHashCache(customerIDN, false)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = hashCache.Save()
+	}()
 
 	if projectMap.Projects == nil {
 		projectMap.Projects = map[string]state.ProjectData{}
@@ -71,8 +78,8 @@ func Run(customerIDN string, outputRoot string, verbose bool, stdout io.Writer,
 
 	for _, relPath := range hashKeys {
 		oldHash := hashes[relPath]
-		absPath := filepath.Clean(relPath)
-		data, err := os.ReadFile(absPath)
+		absPath := filepath.FromSlash(relPath)
+		newHash, err := hashCache.Hash(absPath)
 		if err != nil {
 			if os.IsNotExist(err) {
 				if verbose {
@@ -86,7 +93,6 @@ func Run(customerIDN string, outputRoot string, verbose bool, stdout io.Writer,
 			}
 			return dirty, fmt.Errorf("read %s: %w", relPath, err)
 		}
-		newHash := util.SHA256Bytes(data)
 		if verbose {
 			_, _ = fmt.Fprintf(stdout, "• %s\n", toSlash(relPath))
 			_, _ = fmt.Fprintf(stdout, "  old: %s\n", emptyIf(oldHash, "none"))
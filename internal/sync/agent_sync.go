@@ -0,0 +1,305 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// syncAgents creates agents whose directories were added locally and deletes agents whose directories were removed.
+// A renamed agent directory is handled as a new agent plus a deleted one. Only layouts with agent directories are
+// considered; integration and e2e exports keep all flows directly under the project.
+func (s *SkillSyncService) syncAgents(
+	ctx context.Context,
+	st *skillSyncState,
+	projectIDN, projectSlug string,
+	projectData *state.ProjectData,
+) error {
+	if !fsutil.HasAgentDirs(st.req.CustomerType) {
+		return nil
+	}
+	projectDir := fsutil.ExportProjectDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug)
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Without the project directory every agent would look deleted; leave the remote untouched.
+			return nil
+		}
+		return fmt.Errorf("read project directory: %w", err)
+	}
+
+	if projectData.Agents == nil {
+		projectData.Agents = map[string]state.AgentData{}
+	}
+
+	var added []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(projectDir, entry.Name(), fsutil.FlowsDir)); err != nil || !info.IsDir() {
+			continue
+		}
+		if _, exists := projectData.Agents[entry.Name()]; !exists {
+			added = append(added, entry.Name())
+		}
+	}
+
+	for _, agentIDN := range added {
+		if err := s.createAgent(ctx, st, projectIDN, projectSlug, agentIDN, projectData); err != nil {
+			return err
+		}
+	}
+
+	var removed []string
+	for agentIDN := range projectData.Agents {
+		if agentRemovedLocally(st, projectSlug, agentIDN) {
+			removed = append(removed, agentIDN)
+		}
+	}
+	sort.Strings(removed)
+	for _, agentIDN := range removed {
+		if err := s.deleteAgent(ctx, st, projectIDN, projectSlug, agentIDN, projectData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// agentRemovedLocally reports whether an agent known from the last pull no longer has a flows directory on disk.
+func agentRemovedLocally(st *skillSyncState, projectSlug, agentIDN string) bool {
+	if !fsutil.HasAgentDirs(st.req.CustomerType) {
+		return false
+	}
+	projectDir := fsutil.ExportProjectDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug)
+	if _, err := os.Stat(projectDir); err != nil {
+		return false
+	}
+	agentDir := fsutil.ExportAgentDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN)
+	_, err := os.Stat(filepath.Join(agentDir, fsutil.FlowsDir))
+	return errors.Is(err, os.ErrNotExist)
+}
+
+func (s *SkillSyncService) createAgent(
+	ctx context.Context,
+	st *skillSyncState,
+	projectIDN, projectSlug, agentIDN string,
+	projectData *state.ProjectData,
+) error {
+	if strings.TrimSpace(projectData.ProjectID) == "" {
+		st.reporter.Warnf("Skipping new agent %s/%s: missing project identifier", projectIDN, agentIDN)
+		st.warnings = append(st.warnings, SkillSyncWarning{Message: fmt.Sprintf("missing project identifier for %s/%s", projectIDN, agentIDN)})
+		return nil
+	}
+
+	agentDir := fsutil.ExportAgentDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN)
+	flowEntries, err := os.ReadDir(filepath.Join(agentDir, fsutil.FlowsDir))
+	if err != nil {
+		return fmt.Errorf("read flows for agent %s: %w", agentIDN, err)
+	}
+
+	if st.req.Verbose {
+		st.reporter.Infof("Creating new agent %s/%s", projectIDN, agentIDN)
+	}
+	resp, err := s.client.CreateAgent(ctx, projectData.ProjectID, platform.CreateAgentRequest{IDN: agentIDN, Title: agentIDN})
+	if err != nil {
+		return fmt.Errorf("create agent %s: %w", agentIDN, err)
+	}
+	agentData := state.AgentData{
+		ID:    strings.TrimSpace(resp.ID),
+		Title: agentIDN,
+		Flows: map[string]state.FlowData{},
+	}
+	for _, entry := range flowEntries {
+		if !entry.IsDir() {
+			continue
+		}
+		flowData, err := s.createFlow(ctx, st, projectIDN, projectSlug, agentIDN, entry.Name(), agentData.ID)
+		if err != nil {
+			return err
+		}
+		agentData.Flows[entry.Name()] = flowData
+	}
+	projectData.Agents[agentIDN] = agentData
+	st.agentsCreated++
+	st.metadataChanged = true
+	st.flowsToRegenerate[projectIDN] = projectSlug
+	st.reporter.Successf("Created remote agent %s/%s with %d flow(s)", projectIDN, agentIDN, len(agentData.Flows))
+	return nil
+}
+
+// createNewFlows creates remote flows for directories added under the flows directory of an existing agent.
+func (s *SkillSyncService) createNewFlows(
+	ctx context.Context,
+	st *skillSyncState,
+	projectIDN, projectSlug, agentIDN string,
+	agentData *state.AgentData,
+) error {
+	if strings.TrimSpace(agentData.ID) == "" {
+		return nil
+	}
+	agentDir := fsutil.ExportAgentDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN)
+	entries, err := os.ReadDir(filepath.Join(agentDir, fsutil.FlowsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read flows for agent %s: %w", agentIDN, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, exists := agentData.Flows[entry.Name()]; exists {
+			continue
+		}
+		if st.req.Verbose {
+			st.reporter.Infof("Creating new flow %s/%s/%s", projectIDN, agentIDN, entry.Name())
+		}
+		flowData, err := s.createFlow(ctx, st, projectIDN, projectSlug, agentIDN, entry.Name(), agentData.ID)
+		if err != nil {
+			return err
+		}
+		if agentData.Flows == nil {
+			agentData.Flows = map[string]state.FlowData{}
+		}
+		agentData.Flows[entry.Name()] = flowData
+		st.flowsCreated++
+		st.metadataChanged = true
+		st.flowsToRegenerate[projectIDN] = projectSlug
+		st.reporter.Successf("Created remote flow %s/%s/%s with %d skill(s)", projectIDN, agentIDN, entry.Name(), len(flowData.Skills))
+	}
+	return nil
+}
+
+func (s *SkillSyncService) createFlow(
+	ctx context.Context,
+	st *skillSyncState,
+	projectIDN, projectSlug, agentIDN, flowIDN, agentID string,
+) (state.FlowData, error) {
+	metadataPath := fsutil.ExportFlowMetadataPath(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN, flowIDN)
+	var doc flowMetadataDocument
+	content, err := os.ReadFile(metadataPath)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return state.FlowData{}, fmt.Errorf("decode %s: %w", filepath.ToSlash(metadataPath), err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return state.FlowData{}, fmt.Errorf("read %s: %w", filepath.ToSlash(metadataPath), err)
+	}
+
+	title := choose(doc.Title, flowIDN)
+	resp, err := s.client.CreateFlow(ctx, agentID, platform.CreateFlowRequest{IDN: flowIDN, Title: title, Description: doc.Description})
+	if err != nil {
+		return state.FlowData{}, fmt.Errorf("create flow %s/%s: %w", agentIDN, flowIDN, err)
+	}
+	flowData := state.FlowData{
+		ID:          strings.TrimSpace(resp.ID),
+		Title:       title,
+		Description: doc.Description,
+		Model:       map[string]string{},
+		Skills:      map[string]state.SkillMetadataInfo{},
+	}
+
+	if settings, changed := flowSettingsUpdate(flowIDN, doc, &flowData); changed {
+		if err := s.client.UpdateFlow(ctx, flowData.ID, settings); err != nil {
+			return flowData, fmt.Errorf("update flow %s/%s: %w", agentIDN, flowIDN, err)
+		}
+		flowData.RunnerType = settings.DefaultRunnerType
+		flowData.Model = modelMap(settings.DefaultModel)
+	}
+
+	created, err := s.createMissing(ctx, st, projectIDN, projectSlug, agentIDN, flowIDN, &flowData, nil)
+	st.created += created
+	if err != nil {
+		return flowData, err
+	}
+
+	stateIDs := map[string]string{}
+	for _, op := range planFlowChanges(doc, nil, nil) {
+		if err := s.applyFlowChange(ctx, flowData.ID, op, stateIDs); err != nil {
+			return flowData, fmt.Errorf("%s %s %s in %s/%s: %w", op.Action, op.Kind, op.IDN, agentIDN, flowIDN, err)
+		}
+	}
+	for _, field := range doc.StateFields {
+		field.ID = stateIDs[field.IDN]
+		flowData.StateFields = append(flowData.StateFields, field)
+	}
+	flowData.Events = doc.Events
+	if content != nil {
+		st.newHashes[filepath.ToSlash(metadataPath)] = util.ContentHash(content)
+	}
+
+	if st.req.ShouldPublish {
+		st.flowsToPublish[flowData.ID] = publishTarget{projectIDN: projectIDN, agentIDN: agentIDN, flowIDN: flowIDN, metadataPath: metadataPath}
+	}
+	return flowData, nil
+}
+
+func (s *SkillSyncService) deleteAgent(
+	ctx context.Context,
+	st *skillSyncState,
+	projectIDN, projectSlug, agentIDN string,
+	projectData *state.ProjectData,
+) error {
+	agentData := projectData.Agents[agentIDN]
+	agentDir := fsutil.ExportAgentDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN)
+	normalized := filepath.ToSlash(agentDir)
+	if strings.TrimSpace(agentData.ID) == "" {
+		st.reporter.Warnf("Skipping %s: agent directory missing and remote identifier unknown; run `newo pull`", normalized)
+		st.warnings = append(st.warnings, SkillSyncWarning{Message: fmt.Sprintf("cannot delete unknown remote agent %s", normalized)})
+		return nil
+	}
+
+	if !st.force {
+		if st.req.ConfirmAgentDeletion == nil {
+			return nil
+		}
+		decision, err := st.req.ConfirmAgentDeletion(normalized, agentIDN)
+		if err != nil {
+			return fmt.Errorf("confirm deletion %s: %w", normalized, err)
+		}
+		if !decision.Apply {
+			return nil
+		}
+		if decision.ApplyAll {
+			st.force = true
+		}
+	}
+
+	if st.req.Verbose {
+		st.reporter.Infof("Deleting missing agent %s/%s", projectIDN, agentIDN)
+	}
+	if err := s.client.DeleteAgent(ctx, strings.TrimSpace(agentData.ID)); err != nil {
+		return fmt.Errorf("delete agent %s: %w", normalized, err)
+	}
+
+	for _, flowData := range agentData.Flows {
+		delete(st.flowsToPublish, flowData.ID)
+	}
+	prefix := normalized + "/"
+	for path := range st.newHashes {
+		if strings.HasPrefix(path, prefix) {
+			delete(st.newHashes, path)
+		}
+	}
+	delete(projectData.Agents, agentIDN)
+	st.agentsRemoved++
+	st.metadataChanged = true
+	st.flowsToRegenerate[projectIDN] = projectSlug
+	st.reporter.Successf("Deleted remote agent %s/%s", projectIDN, agentIDN)
+	return nil
+}
@@ -0,0 +1,419 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/diff"
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// Flow definition change kinds and actions reported in FlowChange.
+const (
+	FlowChangeSettings = "settings"
+	FlowChangeEvent    = "event"
+	FlowChangeState    = "state"
+
+	FlowChangeCreate = "create"
+	FlowChangeUpdate = "update"
+	FlowChangeDelete = "delete"
+)
+
+// FlowChange describes a single settings, event, or state field change pending for a remote flow.
+type FlowChange struct {
+	Kind   string
+	Action string
+	IDN    string
+}
+
+// ConfirmFlowChangesRequest describes the flow definition changes shown to the user before they are applied.
+type ConfirmFlowChangesRequest struct {
+	Path       string
+	ProjectIDN string
+	FlowIDN    string
+	Changes    []FlowChange
+	// Diff shows default runner type and model changes; empty when only events or state fields changed.
+	Diff []diff.Line
+}
+
+// ConfirmFlowChangesFunc prompts before applying flow definition changes to a remote flow.
+type ConfirmFlowChangesFunc func(req ConfirmFlowChangesRequest) (Decision, error)
+
+// flowMetadataDocument mirrors the flow definition written to a flow's metadata.yaml by pull.
+type flowMetadataDocument struct {
+	IDN               string                `yaml:"idn"`
+	Title             string                `yaml:"title"`
+	Description       string                `yaml:"description"`
+	DefaultRunnerType string                `yaml:"default_runner_type"`
+	DefaultModel      map[string]string     `yaml:"default_model"`
+	Events            []state.FlowEventInfo `yaml:"events"`
+	StateFields       []state.FlowStateInfo `yaml:"state_fields"`
+	// Publish set to false keeps push from publishing the flow.
+	Publish *bool `yaml:"publish"`
+	// Ownership marks keys whose local edits push must not send.
+	Ownership *state.FlowOwnership `yaml:"ownership"`
+}
+
+type flowChangeOp struct {
+	FlowChange
+	remoteID string
+	event    state.FlowEventInfo
+	state    state.FlowStateInfo
+}
+
+// syncFlowDefinition pushes edits to the default runner type, default model, events, and state fields
+// declared in a flow's metadata.yaml.
+func (s *SkillSyncService) syncFlowDefinition(
+	ctx context.Context,
+	st *skillSyncState,
+	projectIDN, projectSlug, agentIDN, flowIDN string,
+	flowData *state.FlowData,
+) error {
+	metadataPath := fsutil.ExportFlowMetadataPath(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN, flowIDN)
+	normalized := filepath.ToSlash(metadataPath)
+
+	oldHash, tracked := st.req.Hashes[normalized]
+	content, err := os.ReadFile(metadataPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read %s: %w", normalized, err)
+	}
+	currentHash := util.ContentHash(content)
+	if !tracked || currentHash == oldHash {
+		return nil
+	}
+
+	if strings.TrimSpace(flowData.ID) == "" {
+		st.reporter.Warnf("Skipping %s: missing remote flow identifier; run `newo pull`", normalized)
+		st.warnings = append(st.warnings, SkillSyncWarning{Message: fmt.Sprintf("missing remote identifier for %s", normalized)})
+		return nil
+	}
+
+	var doc flowMetadataDocument
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("decode %s: %w", normalized, err)
+	}
+	if err := doc.Ownership.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", normalized, err)
+	}
+	// Blank defaults keep the remote settings, so platform-managed defaults are blanked here; platform-
+	// managed events and state fields are dropped from the plan below.
+	platformEvents := doc.Ownership.Key("events") == state.OwnerPlatform
+	platformStates := doc.Ownership.Key("state_fields") == state.OwnerPlatform
+	if doc.Ownership.Key("default_runner_type") == state.OwnerPlatform {
+		doc.DefaultRunnerType = ""
+	}
+	if doc.Ownership.Key("default_model") == state.OwnerPlatform {
+		doc.DefaultModel = nil
+	}
+
+	remoteEvents, err := s.client.ListFlowEvents(ctx, flowData.ID)
+	if err != nil {
+		return fmt.Errorf("list events for %s: %w", normalized, err)
+	}
+	remoteStates, err := s.client.ListFlowStates(ctx, flowData.ID)
+	if err != nil {
+		return fmt.Errorf("list states for %s: %w", normalized, err)
+	}
+
+	ops := slices.DeleteFunc(planFlowChanges(doc, remoteEvents, remoteStates), func(op flowChangeOp) bool {
+		return (op.Kind == FlowChangeEvent && platformEvents) || (op.Kind == FlowChangeState && platformStates)
+	})
+	settings, settingsChanged := flowSettingsUpdate(flowIDN, doc, flowData)
+	var settingsDiff []diff.Line
+	if settingsChanged {
+		settingsDiff = s.diff.Generate(renderFlowSettings(flowData.RunnerType, flowData.Model), renderFlowSettings(settings.DefaultRunnerType, modelMap(settings.DefaultModel)), st.diffContextLines)
+		ops = append([]flowChangeOp{{FlowChange: FlowChange{Kind: FlowChangeSettings, Action: FlowChangeUpdate, IDN: flowIDN}}}, ops...)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	if !st.force {
+		if st.req.ConfirmFlowChanges == nil {
+			return nil
+		}
+		changes := make([]FlowChange, 0, len(ops))
+		for _, op := range ops {
+			changes = append(changes, op.FlowChange)
+		}
+		decision, err := st.req.ConfirmFlowChanges(ConfirmFlowChangesRequest{
+			Path:       normalized,
+			ProjectIDN: projectIDN,
+			FlowIDN:    flowIDN,
+			Changes:    changes,
+			Diff:       settingsDiff,
+		})
+		if err != nil {
+			return fmt.Errorf("confirm flow changes %s: %w", normalized, err)
+		}
+		if !decision.Apply {
+			st.reporter.Infof("Skipping %s.", normalized)
+			return nil
+		}
+		if decision.ApplyAll {
+			st.force = true
+		}
+	}
+
+	stateIDs := make(map[string]string, len(remoteStates))
+	for _, remote := range remoteStates {
+		stateIDs[remote.IDN] = remote.ID
+	}
+
+	for _, op := range ops {
+		if st.req.Verbose {
+			st.reporter.Infof("Applying %s %s %s/%s/%s", op.Action, op.Kind, projectIDN, flowIDN, op.IDN)
+		}
+		if op.Kind == FlowChangeSettings {
+			if err := s.client.UpdateFlow(ctx, flowData.ID, settings); err != nil {
+				return fmt.Errorf("update flow %s: %w", normalized, err)
+			}
+			continue
+		}
+		if err := s.applyFlowChange(ctx, flowData.ID, op, stateIDs); err != nil {
+			return fmt.Errorf("%s %s %s in %s: %w", op.Action, op.Kind, op.IDN, normalized, err)
+		}
+	}
+
+	if !platformStates {
+		stateFields := make([]state.FlowStateInfo, 0, len(doc.StateFields))
+		for _, field := range doc.StateFields {
+			field.ID = stateIDs[field.IDN]
+			stateFields = append(stateFields, field)
+		}
+		flowData.StateFields = stateFields
+	}
+	if !platformEvents {
+		flowData.Events = doc.Events
+	}
+	if settingsChanged {
+		flowData.RunnerType = settings.DefaultRunnerType
+		flowData.Model = modelMap(settings.DefaultModel)
+	}
+
+	st.newHashes[normalized] = currentHash
+	st.flowChanges += len(ops)
+	st.metadataChanged = true
+	st.flowsToRegenerate[projectIDN] = projectSlug
+	if st.req.ShouldPublish {
+		st.flowsToPublish[flowData.ID] = publishTarget{projectIDN: projectIDN, agentIDN: agentIDN, flowIDN: flowIDN, metadataPath: metadataPath}
+	}
+	st.reporter.Successf("Applied %d flow definition change(s) to %s/%s/%s", len(ops), projectIDN, agentIDN, flowIDN)
+	return nil
+}
+
+// FlowDefinitionPlan holds the event and state field changes that bring a remote flow in line with a
+// declared definition.
+type FlowDefinitionPlan struct {
+	ops      []flowChangeOp
+	stateIDs map[string]string
+}
+
+// PlanFlowDefinition diffs declared events and state fields against those of the remote flow by IDN.
+func PlanFlowDefinition(events []state.FlowEventInfo, fields []state.FlowStateInfo, remoteEvents []platform.FlowEvent, remoteStates []platform.FlowState) FlowDefinitionPlan {
+	plan := FlowDefinitionPlan{
+		ops:      planFlowChanges(flowMetadataDocument{Events: events, StateFields: fields}, remoteEvents, remoteStates),
+		stateIDs: make(map[string]string, len(remoteStates)),
+	}
+	for _, remote := range remoteStates {
+		plan.stateIDs[remote.IDN] = remote.ID
+	}
+	return plan
+}
+
+// Changes lists the planned changes in the order they are applied.
+func (p FlowDefinitionPlan) Changes() []FlowChange {
+	changes := make([]FlowChange, 0, len(p.ops))
+	for _, op := range p.ops {
+		changes = append(changes, op.FlowChange)
+	}
+	return changes
+}
+
+// ApplyFlowDefinition applies a plan to the remote flow and returns the identifiers of the flow's state
+// fields keyed by IDN.
+func (s *SkillSyncService) ApplyFlowDefinition(ctx context.Context, flowID string, plan FlowDefinitionPlan) (map[string]string, error) {
+	stateIDs := make(map[string]string, len(plan.stateIDs))
+	for idn, id := range plan.stateIDs {
+		stateIDs[idn] = id
+	}
+	for _, op := range plan.ops {
+		if err := s.applyFlowChange(ctx, flowID, op, stateIDs); err != nil {
+			return nil, fmt.Errorf("%s %s %s: %w", op.Action, op.Kind, op.IDN, err)
+		}
+	}
+	return stateIDs, nil
+}
+
+func (s *SkillSyncService) applyFlowChange(ctx context.Context, flowID string, op flowChangeOp, stateIDs map[string]string) error {
+	switch {
+	case op.Kind == FlowChangeEvent && op.Action == FlowChangeDelete:
+		return s.client.DeleteFlowEvent(ctx, op.remoteID)
+	case op.Kind == FlowChangeState && op.Action == FlowChangeDelete:
+		if err := s.client.DeleteFlowState(ctx, op.remoteID); err != nil {
+			return err
+		}
+		delete(stateIDs, op.IDN)
+		return nil
+	case op.Kind == FlowChangeState && op.Action == FlowChangeCreate:
+		resp, err := s.client.CreateFlowState(ctx, flowID, platform.CreateFlowStateRequest{
+			Title:        op.state.Title,
+			IDN:          op.state.IDN,
+			DefaultValue: op.state.DefaultValue,
+			Scope:        op.state.Scope,
+		})
+		if err != nil {
+			return err
+		}
+		stateIDs[op.IDN] = strings.TrimSpace(resp.ID)
+		return nil
+	case op.Kind == FlowChangeState && op.Action == FlowChangeUpdate:
+		return s.client.UpdateFlowState(ctx, op.remoteID, platform.UpdateFlowStateRequest{
+			Title:        op.state.Title,
+			IDN:          op.state.IDN,
+			DefaultValue: op.state.DefaultValue,
+			Scope:        op.state.Scope,
+		})
+	case op.Kind == FlowChangeEvent && op.Action == FlowChangeCreate:
+		_, err := s.client.CreateFlowEvent(ctx, flowID, platform.CreateFlowEventRequest{
+			IDN:            op.event.IDN,
+			Description:    op.event.Description,
+			SkillSelector:  op.event.SkillSelector,
+			SkillIDN:       op.event.SkillIDN,
+			StateIDN:       op.event.StateIDN,
+			InterruptMode:  op.event.InterruptMode,
+			IntegrationIDN: op.event.IntegrationIDN,
+			ConnectorIDN:   op.event.ConnectorIDN,
+		})
+		return err
+	case op.Kind == FlowChangeEvent && op.Action == FlowChangeUpdate:
+		return s.client.UpdateFlowEvent(ctx, op.remoteID, platform.UpdateFlowEventRequest{
+			IDN:            op.event.IDN,
+			Description:    op.event.Description,
+			SkillSelector:  op.event.SkillSelector,
+			SkillIDN:       op.event.SkillIDN,
+			StateIDN:       op.event.StateIDN,
+			InterruptMode:  op.event.InterruptMode,
+			IntegrationIDN: op.event.IntegrationIDN,
+			ConnectorIDN:   op.event.ConnectorIDN,
+		})
+	}
+	return fmt.Errorf("unsupported change %s %s", op.Action, op.Kind)
+}
+
+// planFlowChanges diffs local events and state fields against the remote flow by IDN.
+// Deletions come first and state fields are created before the events that may reference them.
+func planFlowChanges(doc flowMetadataDocument, remoteEvents []platform.FlowEvent, remoteStates []platform.FlowState) []flowChangeOp {
+	localEvents := make(map[string]state.FlowEventInfo, len(doc.Events))
+	for _, event := range doc.Events {
+		localEvents[event.IDN] = event
+	}
+	localStates := make(map[string]state.FlowStateInfo, len(doc.StateFields))
+	for _, field := range doc.StateFields {
+		localStates[field.IDN] = field
+	}
+	remoteEventsByIDN := make(map[string]platform.FlowEvent, len(remoteEvents))
+	for _, event := range remoteEvents {
+		remoteEventsByIDN[event.IDN] = event
+	}
+	remoteStatesByIDN := make(map[string]platform.FlowState, len(remoteStates))
+	for _, field := range remoteStates {
+		remoteStatesByIDN[field.IDN] = field
+	}
+
+	var ops []flowChangeOp
+	for _, remote := range remoteEvents {
+		if _, ok := localEvents[remote.IDN]; !ok {
+			ops = append(ops, flowChangeOp{FlowChange: FlowChange{Kind: FlowChangeEvent, Action: FlowChangeDelete, IDN: remote.IDN}, remoteID: remote.ID})
+		}
+	}
+	for _, remote := range remoteStates {
+		if _, ok := localStates[remote.IDN]; !ok {
+			ops = append(ops, flowChangeOp{FlowChange: FlowChange{Kind: FlowChangeState, Action: FlowChangeDelete, IDN: remote.IDN}, remoteID: remote.ID})
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, field := range doc.StateFields {
+		if seen[field.IDN] {
+			continue
+		}
+		seen[field.IDN] = true
+		remote, ok := remoteStatesByIDN[field.IDN]
+		switch {
+		case !ok:
+			ops = append(ops, flowChangeOp{FlowChange: FlowChange{Kind: FlowChangeState, Action: FlowChangeCreate, IDN: field.IDN}, state: field})
+		case remote.Title != field.Title || remote.DefaultValue != field.DefaultValue || remote.Scope != field.Scope:
+			ops = append(ops, flowChangeOp{FlowChange: FlowChange{Kind: FlowChangeState, Action: FlowChangeUpdate, IDN: field.IDN}, remoteID: remote.ID, state: field})
+		}
+	}
+
+	seen = map[string]bool{}
+	for _, event := range doc.Events {
+		if seen[event.IDN] {
+			continue
+		}
+		seen[event.IDN] = true
+		remote, ok := remoteEventsByIDN[event.IDN]
+		switch {
+		case !ok:
+			ops = append(ops, flowChangeOp{FlowChange: FlowChange{Kind: FlowChangeEvent, Action: FlowChangeCreate, IDN: event.IDN}, event: event})
+		case !eventMatches(remote, event):
+			ops = append(ops, flowChangeOp{FlowChange: FlowChange{Kind: FlowChangeEvent, Action: FlowChangeUpdate, IDN: event.IDN}, remoteID: remote.ID, event: event})
+		}
+	}
+	return ops
+}
+
+func eventMatches(remote platform.FlowEvent, local state.FlowEventInfo) bool {
+	return remote.Description == local.Description &&
+		remote.SkillSelector == local.SkillSelector &&
+		remote.SkillIDN == local.SkillIDN &&
+		remote.StateIDN == local.StateIDN &&
+		remote.InterruptMode == local.InterruptMode &&
+		remote.IntegrationIDN == local.IntegrationIDN &&
+		remote.ConnectorIDN == local.ConnectorIDN
+}
+
+// flowSettingsUpdate compares the local flow defaults with the values recorded at the last pull.
+// Blank local values keep the remote setting.
+func flowSettingsUpdate(flowIDN string, doc flowMetadataDocument, flowData *state.FlowData) (platform.UpdateFlowRequest, bool) {
+	remoteModel := platform.ModelConfig{
+		ModelIDN:    flowData.Model["model_idn"],
+		ProviderIDN: flowData.Model["provider_idn"],
+	}
+	request := platform.UpdateFlowRequest{
+		IDN:               choose(doc.IDN, flowIDN),
+		Title:             choose(doc.Title, flowData.Title),
+		Description:       choose(doc.Description, flowData.Description),
+		DefaultRunnerType: choose(doc.DefaultRunnerType, flowData.RunnerType),
+		DefaultModel:      mergeModel(remoteModel, doc.DefaultModel),
+	}
+	changed := request.DefaultRunnerType != flowData.RunnerType || request.DefaultModel != remoteModel
+	return request, changed
+}
+
+func renderFlowSettings(runnerType string, model map[string]string) []byte {
+	return []byte(fmt.Sprintf("default_runner_type: %s\ndefault_model:\n  model_idn: %s\n  provider_idn: %s\n",
+		runnerType, model["model_idn"], model["provider_idn"]))
+}
+
+func modelMap(model platform.ModelConfig) map[string]string {
+	return map[string]string{
+		"model_idn":    model.ModelIDN,
+		"provider_idn": model.ProviderIDN,
+	}
+}
@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// PublishTarget identifies a flow to publish without touching its skills.
+type PublishTarget struct {
+	ProjectIDN string
+	AgentIDN   string
+	FlowIDN    string
+	FlowID     string
+	// MetadataPath is the flow's metadata.yaml; `publish: false` there opts the flow out.
+	MetadataPath string
+}
+
+// PublishRequest configures a publish-only run.
+type PublishRequest struct {
+	Targets []PublishTarget
+	// IgnoreOptOut publishes flows even when their metadata disables publishing.
+	IgnoreOptOut bool
+	Verbose      bool
+	Reporter     Reporter
+}
+
+// PublishResult summarises a publish-only run.
+type PublishResult struct {
+	Published int
+	// OptedOut lists the flows skipped because their metadata disables publishing, as project/agent/flow.
+	OptedOut []string
+}
+
+// Publish publishes the requested flows. Flows without a remote identifier are skipped with a warning.
+func (s *SkillSyncService) Publish(ctx context.Context, req PublishRequest) (PublishResult, error) {
+	reporter := req.Reporter
+	if reporter == nil {
+		reporter = noopReporter{}
+	}
+
+	var result PublishResult
+	flows := map[string]publishTarget{}
+	for _, target := range req.Targets {
+		name := target.ProjectIDN + "/" + target.AgentIDN + "/" + target.FlowIDN
+		if strings.TrimSpace(target.FlowID) == "" {
+			reporter.Warnf("Skipping %s: missing flow identifier; run `newo pull`", name)
+			continue
+		}
+		if !req.IgnoreOptOut && flowPublishDisabled(target.MetadataPath) {
+			result.OptedOut = append(result.OptedOut, name)
+			continue
+		}
+		flows[strings.TrimSpace(target.FlowID)] = publishTarget{
+			projectIDN:   target.ProjectIDN,
+			agentIDN:     target.AgentIDN,
+			flowIDN:      target.FlowIDN,
+			metadataPath: target.MetadataPath,
+		}
+	}
+
+	published, err := s.publishTargets(ctx, flows, concurrencyCap(), req.Verbose, reporter)
+	result.Published = published
+	return result, err
+}
+
+// flowPublishDisabled reports whether the flow metadata at path sets `publish: false`. A missing or
+// unreadable file leaves publishing enabled; push reports malformed metadata on its own.
+func flowPublishDisabled(path string) bool {
+	if path == "" {
+		return false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var doc struct {
+		Publish *bool `yaml:"publish"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	return doc.Publish != nil && !*doc.Publish
+}
+
+// flowMetadataBeside returns the metadata.yaml of the flow directory that holds a skill file.
+func flowMetadataBeside(skillPath string) string {
+	return filepath.Join(filepath.Dir(filepath.FromSlash(skillPath)), fsutil.MetadataYAML)
+}
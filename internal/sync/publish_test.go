@@ -0,0 +1,69 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+func TestSkillSyncService_PublishHonoursOptOut(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	optedOut := filepath.Join(dir, "quiet", fsutil.MetadataYAML)
+	if err := fsutil.EnsureParentDir(optedOut); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	if err := os.WriteFile(optedOut, []byte("idn: quiet\npublish: false\n"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+	targets := []PublishTarget{
+		{ProjectIDN: "project", AgentIDN: "agent", FlowIDN: "main", FlowID: "flow-main", MetadataPath: filepath.Join(dir, "main", fsutil.MetadataYAML)},
+		{ProjectIDN: "project", AgentIDN: "agent", FlowIDN: "quiet", FlowID: "flow-quiet", MetadataPath: optedOut},
+		{ProjectIDN: "project", AgentIDN: "agent", FlowIDN: "local", MetadataPath: filepath.Join(dir, "local", fsutil.MetadataYAML)},
+	}
+
+	client := newFakeSkillClient()
+	result, err := NewSkillSyncService(client, nil).Publish(context.Background(), PublishRequest{Targets: targets})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if result.Published != 1 || len(client.publishCalls) != 1 || client.publishCalls[0] != "flow-main" {
+		t.Fatalf("expected only flow-main to be published, got %+v and calls %v", result, client.publishCalls)
+	}
+	if len(result.OptedOut) != 1 || result.OptedOut[0] != "project/agent/quiet" {
+		t.Fatalf("expected quiet to be reported as opted out, got %v", result.OptedOut)
+	}
+
+	client = newFakeSkillClient()
+	result, err = NewSkillSyncService(client, nil).Publish(context.Background(), PublishRequest{Targets: targets, IgnoreOptOut: true})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	sort.Strings(client.publishCalls)
+	if result.Published != 2 || len(client.publishCalls) != 2 || client.publishCalls[1] != "flow-quiet" {
+		t.Fatalf("expected both flows with identifiers to be published, got %+v and calls %v", result, client.publishCalls)
+	}
+
+	// Push skips opted-out flows the same way.
+	client = newFakeSkillClient()
+	st := skillSyncState{
+		req:      SkillSyncRequest{ShouldPublish: true},
+		reporter: noopReporter{},
+		flowsToPublish: map[string]publishTarget{
+			"flow-main":  {projectIDN: "project", agentIDN: "agent", flowIDN: "main", metadataPath: targets[0].MetadataPath},
+			"flow-quiet": {projectIDN: "project", agentIDN: "agent", flowIDN: "quiet", metadataPath: optedOut},
+		},
+	}
+	published, err := NewSkillSyncService(client, nil).publishFlows(context.Background(), &st)
+	if err != nil {
+		t.Fatalf("publishFlows: %v", err)
+	}
+	if published != 1 || len(client.publishCalls) != 1 || client.publishCalls[0] != "flow-main" {
+		t.Fatalf("expected push to publish only flow-main, got %d and calls %v", published, client.publishCalls)
+	}
+}
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// resolveConflict handles a skill whose platform copy changed since the last pull. When it was also
+// edited locally and the request can resolve conflicts, the merged script replaces the local file and is uploaded without
+// another confirmation; otherwise, or when the merge is abandoned, the skill is skipped.
+func (s *SkillSyncService) resolveConflict(
+	st *skillSyncState,
+	scriptPath, baseHash string,
+	remote platform.Skill,
+	projectIDN, agentIDN, flowIDN, skillIDN string,
+	meta *state.SkillMetadataInfo,
+) error {
+	normalized := filepath.ToSlash(scriptPath)
+	skip := func() error {
+		st.reporter.Warnf("Skipping %s: remote version changed since last pull; run `newo pull`", normalized)
+		st.warnings = append(st.warnings, SkillSyncWarning{Message: fmt.Sprintf("remote changed for %s", normalized)})
+		st.conflicted++
+		return nil
+	}
+	if st.force || st.req.ResolveConflict == nil {
+		return skip()
+	}
+
+	local, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", normalized, err)
+	}
+	if util.ContentHash(local) == baseHash {
+		// Only the platform changed; a pull brings the file up to date.
+		return skip()
+	}
+	merged, err := st.req.ResolveConflict(ResolveConflictRequest{
+		Path:       normalized,
+		SkillID:    remote.ID,
+		BaseHash:   baseHash,
+		Local:      local,
+		Remote:     []byte(remote.PromptScript),
+		SkillIDN:   skillIDN,
+		FlowIDN:    flowIDN,
+		ProjectIDN: projectIDN,
+	})
+	if err != nil {
+		return fmt.Errorf("resolve conflict in %s: %w", normalized, err)
+	}
+	if merged == nil {
+		return skip()
+	}
+
+	if err := fsutil.AtomicWrite(scriptPath, merged, fsutil.FilePerm); err != nil {
+		return fmt.Errorf("write %s: %w", normalized, err)
+	}
+	merged = util.NormalizeEOL(merged)
+	mergedHash := util.ContentHash(merged)
+	if mergedHash == util.ContentHashString(remote.PromptScript) {
+		// The merge kept the platform version: there is nothing to upload, and the file is now in sync.
+		st.reporter.Infof("Resolved %s to the platform version", normalized)
+		st.newHashes[normalized] = mergedHash
+		return nil
+	}
+
+	st.reporter.Infof("Resolved conflict in %s", normalized)
+	st.uploads = append(st.uploads, skillUpload{
+		remote:     remote,
+		meta:       *meta,
+		content:    merged,
+		hash:       mergedHash,
+		path:       normalized,
+		projectIDN: projectIDN,
+		agentIDN:   agentIDN,
+		flowIDN:    flowIDN,
+		skillIDN:   skillIDN,
+	})
+	return nil
+}
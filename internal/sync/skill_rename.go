@@ -0,0 +1,207 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// skillRename is a mapped skill whose files were renamed locally. The new metadata file still carries
+// the remote id, which is what ties it to the old entry.
+type skillRename struct {
+	oldIDN string
+	newIDN string
+	info   state.SkillMetadataInfo
+	meta   skillMetadataDocument
+}
+
+// findRenames pairs metadata files that are not in the project map with mapped skills of the same flow
+// whose script is gone, by the id recorded in the metadata.
+func findRenames(st *skillSyncState, projectSlug, agentIDN, flowIDN string, flowData *state.FlowData) ([]skillRename, error) {
+	byID := map[string]string{}
+	for skillIDN, info := range flowData.Skills {
+		if id := strings.TrimSpace(info.ID); id != "" {
+			byID[id] = skillIDN
+		}
+	}
+	if len(byID) == 0 {
+		return nil, nil
+	}
+
+	flowDir := fsutil.ExportFlowDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN, flowIDN)
+	entries, err := os.ReadDir(flowDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read flow directory: %w", err)
+	}
+
+	var renames []skillRename
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fsutil.SkillMetaFileExt) || name == fsutil.MetadataYAML {
+			continue
+		}
+		newIDN := strings.TrimSuffix(name, fsutil.SkillMetaFileExt)
+		if _, mapped := flowData.Skills[newIDN]; mapped {
+			continue
+		}
+		meta, err := readSkillMetadata(filepath.Join(flowDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("decode metadata %s: %w", name, err)
+		}
+		oldIDN, ok := byID[strings.TrimSpace(meta.ID)]
+		if !ok {
+			continue
+		}
+		info := flowData.Skills[oldIDN]
+		oldScript := filepath.Join(flowDir, oldIDN+"."+platform.ScriptExtension(info.RunnerType))
+		if _, err := os.Stat(oldScript); !errors.Is(err, os.ErrNotExist) {
+			// Both files exist, so this is a copy rather than a rename.
+			continue
+		}
+		if meta.IDN == "" || meta.IDN == info.IDN {
+			meta.IDN = newIDN
+		}
+		renames = append(renames, skillRename{oldIDN: oldIDN, newIDN: newIDN, info: info, meta: meta})
+	}
+	sort.Slice(renames, func(i, j int) bool { return renames[i].newIDN < renames[j].newIDN })
+	return renames, nil
+}
+
+// renameSkills updates the remote skills of a flow that were renamed locally, keeping their ids and
+// history instead of deleting and re-creating them. It returns the skill IDNs the rest of the flow
+// sync must leave alone: both names of a rename that was applied, declined, or blocked by a remote
+// change.
+func (s *SkillSyncService) renameSkills(
+	ctx context.Context,
+	st *skillSyncState,
+	projectIDN, projectSlug, agentIDN, flowIDN string,
+	flowData *state.FlowData,
+) (map[string]bool, error) {
+	renames, err := findRenames(st, projectSlug, agentIDN, flowIDN, flowData)
+	if err != nil || len(renames) == 0 {
+		return nil, err
+	}
+
+	flowDir := fsutil.ExportFlowDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN, flowIDN)
+	handled := map[string]bool{}
+	for _, rename := range renames {
+		handled[rename.oldIDN] = true
+		handled[rename.newIDN] = true
+		if err := s.renameSkill(ctx, st, flowDir, projectIDN, projectSlug, agentIDN, flowIDN, rename, flowData); err != nil {
+			return handled, err
+		}
+	}
+	return handled, nil
+}
+
+func (s *SkillSyncService) renameSkill(
+	ctx context.Context,
+	st *skillSyncState,
+	flowDir, projectIDN, projectSlug, agentIDN, flowIDN string,
+	rename skillRename,
+	flowData *state.FlowData,
+) error {
+	oldScript := filepath.ToSlash(filepath.Join(flowDir, rename.oldIDN+"."+platform.ScriptExtension(rename.info.RunnerType)))
+	newScript := filepath.Join(flowDir, rename.newIDN+"."+platform.ScriptExtension(rename.meta.RunnerType))
+	normalized := filepath.ToSlash(newScript)
+
+	content, err := os.ReadFile(newScript)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("read %s: %w", normalized, err)
+		}
+		content = []byte{}
+	}
+	content = util.NormalizeEOL(content)
+
+	remote, found, err := s.remoteSkillSnapshot(ctx, st, flowData.ID, rename.info)
+	if err != nil {
+		return fmt.Errorf("verify remote skill %s: %w", rename.oldIDN, err)
+	}
+	if !found {
+		st.reporter.Warnf("Skipping rename of %s to %s: remote skill not found; run `newo pull`", rename.oldIDN, rename.newIDN)
+		st.warnings = append(st.warnings, SkillSyncWarning{Message: fmt.Sprintf("remote skill missing for rename of %s", oldScript)})
+		return nil
+	}
+	if oldHash, tracked := st.req.Hashes[oldScript]; tracked && oldHash != "" && util.ContentHashString(remote.PromptScript) != oldHash {
+		st.reporter.Warnf("Skipping rename of %s to %s: remote version changed since last pull; run `newo pull`", rename.oldIDN, rename.newIDN)
+		st.warnings = append(st.warnings, SkillSyncWarning{Message: fmt.Sprintf("remote changed for %s", oldScript)})
+		st.conflicted++
+		return nil
+	}
+
+	if !st.force {
+		if st.req.ConfirmPush == nil {
+			return nil
+		}
+		st.reporter.Infof("Skill %s was renamed to %s", rename.oldIDN, rename.newIDN)
+		decision, err := st.req.ConfirmPush(ConfirmPushRequest{
+			Path:       normalized,
+			Diff:       s.diff.Generate([]byte(remote.PromptScript), content, st.diffContextLines),
+			Remote:     []byte(remote.PromptScript),
+			Local:      content,
+			SkillIDN:   rename.newIDN,
+			FlowIDN:    flowIDN,
+			ProjectIDN: projectIDN,
+		})
+		if err != nil {
+			return fmt.Errorf("confirm rename %s: %w", normalized, err)
+		}
+		if !decision.Apply {
+			st.reporter.Infof("Skipping rename of %s.", rename.oldIDN)
+			st.skipped++
+			return nil
+		}
+		if decision.ApplyAll {
+			st.force = true
+		}
+	}
+
+	title := choose(strings.TrimSpace(rename.meta.Title), rename.info.Title)
+	info := state.SkillMetadataInfo{
+		ID:         remote.ID,
+		IDN:        rename.meta.IDN,
+		Title:      title,
+		RunnerType: rename.meta.RunnerType,
+		Model:      map[string]string{"model_idn": rename.meta.Model.ModelIDN, "provider_idn": rename.meta.Model.ProviderIDN},
+		Parameters: convertParametersForState(rename.meta.Parameters),
+	}
+	if err := s.pushSkill(ctx, remote, info, string(content)); err != nil {
+		return fmt.Errorf("rename skill %s to %s: %w", rename.oldIDN, rename.newIDN, err)
+	}
+	s.invalidateFlowSnapshot(st, flowData.ID)
+	st.reporter.Infof("Renamed skill %s/%s/%s to %s", projectIDN, flowIDN, rename.oldIDN, rename.newIDN)
+
+	delete(flowData.Skills, rename.oldIDN)
+	delete(st.newHashes, oldScript)
+	delete(st.newHashes, filepath.ToSlash(filepath.Join(flowDir, rename.oldIDN+fsutil.SkillMetaFileExt)))
+	st.renamed++
+	st.metadataChanged = true
+	st.flowsToRegenerate[projectIDN] = projectSlug
+	st.journal = append(st.journal, state.PushJournalEntry{
+		ProjectIDN:     projectIDN,
+		AgentIDN:       agentIDN,
+		FlowIDN:        flowIDN,
+		FlowID:         flowData.ID,
+		SkillIDN:       rename.newIDN,
+		SkillID:        remote.ID,
+		Path:           normalized,
+		PreviousScript: remote.PromptScript,
+		PreviousHash:   util.ContentHashString(remote.PromptScript),
+		PushedHash:     util.ContentHash(content),
+	})
+	rename.meta.ID = remote.ID
+	return s.persistMetadata(flowDir, projectIDN, agentIDN, flowIDN, rename.newIDN, rename.meta, title, content, remote.ID, flowData, st)
+}
@@ -9,11 +9,13 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/twinmind/newo-tool/internal/diff"
 	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/logging"
 	"github.com/twinmind/newo-tool/internal/platform"
 	"github.com/twinmind/newo-tool/internal/serialize"
 	"github.com/twinmind/newo-tool/internal/state"
@@ -24,6 +26,8 @@ import (
 const (
 	defaultContextLines   = 3
 	defaultConcurrencyCap = 4
+	// defaultUploadConcurrency bounds the skill uploads and creations in flight for a flow.
+	defaultUploadConcurrency = 8
 )
 
 // SkillSyncClient captures the subset of platform client functionality required for synchronisation.
@@ -33,7 +37,19 @@ type SkillSyncClient interface {
 	DeleteSkill(ctx context.Context, skillID string) error
 	GetSkill(ctx context.Context, skillID string) (platform.Skill, error)
 	ListFlowSkills(ctx context.Context, flowID string) ([]platform.Skill, error)
+	CreateAgent(ctx context.Context, projectID string, payload platform.CreateAgentRequest) (platform.CreateAgentResponse, error)
+	DeleteAgent(ctx context.Context, agentID string) error
+	CreateFlow(ctx context.Context, agentID string, payload platform.CreateFlowRequest) (platform.CreateFlowResponse, error)
 	PublishFlow(ctx context.Context, flowID string, payload platform.PublishFlowRequest) error
+	UpdateFlow(ctx context.Context, flowID string, payload platform.UpdateFlowRequest) error
+	ListFlowEvents(ctx context.Context, flowID string) ([]platform.FlowEvent, error)
+	CreateFlowEvent(ctx context.Context, flowID string, payload platform.CreateFlowEventRequest) (platform.CreateFlowEventResponse, error)
+	UpdateFlowEvent(ctx context.Context, eventID string, payload platform.UpdateFlowEventRequest) error
+	DeleteFlowEvent(ctx context.Context, eventID string) error
+	ListFlowStates(ctx context.Context, flowID string) ([]platform.FlowState, error)
+	CreateFlowState(ctx context.Context, flowID string, payload platform.CreateFlowStateRequest) (platform.CreateFlowStateResponse, error)
+	UpdateFlowState(ctx context.Context, stateID string, payload platform.UpdateFlowStateRequest) error
+	DeleteFlowState(ctx context.Context, stateID string) error
 }
 
 // Reporter provides logging hooks for the service.
@@ -43,6 +59,12 @@ type Reporter interface {
 	Successf(format string, args ...any)
 }
 
+// ProgressReporter receives per-project counts of skills discovered and checked during a sync.
+type ProgressReporter interface {
+	Add(group string, n int)
+	Done(group string, n int)
+}
+
 // DiffGenerator abstracts diff computation to simplify testing.
 type DiffGenerator interface {
 	Generate(local, remote []byte, context int) []diff.Line
@@ -76,6 +98,23 @@ type Decision struct {
 // ConfirmPushFunc prompts before updating a remote skill.
 type ConfirmPushFunc func(req ConfirmPushRequest) (Decision, error)
 
+// ResolveConflictRequest describes a skill edited locally whose platform copy also changed since the
+// last pull. BaseHash is the hash of the script both sides started from.
+type ResolveConflictRequest struct {
+	Path       string
+	SkillID    string
+	BaseHash   string
+	Local      []byte
+	Remote     []byte
+	SkillIDN   string
+	FlowIDN    string
+	ProjectIDN string
+}
+
+// ResolveConflictFunc merges the two sides of a conflict. It returns the merged script, or nil to leave
+// the skill alone until the next pull.
+type ResolveConflictFunc func(req ResolveConflictRequest) ([]byte, error)
+
 // ConfirmDeletionFunc prompts before deleting a remote-only skill.
 type ConfirmDeletionFunc func(path, skillIDN string) (Decision, error)
 
@@ -88,6 +127,9 @@ type SaveProjectMapFunc func(customerIDN string, pm state.ProjectMap) error
 // SaveHashesFunc persists the hash snapshot for a customer.
 type SaveHashesFunc func(customerIDN string, hashes state.HashStore) error
 
+// SavePushJournalFunc records the remote scripts replaced by a push.
+type SavePushJournalFunc func(customerIDN string, record state.PushRecord) error
+
 // RegenerateFlowsFunc regenerates flows.yaml for a project.
 type RegenerateFlowsFunc func(customerType, customerIDN, projectIDN, projectSlug string, projectData state.ProjectData, hashes state.HashStore) error
 
@@ -102,14 +144,32 @@ type SkillSyncRequest struct {
 	Verbose       bool
 	Force         bool
 
-	Reporter         Reporter
-	ProjectSlugger   ProjectSlugger
-	ConfirmPush      ConfirmPushFunc
-	ConfirmDeletion  ConfirmDeletionFunc
-	SaveProjectMap   SaveProjectMapFunc
-	SaveHashes       SaveHashesFunc
-	RegenerateFlows  RegenerateFlowsFunc
-	DiffContextLines int
+	Reporter Reporter
+	Progress ProgressReporter
+	// SlugPrefix is prepended to the lowercased project IDN of projects without a recorded path.
+	SlugPrefix      string
+	ProjectSlugger  ProjectSlugger
+	ConfirmPush     ConfirmPushFunc
+	ConfirmDeletion ConfirmDeletionFunc
+	// ConfirmAgentDeletion prompts before deleting a remote agent whose directory was removed locally.
+	ConfirmAgentDeletion ConfirmDeletionFunc
+	// ConfirmFlowChanges prompts before event and state field edits are applied to a flow.
+	ConfirmFlowChanges ConfirmFlowChangesFunc
+	SaveProjectMap     SaveProjectMapFunc
+	SaveHashes         SaveHashesFunc
+	SavePushJournal    SavePushJournalFunc
+	RegenerateFlows    RegenerateFlowsFunc
+	DiffContextLines   int
+	// HashCache lets unchanged scripts be recognised without reading them; nil reads every script.
+	HashCache *state.FileHashCache
+	// Concurrency bounds the skill uploads and creations in flight for a flow, and the flows published
+	// at once; zero keeps the defaults.
+	Concurrency int
+	// ResolveConflict is offered skills whose platform copy changed since the last pull; without it
+	// they are skipped.
+	ResolveConflict ResolveConflictFunc
+	// Tags limits the push to skills whose .meta.yaml lists one of them; empty pushes every skill.
+	Tags []string
 }
 
 // SkillSyncWarning records non-fatal issues encountered during sync.
@@ -119,14 +179,46 @@ type SkillSyncWarning struct {
 
 // SkillSyncResult summarises the changes performed by the service.
 type SkillSyncResult struct {
-	Updated            int
-	Removed            int
-	Created            int
-	Published          int
+	Updated       int
+	Removed       int
+	Created       int
+	FlowChanges   int
+	AgentsCreated int
+	AgentsRemoved int
+	FlowsCreated  int
+	Published     int
+	// AlreadyRemote counts edited skills whose content the platform already had, so no upload was needed.
+	AlreadyRemote      int
 	Force              bool
 	Hashes             state.HashStore
 	Warnings           []SkillSyncWarning
 	SkippedPublication bool
+	// Skipped counts edited skills whose upload was declined at the prompt; Conflicted counts those
+	// left alone because the platform copy changed since the last pull.
+	Skipped    int
+	Conflicted int
+	// Renamed counts skills whose local rename was applied to the existing remote skill.
+	Renamed int
+}
+
+// PartialPushError reports a push that failed after some of its changes had already reached the
+// platform. The state for those changes is saved, so pushing again picks up what is left.
+type PartialPushError struct {
+	Err error
+}
+
+func (e *PartialPushError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialPushError) Unwrap() error {
+	return e.Err
+}
+
+// Changed reports whether the sync modified anything remotely. Publishing alone does not count.
+func (r SkillSyncResult) Changed() bool {
+	return r.Updated != 0 || r.Removed != 0 || r.Created != 0 || r.FlowChanges != 0 ||
+		r.AgentsCreated != 0 || r.AgentsRemoved != 0 || r.FlowsCreated != 0 || r.Renamed != 0
 }
 
 // SkillSyncService orchestrates skill synchronisation for push operations.
@@ -150,6 +242,8 @@ type publishTarget struct {
 	projectIDN string
 	agentIDN   string
 	flowIDN    string
+	// metadataPath is the flow's metadata.yaml, which can opt the flow out of publishing.
+	metadataPath string
 }
 
 type skillSyncState struct {
@@ -162,11 +256,50 @@ type skillSyncState struct {
 	updated             int
 	removed             int
 	created             int
+	flowChanges         int
+	agentsCreated       int
+	agentsRemoved       int
+	flowsCreated        int
+	alreadyRemote       int
+	skipped             int
+	conflicted          int
+	renamed             int
 	metadataChanged     bool
+	journal             []state.PushJournalEntry
 	warnings            []SkillSyncWarning
 	diffContextLines    int
 	flowSnapshotCache   map[string]*flowSnapshot
 	flowSnapshotCacheMu sync.Mutex
+	// uploads holds the confirmed skill updates of the flow being synced until they are sent together.
+	uploads []skillUpload
+}
+
+// skillUpload is a confirmed update of an existing skill, waiting to be sent.
+type skillUpload struct {
+	remote     platform.Skill
+	meta       state.SkillMetadataInfo
+	content    []byte
+	hash       string
+	path       string
+	projectIDN string
+	agentIDN   string
+	flowIDN    string
+	skillIDN   string
+	done       bool
+}
+
+// limit returns the requested concurrency, or fallback when none was set.
+func (st *skillSyncState) limit(fallback int) int {
+	if st.req.Concurrency > 0 {
+		return st.req.Concurrency
+	}
+	return fallback
+}
+
+// changed reports whether the sync modified anything remotely.
+func (st *skillSyncState) changed() bool {
+	return st.updated != 0 || st.removed != 0 || st.created != 0 || st.flowChanges != 0 ||
+		st.agentsCreated != 0 || st.agentsRemoved != 0 || st.flowsCreated != 0 || st.renamed != 0
 }
 
 // SyncCustomer performs the synchronisation and persists resulting state.
@@ -177,6 +310,9 @@ func (s *SkillSyncService) SyncCustomer(ctx context.Context, req SkillSyncReques
 	if req.Reporter == nil {
 		req.Reporter = noopReporter{}
 	}
+	if req.Progress == nil {
+		req.Progress = noopProgress{}
+	}
 	if req.ProjectSlugger == nil {
 		req.ProjectSlugger = func(projectIDN string, data state.ProjectData) string {
 			slug := strings.TrimSpace(data.Path)
@@ -187,7 +323,7 @@ func (s *SkillSyncService) SyncCustomer(ctx context.Context, req SkillSyncReques
 			if base == "" {
 				base = "project"
 			}
-			return strings.ToLower(base)
+			return req.SlugPrefix + strings.ToLower(base)
 		}
 	}
 
@@ -201,15 +337,37 @@ func (s *SkillSyncService) SyncCustomer(ctx context.Context, req SkillSyncReques
 		diffContextLines:  effectiveContextLines(req.DiffContextLines),
 	}
 
+	logging.Debug("skill sync started", "customer", req.SessionIDN, "projects", len(req.ProjectMap.Projects), "force", req.Force)
 	if err := s.syncProjects(ctx, &state); err != nil {
+		logging.Error("skill sync failed", "customer", req.SessionIDN, "error", err.Error())
+		if state.changed() {
+			// Remote changes made before the failure or interruption are recorded, so the next push
+			// does not mistake them for edits made by someone else.
+			state.reporter.Warnf("Saving state for the changes pushed before the failure")
+			if persistErr := s.persistState(&state); persistErr != nil {
+				err = errors.Join(err, persistErr)
+			}
+			err = &PartialPushError{Err: err}
+		}
 		return SkillSyncResult{}, err
 	}
+	logging.Info("skill sync finished", "customer", req.SessionIDN,
+		"updated", state.updated, "created", state.created, "removed", state.removed,
+		"flow_changes", state.flowChanges, "agents_created", state.agentsCreated, "agents_removed", state.agentsRemoved, "flows_created", state.flowsCreated)
 
-	if state.updated == 0 && state.removed == 0 && state.created == 0 {
+	if !state.changed() {
+		if state.alreadyRemote > 0 {
+			if err := s.persistState(&state); err != nil {
+				return SkillSyncResult{}, err
+			}
+		}
 		return SkillSyncResult{
-			Force:    state.force,
-			Hashes:   state.newHashes,
-			Warnings: state.warnings,
+			AlreadyRemote: state.alreadyRemote,
+			Skipped:       state.skipped,
+			Conflicted:    state.conflicted,
+			Force:         state.force,
+			Hashes:        state.newHashes,
+			Warnings:      state.warnings,
 		}, nil
 	}
 
@@ -219,14 +377,22 @@ func (s *SkillSyncService) SyncCustomer(ctx context.Context, req SkillSyncReques
 
 	published, err := s.publishFlows(ctx, &state)
 	if err != nil {
-		return SkillSyncResult{}, err
+		return SkillSyncResult{}, &PartialPushError{Err: err}
 	}
 
 	return SkillSyncResult{
 		Updated:            state.updated,
 		Removed:            state.removed,
 		Created:            state.created,
+		FlowChanges:        state.flowChanges,
+		AgentsCreated:      state.agentsCreated,
+		AgentsRemoved:      state.agentsRemoved,
+		FlowsCreated:       state.flowsCreated,
 		Published:          published,
+		AlreadyRemote:      state.alreadyRemote,
+		Skipped:            state.skipped,
+		Conflicted:         state.conflicted,
+		Renamed:            state.renamed,
 		Force:              state.force,
 		Hashes:             state.newHashes,
 		Warnings:           state.warnings,
@@ -235,18 +401,39 @@ func (s *SkillSyncService) SyncCustomer(ctx context.Context, req SkillSyncReques
 }
 
 func (s *SkillSyncService) syncProjects(ctx context.Context, st *skillSyncState) error {
+	for projectIDN, projectData := range st.req.ProjectMap.Projects {
+		for _, agentData := range projectData.Agents {
+			for _, flowData := range agentData.Flows {
+				st.req.Progress.Add(projectIDN, len(flowData.Skills))
+			}
+		}
+	}
 	for projectIDN, projectData := range st.req.ProjectMap.Projects {
 		projectSlug := st.req.ProjectSlugger(projectIDN, projectData)
 		st.flowSnapshotCache = make(map[string]*flowSnapshot)
 		for agentIDN, agentData := range projectData.Agents {
+			if agentRemovedLocally(st, projectSlug, agentIDN) {
+				continue
+			}
 			for flowIDN, flowData := range agentData.Flows {
 				if err := s.syncFlow(ctx, st, projectIDN, projectSlug, agentIDN, flowIDN, &flowData); err != nil {
 					return err
 				}
 				agentData.Flows[flowIDN] = flowData
 			}
+			// Without agent directories the flows directory is shared, so new flows are only attributable to a single agent.
+			if !st.tagScoped() && (fsutil.HasAgentDirs(st.req.CustomerType) || len(projectData.Agents) == 1) {
+				if err := s.createNewFlows(ctx, st, projectIDN, projectSlug, agentIDN, &agentData); err != nil {
+					return err
+				}
+			}
 			projectData.Agents[agentIDN] = agentData
 		}
+		if !st.tagScoped() {
+			if err := s.syncAgents(ctx, st, projectIDN, projectSlug, &projectData); err != nil {
+				return err
+			}
+		}
 		st.req.ProjectMap.Projects[projectIDN] = projectData
 	}
 	return nil
@@ -258,25 +445,57 @@ func (s *SkillSyncService) syncFlow(
 	projectIDN, projectSlug, agentIDN, flowIDN string,
 	flowData *state.FlowData,
 ) error {
+	ownership, err := state.LoadFlowOwnership(fsutil.ExportFlowMetadataPath(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN, flowIDN))
+	if err != nil {
+		return err
+	}
+	var renamed map[string]bool
+	if !st.tagScoped() {
+		if renamed, err = s.renameSkills(ctx, st, projectIDN, projectSlug, agentIDN, flowIDN, flowData); err != nil {
+			return err
+		}
+	}
+	flowDir := fsutil.ExportFlowDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN, flowIDN)
 	for skillIDN, skillInfo := range flowData.Skills {
+		if renamed[skillIDN] || (st.tagScoped() && !st.skillTagged(flowDir, skillIDN)) {
+			st.req.Progress.Done(projectIDN, 1)
+			continue
+		}
+		// Platform-managed skills are never uploaded, changed or deleted by push.
+		if ownership.Skill(skillIDN) == state.OwnerPlatform {
+			if st.req.Verbose {
+				st.reporter.Infof("Skipping %s/%s/%s: managed on the platform", projectIDN, flowIDN, skillIDN)
+			}
+			st.req.Progress.Done(projectIDN, 1)
+			continue
+		}
 		if err := s.syncExistingSkill(ctx, st, projectIDN, projectSlug, agentIDN, flowIDN, skillIDN, &skillInfo, flowData); err != nil {
-			return err
+			// Updates confirmed before the failure are still sent, as they were asked for.
+			return errors.Join(err, s.uploadSkills(ctx, st, flowData))
 		}
 		if _, exists := flowData.Skills[skillIDN]; exists {
 			flowData.Skills[skillIDN] = skillInfo
 		}
+		st.req.Progress.Done(projectIDN, 1)
 	}
-
-	created, err := s.createMissing(ctx, st, projectIDN, projectSlug, agentIDN, flowIDN, flowData)
-	if err != nil {
+	if err := s.uploadSkills(ctx, st, flowData); err != nil {
 		return err
 	}
+
+	created, err := s.createMissing(ctx, st, projectIDN, projectSlug, agentIDN, flowIDN, flowData, renamed)
 	if created > 0 {
 		st.created += created
 		st.metadataChanged = true
 		st.flowsToRegenerate[projectIDN] = projectSlug
 	}
-	return nil
+	if err != nil {
+		return err
+	}
+
+	if st.tagScoped() {
+		return nil
+	}
+	return s.syncFlowDefinition(ctx, st, projectIDN, projectSlug, agentIDN, flowIDN, flowData)
 }
 
 func (s *SkillSyncService) syncExistingSkill(
@@ -292,7 +511,7 @@ func (s *SkillSyncService) syncExistingSkill(
 	normalized := filepath.ToSlash(scriptPath)
 
 	oldHash, tracked := st.req.Hashes[normalized]
-	content, readErr := os.ReadFile(scriptPath)
+	currentHash, readErr := st.req.HashCache.Hash(scriptPath)
 	if readErr != nil {
 		if errors.Is(readErr, os.ErrNotExist) {
 			return s.handleMissingFile(ctx, st, projectIDN, projectSlug, flowIDN, skillIDN, normalized, meta, flowData)
@@ -317,15 +536,25 @@ func (s *SkillSyncService) syncExistingSkill(
 	}
 
 	remoteScript := remoteSkill.PromptScript
-	remoteHash := util.SHA256String(remoteScript)
+	remoteHash := util.ContentHashString(remoteScript)
 
-	if tracked && oldHash != "" && remoteHash != oldHash {
-		st.reporter.Warnf("Skipping %s: remote version changed since last pull; run `newo pull`", normalized)
-		st.warnings = append(st.warnings, SkillSyncWarning{Message: fmt.Sprintf("remote changed for %s", normalized)})
+	// The platform already has the local content, for example after an interrupted push or an identical
+	// edit elsewhere: record it as pushed without uploading the script again.
+	if remoteHash == currentHash {
+		if oldHash != currentHash {
+			if st.req.Verbose {
+				st.reporter.Infof("Skipping upload of %s: the platform already has this content", normalized)
+			}
+			st.alreadyRemote++
+		}
+		st.newHashes[normalized] = currentHash
 		return nil
 	}
 
-	currentHash := util.SHA256Bytes(content)
+	if tracked && oldHash != "" && remoteHash != oldHash {
+		return s.resolveConflict(st, scriptPath, oldHash, remoteSkill, projectIDN, agentIDN, flowIDN, skillIDN, meta)
+	}
+
 	if tracked && currentHash == oldHash {
 		return nil
 	}
@@ -336,6 +565,13 @@ func (s *SkillSyncService) syncExistingSkill(
 		return nil
 	}
 
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", normalized, err)
+	}
+	content = util.NormalizeEOL(content)
+	currentHash = util.ContentHash(content)
+
 	if !st.force {
 		if st.req.ConfirmPush == nil {
 			return nil
@@ -355,6 +591,7 @@ func (s *SkillSyncService) syncExistingSkill(
 		}
 		if !decision.Apply {
 			st.reporter.Infof("Skipping %s.", normalized)
+			st.skipped++
 			return nil
 		}
 		if decision.ApplyAll {
@@ -362,23 +599,71 @@ func (s *SkillSyncService) syncExistingSkill(
 		}
 	}
 
-	if st.req.Verbose {
-		st.reporter.Infof("Updating skill %s/%s/%s", projectIDN, flowIDN, skillIDN)
-	}
+	st.uploads = append(st.uploads, skillUpload{
+		remote:     remoteSkill,
+		meta:       *meta,
+		content:    content,
+		hash:       currentHash,
+		path:       normalized,
+		projectIDN: projectIDN,
+		agentIDN:   agentIDN,
+		flowIDN:    flowIDN,
+		skillIDN:   skillIDN,
+	})
+	return nil
+}
 
-	if err := s.pushSkill(ctx, remoteSkill, *meta, string(content)); err != nil {
-		return fmt.Errorf("push skill %s: %w", normalized, err)
+// uploadSkills sends the confirmed updates of a flow concurrently. Prompts have all been answered by
+// then, so only the uploads overlap. Updates that went through are recorded, in the order they were
+// confirmed, even when another one fails.
+func (s *SkillSyncService) uploadSkills(ctx context.Context, st *skillSyncState, flowData *state.FlowData) error {
+	uploads := st.uploads
+	st.uploads = nil
+	if len(uploads) == 0 {
+		return nil
 	}
 
-	st.newHashes[normalized] = currentHash
-	st.updated++
-	s.invalidateFlowSnapshot(st, flowData.ID)
-
-	if st.req.ShouldPublish && strings.TrimSpace(flowData.ID) != "" {
-		st.flowsToPublish[flowData.ID] = publishTarget{projectIDN: projectIDN, agentIDN: agentIDN, flowIDN: flowIDN}
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(st.limit(defaultUploadConcurrency))
+	for idx := range uploads {
+		upload := &uploads[idx]
+		g.Go(func() error {
+			if st.req.Verbose {
+				st.reporter.Infof("Updating skill %s/%s/%s", upload.projectIDN, upload.flowIDN, upload.skillIDN)
+			}
+			if err := s.pushSkill(gctx, upload.remote, upload.meta, string(upload.content)); err != nil {
+				return fmt.Errorf("push skill %s: %w", upload.path, err)
+			}
+			upload.done = true
+			return nil
+		})
 	}
+	err := g.Wait()
 
-	return nil
+	for _, upload := range uploads {
+		if !upload.done {
+			continue
+		}
+		st.newHashes[upload.path] = upload.hash
+		st.updated++
+		st.journal = append(st.journal, state.PushJournalEntry{
+			ProjectIDN:     upload.projectIDN,
+			AgentIDN:       upload.agentIDN,
+			FlowIDN:        upload.flowIDN,
+			FlowID:         flowData.ID,
+			SkillIDN:       upload.skillIDN,
+			SkillID:        upload.remote.ID,
+			Path:           upload.path,
+			PreviousScript: upload.remote.PromptScript,
+			PreviousHash:   util.ContentHashString(upload.remote.PromptScript),
+			PushedHash:     upload.hash,
+		})
+		if st.req.ShouldPublish && strings.TrimSpace(flowData.ID) != "" {
+			st.flowsToPublish[flowData.ID] = publishTarget{projectIDN: upload.projectIDN, agentIDN: upload.agentIDN, flowIDN: upload.flowIDN, metadataPath: flowMetadataBeside(upload.path)}
+		}
+	}
+	s.invalidateFlowSnapshot(st, flowData.ID)
+	return err
 }
 
 func (s *SkillSyncService) handleMissingFile(
@@ -433,6 +718,7 @@ func (s *SkillSyncService) createMissing(
 	st *skillSyncState,
 	projectIDN, projectSlug, agentIDN, flowIDN string,
 	flowData *state.FlowData,
+	renamed map[string]bool,
 ) (int, error) {
 	flowDir := fsutil.ExportFlowDir(st.req.OutputRoot, st.req.CustomerType, st.req.SessionIDN, projectSlug, agentIDN, flowIDN)
 	entries, err := os.ReadDir(flowDir)
@@ -443,7 +729,7 @@ func (s *SkillSyncService) createMissing(
 		return 0, fmt.Errorf("read flow directory: %w", err)
 	}
 
-	created := 0
+	var creations []skillCreation
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -453,14 +739,17 @@ func (s *SkillSyncService) createMissing(
 			continue
 		}
 		skillIDN := strings.TrimSuffix(name, fsutil.SkillMetaFileExt)
-		if _, exists := flowData.Skills[skillIDN]; exists {
+		if _, exists := flowData.Skills[skillIDN]; exists || renamed[skillIDN] {
+			continue
+		}
+		if st.tagScoped() && !st.skillTagged(flowDir, skillIDN) {
 			continue
 		}
 
 		metadataPath := filepath.Join(flowDir, name)
 		metaDoc, err := readSkillMetadata(metadataPath)
 		if err != nil {
-			return created, fmt.Errorf("decode metadata %s: %w", metadataPath, err)
+			return 0, fmt.Errorf("decode metadata %s: %w", metadataPath, err)
 		}
 
 		if strings.TrimSpace(metaDoc.IDN) == "" {
@@ -477,10 +766,11 @@ func (s *SkillSyncService) createMissing(
 		scriptBytes, err := os.ReadFile(scriptPath)
 		if err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
-				return created, fmt.Errorf("read script %s: %w", scriptPath, err)
+				return 0, fmt.Errorf("read script %s: %w", scriptPath, err)
 			}
 			scriptBytes = []byte{}
 		}
+		scriptBytes = util.NormalizeEOL(scriptBytes)
 
 		if st.req.Verbose {
 			st.reporter.Infof("Creating new skill %s/%s/%s", projectIDN, flowIDN, skillIDN)
@@ -503,17 +793,49 @@ func (s *SkillSyncService) createMissing(
 			Parameters: convertParametersForAPI(metaDoc.Parameters),
 		}
 
-		resp, err := s.client.CreateSkill(ctx, flowData.ID, createReq)
-		if err != nil {
-			return created, fmt.Errorf("create skill %s: %w", skillIDN, err)
+		creations = append(creations, skillCreation{skillIDN: skillIDN, metaDoc: metaDoc, title: title, script: scriptBytes, request: createReq})
+	}
+	if len(creations) == 0 {
+		return 0, nil
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(st.limit(defaultUploadConcurrency))
+	for idx := range creations {
+		creation := &creations[idx]
+		g.Go(func() error {
+			resp, err := s.client.CreateSkill(gctx, flowData.ID, creation.request)
+			if err != nil {
+				return fmt.Errorf("create skill %s: %w", creation.skillIDN, err)
+			}
+			creation.id = resp.ID
+			return nil
+		})
+	}
+	createErr := g.Wait()
+
+	created := 0
+	for _, creation := range creations {
+		if creation.id == "" {
+			continue
 		}
 		created++
-
-		if err := s.persistMetadata(flowDir, projectIDN, agentIDN, flowIDN, skillIDN, metaDoc, title, scriptBytes, resp.ID, flowData, st); err != nil {
+		if err := s.persistMetadata(flowDir, projectIDN, agentIDN, flowIDN, creation.skillIDN, creation.metaDoc, creation.title, creation.script, creation.id, flowData, st); err != nil {
 			return created, err
 		}
 	}
-	return created, nil
+	return created, createErr
+}
+
+// skillCreation is a skill found only locally, created together with the others of its flow. id is
+// set once the platform has created it.
+type skillCreation struct {
+	skillIDN string
+	metaDoc  skillMetadataDocument
+	title    string
+	script   []byte
+	request  platform.CreateSkillRequest
+	id       string
 }
 
 func (s *SkillSyncService) pushSkill(ctx context.Context, remote platform.Skill, meta state.SkillMetadataInfo, script string) error {
@@ -527,6 +849,7 @@ func (s *SkillSyncService) pushSkill(ctx context.Context, remote platform.Skill,
 		Parameters:   mergeParameters(remote.Parameters, meta.Parameters),
 		Path:         remote.Path,
 	}
+	logging.Debug("uploading skill", "skill", request.IDN, "id", remote.ID, "bytes", len(script))
 	return s.client.UpdateSkill(ctx, remote.ID, request)
 }
 
@@ -539,7 +862,7 @@ func (s *SkillSyncService) persistMetadata(
 	flowData *state.FlowData,
 	st *skillSyncState,
 ) error {
-	metaBytes, err := serialize.SkillMetadata(platform.Skill{
+	metaBytes, err := serialize.TaggedSkillMetadata(platform.Skill{
 		ID:           remoteID,
 		IDN:          metaDoc.IDN,
 		Title:        title,
@@ -550,7 +873,7 @@ func (s *SkillSyncService) persistMetadata(
 			ProviderIDN: metaDoc.Model.ProviderIDN,
 		},
 		Parameters: convertParametersForAPI(metaDoc.Parameters),
-	})
+	}, metaDoc.Tags)
 	if err != nil {
 		return fmt.Errorf("serialize metadata %s: %w", skillIDN, err)
 	}
@@ -559,7 +882,7 @@ func (s *SkillSyncService) persistMetadata(
 	if err := fsutil.EnsureParentDir(metadataPath); err != nil {
 		return err
 	}
-	if err := os.WriteFile(metadataPath, metaBytes, fsutil.FilePerm); err != nil {
+	if err := fsutil.AtomicWrite(metadataPath, metaBytes, fsutil.FilePerm); err != nil {
 		return fmt.Errorf("write metadata %s: %w", metadataPath, err)
 	}
 
@@ -568,7 +891,7 @@ func (s *SkillSyncService) persistMetadata(
 	if err := fsutil.EnsureParentDir(scriptPath); err != nil {
 		return err
 	}
-	if err := os.WriteFile(scriptPath, scriptBytes, fsutil.FilePerm); err != nil {
+	if err := fsutil.AtomicWrite(scriptPath, scriptBytes, fsutil.FilePerm); err != nil {
 		return fmt.Errorf("write script %s: %w", scriptPath, err)
 	}
 
@@ -584,16 +907,17 @@ func (s *SkillSyncService) persistMetadata(
 		Parameters: convertParametersForState(metaDoc.Parameters),
 	}
 
-	scriptHash := util.SHA256Bytes(scriptBytes)
-	metadataHash := util.SHA256Bytes(metaBytes)
+	scriptHash := util.ContentHash(scriptBytes)
+	metadataHash := util.ContentHash(metaBytes)
 	st.newHashes[filepath.ToSlash(scriptPath)] = scriptHash
 	st.newHashes[filepath.ToSlash(metadataPath)] = metadataHash
 
 	if strings.TrimSpace(flowData.ID) != "" {
 		st.flowsToPublish[flowData.ID] = publishTarget{
-			projectIDN: projectIDN,
-			agentIDN:   agentIDN,
-			flowIDN:    flowIDN,
+			projectIDN:   projectIDN,
+			agentIDN:     agentIDN,
+			flowIDN:      flowIDN,
+			metadataPath: filepath.Join(flowDir, fsutil.MetadataYAML),
 		}
 	}
 	return nil
@@ -608,6 +932,10 @@ func (s *SkillSyncService) persistState(st *skillSyncState) error {
 	if saveHashes == nil {
 		saveHashes = state.SaveHashes
 	}
+	savePushJournal := st.req.SavePushJournal
+	if savePushJournal == nil {
+		savePushJournal = state.AppendPush
+	}
 	regenerateFlows := st.req.RegenerateFlows
 	if regenerateFlows == nil {
 		regenerateFlows = func(customerType, customerIDN, projectIDN, projectSlug string, projectData state.ProjectData, hashes state.HashStore) error {
@@ -622,6 +950,12 @@ func (s *SkillSyncService) persistState(st *skillSyncState) error {
 	if err := saveHashes(st.req.SessionIDN, st.newHashes); err != nil {
 		errs = append(errs, fmt.Errorf("save hashes: %w", err))
 	}
+	if len(st.journal) > 0 {
+		record := state.PushRecord{CreatedAt: time.Now().UTC(), Entries: st.journal}
+		if err := savePushJournal(st.req.SessionIDN, record); err != nil {
+			errs = append(errs, fmt.Errorf("save push journal: %w", err))
+		}
+	}
 	if st.metadataChanged {
 		if err := s.regenerateFlows(regenerateFlows, st); err != nil {
 			errs = append(errs, err)
@@ -657,8 +991,24 @@ func (s *SkillSyncService) publishFlows(ctx context.Context, st *skillSyncState)
 	if !st.req.ShouldPublish || len(st.flowsToPublish) == 0 {
 		return 0, nil
 	}
+	targets := map[string]publishTarget{}
+	for flowID, target := range st.flowsToPublish {
+		if flowPublishDisabled(target.metadataPath) {
+			st.reporter.Infof("Not publishing %s/%s/%s: publish is disabled in its metadata.yaml", target.projectIDN, target.agentIDN, target.flowIDN)
+			continue
+		}
+		targets[flowID] = target
+	}
+	return s.publishTargets(ctx, targets, st.limit(concurrencyCap()), st.req.Verbose, st.reporter)
+}
+
+// publishTargets publishes the given flows concurrently and reports how many succeeded.
+func (s *SkillSyncService) publishTargets(ctx context.Context, flows map[string]publishTarget, limit int, verbose bool, reporter Reporter) (int, error) {
+	if len(flows) == 0 {
+		return 0, nil
+	}
 
-	maxConcurrency := min(len(st.flowsToPublish), concurrencyCap())
+	maxConcurrency := min(len(flows), limit)
 	g, gctx := errgroup.WithContext(ctx)
 	sem := make(chan struct{}, maxConcurrency)
 
@@ -667,7 +1017,7 @@ func (s *SkillSyncService) publishFlows(ctx context.Context, st *skillSyncState)
 	var errs []error
 	var errsMu sync.Mutex
 
-	for flowID, meta := range st.flowsToPublish {
+	for flowID, meta := range flows {
 		flowID := flowID
 		meta := meta
 		sem <- struct{}{}
@@ -679,8 +1029,8 @@ func (s *SkillSyncService) publishFlows(ctx context.Context, st *skillSyncState)
 				errsMu.Unlock()
 				return nil
 			}
-			if st.req.Verbose {
-				st.reporter.Infof("Published %s/%s/%s", meta.projectIDN, meta.agentIDN, meta.flowIDN)
+			if verbose {
+				reporter.Infof("Published %s/%s/%s", meta.projectIDN, meta.agentIDN, meta.flowIDN)
 			}
 			publishedMu.Lock()
 			published++
@@ -849,6 +1199,7 @@ type skillMetadataDocument struct {
 	RunnerType string                   `yaml:"runner_type"`
 	Model      skillMetadataModel       `yaml:"model"`
 	Parameters []skillParameterMetadata `yaml:"parameters"`
+	Tags       []string                 `yaml:"tags"`
 }
 
 type skillMetadataModel struct {
@@ -957,10 +1308,10 @@ func regenerateFlowsYAML(outputRoot, customerType, customerIDN, projectIDN, proj
 	if err := fsutil.EnsureParentDir(path); err != nil {
 		return err
 	}
-	if err := os.WriteFile(path, content, fsutil.FilePerm); err != nil {
+	if err := fsutil.AtomicWrite(path, content, fsutil.FilePerm); err != nil {
 		return fmt.Errorf("write flows.yaml: %w", err)
 	}
-	hashes[filepath.ToSlash(path)] = util.SHA256Bytes(content)
+	hashes[filepath.ToSlash(path)] = util.ContentHash(content)
 	return nil
 }
 
@@ -988,6 +1339,11 @@ func (noopReporter) Infof(string, ...any)    {}
 func (noopReporter) Warnf(string, ...any)    {}
 func (noopReporter) Successf(string, ...any) {}
 
+type noopProgress struct{}
+
+func (noopProgress) Add(string, int)  {}
+func (noopProgress) Done(string, int) {}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -3,10 +3,12 @@ package sync
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -90,8 +92,9 @@ func TestSkillSyncService_UpdateExistingSkill(t *testing.T) {
 	}
 
 	var (
-		savedHashes state.HashStore
-		saveMu      sync.Mutex
+		savedHashes  state.HashStore
+		savedJournal state.PushRecord
+		saveMu       sync.Mutex
 	)
 
 	req := SkillSyncRequest{
@@ -122,6 +125,12 @@ func TestSkillSyncService_UpdateExistingSkill(t *testing.T) {
 			savedHashes = cloneHashes(h)
 			return nil
 		},
+		SavePushJournal: func(_ string, record state.PushRecord) error {
+			saveMu.Lock()
+			defer saveMu.Unlock()
+			savedJournal = record
+			return nil
+		},
 	}
 
 	service := NewSkillSyncService(client, nil)
@@ -147,6 +156,44 @@ func TestSkillSyncService_UpdateExistingSkill(t *testing.T) {
 	if savedHashes[filepath.ToSlash(scriptPath)] != util.SHA256String(localScript) {
 		t.Fatalf("hash not updated")
 	}
+	if len(savedJournal.Entries) != 1 || savedJournal.Entries[0].PreviousScript != remoteSkill.PromptScript {
+		t.Fatalf("expected journal entry with previous script, got %+v", savedJournal.Entries)
+	}
+}
+
+func TestSkillSyncService_UndoPush(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeSkillClient()
+	client.addFlowSkill("flow-id", platform.Skill{ID: "skill-id", IDN: "skill", PromptScript: "pushed"})
+	client.addFlowSkill("flow-id", platform.Skill{ID: "other-id", IDN: "other", PromptScript: "edited remotely"})
+
+	record := state.PushRecord{Entries: []state.PushJournalEntry{
+		{FlowID: "flow-id", SkillID: "skill-id", Path: "a.nsl", PreviousScript: "original", PreviousHash: util.SHA256String("original"), PushedHash: util.SHA256String("pushed")},
+		{FlowID: "flow-id", SkillID: "other-id", Path: "b.nsl", PreviousScript: "older", PreviousHash: util.SHA256String("older"), PushedHash: util.SHA256String("pushed too")},
+	}}
+
+	service := NewSkillSyncService(client, nil)
+	result, err := service.UndoPush(context.Background(), UndoRequest{
+		Record:        record,
+		Hashes:        state.HashStore{"a.nsl": util.SHA256String("pushed")},
+		ShouldPublish: true,
+	})
+	if err != nil {
+		t.Fatalf("UndoPush: %v", err)
+	}
+	if result.Reverted != 1 || result.Skipped != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(client.updateCalls) != 1 || client.updateCalls[0].PromptScript != "original" {
+		t.Fatalf("unexpected update calls: %+v", client.updateCalls)
+	}
+	if result.Hashes["a.nsl"] != util.SHA256String("original") {
+		t.Fatalf("expected hash reset to previous version")
+	}
+	if len(client.publishCalls) != 1 {
+		t.Fatalf("expected flow to be republished, got %v", client.publishCalls)
+	}
 }
 
 func TestSkillSyncService_DeleteMissingSkill(t *testing.T) {
@@ -379,7 +426,771 @@ func TestSkillSyncService_CreateMissingSkill(t *testing.T) {
 	}
 }
 
+func TestSkillSyncService_PushFlowEventsAndStates(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	client.flowEvents["flow-id"] = []platform.FlowEvent{
+		{ID: "ev-keep", IDN: "keep", SkillIDN: "skill", SkillSelector: "skill_idn"},
+		{ID: "ev-edit", IDN: "edit", SkillIDN: "skill", SkillSelector: "skill_idn"},
+		{ID: "ev-drop", IDN: "drop", SkillIDN: "skill", SkillSelector: "skill_idn"},
+	}
+	client.flowStates["flow-id"] = []platform.FlowState{
+		{ID: "st-user", IDN: "user_name", Title: "User", Scope: "user"},
+		{ID: "st-old", IDN: "obsolete", Title: "Old", Scope: "flow"},
+	}
+
+	projectMap := state.ProjectMap{
+		Projects: map[string]state.ProjectData{
+			"project": {
+				ProjectIDN: "project",
+				Path:       "project",
+				Agents: map[string]state.AgentData{
+					"agent": {
+						Flows: map[string]state.FlowData{
+							"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	metadataPath := fsutil.ExportFlowMetadataPath(outputRoot, "integration", "customer", "project", "agent", "flow")
+	if err := fsutil.EnsureParentDir(metadataPath); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	metadata := `idn: flow
+events:
+  - idn: keep
+    skillselector: skill_idn
+    skillidn: skill
+  - idn: edit
+    skillselector: skill_idn
+    skillidn: other
+  - idn: added
+    skillselector: skill_idn
+    skillidn: skill
+    stateidn: counter
+state_fields:
+  - id: st-user
+    idn: user_name
+    title: User name
+    scope: user
+  - idn: counter
+    title: Counter
+    defaultvalue: "0"
+    scope: flow
+`
+	if err := os.WriteFile(metadataPath, []byte(metadata), fsutil.FilePerm); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+
+	var confirmed ConfirmFlowChangesRequest
+	var savedMap state.ProjectMap
+	req := SkillSyncRequest{
+		SessionIDN:   "customer",
+		CustomerType: "integration",
+		OutputRoot:   outputRoot,
+		ProjectMap:   &projectMap,
+		Hashes:       state.HashStore{filepath.ToSlash(metadataPath): "pulled"},
+		ConfirmFlowChanges: func(req ConfirmFlowChangesRequest) (Decision, error) {
+			confirmed = req
+			return Decision{Apply: true}, nil
+		},
+		SaveProjectMap:  func(_ string, pm state.ProjectMap) error { savedMap = pm; return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		RegenerateFlows: func(string, string, string, string, state.ProjectData, state.HashStore) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if result.FlowChanges != 6 || len(confirmed.Changes) != 6 {
+		t.Fatalf("expected 6 flow changes, got %d (confirmed %+v)", result.FlowChanges, confirmed.Changes)
+	}
+
+	expectedStates := []string{"delete st-old", "update user_name st-user", "create counter"}
+	if fmt.Sprint(client.stateCalls) != fmt.Sprint(expectedStates) {
+		t.Fatalf("unexpected state calls: %v", client.stateCalls)
+	}
+	expectedEvents := []string{"delete ev-drop", "update edit ev-edit", "create added"}
+	if fmt.Sprint(client.eventCalls) != fmt.Sprint(expectedEvents) {
+		t.Fatalf("unexpected event calls: %v", client.eventCalls)
+	}
+
+	flow := savedMap.Projects["project"].Agents["agent"].Flows["flow"]
+	if len(flow.Events) != 3 || len(flow.StateFields) != 2 {
+		t.Fatalf("project map not updated: %+v", flow)
+	}
+	if flow.StateFields[1].IDN != "counter" || flow.StateFields[1].ID == "" {
+		t.Fatalf("expected created state id to be recorded, got %+v", flow.StateFields[1])
+	}
+	if result.Hashes[filepath.ToSlash(metadataPath)] != util.SHA256String(metadata) {
+		t.Fatalf("metadata hash not refreshed")
+	}
+}
+
+func TestSkillSyncService_PushFlowDefaults(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+
+	projectMap := state.ProjectMap{
+		Projects: map[string]state.ProjectData{
+			"project": {
+				ProjectIDN: "project",
+				Path:       "project",
+				Agents: map[string]state.AgentData{
+					"agent": {
+						Flows: map[string]state.FlowData{
+							"flow": {
+								ID:         "flow-id",
+								Title:      "Flow",
+								RunnerType: "guidance",
+								Model:      map[string]string{"model_idn": "gpt4o", "provider_idn": "openai"},
+								Skills:     map[string]state.SkillMetadataInfo{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	metadataPath := fsutil.ExportFlowMetadataPath(outputRoot, "integration", "customer", "project", "agent", "flow")
+	if err := fsutil.EnsureParentDir(metadataPath); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	metadata := "idn: flow\ntitle: Flow\ndefault_runner_type: nsl\ndefault_model:\n  model_idn: gpt5\n  provider_idn: \"\"\n"
+	if err := os.WriteFile(metadataPath, []byte(metadata), fsutil.FilePerm); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+
+	var confirmed ConfirmFlowChangesRequest
+	var savedMap state.ProjectMap
+	req := SkillSyncRequest{
+		SessionIDN:   "customer",
+		CustomerType: "integration",
+		OutputRoot:   outputRoot,
+		ProjectMap:   &projectMap,
+		Hashes:       state.HashStore{filepath.ToSlash(metadataPath): "pulled"},
+		ConfirmFlowChanges: func(req ConfirmFlowChangesRequest) (Decision, error) {
+			confirmed = req
+			return Decision{Apply: true}, nil
+		},
+		SaveProjectMap:  func(_ string, pm state.ProjectMap) error { savedMap = pm; return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		RegenerateFlows: func(string, string, string, string, state.ProjectData, state.HashStore) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if result.FlowChanges != 1 || len(confirmed.Diff) == 0 {
+		t.Fatalf("expected one confirmed settings change with a diff, got %d (%+v)", result.FlowChanges, confirmed)
+	}
+	if len(client.flowUpdates) != 1 {
+		t.Fatalf("expected one UpdateFlow call, got %d", len(client.flowUpdates))
+	}
+	update := client.flowUpdates[0]
+	if update.DefaultRunnerType != "nsl" || update.DefaultModel.ModelIDN != "gpt5" || update.DefaultModel.ProviderIDN != "openai" {
+		t.Fatalf("unexpected update payload: %+v", update)
+	}
+	flow := savedMap.Projects["project"].Agents["agent"].Flows["flow"]
+	if flow.RunnerType != "nsl" || flow.Model["model_idn"] != "gpt5" {
+		t.Fatalf("project map not updated: %+v", flow)
+	}
+}
+
+func TestSkillSyncService_CreateAndDeleteAgents(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+
+	projectMap := state.ProjectMap{
+		Projects: map[string]state.ProjectData{
+			"project": {
+				ProjectID:  "proj-uuid",
+				ProjectIDN: "project",
+				Path:       "project",
+				Agents: map[string]state.AgentData{
+					"Old": {
+						ID: "old-id",
+						Flows: map[string]state.FlowData{
+							"OldFlow": {ID: "old-flow", Skills: map[string]state.SkillMetadataInfo{
+								"Greet": {ID: "greet-id", IDN: "Greet", RunnerType: "nsl"},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	flowDir := fsutil.ExportFlowDir(outputRoot, "", "customer", "project", "New", "NewFlow")
+	files := map[string]string{
+		fsutil.MetadataYAML: "idn: NewFlow\ntitle: New flow\nstate_fields:\n  - idn: counter\n    title: Counter\n    scope: flow\n",
+		"Greet.meta.yaml":   "idn: Greet\ntitle: Greet\nrunner_type: nsl\n",
+		"Greet.nsl":         "{{ true }}\n",
+	}
+	if err := fsutil.EnsureDir(flowDir); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(flowDir, name), []byte(content), fsutil.FilePerm); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	var deletionPrompts []string
+	var savedMap state.ProjectMap
+	req := SkillSyncRequest{
+		SessionIDN: "customer",
+		OutputRoot: outputRoot,
+		ProjectMap: &projectMap,
+		Hashes:     state.HashStore{},
+		ConfirmAgentDeletion: func(_ string, agentIDN string) (Decision, error) {
+			deletionPrompts = append(deletionPrompts, agentIDN)
+			return Decision{Apply: true}, nil
+		},
+		ConfirmDeletion: func(string, string) (Decision, error) {
+			t.Fatalf("skills of a deleted agent must not be prompted individually")
+			return Decision{}, nil
+		},
+		SaveProjectMap:  func(_ string, pm state.ProjectMap) error { savedMap = pm; return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		RegenerateFlows: func(string, string, string, string, state.ProjectData, state.HashStore) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if result.AgentsCreated != 1 || result.AgentsRemoved != 1 || result.Created != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	expectedCalls := []string{"create agent New in proj-uuid", "create flow NewFlow in agent-New", "delete agent old-id"}
+	if fmt.Sprint(client.agentCalls) != fmt.Sprint(expectedCalls) {
+		t.Fatalf("unexpected agent calls: %v", client.agentCalls)
+	}
+	if fmt.Sprint(deletionPrompts) != "[Old]" {
+		t.Fatalf("expected one deletion prompt for Old, got %v", deletionPrompts)
+	}
+	if fmt.Sprint(client.stateCalls) != "[create counter]" {
+		t.Fatalf("expected state field to be created, got %v", client.stateCalls)
+	}
+
+	agents := savedMap.Projects["project"].Agents
+	if _, exists := agents["Old"]; exists {
+		t.Fatalf("deleted agent still in project map")
+	}
+	flow := agents["New"].Flows["NewFlow"]
+	if agents["New"].ID != "agent-New" || flow.ID != "flow-NewFlow" || flow.Skills["Greet"].ID == "" {
+		t.Fatalf("new agent not recorded: %+v", agents["New"])
+	}
+}
+
+func TestSkillSyncService_CreateFlowInExistingAgent(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+
+	projectMap := state.ProjectMap{
+		Projects: map[string]state.ProjectData{
+			"project": {
+				ProjectID:  "proj-uuid",
+				ProjectIDN: "project",
+				Path:       "project",
+				Agents: map[string]state.AgentData{
+					"Agent": {ID: "agent-id", Flows: map[string]state.FlowData{}},
+				},
+			},
+		},
+	}
+
+	flowDir := fsutil.ExportFlowDir(outputRoot, "", "customer", "project", "Agent", "Added")
+	if err := fsutil.EnsureDir(flowDir); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	files := map[string]string{
+		fsutil.MetadataYAML: "idn: Added\ntitle: Added flow\ndefault_runner_type: nsl\n",
+		"Hello.meta.yaml":   "idn: Hello\ntitle: Hello\nrunner_type: nsl\n",
+		"Hello.nsl":         "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(flowDir, name), []byte(content), fsutil.FilePerm); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	var savedMap state.ProjectMap
+	req := SkillSyncRequest{
+		SessionIDN:      "customer",
+		OutputRoot:      outputRoot,
+		ProjectMap:      &projectMap,
+		Hashes:          state.HashStore{},
+		SaveProjectMap:  func(_ string, pm state.ProjectMap) error { savedMap = pm; return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		RegenerateFlows: func(string, string, string, string, state.ProjectData, state.HashStore) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if result.FlowsCreated != 1 || result.Created != 1 || result.AgentsCreated != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if fmt.Sprint(client.agentCalls) != "[create flow Added in agent-id]" {
+		t.Fatalf("unexpected agent calls: %v", client.agentCalls)
+	}
+	flow := savedMap.Projects["project"].Agents["Agent"].Flows["Added"]
+	if flow.ID != "flow-Added" || flow.Skills["Hello"].ID == "" {
+		t.Fatalf("new flow not recorded: %+v", flow)
+	}
+}
+
 // fakeSkillClient provides a thread-safe test double for SkillSyncClient.
+func TestSkillSyncService_SavesStateWhenInterrupted(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	skills := map[string]state.SkillMetadataInfo{}
+	hashes := state.HashStore{}
+	paths := map[string]string{}
+	for _, idn := range []string{"first", "second"} {
+		remote := platform.Skill{ID: idn + "-id", IDN: idn, PromptScript: "old " + idn, RunnerType: "nsl"}
+		client.addFlowSkill("flow-id", remote)
+		skills[idn] = state.SkillMetadataInfo{ID: remote.ID, IDN: idn, RunnerType: "nsl"}
+		path := fsutil.ExportSkillScriptPath(outputRoot, "integration", "customer", "project", "agent", "flow", idn+".nsl")
+		if err := fsutil.EnsureParentDir(path); err != nil {
+			t.Fatalf("ensure dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("new "+idn), fsutil.FilePerm); err != nil {
+			t.Fatalf("write script: %v", err)
+		}
+		hashes[filepath.ToSlash(path)] = util.SHA256String(remote.PromptScript)
+		paths[idn] = filepath.ToSlash(path)
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: skills}}},
+		}},
+	}}
+
+	var savedHashes state.HashStore
+	confirmed := 0
+	req := SkillSyncRequest{
+		SessionIDN:   "customer",
+		CustomerType: "integration",
+		OutputRoot:   outputRoot,
+		ProjectMap:   &projectMap,
+		Hashes:       hashes,
+		ConfirmPush: func(ConfirmPushRequest) (Decision, error) {
+			confirmed++
+			if confirmed > 1 {
+				return Decision{}, context.Canceled
+			}
+			return Decision{Apply: true}, nil
+		},
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(_ string, h state.HashStore) error { savedHashes = cloneHashes(h); return nil },
+		SavePushJournal: func(string, state.PushRecord) error { return nil },
+	}
+
+	_, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the interruption to be returned, got %v", err)
+	}
+	if len(client.updateCalls) != 1 {
+		t.Fatalf("expected one skill to be pushed before the interruption, got %d", len(client.updateCalls))
+	}
+	if savedHashes == nil {
+		t.Fatalf("expected hashes to be saved after the interruption")
+	}
+	updated := 0
+	for idn, path := range paths {
+		if savedHashes[path] == util.SHA256String("new "+idn) {
+			updated++
+		}
+	}
+	if updated != 1 {
+		t.Fatalf("expected exactly the pushed skill to have a new hash, got %v", savedHashes)
+	}
+}
+
+func TestSkillSyncService_SkipsUploadWhenRemoteMatches(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	client.addFlowSkill("flow-id", platform.Skill{ID: "skill-id", IDN: "skill", PromptScript: "already pushed", RunnerType: "nsl"})
+	path := fsutil.ExportSkillScriptPath(outputRoot, "integration", "customer", "project", "agent", "flow", "skill.nsl")
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("already pushed"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{
+				"skill": {ID: "skill-id", IDN: "skill", RunnerType: "nsl"},
+			}}}},
+		}},
+	}}
+
+	var savedHashes state.HashStore
+	req := SkillSyncRequest{
+		SessionIDN:   "customer",
+		CustomerType: "integration",
+		OutputRoot:   outputRoot,
+		ProjectMap:   &projectMap,
+		Hashes:       state.HashStore{filepath.ToSlash(path): util.SHA256String("before the interrupted push")},
+		Force:        true,
+		ConfirmPush: func(ConfirmPushRequest) (Decision, error) {
+			t.Fatalf("unexpected confirmation for content the platform already has")
+			return Decision{}, nil
+		},
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(_ string, h state.HashStore) error { savedHashes = cloneHashes(h); return nil },
+		SavePushJournal: func(string, state.PushRecord) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if len(client.updateCalls) != 0 || result.Updated != 0 {
+		t.Fatalf("expected no upload, got %d calls and %d updated", len(client.updateCalls), result.Updated)
+	}
+	if len(result.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %+v", result.Warnings)
+	}
+	if savedHashes[filepath.ToSlash(path)] != util.SHA256String("already pushed") {
+		t.Fatalf("expected the hash to record the remote content, got %v", savedHashes)
+	}
+}
+
+func TestSkillSyncService_CountsSkippedAndConflictedSkills(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	client.addFlowSkill("flow-id", platform.Skill{ID: "declined-id", IDN: "declined", PromptScript: "pulled", RunnerType: "nsl"})
+	client.addFlowSkill("flow-id", platform.Skill{ID: "moved-id", IDN: "moved", PromptScript: "edited remotely", RunnerType: "nsl"})
+	hashes := state.HashStore{}
+	for _, idn := range []string{"declined", "moved"} {
+		path := fsutil.ExportSkillScriptPath(outputRoot, "integration", "customer", "project", "agent", "flow", idn+".nsl")
+		if err := fsutil.EnsureParentDir(path); err != nil {
+			t.Fatalf("ensure dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("edited locally"), fsutil.FilePerm); err != nil {
+			t.Fatalf("write script: %v", err)
+		}
+		hashes[filepath.ToSlash(path)] = util.SHA256String("pulled")
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{
+				"declined": {ID: "declined-id", IDN: "declined", RunnerType: "nsl"},
+				"moved":    {ID: "moved-id", IDN: "moved", RunnerType: "nsl"},
+			}}}},
+		}},
+	}}
+
+	req := SkillSyncRequest{
+		SessionIDN:      "customer",
+		CustomerType:    "integration",
+		OutputRoot:      outputRoot,
+		ProjectMap:      &projectMap,
+		Hashes:          hashes,
+		ConfirmPush:     func(ConfirmPushRequest) (Decision, error) { return Decision{}, nil },
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		SavePushJournal: func(string, state.PushRecord) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if len(client.updateCalls) != 0 {
+		t.Fatalf("expected no upload, got %d calls", len(client.updateCalls))
+	}
+	if result.Skipped != 1 || result.Conflicted != 1 {
+		t.Fatalf("expected one skipped and one conflicted skill, got %d and %d", result.Skipped, result.Conflicted)
+	}
+}
+
+func TestSkillSyncService_PushesOnlyTaggedSkills(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	hashes := state.HashStore{}
+	for _, idn := range []string{"checkout", "other"} {
+		client.addFlowSkill("flow-id", platform.Skill{ID: idn + "-id", IDN: idn, PromptScript: "pulled", RunnerType: "nsl"})
+		path := fsutil.ExportSkillScriptPath(outputRoot, "integration", "customer", "project", "agent", "flow", idn+".nsl")
+		if err := fsutil.EnsureParentDir(path); err != nil {
+			t.Fatalf("ensure dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("edited locally"), fsutil.FilePerm); err != nil {
+			t.Fatalf("write script: %v", err)
+		}
+		hashes[filepath.ToSlash(path)] = util.SHA256String("pulled")
+	}
+	metaPath := fsutil.ExportSkillMetadataPath(outputRoot, "integration", "customer", "project", "agent", "flow", "checkout")
+	if err := os.WriteFile(metaPath, []byte("idn: checkout\ntags:\n  - Checkout\n"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{
+				"checkout": {ID: "checkout-id", IDN: "checkout", RunnerType: "nsl"},
+				"other":    {ID: "other-id", IDN: "other", RunnerType: "nsl"},
+			}}}},
+		}},
+	}}
+
+	req := SkillSyncRequest{
+		SessionIDN:      "customer",
+		CustomerType:    "integration",
+		OutputRoot:      outputRoot,
+		ProjectMap:      &projectMap,
+		Hashes:          hashes,
+		ConfirmPush:     func(ConfirmPushRequest) (Decision, error) { return Decision{Apply: true}, nil },
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		SavePushJournal: func(string, state.PushRecord) error { return nil },
+		Tags:            []string{"checkout"},
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if len(client.updateCalls) != 1 || result.Updated != 1 {
+		t.Fatalf("expected only the tagged skill to upload, got %d calls and %d updated", len(client.updateCalls), result.Updated)
+	}
+	if client.updateCalls[0].IDN != "checkout" {
+		t.Fatalf("expected checkout to upload, got %s", client.updateCalls[0].IDN)
+	}
+	tags, err := state.LoadSkillTags(metaPath)
+	if err != nil || len(tags) != 1 || tags[0] != "Checkout" {
+		t.Fatalf("expected the push to keep the tags, got %v (%v)", tags, err)
+	}
+}
+
+func TestSkillSyncService_UploadsResolvedConflict(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	client.addFlowSkill("flow-id", platform.Skill{ID: "skill-id", IDN: "greet", PromptScript: "Hello from the platform", RunnerType: "nsl"})
+	path := fsutil.ExportSkillScriptPath(outputRoot, "integration", "customer", "project", "agent", "flow", "greet.nsl")
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("Hello from here"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	hashes := state.HashStore{filepath.ToSlash(path): util.SHA256String("Hello")}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{
+				"greet": {ID: "skill-id", IDN: "greet", RunnerType: "nsl"},
+			}}}},
+		}},
+	}}
+
+	var resolved ResolveConflictRequest
+	req := SkillSyncRequest{
+		SessionIDN:   "customer",
+		CustomerType: "integration",
+		OutputRoot:   outputRoot,
+		ProjectMap:   &projectMap,
+		Hashes:       hashes,
+		ConfirmPush: func(ConfirmPushRequest) (Decision, error) {
+			t.Fatal("expected a resolved conflict to be uploaded without another prompt")
+			return Decision{}, nil
+		},
+		ResolveConflict: func(req ResolveConflictRequest) ([]byte, error) {
+			resolved = req
+			return []byte("Hello from both"), nil
+		},
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		SavePushJournal: func(string, state.PushRecord) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if resolved.BaseHash != util.SHA256String("Hello") || string(resolved.Local) != "Hello from here" || string(resolved.Remote) != "Hello from the platform" {
+		t.Fatalf("unexpected conflict request: %+v", resolved)
+	}
+	if len(client.updateCalls) != 1 || client.updateCalls[0].PromptScript != "Hello from both" {
+		t.Fatalf("expected the merged script to be uploaded, got %+v", client.updateCalls)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "Hello from both" {
+		t.Fatalf("expected the merged script on disk, got %q", content)
+	}
+	if result.Conflicted != 0 || result.Hashes[filepath.ToSlash(path)] != util.SHA256String("Hello from both") {
+		t.Fatalf("expected the conflict to be resolved, got %d conflicts and hashes %v", result.Conflicted, result.Hashes)
+	}
+}
+
+func TestSkillSyncService_RenamesSkillByID(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	client.addFlowSkill("flow-id", platform.Skill{ID: "skill-id", IDN: "greet", Title: "Greet", PromptScript: "Hello", RunnerType: "nsl"})
+	flowDir := fsutil.ExportFlowDir(outputRoot, "integration", "customer", "project", "agent", "flow")
+	writeFile := func(name, content string) {
+		path := filepath.Join(flowDir, name)
+		if err := fsutil.EnsureParentDir(path); err != nil {
+			t.Fatalf("ensure dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), fsutil.FilePerm); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	writeFile("welcome.nsl", "Hello")
+	writeFile("welcome.meta.yaml", "id: skill-id\nidn: greet\ntitle: Greet\nrunner_type: nsl\n")
+	oldScript := filepath.ToSlash(filepath.Join(flowDir, "greet.nsl"))
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{
+				"greet": {ID: "skill-id", IDN: "greet", Title: "Greet", RunnerType: "nsl"},
+			}}}},
+		}},
+	}}
+
+	var savedHashes state.HashStore
+	req := SkillSyncRequest{
+		SessionIDN:   "customer",
+		CustomerType: "integration",
+		OutputRoot:   outputRoot,
+		ProjectMap:   &projectMap,
+		Hashes:       state.HashStore{oldScript: util.SHA256String("Hello")},
+		ConfirmPush:  func(ConfirmPushRequest) (Decision, error) { return Decision{Apply: true}, nil },
+		ConfirmDeletion: func(string, string) (Decision, error) {
+			t.Fatalf("unexpected deletion prompt for a renamed skill")
+			return Decision{}, nil
+		},
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(_ string, h state.HashStore) error { savedHashes = cloneHashes(h); return nil },
+		SavePushJournal: func(string, state.PushRecord) error { return nil },
+		RegenerateFlows: func(string, string, string, string, state.ProjectData, state.HashStore) error { return nil },
+	}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if result.Renamed != 1 || result.Created != 0 || result.Removed != 0 {
+		t.Fatalf("expected one rename and nothing created or removed, got %+v", result)
+	}
+	if len(client.updateCalls) != 1 || client.updateCalls[0].ID != "skill-id" || client.updateCalls[0].IDN != "welcome" {
+		t.Fatalf("expected the existing skill to be renamed, got %+v", client.updateCalls)
+	}
+	skills := projectMap.Projects["project"].Agents["agent"].Flows["flow"].Skills
+	if _, ok := skills["greet"]; ok || skills["welcome"].ID != "skill-id" || skills["welcome"].IDN != "welcome" {
+		t.Fatalf("expected the map entry to move to the new name, got %+v", skills)
+	}
+	if _, ok := savedHashes[oldScript]; ok || savedHashes[filepath.ToSlash(filepath.Join(flowDir, "welcome.nsl"))] != util.SHA256String("Hello") {
+		t.Fatalf("expected the hashes to follow the rename, got %v", savedHashes)
+	}
+}
+
+func TestSkillSyncService_SkipsPlatformManagedSkillsAndKeys(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	client.addFlowSkill("flow-id", platform.Skill{ID: "greet-id", IDN: "greet", PromptScript: "Hello", RunnerType: "nsl"})
+	client.addFlowSkill("flow-id", platform.Skill{ID: "faq-id", IDN: "faq", PromptScript: "FAQ", RunnerType: "nsl"})
+	client.flowEvents["flow-id"] = []platform.FlowEvent{{ID: "ev-keep", IDN: "keep"}}
+
+	flowDir := fsutil.ExportFlowDir(outputRoot, "integration", "customer", "project", "agent", "flow")
+	files := map[string]string{
+		"greet.nsl": "Hello, edited",
+		"faq.nsl":   "FAQ, edited",
+		"metadata.yaml": `idn: flow
+events:
+  - idn: added
+state_fields:
+  - idn: counter
+    title: Counter
+    scope: flow
+ownership:
+  skills:
+    greet: platform
+  keys:
+    events: platform
+`,
+	}
+	hashes := state.HashStore{}
+	for name, content := range files {
+		path := filepath.Join(flowDir, name)
+		if err := fsutil.EnsureParentDir(path); err != nil {
+			t.Fatalf("ensure dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), fsutil.FilePerm); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		hashes[filepath.ToSlash(path)] = "pulled"
+	}
+	hashes[filepath.ToSlash(filepath.Join(flowDir, "greet.nsl"))] = util.SHA256String("Hello")
+	hashes[filepath.ToSlash(filepath.Join(flowDir, "faq.nsl"))] = util.SHA256String("FAQ")
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{
+				"greet": {ID: "greet-id", IDN: "greet", RunnerType: "nsl"},
+				"faq":   {ID: "faq-id", IDN: "faq", RunnerType: "nsl"},
+			}}}},
+		}},
+	}}
+
+	req := SkillSyncRequest{
+		SessionIDN:         "customer",
+		CustomerType:       "integration",
+		OutputRoot:         outputRoot,
+		ProjectMap:         &projectMap,
+		Hashes:             hashes,
+		ConfirmPush:        func(ConfirmPushRequest) (Decision, error) { return Decision{Apply: true}, nil },
+		ConfirmFlowChanges: func(ConfirmFlowChangesRequest) (Decision, error) { return Decision{Apply: true}, nil },
+		SaveProjectMap:     func(string, state.ProjectMap) error { return nil },
+		SaveHashes:         func(string, state.HashStore) error { return nil },
+		SavePushJournal:    func(string, state.PushRecord) error { return nil },
+		RegenerateFlows:    func(string, string, string, string, state.ProjectData, state.HashStore) error { return nil },
+	}
+
+	if _, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), req); err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if len(client.updateCalls) != 1 || client.updateCalls[0].ID != "faq-id" {
+		t.Fatalf("expected only the unmarked skill to be pushed, got %+v", client.updateCalls)
+	}
+	if len(client.eventCalls) != 0 || fmt.Sprint(client.stateCalls) != "[create counter]" {
+		t.Fatalf("expected platform-managed events to be left alone, got events %v and states %v", client.eventCalls, client.stateCalls)
+	}
+	if events := projectMap.Projects["project"].Agents["agent"].Flows["flow"].Events; len(events) != 0 {
+		t.Fatalf("expected the recorded events to stay as pulled, got %+v", events)
+	}
+}
+
 type fakeSkillClient struct {
 	mu           sync.Mutex
 	flowSkills   map[string][]platform.Skill
@@ -388,6 +1199,14 @@ type fakeSkillClient struct {
 	deleteCalls  []string
 	publishCalls []string
 
+	flowEvents  map[string][]platform.FlowEvent
+	flowStates  map[string][]platform.FlowState
+	eventCalls  []string
+	stateCalls  []string
+	nextFlowRef int
+	flowUpdates []platform.UpdateFlowRequest
+	agentCalls  []string
+
 	deleteHook func(skillID string)
 	createHook func(req platform.CreateSkillRequest) string
 }
@@ -396,6 +1215,8 @@ func newFakeSkillClient() *fakeSkillClient {
 	return &fakeSkillClient{
 		flowSkills: make(map[string][]platform.Skill),
 		skillsByID: make(map[string]platform.Skill),
+		flowEvents: make(map[string][]platform.FlowEvent),
+		flowStates: make(map[string][]platform.FlowState),
 	}
 }
 
@@ -470,3 +1291,221 @@ func (f *fakeSkillClient) PublishFlow(_ context.Context, flowID string, _ platfo
 	f.publishCalls = append(f.publishCalls, flowID)
 	return nil
 }
+
+func (f *fakeSkillClient) CreateAgent(_ context.Context, projectID string, payload platform.CreateAgentRequest) (platform.CreateAgentResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.agentCalls = append(f.agentCalls, "create agent "+payload.IDN+" in "+projectID)
+	return platform.CreateAgentResponse{ID: "agent-" + payload.IDN}, nil
+}
+
+func (f *fakeSkillClient) DeleteAgent(_ context.Context, agentID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.agentCalls = append(f.agentCalls, "delete agent "+agentID)
+	return nil
+}
+
+func (f *fakeSkillClient) CreateFlow(_ context.Context, agentID string, payload platform.CreateFlowRequest) (platform.CreateFlowResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.agentCalls = append(f.agentCalls, "create flow "+payload.IDN+" in "+agentID)
+	return platform.CreateFlowResponse{ID: "flow-" + payload.IDN}, nil
+}
+
+func (f *fakeSkillClient) UpdateFlow(_ context.Context, _ string, payload platform.UpdateFlowRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flowUpdates = append(f.flowUpdates, payload)
+	return nil
+}
+
+func (f *fakeSkillClient) ListFlowEvents(_ context.Context, flowID string) ([]platform.FlowEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]platform.FlowEvent(nil), f.flowEvents[flowID]...), nil
+}
+
+func (f *fakeSkillClient) CreateFlowEvent(_ context.Context, flowID string, payload platform.CreateFlowEventRequest) (platform.CreateFlowEventResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextFlowRef++
+	id := fmt.Sprintf("event-%d", f.nextFlowRef)
+	f.eventCalls = append(f.eventCalls, "create "+payload.IDN)
+	f.flowEvents[flowID] = append(f.flowEvents[flowID], platform.FlowEvent{ID: id, IDN: payload.IDN, SkillIDN: payload.SkillIDN})
+	return platform.CreateFlowEventResponse{ID: id}, nil
+}
+
+func (f *fakeSkillClient) UpdateFlowEvent(_ context.Context, eventID string, payload platform.UpdateFlowEventRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.eventCalls = append(f.eventCalls, "update "+payload.IDN+" "+eventID)
+	return nil
+}
+
+func (f *fakeSkillClient) DeleteFlowEvent(_ context.Context, eventID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.eventCalls = append(f.eventCalls, "delete "+eventID)
+	return nil
+}
+
+func (f *fakeSkillClient) ListFlowStates(_ context.Context, flowID string) ([]platform.FlowState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]platform.FlowState(nil), f.flowStates[flowID]...), nil
+}
+
+func (f *fakeSkillClient) CreateFlowState(_ context.Context, flowID string, payload platform.CreateFlowStateRequest) (platform.CreateFlowStateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextFlowRef++
+	id := fmt.Sprintf("state-%d", f.nextFlowRef)
+	f.stateCalls = append(f.stateCalls, "create "+payload.IDN)
+	f.flowStates[flowID] = append(f.flowStates[flowID], platform.FlowState{ID: id, IDN: payload.IDN, Title: payload.Title})
+	return platform.CreateFlowStateResponse{ID: id}, nil
+}
+
+func (f *fakeSkillClient) UpdateFlowState(_ context.Context, stateID string, payload platform.UpdateFlowStateRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stateCalls = append(f.stateCalls, "update "+payload.IDN+" "+stateID)
+	return nil
+}
+
+func (f *fakeSkillClient) DeleteFlowState(_ context.Context, stateID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stateCalls = append(f.stateCalls, "delete "+stateID)
+	return nil
+}
+
+func TestSkillSyncService_SlugPrefixLocatesUnmappedProjectDirs(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	client := newFakeSkillClient()
+	client.addFlowSkill("flow-id", platform.Skill{ID: "skill-id", IDN: "skill", PromptScript: "same", RunnerType: "nsl"})
+	path := fsutil.ExportSkillScriptPath(outputRoot, "integration", "customer", "acme-project", "agent", "flow", "skill.nsl")
+	if err := fsutil.EnsureParentDir(path); err != nil {
+		t.Fatalf("ensure dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("same"), fsutil.FilePerm); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"Project": {ProjectID: "proj-uuid", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: map[string]state.SkillMetadataInfo{
+				"skill": {ID: "skill-id", IDN: "skill", RunnerType: "nsl"},
+			}}}},
+		}},
+	}}
+
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), SkillSyncRequest{
+		SessionIDN:      "customer",
+		CustomerType:    "integration",
+		OutputRoot:      outputRoot,
+		ProjectMap:      &projectMap,
+		Hashes:          state.HashStore{filepath.ToSlash(path): util.SHA256String("older")},
+		Force:           true,
+		SlugPrefix:      "acme-",
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(string, state.HashStore) error { return nil },
+		SavePushJournal: func(string, state.PushRecord) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if result.AlreadyRemote != 1 {
+		t.Fatalf("expected the script under the prefixed slug to be checked, got %+v", result)
+	}
+	if result.Changed() {
+		t.Fatalf("expected nothing to change remotely, got %+v", result)
+	}
+}
+
+// gatedSkillClient holds every skill update until want of them are in flight at once.
+type gatedSkillClient struct {
+	*fakeSkillClient
+	want     int
+	inFlight chan struct{}
+	release  chan struct{}
+	once     sync.Once
+}
+
+func (g *gatedSkillClient) UpdateSkill(ctx context.Context, skillID string, payload platform.UpdateSkillRequest) error {
+	g.inFlight <- struct{}{}
+	if len(g.inFlight) == g.want {
+		g.once.Do(func() { close(g.release) })
+	}
+	select {
+	case <-g.release:
+	case <-time.After(5 * time.Second):
+		return errors.New("skill updates were not sent concurrently")
+	}
+	return g.fakeSkillClient.UpdateSkill(ctx, skillID, payload)
+}
+
+func TestSkillSyncService_UploadsSkillsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := t.TempDir()
+	idns := []string{"a", "b", "c"}
+	client := &gatedSkillClient{fakeSkillClient: newFakeSkillClient(), want: len(idns), inFlight: make(chan struct{}, len(idns)), release: make(chan struct{})}
+	skills := map[string]state.SkillMetadataInfo{}
+	hashes := state.HashStore{}
+	paths := map[string]string{}
+	for _, idn := range idns {
+		remote := platform.Skill{ID: idn + "-id", IDN: idn, PromptScript: "old " + idn, RunnerType: "nsl"}
+		client.addFlowSkill("flow-id", remote)
+		skills[idn] = state.SkillMetadataInfo{ID: remote.ID, IDN: idn, RunnerType: "nsl"}
+		path := fsutil.ExportSkillScriptPath(outputRoot, "integration", "customer", "project", "agent", "flow", idn+".nsl")
+		if err := fsutil.EnsureParentDir(path); err != nil {
+			t.Fatalf("ensure dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("new "+idn), fsutil.FilePerm); err != nil {
+			t.Fatalf("write script: %v", err)
+		}
+		hashes[filepath.ToSlash(path)] = util.SHA256String(remote.PromptScript)
+		paths[idn] = filepath.ToSlash(path)
+	}
+	projectMap := state.ProjectMap{Projects: map[string]state.ProjectData{
+		"project": {ProjectID: "proj-uuid", Path: "project", Agents: map[string]state.AgentData{
+			"agent": {ID: "agent-id", Flows: map[string]state.FlowData{"flow": {ID: "flow-id", Skills: skills}}},
+		}},
+	}}
+
+	var (
+		savedHashes  state.HashStore
+		savedJournal state.PushRecord
+		prompts      int
+	)
+	result, err := NewSkillSyncService(client, nil).SyncCustomer(context.Background(), SkillSyncRequest{
+		SessionIDN:   "customer",
+		CustomerType: "integration",
+		OutputRoot:   outputRoot,
+		ProjectMap:   &projectMap,
+		Hashes:       hashes,
+		ConfirmPush: func(ConfirmPushRequest) (Decision, error) {
+			prompts++
+			return Decision{Apply: true}, nil
+		},
+		SaveProjectMap:  func(string, state.ProjectMap) error { return nil },
+		SaveHashes:      func(_ string, h state.HashStore) error { savedHashes = cloneHashes(h); return nil },
+		SavePushJournal: func(_ string, record state.PushRecord) error { savedJournal = record; return nil },
+	})
+	if err != nil {
+		t.Fatalf("SyncCustomer: %v", err)
+	}
+	if result.Updated != len(idns) || prompts != len(idns) {
+		t.Fatalf("expected %d confirmed updates, got %d updated after %d prompts", len(idns), result.Updated, prompts)
+	}
+	if len(savedJournal.Entries) != len(idns) {
+		t.Fatalf("expected every upload in the journal, got %+v", savedJournal.Entries)
+	}
+	for idn, path := range paths {
+		if savedHashes[path] != util.SHA256String("new "+idn) {
+			t.Fatalf("expected %s to record the pushed content, got %v", path, savedHashes)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package sync
+
+import (
+	"path/filepath"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+	"github.com/twinmind/newo-tool/internal/state"
+)
+
+// tagScoped reports whether the push is limited to tagged skills. Such a push only updates and creates
+// skills; deletions, renames, agents, new flows, and flow definitions are left to an unscoped push.
+func (st *skillSyncState) tagScoped() bool {
+	return len(st.req.Tags) > 0
+}
+
+// skillTagged reports whether the .meta.yaml of a skill in flowDir carries one of the requested tags.
+// A skill whose metadata is missing or unreadable carries none.
+func (st *skillSyncState) skillTagged(flowDir, skillIDN string) bool {
+	tags, err := state.LoadSkillTags(filepath.Join(flowDir, skillIDN+fsutil.SkillMetaFileExt))
+	return err == nil && state.HasAnyTag(tags, st.req.Tags)
+}
@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/state"
+	"github.com/twinmind/newo-tool/internal/util"
+)
+
+// UndoRequest configures reverting a recorded push.
+type UndoRequest struct {
+	Record        state.PushRecord
+	Hashes        state.HashStore
+	ShouldPublish bool
+	Verbose       bool
+	// Force reverts skills even if they changed remotely after the recorded push.
+	Force    bool
+	Reporter Reporter
+}
+
+// UndoResult summarises a push revert.
+type UndoResult struct {
+	Reverted  int
+	Skipped   int
+	Published int
+	Hashes    state.HashStore
+}
+
+// UndoPush re-uploads the scripts replaced by a recorded push. Hashes for reverted skills are reset to the
+// restored remote version so the local edits show up as pending changes again.
+func (s *SkillSyncService) UndoPush(ctx context.Context, req UndoRequest) (UndoResult, error) {
+	reporter := req.Reporter
+	if reporter == nil {
+		reporter = noopReporter{}
+	}
+
+	st := skillSyncState{
+		req:            SkillSyncRequest{ShouldPublish: req.ShouldPublish, Verbose: req.Verbose},
+		reporter:       reporter,
+		flowsToPublish: map[string]publishTarget{},
+	}
+	result := UndoResult{Hashes: cloneHashes(req.Hashes)}
+
+	for _, entry := range req.Record.Entries {
+		remote, err := s.client.GetSkill(ctx, strings.TrimSpace(entry.SkillID))
+		if err != nil {
+			var apiErr *platform.APIError
+			if errors.As(err, &apiErr) && apiErr.Status == 404 {
+				reporter.Warnf("Skipping %s: remote skill no longer exists", entry.Path)
+				result.Skipped++
+				continue
+			}
+			return result, fmt.Errorf("get skill %s: %w", entry.Path, err)
+		}
+
+		if !req.Force && util.ContentHashString(remote.PromptScript) != entry.PushedHash {
+			reporter.Warnf("Skipping %s: remote changed after the recorded push; use --force to revert anyway", entry.Path)
+			result.Skipped++
+			continue
+		}
+
+		if req.Verbose {
+			reporter.Infof("Reverting skill %s/%s/%s", entry.ProjectIDN, entry.FlowIDN, entry.SkillIDN)
+		}
+		if err := s.client.UpdateSkill(ctx, remote.ID, platform.UpdateSkillRequest{
+			ID:           remote.ID,
+			IDN:          remote.IDN,
+			Title:        remote.Title,
+			PromptScript: entry.PreviousScript,
+			RunnerType:   remote.RunnerType,
+			Model:        remote.Model,
+			Parameters:   remote.Parameters,
+			Path:         remote.Path,
+		}); err != nil {
+			return result, fmt.Errorf("revert skill %s: %w", entry.Path, err)
+		}
+
+		result.Hashes[entry.Path] = entry.PreviousHash
+		result.Reverted++
+		if req.ShouldPublish && strings.TrimSpace(entry.FlowID) != "" {
+			st.flowsToPublish[entry.FlowID] = publishTarget{projectIDN: entry.ProjectIDN, agentIDN: entry.AgentIDN, flowIDN: entry.FlowIDN, metadataPath: flowMetadataBeside(entry.Path)}
+		}
+	}
+
+	published, err := s.publishFlows(ctx, &st)
+	result.Published = published
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
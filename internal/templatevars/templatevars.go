@@ -0,0 +1,90 @@
+// Package templatevars expands environment placeholders such as {{NEWO_ENV}} in skill scripts
+// copied between customers. Values come from the target customer's vars table in newo.toml.
+package templatevars
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Built-in variables available for every target customer. A customer's vars table may override them.
+const (
+	CustomerIDN  = "NEWO_CUSTOMER_IDN"
+	CustomerType = "NEWO_CUSTOMER_TYPE"
+)
+
+// placeholder matches an upper-case {{NAME}} with no inner whitespace, so regular NSL output tags
+// such as {{ user_name }} or {{user}} are never mistaken for substitution points.
+var placeholder = regexp.MustCompile(`\{\{([A-Z_][A-Z0-9_]*)\}\}`)
+
+// ForCustomer returns the variables to apply for a target customer: the built-ins plus its configured vars.
+func ForCustomer(customerIDN, customerType string, vars map[string]string) map[string]string {
+	result := map[string]string{
+		CustomerIDN:  strings.TrimSpace(customerIDN),
+		CustomerType: strings.TrimSpace(customerType),
+	}
+	for name, value := range vars {
+		result[name] = value
+	}
+	return result
+}
+
+// Expand replaces every {{NAME}} placeholder whose name is defined in vars.
+// Unknown names are left untouched so scripts keep working when a value is not configured.
+func Expand(content []byte, vars map[string]string) []byte {
+	if len(vars) == 0 || len(content) == 0 {
+		return content
+	}
+	return placeholder.ReplaceAllFunc(content, func(match []byte) []byte {
+		name := string(match[2 : len(match)-2])
+		if value, ok := vars[name]; ok {
+			return []byte(value)
+		}
+		return match
+	})
+}
+
+// Collapse is the inverse of Expand: it turns the values in vars back into {{NAME}} placeholders, so a
+// script copied back from a customer does not hard-code that customer's settings. Longer values are
+// replaced first. Empty values and the customer type, a word too common to be a reliable marker, are kept.
+func Collapse(content []byte, vars map[string]string) []byte {
+	names := make([]string, 0, len(vars))
+	for name, value := range vars {
+		if name != CustomerType && value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(vars[names[i]]) != len(vars[names[j]]) {
+			return len(vars[names[i]]) > len(vars[names[j]])
+		}
+		return names[i] < names[j]
+	})
+	pairs := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		pairs = append(pairs, vars[name], "{{"+name+"}}")
+	}
+	if len(pairs) == 0 || len(content) == 0 {
+		return content
+	}
+	var buf bytes.Buffer
+	_, _ = strings.NewReplacer(pairs...).WriteString(&buf, string(content))
+	return buf.Bytes()
+}
+
+// Unresolved lists the placeholder names in content that have no value in vars, in order of first use.
+func Unresolved(content []byte, vars map[string]string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, match := range placeholder.FindAllSubmatch(content, -1) {
+		name := string(match[1])
+		if _, ok := vars[name]; ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,43 @@
+package templatevars
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	vars := ForCustomer("acme", "integration", map[string]string{
+		"NEWO_ENV": "prod",
+		"API_HOST": "api.acme.test",
+	})
+	input := []byte("{% set url = \"https://{{API_HOST}}/{{NEWO_ENV}}\" %}\n{{ user_name }} {{user}} {{MISSING}} {{NEWO_CUSTOMER_IDN}}\n")
+	want := "{% set url = \"https://api.acme.test/prod\" %}\n{{ user_name }} {{user}} {{MISSING}} acme\n"
+	if got := string(Expand(input, vars)); got != want {
+		t.Fatalf("unexpected expansion:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Unresolved(input, vars); !reflect.DeepEqual(got, []string{"MISSING"}) {
+		t.Fatalf("expected MISSING to be unresolved, got %v", got)
+	}
+}
+
+func TestForCustomerOverridesBuiltins(t *testing.T) {
+	vars := ForCustomer("acme", "integration", map[string]string{CustomerIDN: "override"})
+	if vars[CustomerIDN] != "override" {
+		t.Fatalf("expected configured value to win, got %q", vars[CustomerIDN])
+	}
+	if vars[CustomerType] != "integration" {
+		t.Fatalf("expected customer type built-in, got %q", vars[CustomerType])
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	vars := ForCustomer("acme", "integration", map[string]string{
+		"API_HOST": "api.acme.test",
+		"API_URL":  "https://api.acme.test/v1",
+	})
+	input := []byte("{{API_URL}} {{API_HOST}} {{NEWO_CUSTOMER_IDN}} integration")
+	want := string(input)
+	if got := string(Collapse(Expand(input, vars), vars)); got != want {
+		t.Fatalf("unexpected collapse:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,833 @@
+package httpmock
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fixture is the state of a mock NEWO platform: one customer with its projects and attributes. It is
+// the format of the files `newo mock-server --data` loads and of the GET /mock/state response.
+type Fixture struct {
+	Customer   Customer     `json:"customer"`
+	Projects   []*Project   `json:"projects"`
+	Attributes []*Attribute `json:"attributes,omitempty"`
+}
+
+// Customer identifies the customer every API key resolves to.
+type Customer struct {
+	ID  string `json:"id"`
+	IDN string `json:"idn"`
+}
+
+// Project is a mock project and everything in it.
+type Project struct {
+	ID          string   `json:"id"`
+	IDN         string   `json:"idn"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+	Agents      []*Agent `json:"agents"`
+}
+
+// Agent is a mock agent.
+type Agent struct {
+	ID          string  `json:"id"`
+	IDN         string  `json:"idn"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Flows       []*Flow `json:"flows"`
+}
+
+// Flow is a mock flow with its skills, events, and state fields.
+type Flow struct {
+	ID                string   `json:"id"`
+	IDN               string   `json:"idn"`
+	Title             string   `json:"title"`
+	Description       string   `json:"description,omitempty"`
+	DefaultRunnerType string   `json:"default_runner_type"`
+	DefaultModel      Model    `json:"default_model"`
+	Skills            []*Skill `json:"skills"`
+	Events            []*Event `json:"events,omitempty"`
+	States            []*State `json:"states,omitempty"`
+	// Published counts publish requests, so tests can assert that a push published the flow.
+	Published int `json:"published,omitempty"`
+}
+
+// Model names a model and its provider.
+type Model struct {
+	ModelIDN    string `json:"model_idn"`
+	ProviderIDN string `json:"provider_idn"`
+}
+
+// Skill is a mock skill. Every update keeps the previous script as a version.
+type Skill struct {
+	ID           string      `json:"id"`
+	IDN          string      `json:"idn"`
+	Title        string      `json:"title"`
+	PromptScript string      `json:"prompt_script"`
+	RunnerType   string      `json:"runner_type"`
+	Model        Model       `json:"model"`
+	Parameters   []Parameter `json:"parameters"`
+	Path         string      `json:"path,omitempty"`
+	UpdatedAt    string      `json:"updated_at,omitempty"`
+	Versions     []Version   `json:"versions,omitempty"`
+}
+
+// Parameter is a named skill parameter.
+type Parameter struct {
+	Name         string `json:"name"`
+	DefaultValue string `json:"default_value"`
+}
+
+// Version is a saved revision of a skill script.
+type Version struct {
+	ID           string `json:"id"`
+	Version      int    `json:"version"`
+	PromptScript string `json:"prompt_script"`
+	RunnerType   string `json:"runner_type"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// Event is a mock flow event.
+type Event struct {
+	ID             string `json:"id"`
+	IDN            string `json:"idn"`
+	Description    string `json:"description"`
+	SkillSelector  string `json:"skill_selector"`
+	SkillIDN       string `json:"skill_idn"`
+	StateIDN       string `json:"state_idn"`
+	IntegrationIDN string `json:"integration_idn"`
+	ConnectorIDN   string `json:"connector_idn"`
+	InterruptMode  string `json:"interrupt_mode"`
+}
+
+// State is a mock flow state field.
+type State struct {
+	ID           string `json:"id"`
+	IDN          string `json:"idn"`
+	Title        string `json:"title"`
+	DefaultValue string `json:"default_value"`
+	Scope        string `json:"scope"`
+}
+
+// Attribute is a mock customer attribute.
+type Attribute struct {
+	ID             string   `json:"id"`
+	IDN            string   `json:"idn"`
+	Value          any      `json:"value"`
+	Title          string   `json:"title"`
+	Description    string   `json:"description"`
+	Group          string   `json:"group"`
+	IsHidden       bool     `json:"is_hidden"`
+	PossibleValues []string `json:"possible_values"`
+	ValueType      string   `json:"value_type"`
+}
+
+// LoadFixture reads a fixture from a JSON file.
+func LoadFixture(path string) (Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return Fixture{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Server is an in-memory fake of the NEWO platform API, covering the endpoints the platform client
+// calls. It can back a test through New, or listen on a real address for end-to-end runs in CI.
+type Server struct {
+	mu     sync.Mutex
+	data   Fixture
+	apiKey string
+	nextID int
+	tokens int
+}
+
+// NewServer returns a server holding fixture. When apiKey is not empty, token requests must present it.
+func NewServer(fixture Fixture, apiKey string) *Server {
+	s := &Server{data: cloneFixture(fixture), apiKey: apiKey}
+	s.normalize()
+	return s
+}
+
+// Snapshot returns a copy of the current state.
+func (s *Server) Snapshot() Fixture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cloneFixture(s.data)
+}
+
+func cloneFixture(fixture Fixture) Fixture {
+	data, _ := json.Marshal(fixture)
+	var clone Fixture
+	_ = json.Unmarshal(data, &clone)
+	return clone
+}
+
+// normalize fills in the identifiers and defaults a hand-written fixture leaves out.
+func (s *Server) normalize() {
+	if s.data.Customer.IDN == "" {
+		s.data.Customer.IDN = "mock-customer"
+	}
+	if s.data.Customer.ID == "" {
+		s.data.Customer.ID = s.newID("customer")
+	}
+	now := s.now()
+	for _, project := range s.data.Projects {
+		setDefault(&project.ID, s.newID("project"))
+		setDefault(&project.Title, project.IDN)
+		setDefault(&project.CreatedAt, now)
+		setDefault(&project.UpdatedAt, now)
+		for _, agent := range project.Agents {
+			setDefault(&agent.ID, s.newID("agent"))
+			setDefault(&agent.Title, agent.IDN)
+			for _, flow := range agent.Flows {
+				setDefault(&flow.ID, s.newID("flow"))
+				setDefault(&flow.Title, flow.IDN)
+				setDefault(&flow.DefaultRunnerType, "guidance")
+				for _, skill := range flow.Skills {
+					setDefault(&skill.ID, s.newID("skill"))
+					setDefault(&skill.Title, skill.IDN)
+					setDefault(&skill.RunnerType, "guidance")
+					setDefault(&skill.UpdatedAt, now)
+				}
+				for _, event := range flow.Events {
+					setDefault(&event.ID, s.newID("event"))
+				}
+				for _, state := range flow.States {
+					setDefault(&state.ID, s.newID("state"))
+				}
+			}
+		}
+	}
+	for _, attribute := range s.data.Attributes {
+		setDefault(&attribute.ID, s.newID("attribute"))
+	}
+}
+
+func setDefault(field *string, value string) {
+	if strings.TrimSpace(*field) == "" {
+		*field = value
+	}
+}
+
+func (s *Server) newID(kind string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", kind, s.nextID)
+}
+
+func (s *Server) now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// apiVersionPrefix matches the version segment of API paths, which differs between endpoints.
+var apiVersionPrefix = regexp.MustCompile(`^/api/v[0-9]+/`)
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/mock/state" && r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.data)
+		return
+	}
+	if !apiVersionPrefix.MatchString(r.URL.Path) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	route := strings.Split(apiVersionPrefix.ReplaceAllString(r.URL.Path, ""), "/")
+
+	if r.Method == http.MethodPost && matchRoute(route, "auth", "api-key", "token") != nil {
+		if s.apiKey != "" && r.Header.Get("x-api-key") != s.apiKey {
+			writeError(w, http.StatusUnauthorized, "invalid api key")
+			return
+		}
+		s.tokens++
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":  fmt.Sprintf("mock-access-%d", s.tokens),
+			"refresh_token": fmt.Sprintf("mock-refresh-%d", s.tokens),
+			"expires_in":    3600,
+		})
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	status, body := s.route(r, route)
+	if status >= 400 {
+		writeError(w, status, fmt.Sprint(body))
+		return
+	}
+	writeJSON(w, status, body)
+}
+
+// matchRoute reports the {} segments of pattern captured from route, or nil when it does not match.
+func matchRoute(route []string, pattern ...string) []string {
+	if len(route) != len(pattern) {
+		return nil
+	}
+	captured := []string{}
+	for i, segment := range pattern {
+		switch {
+		case segment == "{}":
+			captured = append(captured, route[i])
+		case segment != route[i]:
+			return nil
+		}
+	}
+	return captured
+}
+
+func (s *Server) route(r *http.Request, route []string) (int, any) {
+	get, post, put, del := r.Method == http.MethodGet, r.Method == http.MethodPost, r.Method == http.MethodPut, r.Method == http.MethodDelete
+	switch {
+	case get && matchRoute(route, "customer", "profile") != nil:
+		return http.StatusOK, s.data.Customer
+	case get && matchRoute(route, "bff", "customer", "attributes") != nil:
+		attributes := []*Attribute{}
+		for _, attribute := range s.data.Attributes {
+			if !attribute.IsHidden || r.URL.Query().Get("include_hidden") == "true" {
+				attributes = append(attributes, attribute)
+			}
+		}
+		return http.StatusOK, map[string]any{"attributes": attributes}
+	case post && matchRoute(route, "customer", "attributes") != nil:
+		var req Attribute
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		for _, attribute := range s.data.Attributes {
+			if attribute.IDN == req.IDN {
+				return http.StatusConflict, fmt.Sprintf("attribute %s already exists", req.IDN)
+			}
+		}
+		req.ID = s.newID("attribute")
+		s.data.Attributes = append(s.data.Attributes, &req)
+		return http.StatusOK, map[string]string{"id": req.ID}
+	case put && matchRoute(route, "customer", "attributes", "{}") != nil:
+		for _, attribute := range s.data.Attributes {
+			if attribute.ID != route[2] {
+				continue
+			}
+			var req Attribute
+			if err := decodeBody(r, &req); err != nil {
+				return http.StatusBadRequest, err
+			}
+			req.ID = attribute.ID
+			*attribute = req
+			return http.StatusOK, map[string]any{}
+		}
+		return http.StatusNotFound, "attribute not found"
+	case get && matchRoute(route, "bff", "conversations", "acts") != nil:
+		return http.StatusOK, map[string]any{"items": []any{}}
+
+	case get && matchRoute(route, "designer", "projects") != nil:
+		projects := make([]projectView, 0, len(s.data.Projects))
+		for _, project := range s.data.Projects {
+			projects = append(projects, viewProject(project))
+		}
+		return http.StatusOK, projects
+	case post && matchRoute(route, "designer", "projects") != nil:
+		var req struct {
+			IDN         string `json:"idn"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		for _, project := range s.data.Projects {
+			if project.IDN == req.IDN {
+				return http.StatusConflict, fmt.Sprintf("project %s already exists", req.IDN)
+			}
+		}
+		project := &Project{ID: s.newID("project"), IDN: req.IDN, Title: req.Title, Description: req.Description, CreatedAt: s.now(), UpdatedAt: s.now(), Agents: []*Agent{}}
+		s.data.Projects = append(s.data.Projects, project)
+		return http.StatusOK, map[string]string{"id": project.ID}
+	case get && matchRoute(route, "designer", "projects", "by-id", "{}") != nil:
+		project := s.project(route[3])
+		if project == nil {
+			return http.StatusNotFound, "project not found"
+		}
+		return http.StatusOK, viewProject(project)
+	case del && matchRoute(route, "designer", "projects", "{}") != nil:
+		for i, project := range s.data.Projects {
+			if project.ID == route[2] {
+				s.data.Projects = append(s.data.Projects[:i], s.data.Projects[i+1:]...)
+				return http.StatusOK, map[string]any{}
+			}
+		}
+		return http.StatusNotFound, "project not found"
+
+	case get && matchRoute(route, "bff", "agents", "list") != nil:
+		project := s.project(r.URL.Query().Get("project_id"))
+		if project == nil {
+			return http.StatusNotFound, "project not found"
+		}
+		agents := make([]agentView, 0, len(project.Agents))
+		for _, agent := range project.Agents {
+			agents = append(agents, viewAgent(agent))
+		}
+		return http.StatusOK, agents
+	case post && matchRoute(route, "designer", "{}", "agents") != nil:
+		project := s.project(route[1])
+		if project == nil {
+			return http.StatusNotFound, "project not found"
+		}
+		var req struct {
+			IDN         string `json:"idn"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		agent := &Agent{ID: s.newID("agent"), IDN: req.IDN, Title: req.Title, Description: req.Description, Flows: []*Flow{}}
+		project.Agents = append(project.Agents, agent)
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]string{"id": agent.ID}
+	case del && matchRoute(route, "designer", "agents", "{}") != nil:
+		for _, project := range s.data.Projects {
+			for i, agent := range project.Agents {
+				if agent.ID == route[2] {
+					project.Agents = append(project.Agents[:i], project.Agents[i+1:]...)
+					project.UpdatedAt = s.now()
+					return http.StatusOK, map[string]any{}
+				}
+			}
+		}
+		return http.StatusNotFound, "agent not found"
+
+	case post && matchRoute(route, "designer", "{}", "flows", "empty") != nil:
+		project, agent := s.agent(route[1])
+		if agent == nil {
+			return http.StatusNotFound, "agent not found"
+		}
+		var req struct {
+			IDN         string `json:"idn"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		flow := &Flow{ID: s.newID("flow"), IDN: req.IDN, Title: req.Title, Description: req.Description, DefaultRunnerType: "guidance", Skills: []*Skill{}}
+		agent.Flows = append(agent.Flows, flow)
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]string{"id": flow.ID}
+	case put && matchRoute(route, "designer", "flows", "{}") != nil:
+		project, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		var req struct {
+			IDN               string `json:"idn"`
+			Title             string `json:"title"`
+			Description       string `json:"description"`
+			DefaultRunnerType string `json:"default_runner_type"`
+			DefaultModel      Model  `json:"default_model"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		flow.IDN, flow.Title, flow.Description = req.IDN, req.Title, req.Description
+		flow.DefaultRunnerType, flow.DefaultModel = req.DefaultRunnerType, req.DefaultModel
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]any{}
+	case del && matchRoute(route, "designer", "flows", "{}") != nil:
+		for _, project := range s.data.Projects {
+			for _, agent := range project.Agents {
+				for i, flow := range agent.Flows {
+					if flow.ID == route[2] {
+						agent.Flows = append(agent.Flows[:i], agent.Flows[i+1:]...)
+						project.UpdatedAt = s.now()
+						return http.StatusOK, map[string]any{}
+					}
+				}
+			}
+		}
+		return http.StatusNotFound, "flow not found"
+	case post && matchRoute(route, "designer", "flows", "{}", "publish") != nil:
+		_, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		flow.Published++
+		return http.StatusOK, map[string]any{}
+
+	case get && matchRoute(route, "designer", "flows", "{}", "skills") != nil:
+		_, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		skills := make([]skillView, 0, len(flow.Skills))
+		for _, skill := range flow.Skills {
+			skills = append(skills, viewSkill(skill))
+		}
+		return http.StatusOK, skills
+	case post && matchRoute(route, "designer", "flows", "{}", "skills") != nil:
+		project, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		var req skillView
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		for _, skill := range flow.Skills {
+			if skill.IDN == req.IDN {
+				return http.StatusConflict, fmt.Sprintf("skill %s already exists", req.IDN)
+			}
+		}
+		skill := &Skill{ID: s.newID("skill"), IDN: req.IDN, Title: req.Title, PromptScript: req.PromptScript, RunnerType: req.RunnerType,
+			Model: req.Model, Parameters: req.Parameters, Path: req.Path, UpdatedAt: s.now()}
+		flow.Skills = append(flow.Skills, skill)
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]string{"id": skill.ID}
+	case get && matchRoute(route, "designer", "skills", "{}") != nil:
+		_, _, skill := s.skill(route[2])
+		if skill == nil {
+			return http.StatusNotFound, "skill not found"
+		}
+		return http.StatusOK, viewSkill(skill)
+	case put && matchRoute(route, "designer", "flows", "skills", "{}") != nil:
+		project, _, skill := s.skill(route[3])
+		if skill == nil {
+			return http.StatusNotFound, "skill not found"
+		}
+		var req skillView
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		if req.PromptScript != skill.PromptScript || req.RunnerType != skill.RunnerType {
+			skill.Versions = append(skill.Versions, Version{
+				ID:           s.newID("version"),
+				Version:      len(skill.Versions) + 1,
+				PromptScript: skill.PromptScript,
+				RunnerType:   skill.RunnerType,
+				CreatedAt:    skill.UpdatedAt,
+			})
+		}
+		skill.IDN, skill.Title, skill.PromptScript, skill.RunnerType = req.IDN, req.Title, req.PromptScript, req.RunnerType
+		skill.Model, skill.Parameters, skill.Path = req.Model, req.Parameters, req.Path
+		skill.UpdatedAt = s.now()
+		project.UpdatedAt = skill.UpdatedAt
+		return http.StatusOK, map[string]any{}
+	case del && matchRoute(route, "designer", "flows", "skills", "{}") != nil:
+		project, flow, skill := s.skill(route[3])
+		if skill == nil {
+			return http.StatusNotFound, "skill not found"
+		}
+		for i, candidate := range flow.Skills {
+			if candidate == skill {
+				flow.Skills = append(flow.Skills[:i], flow.Skills[i+1:]...)
+				break
+			}
+		}
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]any{}
+	case post && matchRoute(route, "designer", "flows", "skills", "{}", "parameters") != nil:
+		project, _, skill := s.skill(route[3])
+		if skill == nil {
+			return http.StatusNotFound, "skill not found"
+		}
+		var req Parameter
+		if err := decodeBody(r, &req); err != nil {
+			return http.StatusBadRequest, err
+		}
+		skill.Parameters = append(skill.Parameters, req)
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]string{"id": s.newID("parameter")}
+	case get && matchRoute(route, "designer", "flows", "skills", "{}", "versions") != nil:
+		_, _, skill := s.skill(route[3])
+		if skill == nil {
+			return http.StatusNotFound, "skill not found"
+		}
+		versions := make([]Version, 0, len(skill.Versions))
+		for i := len(skill.Versions) - 1; i >= 0; i-- {
+			versions = append(versions, skill.Versions[i])
+		}
+		return http.StatusOK, versions
+	case get && matchRoute(route, "designer", "flows", "skills", "{}", "versions", "{}") != nil:
+		_, _, skill := s.skill(route[3])
+		if skill == nil {
+			return http.StatusNotFound, "skill not found"
+		}
+		for _, version := range skill.Versions {
+			if version.ID == route[5] {
+				return http.StatusOK, version
+			}
+		}
+		return http.StatusNotFound, "version not found"
+	case post && matchRoute(route, "designer", "flows", "skills", "{}", "execute") != nil:
+		return http.StatusNotImplemented, "skill execution is not supported by the mock server"
+
+	case get && matchRoute(route, "designer", "flows", "{}", "events") != nil:
+		_, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		return http.StatusOK, nonNil(flow.Events)
+	case post && matchRoute(route, "designer", "flows", "{}", "events") != nil:
+		project, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		event := &Event{}
+		if err := decodeBody(r, event); err != nil {
+			return http.StatusBadRequest, err
+		}
+		event.ID = s.newID("event")
+		flow.Events = append(flow.Events, event)
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]string{"id": event.ID}
+	case (put || del) && matchRoute(route, "designer", "flows", "events", "{}") != nil:
+		project, flow, i := s.event(route[3])
+		if flow == nil {
+			return http.StatusNotFound, "event not found"
+		}
+		if del {
+			flow.Events = append(flow.Events[:i], flow.Events[i+1:]...)
+		} else {
+			updated := &Event{}
+			if err := decodeBody(r, updated); err != nil {
+				return http.StatusBadRequest, err
+			}
+			updated.ID = flow.Events[i].ID
+			flow.Events[i] = updated
+		}
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]any{}
+
+	case get && matchRoute(route, "designer", "flows", "{}", "states") != nil:
+		_, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		return http.StatusOK, nonNil(flow.States)
+	case post && matchRoute(route, "designer", "flows", "{}", "states") != nil:
+		project, flow := s.flow(route[2])
+		if flow == nil {
+			return http.StatusNotFound, "flow not found"
+		}
+		state := &State{}
+		if err := decodeBody(r, state); err != nil {
+			return http.StatusBadRequest, err
+		}
+		state.ID = s.newID("state")
+		flow.States = append(flow.States, state)
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]string{"id": state.ID}
+	case (put || del) && matchRoute(route, "designer", "flows", "states", "{}") != nil:
+		project, flow, i := s.state(route[3])
+		if flow == nil {
+			return http.StatusNotFound, "state not found"
+		}
+		if del {
+			flow.States = append(flow.States[:i], flow.States[i+1:]...)
+		} else {
+			updated := &State{}
+			if err := decodeBody(r, updated); err != nil {
+				return http.StatusBadRequest, err
+			}
+			updated.ID = flow.States[i].ID
+			flow.States[i] = updated
+		}
+		project.UpdatedAt = s.now()
+		return http.StatusOK, map[string]any{}
+	}
+	return http.StatusNotFound, fmt.Sprintf("%s %s is not supported by the mock server", r.Method, r.URL.Path)
+}
+
+func (s *Server) project(id string) *Project {
+	for _, project := range s.data.Projects {
+		if project.ID == id {
+			return project
+		}
+	}
+	return nil
+}
+
+func (s *Server) agent(id string) (*Project, *Agent) {
+	for _, project := range s.data.Projects {
+		for _, agent := range project.Agents {
+			if agent.ID == id {
+				return project, agent
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) flow(id string) (*Project, *Flow) {
+	for _, project := range s.data.Projects {
+		for _, agent := range project.Agents {
+			for _, flow := range agent.Flows {
+				if flow.ID == id {
+					return project, flow
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// eachFlow calls fn for every flow until it returns true.
+func (s *Server) eachFlow(fn func(*Project, *Flow) bool) {
+	for _, project := range s.data.Projects {
+		for _, agent := range project.Agents {
+			for _, flow := range agent.Flows {
+				if fn(project, flow) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) skill(id string) (project *Project, flow *Flow, skill *Skill) {
+	s.eachFlow(func(p *Project, f *Flow) bool {
+		for _, candidate := range f.Skills {
+			if candidate.ID == id {
+				project, flow, skill = p, f, candidate
+				return true
+			}
+		}
+		return false
+	})
+	return project, flow, skill
+}
+
+func (s *Server) event(id string) (project *Project, flow *Flow, index int) {
+	s.eachFlow(func(p *Project, f *Flow) bool {
+		for i, candidate := range f.Events {
+			if candidate.ID == id {
+				project, flow, index = p, f, i
+				return true
+			}
+		}
+		return false
+	})
+	return project, flow, index
+}
+
+func (s *Server) state(id string) (project *Project, flow *Flow, index int) {
+	s.eachFlow(func(p *Project, f *Flow) bool {
+		for i, candidate := range f.States {
+			if candidate.ID == id {
+				project, flow, index = p, f, i
+				return true
+			}
+		}
+		return false
+	})
+	return project, flow, index
+}
+
+// projectView, agentView, flowView, and skillView are the shapes the API returns, without the nested
+// data the mock keeps for itself.
+type projectView struct {
+	ID          string `json:"id"`
+	IDN         string `json:"idn"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type agentView struct {
+	ID          string     `json:"id"`
+	IDN         string     `json:"idn"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Flows       []flowView `json:"flows"`
+}
+
+type flowView struct {
+	ID                string `json:"id"`
+	IDN               string `json:"idn"`
+	Title             string `json:"title"`
+	Description       string `json:"description"`
+	DefaultRunnerType string `json:"default_runner_type"`
+	DefaultModel      Model  `json:"default_model"`
+}
+
+type skillView struct {
+	ID           string      `json:"id"`
+	IDN          string      `json:"idn"`
+	Title        string      `json:"title"`
+	PromptScript string      `json:"prompt_script"`
+	RunnerType   string      `json:"runner_type"`
+	Model        Model       `json:"model"`
+	Parameters   []Parameter `json:"parameters"`
+	Path         string      `json:"path,omitempty"`
+	UpdatedAt    string      `json:"updated_at,omitempty"`
+}
+
+func viewProject(project *Project) projectView {
+	return projectView{ID: project.ID, IDN: project.IDN, Title: project.Title, Description: project.Description, CreatedAt: project.CreatedAt, UpdatedAt: project.UpdatedAt}
+}
+
+func viewAgent(agent *Agent) agentView {
+	flows := make([]flowView, 0, len(agent.Flows))
+	for _, flow := range agent.Flows {
+		flows = append(flows, flowView{ID: flow.ID, IDN: flow.IDN, Title: flow.Title, Description: flow.Description, DefaultRunnerType: flow.DefaultRunnerType, DefaultModel: flow.DefaultModel})
+	}
+	return agentView{ID: agent.ID, IDN: agent.IDN, Title: agent.Title, Description: agent.Description, Flows: flows}
+}
+
+func viewSkill(skill *Skill) skillView {
+	parameters := skill.Parameters
+	if parameters == nil {
+		parameters = []Parameter{}
+	}
+	return skillView{ID: skill.ID, IDN: skill.IDN, Title: skill.Title, PromptScript: skill.PromptScript, RunnerType: skill.RunnerType,
+		Model: skill.Model, Parameters: parameters, Path: skill.Path, UpdatedAt: skill.UpdatedAt}
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func decodeBody(r *http.Request, dest any) error {
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("decode request body: %v", err)
+		}
+		body = zr
+	}
+	if err := json.NewDecoder(body).Decode(dest); err != nil {
+		return fmt.Errorf("decode request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"detail": message})
+}
@@ -6,6 +6,10 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/logging"
+	"github.com/twinmind/newo-tool/internal/redact"
 )
 
 const (
@@ -26,6 +30,13 @@ type Writer struct {
 
 	mu    sync.Mutex
 	wrote bool
+
+	// live enables in-place progress bars; progress, drawn, and midLine track what is on screen.
+	live     bool
+	progress *Progress
+	drawn    int
+	midLine  bool
+	now      func() time.Time
 }
 
 // Option customises writer behaviour.
@@ -33,6 +44,7 @@ type Option func(*options)
 
 type options struct {
 	colorOverride *bool
+	liveOverride  *bool
 }
 
 // WithColors forces colour output (true enables, false disables) regardless of terminal detection.
@@ -42,6 +54,13 @@ func WithColors(enabled bool) Option {
 	}
 }
 
+// WithLiveProgress forces in-place progress bars on or off regardless of terminal detection.
+func WithLiveProgress(enabled bool) Option {
+	return func(o *options) {
+		o.liveOverride = ptr(enabled)
+	}
+}
+
 func ptr[T any](v T) *T {
 	return &v
 }
@@ -72,12 +91,18 @@ func New(out, err io.Writer, opts ...Option) *Writer {
 		enabled = detectTTY(out)
 	}
 
+	live := detectLive(out)
+	if cfg.liveOverride != nil {
+		live = *cfg.liveOverride
+	}
+
 	return &Writer{
 		out: out,
 		err: err,
 		theme: theme{
 			colorEnabled: enabled,
 		},
+		live: live,
 	}
 }
 
@@ -103,6 +128,7 @@ func (w *Writer) Section(title string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	w.clearProgress()
 	if w.wrote {
 		_, _ = fmt.Fprintln(w.out)
 	}
@@ -111,26 +137,28 @@ func (w *Writer) Section(title string) {
 	headline = w.theme.style(headline, ansiBold, ansiBlue)
 	_, _ = fmt.Fprintln(w.out, headline)
 	w.wrote = true
+	w.midLine = false
+	w.drawProgress()
 }
 
 // Info prints a neutral informational line.
 func (w *Writer) Info(format string, args ...any) {
-	w.printLine(w.out, "[i]", ansiBlue, nil, format, args...)
+	w.printLine(w.out, "[i]", ansiBlue, nil, logging.Info, format, args...)
 }
 
 // Success prints a success line.
 func (w *Writer) Success(format string, args ...any) {
-	w.printLine(w.out, "[+]", ansiGreen, []string{ansiBold}, format, args...)
+	w.printLine(w.out, "[+]", ansiGreen, []string{ansiBold}, logging.Info, format, args...)
 }
 
 // Warn prints a warning line to stderr.
 func (w *Writer) Warn(format string, args ...any) {
-	w.printLine(w.err, "[!]", ansiYellow, nil, format, args...)
+	w.printLine(w.err, "[!]", ansiYellow, nil, logging.Warn, format, args...)
 }
 
 // Error prints an error line to stderr.
 func (w *Writer) Error(format string, args ...any) {
-	w.printLine(w.err, "[x]", ansiRed, []string{ansiBold}, format, args...)
+	w.printLine(w.err, "[x]", ansiRed, []string{ansiBold}, logging.Error, format, args...)
 }
 
 // List prints a bulleted list to stdout.
@@ -140,19 +168,25 @@ func (w *Writer) List(items []string) {
 	if len(items) == 0 {
 		return
 	}
+	w.clearProgress()
 	bullet := w.theme.style("-", ansiGray)
 	for _, item := range items {
-		_, _ = fmt.Fprintf(w.out, "    %s %s\n", bullet, strings.TrimSpace(item))
+		_, _ = fmt.Fprintf(w.out, "    %s %s\n", bullet, redact.String(strings.TrimSpace(item)))
 	}
 	w.wrote = true
+	w.midLine = false
+	w.drawProgress()
 }
 
-// RawLine prints a line verbatim to stdout.
+// RawLine prints a line verbatim to stdout, apart from secret redaction.
 func (w *Writer) RawLine(format string, args ...any) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	_, _ = fmt.Fprintf(w.out, format+"\n", args...)
+	w.clearProgress()
+	_, _ = fmt.Fprintln(w.out, redact.String(fmt.Sprintf(format, args...)))
 	w.wrote = true
+	w.midLine = false
+	w.drawProgress()
 }
 
 // Write emits text to stdout without forcing a trailing newline.
@@ -162,8 +196,7 @@ func (w *Writer) Write(text string) {
 	}
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	_, _ = fmt.Fprint(w.out, text)
-	w.wrote = true
+	w.writePartial(w.out, redact.String(text))
 }
 
 // WriteErr emits text to stderr without forcing a newline.
@@ -173,16 +206,14 @@ func (w *Writer) WriteErr(text string) {
 	}
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	_, _ = fmt.Fprint(w.err, text)
-	w.wrote = true
+	w.writePartial(w.err, redact.String(text))
 }
 
 // Prompt writes a prompt without a newline, intended for interactive questions.
 func (w *Writer) Prompt(format string, args ...any) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	_, _ = fmt.Fprintf(w.out, format, args...)
-	w.wrote = true
+	w.writePartial(w.out, redact.String(fmt.Sprintf(format, args...)))
 }
 
 // ColorsEnabled reports whether the writer emits ANSI colour codes.
@@ -190,14 +221,34 @@ func (w *Writer) ColorsEnabled() bool {
 	return w.theme.colorEnabled
 }
 
-func (w *Writer) printLine(target io.Writer, icon, iconColor string, msgStyles []string, format string, args ...any) {
+// printLine writes a styled status line and mirrors the plain message to the diagnostic log.
+func (w *Writer) printLine(target io.Writer, icon, iconColor string, msgStyles []string, log func(string, ...any), format string, args ...any) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	msg := fmt.Sprintf(format, args...)
+	w.clearProgress()
+	w.writeLine(target, icon, iconColor, msgStyles, log, format, args...)
+	w.drawProgress()
+}
+
+// writeLine emits a styled status line. The caller holds w.mu.
+func (w *Writer) writeLine(target io.Writer, icon, iconColor string, msgStyles []string, log func(string, ...any), format string, args ...any) {
+	msg := redact.String(fmt.Sprintf(format, args...))
+	log(msg)
 	styledIcon := w.theme.style(icon, iconColor, ansiBold)
 	styledMsg := w.theme.style(msg, msgStyles...)
 	_, _ = fmt.Fprintf(target, "  %s %s\n", styledIcon, styledMsg)
 	w.wrote = true
+	w.midLine = false
+}
+
+// writePartial emits text that may end mid-line, such as a prompt. Progress bars stay hidden until the
+// next complete line, so they never split an unfinished line. The caller holds w.mu.
+func (w *Writer) writePartial(target io.Writer, text string) {
+	w.clearProgress()
+	_, _ = fmt.Fprint(target, text)
+	w.wrote = true
+	w.midLine = !strings.HasSuffix(text, "\n")
+	w.drawProgress()
 }
 
 type theme struct {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestSectionWithoutColour(t *testing.T) {
@@ -104,3 +105,66 @@ func TestWriteAndPrompt(t *testing.T) {
 		t.Fatalf("expected stderr write, got %q", errBuf.String())
 	}
 }
+
+func TestLiveProgressRedrawsBelowOutput(t *testing.T) {
+	var out bytes.Buffer
+	w := New(&out, &bytes.Buffer{}, WithColors(false), WithLiveProgress(true))
+
+	p := w.StartProgress("Pull acme", "skills")
+	p.Add("booking", 4)
+	p.Done("booking", 1)
+	w.Info("exported greet")
+	p.Done("booking", 1)
+
+	got := out.String()
+	for _, want := range []string{"Pull acme: 1/4 skills (25%)", "booking [######..................] 1/4", "\033[2A\033[J  [i] exported greet\n", "Pull acme: 2/4 skills (50%)"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%q", want, got)
+		}
+	}
+
+	out.Reset()
+	p.Finish()
+	if out.String() != "\033[2A\033[J" {
+		t.Fatalf("expected finish to erase the bars, got %q", out.String())
+	}
+	p.Done("booking", 1)
+	if out.String() != "\033[2A\033[J" {
+		t.Fatalf("expected no output after finish, got %q", out.String())
+	}
+}
+
+func TestProgressWithoutTerminalLogsPeriodically(t *testing.T) {
+	var out bytes.Buffer
+	w := New(&out, &bytes.Buffer{}, WithColors(false), WithLiveProgress(false))
+	now := time.Unix(0, 0)
+	w.now = func() time.Time { return now }
+
+	p := w.StartProgress("Push acme", "skills")
+	p.Add("booking", 10)
+	p.Done("booking", 3)
+	if out.Len() != 0 {
+		t.Fatalf("expected no output before the log interval, got %q", out.String())
+	}
+
+	now = now.Add(progressLogInterval)
+	p.Done("booking", 2)
+	if got := out.String(); got != "  [i] Push acme: 5/10 skills (50%)\n" {
+		t.Fatalf("unexpected progress line %q", got)
+	}
+}
+
+func TestScopeTagsLines(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	w := New(&out, &errBuf, WithColors(false))
+
+	w.Scope("shop", "", "bot").Info("Agent %s", "Bot")
+	w.Scope("shop").Warn("Events missing: %s", "100%")
+
+	if got := out.String(); got != "  [i] [shop/bot] Agent Bot\n" {
+		t.Fatalf("unexpected scoped line: %q", got)
+	}
+	if got := errBuf.String(); got != "  [!] [shop] Events missing: 100%\n" {
+		t.Fatalf("unexpected scoped warning: %q", got)
+	}
+}
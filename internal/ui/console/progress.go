@@ -0,0 +1,193 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/twinmind/newo-tool/internal/logging"
+)
+
+const (
+	progressBarWidth    = 24
+	progressMaxGroups   = 8
+	progressLogInterval = 5 * time.Second
+)
+
+// Progress tracks discovered and processed work items per group (typically per project).
+// On a terminal the bars are redrawn in place underneath the regular output; otherwise a plain
+// summary line is printed at most every few seconds, so logs stay readable. A nil Progress is a no-op.
+type Progress struct {
+	w       *Writer
+	title   string
+	unit    string
+	groups  []*progressGroup
+	lastLog time.Time
+}
+
+type progressGroup struct {
+	name  string
+	total int
+	done  int
+}
+
+// StartProgress begins progress reporting for a long operation, for example StartProgress("Pull acme", "skills").
+// Only one progress display is active per writer; starting a new one replaces the previous one.
+func (w *Writer) StartProgress(title, unit string) *Progress {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.clearProgress()
+	p := &Progress{w: w, title: title, unit: unit, lastLog: w.clock()}
+	w.progress = p
+	return p
+}
+
+// Add records n newly discovered items in group.
+func (p *Progress) Add(group string, n int) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.update(group, n, 0)
+}
+
+// Done records n processed items in group.
+func (p *Progress) Done(group string, n int) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.update(group, 0, n)
+}
+
+// Finish removes the bars from the terminal.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	w := p.w
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.progress != p {
+		return
+	}
+	w.clearProgress()
+	w.progress = nil
+}
+
+func (p *Progress) update(name string, added, done int) {
+	w := p.w
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	group := p.group(name)
+	group.total += added
+	group.done += done
+	if group.done > group.total {
+		group.total = group.done
+	}
+	if w.progress != p {
+		return
+	}
+	if w.live {
+		w.clearProgress()
+		w.drawProgress()
+		return
+	}
+	if now := w.clock(); now.Sub(p.lastLog) >= progressLogInterval {
+		p.lastLog = now
+		w.writeLine(w.out, "[i]", ansiBlue, nil, logging.Info, "%s", p.summary())
+	}
+}
+
+func (p *Progress) group(name string) *progressGroup {
+	for _, group := range p.groups {
+		if group.name == name {
+			return group
+		}
+	}
+	group := &progressGroup{name: name}
+	p.groups = append(p.groups, group)
+	return group
+}
+
+func (p *Progress) totals() (int, int) {
+	total, done := 0, 0
+	for _, group := range p.groups {
+		total += group.total
+		done += group.done
+	}
+	return total, done
+}
+
+func (p *Progress) summary() string {
+	total, done := p.totals()
+	return fmt.Sprintf("%s: %d/%d %s (%d%%)", p.title, done, total, p.unit, percent(done, total))
+}
+
+// lines renders the title line plus one bar per group.
+func (p *Progress) lines(t theme) []string {
+	lines := []string{"  " + t.style(p.summary(), ansiBold)}
+	width := 0
+	for _, group := range p.groups {
+		if len(group.name) > width {
+			width = len(group.name)
+		}
+	}
+	for idx, group := range p.groups {
+		if idx == progressMaxGroups {
+			lines = append(lines, t.style(fmt.Sprintf("    … and %d more", len(p.groups)-progressMaxGroups), ansiGray))
+			break
+		}
+		filled := 0
+		if group.total > 0 {
+			filled = group.done * progressBarWidth / group.total
+		}
+		bar := t.style(strings.Repeat("#", filled), ansiGreen) + t.style(strings.Repeat(".", progressBarWidth-filled), ansiGray)
+		lines = append(lines, fmt.Sprintf("    %-*s [%s] %d/%d", width, group.name, bar, group.done, group.total))
+	}
+	return lines
+}
+
+func percent(done, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return done * 100 / total
+}
+
+// clearProgress erases the bars drawn by drawProgress. The caller holds w.mu.
+func (w *Writer) clearProgress() {
+	if w.drawn == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w.out, "\033[%dA\033[J", w.drawn)
+	w.drawn = 0
+}
+
+// drawProgress prints the active bars below the cursor. The caller holds w.mu.
+func (w *Writer) drawProgress() {
+	if !w.live || w.progress == nil || w.midLine {
+		return
+	}
+	lines := w.progress.lines(w.theme)
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(w.out, line)
+	}
+	w.drawn = len(lines)
+}
+
+func (w *Writer) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
+
+// detectLive reports whether progress bars can be redrawn in place on w.
+func detectLive(out any) bool {
+	file, ok := out.(*os.File)
+	if !ok || !detectTTY(file) {
+		return false
+	}
+	return os.Getenv("TERM") != "dumb"
+}
@@ -0,0 +1,42 @@
+package console
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/twinmind/newo-tool/internal/logging"
+)
+
+// Scope writes the status lines of one unit of work that runs alongside others, such as a project or
+// flow of a parallel pull. Each line starts with the unit's path in brackets, so interleaved lines stay
+// attributable while they still stream as they are written.
+type Scope struct {
+	w   *Writer
+	tag string
+}
+
+// Scope returns a scope tagged with the non-empty parts joined by "/", for example [shop/bot/main].
+func (w *Writer) Scope(parts ...string) *Scope {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return &Scope{w: w, tag: "[" + strings.Join(kept, "/") + "]"}
+}
+
+// Info prints a neutral informational line.
+func (s *Scope) Info(format string, args ...any) {
+	s.w.printLine(s.w.out, "[i]", ansiBlue, nil, logging.Info, "%s %s", s.tag, fmt.Sprintf(format, args...))
+}
+
+// Success prints a success line.
+func (s *Scope) Success(format string, args ...any) {
+	s.w.printLine(s.w.out, "[+]", ansiGreen, []string{ansiBold}, logging.Info, "%s %s", s.tag, fmt.Sprintf(format, args...))
+}
+
+// Warn prints a warning line to stderr.
+func (s *Scope) Warn(format string, args ...any) {
+	s.w.printLine(s.w.err, "[!]", ansiYellow, nil, logging.Warn, "%s %s", s.tag, fmt.Sprintf(format, args...))
+}
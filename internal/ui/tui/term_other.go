@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package tui
+
+import "os"
+
+func makeRaw(*os.File) (func(), error) {
+	return nil, ErrNotTerminal
+}
+
+// DisableEcho is not supported on this platform.
+func DisableEcho(*os.File) (func(), error) {
+	return nil, ErrNotTerminal
+}
+
+func terminalSize(*os.File) (int, int) {
+	return 80, 24
+}
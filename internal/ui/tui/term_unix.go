@@ -0,0 +1,61 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// makeRaw switches the terminal to raw mode and returns a function that restores the previous mode.
+func makeRaw(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+	previous, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	raw := *previous
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, fmt.Errorf("enable raw terminal mode: %w", err)
+	}
+	return func() {
+		_ = unix.IoctlSetTermios(fd, ioctlSetTermios, previous)
+	}, nil
+}
+
+// DisableEcho stops the terminal from echoing typed input, for reading secrets, and returns a function
+// that restores the previous mode. It fails with ErrNotTerminal when f is not a terminal.
+func DisableEcho(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+	previous, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	silent := *previous
+	silent.Lflag &^= unix.ECHO
+	silent.Lflag |= unix.ICANON | unix.ISIG
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &silent); err != nil {
+		return nil, fmt.Errorf("disable terminal echo: %w", err)
+	}
+	return func() {
+		_ = unix.IoctlSetTermios(fd, ioctlSetTermios, previous)
+	}, nil
+}
+
+// terminalSize reports the terminal's columns and rows, falling back to 80×24.
+func terminalSize(f *os.File) (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
@@ -0,0 +1,378 @@
+// Package tui implements a minimal full-screen tree browser for the terminal.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiReverse = "\033[7m"
+	ansiYellow  = "\033[33m"
+	ansiRed     = "\033[31m"
+	ansiGray    = "\033[90m"
+	clearScreen = "\033[H\033[2J"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+)
+
+// ErrNotTerminal is returned when the UI is started without an interactive terminal.
+var ErrNotTerminal = errors.New("the interactive UI needs a terminal")
+
+// Node is a row of the tree. Children are shown when the node is expanded.
+type Node struct {
+	Label    string
+	Detail   string
+	Status   string
+	Drift    bool
+	Children []*Node
+	Expanded bool
+	Value    any
+}
+
+// Action binds a key to an operation on the selected node.
+type Action struct {
+	Key   rune
+	Label string
+}
+
+// Options configures a UI session.
+type Options struct {
+	Title   string
+	Actions []Action
+	// Load builds the tree; it runs at start-up and again after every action.
+	Load func() ([]*Node, error)
+	// Do runs an action with the terminal restored to normal mode, so commands can print and prompt.
+	Do func(key rune, node *Node) error
+}
+
+// Run shows the tree on the terminal behind in and out until the user quits.
+func Run(in *os.File, out io.Writer, opts Options) error {
+	if opts.Load == nil {
+		return errors.New("tui: Load is required")
+	}
+	roots, err := opts.Load()
+	if err != nil {
+		return err
+	}
+	view := &View{Roots: roots}
+
+	restore, err := makeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		fmt.Fprint(out, showCursor)
+		restore()
+	}()
+	fmt.Fprint(out, hideCursor)
+
+	buf := make([]byte, 16)
+	for {
+		width, height := terminalSize(in)
+		fmt.Fprint(out, clearScreen, view.Render(opts, width, height))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read terminal input: %w", err)
+		}
+		key := parseKey(buf[:n])
+		switch key {
+		case keyQuit:
+			return nil
+		case keyUp:
+			view.Move(-1)
+		case keyDown:
+			view.Move(1)
+		case keyPageUp:
+			view.Move(-(height - 4))
+		case keyPageDown:
+			view.Move(height - 4)
+		case keyLeft:
+			view.Collapse()
+		case keyRight, keyEnter:
+			view.Toggle()
+		default:
+			if !hasAction(opts.Actions, key) || opts.Do == nil {
+				continue
+			}
+			node := view.Selected()
+			if node == nil {
+				continue
+			}
+			restore()
+			fmt.Fprint(out, clearScreen, showCursor)
+			if err := opts.Do(key, node); err != nil {
+				fmt.Fprintf(out, "%sError:%s %v\n", ansiRed, ansiReset, err)
+			}
+			fmt.Fprint(out, "\nPress Enter to return.")
+			_, _ = in.Read(buf)
+			if roots, err := opts.Load(); err == nil {
+				view.Replace(roots)
+			}
+			if restore, err = makeRaw(in); err != nil {
+				return err
+			}
+			fmt.Fprint(out, hideCursor)
+		}
+	}
+}
+
+func hasAction(actions []Action, key rune) bool {
+	for _, action := range actions {
+		if action.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Navigation keys are mapped to private-use runes so they never collide with action keys.
+const (
+	keyNone     rune = 0
+	keyUp       rune = 0xE000
+	keyDown     rune = 0xE001
+	keyLeft     rune = 0xE002
+	keyRight    rune = 0xE003
+	keyPageUp   rune = 0xE004
+	keyPageDown rune = 0xE005
+	keyEnter    rune = 0xE006
+	keyQuit     rune = 0xE007
+)
+
+// parseKey decodes one read from a raw terminal into a key.
+func parseKey(input []byte) rune {
+	switch s := string(input); s {
+	case "\x1b[A", "\x1bOA", "k":
+		return keyUp
+	case "\x1b[B", "\x1bOB", "j":
+		return keyDown
+	case "\x1b[D", "\x1bOD", "h":
+		return keyLeft
+	case "\x1b[C", "\x1bOC", "l":
+		return keyRight
+	case "\x1b[5~":
+		return keyPageUp
+	case "\x1b[6~":
+		return keyPageDown
+	case "\r", "\n", " ":
+		return keyEnter
+	case "\x1b", "\x03", "\x04", "q":
+		return keyQuit
+	default:
+		if len(s) == 1 && s[0] >= 0x20 && s[0] < 0x7f {
+			return rune(s[0])
+		}
+	}
+	return keyNone
+}
+
+// View is the navigation state of the tree: the roots, the selected row, and the first visible row.
+type View struct {
+	Roots  []*Node
+	cursor int
+	offset int
+}
+
+type row struct {
+	node  *Node
+	depth int
+}
+
+func (v *View) rows() []row {
+	var rows []row
+	var walk func(nodes []*Node, depth int)
+	walk = func(nodes []*Node, depth int) {
+		for _, node := range nodes {
+			rows = append(rows, row{node: node, depth: depth})
+			if node.Expanded {
+				walk(node.Children, depth+1)
+			}
+		}
+	}
+	walk(v.Roots, 0)
+	return rows
+}
+
+// Selected returns the node under the cursor.
+func (v *View) Selected() *Node {
+	rows := v.rows()
+	if v.cursor < 0 || v.cursor >= len(rows) {
+		return nil
+	}
+	return rows[v.cursor].node
+}
+
+// Move shifts the cursor by delta rows, staying within the visible rows.
+func (v *View) Move(delta int) {
+	count := len(v.rows())
+	v.cursor += delta
+	if v.cursor >= count {
+		v.cursor = count - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+// Toggle expands or collapses the selected node.
+func (v *View) Toggle() {
+	if node := v.Selected(); node != nil && len(node.Children) > 0 {
+		node.Expanded = !node.Expanded
+	}
+}
+
+// Collapse closes the selected node, or moves to its parent when it is already closed.
+func (v *View) Collapse() {
+	rows := v.rows()
+	if v.cursor >= len(rows) {
+		return
+	}
+	current := rows[v.cursor]
+	if current.node.Expanded {
+		current.node.Expanded = false
+		return
+	}
+	for idx := v.cursor - 1; idx >= 0; idx-- {
+		if rows[idx].depth < current.depth {
+			v.cursor = idx
+			return
+		}
+	}
+}
+
+// Replace swaps in a freshly loaded tree, keeping expanded nodes open and the cursor on the same path.
+func (v *View) Replace(roots []*Node) {
+	selected := v.path(v.Selected())
+	expanded := map[string]bool{}
+	for _, r := range v.rows() {
+		if r.node.Expanded {
+			expanded[v.path(r.node)] = true
+		}
+	}
+	v.Roots = roots
+	var restore func(nodes []*Node, prefix string)
+	restore = func(nodes []*Node, prefix string) {
+		for _, node := range nodes {
+			key := prefix + "/" + node.Label
+			if expanded[key] {
+				node.Expanded = true
+			}
+			restore(node.Children, key)
+		}
+	}
+	restore(roots, "")
+	v.cursor = 0
+	for idx, r := range v.rows() {
+		if v.path(r.node) == selected {
+			v.cursor = idx
+			break
+		}
+	}
+	v.Move(0)
+}
+
+func (v *View) path(target *Node) string {
+	if target == nil {
+		return ""
+	}
+	var find func(nodes []*Node, prefix string) string
+	find = func(nodes []*Node, prefix string) string {
+		for _, node := range nodes {
+			key := prefix + "/" + node.Label
+			if node == target {
+				return key
+			}
+			if found := find(node.Children, key); found != "" {
+				return found
+			}
+		}
+		return ""
+	}
+	return find(v.Roots, "")
+}
+
+// Render draws the title, the visible part of the tree, and the key help for a width×height screen.
+func (v *View) Render(opts Options, width, height int) string {
+	rows := v.rows()
+	listHeight := height - 3
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+listHeight {
+		v.offset = v.cursor - listHeight + 1
+	}
+
+	var b strings.Builder
+	b.WriteString(ansiBold + truncate(opts.Title, width) + ansiReset + "\r\n\r\n")
+	if len(rows) == 0 {
+		b.WriteString("Nothing to show. Run `newo pull` first.\r\n")
+	}
+	for idx := v.offset; idx < len(rows) && idx < v.offset+listHeight; idx++ {
+		r := rows[idx]
+		marker := "  "
+		switch {
+		case len(r.node.Children) > 0 && r.node.Expanded:
+			marker = "▾ "
+		case len(r.node.Children) > 0:
+			marker = "▸ "
+		}
+		line := strings.Repeat("  ", r.depth) + marker + r.node.Label
+		if r.node.Detail != "" {
+			line += "  " + r.node.Detail
+		}
+		line = truncate(line, width-12)
+		status := r.node.Status
+		if idx == v.cursor {
+			b.WriteString(ansiReverse + line + ansiReset)
+		} else {
+			b.WriteString(line)
+		}
+		if status != "" {
+			color := ansiGray
+			if r.node.Drift {
+				color = ansiYellow
+			}
+			b.WriteString("  " + color + status + ansiReset)
+		}
+		b.WriteString("\r\n")
+	}
+
+	help := []string{"↑/↓ move", "→/enter expand", "← collapse"}
+	for _, action := range opts.Actions {
+		help = append(help, fmt.Sprintf("%c %s", action.Key, action.Label))
+	}
+	help = append(help, "q quit")
+	b.WriteString("\r\n" + ansiGray + truncate(strings.Join(help, " · "), width) + ansiReset)
+	return b.String()
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// TerminalSize reports the columns and rows of the terminal f is attached to, falling back to 80×24.
+func TerminalSize(f *os.File) (int, int) {
+	return terminalSize(f)
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func sampleTree() []*Node {
+	return []*Node{
+		{Label: "acme", Children: []*Node{
+			{Label: "booking", Children: []*Node{
+				{Label: "greet", Status: "modified", Drift: true},
+				{Label: "farewell", Status: "clean"},
+			}},
+		}},
+		{Label: "globex"},
+	}
+}
+
+func TestViewNavigation(t *testing.T) {
+	view := &View{Roots: sampleTree()}
+
+	view.Toggle()
+	view.Move(1)
+	view.Toggle()
+	view.Move(1)
+	if got := view.Selected().Label; got != "greet" {
+		t.Fatalf("expected greet, got %s", got)
+	}
+	view.Move(10)
+	if got := view.Selected().Label; got != "globex" {
+		t.Fatalf("expected the cursor to stop at the last row, got %s", got)
+	}
+
+	view.Move(-2)
+	view.Collapse()
+	if got := view.Selected().Label; got != "booking" {
+		t.Fatalf("expected collapse on a leaf to select its parent, got %s", got)
+	}
+	view.Collapse()
+	if len(view.rows()) != 3 {
+		t.Fatalf("expected booking to be collapsed, got %d rows", len(view.rows()))
+	}
+
+	view.Replace(sampleTree())
+	if got := view.Selected().Label; got != "booking" {
+		t.Fatalf("expected the selection to survive a reload, got %s", got)
+	}
+	if !view.Roots[0].Expanded || view.Roots[0].Children[0].Expanded {
+		t.Fatalf("expected expansion state to survive a reload")
+	}
+}
+
+func TestViewRender(t *testing.T) {
+	view := &View{Roots: sampleTree()}
+	view.Roots[0].Expanded = true
+	out := view.Render(Options{Title: "newo", Actions: []Action{{Key: 'p', Label: "pull"}}}, 80, 10)
+	for _, want := range []string{"newo", "▾ acme", "▸ booking", "globex", "p pull", "q quit"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	cases := map[string]rune{
+		"\x1b[A": keyUp,
+		"j":      keyDown,
+		"\r":     keyEnter,
+		"q":      keyQuit,
+		"\x03":   keyQuit,
+		"p":      'p',
+		"\x1b[Z": keyNone,
+	}
+	for input, want := range cases {
+		if got := parseKey([]byte(input)); got != want {
+			t.Fatalf("parseKey(%q) = %x, want %x", input, got, want)
+		}
+	}
+}
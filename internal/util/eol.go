@@ -0,0 +1,76 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Line-ending modes, named after git's core.autocrlf and set by autocrlf in the [defaults] section of
+// newo.toml.
+const (
+	// AutoCRLFFalse hashes and uploads files byte for byte.
+	AutoCRLFFalse = "false"
+	// AutoCRLFInput reads CRLF as LF when hashing files and uploads them with LF.
+	AutoCRLFInput = "input"
+	// AutoCRLFTrue behaves like AutoCRLFInput and also writes pulled files with CRLF.
+	AutoCRLFTrue = "true"
+)
+
+var (
+	eolMu    sync.RWMutex
+	autoCRLF = AutoCRLFFalse
+)
+
+// ConfigureLineEndings sets the line-ending mode; an empty mode means AutoCRLFFalse.
+func ConfigureLineEndings(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "":
+		mode = AutoCRLFFalse
+	case AutoCRLFFalse, AutoCRLFInput, AutoCRLFTrue:
+	default:
+		return fmt.Errorf("autocrlf must be %q, %q, or %q, got %q", AutoCRLFFalse, AutoCRLFInput, AutoCRLFTrue, mode)
+	}
+	eolMu.Lock()
+	autoCRLF = mode
+	eolMu.Unlock()
+	return nil
+}
+
+// LineEndings returns the active line-ending mode.
+func LineEndings() string {
+	eolMu.RLock()
+	defer eolMu.RUnlock()
+	return autoCRLF
+}
+
+// NormalizeEOL returns content with CRLF replaced by LF, unless the mode is AutoCRLFFalse.
+func NormalizeEOL(content []byte) []byte {
+	if LineEndings() == AutoCRLFFalse || !bytes.Contains(content, []byte("\r\n")) {
+		return content
+	}
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// WorkingTreeEOL returns content as it should be written to the workspace: with CRLF line endings
+// under AutoCRLFTrue, unchanged otherwise.
+func WorkingTreeEOL(content []byte) []byte {
+	if LineEndings() != AutoCRLFTrue || !bytes.Contains(content, []byte("\n")) {
+		return content
+	}
+	lf := bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+}
+
+// ContentHash is the digest used to compare file contents with the platform. Line endings are
+// normalized first, so a file checked out with CRLF matches the LF script it came from.
+func ContentHash(content []byte) string {
+	return SHA256Bytes(NormalizeEOL(content))
+}
+
+// ContentHashString is ContentHash for a string.
+func ContentHashString(content string) string {
+	return ContentHash([]byte(content))
+}
@@ -0,0 +1,30 @@
+package util
+
+import "testing"
+
+func TestContentHashLineEndings(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureLineEndings("") })
+
+	lf, crlf := []byte("a\nb\n"), []byte("a\r\nb\r\n")
+	if ContentHash(lf) == ContentHash(crlf) {
+		t.Fatal("expected byte-for-byte hashes by default")
+	}
+	if err := ConfigureLineEndings(AutoCRLFInput); err != nil {
+		t.Fatal(err)
+	}
+	if ContentHash(crlf) != SHA256Bytes(lf) {
+		t.Fatal("expected CRLF content to hash like LF under input")
+	}
+	if got := string(WorkingTreeEOL(lf)); got != "a\nb\n" {
+		t.Fatalf("expected input to write LF unchanged, got %q", got)
+	}
+	if err := ConfigureLineEndings(AutoCRLFTrue); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(WorkingTreeEOL([]byte("a\r\nb\n"))); got != "a\r\nb\r\n" {
+		t.Fatalf("expected CRLF line endings without doubling, got %q", got)
+	}
+	if err := ConfigureLineEndings("auto"); err == nil {
+		t.Fatal("expected an unknown mode to be rejected")
+	}
+}
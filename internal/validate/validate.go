@@ -0,0 +1,227 @@
+package validate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twinmind/newo-tool/internal/fsutil"
+)
+
+// Check identifiers reported with every issue.
+const (
+	CheckYAML          = "yaml"
+	CheckMetadata      = "metadata"
+	CheckMissingScript = "missing-script"
+	CheckRunnerType    = "runner-type"
+	CheckIDN           = "idn"
+)
+
+// Issue describes a structural problem in an exported project.
+type Issue struct {
+	Path    string `json:"path"`
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+// Report collects the issues found in a set of projects.
+type Report struct {
+	Projects []string `json:"projects"`
+	Issues   []Issue  `json:"issues"`
+}
+
+var (
+	knownRunnerTypes = map[string]bool{"nsl": true, "guidance": true}
+	idnPattern       = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+)
+
+type flowsDocument struct {
+	Flows []struct {
+		AgentIDN   string `yaml:"agent_idn"`
+		AgentFlows []struct {
+			IDN               string `yaml:"idn"`
+			DefaultRunnerType string `yaml:"default_runner_type"`
+			Skills            []struct {
+				IDN          string `yaml:"idn"`
+				PromptScript string `yaml:"prompt_script"`
+				RunnerType   string `yaml:"runner_type"`
+			} `yaml:"skills"`
+			Events []struct {
+				IDN string `yaml:"idn"`
+			} `yaml:"events"`
+			StateFields []struct {
+				IDN string `yaml:"idn"`
+			} `yaml:"state_fields"`
+		} `yaml:"agent_flows"`
+	} `yaml:"flows"`
+}
+
+type skillMetadata struct {
+	IDN        string `yaml:"idn"`
+	Title      string `yaml:"title"`
+	RunnerType string `yaml:"runner_type"`
+}
+
+// Run validates every exported project found under root, which holds exports of the given customer
+// type. A project is any directory containing flows.yaml.
+func Run(root, customerType string) (Report, error) {
+	var report Report
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == fsutil.StateDirName {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == fsutil.FlowsYAML {
+			report.Projects = append(report.Projects, filepath.ToSlash(filepath.Dir(path)))
+		}
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	sort.Strings(report.Projects)
+
+	for _, projectDir := range report.Projects {
+		issues, err := Project(filepath.FromSlash(projectDir), customerType)
+		if err != nil {
+			return Report{}, err
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+	return report, nil
+}
+
+// Project validates a single exported project directory of a customer of the given type. The type
+// decides whether scripts live under an agent directory.
+func Project(projectDir, customerType string) ([]Issue, error) {
+	var issues []Issue
+	add := func(path, check, format string, args ...any) {
+		issues = append(issues, Issue{Path: filepath.ToSlash(path), Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	yamlFiles := map[string]bool{}
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			add(path, CheckYAML, "invalid YAML: %v", err)
+			return nil
+		}
+		yamlFiles[path] = true
+		if strings.HasSuffix(path, fsutil.SkillMetaFileExt) {
+			issues = append(issues, checkSkillMetadata(path, data)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	flowsPath := filepath.Join(projectDir, fsutil.FlowsYAML)
+	if !yamlFiles[flowsPath] {
+		// Missing or unparsable; the parse error has already been reported.
+		return issues, nil
+	}
+	data, err := os.ReadFile(flowsPath)
+	if err != nil {
+		return nil, err
+	}
+	var doc flowsDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		add(flowsPath, CheckYAML, "unexpected %s structure: %v", fsutil.FlowsYAML, err)
+		return issues, nil
+	}
+
+	for _, agent := range doc.Flows {
+		checkIDN(add, flowsPath, "agent", agent.AgentIDN)
+		agentDir := fsutil.ProjectAgentDir(projectDir, customerType, agent.AgentIDN)
+		for _, flow := range agent.AgentFlows {
+			checkIDN(add, flowsPath, "flow", flow.IDN)
+			if runner := enumValue(flow.DefaultRunnerType); runner != "" && runner != "none" && !knownRunnerTypes[runner] {
+				add(flowsPath, CheckRunnerType, "flow %s: unknown default runner type %q", flow.IDN, flow.DefaultRunnerType)
+			}
+			for _, skill := range flow.Skills {
+				checkIDN(add, flowsPath, "skill", skill.IDN)
+				if runner := enumValue(skill.RunnerType); !knownRunnerTypes[runner] {
+					add(flowsPath, CheckRunnerType, "skill %s/%s: unknown runner type %q", flow.IDN, skill.IDN, skill.RunnerType)
+				}
+				script := strings.TrimSpace(skill.PromptScript)
+				if script == "" {
+					add(flowsPath, CheckMissingScript, "skill %s/%s has no prompt_script", flow.IDN, skill.IDN)
+					continue
+				}
+				if _, err := os.Stat(filepath.Join(agentDir, filepath.FromSlash(script))); err != nil {
+					add(flowsPath, CheckMissingScript, "skill %s/%s: script %s not found", flow.IDN, skill.IDN, script)
+				}
+			}
+			for _, event := range flow.Events {
+				checkIDN(add, flowsPath, "event", event.IDN)
+			}
+			for _, field := range flow.StateFields {
+				checkIDN(add, flowsPath, "state field", field.IDN)
+			}
+		}
+	}
+	return issues, nil
+}
+
+func checkSkillMetadata(path string, data []byte) []Issue {
+	var meta skillMetadata
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return []Issue{{Path: filepath.ToSlash(path), Check: CheckYAML, Message: fmt.Sprintf("unexpected skill metadata structure: %v", err)}}
+	}
+
+	var issues []Issue
+	for _, field := range []struct{ name, value string }{
+		{"idn", meta.IDN},
+		{"title", meta.Title},
+		{"runner_type", meta.RunnerType},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			issues = append(issues, Issue{Path: filepath.ToSlash(path), Check: CheckMetadata, Message: fmt.Sprintf("missing required field %q", field.name)})
+		}
+	}
+	expected := strings.TrimSuffix(filepath.Base(path), fsutil.SkillMetaFileExt)
+	if idn := strings.TrimSpace(meta.IDN); idn != "" && idn != expected {
+		issues = append(issues, Issue{Path: filepath.ToSlash(path), Check: CheckMetadata, Message: fmt.Sprintf("idn %q does not match file name %q", idn, expected)})
+	}
+	if runner := strings.ToLower(strings.TrimSpace(meta.RunnerType)); runner != "" && !knownRunnerTypes[runner] {
+		issues = append(issues, Issue{Path: filepath.ToSlash(path), Check: CheckRunnerType, Message: fmt.Sprintf("unknown runner type %q", meta.RunnerType)})
+	}
+	return issues
+}
+
+func checkIDN(add func(string, string, string, ...any), path, kind, idn string) {
+	if !idnPattern.MatchString(idn) {
+		add(path, CheckIDN, "%s IDN %q must start with a letter and contain only letters, digits, '_' or '-'", kind, idn)
+	}
+}
+
+// enumValue strips the enum prefix written to flows.yaml (e.g. "RunnerType.nsl" -> "nsl").
+func enumValue(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if idx := strings.LastIndex(value, "."); idx >= 0 {
+		value = value[idx+1:]
+	}
+	return value
+}
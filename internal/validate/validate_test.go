@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunReportsStructuralIssues(t *testing.T) {
+	root := t.TempDir()
+	projectDir := filepath.Join(root, "support")
+	flowDir := filepath.Join(projectDir, "flows", "MainFlow")
+	if err := os.MkdirAll(flowDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	files := map[string]string{
+		"flows.yaml": `flows:
+  - agent_idn: Support
+    agent_flows:
+      - idn: MainFlow
+        default_runner_type: !enum "RunnerType.nsl"
+        skills:
+          - idn: Greet
+            prompt_script: flows/MainFlow/Greet.nsl
+            runner_type: !enum "RunnerType.nsl"
+          - idn: 9lives
+            prompt_script: flows/MainFlow/9lives.nsl
+            runner_type: !enum "RunnerType.python"
+`,
+		"flows/MainFlow/Greet.nsl":       "{{ true }}\n",
+		"flows/MainFlow/Greet.meta.yaml": "idn: Greet\ntitle: Greet\nrunner_type: nsl\n",
+		"flows/MainFlow/Other.meta.yaml": "idn: Wrong\nrunner_type: nsl\n",
+		"flows/MainFlow/metadata.yaml":   "idn: [unterminated\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	report, err := Run(root, "integration")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Projects) != 1 {
+		t.Fatalf("expected one project, got %v", report.Projects)
+	}
+
+	checks := map[string]int{}
+	for _, issue := range report.Issues {
+		checks[issue.Check]++
+	}
+	expected := map[string]int{
+		CheckYAML:          1, // metadata.yaml
+		CheckMetadata:      2, // missing title, idn/file name mismatch
+		CheckIDN:           1, // 9lives
+		CheckRunnerType:    1, // python
+		CheckMissingScript: 1, // 9lives.nsl
+	}
+	for check, count := range expected {
+		if checks[check] != count {
+			t.Fatalf("expected %d %s issue(s), got %d: %+v", count, check, checks[check], report.Issues)
+		}
+	}
+	if len(report.Issues) != 6 {
+		t.Fatalf("unexpected issues: %+v", report.Issues)
+	}
+}
+
+func TestProjectResolvesScriptsUnderAgentDirectories(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "acme", "support")
+	files := map[string]string{
+		"flows.yaml": `flows:
+  - agent_idn: Support
+    agent_flows:
+      - idn: Main
+        default_runner_type: !enum "RunnerType.nsl"
+        skills:
+          - idn: Greet
+            prompt_script: flows/Main/Greet.nsl
+            runner_type: !enum "RunnerType.nsl"
+`,
+		"Support/flows/Main/Greet.nsl":       "{{ true }}\n",
+		"Support/flows/Main/Greet.meta.yaml": "idn: Greet\ntitle: Greet\nrunner_type: nsl\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	issues, err := Project(projectDir, "")
+	if err != nil {
+		t.Fatalf("Project: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected a clean default-type export, got %+v", issues)
+	}
+
+	issues, err = Project(projectDir, "integration")
+	if err != nil {
+		t.Fatalf("Project: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Check != CheckMissingScript {
+		t.Fatalf("expected the integration layout to miss the script, got %+v", issues)
+	}
+}
@@ -0,0 +1,78 @@
+// Package newo is the embeddable API of newo-tool. It exposes the platform client together with the
+// push and pull services the CLI is built on, so other tools can synchronise NEWO projects without
+// shelling out to the newo binary.
+//
+// The types are aliases of the CLI's own, which keeps both in step: a fix to the client or to a
+// service reaches embedders and the CLI alike.
+package newo
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+)
+
+// Client talks to the NEWO platform API. Its methods satisfy SkillSyncClient and ApplyClient.
+type Client = platform.Client
+
+// ClientOption customises a Client.
+type ClientOption = platform.ClientOption
+
+// APIError is returned for non-2xx platform responses.
+type APIError = platform.APIError
+
+// Platform resources returned by the client.
+type (
+	Project         = platform.Project
+	Agent           = platform.Agent
+	Flow            = platform.Flow
+	Skill           = platform.Skill
+	CustomerProfile = platform.CustomerProfile
+)
+
+// NewClient constructs a client that authenticates with an access token.
+func NewClient(baseURL, token string, opts ...ClientOption) (*Client, error) {
+	return platform.NewClient(baseURL, token, opts...)
+}
+
+// Connect exchanges apiKey for an access token and returns a client that repeats the exchange
+// whenever the platform rejects an expired token.
+func Connect(ctx context.Context, baseURL, apiKey string, opts ...ClientOption) (*Client, error) {
+	tokens, err := platform.ExchangeAPIKeyForToken(ctx, baseURL, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("exchange api key: %w", err)
+	}
+	token := tokens.AccessToken
+	if token == "" {
+		token = tokens.Token
+	}
+	renew := func(ctx context.Context) (string, error) {
+		fresh, err := platform.ExchangeAPIKeyForToken(ctx, baseURL, apiKey)
+		if err != nil {
+			return "", fmt.Errorf("exchange api key: %w", err)
+		}
+		if fresh.AccessToken != "" {
+			return fresh.AccessToken, nil
+		}
+		return fresh.Token, nil
+	}
+	return platform.NewClient(baseURL, token, append([]ClientOption{platform.WithTokenRenewer(renew)}, opts...)...)
+}
+
+// WithAPIVersion serves the API under another version prefix, such as /api/v2/.
+func WithAPIVersion(version string) ClientOption {
+	return platform.WithAPIVersion(version)
+}
+
+// WithRequestCompression gzip-compresses large request bodies.
+func WithRequestCompression(enabled bool) ClientOption {
+	return platform.WithRequestCompression(enabled)
+}
+
+// WithStrictAPI checks responses against the client's types, warning about unknown fields on warnings
+// and failing on missing required ones.
+func WithStrictAPI(warnings io.Writer) ClientOption {
+	return platform.WithStrictAPI(warnings)
+}
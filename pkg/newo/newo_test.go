@@ -0,0 +1,66 @@
+package newo_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/twinmind/newo-tool/internal/platform"
+	"github.com/twinmind/newo-tool/internal/testutil/httpmock"
+	"github.com/twinmind/newo-tool/pkg/newo"
+)
+
+var (
+	_ newo.SkillSyncClient = (*newo.Client)(nil)
+	_ newo.ApplyClient     = (*newo.Client)(nil)
+)
+
+func TestConnectAndImport(t *testing.T) {
+	server := httpmock.NewServer(httpmock.Fixture{
+		Customer: httpmock.Customer{IDN: "acme"},
+		Projects: []*httpmock.Project{{IDN: "shop", Agents: []*httpmock.Agent{{IDN: "bot", Flows: []*httpmock.Flow{{
+			IDN:    "main",
+			Skills: []*httpmock.Skill{{IDN: "greet", RunnerType: "nsl", PromptScript: "Hello"}},
+		}}}}}},
+	}, "secret")
+	httpClient, transport := httpmock.New(server)
+	t.Cleanup(platform.SetHTTPClientForTesting(httpClient))
+	t.Cleanup(platform.SetTransportForTesting(transport))
+
+	ctx := context.Background()
+	if _, err := newo.Connect(ctx, httpmock.BaseURL, "wrong"); err == nil {
+		t.Fatal("expected a rejected api key to fail")
+	}
+	client, err := newo.Connect(ctx, httpmock.BaseURL, "secret")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	profile, err := client.GetCustomerProfile(ctx)
+	if err != nil || profile.IDN != "acme" {
+		t.Fatalf("expected the acme profile, got %+v, %v", profile, err)
+	}
+
+	dir := t.TempDir()
+	manifest, err := newo.NewApplier(client).Import(ctx, "shop", dir)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(manifest.Agents) != 1 || len(manifest.Agents[0].Flows) != 1 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	manifestPath := filepath.Join(dir, "newo.yaml")
+	if err := newo.WriteManifest(manifestPath, manifest); err != nil {
+		t.Fatalf("WriteManifest: %v", err)
+	}
+	plan, err := newo.LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if plan.IDN != "shop" {
+		t.Fatalf("expected the shop project, got %q", plan.IDN)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills", "main")); err != nil {
+		t.Fatalf("expected imported skill scripts: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package newo
+
+import (
+	"github.com/twinmind/newo-tool/internal/deploy"
+)
+
+// Applier pulls a remote project into a manifest with Import and reconciles a remote project with a
+// manifest with Apply. It is the service behind `newo apply` and `newo apply --import`.
+type Applier = deploy.Applier
+
+// ApplyClient is the subset of Client the applier needs; substitute it in tests.
+type ApplyClient = deploy.ApplyClient
+
+// Manifests, plans, and the inputs and results of an apply.
+type (
+	Manifest         = deploy.Manifest
+	ProjectPlan      = deploy.ProjectPlan
+	ApplyRequest     = deploy.ApplyRequest
+	ApplyResult      = deploy.ApplyResult
+	ApplyPlan        = deploy.ApplyPlan
+	Change           = deploy.Change
+	ConfirmApplyFunc = deploy.ConfirmApplyFunc
+)
+
+// NewApplier constructs an applier.
+func NewApplier(client ApplyClient) *Applier {
+	return deploy.NewApplier(client)
+}
+
+// WriteManifest writes a manifest, such as one returned by Import, to path.
+func WriteManifest(path string, manifest Manifest) error {
+	return deploy.WriteManifest(path, manifest)
+}
+
+// LoadManifest reads the manifest at path and resolves the skill scripts it refers to.
+func LoadManifest(path string) (ProjectPlan, error) {
+	return deploy.LoadManifest(path)
+}
@@ -0,0 +1,52 @@
+package newo
+
+import (
+	"github.com/twinmind/newo-tool/internal/state"
+	skillsync "github.com/twinmind/newo-tool/internal/sync"
+)
+
+// SkillSyncService pushes local skill scripts, flow definitions, agents, and flows to the platform,
+// and publishes flows. It is the service behind `newo push`.
+type SkillSyncService = skillsync.SkillSyncService
+
+// SkillSyncClient is the subset of Client the push service needs; substitute it in tests.
+type SkillSyncClient = skillsync.SkillSyncClient
+
+// Inputs and results of a push.
+type (
+	SkillSyncRequest    = skillsync.SkillSyncRequest
+	SkillSyncResult     = skillsync.SkillSyncResult
+	SkillSyncWarning    = skillsync.SkillSyncWarning
+	Reporter            = skillsync.Reporter
+	ProgressReporter    = skillsync.ProgressReporter
+	DiffGenerator       = skillsync.DiffGenerator
+	DiffFunc            = skillsync.DiffFunc
+	Decision            = skillsync.Decision
+	ConfirmPushRequest  = skillsync.ConfirmPushRequest
+	ConfirmPushFunc     = skillsync.ConfirmPushFunc
+	ConfirmDeletionFunc = skillsync.ConfirmDeletionFunc
+	PublishRequest      = skillsync.PublishRequest
+	PublishResult       = skillsync.PublishResult
+)
+
+// Workspace state the push service reads and updates.
+type (
+	ProjectMap  = state.ProjectMap
+	ProjectData = state.ProjectData
+	HashStore   = state.HashStore
+)
+
+// NewSkillSyncService constructs a push service. A nil diffGen shows no diffs in confirmations.
+func NewSkillSyncService(client SkillSyncClient, diffGen DiffGenerator) *SkillSyncService {
+	return skillsync.NewSkillSyncService(client, diffGen)
+}
+
+// LoadProjectMap reads the project map the CLI keeps for a customer in the current workspace.
+func LoadProjectMap(customerIDN string) (ProjectMap, error) {
+	return state.LoadProjectMap(customerIDN)
+}
+
+// LoadHashes reads the hash snapshot the CLI keeps for a customer in the current workspace.
+func LoadHashes(customerIDN string) (HashStore, error) {
+	return state.LoadHashes(customerIDN)
+}